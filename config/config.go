@@ -2,43 +2,230 @@ package config
 
 import (
 	"context"
+	"log/slog"
+	"os"
+	"slices"
 	"strings"
+	"sync/atomic"
 
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	RestServer restServer `mapstructure:"restServer"`
-	Postgres   postgres   `mapstructure:"postgres"`
-	Redis      redis      `mapstructure:"redis"`
+	Storage     storage     `mapstructure:"storage"`
+	Startup     startup     `mapstructure:"startup"`
+	HealthCheck healthCheck `mapstructure:"healthCheck"`
+	Auth        auth        `mapstructure:"auth"`
+	RestServer  restServer  `mapstructure:"restServer"`
+	Log         logging     `mapstructure:"log"`
+	Postgres    postgres    `mapstructure:"postgres"`
+	Redis       redis       `mapstructure:"redis"`
+	Cache       cache       `mapstructure:"cache"`
+	Feed        feed        `mapstructure:"feed"`
+	Sitemap     sitemap     `mapstructure:"sitemap"`
+	Summarizer  summarizer  `mapstructure:"summarizer"`
+	Collector   collector   `mapstructure:"collector"`
+	Jobs        jobs        `mapstructure:"jobs"`
+	Retention   retention   `mapstructure:"retention"`
+	Stream      stream      `mapstructure:"stream"`
+	WebSocket   webSocket   `mapstructure:"webSocket"`
+	Webhook     webhook     `mapstructure:"webhook"`
+	Search      search      `mapstructure:"search"`
+	Notify      notify      `mapstructure:"notify"`
+	Line        line        `mapstructure:"line"`
+	Telegram    telegram    `mapstructure:"telegram"`
+	Discord     discord     `mapstructure:"discord"`
+	FCM         fcm         `mapstructure:"fcm"`
+	// Personalization tunes the personalized ranking of /news
+	Personalization personalization `mapstructure:"personalization"`
+	// Clustering groups near-duplicate stories of different sources for groupDuplicates on /news
+	Clustering clustering `mapstructure:"clustering"`
+	RateLimit  rateLimit  `mapstructure:"rateLimit"`
+	// SourceSuggestion governs the sources readers suggest through /sources/suggest
+	SourceSuggestion sourceSuggestion `mapstructure:"sourceSuggestion"`
+	// SavedSearch limits the searches readers save through /users/me/searches
+	SavedSearch savedSearch `mapstructure:"savedSearch"`
+	// Sentry receives server errors and recovered panics
+	Sentry sentry `mapstructure:"sentry"`
+	// Secrets looks up postgres.password and redis.password in a secrets store
+	Secrets secretStore `mapstructure:"secrets"`
+
+	// files are the config files this configuration was read from
+	files []string
+}
+
+// StorageDriverMemory selects the in-process repository and cache instead of Postgres and Redis
+const StorageDriverMemory = "memory"
+
+type storage struct {
+	// Driver is postgres (Postgres + Redis) or memory (in-process, for local development)
+	Driver string `mapstructure:"driver"`
+}
+
+// Startup policies decide what happens when Postgres or Redis cannot be reached at boot
+const (
+	StartupPolicyFailFast = "failFast"
+	StartupPolicyDegraded = "degraded"
+)
+
+type startup struct {
+	// Policy is failFast (exit on a dependency error) or degraded (serve anyway;
+	// database calls answer 503 and caching is skipped until the dependency is back)
+	Policy string `mapstructure:"policy"`
+}
+
+type healthCheck struct {
+	Interval         int `mapstructure:"interval"`         // seconds between dependency pings, 0 disables reconnects
+	FailureThreshold int `mapstructure:"failureThreshold"` // consecutive failed pings before reconnecting
+}
+
+type auth struct {
+	// Enabled requires an API key on /internal and /backoffice routes
+	Enabled bool `mapstructure:"enabled"`
+	// AdminKeyHashes are hex sha256 digests of bootstrap admin keys, which can manage
+	// the keys stored in the database
+	AdminKeyHashes []string `mapstructure:"adminKeyHashes" secret:"true"`
+	JWT            authJWT  `mapstructure:"jwt"`
+	// OAuth lists the apps whose Google and Apple ID tokens readers may sign in with
+	OAuth authOAuth `mapstructure:"oauth"`
+}
+
+// authJWT configures backoffice logins; tokens are only issued when Secret is set
+type authJWT struct {
+	Secret          string `mapstructure:"secret" secret:"true"`
+	AccessTokenTTL  int    `mapstructure:"accessTokenTTL"`  // in minutes
+	RefreshTokenTTL int    `mapstructure:"refreshTokenTTL"` // in hours
+}
+
+type authOAuth struct {
+	Google oauthProvider `mapstructure:"google"`
+	Apple  oauthProvider `mapstructure:"apple"`
+}
+
+// oauthProvider enables sign-in with a provider once at least one client id is set
+type oauthProvider struct {
+	// ClientIDs are the OAuth client ids (Google) or bundle and service ids (Apple) of our apps
+	ClientIDs []string `mapstructure:"clientIds"`
+	JWKSURL   string   `mapstructure:"jwksUrl"`
 }
 
 type restServer struct {
-	Port int `mapstructure:"port"`
+	// Host is the interface address to listen on, empty for all of them
+	Host string `mapstructure:"host"`
+	Port int    `mapstructure:"port"`
+	// Socket is a Unix socket path listened on instead of host and port, for deployments
+	// behind a local reverse proxy; SocketMode is its octal file mode such as 0660
+	Socket     string `mapstructure:"socket"`
+	SocketMode string `mapstructure:"socketMode"`
+	// MaxBodyBytes caps JSON request bodies; larger ones are answered with 413
+	MaxBodyBytes int64 `mapstructure:"maxBodyBytes"`
+	// DisallowUnknownFields rejects JSON bodies with fields the endpoint doesn't accept
+	DisallowUnknownFields bool              `mapstructure:"disallowUnknownFields"`
+	Timeout               restServerTimeout `mapstructure:"timeout"`
+	// LegacySunset is the YYYY-MM-DD date announced in the Sunset header of the
+	// unversioned routes; empty only marks them deprecated
+	LegacySunset string `mapstructure:"legacySunset"`
+	// Debug serves the pprof profiles and expvar counters under /internal/debug to admins
+	Debug bool `mapstructure:"debug"`
+}
+
+// restServerTimeout bounds how long a request may run before it is answered with 504
+type restServerTimeout struct {
+	Default  int `mapstructure:"default"`  // in seconds, 0 disables
+	Internal int `mapstructure:"internal"` // in seconds, for the /internal jobs, 0 disables
+}
+
+type logging struct {
+	// Level is the lowest level logged, such as debug, info, warn or error
+	Level string `mapstructure:"level"`
+	// Levels set the level of single modules, such as collector or http, apart from Level
+	Levels map[string]string `mapstructure:"levels"`
+	// Sampling writes 1 in N entries of a message, such as "cache hit": 100. Errors are
+	// always written
+	Sampling map[string]int `mapstructure:"sampling"`
+	// Format is json, or text for reading during local development
+	Format string `mapstructure:"format"`
+	// Output is stdout, stderr or file; with stdout, ErrorsToStderr writes error entries to
+	// stderr instead
+	Output         string  `mapstructure:"output"`
+	ErrorsToStderr bool    `mapstructure:"errorsToStderr"`
+	File           logFile `mapstructure:"file"`
+	// MaxBodyBytes is the largest request body written to the access log; bigger bodies
+	// are skipped and 0 disables body logging
+	MaxBodyBytes int64 `mapstructure:"maxBodyBytes"`
+	// RedactFields are JSON keys whose values are masked in logged bodies, at any depth, and
+	// RedactAttrs the log attributes masked in every entry. Both ignore case, "_" and "-"
+	RedactFields []string `mapstructure:"redactFields"`
+	RedactAttrs  []string `mapstructure:"redactAttrs"`
+	// BodySampleRate is the share of access log entries, from 0 to 1, that include the body
+	BodySampleRate float64 `mapstructure:"bodySampleRate"`
+}
+
+// logFile is written with the file output and rotated once it reaches MaxSize
+type logFile struct {
+	Path       string `mapstructure:"path"`
+	MaxSize    int    `mapstructure:"maxSize"`    // in megabytes
+	MaxBackups int    `mapstructure:"maxBackups"` // rotated files kept, 0 keeps all
+	MaxAge     int    `mapstructure:"maxAge"`     // in days a rotated file is kept, 0 keeps them
+	Compress   bool   `mapstructure:"compress"`   // gzip rotated files
 }
 
 type postgres struct {
-	Host     string `mapstructure:"host"`
-	Port     int    `mapstructure:"port"`
-	User     string `mapstructure:"user"`
-	Password string `mapstructure:"password"`
-	Dbname   string `mapstructure:"dbname"`
+	Host     string       `mapstructure:"host"`
+	Port     int          `mapstructure:"port"`
+	User     string       `mapstructure:"user"`
+	Password string       `mapstructure:"password" secret:"true"`
+	Dbname   string       `mapstructure:"dbname"`
 	Pool     postgresPool `mapstructure:"pool"`
+	// SSLMode is a libpq sslmode (disable, require, verify-ca, verify-full); the
+	// certificate settings are file paths and only needed for the verify modes or client certs
+	SSLMode     string `mapstructure:"sslMode"`
+	SSLRootCert string `mapstructure:"sslRootCert"`
+	SSLCert     string `mapstructure:"sslCert"`
+	SSLKey      string `mapstructure:"sslKey"`
+	// MigrateOnStartup applies pending embedded migrations before serving
+	MigrateOnStartup bool `mapstructure:"migrateOnStartup"`
+	// QueryTimeout bounds each repository call in seconds; StatementTimeout is the
+	// server-side statement_timeout in seconds. Zero disables either
+	QueryTimeout     int           `mapstructure:"queryTimeout"`
+	StatementTimeout int           `mapstructure:"statementTimeout"`
+	Retry            postgresRetry `mapstructure:"retry"`
+	SlowQuery        postgresSlow  `mapstructure:"slowQuery"`
+	// Replicas receive read-only queries; they share the primary's credentials and pool settings
+	Replicas []postgresReplica `mapstructure:"replicas"`
+}
+
+// postgresSlow logs the queries that take at least Threshold, with their SQL and, unless
+// LogArgs is off, their arguments
+type postgresSlow struct {
+	Threshold int  `mapstructure:"threshold"` // in milliseconds, 0 disables
+	LogArgs   bool `mapstructure:"logArgs"`
+}
+
+// postgresRetry controls retries of idempotent reads on transient errors
+type postgresRetry struct {
+	MaxAttempts int `mapstructure:"maxAttempts"` // total attempts including the first
+	Backoff     int `mapstructure:"backoff"`     // initial backoff in milliseconds, doubled per retry
+}
+
+type postgresReplica struct {
+	Host string `mapstructure:"host"`
+	Port int    `mapstructure:"port"`
 }
 
 type postgresPool struct {
-	MaxConns        int32 `mapstructure:"maxConns"`
-	MinConns        int32 `mapstructure:"minConns"`
-	MaxConnLifetime int   `mapstructure:"maxConnLifetime"` // in minutes
-	MaxConnIdleTime int   `mapstructure:"maxConnIdleTime"` // in minutes
-	HealthCheckPeriod int `mapstructure:"healthCheckPeriod"` // in minutes
-	ConnectTimeout  int   `mapstructure:"connectTimeout"`    // in seconds
+	MaxConns          int32 `mapstructure:"maxConns"`
+	MinConns          int32 `mapstructure:"minConns"`
+	MaxConnLifetime   int   `mapstructure:"maxConnLifetime"`   // in minutes
+	MaxConnIdleTime   int   `mapstructure:"maxConnIdleTime"`   // in minutes
+	HealthCheckPeriod int   `mapstructure:"healthCheckPeriod"` // in minutes
+	ConnectTimeout    int   `mapstructure:"connectTimeout"`    // in seconds
 }
 
 type redis struct {
 	Host     string    `mapstructure:"host"`
 	Port     int       `mapstructure:"port"`
-	Password string    `mapstructure:"password"`
+	Password string    `mapstructure:"password" secret:"true"`
 	Pool     redisPool `mapstructure:"pool"`
 }
 
@@ -46,41 +233,311 @@ type redisPool struct {
 	PoolSize        int `mapstructure:"poolSize"`
 	MinIdleConns    int `mapstructure:"minIdleConns"`
 	MaxIdleConns    int `mapstructure:"maxIdleConns"`
-	PoolTimeout     int `mapstructure:"poolTimeout"`     // in seconds
-	IdleTimeout     int `mapstructure:"idleTimeout"`     // in minutes
-	MaxConnAge      int `mapstructure:"maxConnAge"`      // in minutes
-	DialTimeout     int `mapstructure:"dialTimeout"`     // in seconds
-	ReadTimeout     int `mapstructure:"readTimeout"`     // in seconds
-	WriteTimeout    int `mapstructure:"writeTimeout"`    // in seconds
+	PoolTimeout     int `mapstructure:"poolTimeout"`  // in seconds
+	IdleTimeout     int `mapstructure:"idleTimeout"`  // in minutes
+	MaxConnAge      int `mapstructure:"maxConnAge"`   // in minutes
+	DialTimeout     int `mapstructure:"dialTimeout"`  // in seconds
+	ReadTimeout     int `mapstructure:"readTimeout"`  // in seconds
+	WriteTimeout    int `mapstructure:"writeTimeout"` // in seconds
 	MaxRetries      int `mapstructure:"maxRetries"`
 	MinRetryBackoff int `mapstructure:"minRetryBackoff"` // in milliseconds
 	MaxRetryBackoff int `mapstructure:"maxRetryBackoff"` // in milliseconds
 }
 
-var config *Config
+// cache sets how long cached responses live per domain, the part of their key before the
+// first colon
+type cache struct {
+	News    cacheDomain `mapstructure:"news"`
+	Tags    cacheDomain `mapstructure:"tags"`
+	Sources cacheDomain `mapstructure:"sources"`
+	// EmptyTTL is how long, in seconds, a query that matched nothing is remembered as empty
+	EmptyTTL int `mapstructure:"emptyTTL"`
+	// Encoding is json or msgpack; values are decoded by what they were written with
+	Encoding string `mapstructure:"encoding"`
+	// Compression is none, snappy or zstd, for values of at least CompressMinBytes
+	Compression      string `mapstructure:"compression"`
+	CompressMinBytes int    `mapstructure:"compressMinBytes"`
+}
+
+type cacheDomain struct {
+	TTL int `mapstructure:"ttl"` // in seconds, 0 keeps entries until they are invalidated
+}
+
+type feed struct {
+	Title       string `mapstructure:"title"`
+	Description string `mapstructure:"description"`
+	Link        string `mapstructure:"link"`
+	Limit       int32  `mapstructure:"limit"`
+}
+
+// sitemap configures the sitemaps that get the companion website indexed
+type sitemap struct {
+	Enabled bool `mapstructure:"enabled"` // serve /sitemap.xml
+	// News also serves /news-sitemap.xml, the Google News sitemap of the last two days
+	News bool `mapstructure:"news"`
+	// BaseURL is the website; its article pages are BaseURL + ArticlePath
+	BaseURL string `mapstructure:"baseUrl"`
+	// ArticlePath is the path of an article page, where {id} is the id of the news
+	ArticlePath     string `mapstructure:"articlePath"`
+	Interval        int    `mapstructure:"interval"` // seconds between regenerations
+	MaxURLs         int    `mapstructure:"maxUrls"`  // newest articles in /sitemap.xml, at most 50000
+	PublicationName string `mapstructure:"publicationName"`
+	Language        string `mapstructure:"language"` // ISO 639 code of the articles
+}
+
+type summarizer struct {
+	Provider     string        `mapstructure:"provider"` // none, extractive or llm
+	MaxSentences int           `mapstructure:"maxSentences"`
+	Concurrency  int           `mapstructure:"concurrency"` // summaries generated at once after a collection
+	LLM          summarizerLLM `mapstructure:"llm"`
+}
+
+type summarizerLLM struct {
+	Endpoint string `mapstructure:"endpoint"` // OpenAI-compatible chat completions URL
+	APIKey   string `mapstructure:"apiKey" secret:"true"`
+	Model    string `mapstructure:"model"`
+	Timeout  int    `mapstructure:"timeout"` // in seconds
+}
+
+type collector struct {
+	// UpdateExisting refreshes title and image of already stored news on re-collection
+	UpdateExisting bool `mapstructure:"updateExisting"`
+}
+
+// jobs configures the workers running queued background jobs
+type jobs struct {
+	Workers       int `mapstructure:"workers"`       // jobs an instance runs at once, 0 leaves the queue to other instances
+	PollInterval  int `mapstructure:"pollInterval"`  // in seconds, how often workers look for due jobs
+	MaxAttempts   int `mapstructure:"maxAttempts"`   // before a job is marked failed; webhook deliveries use webhook.maxAttempts
+	Backoff       int `mapstructure:"backoff"`       // in seconds before the first retry, doubled after each
+	Lease         int `mapstructure:"lease"`         // in seconds, after which a job whose worker died is run again
+	RetentionDays int `mapstructure:"retentionDays"` // finished jobs are removed by the retention job after this
+	// IdempotencyWindow is how long, in seconds, an /internal response is replayed to requests
+	// repeating its Idempotency-Key
+	IdempotencyWindow int `mapstructure:"idempotencyWindow"`
+}
+
+// retention configures what the retention job removes besides archiving expired news
+type retention struct {
+	// ArchiveMonths is how many months archived news are kept, with their bookmarks, reads and
+	// tags; 0 keeps them for good
+	ArchiveMonths int `mapstructure:"archiveMonths"`
+}
+
+// stream configures the live news streams
+type stream struct {
+	KeepAlive  int `mapstructure:"keepAlive"`  // seconds between keep-alive messages, 0 disables
+	BufferSize int `mapstructure:"bufferSize"` // events queued per client before it misses some
+}
+
+// webSocket limits the /ws news subscriptions; events are buffered per client like the
+// other streams (stream.bufferSize)
+type webSocket struct {
+	MaxConnections  int   `mapstructure:"maxConnections"`  // per instance, 0 means unlimited
+	MaxMessageBytes int64 `mapstructure:"maxMessageBytes"` // largest message a client may send
+	MaxFilterSize   int   `mapstructure:"maxFilterSize"`   // sources plus tags in one subscription
+	PingInterval    int   `mapstructure:"pingInterval"`    // in seconds, clients must answer within two intervals
+	WriteTimeout    int   `mapstructure:"writeTimeout"`    // in seconds, slower clients are disconnected
+	// AllowedOrigins are browser origins allowed to connect besides the API's own host,
+	// "*" allows every origin. Clients that send no Origin (apps) are always allowed
+	AllowedOrigins []string `mapstructure:"allowedOrigins"`
+}
+
+// webhook configures deliveries to the URLs registered under /backoffice/webhooks and the
+// posts of webhook notification rules
+type webhook struct {
+	Timeout       int `mapstructure:"timeout"`       // in seconds, per attempt
+	MaxAttempts   int `mapstructure:"maxAttempts"`   // before a delivery goes to the dead-letter log
+	Backoff       int `mapstructure:"backoff"`       // in seconds before the first retry, doubled after each
+	BatchSize     int `mapstructure:"batchSize"`     // news items per delivery
+	RatePerMinute int `mapstructure:"ratePerMinute"` // notification rule messages per URL, 0 disables the limit
+}
+
+type search struct {
+	// UseOpenSearch serves /news/search from OpenSearch, falling back to Postgres when
+	// it fails; news are mirrored whenever openSearch.url is set
+	UseOpenSearch bool       `mapstructure:"useOpenSearch"`
+	OpenSearch    openSearch `mapstructure:"openSearch"`
+}
+
+type openSearch struct {
+	URL      string `mapstructure:"url"` // empty disables indexing
+	Index    string `mapstructure:"index"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password" secret:"true"`
+	Timeout  int    `mapstructure:"timeout"` // in seconds
+}
+
+// notify holds settings shared by every notification rule channel
+type notify struct {
+	MaxItemsPerRule int `mapstructure:"maxItemsPerRule"` // per collection, so a busy rule can't flood a chat
+}
+
+// line configures the LINE notification channels
+type line struct {
+	ChannelAccessToken string `mapstructure:"channelAccessToken" secret:"true"` // of the official account, for line_messaging rules
+	MessagingEndpoint  string `mapstructure:"messagingEndpoint"`
+	NotifyEndpoint     string `mapstructure:"notifyEndpoint"`
+	Timeout            int    `mapstructure:"timeout"`       // in seconds
+	RatePerMinute      int    `mapstructure:"ratePerMinute"` // messages per target, 0 disables the limit
+}
+
+// telegram configures the bot that posts telegram rules to channels and groups
+type telegram struct {
+	BotToken      string `mapstructure:"botToken" secret:"true"`
+	Endpoint      string `mapstructure:"endpoint"`
+	Timeout       int    `mapstructure:"timeout"`       // in seconds
+	RatePerMinute int    `mapstructure:"ratePerMinute"` // messages per chat, 0 disables the limit
+}
+
+// discord configures posting discord rules to their webhook URLs
+type discord struct {
+	Timeout       int `mapstructure:"timeout"`       // in seconds
+	RatePerMinute int `mapstructure:"ratePerMinute"` // messages per webhook, 0 disables the limit
+}
+
+// fcm configures Firebase Cloud Messaging push notifications
+type fcm struct {
+	// CredentialsFile is a Google service account JSON key; push is disabled without it
+	CredentialsFile   string `mapstructure:"credentialsFile"`
+	Endpoint          string `mapstructure:"endpoint"`
+	Timeout           int    `mapstructure:"timeout"` // in seconds
+	MaxAttempts       int    `mapstructure:"maxAttempts"`
+	Backoff           int    `mapstructure:"backoff"`           // in seconds, doubled after each retry
+	PollInterval      int    `mapstructure:"pollInterval"`      // in seconds, how often workers look for due jobs
+	BatchSize         int    `mapstructure:"batchSize"`         // jobs a worker claims at once
+	MaxItemsPerDevice int    `mapstructure:"maxItemsPerDevice"` // per collection, so a device isn't flooded
+}
+
+// personalization scores items as recency × source affinity × topic affinity
+type personalization struct {
+	RecencyHalfLife int     `mapstructure:"recencyHalfLife"` // in hours, age at which recency halves
+	HistorySize     int     `mapstructure:"historySize"`     // latest reads the affinities are learned from
+	HistoryWeight   float64 `mapstructure:"historyWeight"`   // affinity added by a source or tag all reads went to
+	FollowBoost     float64 `mapstructure:"followBoost"`     // affinity added by a followed source or tag
+	CacheTTL        int     `mapstructure:"cacheTTL"`        // in seconds, how long a reader's affinities are reused
+}
+
+// clustering puts a new item in the story of the most similar title of another source
+type clustering struct {
+	Enabled       bool    `mapstructure:"enabled"`
+	Window        int     `mapstructure:"window"`        // in hours, either side of the item's publish date
+	MinSimilarity float64 `mapstructure:"minSimilarity"` // trigram similarity of the titles, from 0 to 1
+}
+
+// rateLimit throttles public routes per reader (account or device profile) and per client IP,
+// blocking clients that keep going over the limit for a while
+type rateLimit struct {
+	Enabled        bool `mapstructure:"enabled"`
+	PerReader      int  `mapstructure:"perReader"`      // requests per minute per account or device, 0 disables the limit
+	PerIP          int  `mapstructure:"perIP"`          // requests per minute per client IP, 0 disables the limit
+	BlockThreshold int  `mapstructure:"blockThreshold"` // minutes over the limit within an hour before a block, 0 never blocks
+	BlockDuration  int  `mapstructure:"blockDuration"`  // in minutes
+	// TrustedProxies is how many proxies in front of the server append to X-Forwarded-For; the
+	// client IP is the entry the outermost one added. 0 uses the address of the connection
+	TrustedProxies int `mapstructure:"trustedProxies"`
+}
+
+// sourceSuggestion limits what readers may suggest and where reviewers hear about it
+type sourceSuggestion struct {
+	MaxPendingPerReader int    `mapstructure:"maxPendingPerReader"` // 0 disables the limit
+	NotifyChannel       string `mapstructure:"notifyChannel"`       // a notification rule channel, empty disables
+	NotifyTarget        string `mapstructure:"notifyTarget"`        // in the channel's target format
+}
+
+// savedSearch limits how many searches a reader saves and how loud a single collection gets
+type savedSearch struct {
+	MaxPerReader      int `mapstructure:"maxPerReader"`      // 0 disables the limit
+	MaxItemsPerSearch int `mapstructure:"maxItemsPerSearch"` // new matches notified per search and collection
+}
+
+// sentry reports errors of the listed AppError types and recovered panics; reporting is
+// disabled without a DSN
+type sentry struct {
+	DSN         string   `mapstructure:"dsn" secret:"true"`
+	Environment string   `mapstructure:"environment"`
+	Release     string   `mapstructure:"release"`
+	SampleRate  float64  `mapstructure:"sampleRate"` // share of errors sent, from 0 to 1
+	Types       []string `mapstructure:"types"`      // AppError types reported, such as DATABASE_ERROR
+}
+
+// secretStore resolves the password references with Provider: env uses the passwords as
+// configured, file reads them from the referenced paths, vault and aws from those stores.
+// POSTGRES_PASSWORD_FILE and REDIS_PASSWORD_FILE are read whatever the provider
+type secretStore struct {
+	Provider         string           `mapstructure:"provider"`
+	PostgresPassword string           `mapstructure:"postgresPassword"` // reference in the provider's format
+	RedisPassword    string           `mapstructure:"redisPassword"`    // reference in the provider's format
+	Timeout          int              `mapstructure:"timeout"`          // in seconds, for all lookups together
+	Vault            secretStoreVault `mapstructure:"vault"`
+	AWS              secretStoreAWS   `mapstructure:"aws"`
+}
+
+// secretStoreVault reads a KV version 2 engine; VAULT_ADDR and VAULT_TOKEN are used when unset
+type secretStoreVault struct {
+	Address   string `mapstructure:"address"`
+	Token     string `mapstructure:"token" secret:"true"`
+	TokenFile string `mapstructure:"tokenFile"`
+	Namespace string `mapstructure:"namespace"`
+	Mount     string `mapstructure:"mount"`
+}
+
+// secretStoreAWS reads AWS Secrets Manager; the SDK's default credential chain is used
+// when the keys are unset
+type secretStoreAWS struct {
+	Region          string `mapstructure:"region"`
+	AccessKeyID     string `mapstructure:"accessKeyId"`
+	SecretAccessKey string `mapstructure:"secretAccessKey" secret:"true"`
+	SessionToken    string `mapstructure:"sessionToken" secret:"true"`
+	Endpoint        string `mapstructure:"endpoint"`
+}
+
+// current is swapped as a whole on every reload, so a *Config read once stays consistent
+var current atomic.Pointer[Config]
 
 func Init(ctx context.Context, configPath string) error {
-	var err error
-	config, err = LoadConfig(ctx, configPath)
-	return err
+	cfg, err := LoadConfig(ctx, configPath)
+	if err != nil {
+		return err
+	}
+
+	// every problem is logged before refusing to start, so they can be fixed in one go
+	problems := cfg.Validate()
+	problems.Log()
+	if err := problems.Err(); err != nil {
+		return err
+	}
+	current.Store(cfg)
+	return nil
 }
 
 func LoadConfig(ctx context.Context, configPath string) (*Config, error) {
 	// Set up environment variable support
-	// setEnvKeyReplacer allows nested config keys (like restServer.port) 
+	// setEnvKeyReplacer allows nested config keys (like restServer.port)
 	// to be overridden by environment variables (REST_SERVER_PORT)
 	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	viper.AutomaticEnv() // Enable automatic environment variable binding
-	
+
 	// Set reasonable defaults
 	setDefaults()
 
-	// Read configuration file if provided
+	// Read configuration file if provided, with the APP_ENV overlay merged over it
+	basePath = configPath
+	var files []string
 	if configPath != "" {
-		viper.SetConfigFile(configPath)
-		if err := viper.ReadInConfig(); err != nil {
-			// Don't fail if config file is missing - env vars and defaults will be used
-			// This allows for container deployments with only env vars
+		// Don't fail if config file is missing - env vars and defaults will be used
+		// This allows for container deployments with only env vars
+		var err error
+		files, err = readConfigFiles()
+		if err != nil {
+			return nil, err
+		}
+		paths := configPaths()
+		if len(paths) > 1 && !slices.Contains(files, paths[1]) {
+			slog.Warn("Config overlay not found, using the base configuration", "env", os.Getenv(AppEnvVar), "path", paths[1])
+		}
+		if len(files) > 0 {
+			slog.Info("Configuration files read", "files", files)
 		}
 	}
 
@@ -88,29 +545,89 @@ func LoadConfig(ctx context.Context, configPath string) (*Config, error) {
 	if err := viper.Unmarshal(&cfg); err != nil {
 		return nil, err
 	}
+	cfg.files = files
+	if err := resolveSecrets(ctx, &cfg); err != nil {
+		return nil, err
+	}
 
 	return &cfg, nil
 }
 
 func setDefaults() {
+	// Storage defaults
+	viper.SetDefault("storage.driver", "postgres")
+
+	// Startup defaults
+	viper.SetDefault("startup.policy", StartupPolicyFailFast)
+
+	// Dependency health check defaults
+	viper.SetDefault("healthCheck.interval", 15) // 15 seconds
+	viper.SetDefault("healthCheck.failureThreshold", 3)
+
+	// Auth defaults
+	viper.SetDefault("auth.enabled", true)
+	viper.SetDefault("auth.adminKeyHashes", []string{}) // registers the key so it can be set from the environment
+	viper.SetDefault("auth.jwt.accessTokenTTL", 15)     // 15 minutes
+	viper.SetDefault("auth.jwt.refreshTokenTTL", 168)   // 7 days
+	viper.SetDefault("auth.jwt.secret", "")             // registers the key; logins are disabled until it is provided
+	viper.SetDefault("auth.oauth.google.clientIds", []string{})
+	viper.SetDefault("auth.oauth.google.jwksUrl", "https://www.googleapis.com/oauth2/v3/certs")
+	viper.SetDefault("auth.oauth.apple.clientIds", []string{})
+	viper.SetDefault("auth.oauth.apple.jwksUrl", "https://appleid.apple.com/auth/keys")
+
 	// Server defaults
+	viper.SetDefault("restServer.host", "")
 	viper.SetDefault("restServer.port", 8080)
+	viper.SetDefault("restServer.socket", "")
+	viper.SetDefault("restServer.socketMode", "0660")
+	viper.SetDefault("restServer.maxBodyBytes", 1<<20) // 1 MiB
+	viper.SetDefault("restServer.disallowUnknownFields", false)
+	viper.SetDefault("restServer.timeout.default", 15)   // 15 seconds
+	viper.SetDefault("restServer.timeout.internal", 300) // 5 minutes
+	viper.SetDefault("restServer.legacySunset", "")
+	viper.SetDefault("restServer.debug", false)
+
+	// Logging defaults
+	viper.SetDefault("log.level", "info")
+	viper.SetDefault("log.levels", map[string]string{})
+	viper.SetDefault("log.sampling", map[string]int{})
+	viper.SetDefault("log.format", "json")
+	viper.SetDefault("log.output", "stdout")
+	viper.SetDefault("log.errorsToStderr", false)
+	viper.SetDefault("log.file.path", "")
+	viper.SetDefault("log.file.maxSize", 100) // 100 MB
+	viper.SetDefault("log.file.maxBackups", 5)
+	viper.SetDefault("log.file.maxAge", 30) // 30 days
+	viper.SetDefault("log.file.compress", true)
+	viper.SetDefault("log.maxBodyBytes", 2048) // 2 KiB
+	viper.SetDefault("log.redactFields", []string{"password", "refreshToken", "accessToken", "apiKey", "key", "token", "secret", "authorization"})
+	viper.SetDefault("log.redactAttrs", []string{"password", "token", "refreshToken", "accessToken", "apiKey", "secret", "authorization", "cookie"})
+	viper.SetDefault("log.bodySampleRate", 1.0)
 
 	// Database connection defaults (not credentials)
 	viper.SetDefault("postgres.host", "localhost")
 	viper.SetDefault("postgres.port", 5432)
 	// Note: No defaults for user, password, dbname - these must be provided
+	viper.SetDefault("postgres.sslMode", "disable")
+	viper.SetDefault("postgres.migrateOnStartup", false)
+	viper.SetDefault("postgres.queryTimeout", 10)     // 10 seconds
+	viper.SetDefault("postgres.statementTimeout", 60) // 60 seconds
+	viper.SetDefault("postgres.retry.maxAttempts", 3)
+	viper.SetDefault("postgres.retry.backoff", 100) // 100 milliseconds
+
+	viper.SetDefault("postgres.slowQuery.threshold", 500) // 500 milliseconds
+	viper.SetDefault("postgres.slowQuery.logArgs", true)
 
 	// PostgreSQL Pool defaults
 	viper.SetDefault("postgres.pool.maxConns", 25)
 	viper.SetDefault("postgres.pool.minConns", 5)
-	viper.SetDefault("postgres.pool.maxConnLifetime", 60)      // 1 hour
-	viper.SetDefault("postgres.pool.maxConnIdleTime", 30)      // 30 minutes
-	viper.SetDefault("postgres.pool.healthCheckPeriod", 1)     // 1 minute
-	viper.SetDefault("postgres.pool.connectTimeout", 5)        // 5 seconds
+	viper.SetDefault("postgres.pool.maxConnLifetime", 60)  // 1 hour
+	viper.SetDefault("postgres.pool.maxConnIdleTime", 30)  // 30 minutes
+	viper.SetDefault("postgres.pool.healthCheckPeriod", 1) // 1 minute
+	viper.SetDefault("postgres.pool.connectTimeout", 5)    // 5 seconds
 
 	// Redis connection defaults (not password)
-	viper.SetDefault("redis.host", "localhost") 
+	viper.SetDefault("redis.host", "localhost")
 	viper.SetDefault("redis.port", 6379)
 	// Note: No default for password - it must be provided if required
 
@@ -118,19 +635,177 @@ func setDefaults() {
 	viper.SetDefault("redis.pool.poolSize", 15)
 	viper.SetDefault("redis.pool.minIdleConns", 5)
 	viper.SetDefault("redis.pool.maxIdleConns", 10)
-	viper.SetDefault("redis.pool.poolTimeout", 4)              // 4 seconds
-	viper.SetDefault("redis.pool.idleTimeout", 5)              // 5 minutes
-	viper.SetDefault("redis.pool.maxConnAge", 30)              // 30 minutes
-	viper.SetDefault("redis.pool.dialTimeout", 5)              // 5 seconds
-	viper.SetDefault("redis.pool.readTimeout", 3)              // 3 seconds
-	viper.SetDefault("redis.pool.writeTimeout", 3)             // 3 seconds
+	viper.SetDefault("redis.pool.poolTimeout", 4)  // 4 seconds
+	viper.SetDefault("redis.pool.idleTimeout", 5)  // 5 minutes
+	viper.SetDefault("redis.pool.maxConnAge", 30)  // 30 minutes
+	viper.SetDefault("redis.pool.dialTimeout", 5)  // 5 seconds
+	viper.SetDefault("redis.pool.readTimeout", 3)  // 3 seconds
+	viper.SetDefault("redis.pool.writeTimeout", 3) // 3 seconds
 	viper.SetDefault("redis.pool.maxRetries", 2)
-	viper.SetDefault("redis.pool.minRetryBackoff", 8)          // 8 milliseconds
-	viper.SetDefault("redis.pool.maxRetryBackoff", 512)        // 512 milliseconds
+	viper.SetDefault("redis.pool.minRetryBackoff", 8)   // 8 milliseconds
+	viper.SetDefault("redis.pool.maxRetryBackoff", 512) // 512 milliseconds
+
+	// Cache defaults
+	viper.SetDefault("cache.news.ttl", 600)     // 10 minutes
+	viper.SetDefault("cache.tags.ttl", 3600)    // 1 hour
+	viper.SetDefault("cache.sources.ttl", 3600) // 1 hour
+	viper.SetDefault("cache.emptyTTL", 30)      // 30 seconds
+	viper.SetDefault("cache.encoding", "json")
+	viper.SetDefault("cache.compression", "none")
+	viper.SetDefault("cache.compressMinBytes", 1024) // 1 KiB
+
+	// Output feed defaults
+	viper.SetDefault("feed.title", "OneFeed")
+	viper.SetDefault("feed.description", "รวมข่าวล่าสุดจากทุกสำนักข่าว")
+	viper.SetDefault("feed.link", "https://onefeed.in.th")
+	viper.SetDefault("feed.limit", 50)
+
+	// Sitemap defaults
+	viper.SetDefault("sitemap.enabled", false)
+	viper.SetDefault("sitemap.news", false)
+	viper.SetDefault("sitemap.baseUrl", "https://onefeed.in.th")
+	viper.SetDefault("sitemap.articlePath", "/news/{id}")
+	viper.SetDefault("sitemap.interval", 3600) // 1 hour
+	viper.SetDefault("sitemap.maxUrls", 10000)
+	viper.SetDefault("sitemap.publicationName", "OneFeed")
+	viper.SetDefault("sitemap.language", "th")
+
+	// Summarizer defaults
+	viper.SetDefault("summarizer.provider", "extractive")
+	viper.SetDefault("summarizer.maxSentences", 3)
+	viper.SetDefault("summarizer.concurrency", 4)
+	viper.SetDefault("summarizer.llm.endpoint", "https://api.openai.com/v1/chat/completions")
+	viper.SetDefault("summarizer.llm.model", "gpt-4o-mini")
+	viper.SetDefault("summarizer.llm.timeout", 15) // 15 seconds
+	// Note: No default for summarizer.llm.apiKey - it must be provided for the llm provider
+
+	// Collector defaults
+	viper.SetDefault("collector.updateExisting", false)
+
+	// Job defaults
+	viper.SetDefault("jobs.workers", 4)
+	viper.SetDefault("jobs.pollInterval", 2) // 2 seconds
+	viper.SetDefault("jobs.maxAttempts", 3)
+	viper.SetDefault("jobs.backoff", 30) // 30 seconds
+	viper.SetDefault("jobs.lease", 900)  // 15 minutes
+	viper.SetDefault("jobs.retentionDays", 7)
+	viper.SetDefault("jobs.idempotencyWindow", 86400) // 24 hours
+
+	// Retention defaults
+	viper.SetDefault("retention.archiveMonths", 0) // archived news are kept
+
+	// Stream defaults
+	viper.SetDefault("stream.keepAlive", 15) // 15 seconds
+	viper.SetDefault("stream.bufferSize", 32)
+
+	// WebSocket defaults
+	viper.SetDefault("webSocket.maxConnections", 1000)
+	viper.SetDefault("webSocket.maxMessageBytes", 4096)
+	viper.SetDefault("webSocket.maxFilterSize", 50)
+	viper.SetDefault("webSocket.pingInterval", 30) // 30 seconds
+	viper.SetDefault("webSocket.writeTimeout", 10) // 10 seconds
+	viper.SetDefault("webSocket.allowedOrigins", []string{})
+
+	// Webhook defaults
+	viper.SetDefault("webhook.timeout", 10) // 10 seconds
+	viper.SetDefault("webhook.maxAttempts", 3)
+	viper.SetDefault("webhook.backoff", 2) // 2 seconds
+	viper.SetDefault("webhook.batchSize", 100)
+	viper.SetDefault("webhook.ratePerMinute", 60)
+
+	// Search defaults
+	viper.SetDefault("search.useOpenSearch", false)
+	viper.SetDefault("search.openSearch.url", "") // registers the key; indexing is disabled until it is provided
+	viper.SetDefault("search.openSearch.index", "onefeed-news")
+	viper.SetDefault("search.openSearch.username", "")
+	viper.SetDefault("search.openSearch.password", "")
+	viper.SetDefault("search.openSearch.timeout", 10) // 10 seconds
+
+	// Notification rule defaults
+	viper.SetDefault("notify.maxItemsPerRule", 5)
+
+	// LINE defaults
+	viper.SetDefault("line.channelAccessToken", "") // registers the key; line_messaging rules fail until it is provided
+	viper.SetDefault("line.messagingEndpoint", "https://api.line.me")
+	viper.SetDefault("line.notifyEndpoint", "https://notify-api.line.me/api/notify")
+	viper.SetDefault("line.timeout", 10) // 10 seconds
+	viper.SetDefault("line.ratePerMinute", 60)
+
+	// Telegram defaults
+	viper.SetDefault("telegram.botToken", "") // registers the key; telegram rules fail until it is provided
+	viper.SetDefault("telegram.endpoint", "https://api.telegram.org")
+	viper.SetDefault("telegram.timeout", 10)       // 10 seconds
+	viper.SetDefault("telegram.ratePerMinute", 20) // Telegram's limit for a single group or channel
+
+	// Discord defaults
+	viper.SetDefault("discord.timeout", 10)       // 10 seconds
+	viper.SetDefault("discord.ratePerMinute", 30) // Discord's limit for a single webhook
+
+	// FCM defaults
+	viper.SetDefault("fcm.credentialsFile", "") // registers the key; push notifications are disabled until it is provided
+	viper.SetDefault("fcm.endpoint", "https://fcm.googleapis.com")
+	viper.SetDefault("fcm.timeout", 10) // 10 seconds
+	viper.SetDefault("fcm.maxAttempts", 3)
+	viper.SetDefault("fcm.backoff", 30)     // 30 seconds
+	viper.SetDefault("fcm.pollInterval", 2) // 2 seconds
+	viper.SetDefault("fcm.batchSize", 100)
+	viper.SetDefault("fcm.maxItemsPerDevice", 3)
+
+	// Personalization defaults
+	viper.SetDefault("personalization.recencyHalfLife", 12) // 12 hours
+	viper.SetDefault("personalization.historySize", 200)
+	viper.SetDefault("personalization.historyWeight", 2.0)
+	viper.SetDefault("personalization.followBoost", 1.0)
+	viper.SetDefault("personalization.cacheTTL", 300) // 5 minutes
+
+	// Clustering defaults
+	viper.SetDefault("clustering.enabled", true)
+	viper.SetDefault("clustering.window", 24) // 24 hours
+	viper.SetDefault("clustering.minSimilarity", 0.5)
+
+	// Rate limit defaults
+	viper.SetDefault("rateLimit.enabled", true)
+	viper.SetDefault("rateLimit.perReader", 120)
+	viper.SetDefault("rateLimit.perIP", 600)
+	viper.SetDefault("rateLimit.blockThreshold", 5)
+	viper.SetDefault("rateLimit.blockDuration", 60) // 1 hour
+	viper.SetDefault("rateLimit.trustedProxies", 0)
+
+	// Source suggestion defaults
+	viper.SetDefault("sourceSuggestion.maxPendingPerReader", 5)
+	viper.SetDefault("sourceSuggestion.notifyChannel", "") // registers the key; reviewers aren't notified until it is provided
+	viper.SetDefault("sourceSuggestion.notifyTarget", "")
+
+	// Saved search defaults
+	viper.SetDefault("savedSearch.maxPerReader", 20)
+	viper.SetDefault("savedSearch.maxItemsPerSearch", 3)
+
+	// Sentry defaults
+	viper.SetDefault("sentry.dsn", "") // registers the key; errors aren't reported until it is provided
+	viper.SetDefault("sentry.environment", "production")
+	viper.SetDefault("sentry.release", "")
+	viper.SetDefault("sentry.sampleRate", 1.0)
+	viper.SetDefault("sentry.types", []string{"DATABASE_ERROR", "INTERNAL_ERROR"})
+
+	// Secrets defaults
+	viper.SetDefault("secrets.provider", "env")
+	viper.SetDefault("secrets.postgresPassword", "") // registers the key; the configured password is used until it is provided
+	viper.SetDefault("secrets.redisPassword", "")
+	viper.SetDefault("secrets.timeout", 10) // 10 seconds
+	viper.SetDefault("secrets.vault.address", "")
+	viper.SetDefault("secrets.vault.token", "")
+	viper.SetDefault("secrets.vault.tokenFile", "")
+	viper.SetDefault("secrets.vault.namespace", "")
+	viper.SetDefault("secrets.vault.mount", "secret")
+	viper.SetDefault("secrets.aws.region", "")
+	viper.SetDefault("secrets.aws.accessKeyId", "")
+	viper.SetDefault("secrets.aws.secretAccessKey", "")
+	viper.SetDefault("secrets.aws.sessionToken", "")
+	viper.SetDefault("secrets.aws.endpoint", "")
 }
 
 func GetConfig() *Config {
-	return config
+	return current.Load()
 }
 
 // ResolveConfigFromFile exists for backward compatibility
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// AppEnvVar selects the overlay merged over the base config file, such as staging for
+// config/config.staging.yaml
+const AppEnvVar = "APP_ENV"
+
+// basePath is the config file LoadConfig was given, empty when only env vars are used
+var basePath string
+
+// configPaths returns the base config file and, when APP_ENV is set, its overlay
+func configPaths() []string {
+	if basePath == "" {
+		return nil
+	}
+	paths := []string{filepath.Clean(basePath)}
+	if env := os.Getenv(AppEnvVar); env != "" {
+		ext := filepath.Ext(basePath)
+		paths = append(paths, filepath.Clean(strings.TrimSuffix(basePath, ext)+"."+env+ext))
+	}
+	return paths
+}
+
+// readConfigFiles reads the base file and deep-merges the overlay over it: maps are merged
+// key by key, while scalars and lists in the overlay replace the base ones. Missing files
+// are skipped, so deployments can rely on env vars alone. It returns the files read
+func readConfigFiles() ([]string, error) {
+	viper.SetConfigFile(basePath)
+
+	var read []string
+	for _, path := range configPaths() {
+		data, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if len(read) == 0 {
+			err = viper.ReadConfig(bytes.NewReader(data))
+		} else {
+			err = viper.MergeConfig(bytes.NewReader(data))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		read = append(read, path)
+	}
+	return read, nil
+}
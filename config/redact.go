@@ -0,0 +1,87 @@
+package config
+
+import (
+	"os"
+	"reflect"
+	"strings"
+)
+
+// redactedValue replaces every configured secret in Redacted; unset secrets stay empty so
+// it still shows whether one is set
+const redactedValue = "********"
+
+// Redacted returns the configuration keyed like config.yaml, with the fields tagged
+// secret:"true" masked
+func (c *Config) Redacted() map[string]any {
+	return redactStruct(reflect.ValueOf(*c))
+}
+
+// Files returns the config files the configuration was read from, the base file first
+func (c *Config) Files() []string {
+	return c.files
+}
+
+// EnvOverrides returns the keys set by an environment variable, which take precedence over
+// the config files
+func EnvOverrides() []string {
+	var keys []string
+	for _, key := range leafKeys(reflect.TypeOf(Config{}), "") {
+		// viper looks keys up upper-cased with the dots replaced, as set in LoadConfig
+		if _, ok := os.LookupEnv(strings.ToUpper(strings.ReplaceAll(key, ".", "_"))); ok {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func redactStruct(v reflect.Value) map[string]any {
+	out := make(map[string]any, v.NumField())
+	for i := range v.NumField() {
+		field := v.Type().Field(i)
+		key := field.Tag.Get("mapstructure")
+		if key == "" {
+			continue
+		}
+		out[key] = redactValue(v.Field(i), field.Tag.Get("secret") == "true")
+	}
+	return out
+}
+
+func redactValue(v reflect.Value, secret bool) any {
+	switch {
+	case secret && (v.IsZero() || v.Kind() == reflect.Slice && v.Len() == 0):
+		return ""
+	case secret:
+		return redactedValue
+	case v.Kind() == reflect.Struct:
+		return redactStruct(v)
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Struct:
+		items := make([]any, v.Len())
+		for i := range v.Len() {
+			items[i] = redactStruct(v.Index(i))
+		}
+		return items
+	default:
+		return v.Interface()
+	}
+}
+
+// leafKeys lists the dotted keys of the settings in t, skipping lists of sections
+func leafKeys(t reflect.Type, prefix string) []string {
+	var keys []string
+	for i := range t.NumField() {
+		key := t.Field(i).Tag.Get("mapstructure")
+		if key == "" {
+			continue
+		}
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		if t.Field(i).Type.Kind() == reflect.Struct {
+			keys = append(keys, leafKeys(t.Field(i).Type, key)...)
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
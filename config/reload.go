@@ -0,0 +1,193 @@
+package config
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// fileChangeDelay lets an editor or a ConfigMap update finish writing before the files are read
+const fileChangeDelay = 200 * time.Millisecond
+
+var (
+	reloadMu sync.Mutex
+	hooks    []func(old, cfg *Config)
+)
+
+// OnChange registers fn to run after every reload that changed a setting, with the
+// settings before and after it, so a subsystem that copied settings at startup can pick up
+// the new ones
+func OnChange(fn func(old, cfg *Config)) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+	hooks = append(hooks, fn)
+}
+
+// Watch reloads the configuration when the config file or its overlay changes and when the
+// process gets SIGHUP, until ctx is done
+func Watch(ctx context.Context) {
+	if paths := configPaths(); len(paths) > 0 {
+		watchFiles(ctx, paths)
+	}
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		defer signal.Stop(hup)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hup:
+				Reload("SIGHUP")
+			}
+		}
+	}()
+}
+
+// watchFiles watches the directories of paths rather than the files, so files replaced by
+// an editor or a Kubernetes ConfigMap update (which swaps the ..data symlink) are noticed
+func watchFiles(ctx context.Context, paths []string) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		slog.Warn("Failed to watch config files, only SIGHUP reloads them", "error", err)
+		return
+	}
+	for _, path := range paths {
+		if err := watcher.Add(filepath.Dir(path)); err != nil {
+			slog.Warn("Failed to watch config directory", "path", filepath.Dir(path), "error", err)
+		}
+	}
+
+	go func() {
+		defer watcher.Close()
+		var pending *time.Timer
+		for {
+			select {
+			case <-ctx.Done():
+				if pending != nil {
+					pending.Stop()
+				}
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				name := filepath.Clean(event.Name)
+				if !slices.Contains(paths, name) && filepath.Base(name) != "..data" {
+					continue
+				}
+				if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) {
+					continue
+				}
+				if pending == nil {
+					pending = time.AfterFunc(fileChangeDelay, func() { Reload("file changed") })
+				} else {
+					pending.Reset(fileChangeDelay)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				slog.Warn("Config file watcher failed", "error", err)
+			}
+		}
+	}()
+}
+
+// Reload reads the config files again and applies them. Settings only read at startup keep
+// their running values until a restart, and are logged when they changed
+func Reload(reason string) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	var files []string
+	if basePath != "" {
+		var err error
+		if files, err = readConfigFiles(); err != nil {
+			slog.Error("Failed to read config file, keeping the current configuration", "reason", reason, "error", err)
+			return
+		}
+	}
+	var cfg Config
+	if err := viper.Unmarshal(&cfg); err != nil {
+		slog.Error("Failed to reload configuration, keeping the current one", "reason", reason, "error", err)
+		return
+	}
+	cfg.files = files
+	old := current.Load()
+	if err := resolveSecrets(context.Background(), &cfg); err != nil {
+		// passwords only change on restart, so an unreachable secrets store doesn't block the reload
+		slog.Warn("Failed to resolve secrets on reload, keeping the current passwords", "error", err)
+		cfg.Postgres.Password, cfg.Redis.Password = old.Postgres.Password, old.Redis.Password
+	}
+	pending := keepStartupSettings(old, &cfg)
+	problems := cfg.Validate()
+	problems.Log()
+	if err := problems.Err(); err != nil {
+		slog.Error("Invalid configuration, keeping the current one", "reason", reason)
+		return
+	}
+	if len(pending) > 0 {
+		slog.Warn("Changed settings only apply after a restart", "settings", pending)
+	}
+	if reflect.DeepEqual(old, &cfg) {
+		slog.Info("Configuration reloaded without changes", "reason", reason)
+		return
+	}
+
+	current.Store(&cfg)
+	slog.Info("Configuration reloaded", "reason", reason)
+	for _, hook := range hooks {
+		hook(old, &cfg)
+	}
+}
+
+// keepStartupSettings copies the settings that connections, routes and clients were built
+// from at startup from old into cfg, and returns the ones that changed
+func keepStartupSettings(old, cfg *Config) []string {
+	var changed []string
+	keep(&changed, "storage", old.Storage, &cfg.Storage)
+	keep(&changed, "startup", old.Startup, &cfg.Startup)
+	keep(&changed, "healthCheck", old.HealthCheck, &cfg.HealthCheck)
+	keep(&changed, "auth", old.Auth, &cfg.Auth)
+	keep(&changed, "restServer", old.RestServer, &cfg.RestServer)
+	keep(&changed, "postgres", old.Postgres, &cfg.Postgres)
+	keep(&changed, "redis", old.Redis, &cfg.Redis)
+	keep(&changed, "summarizer", old.Summarizer, &cfg.Summarizer)
+	keep(&changed, "jobs.workers", old.Jobs.Workers, &cfg.Jobs.Workers)
+	keep(&changed, "webhook", old.Webhook, &cfg.Webhook)
+	keep(&changed, "search", old.Search, &cfg.Search)
+	keep(&changed, "line", old.Line, &cfg.Line)
+	keep(&changed, "telegram", old.Telegram, &cfg.Telegram)
+	keep(&changed, "discord", old.Discord, &cfg.Discord)
+	keep(&changed, "fcm", old.FCM, &cfg.FCM)
+	keep(&changed, "secrets", old.Secrets, &cfg.Secrets)
+	// the log handlers are built once, loggers derived from them keep their output
+	keep(&changed, "log.format", old.Log.Format, &cfg.Log.Format)
+	keep(&changed, "log.output", old.Log.Output, &cfg.Log.Output)
+	keep(&changed, "log.errorsToStderr", old.Log.ErrorsToStderr, &cfg.Log.ErrorsToStderr)
+	keep(&changed, "log.file", old.Log.File, &cfg.Log.File)
+	// the rate limit middleware is only installed when enabled at startup
+	keep(&changed, "rateLimit.enabled", old.RateLimit.Enabled, &cfg.RateLimit.Enabled)
+	keep(&changed, "rateLimit.trustedProxies", old.RateLimit.TrustedProxies, &cfg.RateLimit.TrustedProxies)
+	return changed
+}
+
+func keep[T any](changed *[]string, name string, old T, value *T) {
+	if reflect.DeepEqual(old, *value) {
+		return
+	}
+	*changed = append(*changed, name)
+	*value = old
+}
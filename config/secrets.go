@@ -0,0 +1,82 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/secrets"
+)
+
+// resolveSecrets replaces the passwords with the ones found in their *_FILE variable or,
+// when a reference is set, in the secrets provider
+func resolveSecrets(ctx context.Context, cfg *Config) error {
+	targets := []struct {
+		key   string
+		env   string
+		ref   string
+		value *string
+	}{
+		{"postgres.password", "POSTGRES_PASSWORD", cfg.Secrets.PostgresPassword, &cfg.Postgres.Password},
+		{"redis.password", "REDIS_PASSWORD", cfg.Secrets.RedisPassword, &cfg.Redis.Password},
+	}
+
+	if cfg.Secrets.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(cfg.Secrets.Timeout)*time.Second)
+		defer cancel()
+	}
+
+	var provider secrets.Provider
+	for _, target := range targets {
+		value, ok, err := secrets.FromFileEnv(target.env)
+		if err != nil {
+			return fmt.Errorf("%s: %w", target.key, err)
+		}
+		if ok {
+			*target.value = value
+			continue
+		}
+		if target.ref == "" || cfg.Secrets.Provider == secrets.ProviderEnv {
+			continue
+		}
+
+		if provider == nil {
+			if provider, err = newSecretsProvider(cfg.Secrets); err != nil {
+				return err
+			}
+		}
+		if *target.value, err = provider.Secret(ctx, target.ref); err != nil {
+			return fmt.Errorf("%s: %w", target.key, err)
+		}
+	}
+	return nil
+}
+
+func newSecretsProvider(cfg secretStore) (secrets.Provider, error) {
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	switch cfg.Provider {
+	case secrets.ProviderFile:
+		return secrets.File{}, nil
+	case secrets.ProviderVault:
+		return secrets.NewVault(secrets.VaultOptions{
+			Address:   cfg.Vault.Address,
+			Token:     cfg.Vault.Token,
+			TokenFile: cfg.Vault.TokenFile,
+			Namespace: cfg.Vault.Namespace,
+			Mount:     cfg.Vault.Mount,
+			Timeout:   timeout,
+		})
+	case secrets.ProviderAWS:
+		return secrets.NewAWS(secrets.AWSOptions{
+			Region:          cfg.AWS.Region,
+			AccessKeyID:     cfg.AWS.AccessKeyID,
+			SecretAccessKey: cfg.AWS.SecretAccessKey,
+			SessionToken:    cfg.AWS.SessionToken,
+			Endpoint:        cfg.AWS.Endpoint,
+			Timeout:         timeout,
+		})
+	default:
+		return nil, fmt.Errorf("secrets.provider %q is not one of env, file, vault, aws", cfg.Provider)
+	}
+}
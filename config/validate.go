@@ -0,0 +1,438 @@
+package config
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Problem is a setting Validate found wrong. Critical problems keep the server from
+// starting, the others are only logged
+type Problem struct {
+	Key      string
+	Message  string
+	Critical bool
+}
+
+func (p Problem) String() string {
+	return p.Key + ": " + p.Message
+}
+
+// Problems are all the problems found in one pass, so they can be fixed at once
+type Problems []Problem
+
+// Log writes every problem, critical ones as errors
+func (p Problems) Log() {
+	for _, problem := range p {
+		if problem.Critical {
+			slog.Error("Invalid configuration", "key", problem.Key, "problem", problem.Message)
+		} else {
+			slog.Warn("Questionable configuration", "key", problem.Key, "problem", problem.Message)
+		}
+	}
+}
+
+// Err returns an error listing the critical problems, or nil when there are none
+func (p Problems) Err() error {
+	var critical []string
+	for _, problem := range p {
+		if problem.Critical {
+			critical = append(critical, problem.String())
+		}
+	}
+	if len(critical) == 0 {
+		return nil
+	}
+	return errors.New("invalid configuration: " + strings.Join(critical, "; "))
+}
+
+// Validate checks ranges, required credentials and settings that only work together. The
+// postgres and redis sections are only checked when they are used
+func (c *Config) Validate() Problems {
+	v := &validator{}
+
+	v.oneOf("storage.driver", c.Storage.Driver, "postgres", StorageDriverMemory)
+	v.oneOf("startup.policy", c.Startup.Policy, StartupPolicyFailFast, StartupPolicyDegraded)
+	v.atLeast("healthCheck.interval", c.HealthCheck.Interval, 0)
+	if c.HealthCheck.Interval > 0 {
+		v.atLeast("healthCheck.failureThreshold", c.HealthCheck.FailureThreshold, 1)
+	}
+
+	v.validateAuth(c.Auth)
+	v.validateRestServer(c.RestServer)
+	if c.RestServer.Debug && !c.Auth.Enabled {
+		v.fail("restServer.debug", "needs auth.enabled, the profiles would be open to anyone")
+	}
+	v.logLevel("log.level", c.Log.Level)
+	for _, module := range slices.Sorted(maps.Keys(c.Log.Levels)) {
+		v.logLevel("log.levels."+module, c.Log.Levels[module])
+	}
+	for _, message := range slices.Sorted(maps.Keys(c.Log.Sampling)) {
+		v.atLeast("log.sampling."+message, c.Log.Sampling[message], 1)
+	}
+	v.oneOf("log.format", c.Log.Format, "json", "text")
+	v.oneOf("log.output", c.Log.Output, "stdout", "stderr", "file")
+	if c.Log.Output == "file" {
+		v.required("log.file.path", c.Log.File.Path)
+		v.atLeast("log.file.maxSize", c.Log.File.MaxSize, 1)
+		v.atLeast("log.file.maxBackups", c.Log.File.MaxBackups, 0)
+		v.atLeast("log.file.maxAge", c.Log.File.MaxAge, 0)
+	}
+	if c.Log.ErrorsToStderr && c.Log.Output != "stdout" {
+		v.warn("log.errorsToStderr", "only applies to the stdout output")
+	}
+	v.atLeast("log.maxBodyBytes", int(c.Log.MaxBodyBytes), 0)
+	v.share("log.bodySampleRate", c.Log.BodySampleRate)
+
+	if c.Storage.Driver == StorageDriverMemory {
+		if c.Postgres.MigrateOnStartup {
+			v.warn("postgres.migrateOnStartup", "is ignored with the memory storage driver")
+		}
+	} else {
+		v.validatePostgres(c.Postgres)
+		v.validateRedis(c.Redis)
+	}
+
+	v.atLeast("cache.news.ttl", c.Cache.News.TTL, 0)
+	v.atLeast("cache.tags.ttl", c.Cache.Tags.TTL, 0)
+	v.atLeast("cache.sources.ttl", c.Cache.Sources.TTL, 0)
+	v.atLeast("cache.emptyTTL", c.Cache.EmptyTTL, 0)
+	v.oneOf("cache.encoding", c.Cache.Encoding, "json", "msgpack")
+	v.oneOf("cache.compression", c.Cache.Compression, "none", "snappy", "zstd")
+	v.atLeast("cache.compressMinBytes", c.Cache.CompressMinBytes, 0)
+
+	v.atLeast("feed.limit", int(c.Feed.Limit), 1)
+
+	if c.Sitemap.Enabled || c.Sitemap.News {
+		v.required("sitemap.baseUrl", c.Sitemap.BaseURL)
+		if !strings.Contains(c.Sitemap.ArticlePath, "{id}") {
+			v.fail("sitemap.articlePath", "must contain {id}")
+		}
+		v.atLeast("sitemap.interval", c.Sitemap.Interval, 60)
+	}
+	if c.Sitemap.Enabled {
+		v.atLeast("sitemap.maxUrls", c.Sitemap.MaxURLs, 1)
+		if c.Sitemap.MaxURLs > 50000 {
+			v.fail("sitemap.maxUrls", "is %d, must be at most 50000", c.Sitemap.MaxURLs)
+		}
+	}
+	if c.Sitemap.News {
+		v.required("sitemap.publicationName", c.Sitemap.PublicationName)
+		v.required("sitemap.language", c.Sitemap.Language)
+	}
+
+	v.oneOf("summarizer.provider", c.Summarizer.Provider, "none", "extractive", "llm")
+	v.atLeast("summarizer.maxSentences", c.Summarizer.MaxSentences, 1)
+	v.atLeast("summarizer.concurrency", c.Summarizer.Concurrency, 1)
+	if c.Summarizer.Provider == "llm" {
+		v.required("summarizer.llm.endpoint", c.Summarizer.LLM.Endpoint)
+		v.required("summarizer.llm.apiKey", c.Summarizer.LLM.APIKey)
+		v.required("summarizer.llm.model", c.Summarizer.LLM.Model)
+		v.atLeast("summarizer.llm.timeout", c.Summarizer.LLM.Timeout, 1)
+	}
+
+	v.atLeast("jobs.workers", c.Jobs.Workers, 0)
+	v.atLeast("jobs.pollInterval", c.Jobs.PollInterval, 1)
+	v.atLeast("jobs.maxAttempts", c.Jobs.MaxAttempts, 1)
+	v.atLeast("jobs.backoff", c.Jobs.Backoff, 0)
+	v.atLeast("jobs.lease", c.Jobs.Lease, 60)
+	v.atLeast("jobs.retentionDays", c.Jobs.RetentionDays, 1)
+	v.atLeast("jobs.idempotencyWindow", c.Jobs.IdempotencyWindow, 1)
+	v.atLeast("retention.archiveMonths", c.Retention.ArchiveMonths, 0)
+
+	v.atLeast("stream.keepAlive", c.Stream.KeepAlive, 0)
+	v.atLeast("stream.bufferSize", c.Stream.BufferSize, 1)
+	v.atLeast("webSocket.maxConnections", c.WebSocket.MaxConnections, 0)
+	v.atLeast("webSocket.maxMessageBytes", int(c.WebSocket.MaxMessageBytes), 1)
+	v.atLeast("webSocket.maxFilterSize", c.WebSocket.MaxFilterSize, 1)
+	v.atLeast("webSocket.pingInterval", c.WebSocket.PingInterval, 1)
+	v.atLeast("webSocket.writeTimeout", c.WebSocket.WriteTimeout, 1)
+
+	v.atLeast("webhook.timeout", c.Webhook.Timeout, 1)
+	v.atLeast("webhook.maxAttempts", c.Webhook.MaxAttempts, 1)
+	v.atLeast("webhook.backoff", c.Webhook.Backoff, 0)
+	v.atLeast("webhook.batchSize", c.Webhook.BatchSize, 1)
+	v.atLeast("webhook.ratePerMinute", c.Webhook.RatePerMinute, 0)
+
+	if c.Search.UseOpenSearch && c.Search.OpenSearch.URL == "" {
+		v.fail("search.useOpenSearch", "needs search.openSearch.url")
+	}
+	if c.Search.OpenSearch.URL != "" {
+		v.required("search.openSearch.index", c.Search.OpenSearch.Index)
+		v.atLeast("search.openSearch.timeout", c.Search.OpenSearch.Timeout, 1)
+		if (c.Search.OpenSearch.Username == "") != (c.Search.OpenSearch.Password == "") {
+			v.warn("search.openSearch.username", "is only sent together with search.openSearch.password")
+		}
+	}
+
+	v.atLeast("notify.maxItemsPerRule", c.Notify.MaxItemsPerRule, 1)
+	v.atLeast("line.timeout", c.Line.Timeout, 1)
+	v.atLeast("line.ratePerMinute", c.Line.RatePerMinute, 0)
+	v.atLeast("telegram.timeout", c.Telegram.Timeout, 1)
+	v.atLeast("telegram.ratePerMinute", c.Telegram.RatePerMinute, 0)
+	v.atLeast("discord.timeout", c.Discord.Timeout, 1)
+	v.atLeast("discord.ratePerMinute", c.Discord.RatePerMinute, 0)
+
+	if c.FCM.CredentialsFile != "" {
+		v.file("fcm.credentialsFile", c.FCM.CredentialsFile)
+		v.atLeast("fcm.timeout", c.FCM.Timeout, 1)
+		v.atLeast("fcm.maxAttempts", c.FCM.MaxAttempts, 1)
+		v.atLeast("fcm.backoff", c.FCM.Backoff, 0)
+		v.atLeast("fcm.pollInterval", c.FCM.PollInterval, 1)
+		v.atLeast("fcm.batchSize", c.FCM.BatchSize, 1)
+		v.atLeast("fcm.maxItemsPerDevice", c.FCM.MaxItemsPerDevice, 1)
+	}
+
+	v.atLeast("personalization.recencyHalfLife", c.Personalization.RecencyHalfLife, 0)
+	v.atLeast("personalization.historySize", c.Personalization.HistorySize, 1)
+	v.nonNegative("personalization.historyWeight", c.Personalization.HistoryWeight)
+	v.nonNegative("personalization.followBoost", c.Personalization.FollowBoost)
+	v.atLeast("personalization.cacheTTL", c.Personalization.CacheTTL, 0)
+
+	v.atLeast("clustering.window", c.Clustering.Window, 1)
+	v.share("clustering.minSimilarity", c.Clustering.MinSimilarity)
+
+	v.atLeast("rateLimit.perReader", c.RateLimit.PerReader, 0)
+	v.atLeast("rateLimit.perIP", c.RateLimit.PerIP, 0)
+	v.atLeast("rateLimit.blockThreshold", c.RateLimit.BlockThreshold, 0)
+	v.atLeast("rateLimit.trustedProxies", c.RateLimit.TrustedProxies, 0)
+	if c.RateLimit.BlockThreshold > 0 {
+		v.atLeast("rateLimit.blockDuration", c.RateLimit.BlockDuration, 1)
+	}
+
+	v.atLeast("sourceSuggestion.maxPendingPerReader", c.SourceSuggestion.MaxPendingPerReader, 0)
+	if c.SourceSuggestion.NotifyChannel != "" {
+		v.oneOf("sourceSuggestion.notifyChannel", c.SourceSuggestion.NotifyChannel, "line_notify", "line_messaging", "telegram", "discord", "webhook")
+		v.required("sourceSuggestion.notifyTarget", c.SourceSuggestion.NotifyTarget)
+	}
+
+	v.atLeast("savedSearch.maxPerReader", c.SavedSearch.MaxPerReader, 0)
+	v.atLeast("savedSearch.maxItemsPerSearch", c.SavedSearch.MaxItemsPerSearch, 1)
+
+	v.share("sentry.sampleRate", c.Sentry.SampleRate)
+	for _, t := range c.Sentry.Types {
+		v.oneOf("sentry.types", t, "VALIDATION_ERROR", "NOT_FOUND", "DATABASE_ERROR", "REDIS_ERROR",
+			"NETWORK_ERROR", "PARSE_ERROR", "INTERNAL_ERROR", "SERVICE_UNAVAILABLE", "UNAUTHORIZED",
+			"FORBIDDEN", "PAYLOAD_TOO_LARGE", "TIMEOUT", "METHOD_NOT_ALLOWED", "RATE_LIMITED", "CONFLICT")
+	}
+
+	v.validateSecrets(c.Secrets)
+
+	return v.problems
+}
+
+func (v *validator) validateAuth(cfg auth) {
+	for _, hash := range cfg.AdminKeyHashes {
+		if _, err := hex.DecodeString(hash); err != nil || len(hash) != 64 {
+			v.fail("auth.adminKeyHashes", "%q is not a hex sha256 digest", hash)
+		}
+	}
+	if cfg.Enabled && len(cfg.AdminKeyHashes) == 0 {
+		v.warn("auth.adminKeyHashes", "is empty, only keys already stored in the database are accepted")
+	}
+	if cfg.JWT.Secret != "" {
+		if len(cfg.JWT.Secret) < 32 {
+			v.warn("auth.jwt.secret", "is shorter than 32 bytes")
+		}
+		v.atLeast("auth.jwt.accessTokenTTL", cfg.JWT.AccessTokenTTL, 1)
+		v.atLeast("auth.jwt.refreshTokenTTL", cfg.JWT.RefreshTokenTTL, 1)
+	}
+	if len(cfg.OAuth.Google.ClientIDs) > 0 {
+		v.required("auth.oauth.google.jwksUrl", cfg.OAuth.Google.JWKSURL)
+	}
+	if len(cfg.OAuth.Apple.ClientIDs) > 0 {
+		v.required("auth.oauth.apple.jwksUrl", cfg.OAuth.Apple.JWKSURL)
+	}
+}
+
+func (v *validator) validateSecrets(cfg secretStore) {
+	v.oneOf("secrets.provider", cfg.Provider, "env", "file", "vault", "aws")
+	v.atLeast("secrets.timeout", cfg.Timeout, 0)
+	if cfg.Provider == "env" {
+		if cfg.PostgresPassword != "" || cfg.RedisPassword != "" {
+			v.warn("secrets.provider", "is env, the password references are ignored")
+		}
+		return
+	}
+	if cfg.Provider == "file" {
+		for _, file := range [][2]string{
+			{"secrets.postgresPassword", cfg.PostgresPassword},
+			{"secrets.redisPassword", cfg.RedisPassword},
+		} {
+			if file[1] != "" {
+				v.file(file[0], file[1])
+			}
+		}
+	}
+	if cfg.Provider == "vault" {
+		if cfg.Vault.Address == "" && os.Getenv("VAULT_ADDR") == "" {
+			v.fail("secrets.vault.address", "is required, or VAULT_ADDR")
+		}
+		if cfg.Vault.Token == "" && cfg.Vault.TokenFile == "" && os.Getenv("VAULT_TOKEN") == "" {
+			v.fail("secrets.vault.token", "is required, or secrets.vault.tokenFile or VAULT_TOKEN")
+		}
+		v.required("secrets.vault.mount", cfg.Vault.Mount)
+	}
+}
+
+func (v *validator) validateRestServer(cfg restServer) {
+	if cfg.Socket == "" {
+		v.port("restServer.port", cfg.Port)
+		if strings.Contains(cfg.Host, ":") && net.ParseIP(cfg.Host) == nil {
+			v.fail("restServer.host", "%q must not include a port, use restServer.port", cfg.Host)
+		}
+	} else {
+		v.file("restServer.socket", filepath.Dir(cfg.Socket))
+		if _, err := strconv.ParseUint(cfg.SocketMode, 8, 32); err != nil {
+			v.fail("restServer.socketMode", "%q is not an octal file mode", cfg.SocketMode)
+		}
+	}
+	v.atLeast("restServer.maxBodyBytes", int(cfg.MaxBodyBytes), 0)
+	v.atLeast("restServer.timeout.default", cfg.Timeout.Default, 0)
+	v.atLeast("restServer.timeout.internal", cfg.Timeout.Internal, 0)
+	if cfg.LegacySunset != "" {
+		if _, err := time.Parse(time.DateOnly, cfg.LegacySunset); err != nil {
+			v.fail("restServer.legacySunset", "%q is not a YYYY-MM-DD date", cfg.LegacySunset)
+		}
+	}
+}
+
+func (v *validator) validatePostgres(cfg postgres) {
+	v.required("postgres.host", cfg.Host)
+	v.port("postgres.port", cfg.Port)
+	v.required("postgres.user", cfg.User)
+	v.required("postgres.dbname", cfg.Dbname)
+	if cfg.Password == "" {
+		v.warn("postgres.password", "is empty, the server must trust connections from this host")
+	}
+
+	v.oneOf("postgres.sslMode", cfg.SSLMode, "disable", "allow", "prefer", "require", "verify-ca", "verify-full")
+	if (cfg.SSLCert == "") != (cfg.SSLKey == "") {
+		v.fail("postgres.sslCert", "and postgres.sslKey must be set together")
+	}
+	for _, file := range [][2]string{
+		{"postgres.sslRootCert", cfg.SSLRootCert},
+		{"postgres.sslCert", cfg.SSLCert},
+		{"postgres.sslKey", cfg.SSLKey},
+	} {
+		if file[1] != "" {
+			v.file(file[0], file[1])
+		}
+	}
+	if strings.HasPrefix(cfg.SSLMode, "verify-") && cfg.SSLRootCert == "" {
+		v.warn("postgres.sslRootCert", "is empty, %s falls back to ~/.postgresql/root.crt", cfg.SSLMode)
+	}
+
+	v.atLeast("postgres.pool.maxConns", int(cfg.Pool.MaxConns), 1)
+	v.atLeast("postgres.pool.minConns", int(cfg.Pool.MinConns), 0)
+	if cfg.Pool.MinConns > cfg.Pool.MaxConns {
+		v.fail("postgres.pool.minConns", "must not be greater than postgres.pool.maxConns (%d)", cfg.Pool.MaxConns)
+	}
+	v.atLeast("postgres.pool.maxConnLifetime", cfg.Pool.MaxConnLifetime, 0)
+	v.atLeast("postgres.pool.maxConnIdleTime", cfg.Pool.MaxConnIdleTime, 0)
+	v.atLeast("postgres.pool.healthCheckPeriod", cfg.Pool.HealthCheckPeriod, 0)
+	v.atLeast("postgres.pool.connectTimeout", cfg.Pool.ConnectTimeout, 0)
+	v.atLeast("postgres.queryTimeout", cfg.QueryTimeout, 0)
+	v.atLeast("postgres.statementTimeout", cfg.StatementTimeout, 0)
+	v.atLeast("postgres.retry.maxAttempts", cfg.Retry.MaxAttempts, 1)
+	v.atLeast("postgres.retry.backoff", cfg.Retry.Backoff, 0)
+	v.atLeast("postgres.slowQuery.threshold", cfg.SlowQuery.Threshold, 0)
+	for i, replica := range cfg.Replicas {
+		v.required(fmt.Sprintf("postgres.replicas[%d].host", i), replica.Host)
+		v.port(fmt.Sprintf("postgres.replicas[%d].port", i), replica.Port)
+	}
+}
+
+func (v *validator) validateRedis(cfg redis) {
+	v.required("redis.host", cfg.Host)
+	v.port("redis.port", cfg.Port)
+	v.atLeast("redis.pool.poolSize", cfg.Pool.PoolSize, 1)
+	v.atLeast("redis.pool.minIdleConns", cfg.Pool.MinIdleConns, 0)
+	if cfg.Pool.MinIdleConns > cfg.Pool.PoolSize {
+		v.fail("redis.pool.minIdleConns", "must not be greater than redis.pool.poolSize (%d)", cfg.Pool.PoolSize)
+	}
+	if cfg.Pool.MaxIdleConns > 0 && cfg.Pool.MaxIdleConns < cfg.Pool.MinIdleConns {
+		v.fail("redis.pool.maxIdleConns", "must not be less than redis.pool.minIdleConns (%d)", cfg.Pool.MinIdleConns)
+	}
+	v.atLeast("redis.pool.poolTimeout", cfg.Pool.PoolTimeout, 0)
+	v.atLeast("redis.pool.idleTimeout", cfg.Pool.IdleTimeout, 0)
+	v.atLeast("redis.pool.maxConnAge", cfg.Pool.MaxConnAge, 0)
+	v.atLeast("redis.pool.dialTimeout", cfg.Pool.DialTimeout, 0)
+	v.atLeast("redis.pool.readTimeout", cfg.Pool.ReadTimeout, 0)
+	v.atLeast("redis.pool.writeTimeout", cfg.Pool.WriteTimeout, 0)
+	v.atLeast("redis.pool.maxRetries", cfg.Pool.MaxRetries, 0)
+	if cfg.Pool.MaxRetryBackoff < cfg.Pool.MinRetryBackoff {
+		v.fail("redis.pool.maxRetryBackoff", "must not be less than redis.pool.minRetryBackoff (%d)", cfg.Pool.MinRetryBackoff)
+	}
+}
+
+// validator collects the problems of one Validate pass
+type validator struct {
+	problems Problems
+}
+
+func (v *validator) fail(key, format string, args ...any) {
+	v.problems = append(v.problems, Problem{Key: key, Message: fmt.Sprintf(format, args...), Critical: true})
+}
+
+func (v *validator) warn(key, format string, args ...any) {
+	v.problems = append(v.problems, Problem{Key: key, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) required(key, value string) {
+	if strings.TrimSpace(value) == "" {
+		v.fail(key, "is required")
+	}
+}
+
+func (v *validator) oneOf(key, value string, allowed ...string) {
+	if !slices.Contains(allowed, value) {
+		v.fail(key, "%q is not one of %s", value, strings.Join(allowed, ", "))
+	}
+}
+
+func (v *validator) atLeast(key string, value, minimum int) {
+	if value < minimum {
+		v.fail(key, "is %d, must be at least %d", value, minimum)
+	}
+}
+
+func (v *validator) nonNegative(key string, value float64) {
+	if value < 0 {
+		v.fail(key, "is %g, must not be negative", value)
+	}
+}
+
+func (v *validator) share(key string, value float64) {
+	if value < 0 || value > 1 {
+		v.fail(key, "is %g, must be from 0 to 1", value)
+	}
+}
+
+func (v *validator) logLevel(key, value string) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(value)); err != nil {
+		v.fail(key, "%q is not a log level such as debug, info, warn or error", value)
+	}
+}
+
+func (v *validator) port(key string, port int) {
+	if port < 1 || port > 65535 {
+		v.fail(key, "%d is not a TCP port", port)
+	}
+}
+
+func (v *validator) file(key, path string) {
+	if _, err := os.Stat(path); err != nil {
+		v.fail(key, "can't be read: %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+// Package buildinfo describes the running build. Commit, BuildTime and Profile are set
+// at link time:
+//
+//	go build -ldflags "-X github.com/onefeed-th/onefeed-th-backend-api/internal/core/buildinfo.Commit=$(git rev-parse HEAD)"
+//
+// Without them the commit and time recorded by the Go toolchain are used, when the binary
+// was built from a git checkout.
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+var (
+	Commit    string
+	BuildTime string // RFC 3339
+	Profile   string // the config profile the build was made for, such as staging or prod
+)
+
+type Info struct {
+	Commit    string
+	BuildTime string
+	Profile   string
+	GoVersion string
+	// Modified reports uncommitted changes in the checkout the binary was built from
+	Modified bool
+}
+
+// Get returns the build info, falling back to the toolchain's VCS stamp
+func Get() Info {
+	info := Info{
+		Commit:    Commit,
+		BuildTime: BuildTime,
+		Profile:   Profile,
+		GoVersion: runtime.Version(),
+	}
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	for _, setting := range build.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if info.Commit == "" {
+				info.Commit = setting.Value
+			}
+		case "vcs.time":
+			if info.BuildTime == "" {
+				info.BuildTime = setting.Value
+			}
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		}
+	}
+	return info
+}
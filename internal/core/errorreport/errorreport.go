@@ -0,0 +1,161 @@
+// Package errorreport sends server errors and recovered panics to Sentry. It does nothing
+// until Init has been called with a sentry.dsn configured.
+package errorreport
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/onefeed-th/onefeed-th-backend-api/config"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/requestid"
+	apperrors "github.com/onefeed-th/onefeed-th-backend-api/internal/errors"
+)
+
+// PanicCode is the code of the error answered for a recovered panic. Panic reports it with
+// the panic's stack, so Error leaves it out
+const PanicCode = "PANIC"
+
+var (
+	// types are the AppError types worth reporting; the others are the client's mistakes.
+	// It is nil while reporting is disabled
+	types atomic.Pointer[[]apperrors.ErrorType]
+
+	watchOnce sync.Once
+)
+
+// Init sets up the Sentry client from config.yaml; without a DSN reporting stays disabled.
+// The client is set up again when a config reload changes the sentry section
+func Init() error {
+	watchOnce.Do(func() {
+		config.OnChange(func(old, cfg *config.Config) {
+			if reflect.DeepEqual(old.Sentry, cfg.Sentry) {
+				return
+			}
+			if err := setup(cfg); err != nil {
+				slog.Error("Failed to reconfigure error reporting", "error", err)
+			}
+		})
+	})
+	return setup(config.GetConfig())
+}
+
+func setup(c *config.Config) error {
+	cfg := c.Sentry
+	if cfg.DSN == "" {
+		if types.Swap(nil) != nil {
+			slog.Info("Error reporting disabled")
+		}
+		return nil
+	}
+
+	err := sentry.Init(sentry.ClientOptions{
+		Dsn:         cfg.DSN,
+		Environment: cfg.Environment,
+		Release:     cfg.Release,
+		SampleRate:  cfg.SampleRate,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to initialize sentry: %w", err)
+	}
+
+	reported := make([]apperrors.ErrorType, 0, len(cfg.Types))
+	for _, t := range cfg.Types {
+		reported = append(reported, apperrors.ErrorType(t))
+	}
+	types.Store(&reported)
+	slog.Info("Error reporting enabled", "environment", cfg.Environment, "types", cfg.Types)
+	return nil
+}
+
+// Flush waits up to timeout for queued reports to be sent, before the process exits
+func Flush(timeout time.Duration) {
+	if types.Load() != nil {
+		sentry.Flush(timeout)
+	}
+}
+
+// Error reports err when it is, or wraps, an AppError of a reported type. The event is
+// tagged with the error's type and code and carries its details and the file and line it
+// was created at
+func Error(r *http.Request, err error) {
+	reported := types.Load()
+	if reported == nil || err == nil {
+		return
+	}
+	appErr := reportedError(err, *reported)
+	if appErr == nil || appErr.Code == PanicCode {
+		return
+	}
+
+	hub := sentry.CurrentHub().Clone()
+	hub.WithScope(func(scope *sentry.Scope) {
+		tagRequest(scope, r)
+		scope.SetTag("error.type", string(appErr.Type))
+		if appErr.Code != "" {
+			scope.SetTag("error.code", appErr.Code)
+		}
+		scope.SetContext("app_error", sentry.Context{
+			"message": appErr.Message,
+			"details": appErr.Details,
+			"file":    appErr.File,
+			"line":    appErr.Line,
+		})
+		// errors created at the same place are one issue, whatever their message says
+		if appErr.File != "" {
+			scope.SetFingerprint([]string{string(appErr.Type), appErr.Code, fmt.Sprintf("%s:%d", appErr.File, appErr.Line)})
+		}
+		hub.CaptureException(err)
+	})
+}
+
+// Panic reports a value recovered from a panicking request
+func Panic(r *http.Request, recovered any) {
+	if types.Load() == nil {
+		return
+	}
+	hub := sentry.CurrentHub().Clone()
+	hub.WithScope(func(scope *sentry.Scope) {
+		tagRequest(scope, r)
+		scope.SetTag("error.type", string(apperrors.InternalError))
+		scope.SetTag("error.code", PanicCode)
+		hub.Recover(recovered)
+	})
+}
+
+// reportedError returns the outermost AppError in err's chain with a reported type
+func reportedError(err error, types []apperrors.ErrorType) *apperrors.AppError {
+	for err != nil {
+		if appErr, ok := err.(*apperrors.AppError); ok && slices.Contains(types, appErr.Type) {
+			return appErr
+		}
+		unwrapper, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			return nil
+		}
+		err = unwrapper.Unwrap()
+	}
+	return nil
+}
+
+// tagRequest identifies the request without its headers or body, which may hold API keys,
+// tokens or passwords
+func tagRequest(scope *sentry.Scope, r *http.Request) {
+	if r == nil {
+		return
+	}
+	scope.SetTag("http.method", r.Method)
+	scope.SetTag("http.path", r.URL.Path)
+	if r.Pattern != "" {
+		scope.SetTag("http.route", r.Pattern)
+	}
+	if id := requestid.FromContext(r.Context()); id != "" {
+		scope.SetTag("request_id", id)
+	}
+}
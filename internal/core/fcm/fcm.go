@@ -0,0 +1,158 @@
+// Package fcm sends push notifications through the Firebase Cloud Messaging HTTP v1 API,
+// authenticating as a Google service account.
+package fcm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/onefeed-th/onefeed-th-backend-api/config"
+)
+
+// ErrUnregistered means the device token is no longer valid and should be forgotten
+var ErrUnregistered = errors.New("fcm: registration token is no longer valid")
+
+// Message is a notification shown on the device; Data is delivered to the app as is
+type Message struct {
+	Title string
+	Body  string
+	Data  map[string]string
+}
+
+type Client struct {
+	endpoint   string
+	projectID  string
+	tokens     *tokenSource
+	httpClient *http.Client
+}
+
+// New returns a client for the service account in fcm.credentialsFile, or nil when push
+// notifications are not configured or the credentials can't be loaded
+func New() *Client {
+	cfg := config.GetConfig().FCM
+	if cfg.CredentialsFile == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(cfg.CredentialsFile)
+	if err != nil {
+		slog.Error("Failed to read FCM credentials, push notifications are disabled", "error", err)
+		return nil
+	}
+	account, err := parseServiceAccount(data)
+	if err != nil {
+		slog.Error("Failed to parse FCM credentials, push notifications are disabled", "error", err)
+		return nil
+	}
+
+	httpClient := &http.Client{Timeout: time.Duration(cfg.Timeout) * time.Second}
+	return &Client{
+		endpoint:   strings.TrimRight(cfg.Endpoint, "/"),
+		projectID:  account.ProjectID,
+		tokens:     newTokenSource(account, httpClient),
+		httpClient: httpClient,
+	}
+}
+
+type sendRequest struct {
+	Message message `json:"message"`
+}
+
+type message struct {
+	Token        string            `json:"token"`
+	Notification notification      `json:"notification"`
+	Data         map[string]string `json:"data,omitempty"`
+}
+
+type notification struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+// Send delivers msg to one device. Errors other than ErrUnregistered are *SendError
+// or transport errors
+func (c *Client) Send(ctx context.Context, token string, msg Message) error {
+	accessToken, err := c.tokens.token(ctx)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(sendRequest{Message: message{
+		Token:        token,
+		Notification: notification{Title: msg.Title, Body: msg.Body},
+		Data:         msg.Data,
+	}})
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/v1/projects/%s/messages:send", c.endpoint, c.projectID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 300 {
+		return nil
+	}
+	return newSendError(resp)
+}
+
+// SendError is a request FCM answered with an error
+type SendError struct {
+	StatusCode int
+	// Code is the FCM error code, such as QUOTA_EXCEEDED or INVALID_ARGUMENT
+	Code    string
+	Message string
+}
+
+func (e *SendError) Error() string {
+	return fmt.Sprintf("fcm answered %d %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// Retryable reports whether sending again later may succeed
+func (e *SendError) Retryable() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+func newSendError(resp *http.Response) error {
+	var body struct {
+		Error struct {
+			Message string `json:"message"`
+			Status  string `json:"status"`
+			Details []struct {
+				ErrorCode string `json:"errorCode"`
+			} `json:"details"`
+		} `json:"error"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	_ = json.Unmarshal(data, &body)
+
+	code := body.Error.Status
+	for _, detail := range body.Error.Details {
+		if detail.ErrorCode != "" {
+			code = detail.ErrorCode
+		}
+	}
+	if code == "UNREGISTERED" || code == "SENDER_ID_MISMATCH" {
+		return ErrUnregistered
+	}
+	return &SendError{StatusCode: resp.StatusCode, Code: code, Message: body.Error.Message}
+}
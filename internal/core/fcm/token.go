@@ -0,0 +1,155 @@
+package fcm
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	messagingScope = "https://www.googleapis.com/auth/firebase.messaging"
+	// tokenLifetime is the longest Google accepts for a service account assertion
+	tokenLifetime = time.Hour
+	// refreshBefore renews access tokens early so a request never carries an expired one
+	refreshBefore = time.Minute
+)
+
+type serviceAccount struct {
+	ProjectID   string `json:"project_id"`
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+
+	key *rsa.PrivateKey
+}
+
+func parseServiceAccount(data []byte) (*serviceAccount, error) {
+	var account serviceAccount
+	if err := json.Unmarshal(data, &account); err != nil {
+		return nil, err
+	}
+	if account.ProjectID == "" || account.ClientEmail == "" || account.TokenURI == "" {
+		return nil, errors.New("service account needs project_id, client_email and token_uri")
+	}
+
+	block, _ := pem.Decode([]byte(account.PrivateKey))
+	if block == nil {
+		return nil, errors.New("service account private_key is not PEM encoded")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		if key, err = x509.ParsePKCS1PrivateKey(block.Bytes); err != nil {
+			return nil, fmt.Errorf("parse service account private_key: %w", err)
+		}
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("service account private_key is not an RSA key")
+	}
+	account.key = rsaKey
+	return &account, nil
+}
+
+// tokenSource exchanges signed service account assertions for OAuth access tokens and
+// caches them until shortly before they expire
+type tokenSource struct {
+	account    *serviceAccount
+	httpClient *http.Client
+
+	mu        sync.Mutex
+	current   string
+	expiresAt time.Time
+}
+
+func newTokenSource(account *serviceAccount, httpClient *http.Client) *tokenSource {
+	return &tokenSource{
+		account:    account,
+		httpClient: httpClient,
+	}
+}
+
+func (t *tokenSource) token(ctx context.Context) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.current != "" && time.Now().Before(t.expiresAt.Add(-refreshBefore)) {
+		return t.current, nil
+	}
+
+	assertion, err := t.assertion(time.Now())
+	if err != nil {
+		return "", err
+	}
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.account.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("fcm token exchange answered %s: %s", resp.Status, data)
+	}
+	var body struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("decode fcm token response: %w", err)
+	}
+
+	t.current = body.AccessToken
+	t.expiresAt = time.Now().Add(time.Duration(body.ExpiresIn) * time.Second)
+	return t.current, nil
+}
+
+// assertion builds the RS256 signed JWT that identifies the service account
+func (t *tokenSource) assertion(now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]any{
+		"iss":   t.account.ClientEmail,
+		"scope": messagingScope,
+		"aud":   t.account.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(tokenLifetime).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	encoding := base64.RawURLEncoding
+	unsigned := encoding.EncodeToString(header) + "." + encoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, t.account.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return unsigned + "." + encoding.EncodeToString(signature), nil
+}
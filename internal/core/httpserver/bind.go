@@ -0,0 +1,89 @@
+package httpserver
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+)
+
+// bindParams copies path wildcards (e.g. /news/{id}) into fields tagged with `path:"id"`
+// and query string values into fields tagged with `query:"name"`
+func bindParams(r *http.Request, dst any) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	v = v.Elem()
+	t := v.Type()
+	query := r.URL.Query()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		if name, ok := field.Tag.Lookup("path"); ok && name != "" {
+			raw := r.PathValue(name)
+			if raw == "" {
+				continue
+			}
+			if err := setField(v.Field(i), raw); err != nil {
+				return fmt.Errorf("invalid path parameter %q: %w", name, err)
+			}
+			continue
+		}
+
+		if name, ok := field.Tag.Lookup("query"); ok && name != "" {
+			values, exists := query[name]
+			if !exists || len(values) == 0 {
+				continue
+			}
+			if err := setFieldValues(v.Field(i), values); err != nil {
+				return fmt.Errorf("invalid query parameter %q: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func setFieldValues(field reflect.Value, values []string) error {
+	if field.Kind() != reflect.Slice {
+		return setField(field, values[0])
+	}
+
+	slice := reflect.MakeSlice(field.Type(), len(values), len(values))
+	for i, raw := range values {
+		if err := setField(slice.Index(i), raw); err != nil {
+			return err
+		}
+	}
+	field.Set(slice)
+	return nil
+}
+
+func setField(field reflect.Value, raw string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/onefeed-th/onefeed-th-backend-api/config"
+	apperrors "github.com/onefeed-th/onefeed-th-backend-api/internal/errors"
+)
+
+// CSVDecoder is implemented by requests that also accept a text/csv body
+type CSVDecoder interface {
+	DecodeCSV(r io.Reader) error
+}
+
+// decodeBody reads the JSON request body into dst, bounded by restServer.maxBodyBytes.
+// An empty body leaves dst untouched so path and query parameters can still be bound
+func decodeBody(w http.ResponseWriter, r *http.Request, dst any) error {
+	if r.Body == nil || r.Body == http.NoBody || r.ContentLength == 0 {
+		return nil
+	}
+
+	cfg := config.GetConfig().RestServer
+	if cfg.MaxBodyBytes > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, cfg.MaxBodyBytes)
+	}
+
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "text/csv" {
+		decoder, ok := dst.(CSVDecoder)
+		if !ok {
+			return apperrors.New(apperrors.ValidationError, "this endpoint does not accept CSV").
+				WithCode("UNSUPPORTED_MEDIA_TYPE")
+		}
+		if err := decoder.DecodeCSV(r.Body); err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				return bodyError(err)
+			}
+			return apperrors.Wrap(err, apperrors.ParseError, "malformed CSV body").
+				WithCode("INVALID_CSV")
+		}
+		return nil
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	if cfg.DisallowUnknownFields {
+		decoder.DisallowUnknownFields()
+	}
+
+	err := decoder.Decode(dst)
+	if errors.Is(err, io.EOF) {
+		// chunked requests report an unknown length and may still be empty
+		return nil
+	}
+	if err == nil && decoder.More() {
+		err = errors.New("body must contain a single JSON value")
+	}
+	if err != nil {
+		return bodyError(err)
+	}
+	return nil
+}
+
+func bodyError(err error) error {
+	var (
+		maxBytesErr *http.MaxBytesError
+		syntaxErr   *json.SyntaxError
+		typeErr     *json.UnmarshalTypeError
+	)
+	switch {
+	case errors.As(err, &maxBytesErr):
+		return apperrors.Newf(apperrors.TooLargeError, "request body exceeds %d bytes", maxBytesErr.Limit).
+			WithCode("BODY_TOO_LARGE")
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		return apperrors.Wrap(err, apperrors.ParseError, "malformed JSON body").
+			WithCode("INVALID_JSON")
+	case errors.As(err, &typeErr):
+		return apperrors.Newf(apperrors.ValidationError, "field %q must be %s", typeErr.Field, typeErr.Type).
+			WithCode("INVALID_FIELD_TYPE")
+	default:
+		// json reports unknown fields only by message: `json: unknown field "name"`
+		return apperrors.Wrap(err, apperrors.ValidationError, "invalid request body").
+			WithCode("INVALID_BODY")
+	}
+}
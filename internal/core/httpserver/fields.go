@@ -0,0 +1,79 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// requestedFields parses the ?fields=title,link,publishedAt query parameter
+func requestedFields(r *http.Request) []string {
+	raw := r.URL.Query().Get("fields")
+	if raw == "" {
+		return nil
+	}
+
+	var fields []string
+	for _, field := range strings.Split(raw, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// selectFields shapes a response down to the requested fields.
+// Any object that carries at least one requested field is treated as a resource and
+// trimmed to those fields; other objects (envelopes, pagination wrappers, maps of groups)
+// are kept and searched recursively, so the same parameter works for lists and details.
+func selectFields(data any, fields []string) (any, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var generic any
+	if err := json.Unmarshal(raw, &generic); err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[string]struct{}, len(fields))
+	for _, field := range fields {
+		wanted[field] = struct{}{}
+	}
+	return pruneFields(generic, wanted), nil
+}
+
+func pruneFields(value any, wanted map[string]struct{}) any {
+	switch v := value.(type) {
+	case []any:
+		for i, item := range v {
+			v[i] = pruneFields(item, wanted)
+		}
+		return v
+	case map[string]any:
+		if !hasAnyField(v, wanted) {
+			for key, item := range v {
+				v[key] = pruneFields(item, wanted)
+			}
+			return v
+		}
+		for key := range v {
+			if _, ok := wanted[key]; !ok {
+				delete(v, key)
+			}
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func hasAnyField(obj map[string]any, wanted map[string]struct{}) bool {
+	for key := range obj {
+		if _, ok := wanted[key]; ok {
+			return true
+		}
+	}
+	return false
+}
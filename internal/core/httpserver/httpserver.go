@@ -1,37 +1,109 @@
 package httpserver
 
 import (
-	"encoding/json"
+	"log/slog"
 	"net/http"
+	"reflect"
+	"strings"
 
-	"github.com/onefeed-th/onefeed-th-backend-api/internal/dto"
+	apperrors "github.com/onefeed-th/onefeed-th-backend-api/internal/errors"
 )
 
+// Renderer is implemented by responses that write their own body (feeds, files, ...)
+// instead of being wrapped in the JSON response envelope
+type Renderer interface {
+	Render(w http.ResponseWriter) error
+}
+
+// Upgrader is implemented by responses that take the connection over (WebSockets) and
+// need the request to do so
+type Upgrader interface {
+	Upgrade(w http.ResponseWriter, r *http.Request) error
+}
+
 type Endpoint[TReq any, TResp any] func() (fn Service[TReq, TResp])
 
-func NewEndpoint[TReq any, TResp any](fn Service[TReq, TResp]) func(w http.ResponseWriter, r *http.Request) {
-	return func(w http.ResponseWriter, r *http.Request) {
+// endpoint is the handler returned by NewEndpoint; it remembers its request and response
+// types for the OpenAPI document
+type endpoint struct {
+	http.HandlerFunc
+	description endpointDescription
+}
+
+func (e endpoint) describe() endpointDescription {
+	return e.description
+}
+
+func NewEndpoint[TReq any, TResp any](fn Service[TReq, TResp]) http.Handler {
+	upgrades := reflect.TypeFor[TResp]().Implements(upgraderType)
+	handler := func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 		var req TReq
-		var finalRes dto.Response
-
-		if r.Body != nil && r.ContentLength > 0 {
-			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-				finalRes.Error = err.Error()
-				w.WriteHeader(http.StatusBadRequest)
-				json.NewEncoder(w).Encode(finalRes)
-				return
-			}
+
+		if upgrades && !isUpgradeRequest(r) {
+			WriteError(w, r, apperrors.New(apperrors.ValidationError, "this endpoint requires a connection upgrade").
+				WithCode("UPGRADE_REQUIRED"))
+			return
 		}
 
-		resp, err := fn(ctx, req)
-		w.Header().Set("Content-Type", "application/json")
+		if err := decodeBody(w, r, &req); err != nil {
+			WriteError(w, r, err)
+			return
+		}
+
+		if err := bindParams(r, &req); err != nil {
+			WriteError(w, r, apperrors.New(apperrors.ValidationError, err.Error()).
+				WithCode("INVALID_PARAMETER"))
+			return
+		}
+
+		fieldErrors, err := validateRequest(req)
 		if err != nil {
-			finalRes.Error = err.Error()
-			w.WriteHeader(http.StatusBadRequest)
+			slog.Error("Failed to validate request", "path", r.URL.Path, "error", err)
+		}
+		if len(fieldErrors) > 0 {
+			writeResponse(w, r, nil, apperrors.New(apperrors.ValidationError, "request validation failed"), fieldErrors)
+			return
+		}
+
+		resp, err := fn(ctx, req)
+		if upgrader, ok := any(resp).(Upgrader); ok && err == nil {
+			if err := upgrader.Upgrade(w, r); err != nil {
+				slog.Error("Failed to upgrade connection", "path", r.URL.Path, "error", err)
+			}
+			return
+		}
+		if renderer, ok := any(resp).(Renderer); ok && err == nil {
+			if err := renderer.Render(w); err != nil {
+				slog.Error("Failed to render response", "path", r.URL.Path, "error", err)
+			}
+			return
 		}
 
-		finalRes.Data = resp
-		json.NewEncoder(w).Encode(finalRes)
+		writeResponse(w, r, resp, err, nil)
+	}
+
+	return endpoint{
+		HandlerFunc: handler,
+		description: endpointDescription{
+			name:     endpointName(fn),
+			request:  reflect.TypeFor[TReq](),
+			response: reflect.TypeFor[TResp](),
+		},
+	}
+}
+
+// isUpgradeRequest reports whether the client asked to switch protocols
+func isUpgradeRequest(r *http.Request) bool {
+	if r.Header.Get("Upgrade") == "" {
+		return false
+	}
+	for _, value := range r.Header.Values("Connection") {
+		for _, token := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(token), "upgrade") {
+				return true
+			}
+		}
 	}
+	return false
 }
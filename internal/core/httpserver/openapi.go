@@ -0,0 +1,458 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/dto"
+)
+
+// describedEndpoint is implemented by handlers built with NewEndpoint, so the router can
+// document the request and response types of every route it registers
+type describedEndpoint interface {
+	describe() endpointDescription
+}
+
+type endpointDescription struct {
+	name     string
+	request  reflect.Type
+	response reflect.Type
+}
+
+// routeInfo is one registered method and path, recorded for the OpenAPI document
+type routeInfo struct {
+	method      string
+	path        string
+	version     string
+	description endpointDescription
+}
+
+// routeTable is shared by a router and all of its groups
+type routeTable struct {
+	mu     sync.Mutex
+	routes []routeInfo
+}
+
+func (t *routeTable) add(route routeInfo) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.routes = append(t.routes, route)
+}
+
+// byMethod returns one route per registered method, in the order methods were first used
+func (t *routeTable) byMethod() []routeInfo {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	seen := make(map[string]bool)
+	var routes []routeInfo
+	for _, route := range t.routes {
+		if !seen[route.method] {
+			seen[route.method] = true
+			routes = append(routes, route)
+		}
+	}
+	return routes
+}
+
+// lookup finds the route registered with a ServeMux pattern such as "GET /v1/news/{id}"
+func (t *routeTable) lookup(pattern string) (routeInfo, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, route := range t.routes {
+		if route.method+" "+route.path == pattern {
+			return route, true
+		}
+	}
+	return routeInfo{}, false
+}
+
+// endpointName turns the service method passed to NewEndpoint into an operation id,
+// e.g. "github.com/.../service.(*service).GetNews-fm" becomes "GetNews"
+func endpointName(fn any) string {
+	f := runtime.FuncForPC(reflect.ValueOf(fn).Pointer())
+	if f == nil {
+		return ""
+	}
+	name := f.Name()
+	name = name[strings.LastIndex(name, ".")+1:]
+	return strings.TrimSuffix(name, "-fm")
+}
+
+var (
+	rendererType = reflect.TypeOf((*Renderer)(nil)).Elem()
+	upgraderType = reflect.TypeOf((*Upgrader)(nil)).Elem()
+)
+
+// OpenAPIHandler serves an OpenAPI 3 document describing every route registered with
+// NewEndpoint on this router and its groups. The document is built on the first request,
+// after all routes are registered. Unversioned routes that also exist under a version are
+// documented only once, under their versioned path
+func (r *Router) OpenAPIHandler(title, version string) http.Handler {
+	var (
+		once sync.Once
+		doc  []byte
+		err  error
+	)
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		once.Do(func() {
+			doc, err = json.Marshal(r.routes.openAPI(title, version))
+		})
+		if err != nil {
+			WriteError(w, req, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(doc)
+	})
+}
+
+// SwaggerUIHandler serves a Swagger UI page for the OpenAPI document at specURL
+func SwaggerUIHandler(title, specURL string) http.Handler {
+	page := strings.NewReplacer("{{title}}", title, "{{specURL}}", specURL).Replace(swaggerUIPage)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(page))
+	})
+}
+
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>{{title}}</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5.17.14/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5.17.14/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({ url: "{{specURL}}", dom_id: "#swagger-ui" });
+  </script>
+</body>
+</html>
+`
+
+func (t *routeTable) openAPI(title, version string) map[string]any {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	versioned := make(map[string]bool)
+	for _, route := range t.routes {
+		if route.version != "" {
+			versioned[route.method+" "+strings.TrimPrefix(route.path, "/"+route.version)] = true
+		}
+	}
+
+	schemas := &schemaRegistry{components: make(map[string]any)}
+	paths := make(map[string]map[string]any)
+	for _, route := range t.routes {
+		if route.description.request == nil {
+			// not built with NewEndpoint, e.g. the docs themselves
+			continue
+		}
+		if route.version == "" && versioned[route.method+" "+route.path] {
+			continue
+		}
+		if paths[route.path] == nil {
+			paths[route.path] = make(map[string]any)
+		}
+		paths[route.path][strings.ToLower(route.method)] = schemas.operation(route)
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   title,
+			"version": version,
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": schemas.components,
+			"securitySchemes": map[string]any{
+				"apiKey":   map[string]any{"type": "apiKey", "in": "header", "name": "X-API-Key"},
+				"bearer":   map[string]any{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+				"deviceId": map[string]any{"type": "apiKey", "in": "header", "name": "X-Device-ID"},
+			},
+		},
+	}
+}
+
+// schemaRegistry collects named struct types under components/schemas
+type schemaRegistry struct {
+	components map[string]any
+}
+
+func (s *schemaRegistry) operation(route routeInfo) map[string]any {
+	op := map[string]any{
+		"operationId": route.description.name,
+		"tags":        []string{routeTag(route)},
+	}
+	if route.version != "" {
+		op["operationId"] = route.description.name + strings.ToUpper(route.version[:1]) + route.version[1:]
+	}
+	if tag := routeTag(route); tag == "backoffice" || tag == "internal" {
+		op["security"] = []map[string][]string{{"apiKey": {}}, {"bearer": {}}}
+	} else if path := strings.TrimPrefix(route.path, "/"+route.version); strings.HasPrefix(path, "/users/me/") {
+		// readers with an account or a device profile
+		op["security"] = []map[string][]string{{"bearer": {}}, {"deviceId": {}}}
+	} else if strings.HasPrefix(path, "/users/me") {
+		// signed-in readers
+		op["security"] = []map[string][]string{{"bearer": {}}}
+	}
+
+	var (
+		parameters []map[string]any
+		body       = map[string]any{"type": "object", "properties": map[string]any{}}
+		required   []string
+	)
+	if req := route.description.request; req != nil && req.Kind() == reflect.Struct {
+		for _, field := range structFields(req) {
+			if name := tagName(field, "path"); name != "" {
+				parameters = append(parameters, map[string]any{
+					"name": name, "in": "path", "required": true, "schema": s.schema(field.Type, field),
+				})
+				continue
+			}
+			if name := tagName(field, "query"); name != "" {
+				parameters = append(parameters, map[string]any{
+					"name": name, "in": "query", "required": hasRule(field, "required"), "schema": s.schema(field.Type, field),
+				})
+				continue
+			}
+			if name, isRequired, ok := jsonField(field); ok {
+				body["properties"].(map[string]any)[name] = s.schema(field.Type, field)
+				if isRequired {
+					required = append(required, name)
+				}
+			}
+		}
+	}
+	if len(parameters) > 0 {
+		op["parameters"] = parameters
+	}
+	if len(body["properties"].(map[string]any)) > 0 {
+		if len(required) > 0 {
+			body["required"] = required
+		}
+		op["requestBody"] = map[string]any{
+			"required": len(required) > 0,
+			"content":  map[string]any{"application/json": map[string]any{"schema": body}},
+		}
+	}
+
+	envelope := s.schema(legacyResponseType, reflect.StructField{})
+	if route.version != "" {
+		envelope = s.schema(envelopeType, reflect.StructField{})
+	}
+	failure := map[string]any{"description": "Error", "content": map[string]any{"application/json": map[string]any{"schema": envelope}}}
+	if resp := route.description.response; resp != nil && resp.Implements(upgraderType) {
+		op["responses"] = map[string]any{
+			"101":     map[string]any{"description": "Switching Protocols"},
+			"default": failure,
+		}
+		return op
+	}
+
+	success := map[string]any{"description": "OK"}
+	if resp := route.description.response; resp != nil && resp.Implements(rendererType) {
+		success["content"] = map[string]any{"*/*": map[string]any{"schema": map[string]any{"type": "string"}}}
+	} else {
+		data := map[string]any{}
+		if resp != nil {
+			data = s.schema(resp, reflect.StructField{})
+		}
+		success["content"] = map[string]any{"application/json": map[string]any{"schema": map[string]any{
+			"allOf": []any{envelope, map[string]any{"type": "object", "properties": map[string]any{"data": data}}},
+		}}}
+	}
+	op["responses"] = map[string]any{
+		"200":     success,
+		"default": failure,
+	}
+	return op
+}
+
+// routeTag groups operations by the first path segment after the version, e.g. "news"
+func routeTag(route routeInfo) string {
+	path := strings.TrimPrefix(route.path, "/"+route.version)
+	segment, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	return segment
+}
+
+var (
+	timeType           = reflect.TypeOf(time.Time{})
+	legacyResponseType = reflect.TypeOf(dto.Response{})
+	envelopeType       = reflect.TypeOf(dto.Envelope{})
+)
+
+// schema describes t, using field's validate tag for constraints. Named structs are
+// registered once under components/schemas and referenced
+func (s *schemaRegistry) schema(t reflect.Type, field reflect.StructField) map[string]any {
+	nullable := false
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+		nullable = true
+	}
+
+	var schema map[string]any
+	switch {
+	case t == timeType:
+		schema = map[string]any{"type": "string", "format": "date-time"}
+	case t.Kind() == reflect.Struct && t.Name() != "":
+		name := t.Name()
+		if _, ok := s.components[name]; !ok {
+			s.components[name] = map[string]any{} // placeholder for recursive types
+			s.components[name] = s.structSchema(t)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + name}
+	case t.Kind() == reflect.Struct:
+		schema = s.structSchema(t)
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			schema = map[string]any{"type": "string", "format": "byte"}
+			break
+		}
+		schema = map[string]any{"type": "array", "items": s.schema(t.Elem(), reflect.StructField{})}
+	case t.Kind() == reflect.Map:
+		schema = map[string]any{"type": "object", "additionalProperties": s.schema(t.Elem(), reflect.StructField{})}
+	case t.Kind() == reflect.String:
+		schema = map[string]any{"type": "string"}
+	case t.Kind() == reflect.Bool:
+		schema = map[string]any{"type": "boolean"}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		schema = map[string]any{"type": "integer"}
+		if t.Kind() == reflect.Int64 || t.Kind() == reflect.Uint64 {
+			schema["format"] = "int64"
+		} else if t.Kind() == reflect.Int32 || t.Kind() == reflect.Uint32 {
+			schema["format"] = "int32"
+		}
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		schema = map[string]any{"type": "number"}
+	default:
+		// interfaces (any) can hold any JSON value
+		return map[string]any{}
+	}
+
+	if nullable {
+		schema["nullable"] = true
+	}
+	applyRules(schema, field)
+	return schema
+}
+
+func (s *schemaRegistry) structSchema(t reflect.Type) map[string]any {
+	properties := make(map[string]any)
+	var required []string
+	for _, field := range structFields(t) {
+		name, isRequired, ok := jsonField(field)
+		if !ok {
+			continue
+		}
+		properties[name] = s.schema(field.Type, field)
+		if isRequired {
+			required = append(required, name)
+		}
+	}
+
+	schema := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		sort.Strings(required)
+		schema["required"] = required
+	}
+	return schema
+}
+
+// structFields lists exported fields, flattening embedded structs like encoding/json does
+func structFields(t reflect.Type) []reflect.StructField {
+	var fields []reflect.StructField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && field.Tag.Get("json") == "" {
+			fields = append(fields, structFields(field.Type)...)
+			continue
+		}
+		if field.IsExported() {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// jsonField returns the JSON name of a body field and whether a client must send it.
+// Fields bound from the path or query string are not part of the body
+func jsonField(field reflect.StructField) (name string, required bool, ok bool) {
+	if tagName(field, "path") != "" || tagName(field, "query") != "" {
+		return "", false, false
+	}
+	tag := field.Tag.Get("json")
+	name, options, _ := strings.Cut(tag, ",")
+	if name == "-" {
+		return "", false, false
+	}
+	if name == "" {
+		name = field.Name
+	}
+	return name, hasRule(field, "required") && !strings.Contains(options, "omitempty"), true
+}
+
+func tagName(field reflect.StructField, tag string) string {
+	name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+	return name
+}
+
+func hasRule(field reflect.StructField, rule string) bool {
+	for _, r := range strings.Split(field.Tag.Get("validate"), ",") {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// applyRules maps the validate rules NewEndpoint enforces onto schema constraints
+func applyRules(schema map[string]any, field reflect.StructField) {
+	for _, rule := range strings.Split(field.Tag.Get("validate"), ",") {
+		if rule == "dive" {
+			// rules after dive apply to the items
+			return
+		}
+		name, param, _ := strings.Cut(rule, "=")
+		n, numErr := strconv.ParseFloat(param, 64)
+		switch {
+		case name == "oneof":
+			schema["enum"] = strings.Fields(param)
+		case name == "url" || name == "http_url":
+			schema["format"] = "uri"
+		case (name == "min" || name == "max") && numErr == nil:
+			schema[limitKeyword(schema["type"], name)] = n
+		case name == "gt" && numErr == nil:
+			schema["minimum"] = n
+			schema["exclusiveMinimum"] = true
+		case name == "gte" && numErr == nil:
+			schema["minimum"] = n
+		case name == "lte" && numErr == nil:
+			schema["maximum"] = n
+		}
+	}
+}
+
+func limitKeyword(schemaType any, rule string) string {
+	bound := "Length"
+	switch schemaType {
+	case "array":
+		bound = "Items"
+	case "integer", "number":
+		if rule == "min" {
+			return "minimum"
+		}
+		return "maximum"
+	}
+	return rule + bound
+}
@@ -0,0 +1,109 @@
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/errorreport"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/requestid"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/dto"
+	apperrors "github.com/onefeed-th/onefeed-th-backend-api/internal/errors"
+)
+
+type apiVersionKey struct{}
+
+// withAPIVersion marks requests served by a Version group, which answer in dto.Envelope
+func withAPIVersion(version string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), apiVersionKey{}, version)))
+		})
+	}
+}
+
+// APIVersion returns the version of the route serving ctx, or "" for unversioned routes
+func APIVersion(ctx context.Context) string {
+	version, _ := ctx.Value(apiVersionKey{}).(string)
+	return version
+}
+
+// writeResponse writes data or err as JSON with the status mapped from err, applying the
+// ?fields= selection to successful data. Versioned routes use dto.Envelope, unversioned
+// ones keep the legacy dto.Response shape
+func writeResponse(w http.ResponseWriter, r *http.Request, data any, err error, fields []dto.FieldError) {
+	status := http.StatusOK
+	if err != nil {
+		status = statusCodeFromError(err)
+		errorreport.Error(r, err)
+		if status >= http.StatusInternalServerError {
+			// the cause stays in the logs, clients only get the error's message
+			slog.Error("Request failed",
+				"path", r.URL.Path,
+				"request_id", requestid.FromContext(r.Context()),
+				"error", err,
+			)
+		}
+	}
+
+	shaped := data
+	if selected := requestedFields(r); err == nil && len(selected) > 0 {
+		var shapeErr error
+		if shaped, shapeErr = selectFields(data, selected); shapeErr != nil {
+			slog.Error("Failed to apply field selection", "path", r.URL.Path, "error", shapeErr)
+			shaped = data
+		}
+	}
+
+	var body any
+	if APIVersion(r.Context()) == "" {
+		response := dto.Response{Data: shaped, Fields: fields}
+		if err != nil {
+			response.Error = err.Error()
+		}
+		body = response
+	} else {
+		envelope := dto.Envelope{
+			Success: err == nil,
+			Meta: dto.Meta{
+				RequestID: requestid.FromContext(r.Context()),
+				Timestamp: time.Now().UTC(),
+			},
+		}
+		if err != nil {
+			envelope.Error = errorResponse(err, fields)
+		} else {
+			envelope.Data = shaped
+			if paginated, ok := data.(dto.Paginated); ok {
+				envelope.Meta.Pagination = paginated.Pagination()
+			}
+		}
+		body = envelope
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func errorResponse(err error, fields []dto.FieldError) *dto.ErrorResponse {
+	var appErr *apperrors.AppError
+	if !errors.As(err, &appErr) {
+		return &dto.ErrorResponse{
+			Type:    string(apperrors.InternalError),
+			Message: "internal server error",
+			Fields:  fields,
+		}
+	}
+
+	return &dto.ErrorResponse{
+		Type:    string(appErr.Type),
+		Code:    appErr.Code,
+		Message: appErr.Message,
+		Details: appErr.Details,
+		Fields:  fields,
+	}
+}
@@ -1,21 +1,136 @@
 package httpserver
 
-import "net/http"
+import (
+	"context"
+	"net/http"
+	"strings"
 
+	apperrors "github.com/onefeed-th/onefeed-th-backend-api/internal/errors"
+)
+
+// Middleware wraps a handler, e.g. middleware.ETag
+type Middleware func(http.Handler) http.Handler
+
+// Router registers method-scoped routes on a ServeMux. Paths may contain Go 1.22
+// wildcards such as /news/{id}; NewEndpoint binds them into `path:"id"` request fields
 type Router struct {
-	mux *http.ServeMux
+	mux         *http.ServeMux
+	prefix      string
+	version     string
+	middlewares []Middleware
+	routes      *routeTable
 }
 
 func NewRouter(mux *http.ServeMux) *Router {
 	return &Router{
-		mux: mux,
+		mux:    mux,
+		routes: &routeTable{},
+	}
+}
+
+// NotFound handles requests that match no route. When the path exists for other methods
+// the request is answered with 405 Method Not Allowed and an Allow header instead
+func (r *Router) NotFound(handler http.Handler) {
+	r.mux.Handle("/", http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		allowed, version := r.allowedMethods(req)
+		if len(allowed) == 0 {
+			handler.ServeHTTP(w, req)
+			return
+		}
+		if version != "" {
+			req = req.WithContext(context.WithValue(req.Context(), apiVersionKey{}, version))
+		}
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		WriteError(w, req, apperrors.Newf(apperrors.MethodNotAllowedError, "method %s is not allowed on %s", req.Method, req.URL.Path).
+			WithCode("METHOD_NOT_ALLOWED"))
+	}))
+}
+
+// allowedMethods asks the mux which registered methods would serve req's path, along with
+// the API version of those routes
+func (r *Router) allowedMethods(req *http.Request) (allowed []string, version string) {
+	for _, route := range r.routes.byMethod() {
+		probe := &http.Request{Method: route.method, Host: req.Host, URL: req.URL}
+		_, pattern := r.mux.Handler(probe)
+		matched, ok := r.routes.lookup(pattern)
+		if !ok {
+			continue
+		}
+		allowed = append(allowed, route.method)
+		if route.method == http.MethodGet {
+			allowed = append(allowed, http.MethodHead)
+		}
+		version = matched.version
+	}
+	return allowed, version
+}
+
+// Use adds middleware to every route registered on this router afterwards.
+// Middleware runs in the order it was added, the first one being the outermost
+func (r *Router) Use(middlewares ...Middleware) {
+	r.middlewares = append(r.middlewares, middlewares...)
+}
+
+// Group returns a router on the same mux whose routes are prefixed with prefix and
+// inherit this router's middleware; middleware added to the group stays in the group
+func (r *Router) Group(prefix string) *Router {
+	return &Router{
+		mux:         r.mux,
+		prefix:      r.prefix + prefix,
+		version:     r.version,
+		middlewares: append([]Middleware(nil), r.middlewares...),
+		routes:      r.routes,
 	}
 }
 
-func (r *Router) Get(path string, handler http.HandlerFunc) {
-	r.mux.Handle("GET "+path, handler)
+// Version returns a group for one API version, e.g. Version("v1") serves /v1/news
+func (r *Router) Version(version string) *Router {
+	group := r.Group("/" + version)
+	group.version = version
+	group.Use(withAPIVersion(version))
+	return group
+}
+
+// With returns a group without a prefix that adds middlewares, for one-off routes
+func (r *Router) With(middlewares ...Middleware) *Router {
+	group := r.Group("")
+	group.Use(middlewares...)
+	return group
+}
+
+// Get also answers HEAD requests for path, without a body
+func (r *Router) Get(path string, handler http.Handler) {
+	r.handle(http.MethodGet, path, handler)
+}
+
+func (r *Router) Post(path string, handler http.Handler) {
+	r.handle(http.MethodPost, path, handler)
+}
+
+func (r *Router) Put(path string, handler http.Handler) {
+	r.handle(http.MethodPut, path, handler)
 }
 
-func (r *Router) Post(path string, handler http.HandlerFunc) {
-	r.mux.Handle("POST "+path, handler)
+func (r *Router) Patch(path string, handler http.Handler) {
+	r.handle(http.MethodPatch, path, handler)
+}
+
+func (r *Router) Delete(path string, handler http.Handler) {
+	r.handle(http.MethodDelete, path, handler)
+}
+
+func (r *Router) handle(method, path string, handler http.Handler) {
+	route := routeInfo{
+		method:  method,
+		path:    r.prefix + path,
+		version: r.version,
+	}
+	if endpoint, ok := handler.(describedEndpoint); ok {
+		route.description = endpoint.describe()
+	}
+	r.routes.add(route)
+	for i := len(r.middlewares) - 1; i >= 0; i-- {
+		handler = r.middlewares[i](handler)
+	}
+	r.mux.Handle(method+" "+r.prefix+path, handler)
 }
@@ -0,0 +1,42 @@
+package httpserver
+
+import (
+	"net/http"
+
+	apperrors "github.com/onefeed-th/onefeed-th-backend-api/internal/errors"
+)
+
+// statusCodeFromError maps an AppError type to the HTTP status returned to the client
+func statusCodeFromError(err error) int {
+	switch {
+	// an unavailable dependency wins even when a service wrapped it in a DatabaseError
+	case apperrors.HasType(err, apperrors.UnavailableError):
+		return http.StatusServiceUnavailable
+	case apperrors.IsType(err, apperrors.NotFoundError):
+		return http.StatusNotFound
+	case apperrors.IsType(err, apperrors.UnauthorizedError):
+		return http.StatusUnauthorized
+	case apperrors.IsType(err, apperrors.ForbiddenError):
+		return http.StatusForbidden
+	case apperrors.IsType(err, apperrors.TooLargeError):
+		return http.StatusRequestEntityTooLarge
+	case apperrors.IsType(err, apperrors.TimeoutError):
+		return http.StatusGatewayTimeout
+	case apperrors.IsType(err, apperrors.MethodNotAllowedError):
+		return http.StatusMethodNotAllowed
+	case apperrors.IsType(err, apperrors.RateLimitedError):
+		return http.StatusTooManyRequests
+	case apperrors.IsType(err, apperrors.ConflictError):
+		return http.StatusConflict
+	case apperrors.IsType(err, apperrors.InternalError):
+		return http.StatusInternalServerError
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// WriteError writes err in the JSON response envelope with the status mapped from its
+// AppError type, for middleware that rejects a request before it reaches an endpoint
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	writeResponse(w, r, nil, err, nil)
+}
@@ -0,0 +1,116 @@
+package httpserver
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/dto"
+)
+
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+
+	// Report fields by the name the client sent: json, path or query tag
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		for _, tag := range []string{"json", "path", "query"} {
+			name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+			if name == "-" {
+				return ""
+			}
+			if name != "" {
+				return name
+			}
+		}
+		return field.Name
+	})
+
+	// max counts characters, while bcrypt only hashes the first 72 bytes of a password
+	_ = v.RegisterValidation("maxbytes", func(fl validator.FieldLevel) bool {
+		limit, err := strconv.Atoi(fl.Param())
+		return err == nil && len(fl.Field().String()) <= limit
+	})
+	return v
+}
+
+// validateRequest checks the `validate:"..."` tags of a decoded request and returns one
+// entry per failing field; requests without tags always pass
+func validateRequest(req any) ([]dto.FieldError, error) {
+	if v := reflect.ValueOf(req); v.Kind() != reflect.Struct {
+		return nil, nil
+	}
+
+	err := validate.Struct(req)
+	if err == nil {
+		return nil, nil
+	}
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return nil, err
+	}
+
+	fieldErrors := make([]dto.FieldError, 0, len(validationErrors))
+	for _, fieldError := range validationErrors {
+		fieldErrors = append(fieldErrors, dto.FieldError{
+			Field:   fieldError.Field(),
+			Rule:    fieldError.Tag(),
+			Message: validationMessage(fieldError),
+		})
+	}
+	return fieldErrors, nil
+}
+
+// ValidateStruct checks the `validate:"..."` tags of v like a decoded request, for services
+// that validate items one by one instead of failing the whole request
+func ValidateStruct(v any) ([]dto.FieldError, error) {
+	return validateRequest(v)
+}
+
+func validationMessage(fieldError validator.FieldError) string {
+	field := fieldError.Field()
+	param := fieldError.Param()
+	isCollection := fieldError.Kind() == reflect.Slice || fieldError.Kind() == reflect.Map
+
+	switch fieldError.Tag() {
+	case "required":
+		return fmt.Sprintf("%s is required", field)
+	case "url", "http_url":
+		return fmt.Sprintf("%s must be a valid URL", field)
+	case "email":
+		return fmt.Sprintf("%s must be a valid email address", field)
+	case "min":
+		if isCollection {
+			return fmt.Sprintf("%s must contain at least %s items", field, param)
+		}
+		if fieldError.Kind() == reflect.String {
+			return fmt.Sprintf("%s must be at least %s characters", field, param)
+		}
+		return fmt.Sprintf("%s must be at least %s", field, param)
+	case "max":
+		if isCollection {
+			return fmt.Sprintf("%s must contain at most %s items", field, param)
+		}
+		if fieldError.Kind() == reflect.String {
+			return fmt.Sprintf("%s must be at most %s characters", field, param)
+		}
+		return fmt.Sprintf("%s must be at most %s", field, param)
+	case "maxbytes":
+		return fmt.Sprintf("%s must be at most %s bytes", field, param)
+	case "gt":
+		return fmt.Sprintf("%s must be greater than %s", field, param)
+	case "oneof":
+		return fmt.Sprintf("%s must be one of: %s", field, strings.ReplaceAll(param, " ", ", "))
+	case "excludes":
+		return fmt.Sprintf("%s must not contain %q", field, param)
+	case "datetime":
+		return fmt.Sprintf("%s must match the layout %s", field, param)
+	default:
+		return fmt.Sprintf("%s failed the %q rule", field, fieldError.Tag())
+	}
+}
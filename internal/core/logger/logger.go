@@ -0,0 +1,167 @@
+// Package logger sets up the default slog logger: JSON or text, written to stdout, stderr
+// or a rotated file, with a level that can change at runtime globally or per module,
+// high-volume messages sampled and sensitive attributes masked. Module loggers come from
+// For and carry a module attribute.
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/onefeed-th/onefeed-th-backend-api/config"
+)
+
+// ModuleKey is the attribute naming the module a log entry comes from
+const ModuleKey = "module"
+
+var (
+	level slog.LevelVar
+
+	// modules are the per-module levels, swapped as a whole when one changes
+	modules atomic.Pointer[map[string]slog.Level]
+	// setMu serializes the read-modify-write of modules
+	setMu sync.Mutex
+
+	// outputCloser closes the log file of the file output
+	outputCloser io.Closer
+
+	watchOnce sync.Once
+)
+
+func init() {
+	modules.Store(&map[string]slog.Level{})
+}
+
+// Init installs the default logger with the output and levels from the log section of
+// config.yaml. The levels are applied again when a config reload changes them, the output
+// needs a restart
+func Init() error {
+	watchOnce.Do(func() {
+		config.OnChange(func(old, cfg *config.Config) {
+			if old.Log.Level == cfg.Log.Level && reflect.DeepEqual(old.Log.Levels, cfg.Log.Levels) {
+				return
+			}
+			if err := setLevels(cfg); err != nil {
+				slog.Error("Failed to apply log levels", "error", err)
+			}
+		})
+	})
+	if err := setLevels(config.GetConfig()); err != nil {
+		return err
+	}
+
+	output, closer := newOutput(config.GetConfig())
+	// levels are checked first, then sampled entries dropped, before masking what is left
+	slog.SetDefault(slog.New(&moduleHandler{Handler: &sampleHandler{Handler: &redactHandler{Handler: output}}}))
+	outputCloser = closer
+	return nil
+}
+
+// Close releases the log file, so buffered entries are written before the process exits
+func Close() error {
+	if outputCloser == nil {
+		return nil
+	}
+	return outputCloser.Close()
+}
+
+// For returns the default logger for module, whose level can be set apart from the others
+func For(module string) *slog.Logger {
+	return slog.Default().With(ModuleKey, module)
+}
+
+// Level returns the level of the modules without one of their own
+func Level() slog.Level {
+	return level.Level()
+}
+
+// ModuleLevels returns the levels set per module
+func ModuleLevels() map[string]slog.Level {
+	return maps.Clone(*modules.Load())
+}
+
+// SetLevel sets the level of the modules without one of their own
+func SetLevel(l slog.Level) {
+	level.Set(l)
+}
+
+// SetModuleLevel sets the level of module; a nil level makes it follow the global one again
+func SetModuleLevel(module string, l *slog.Level) {
+	setMu.Lock()
+	defer setMu.Unlock()
+
+	levels := maps.Clone(*modules.Load())
+	if l == nil {
+		delete(levels, module)
+	} else {
+		levels[module] = *l
+	}
+	modules.Store(&levels)
+}
+
+// ParseLevel reads a level such as debug, INFO or warn+2
+func ParseLevel(s string) (slog.Level, error) {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(s)); err != nil {
+		return 0, fmt.Errorf("unknown log level %q", s)
+	}
+	return l, nil
+}
+
+func setLevels(cfg *config.Config) error {
+	global, err := ParseLevel(cfg.Log.Level)
+	if err != nil {
+		return err
+	}
+	levels := make(map[string]slog.Level, len(cfg.Log.Levels))
+	for module, name := range cfg.Log.Levels {
+		l, err := ParseLevel(name)
+		if err != nil {
+			return fmt.Errorf("log.levels.%s: %w", module, err)
+		}
+		// viper lower-cases map keys, module names are lower case too
+		levels[strings.ToLower(module)] = l
+	}
+
+	setMu.Lock()
+	defer setMu.Unlock()
+	level.Set(global)
+	modules.Store(&levels)
+	return nil
+}
+
+// moduleHandler drops the entries below the level of their module
+type moduleHandler struct {
+	slog.Handler
+	module string
+}
+
+func (h *moduleHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	if h.module != "" {
+		if threshold, ok := (*modules.Load())[h.module]; ok {
+			return l >= threshold
+		}
+	}
+	return l >= level.Level()
+}
+
+func (h *moduleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	module := h.module
+	for _, attr := range attrs {
+		if attr.Key == ModuleKey {
+			module = attr.Value.String()
+		}
+	}
+	return &moduleHandler{Handler: h.Handler.WithAttrs(attrs), module: module}
+}
+
+func (h *moduleHandler) WithGroup(name string) slog.Handler {
+	return &moduleHandler{Handler: h.Handler.WithGroup(name), module: h.module}
+}
@@ -0,0 +1,71 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/onefeed-th/onefeed-th-backend-api/config"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// allLevels lets the output handlers write every entry; moduleHandler checks the levels
+const allLevels = slog.Level(-1 << 16)
+
+// newOutput builds the handler writing to log.output in log.format. The returned closer
+// releases the log file, when there is one
+func newOutput(cfg *config.Config) (slog.Handler, io.Closer) {
+	switch cfg.Log.Output {
+	case "stderr":
+		return newFormatHandler(cfg.Log.Format, os.Stderr), nil
+	case "file":
+		file := &lumberjack.Logger{
+			Filename:   cfg.Log.File.Path,
+			MaxSize:    cfg.Log.File.MaxSize,
+			MaxBackups: cfg.Log.File.MaxBackups,
+			MaxAge:     cfg.Log.File.MaxAge,
+			Compress:   cfg.Log.File.Compress,
+		}
+		return newFormatHandler(cfg.Log.Format, file), file
+	}
+
+	stdout := newFormatHandler(cfg.Log.Format, os.Stdout)
+	if !cfg.Log.ErrorsToStderr {
+		return stdout, nil
+	}
+	return &splitHandler{low: stdout, high: newFormatHandler(cfg.Log.Format, os.Stderr), at: slog.LevelError}, nil
+}
+
+func newFormatHandler(format string, w io.Writer) slog.Handler {
+	options := &slog.HandlerOptions{Level: allLevels}
+	if format == "text" {
+		return slog.NewTextHandler(w, options)
+	}
+	return slog.NewJSONHandler(w, options)
+}
+
+// splitHandler writes the entries at or above level at to high and the others to low
+type splitHandler struct {
+	low, high slog.Handler
+	at        slog.Level
+}
+
+func (h *splitHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	return true
+}
+
+func (h *splitHandler) Handle(ctx context.Context, record slog.Record) error {
+	if record.Level >= h.at {
+		return h.high.Handle(ctx, record)
+	}
+	return h.low.Handle(ctx, record)
+}
+
+func (h *splitHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &splitHandler{low: h.low.WithAttrs(attrs), high: h.high.WithAttrs(attrs), at: h.at}
+}
+
+func (h *splitHandler) WithGroup(name string) slog.Handler {
+	return &splitHandler{low: h.low.WithGroup(name), high: h.high.WithGroup(name), at: h.at}
+}
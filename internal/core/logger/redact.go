@@ -0,0 +1,93 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"github.com/onefeed-th/onefeed-th-backend-api/config"
+)
+
+// Redacted replaces the value of every sensitive attribute and logged body field
+const Redacted = "[REDACTED]"
+
+// IsSensitive reports whether key is one of fields, ignoring case, "_" and "-", so api_key,
+// API-Key and apiKey all match apiKey
+func IsSensitive(fields []string, key string) bool {
+	key = normalizeKey(key)
+	for _, field := range fields {
+		if normalizeKey(field) == key {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeKey(key string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(key))
+}
+
+// RedactJSON replaces the values of the keys listed in fields, at any depth of a decoded
+// JSON value
+func RedactJSON(value any, fields []string) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			if IsSensitive(fields, key) {
+				v[key] = Redacted
+				continue
+			}
+			v[key] = RedactJSON(item, fields)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = RedactJSON(item, fields)
+		}
+	}
+	return value
+}
+
+// redactHandler masks the attributes listed in log.redactAttrs, in groups too, before the
+// entry is written
+type redactHandler struct {
+	slog.Handler
+}
+
+func (h *redactHandler) Handle(ctx context.Context, record slog.Record) error {
+	fields := config.GetConfig().Log.RedactAttrs
+	redacted := slog.NewRecord(record.Time, record.Level, record.Message, record.PC)
+	record.Attrs(func(attr slog.Attr) bool {
+		redacted.AddAttrs(redactAttr(attr, fields))
+		return true
+	})
+	return h.Handler.Handle(ctx, redacted)
+}
+
+func (h *redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := config.GetConfig().Log.RedactAttrs
+	redacted := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		redacted[i] = redactAttr(attr, fields)
+	}
+	return &redactHandler{Handler: h.Handler.WithAttrs(redacted)}
+}
+
+func (h *redactHandler) WithGroup(name string) slog.Handler {
+	return &redactHandler{Handler: h.Handler.WithGroup(name)}
+}
+
+func redactAttr(attr slog.Attr, fields []string) slog.Attr {
+	attr.Value = attr.Value.Resolve()
+	if attr.Value.Kind() == slog.KindGroup {
+		group := attr.Value.Group()
+		redacted := make([]slog.Attr, len(group))
+		for i, item := range group {
+			redacted[i] = redactAttr(item, fields)
+		}
+		return slog.Attr{Key: attr.Key, Value: slog.GroupValue(redacted...)}
+	}
+	if IsSensitive(fields, attr.Key) {
+		return slog.String(attr.Key, Redacted)
+	}
+	return attr
+}
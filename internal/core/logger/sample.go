@@ -0,0 +1,55 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/onefeed-th/onefeed-th-backend-api/config"
+)
+
+// SampleRateKey is the attribute telling how many entries a sampled entry stands for
+const SampleRateKey = "sample_rate"
+
+// sampleCounts counts the entries per lower-cased message, *atomic.Uint64 by string
+var sampleCounts sync.Map
+
+// sampleHandler writes 1 in N entries of the messages listed in log.sampling, starting with
+// the first one. Errors are always written
+type sampleHandler struct {
+	slog.Handler
+}
+
+func (h *sampleHandler) Handle(ctx context.Context, record slog.Record) error {
+	if record.Level >= slog.LevelError {
+		return h.Handler.Handle(ctx, record)
+	}
+	sampling := config.GetConfig().Log.Sampling
+	if len(sampling) == 0 {
+		return h.Handler.Handle(ctx, record)
+	}
+	// viper lower-cases the keys of log.sampling
+	message := strings.ToLower(record.Message)
+	rate := sampling[message]
+	if rate <= 1 {
+		return h.Handler.Handle(ctx, record)
+	}
+
+	counter, _ := sampleCounts.LoadOrStore(message, new(atomic.Uint64))
+	if (counter.(*atomic.Uint64).Add(1)-1)%uint64(rate) != 0 {
+		return nil
+	}
+	record = record.Clone()
+	record.AddAttrs(slog.Int(SampleRateKey, rate))
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *sampleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &sampleHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *sampleHandler) WithGroup(name string) slog.Handler {
+	return &sampleHandler{Handler: h.Handler.WithGroup(name)}
+}
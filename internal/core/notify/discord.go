@@ -0,0 +1,51 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// discordMaxContentLength is the longest message content a webhook accepts
+const discordMaxContentLength = 2000
+
+// discord posts each message to the target, a Discord webhook URL
+type discord struct {
+	httpClient *http.Client
+	limiter    *limiter
+}
+
+func newDiscord(timeout time.Duration, limiter *limiter) *discord {
+	return &discord{
+		httpClient: &http.Client{Timeout: timeout},
+		limiter:    limiter,
+	}
+}
+
+type discordMessage struct {
+	Content string `json:"content"`
+}
+
+func (d *discord) Send(ctx context.Context, webhookURL string, messages []string) error {
+	for _, message := range messages {
+		if err := d.limiter.wait(ctx, webhookURL); err != nil {
+			return err
+		}
+
+		data, err := json.Marshal(discordMessage{Content: truncate(message, discordMaxContentLength)})
+		if err != nil {
+			return err
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if err := doRequest(d.httpClient, req, "discord"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
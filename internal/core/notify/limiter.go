@@ -0,0 +1,58 @@
+package notify
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// limiter spaces messages to the same target evenly so a channel stays under the
+// receiving service's per-chat limit. Callers wait for their slot instead of failing
+type limiter struct {
+	interval time.Duration
+
+	mu   sync.Mutex
+	next map[string]time.Time
+}
+
+// newLimiter allows perMinute messages per target; zero or less disables limiting
+func newLimiter(perMinute int) *limiter {
+	if perMinute <= 0 {
+		return &limiter{}
+	}
+	return &limiter{
+		interval: time.Minute / time.Duration(perMinute),
+		next:     make(map[string]time.Time),
+	}
+}
+
+// wait blocks until target may receive another message or ctx is done
+func (l *limiter) wait(ctx context.Context, target string) error {
+	if l.interval == 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	slot := l.next[target]
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next[target] = slot.Add(l.interval)
+	// forget targets that have been idle so the map doesn't grow forever
+	for key, next := range l.next {
+		if next.Before(now) {
+			delete(l.next, key)
+		}
+	}
+	l.mu.Unlock()
+
+	timer := time.NewTimer(time.Until(slot))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
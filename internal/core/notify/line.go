@@ -0,0 +1,118 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// lineMessagesPerRequest is the most messages the Messaging API accepts in one call
+	lineMessagesPerRequest = 5
+	// lineMaxTextLength is the longest text message LINE accepts
+	lineMaxTextLength = 5000
+)
+
+// lineNotify posts one message per item with the target's LINE Notify token
+type lineNotify struct {
+	endpoint   string
+	httpClient *http.Client
+	limiter    *limiter
+}
+
+func newLineNotify(endpoint string, timeout time.Duration, limiter *limiter) *lineNotify {
+	return &lineNotify{
+		endpoint:   endpoint,
+		httpClient: &http.Client{Timeout: timeout},
+		limiter:    limiter,
+	}
+}
+
+func (l *lineNotify) Send(ctx context.Context, token string, messages []string) error {
+	for _, message := range messages {
+		if err := l.limiter.wait(ctx, token); err != nil {
+			return err
+		}
+
+		form := url.Values{"message": {"\n" + truncate(message, lineMaxTextLength-1)}}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, l.endpoint, strings.NewReader(form.Encode()))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("Authorization", "Bearer "+token)
+		if err := doRequest(l.httpClient, req, "line"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lineMessaging sends text messages from the official account. An empty target
+// broadcasts to every friend of the account, otherwise it is a user, group or room id
+type lineMessaging struct {
+	endpoint           string
+	channelAccessToken string
+	httpClient         *http.Client
+	limiter            *limiter
+}
+
+func newLineMessaging(endpoint, channelAccessToken string, timeout time.Duration, limiter *limiter) *lineMessaging {
+	return &lineMessaging{
+		endpoint:           strings.TrimRight(endpoint, "/"),
+		channelAccessToken: channelAccessToken,
+		httpClient:         &http.Client{Timeout: timeout},
+		limiter:            limiter,
+	}
+}
+
+type lineTextMessage struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+func (l *lineMessaging) Send(ctx context.Context, to string, messages []string) error {
+	if l.channelAccessToken == "" {
+		return errors.New("line.channelAccessToken is not configured")
+	}
+
+	path := "/v2/bot/message/push"
+	if to == "" {
+		path = "/v2/bot/message/broadcast"
+	}
+	for batch := range slices.Chunk(messages, lineMessagesPerRequest) {
+		if err := l.limiter.wait(ctx, to); err != nil {
+			return err
+		}
+
+		texts := make([]lineTextMessage, 0, len(batch))
+		for _, message := range batch {
+			texts = append(texts, lineTextMessage{Type: "text", Text: truncate(message, lineMaxTextLength)})
+		}
+		body := map[string]any{"messages": texts}
+		if to != "" {
+			body["to"] = to
+		}
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, l.endpoint+path, bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+l.channelAccessToken)
+		if err := doRequest(l.httpClient, req, "line"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+// Package notify pushes news alerts to chat services. Each channel has a Sender that
+// delivers rendered messages to a channel specific target (a token, a chat id, a URL, ...).
+package notify
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/onefeed-th/onefeed-th-backend-api/config"
+)
+
+const (
+	ChannelLineNotify    = "line_notify"
+	ChannelLineMessaging = "line_messaging"
+	ChannelTelegram      = "telegram"
+	ChannelDiscord       = "discord"
+	ChannelWebhook       = "webhook"
+)
+
+// DefaultTemplate is used by rules without a template of their own
+const DefaultTemplate = "[{{.Source}}] {{.Title}}\n{{.Link}}"
+
+// Item is a news item to announce and the data available to templates
+type Item struct {
+	Title       string
+	Link        string
+	Source      string
+	Tags        string
+	PublishedAt time.Time
+}
+
+type Sender interface {
+	Send(ctx context.Context, target string, messages []string) error
+}
+
+// NewSenders returns a sender for every supported channel
+func NewSenders() map[string]Sender {
+	cfg := config.GetConfig()
+	return map[string]Sender{
+		ChannelLineNotify: newLineNotify(cfg.Line.NotifyEndpoint,
+			time.Duration(cfg.Line.Timeout)*time.Second, newLimiter(cfg.Line.RatePerMinute)),
+		ChannelLineMessaging: newLineMessaging(cfg.Line.MessagingEndpoint, cfg.Line.ChannelAccessToken,
+			time.Duration(cfg.Line.Timeout)*time.Second, newLimiter(cfg.Line.RatePerMinute)),
+		ChannelTelegram: newTelegram(cfg.Telegram.Endpoint, cfg.Telegram.BotToken,
+			time.Duration(cfg.Telegram.Timeout)*time.Second, newLimiter(cfg.Telegram.RatePerMinute)),
+		ChannelDiscord: newDiscord(
+			time.Duration(cfg.Discord.Timeout)*time.Second, newLimiter(cfg.Discord.RatePerMinute)),
+		ChannelWebhook: newWebhook(
+			time.Duration(cfg.Webhook.Timeout)*time.Second, newLimiter(cfg.Webhook.RatePerMinute)),
+	}
+}
+
+// ParseTemplate compiles a rule's message template, falling back to DefaultTemplate when
+// it is empty. The template is tried on a sample item so unknown fields are caught early
+func ParseTemplate(text string) (*template.Template, error) {
+	if text == "" {
+		text = DefaultTemplate
+	}
+	tmpl, err := template.New("message").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	if err := tmpl.Execute(io.Discard, Item{}); err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+// Render executes tmpl for item
+func Render(tmpl *template.Template, item Item) (string, error) {
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, item); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// truncate cuts text to at most limit runes, the length cap of the receiving service
+func truncate(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit-1]) + "…"
+}
+
+// doRequest sends req and turns a non-2xx answer into an error that names the service
+func doRequest(client *http.Client, req *http.Request, service string) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s answered %s: %s", service, resp.Status, data)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// telegramMaxTextLength is the longest message the Bot API accepts
+const telegramMaxTextLength = 4096
+
+// telegram posts each message through the bot to the target chat, a channel username
+// such as @onefeed or a numeric chat id. The bot must be allowed to post there
+type telegram struct {
+	endpoint   string
+	botToken   string
+	httpClient *http.Client
+	limiter    *limiter
+}
+
+func newTelegram(endpoint, botToken string, timeout time.Duration, limiter *limiter) *telegram {
+	return &telegram{
+		endpoint:   strings.TrimRight(endpoint, "/"),
+		botToken:   botToken,
+		httpClient: &http.Client{Timeout: timeout},
+		limiter:    limiter,
+	}
+}
+
+type telegramMessage struct {
+	ChatID string `json:"chat_id"`
+	Text   string `json:"text"`
+}
+
+func (t *telegram) Send(ctx context.Context, chatID string, messages []string) error {
+	if t.botToken == "" {
+		return errors.New("telegram.botToken is not configured")
+	}
+
+	for _, message := range messages {
+		if err := t.limiter.wait(ctx, chatID); err != nil {
+			return err
+		}
+
+		data, err := json.Marshal(telegramMessage{ChatID: chatID, Text: truncate(message, telegramMaxTextLength)})
+		if err != nil {
+			return err
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.endpoint+"/bot"+t.botToken+"/sendMessage", bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if err := doRequest(t.httpClient, req, "telegram"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
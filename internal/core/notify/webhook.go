@@ -0,0 +1,49 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// webhook posts each message as {"text": ...} to the target URL, the payload incoming
+// webhooks of Slack, Google Chat and Mattermost accept
+type webhook struct {
+	httpClient *http.Client
+	limiter    *limiter
+}
+
+func newWebhook(timeout time.Duration, limiter *limiter) *webhook {
+	return &webhook{
+		httpClient: &http.Client{Timeout: timeout},
+		limiter:    limiter,
+	}
+}
+
+type webhookMessage struct {
+	Text string `json:"text"`
+}
+
+func (w *webhook) Send(ctx context.Context, webhookURL string, messages []string) error {
+	for _, message := range messages {
+		if err := w.limiter.wait(ctx, webhookURL); err != nil {
+			return err
+		}
+
+		data, err := json.Marshal(webhookMessage{Text: message})
+		if err != nil {
+			return err
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if err := doRequest(w.httpClient, req, "webhook"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
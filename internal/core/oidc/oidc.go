@@ -0,0 +1,186 @@
+// Package oidc verifies ID tokens issued by OpenID Connect providers such as Google and
+// Sign in with Apple, using the provider's published signing keys (JWKS).
+package oidc
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// keysMaxAge bounds how long fetched keys are trusted before they are fetched again
+	keysMaxAge = 6 * time.Hour
+	// minRefreshInterval keeps tokens with unknown key ids from hammering the JWKS endpoint
+	minRefreshInterval = time.Minute
+)
+
+// Identity is the account an ID token was issued for
+type Identity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// Verifier checks ID tokens from one provider. A token is accepted when it is signed by one
+// of the provider's keys, unexpired, from one of issuers and meant for one of audiences
+type Verifier struct {
+	jwksURL    string
+	issuers    []string
+	audiences  []string
+	httpClient *http.Client
+
+	mu        sync.Mutex
+	keys      map[string]*rsa.PublicKey
+	fetchedAt time.Time
+}
+
+func NewVerifier(jwksURL string, issuers, audiences []string) *Verifier {
+	return &Verifier{
+		jwksURL:    jwksURL,
+		issuers:    issuers,
+		audiences:  audiences,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type claims struct {
+	Email         string   `json:"email"`
+	EmailVerified flexBool `json:"email_verified"`
+	Name          string   `json:"name"`
+	jwt.RegisteredClaims
+}
+
+// flexBool accepts both true and "true"; Apple sends email_verified as a string
+type flexBool bool
+
+func (b *flexBool) UnmarshalJSON(data []byte) error {
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	switch v := value.(type) {
+	case bool:
+		*b = flexBool(v)
+	case string:
+		*b = flexBool(v == "true")
+	}
+	return nil
+}
+
+func (v *Verifier) Verify(ctx context.Context, idToken string) (Identity, error) {
+	var c claims
+	_, err := jwt.ParseWithClaims(idToken, &c, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return v.key(ctx, kid)
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return Identity{}, err
+	}
+
+	if !slices.Contains(v.issuers, c.Issuer) {
+		return Identity{}, fmt.Errorf("unexpected issuer %q", c.Issuer)
+	}
+	if !slices.ContainsFunc(c.Audience, func(audience string) bool { return slices.Contains(v.audiences, audience) }) {
+		return Identity{}, errors.New("token is not meant for this app")
+	}
+	if c.Subject == "" {
+		return Identity{}, errors.New("token has no subject")
+	}
+	return Identity{
+		Subject:       c.Subject,
+		Email:         c.Email,
+		EmailVerified: bool(c.EmailVerified),
+		Name:          c.Name,
+	}, nil
+}
+
+// key returns the signing key with id kid, fetching the key set when it is stale or
+// doesn't know kid yet (providers rotate keys)
+func (v *Verifier) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	key, ok := v.keys[kid]
+	age := time.Since(v.fetchedAt)
+	if ok && age < keysMaxAge {
+		return key, nil
+	}
+	if !ok && age < minRefreshInterval {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	keys, err := v.fetchKeys(ctx)
+	if err != nil {
+		if ok {
+			// keep using a known key while the provider is unreachable
+			return key, nil
+		}
+		return nil, err
+	}
+	v.keys = keys
+	v.fetchedAt = time.Now()
+
+	if key, ok = v.keys[kid]; !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	return key, nil
+}
+
+func (v *Verifier) fetchKeys(ctx context.Context) (map[string]*rsa.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.jwksURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("jwks answered %s", resp.Status)
+	}
+	var set struct {
+		Keys []struct {
+			Kid string `json:"kid"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("decode jwks: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			continue
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			continue
+		}
+		keys[k.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	return keys, nil
+}
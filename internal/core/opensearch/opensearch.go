@@ -0,0 +1,276 @@
+// Package opensearch mirrors news into an OpenSearch (or Elasticsearch) index through its
+// REST API. Titles and summaries use the built-in thai analyzer, which tokenizes Thai
+// text far better than Postgres pattern matching.
+package opensearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/onefeed-th/onefeed-th-backend-api/config"
+)
+
+// Document is the indexed form of a news item; its id is also the document _id
+type Document struct {
+	ID          int64     `json:"id"`
+	Title       string    `json:"title"`
+	Summary     string    `json:"summary,omitempty"`
+	Source      string    `json:"source"`
+	Link        string    `json:"link"`
+	Image       string    `json:"image,omitempty"`
+	PublishedAt time.Time `json:"publishedAt"`
+}
+
+// Query is a full text search over titles and summaries
+type Query struct {
+	Text    string
+	Sources []string // empty searches every source
+	From    int
+	Size    int
+}
+
+// Result holds the matching news ids by relevance
+type Result struct {
+	IDs   []int64
+	Total int64
+}
+
+const indexMapping = `{
+  "mappings": {
+    "properties": {
+      "id": { "type": "long" },
+      "title": { "type": "text", "analyzer": "thai" },
+      "summary": { "type": "text", "analyzer": "thai" },
+      "source": { "type": "keyword" },
+      "link": { "type": "keyword", "index": false },
+      "image": { "type": "keyword", "index": false },
+      "publishedAt": { "type": "date" }
+    }
+  }
+}`
+
+type Client struct {
+	baseURL    string
+	index      string
+	username   string
+	password   string
+	httpClient *http.Client
+	// indexReady is set once the index is known to exist
+	indexReady atomic.Bool
+}
+
+// New returns a client for search.openSearch, or nil when no URL is configured
+func New() *Client {
+	cfg := config.GetConfig().Search.OpenSearch
+	if cfg.URL == "" {
+		return nil
+	}
+	return &Client{
+		baseURL:    strings.TrimRight(cfg.URL, "/"),
+		index:      cfg.Index,
+		username:   cfg.Username,
+		password:   cfg.Password,
+		httpClient: &http.Client{Timeout: time.Duration(cfg.Timeout) * time.Second},
+	}
+}
+
+// Index adds or replaces docs with a single bulk request, creating the index on first use
+func (c *Client) Index(ctx context.Context, docs []Document) error {
+	if len(docs) == 0 {
+		return nil
+	}
+	if err := c.ensureIndex(ctx); err != nil {
+		return err
+	}
+
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, doc := range docs {
+		action := map[string]any{"index": map[string]any{"_index": c.index, "_id": strconv.FormatInt(doc.ID, 10)}}
+		if err := encoder.Encode(action); err != nil {
+			return err
+		}
+		if err := encoder.Encode(doc); err != nil {
+			return err
+		}
+	}
+
+	var resp struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			ID     string          `json:"_id"`
+			Status int             `json:"status"`
+			Error  json.RawMessage `json:"error"`
+		} `json:"items"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/_bulk", "application/x-ndjson", &body, &resp); err != nil {
+		return err
+	}
+	if !resp.Errors {
+		return nil
+	}
+	failed := 0
+	var first string
+	for _, item := range resp.Items {
+		for _, result := range item {
+			if result.Status >= 300 {
+				failed++
+				if first == "" {
+					first = fmt.Sprintf("document %s: %s", result.ID, result.Error)
+				}
+			}
+		}
+	}
+	return fmt.Errorf("opensearch: %d of %d documents failed to index, first: %s", failed, len(docs), first)
+}
+
+// DeletePublishedBefore removes documents that fell out of retention
+func (c *Client) DeletePublishedBefore(ctx context.Context, before time.Time) (int64, error) {
+	query := map[string]any{
+		"query": map[string]any{
+			"range": map[string]any{"publishedAt": map[string]any{"lt": before.Format(time.RFC3339)}},
+		},
+	}
+	body, err := json.Marshal(query)
+	if err != nil {
+		return 0, err
+	}
+
+	var resp struct {
+		Deleted int64 `json:"deleted"`
+	}
+	path := "/" + c.index + "/_delete_by_query?conflicts=proceed&ignore_unavailable=true"
+	if err := c.do(ctx, http.MethodPost, path, "application/json", bytes.NewReader(body), &resp); err != nil {
+		return 0, err
+	}
+	return resp.Deleted, nil
+}
+
+// Search returns the ids of the best matching documents
+func (c *Client) Search(ctx context.Context, q Query) (Result, error) {
+	filter := []any{}
+	if len(q.Sources) > 0 {
+		filter = append(filter, map[string]any{"terms": map[string]any{"source": q.Sources}})
+	}
+	query := map[string]any{
+		"from":             q.From,
+		"size":             q.Size,
+		"track_total_hits": true,
+		"_source":          false,
+		"query": map[string]any{
+			"bool": map[string]any{
+				"must": map[string]any{
+					"multi_match": map[string]any{
+						"query":  q.Text,
+						"fields": []string{"title^3", "summary"},
+					},
+				},
+				"filter": filter,
+			},
+		},
+		"sort": []any{"_score", map[string]any{"publishedAt": "desc"}},
+	}
+	body, err := json.Marshal(query)
+	if err != nil {
+		return Result{}, err
+	}
+
+	var resp struct {
+		Hits struct {
+			Total struct {
+				Value int64 `json:"value"`
+			} `json:"total"`
+			Hits []struct {
+				ID string `json:"_id"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/"+c.index+"/_search", "application/json", bytes.NewReader(body), &resp); err != nil {
+		return Result{}, err
+	}
+
+	result := Result{Total: resp.Hits.Total.Value, IDs: make([]int64, 0, len(resp.Hits.Hits))}
+	for _, hit := range resp.Hits.Hits {
+		id, err := strconv.ParseInt(hit.ID, 10, 64)
+		if err != nil {
+			continue
+		}
+		result.IDs = append(result.IDs, id)
+	}
+	return result, nil
+}
+
+// ensureIndex creates the index with the thai mappings unless it already exists
+func (c *Client) ensureIndex(ctx context.Context) error {
+	if c.indexReady.Load() {
+		return nil
+	}
+
+	err := c.do(ctx, http.MethodHead, "/"+c.index, "", nil, nil)
+	if errStatus(err) == http.StatusNotFound {
+		err = c.do(ctx, http.MethodPut, "/"+c.index, "application/json", strings.NewReader(indexMapping), nil)
+		// another instance may have created it in the meantime
+		if errStatus(err) == http.StatusBadRequest && strings.Contains(err.Error(), "resource_already_exists_exception") {
+			err = nil
+		}
+	}
+	if err != nil {
+		return err
+	}
+	c.indexReady.Store(true)
+	return nil
+}
+
+type statusError struct {
+	status int
+	body   string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("opensearch: status %d: %s", e.status, e.body)
+}
+
+func errStatus(err error) int {
+	var e *statusError
+	if errors.As(err, &e) {
+		return e.status
+	}
+	return 0
+}
+
+func (c *Client) do(ctx context.Context, method, path, contentType string, body io.Reader, out any) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("opensearch: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
+		return &statusError{status: resp.StatusCode, body: string(data)}
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,159 @@
+package rds
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+	"github.com/onefeed-th/onefeed-th-backend-api/config"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Cache encodings and compressions, set by cache.encoding and cache.compression
+const (
+	EncodingJSON    = "json"
+	EncodingMsgpack = "msgpack"
+
+	CompressionNone   = "none"
+	CompressionSnappy = "snappy"
+	CompressionZstd   = "zstd"
+)
+
+// Values other than plain JSON start with a header: a zero byte, which JSON never starts
+// with, then the encoding and the compression. Values are decoded by their header, so values
+// written before a config change keep being read until they expire.
+const (
+	headerMarker = 0x00
+	headerSize   = 3
+
+	// encodingEmptyByte marks a result known to be empty, stored by SetEmpty without a payload
+	encodingEmptyByte   = 0
+	encodingJSONByte    = 1
+	encodingMsgpackByte = 2
+
+	compressionNoneByte   = 0
+	compressionSnappyByte = 1
+	compressionZstdByte   = 2
+)
+
+// ErrCachedEmpty is returned by Get for a key stored with SetEmpty; dest is left untouched
+var ErrCachedEmpty = errors.New("cached result is empty")
+
+// emptyValue is what SetEmpty stores
+var emptyValue = []byte{headerMarker, encodingEmptyByte, compressionNoneByte}
+
+var (
+	zstdOnce    sync.Once
+	zstdEncoder *zstd.Encoder
+	zstdDecoder *zstd.Decoder
+	zstdErr     error
+)
+
+// zstdCodec creates the shared zstd encoder and decoder, which are safe for concurrent use
+func zstdCodec() (*zstd.Encoder, *zstd.Decoder, error) {
+	zstdOnce.Do(func() {
+		zstdEncoder, zstdErr = zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedFastest))
+		if zstdErr != nil {
+			return
+		}
+		zstdDecoder, zstdErr = zstd.NewReader(nil)
+	})
+	return zstdEncoder, zstdDecoder, zstdErr
+}
+
+// encode serializes a cached value with the configured encoding, compressing it when it is at
+// least cache.compressMinBytes long. JSON without compression is stored as is
+func encode(value any) ([]byte, error) {
+	cfg := config.GetConfig().Cache
+
+	var payload []byte
+	var err error
+	encoding := byte(encodingJSONByte)
+	if cfg.Encoding == EncodingMsgpack {
+		encoding = encodingMsgpackByte
+		payload, err = marshalMsgpack(value)
+	} else {
+		payload, err = json.Marshal(value)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	compression := byte(compressionNoneByte)
+	if len(payload) >= cfg.CompressMinBytes {
+		switch cfg.Compression {
+		case CompressionSnappy:
+			compression = compressionSnappyByte
+			payload = snappy.Encode(nil, payload)
+		case CompressionZstd:
+			encoder, _, err := zstdCodec()
+			if err != nil {
+				return nil, err
+			}
+			compression = compressionZstdByte
+			payload = encoder.EncodeAll(payload, nil)
+		}
+	}
+
+	if encoding == encodingJSONByte && compression == compressionNoneByte {
+		return payload, nil
+	}
+	return append([]byte{headerMarker, encoding, compression}, payload...), nil
+}
+
+// decode reads a value written by encode, whatever the configuration was at the time
+func decode(data []byte, dest any) error {
+	if len(data) < headerSize || data[0] != headerMarker {
+		return json.Unmarshal(data, dest)
+	}
+	if data[1] == encodingEmptyByte {
+		return ErrCachedEmpty
+	}
+
+	payload := data[headerSize:]
+	var err error
+	switch data[2] {
+	case compressionNoneByte:
+	case compressionSnappyByte:
+		payload, err = snappy.Decode(nil, payload)
+	case compressionZstdByte:
+		var decoder *zstd.Decoder
+		if _, decoder, err = zstdCodec(); err == nil {
+			payload, err = decoder.DecodeAll(payload, nil)
+		}
+	default:
+		err = fmt.Errorf("unknown cache compression %d", data[2])
+	}
+	if err != nil {
+		return err
+	}
+
+	switch data[1] {
+	case encodingJSONByte:
+		return json.Unmarshal(payload, dest)
+	case encodingMsgpackByte:
+		return unmarshalMsgpack(payload, dest)
+	}
+	return errors.New("unknown cache encoding")
+}
+
+// marshalMsgpack follows the json tags, so cached DTOs keep their field names and omitempty
+func marshalMsgpack(value any) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := msgpack.NewEncoder(&buf)
+	encoder.SetCustomStructTag("json")
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func unmarshalMsgpack(data []byte, dest any) error {
+	decoder := msgpack.NewDecoder(bytes.NewReader(data))
+	decoder.SetCustomStructTag("json")
+	return decoder.Decode(dest)
+}
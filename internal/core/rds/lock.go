@@ -0,0 +1,124 @@
+package rds
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// lockKeyPrefix namespaces lock keys apart from cached values
+const lockKeyPrefix = "lock:"
+
+var (
+	// ErrLockHeld is returned by Lock while another owner holds the lock
+	ErrLockHeld = errors.New("lock is held by another owner")
+	// ErrLockLost is returned by Unlock when the lock expired before it was released, and
+	// may since have been taken by another owner
+	ErrLockLost = errors.New("lock expired before it was released")
+)
+
+// releaseScript deletes the lock only while it still holds the owner's token, so an owner
+// whose lock expired can't release the lock of the next owner
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// Lock is a lock held until Unlock is called or its TTL passes
+type Lock struct {
+	key     string
+	token   string
+	release func(ctx context.Context, key, token string) (bool, error)
+}
+
+// Key returns the name the lock was taken under
+func (l *Lock) Key() string {
+	return l.key
+}
+
+// Unlock releases the lock; it returns ErrLockLost when the lock had already expired
+func (l *Lock) Unlock(ctx context.Context) error {
+	released, err := l.release(ctx, lockKeyPrefix+l.key, l.token)
+	if err != nil {
+		return err
+	}
+	if !released {
+		return ErrLockLost
+	}
+	return nil
+}
+
+// lockToken identifies the owner of a lock
+func lockToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func (r *redisClient) Lock(ctx context.Context, key string, ttl time.Duration) (*Lock, error) {
+	client, err := r.conn()
+	if err != nil {
+		return nil, err
+	}
+	token, err := lockToken()
+	if err != nil {
+		return nil, err
+	}
+	acquired, err := client.SetNX(ctx, lockKeyPrefix+key, token, ttl).Result()
+	if err != nil {
+		return nil, err
+	}
+	if !acquired {
+		return nil, ErrLockHeld
+	}
+	return &Lock{key: key, token: token, release: r.releaseLock}, nil
+}
+
+func (r *redisClient) releaseLock(ctx context.Context, key, token string) (bool, error) {
+	client, err := r.conn()
+	if err != nil {
+		return false, err
+	}
+	deleted, err := releaseScript.Run(ctx, client, []string{key}, token).Int()
+	if err != nil {
+		return false, err
+	}
+	return deleted == 1, nil
+}
+
+func (m *memoryClient) Lock(ctx context.Context, key string, ttl time.Duration) (*Lock, error) {
+	token, err := lockToken()
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.expireLocked(lockKeyPrefix + key)
+	if _, ok := m.values[lockKeyPrefix+key]; ok {
+		return nil, ErrLockHeld
+	}
+	m.values[lockKeyPrefix+key] = token
+	m.expires[lockKeyPrefix+key] = time.Now().Add(ttl)
+	return &Lock{key: key, token: token, release: m.releaseLock}, nil
+}
+
+func (m *memoryClient) releaseLock(ctx context.Context, key, token string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.expireLocked(key)
+	if m.values[key] != token {
+		return false, nil
+	}
+	delete(m.values, key)
+	delete(m.expires, key)
+	return true, nil
+}
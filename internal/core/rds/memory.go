@@ -0,0 +1,209 @@
+package rds
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// memoryClient is an in-process RedisClient used by the "memory" storage driver.
+// Misses return redis.Nil so callers treat it exactly like a real cache
+type memoryClient struct {
+	mu      sync.Mutex
+	values  map[string]string
+	hashes  map[string]map[string]string
+	expires map[string]time.Time
+}
+
+var memory *memoryClient
+
+// InitMemory makes NewRedisClient hand out the in-process cache instead of Redis
+func InitMemory() {
+	memory = &memoryClient{
+		values:  make(map[string]string),
+		hashes:  make(map[string]map[string]string),
+		expires: make(map[string]time.Time),
+	}
+}
+
+// expireLocked drops key when its TTL has passed; callers hold mu
+func (m *memoryClient) expireLocked(key string) {
+	if at, ok := m.expires[key]; ok && time.Now().After(at) {
+		delete(m.values, key)
+		delete(m.hashes, key)
+		delete(m.expires, key)
+	}
+}
+
+func (m *memoryClient) keysLocked() []string {
+	keys := make([]string, 0, len(m.values)+len(m.hashes))
+	for key := range m.values {
+		m.expireLocked(key)
+	}
+	for key := range m.hashes {
+		m.expireLocked(key)
+	}
+	for key := range m.values {
+		keys = append(keys, key)
+	}
+	for key := range m.hashes {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func (m *memoryClient) Get(ctx context.Context, key string, dest any) error {
+	m.mu.Lock()
+	m.expireLocked(key)
+	val, ok := m.values[key]
+	m.mu.Unlock()
+
+	if !ok {
+		countGet(key, redis.Nil)
+		return redis.Nil
+	}
+	err := decode([]byte(val), dest)
+	countGet(key, err)
+	return err
+}
+
+func (m *memoryClient) SetWithExpiredTime(ctx context.Context, key string, value any, expiration time.Duration) error {
+	bytes, err := encode(value)
+	countSet(key, err)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.values[key] = string(bytes)
+	delete(m.expires, key)
+	if expiration > 0 {
+		m.expires[key] = time.Now().Add(expiration)
+	}
+	return nil
+}
+
+func (m *memoryClient) Set(ctx context.Context, key string, value any) error {
+	return m.SetWithExpiredTime(ctx, key, value, 0)
+}
+
+func (m *memoryClient) GetMany(ctx context.Context, keys []string, dests []any) []error {
+	errs := make([]error, len(keys))
+	for i, key := range keys {
+		if i >= len(dests) {
+			errs[i] = fmt.Errorf("got %d keys but %d destinations", len(keys), len(dests))
+			continue
+		}
+		errs[i] = m.Get(ctx, key, dests[i])
+	}
+	return errs
+}
+
+func (m *memoryClient) SetMany(ctx context.Context, values map[string]any, expiration time.Duration) error {
+	for key, value := range values {
+		if err := m.SetWithExpiredTime(ctx, key, value, expiration); err != nil {
+			return fmt.Errorf("failed to encode key %q: %w", key, err)
+		}
+	}
+	return nil
+}
+
+func (m *memoryClient) SetEmpty(ctx context.Context, key string, expiration time.Duration) error {
+	countSet(key, nil)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.values[key] = string(emptyValue)
+	delete(m.expires, key)
+	if expiration > 0 {
+		m.expires[key] = time.Now().Add(expiration)
+	}
+	return nil
+}
+
+func (m *memoryClient) RemoveKeyContaining(ctx context.Context, containKey string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, key := range m.keysLocked() {
+		if strings.Contains(key, containKey) {
+			delete(m.values, key)
+			delete(m.hashes, key)
+			delete(m.expires, key)
+		}
+	}
+	return nil
+}
+
+func (m *memoryClient) IncrBy(ctx context.Context, key string, incr int64, expiration time.Duration) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.expireLocked(key)
+	current, _ := strconv.ParseInt(m.values[key], 10, 64)
+	current += incr
+	m.values[key] = strconv.FormatInt(current, 10)
+	m.expires[key] = time.Now().Add(expiration)
+	return current, nil
+}
+
+func (m *memoryClient) HashIncrBy(ctx context.Context, key, field string, incr int64, expiration time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.expireLocked(key)
+	hash, ok := m.hashes[key]
+	if !ok {
+		hash = make(map[string]string)
+		m.hashes[key] = hash
+	}
+	current, _ := strconv.ParseInt(hash[field], 10, 64)
+	hash[field] = strconv.FormatInt(current+incr, 10)
+	m.expires[key] = time.Now().Add(expiration)
+	return nil
+}
+
+func (m *memoryClient) HashGetAll(ctx context.Context, key string) (map[string]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.expireLocked(key)
+	result := make(map[string]string, len(m.hashes[key]))
+	for field, value := range m.hashes[key] {
+		result[field] = value
+	}
+	return result, nil
+}
+
+func (m *memoryClient) ScanKeys(ctx context.Context, pattern string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var result []string
+	for _, key := range m.keysLocked() {
+		// path.Match understands the *, ? and [...] globs Redis uses
+		if ok, _ := path.Match(pattern, key); ok {
+			result = append(result, key)
+		}
+	}
+	return result, nil
+}
+
+func (m *memoryClient) Delete(ctx context.Context, keys ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, key := range keys {
+		delete(m.values, key)
+		delete(m.hashes, key)
+		delete(m.expires, key)
+	}
+	return nil
+}
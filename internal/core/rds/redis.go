@@ -2,18 +2,51 @@ package rds
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/onefeed-th/onefeed-th-backend-api/config"
 	"github.com/redis/go-redis/v9"
 )
 
-var client *redis.Client
+// client holds the shared connection; Reconnect swaps it while requests are running
+var client atomic.Pointer[redis.Client]
 
 func InitRedis(ctx context.Context) error {
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	client.Store(c)
+
+	if err := c.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("failed to ping Redis: %w", err)
+	}
+
+	return nil
+}
+
+// Reconnect builds a fresh client (re-resolving the Redis host) and swaps it in once it
+// answers a ping, closing the previous one. On failure the current client is kept
+func Reconnect(ctx context.Context) error {
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	if err := c.Ping(ctx).Err(); err != nil {
+		c.Close()
+		return fmt.Errorf("failed to ping Redis: %w", err)
+	}
+	if old := client.Swap(c); old != nil {
+		old.Close()
+	}
+	return nil
+}
+
+func newClient() (*redis.Client, error) {
 	config := config.GetConfig()
 	password := config.Redis.Password
 	host := config.Redis.Host
@@ -28,13 +61,13 @@ func InitRedis(ctx context.Context) error {
 	}
 
 	if len(missing) > 0 {
-		return fmt.Errorf("missing required environment variables: %s", strings.Join(missing, ", "))
+		return nil, fmt.Errorf("missing required environment variables: %s", strings.Join(missing, ", "))
 	}
 
 	// Get pool configuration from config
 	poolCfg := config.Redis.Pool
 	
-	client = redis.NewClient(&redis.Options{
+	return redis.NewClient(&redis.Options{
 		Addr:     fmt.Sprintf("%s:%d", host, port),
 		Password: password,
 		DB:       0, // use default DB
@@ -54,89 +87,220 @@ func InitRedis(ctx context.Context) error {
 		MaxRetries:      poolCfg.MaxRetries,
 		MinRetryBackoff: time.Duration(poolCfg.MinRetryBackoff) * time.Millisecond,
 		MaxRetryBackoff: time.Duration(poolCfg.MaxRetryBackoff) * time.Millisecond,
-	})
-
-	if err := client.Ping(ctx).Err(); err != nil {
-		return fmt.Errorf("failed to ping Redis: %w", err)
-	}
-
-	return nil
+	}), nil
 }
 
 func GetClient() *redis.Client {
-	return client
+	return client.Load()
 }
 
 func CloseRedis() error {
-	if client != nil {
+	if client := GetClient(); client != nil {
 		return client.Close()
 	}
 	return nil
 }
 
+// Ping checks that Redis is reachable
+func Ping(ctx context.Context) error {
+	client := GetClient()
+	if client == nil {
+		return errors.New("redis client is not initialized")
+	}
+	return client.Ping(ctx).Err()
+}
+
 // GetRedisStats returns Redis connection pool statistics for monitoring
 func GetRedisStats() *redis.PoolStats {
+	client := GetClient()
 	if client == nil {
 		return nil
 	}
 	return client.PoolStats()
 }
 
+// RedisClient stores values encoded to JSON, or as set by cache.encoding and cache.compression
 type RedisClient interface {
 	SetWithExpiredTime(ctx context.Context, key string, value any, expiration time.Duration) error
 	Set(ctx context.Context, key string, value any) error
+	// SetEmpty caches that a result is empty, so Get answers ErrCachedEmpty instead of a miss
+	SetEmpty(ctx context.Context, key string, expiration time.Duration) error
 	Get(ctx context.Context, key string, dest any) error
+	// GetMany looks up keys in one round trip and decodes them into dests, which must be as
+	// long as keys; errs[i] is what Get would return for keys[i]
+	GetMany(ctx context.Context, keys []string, dests []any) (errs []error)
+	// SetMany stores the values by key in one round trip
+	SetMany(ctx context.Context, values map[string]any, expiration time.Duration) error
 	RemoveKeyContaining(ctx context.Context, containKey string) error
+	IncrBy(ctx context.Context, key string, incr int64, expiration time.Duration) (int64, error)
+	HashIncrBy(ctx context.Context, key, field string, incr int64, expiration time.Duration) error
+	HashGetAll(ctx context.Context, key string) (map[string]string, error)
+	ScanKeys(ctx context.Context, pattern string) ([]string, error)
+	Delete(ctx context.Context, keys ...string) error
+	// Lock takes the lock key for ttl, across every instance sharing the Redis, or returns
+	// ErrLockHeld while another owner holds it
+	Lock(ctx context.Context, key string, ttl time.Duration) (*Lock, error)
 }
 
-type redisClient struct {
-	client *redis.Client
-}
+// ErrUnavailable is returned by cache calls when Redis was never initialized
+var ErrUnavailable = errors.New("redis is unavailable")
+
+// redisClient resolves the shared client on every call, so a client created after
+// startup (or never created, in degraded mode) is handled without a nil-pointer panic
+type redisClient struct{}
 
 func NewRedisClient() RedisClient {
-	return &redisClient{
-		client: GetClient(),
+	if memory != nil {
+		return memory
+	}
+	return &redisClient{}
+}
+
+func (r *redisClient) conn() (*redis.Client, error) {
+	client := GetClient()
+	if client == nil {
+		return nil, ErrUnavailable
 	}
+	return client, nil
 }
 
 func (r *redisClient) Get(ctx context.Context, key string, dest any) error {
-	val, err := r.client.Get(ctx, key).Result()
+	err := r.get(ctx, key, dest)
+	countGet(key, err)
+	return err
+}
+
+func (r *redisClient) get(ctx context.Context, key string, dest any) error {
+	client, err := r.conn()
 	if err != nil {
 		return err
 	}
-	if err := json.Unmarshal([]byte(val), dest); err != nil {
+	val, err := client.Get(ctx, key).Bytes()
+	if err != nil {
 		return err
 	}
-	return nil
+	return decode(val, dest)
 }
 
 func (r *redisClient) SetWithExpiredTime(ctx context.Context, key string, value any, expiration time.Duration) error {
-	if err := r.client.Set(ctx, key, value, expiration).Err(); err != nil {
+	err := r.set(ctx, key, value, expiration)
+	countSet(key, err)
+	return err
+}
+
+func (r *redisClient) set(ctx context.Context, key string, value any, expiration time.Duration) error {
+	client, err := r.conn()
+	if err != nil {
+		return err
+	}
+	bytes, err := encode(value)
+	if err != nil {
+		return err
+	}
+	if err := client.Set(ctx, key, bytes, expiration).Err(); err != nil {
 		return fmt.Errorf("failed to set key %q: %w", key, err)
 	}
 	return nil
 }
 
+// Set stores the value without an expiration, until it is invalidated
 func (r *redisClient) Set(ctx context.Context, key string, value any) error {
-	bytes, err := json.Marshal(value)
+	return r.SetWithExpiredTime(ctx, key, value, 0)
+}
+
+func (r *redisClient) GetMany(ctx context.Context, keys []string, dests []any) []error {
+	errs := make([]error, len(keys))
+	if len(keys) != len(dests) {
+		for i := range errs {
+			errs[i] = fmt.Errorf("got %d keys but %d destinations", len(keys), len(dests))
+		}
+		return errs
+	}
+	if len(keys) == 0 {
+		return errs
+	}
+
+	client, err := r.conn()
+	var vals []any
 	if err == nil {
-		if err := r.client.Set(ctx, key, bytes, 0).Err(); err != nil {
-			return err
+		vals, err = client.MGet(ctx, keys...).Result()
+	}
+	for i, key := range keys {
+		switch {
+		case err != nil:
+			errs[i] = err
+		case vals[i] == nil:
+			errs[i] = redis.Nil
+		default:
+			val, _ := vals[i].(string)
+			errs[i] = decode([]byte(val), dests[i])
+		}
+		countGet(key, errs[i])
+	}
+	return errs
+}
+
+func (r *redisClient) SetMany(ctx context.Context, values map[string]any, expiration time.Duration) error {
+	if len(values) == 0 {
+		return nil
+	}
+	client, err := r.conn()
+	if err != nil {
+		for key := range values {
+			countSet(key, err)
 		}
+		return err
+	}
+
+	encoded := make(map[string][]byte, len(values))
+	for key, value := range values {
+		bytes, err := encode(value)
+		if err != nil {
+			countSet(key, err)
+			return fmt.Errorf("failed to encode key %q: %w", key, err)
+		}
+		encoded[key] = bytes
+	}
+
+	cmds := make(map[string]*redis.StatusCmd, len(encoded))
+	_, err = client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for key, bytes := range encoded {
+			cmds[key] = pipe.Set(ctx, key, bytes, expiration)
+		}
+		return nil
+	})
+	for key, cmd := range cmds {
+		countSet(key, cmd.Err())
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set %d keys: %w", len(encoded), err)
+	}
+	return nil
+}
+
+func (r *redisClient) SetEmpty(ctx context.Context, key string, expiration time.Duration) error {
+	client, err := r.conn()
+	if err == nil {
+		err = client.Set(ctx, key, emptyValue, expiration).Err()
 	}
+	countSet(key, err)
 	return err
 }
 
 func (r *redisClient) RemoveKeyContaining(ctx context.Context, containKey string) error {
+	client, err := r.conn()
+	if err != nil {
+		return err
+	}
 	var cursor uint64
 	for {
-		keys, nextCursor, err := r.client.Scan(ctx, cursor, fmt.Sprintf("*%s*", containKey), 100).Result()
+		keys, nextCursor, err := client.Scan(ctx, cursor, fmt.Sprintf("*%s*", containKey), 100).Result()
 		if err != nil {
 			return err
 		}
 
 		if len(keys) > 0 {
-			if err := r.client.Del(ctx, keys...).Err(); err != nil {
+			if err := client.Del(ctx, keys...).Err(); err != nil {
 				return err
 			}
 		}
@@ -148,3 +312,77 @@ func (r *redisClient) RemoveKeyContaining(ctx context.Context, containKey string
 	}
 	return nil
 }
+
+// IncrBy increments a counter and refreshes the key expiration in one round trip,
+// returning the new value
+func (r *redisClient) IncrBy(ctx context.Context, key string, incr int64, expiration time.Duration) (int64, error) {
+	client, err := r.conn()
+	if err != nil {
+		return 0, err
+	}
+	pipe := client.TxPipeline()
+	value := pipe.IncrBy(ctx, key, incr)
+	pipe.Expire(ctx, key, expiration)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("failed to increment %q: %w", key, err)
+	}
+	return value.Val(), nil
+}
+
+// HashIncrBy increments a hash field and refreshes the key expiration in one round trip
+func (r *redisClient) HashIncrBy(ctx context.Context, key, field string, incr int64, expiration time.Duration) error {
+	client, err := r.conn()
+	if err != nil {
+		return err
+	}
+	pipe := client.TxPipeline()
+	pipe.HIncrBy(ctx, key, field, incr)
+	pipe.Expire(ctx, key, expiration)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to increment %q field %q: %w", key, field, err)
+	}
+	return nil
+}
+
+func (r *redisClient) HashGetAll(ctx context.Context, key string) (map[string]string, error) {
+	client, err := r.conn()
+	if err != nil {
+		return nil, err
+	}
+	return client.HGetAll(ctx, key).Result()
+}
+
+func (r *redisClient) ScanKeys(ctx context.Context, pattern string) ([]string, error) {
+	client, err := r.conn()
+	if err != nil {
+		return nil, err
+	}
+	var (
+		cursor uint64
+		result []string
+	)
+	for {
+		keys, nextCursor, err := client.Scan(ctx, cursor, pattern, 100).Result()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, keys...)
+
+		cursor = nextCursor
+		if cursor == 0 {
+			break
+		}
+	}
+	return result, nil
+}
+
+func (r *redisClient) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	client, err := r.conn()
+	if err != nil {
+		return err
+	}
+	return client.Del(ctx, keys...).Err()
+}
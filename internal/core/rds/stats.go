@@ -0,0 +1,90 @@
+package rds
+
+import (
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// CacheStats counts the cache calls of this process: Get calls that found their key (hits)
+// and those that didn't (misses), values stored, and failed Get and Set calls
+type CacheStats struct {
+	Hits   uint64
+	Misses uint64
+	Sets   uint64
+	Errors uint64
+}
+
+var (
+	statsMu sync.Mutex
+	// stats are kept per key prefix, the part of the key before the first colon
+	stats = make(map[string]*CacheStats)
+)
+
+// GetCacheStats returns the cache calls counted since the process started
+func GetCacheStats() CacheStats {
+	statsMu.Lock()
+	defer statsMu.Unlock()
+
+	var total CacheStats
+	for _, s := range stats {
+		total.Hits += s.Hits
+		total.Misses += s.Misses
+		total.Sets += s.Sets
+		total.Errors += s.Errors
+	}
+	return total
+}
+
+// GetCacheStatsByPrefix returns the cache calls counted since the process started per key
+// prefix
+func GetCacheStatsByPrefix() map[string]CacheStats {
+	statsMu.Lock()
+	defer statsMu.Unlock()
+
+	byPrefix := make(map[string]CacheStats, len(stats))
+	for prefix, s := range stats {
+		byPrefix[prefix] = *s
+	}
+	return byPrefix
+}
+
+// countGet records the outcome of a Get lookup
+func countGet(key string, err error) {
+	count(key, func(s *CacheStats) {
+		switch {
+		case err == nil, errors.Is(err, ErrCachedEmpty):
+			s.Hits++
+		case errors.Is(err, redis.Nil):
+			s.Misses++
+		default:
+			s.Errors++
+		}
+	})
+}
+
+// countSet records the outcome of storing a value
+func countSet(key string, err error) {
+	count(key, func(s *CacheStats) {
+		if err != nil {
+			s.Errors++
+			return
+		}
+		s.Sets++
+	})
+}
+
+func count(key string, update func(s *CacheStats)) {
+	prefix, _, _ := strings.Cut(key, ":")
+
+	statsMu.Lock()
+	defer statsMu.Unlock()
+	s, ok := stats[prefix]
+	if !ok {
+		s = &CacheStats{}
+		stats[prefix] = s
+	}
+	update(s)
+}
@@ -0,0 +1,78 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+)
+
+// AWSOptions configure reads from AWS Secrets Manager. Without static keys the SDK's default
+// credential chain is used: the AWS_* variables, shared config and SSO profiles, web identity
+// tokens, and the ECS task or EC2 instance role
+type AWSOptions struct {
+	Region          string // defaults to AWS_REGION or the profile's region
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	Endpoint        string // overrides https://secretsmanager.<region>.amazonaws.com
+	Timeout         time.Duration
+}
+
+// AWS reads secret-id or secret-id#key references; with a key the secret string is a
+// JSON object, as in the secrets RDS manages
+type AWS struct {
+	client *secretsmanager.Client
+}
+
+func NewAWS(opts AWSOptions) (*AWS, error) {
+	load := []func(*awsconfig.LoadOptions) error{
+		awsconfig.WithHTTPClient(newHTTPClient(opts.Timeout)),
+	}
+	if opts.Region != "" {
+		load = append(load, awsconfig.WithRegion(opts.Region))
+	}
+	if opts.AccessKeyID != "" {
+		load = append(load, awsconfig.WithCredentialsProvider(
+			credentials.NewStaticCredentialsProvider(opts.AccessKeyID, opts.SecretAccessKey, opts.SessionToken),
+		))
+	}
+	// only reads the environment and shared config files, credentials are fetched on first use
+	cfg, err := awsconfig.LoadDefaultConfig(context.Background(), load...)
+	if err != nil {
+		return nil, fmt.Errorf("load aws config: %w", err)
+	}
+	if cfg.Region == "" {
+		return nil, errors.New("aws region is required")
+	}
+
+	client := secretsmanager.NewFromConfig(cfg, func(o *secretsmanager.Options) {
+		if opts.Endpoint != "" {
+			o.BaseEndpoint = aws.String(opts.Endpoint)
+		}
+	})
+	return &AWS{client: client}, nil
+}
+
+func (a *AWS) Secret(ctx context.Context, ref string) (string, error) {
+	id, key := splitRef(ref)
+	out, err := a.client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(id),
+	})
+	if err != nil {
+		return "", fmt.Errorf("aws secrets manager: %w", err)
+	}
+	if out.SecretString == nil {
+		return "", fmt.Errorf("aws secret %s has no secret string", id)
+	}
+	value, err := jsonField(*out.SecretString, key)
+	if err != nil {
+		return "", fmt.Errorf("aws secret %s: %w", id, err)
+	}
+	return value, nil
+}
@@ -0,0 +1,89 @@
+// Package secrets reads credentials from where the deployment keeps them: files such as
+// Docker secrets, HashiCorp Vault or AWS Secrets Manager. It doesn't depend on config, so
+// config can resolve its own secrets with it.
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Providers select where secret references are looked up
+const (
+	ProviderEnv   = "env"
+	ProviderFile  = "file"
+	ProviderVault = "vault"
+	ProviderAWS   = "aws"
+)
+
+// Provider returns the secret a reference points to. References are in the provider's
+// format: a path for files, path#field for Vault and secret-id or secret-id#key for AWS
+type Provider interface {
+	Secret(ctx context.Context, ref string) (string, error)
+}
+
+// File reads secrets from files, such as the ones Docker and Kubernetes mount
+type File struct{}
+
+func (File) Secret(ctx context.Context, ref string) (string, error) {
+	return ReadFile(ref)
+}
+
+// ReadFile returns the content of a secret file without the trailing newline editors and
+// echo add
+func ReadFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read secret file: %w", err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// FromFileEnv reads the file named by the env variable name+"_FILE", the Docker secrets
+// convention. ok is false when the variable isn't set
+func FromFileEnv(name string) (value string, ok bool, err error) {
+	path := os.Getenv(name + "_FILE")
+	if path == "" {
+		return "", false, nil
+	}
+	value, err = ReadFile(path)
+	if err != nil {
+		return "", true, fmt.Errorf("%s_FILE: %w", name, err)
+	}
+	return value, true, nil
+}
+
+// splitRef splits path#field references; field is empty without a #
+func splitRef(ref string) (path, field string) {
+	path, field, _ = strings.Cut(ref, "#")
+	return path, field
+}
+
+// jsonField picks field out of a JSON object secret, or returns the secret as it is
+// without a field
+func jsonField(secret, field string) (string, error) {
+	if field == "" {
+		return secret, nil
+	}
+	var values map[string]any
+	if err := json.Unmarshal([]byte(secret), &values); err != nil {
+		return "", fmt.Errorf("secret is not a JSON object, can't read %q: %w", field, err)
+	}
+	value, ok := values[field]
+	if !ok {
+		return "", fmt.Errorf("secret has no %q field", field)
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	return fmt.Sprint(value), nil
+}
+
+func newHTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout}
+}
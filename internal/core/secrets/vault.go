@@ -0,0 +1,99 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// VaultOptions configure reads from a Vault KV version 2 secrets engine
+type VaultOptions struct {
+	Address   string
+	Token     string
+	TokenFile string // read when Token is empty, such as a token written by Vault Agent
+	Namespace string // Vault Enterprise namespace, empty for the root one
+	Mount     string // path the KV engine is mounted at
+	Timeout   time.Duration
+}
+
+// Vault reads path#field references from a KV version 2 engine; field defaults to password
+type Vault struct {
+	opts       VaultOptions
+	httpClient *http.Client
+}
+
+func NewVault(opts VaultOptions) (*Vault, error) {
+	// the variables the vault CLI reads work as well
+	if opts.Address == "" {
+		opts.Address = os.Getenv("VAULT_ADDR")
+	}
+	if opts.Token == "" && opts.TokenFile == "" {
+		opts.Token = os.Getenv("VAULT_TOKEN")
+	}
+	if opts.Address == "" {
+		return nil, errors.New("vault address is required")
+	}
+	if opts.Token == "" && opts.TokenFile != "" {
+		token, err := ReadFile(opts.TokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("vault token: %w", err)
+		}
+		opts.Token = token
+	}
+	if opts.Token == "" {
+		return nil, errors.New("vault token or tokenFile is required")
+	}
+	opts.Address = strings.TrimRight(opts.Address, "/")
+	opts.Mount = strings.Trim(opts.Mount, "/")
+	return &Vault{opts: opts, httpClient: newHTTPClient(opts.Timeout)}, nil
+}
+
+func (v *Vault) Secret(ctx context.Context, ref string) (string, error) {
+	path, field := splitRef(ref)
+	if field == "" {
+		field = "password"
+	}
+
+	url := fmt.Sprintf("%s/v1/%s/data/%s", v.opts.Address, v.opts.Mount, strings.TrimLeft(path, "/"))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", v.opts.Token)
+	if v.opts.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.opts.Namespace)
+	}
+
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("vault: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("vault answered %s for %s: %s", resp.Status, path, data)
+	}
+	var body struct {
+		Data struct {
+			Data map[string]any `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("decode vault response: %w", err)
+	}
+	value, ok := body.Data.Data[field]
+	if !ok {
+		return "", fmt.Errorf("vault secret %s has no %q field", path, field)
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	return fmt.Sprint(value), nil
+}
@@ -0,0 +1,119 @@
+// Package sitemap writes the sitemaps search engines read to index a website: the sitemap
+// protocol of sitemaps.org and its Google News extension.
+package sitemap
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// MaxURLs is the most URLs a single sitemap may list
+const MaxURLs = 50000
+
+// MaxNewsURLs is the most articles a Google News sitemap may list
+const MaxNewsURLs = 1000
+
+type URL struct {
+	Loc     string
+	LastMod time.Time
+}
+
+// NewsURL is an article of a Google News sitemap
+type NewsURL struct {
+	Loc         string
+	Title       string
+	PublishedAt time.Time
+}
+
+// Publication names the publisher of the articles of a Google News sitemap
+type Publication struct {
+	Name     string
+	Language string
+}
+
+// Document is a written sitemap; it renders itself as the HTTP response body
+type Document []byte
+
+func (d Document) Render(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	_, err := w.Write(d)
+	return err
+}
+
+type urlSet struct {
+	XMLName xml.Name   `xml:"urlset"`
+	NS      string     `xml:"xmlns,attr"`
+	NewsNS  string     `xml:"xmlns:news,attr,omitempty"`
+	URLs    []urlEntry `xml:"url"`
+}
+
+type urlEntry struct {
+	Loc     string     `xml:"loc"`
+	LastMod string     `xml:"lastmod,omitempty"`
+	News    *newsEntry `xml:"news:news,omitempty"`
+}
+
+type newsEntry struct {
+	Publication     newsPublication `xml:"news:publication"`
+	PublicationDate string          `xml:"news:publication_date"`
+	Title           string          `xml:"news:title"`
+}
+
+type newsPublication struct {
+	Name     string `xml:"news:name"`
+	Language string `xml:"news:language"`
+}
+
+const (
+	sitemapNS = "http://www.sitemaps.org/schemas/sitemap/0.9"
+	newsNS    = "http://www.google.com/schemas/sitemap-news/0.9"
+)
+
+// Write writes urls as a sitemap; URLs past MaxURLs are left out
+func Write(urls []URL) (Document, error) {
+	doc := urlSet{NS: sitemapNS, URLs: make([]urlEntry, 0, min(len(urls), MaxURLs))}
+	for _, u := range urls[:min(len(urls), MaxURLs)] {
+		entry := urlEntry{Loc: u.Loc}
+		if !u.LastMod.IsZero() {
+			entry.LastMod = u.LastMod.UTC().Format(time.RFC3339)
+		}
+		doc.URLs = append(doc.URLs, entry)
+	}
+	return encode(doc)
+}
+
+// WriteNews writes urls as a Google News sitemap; articles past MaxNewsURLs are left out
+func WriteNews(publication Publication, urls []NewsURL) (Document, error) {
+	doc := urlSet{NS: sitemapNS, NewsNS: newsNS, URLs: make([]urlEntry, 0, min(len(urls), MaxNewsURLs))}
+	for _, u := range urls[:min(len(urls), MaxNewsURLs)] {
+		doc.URLs = append(doc.URLs, urlEntry{
+			Loc: u.Loc,
+			News: &newsEntry{
+				Publication:     newsPublication{Name: publication.Name, Language: publication.Language},
+				PublicationDate: u.PublishedAt.UTC().Format(time.RFC3339),
+				Title:           u.Title,
+			},
+		})
+	}
+	return encode(doc)
+}
+
+func encode(doc urlSet) (Document, error) {
+	var buf bytes.Buffer
+	if _, err := io.WriteString(&buf, xml.Header); err != nil {
+		return nil, err
+	}
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return nil, fmt.Errorf("failed to encode sitemap: %w", err)
+	}
+	if err := enc.Flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
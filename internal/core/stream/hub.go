@@ -0,0 +1,95 @@
+// Package stream fans events out to in-process subscribers such as SSE and WebSocket
+// connections. Publishing never blocks: a subscriber whose buffer is full misses the
+// event, so one slow client can't hold up the others
+package stream
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+type Hub[T any] struct {
+	mu          sync.RWMutex
+	subscribers map[*Subscription[T]]struct{}
+	closed      bool
+}
+
+func NewHub[T any]() *Hub[T] {
+	return &Hub[T]{
+		subscribers: make(map[*Subscription[T]]struct{}),
+	}
+}
+
+// Subscription receives published events on C until it is closed or the hub shuts down
+type Subscription[T any] struct {
+	C       <-chan T
+	ch      chan T
+	hub     *Hub[T]
+	once    sync.Once
+	dropped atomic.Int64
+}
+
+// Subscribe registers a subscriber that buffers up to buffer events. It returns nil when
+// the hub is closed
+func (h *Hub[T]) Subscribe(buffer int) *Subscription[T] {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return nil
+	}
+
+	ch := make(chan T, buffer)
+	sub := &Subscription[T]{C: ch, ch: ch, hub: h}
+	h.subscribers[sub] = struct{}{}
+	return sub
+}
+
+// Publish delivers event to every subscriber with room in its buffer
+func (h *Hub[T]) Publish(event T) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for sub := range h.subscribers {
+		select {
+		case sub.ch <- event:
+		default:
+			sub.dropped.Add(1)
+		}
+	}
+}
+
+// Count returns the number of active subscribers
+func (h *Hub[T]) Count() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.subscribers)
+}
+
+// Close ends every subscription, closing their channels, and refuses new ones
+func (h *Hub[T]) Close() {
+	h.mu.Lock()
+	subs := make([]*Subscription[T], 0, len(h.subscribers))
+	for sub := range h.subscribers {
+		subs = append(subs, sub)
+	}
+	h.closed = true
+	h.mu.Unlock()
+
+	for _, sub := range subs {
+		sub.Close()
+	}
+}
+
+// Close unsubscribes and closes C; it is safe to call more than once
+func (s *Subscription[T]) Close() {
+	s.once.Do(func() {
+		s.hub.mu.Lock()
+		delete(s.hub.subscribers, s)
+		s.hub.mu.Unlock()
+		close(s.ch)
+	})
+}
+
+// Dropped returns how many events were skipped because the buffer was full
+func (s *Subscription[T]) Dropped() int64 {
+	return s.dropped.Load()
+}
@@ -0,0 +1,93 @@
+package summarizer
+
+import (
+	"context"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+const (
+	// Thai has no sentence punctuation, so short whitespace-separated phrases
+	// are joined until they reach a readable sentence length
+	minSentenceRunes = 40
+	maxSummaryRunes  = 400
+)
+
+type extractive struct {
+	maxSentences int
+}
+
+// NewExtractive returns a summarizer that keeps the leading sentences of the article body
+func NewExtractive(maxSentences int) Summarizer {
+	if maxSentences <= 0 {
+		maxSentences = 3
+	}
+	return &extractive{maxSentences: maxSentences}
+}
+
+func (e *extractive) Summarize(ctx context.Context, title, content string) (string, error) {
+	text := plainText(content)
+	if text == "" {
+		return "", nil
+	}
+
+	var (
+		sentences []string
+		current   strings.Builder
+	)
+	for _, segment := range splitSegments(text) {
+		if strings.TrimSpace(segment) == strings.TrimSpace(title) {
+			continue
+		}
+		if current.Len() > 0 {
+			current.WriteString(" ")
+		}
+		current.WriteString(segment)
+
+		if utf8.RuneCountInString(current.String()) >= minSentenceRunes || endsSentence(segment) {
+			sentences = append(sentences, current.String())
+			current.Reset()
+			if len(sentences) == e.maxSentences {
+				break
+			}
+		}
+	}
+	if current.Len() > 0 && len(sentences) < e.maxSentences {
+		sentences = append(sentences, current.String())
+	}
+
+	return truncateRunes(strings.Join(sentences, " "), maxSummaryRunes), nil
+}
+
+// plainText strips markup from RSS descriptions and collapses whitespace
+func plainText(content string) string {
+	if content == "" {
+		return ""
+	}
+	if doc, err := goquery.NewDocumentFromReader(strings.NewReader(content)); err == nil {
+		content = doc.Text()
+	}
+	return strings.Join(strings.Fields(content), " ")
+}
+
+func splitSegments(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return unicode.IsSpace(r)
+	})
+}
+
+func endsSentence(segment string) bool {
+	last, _ := utf8.DecodeLastRuneInString(segment)
+	return last == '.' || last == '!' || last == '?' || last == '”'
+}
+
+func truncateRunes(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	runes := []rune(s)
+	return strings.TrimSpace(string(runes[:max])) + "…"
+}
@@ -0,0 +1,123 @@
+package summarizer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// LLMOptions configures an OpenAI-compatible chat completions endpoint
+type LLMOptions struct {
+	Endpoint     string
+	APIKey       string
+	Model        string
+	MaxSentences int
+	Timeout      time.Duration
+}
+
+type llm struct {
+	opts       LLMOptions
+	httpClient *http.Client
+	fallback   Summarizer
+}
+
+func NewLLM(opts LLMOptions, fallback Summarizer) Summarizer {
+	if opts.Timeout <= 0 {
+		opts.Timeout = 15 * time.Second
+	}
+	if opts.MaxSentences <= 0 {
+		opts.MaxSentences = 3
+	}
+	return &llm{
+		opts:       opts,
+		httpClient: &http.Client{Timeout: opts.Timeout},
+		fallback:   fallback,
+	}
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model     string        `json:"model"`
+	Messages  []chatMessage `json:"messages"`
+	MaxTokens int           `json:"max_tokens"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+}
+
+func (l *llm) Summarize(ctx context.Context, title, content string) (string, error) {
+	text := plainText(content)
+	if text == "" && title == "" {
+		return "", nil
+	}
+
+	summary, err := l.complete(ctx, title, text)
+	if err != nil {
+		slog.Warn("LLM summarization failed, using fallback", "title", title, "error", err)
+		if l.fallback != nil {
+			return l.fallback.Summarize(ctx, title, content)
+		}
+		return "", err
+	}
+	return summary, nil
+}
+
+func (l *llm) complete(ctx context.Context, title, text string) (string, error) {
+	body, err := json.Marshal(chatRequest{
+		Model: l.opts.Model,
+		Messages: []chatMessage{
+			{
+				Role:    "system",
+				Content: fmt.Sprintf("สรุปข่าวต่อไปนี้เป็นภาษาไทยไม่เกิน %d ประโยค กระชับและเป็นกลาง ไม่ต้องใส่หัวข้อ", l.opts.MaxSentences),
+			},
+			{
+				Role:    "user",
+				Content: title + "\n\n" + truncateRunes(text, 4000),
+			},
+		},
+		MaxTokens: 300,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, l.opts.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if l.opts.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+l.opts.APIKey)
+	}
+
+	resp, err := l.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("summarizer API returned status %d", resp.StatusCode)
+	}
+
+	var parsed chatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return "", fmt.Errorf("failed to decode summarizer response: %w", err)
+	}
+	if len(parsed.Choices) == 0 {
+		return "", fmt.Errorf("summarizer response has no choices")
+	}
+	return truncateRunes(strings.TrimSpace(parsed.Choices[0].Message.Content), maxSummaryRunes), nil
+}
@@ -0,0 +1,47 @@
+package summarizer
+
+import (
+	"context"
+	"time"
+
+	"github.com/onefeed-th/onefeed-th-backend-api/config"
+)
+
+const (
+	ProviderNone       = "none"
+	ProviderExtractive = "extractive"
+	ProviderLLM        = "llm"
+)
+
+// Summarizer produces a short (2-3 sentence) summary of an article
+type Summarizer interface {
+	Summarize(ctx context.Context, title, content string) (string, error)
+}
+
+// New builds the summarizer selected by summarizer.provider.
+// The LLM provider falls back to the extractive summarizer when the API call fails.
+func New() Summarizer {
+	cfg := config.GetConfig().Summarizer
+	extractive := NewExtractive(cfg.MaxSentences)
+
+	switch cfg.Provider {
+	case ProviderNone:
+		return noop{}
+	case ProviderLLM:
+		return NewLLM(LLMOptions{
+			Endpoint:     cfg.LLM.Endpoint,
+			APIKey:       cfg.LLM.APIKey,
+			Model:        cfg.LLM.Model,
+			MaxSentences: cfg.MaxSentences,
+			Timeout:      time.Duration(cfg.LLM.Timeout) * time.Second,
+		}, extractive)
+	default:
+		return extractive
+	}
+}
+
+type noop struct{}
+
+func (noop) Summarize(ctx context.Context, title, content string) (string, error) {
+	return "", nil
+}
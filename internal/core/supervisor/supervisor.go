@@ -0,0 +1,158 @@
+// Package supervisor keeps long-lived connections (Postgres, Redis) healthy: it pings
+// every registered dependency on an interval and rebuilds the connection after repeated
+// failures, recording the outcome for the readiness endpoint.
+package supervisor
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	StatusUnknown = "unknown"
+	StatusUp      = "up"
+	StatusDown    = "down"
+)
+
+const (
+	// maxPingTimeout caps a single ping so one hung dependency can't stall the others
+	maxPingTimeout = 5 * time.Second
+	// reconnectTimeout matches the budget db.InitDB gives the initial connection
+	reconnectTimeout = 10 * time.Second
+)
+
+type Dependency struct {
+	Name     string
+	Critical bool
+	// Ping reports whether the current connection works
+	Ping func(ctx context.Context) error
+	// Reconnect replaces the connection; it must keep the old one when it fails
+	Reconnect func(ctx context.Context) error
+}
+
+type State struct {
+	Name                string
+	Critical            bool
+	Status              string
+	LastCheckedAt       time.Time
+	LastError           string
+	ConsecutiveFailures int
+	Reconnects          int
+}
+
+type entry struct {
+	dependency Dependency
+	state      State
+}
+
+var (
+	mu      sync.RWMutex
+	entries []*entry
+)
+
+// Register adds dependencies to be supervised by Run
+func Register(dependencies ...Dependency) {
+	mu.Lock()
+	defer mu.Unlock()
+	for _, dependency := range dependencies {
+		entries = append(entries, &entry{
+			dependency: dependency,
+			state: State{
+				Name:     dependency.Name,
+				Critical: dependency.Critical,
+				Status:   StatusUnknown,
+			},
+		})
+	}
+}
+
+// States returns a snapshot of every registered dependency, in registration order
+func States() []State {
+	mu.RLock()
+	defer mu.RUnlock()
+	states := make([]State, 0, len(entries))
+	for _, e := range entries {
+		states = append(states, e.state)
+	}
+	return states
+}
+
+// Run checks every dependency immediately and then once per interval until ctx is
+// cancelled. A dependency is reconnected once it has failed failureThreshold checks in a row
+func Run(ctx context.Context, interval time.Duration, failureThreshold int) {
+	failureThreshold = max(failureThreshold, 1)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		mu.RLock()
+		current := append([]*entry(nil), entries...)
+		mu.RUnlock()
+
+		for _, e := range current {
+			check(ctx, e, min(interval, maxPingTimeout), failureThreshold)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func check(ctx context.Context, e *entry, timeout time.Duration, failureThreshold int) {
+	name := e.dependency.Name
+
+	pingCtx, cancel := context.WithTimeout(ctx, timeout)
+	err := e.dependency.Ping(pingCtx)
+	cancel()
+	if ctx.Err() != nil {
+		return
+	}
+
+	mu.Lock()
+	e.state.LastCheckedAt = time.Now()
+	if err == nil {
+		if e.state.Status == StatusDown {
+			slog.Info("Dependency recovered", "dependency", name)
+		}
+		e.state.Status = StatusUp
+		e.state.LastError = ""
+		e.state.ConsecutiveFailures = 0
+		mu.Unlock()
+		return
+	}
+	e.state.Status = StatusDown
+	e.state.LastError = err.Error()
+	e.state.ConsecutiveFailures++
+	failures := e.state.ConsecutiveFailures
+	mu.Unlock()
+
+	slog.Warn("Dependency health check failed",
+		"dependency", name,
+		"consecutive_failures", failures,
+		"error", err,
+	)
+	if failures < failureThreshold || e.dependency.Reconnect == nil {
+		return
+	}
+
+	reconnectCtx, cancel := context.WithTimeout(ctx, reconnectTimeout)
+	err = e.dependency.Reconnect(reconnectCtx)
+	cancel()
+	if err != nil {
+		slog.Error("Failed to reconnect dependency", "dependency", name, "error", err)
+		return
+	}
+
+	slog.Info("Dependency reconnected", "dependency", name, "consecutive_failures", failures)
+	mu.Lock()
+	e.state.Status = StatusUp
+	e.state.LastError = ""
+	e.state.ConsecutiveFailures = 0
+	e.state.Reconnects++
+	mu.Unlock()
+}
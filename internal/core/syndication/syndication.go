@@ -0,0 +1,240 @@
+package syndication
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"time"
+)
+
+type Format string
+
+const (
+	FormatRSS  Format = "rss"
+	FormatAtom Format = "atom"
+)
+
+// Feed is a format-agnostic description of an outgoing feed
+type Feed struct {
+	Title       string
+	Link        string
+	SelfLink    string
+	Description string
+	Updated     time.Time
+	Items       []Item
+}
+
+type Item struct {
+	Title       string
+	Link        string
+	Source      string
+	Image       string
+	Summary     string
+	PublishedAt time.Time
+}
+
+// Document is a feed bound to an output format; it renders itself as the HTTP response body
+type Document struct {
+	Feed   Feed
+	Format Format
+}
+
+func (d Document) Render(w http.ResponseWriter) error {
+	switch d.Format {
+	case FormatAtom:
+		w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+		return d.Feed.WriteAtom(w)
+	default:
+		w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+		return d.Feed.WriteRSS(w)
+	}
+}
+
+type rssDocument struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string       `xml:"title"`
+	Link          string       `xml:"link"`
+	Description   string       `xml:"description"`
+	LastBuildDate string       `xml:"lastBuildDate,omitempty"`
+	AtomLink      *rssAtomLink `xml:"atom:link,omitempty"`
+	Items         []rssItem    `xml:"item"`
+}
+
+type rssAtomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssItem struct {
+	Title       string        `xml:"title"`
+	Link        string        `xml:"link"`
+	Description string        `xml:"description,omitempty"`
+	GUID        rssGUID       `xml:"guid"`
+	PubDate     string        `xml:"pubDate,omitempty"`
+	Source      *rssSource    `xml:"source,omitempty"`
+	Enclosure   *rssEnclosure `xml:"enclosure,omitempty"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssSource struct {
+	URL   string `xml:"url,attr"`
+	Value string `xml:",chardata"`
+}
+
+type rssEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Length int    `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+// WriteRSS writes the feed as RSS 2.0
+func (f Feed) WriteRSS(w io.Writer) error {
+	doc := rssDocument{
+		Version: "2.0",
+		AtomNS:  "http://www.w3.org/2005/Atom",
+		Channel: rssChannel{
+			Title:       f.Title,
+			Link:        f.Link,
+			Description: f.Description,
+			Items:       make([]rssItem, 0, len(f.Items)),
+		},
+	}
+	if !f.Updated.IsZero() {
+		doc.Channel.LastBuildDate = f.Updated.Format(time.RFC1123Z)
+	}
+	if f.SelfLink != "" {
+		doc.Channel.AtomLink = &rssAtomLink{Href: f.SelfLink, Rel: "self", Type: "application/rss+xml"}
+	}
+
+	for _, item := range f.Items {
+		entry := rssItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			Description: item.Summary,
+			GUID:        rssGUID{IsPermaLink: true, Value: item.Link},
+		}
+		if !item.PublishedAt.IsZero() {
+			entry.PubDate = item.PublishedAt.Format(time.RFC1123Z)
+		}
+		if item.Source != "" {
+			// RSS requires the url attribute, which we only have for the aggregated feed itself
+			entry.Source = &rssSource{URL: f.Link, Value: item.Source}
+		}
+		if item.Image != "" {
+			entry.Enclosure = &rssEnclosure{URL: item.Image, Type: imageMimeType(item.Image)}
+		}
+		doc.Channel.Items = append(doc.Channel.Items, entry)
+	}
+
+	return writeXML(w, doc)
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"feed"`
+	NS      string      `xml:"xmlns,attr"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Author  atomAuthor  `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomEntry struct {
+	ID        string     `xml:"id"`
+	Title     string     `xml:"title"`
+	Updated   string     `xml:"updated"`
+	Published string     `xml:"published,omitempty"`
+	Summary   string     `xml:"summary,omitempty"`
+	Links     []atomLink `xml:"link"`
+	Author    atomAuthor `xml:"author"`
+}
+
+// WriteAtom writes the feed as Atom 1.0
+func (f Feed) WriteAtom(w io.Writer) error {
+	updated := f.Updated
+	if updated.IsZero() {
+		updated = time.Now()
+	}
+
+	doc := atomFeed{
+		NS:      "http://www.w3.org/2005/Atom",
+		ID:      f.Link,
+		Title:   f.Title,
+		Updated: updated.Format(time.RFC3339),
+		Links:   []atomLink{{Href: f.Link, Rel: "alternate"}},
+		Author:  atomAuthor{Name: f.Title},
+		Entries: make([]atomEntry, 0, len(f.Items)),
+	}
+	if f.SelfLink != "" {
+		doc.Links = append(doc.Links, atomLink{Href: f.SelfLink, Rel: "self", Type: "application/atom+xml"})
+	}
+
+	for _, item := range f.Items {
+		published := item.PublishedAt
+		if published.IsZero() {
+			published = updated
+		}
+		entry := atomEntry{
+			ID:        item.Link,
+			Title:     item.Title,
+			Updated:   published.Format(time.RFC3339),
+			Published: published.Format(time.RFC3339),
+			Summary:   item.Summary,
+			Links:     []atomLink{{Href: item.Link, Rel: "alternate"}},
+			Author:    atomAuthor{Name: item.Source},
+		}
+		if item.Image != "" {
+			entry.Links = append(entry.Links, atomLink{Href: item.Image, Rel: "enclosure", Type: imageMimeType(item.Image)})
+		}
+		doc.Entries = append(doc.Entries, entry)
+	}
+
+	return writeXML(w, doc)
+}
+
+func writeXML(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("failed to encode feed: %w", err)
+	}
+	return enc.Flush()
+}
+
+func imageMimeType(rawURL string) string {
+	if u, err := url.Parse(rawURL); err == nil {
+		if t := mime.TypeByExtension(path.Ext(u.Path)); t != "" {
+			return t
+		}
+	}
+	return "image/jpeg"
+}
@@ -21,12 +21,22 @@ func StringToPGTypeTextNull(s string) pgtype.Text {
 }
 
 func TimePointerToPGTypeTimestamp(s *time.Time) pgtype.Timestamp {
+	if s == nil {
+		return pgtype.Timestamp{}
+	}
 	return pgtype.Timestamp{
-		Valid: s != nil,
+		Valid: true,
 		Time:  *s,
 	}
 }
 
+func TimeToPGTypeTimestamp(t time.Time) pgtype.Timestamp {
+	return pgtype.Timestamp{
+		Valid: !t.IsZero(),
+		Time:  t,
+	}
+}
+
 func PGTypeTimestampToTime(s pgtype.Timestamp) time.Time {
 	if !s.Valid {
 		return time.Time{}
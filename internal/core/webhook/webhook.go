@@ -0,0 +1,104 @@
+// Package webhook signs and delivers outbound webhook calls.
+//
+// Every delivery is a JSON POST carrying the event name, a unix timestamp and an
+// HMAC-SHA256 signature of "<timestamp>.<body>" keyed with the webhook's secret, so
+// receivers can check both the origin and the age of a call.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/onefeed-th/onefeed-th-backend-api/config"
+)
+
+const (
+	EventHeader     = "X-Onefeed-Event"
+	TimestampHeader = "X-Onefeed-Timestamp"
+	SignatureHeader = "X-Onefeed-Signature"
+
+	secretPrefix = "whsec_"
+)
+
+// GenerateSecret returns a new random signing secret
+func GenerateSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return secretPrefix + hex.EncodeToString(buf), nil
+}
+
+// Sign returns the signature header value for body sent at timestamp
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Result describes one delivery attempt
+type Result struct {
+	StatusCode int // 0 when no response was received
+	Err        error
+	// Retryable tells whether a later attempt may succeed: network errors, 429 and 5xx
+	Retryable bool
+}
+
+// Sender posts signed events. Retries are left to the caller, deliveries run as jobs that
+// are retried with webhook.maxAttempts and webhook.backoff
+type Sender struct {
+	httpClient *http.Client
+}
+
+// NewSender builds a sender from the webhook config
+func NewSender() *Sender {
+	cfg := config.GetConfig().Webhook
+	return &Sender{
+		httpClient: &http.Client{Timeout: time.Duration(cfg.Timeout) * time.Second},
+	}
+}
+
+// Deliver posts body to url once. Result.Err is nil once the receiver answers 2xx
+func (s *Sender) Deliver(ctx context.Context, url, secret, event string, body []byte) Result {
+	var result Result
+	result.StatusCode, result.Retryable, result.Err = s.post(ctx, url, secret, event, body)
+	return result
+}
+
+func (s *Sender) post(ctx context.Context, url, secret, event string, body []byte) (status int, retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, false, err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "OneFeed-Webhook/1.0")
+	req.Header.Set(EventHeader, event)
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, Sign(secret, timestamp, body))
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return 0, true, err
+	}
+	defer resp.Body.Close()
+	// drain a little so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp.StatusCode, false, nil
+	}
+	retry = resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return resp.StatusCode, retry, fmt.Errorf("receiver answered %s", resp.Status)
+}
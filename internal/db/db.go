@@ -2,30 +2,67 @@ package db
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/url"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/onefeed-th/onefeed-th-backend-api/config"
 )
 
-var pool *pgxpool.Pool
+// primary holds the read/write pool; Reconnect swaps it while requests are running
+var primary atomic.Pointer[pgxpool.Pool]
+
+// replicaPools serve read-only queries; replicaNext round-robins between them
+var (
+	replicaPools []*pgxpool.Pool
+	replicaNext  atomic.Uint64
+)
 
 func InitDB() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	dsn, err := buildPostgresDSN()
+	cfg := config.GetConfig()
+
+	p, err := newPool(ctx, cfg.Postgres.Host, cfg.Postgres.Port)
 	if err != nil {
 		return err
 	}
+	primary.Store(p)
+
+	// A replica that cannot be reached is skipped so reads keep working on the primary
+	for _, replica := range cfg.Postgres.Replicas {
+		replicaPool, err := newPool(ctx, replica.Host, replica.Port)
+		if err != nil {
+			slog.Warn("Failed to connect to read replica, skipping",
+				"host", replica.Host,
+				"port", replica.Port,
+				"error", err,
+			)
+			continue
+		}
+		replicaPools = append(replicaPools, replicaPool)
+	}
+
+	return nil
+}
+
+func newPool(ctx context.Context, host string, port int) (*pgxpool.Pool, error) {
+	dsn, err := buildPostgresDSN(host, port)
+	if err != nil {
+		return nil, err
+	}
 
 	// Parse the DSN and configure connection pool
 	poolConfig, err := pgxpool.ParseConfig(dsn)
 	if err != nil {
-		return fmt.Errorf("failed to parse database DSN: %w", err)
+		return nil, fmt.Errorf("failed to parse database DSN: %w", err)
 	}
 
 	// Get pool configuration from config
@@ -39,44 +76,97 @@ func InitDB() error {
 	poolConfig.HealthCheckPeriod = time.Duration(cfg.Postgres.Pool.HealthCheckPeriod) * time.Minute
 	poolConfig.ConnConfig.ConnectTimeout = time.Duration(cfg.Postgres.Pool.ConnectTimeout) * time.Second
 	poolConfig.ConnConfig.RuntimeParams["application_name"] = "onefeed-backend"
+	if cfg.Postgres.StatementTimeout > 0 {
+		// Server-side cap so a runaway query can't hold a pool connection indefinitely
+		poolConfig.ConnConfig.RuntimeParams["statement_timeout"] = strconv.Itoa(cfg.Postgres.StatementTimeout * 1000)
+	}
+	if cfg.Postgres.SlowQuery.Threshold > 0 {
+		poolConfig.ConnConfig.Tracer = &slowQueryTracer{
+			threshold: time.Duration(cfg.Postgres.SlowQuery.Threshold) * time.Millisecond,
+			logArgs:   cfg.Postgres.SlowQuery.LogArgs,
+		}
+	}
 
-	pool, err = pgxpool.NewWithConfig(ctx, poolConfig)
+	p, err := pgxpool.NewWithConfig(ctx, poolConfig)
 	if err != nil {
-		return fmt.Errorf("failed to create connection pool: %w", err)
+		return nil, fmt.Errorf("failed to create connection pool: %w", err)
 	}
 
-	if err = pool.Ping(ctx); err != nil {
-		pool.Close()
-		return fmt.Errorf("failed to connect to database: %w", err)
+	if err = p.Ping(ctx); err != nil {
+		p.Close()
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
-	return nil
+	return p, nil
 }
 
 func GetPool() *pgxpool.Pool {
-	return pool
+	return primary.Load()
+}
+
+// Reconnect builds a fresh primary pool and swaps it in, closing the previous one.
+// On failure the current pool (if any) is kept
+func Reconnect(ctx context.Context) error {
+	cfg := config.GetConfig()
+	p, err := newPool(ctx, cfg.Postgres.Host, cfg.Postgres.Port)
+	if err != nil {
+		return err
+	}
+	if old := primary.Swap(p); old != nil {
+		// Close waits for acquired connections, so in-flight queries finish on the old pool
+		go old.Close()
+	}
+	return nil
+}
+
+// Ping checks that the primary database accepts connections
+func Ping(ctx context.Context) error {
+	pool := GetPool()
+	if pool == nil {
+		return errors.New("database pool is not initialized")
+	}
+	return pool.Ping(ctx)
+}
+
+// GetReadPool returns the next read replica, or the primary when none are configured
+func GetReadPool() *pgxpool.Pool {
+	if len(replicaPools) == 0 {
+		return GetPool()
+	}
+	return replicaPools[replicaNext.Add(1)%uint64(len(replicaPools))]
 }
 
 func CloseDB() {
-	if pool != nil {
+	for _, replicaPool := range replicaPools {
+		replicaPool.Close()
+	}
+	if pool := GetPool(); pool != nil {
 		pool.Close()
 	}
 }
 
 // GetPoolStats returns connection pool statistics for monitoring
 func GetPoolStats() *pgxpool.Stat {
+	pool := GetPool()
 	if pool == nil {
 		return nil
 	}
 	return pool.Stat()
 }
 
-func buildPostgresDSN() (string, error) {
+// GetReplicaPoolStats returns connection pool statistics for every connected read replica
+func GetReplicaPoolStats() []*pgxpool.Stat {
+	stats := make([]*pgxpool.Stat, 0, len(replicaPools))
+	for _, replicaPool := range replicaPools {
+		stats = append(stats, replicaPool.Stat())
+	}
+	return stats
+}
+
+func buildPostgresDSN(host string, port int) (string, error) {
 	config := config.GetConfig()
 	user := config.Postgres.User
 	password := config.Postgres.Password
-	host := config.Postgres.Host
-	port := config.Postgres.Port
 	db := config.Postgres.Dbname
 
 	var missing []string
@@ -95,6 +185,9 @@ func buildPostgresDSN() (string, error) {
 	if db == "" {
 		missing = append(missing, "POSTGRES_DB")
 	}
+	if config.Postgres.SSLMode == "" {
+		missing = append(missing, "POSTGRES_SSL_MODE")
+	}
 
 	if len(missing) > 0 {
 		return "", fmt.Errorf("missing required environment variables: %s", strings.Join(missing, ", "))
@@ -108,7 +201,16 @@ func buildPostgresDSN() (string, error) {
 	}
 
 	q := u.Query()
-	q.Set("sslmode", "disable")
+	q.Set("sslmode", config.Postgres.SSLMode)
+	if config.Postgres.SSLRootCert != "" {
+		q.Set("sslrootcert", config.Postgres.SSLRootCert)
+	}
+	if config.Postgres.SSLCert != "" {
+		q.Set("sslcert", config.Postgres.SSLCert)
+	}
+	if config.Postgres.SSLKey != "" {
+		q.Set("sslkey", config.Postgres.SSLKey)
+	}
 	u.RawQuery = q.Encode()
 
 	return u.String(), nil
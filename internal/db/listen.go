@@ -0,0 +1,75 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// NewsCreatedChannel carries the ids of newly collected news as a JSON array
+const NewsCreatedChannel = "news_created"
+
+const (
+	listenMinBackoff = time.Second
+	listenMaxBackoff = 30 * time.Second
+)
+
+// Listen subscribes to a Postgres NOTIFY channel on the primary and calls handle for every
+// notification until ctx is cancelled. The dedicated connection is re-established with
+// backoff when it drops, so notifications sent while disconnected are missed
+func Listen(ctx context.Context, channel string, handle func(ctx context.Context, payload string)) {
+	backoff := listenMinBackoff
+	for {
+		err := listen(ctx, channel, handle, func() { backoff = listenMinBackoff })
+		if ctx.Err() != nil {
+			return
+		}
+
+		slog.Warn("Notification listener disconnected, reconnecting",
+			"channel", channel,
+			"backoff", backoff,
+			"error", err,
+		)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, listenMaxBackoff)
+	}
+}
+
+func listen(ctx context.Context, channel string, handle func(ctx context.Context, payload string), connected func()) error {
+	pool := GetPool()
+	if pool == nil {
+		return errors.New("database is not initialized")
+	}
+
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	// The connection carries LISTEN state, so it is closed rather than returned to the pool
+	defer func() {
+		conn.Conn().Close(context.Background())
+		conn.Release()
+	}()
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", channel, err)
+	}
+	connected()
+	slog.Info("Listening for notifications", "channel", channel)
+
+	for {
+		notification, err := conn.Conn().WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		handle(ctx, notification.Payload)
+	}
+}
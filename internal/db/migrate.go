@@ -0,0 +1,249 @@
+package db
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationLockID is the advisory lock key held while migrating so that
+// several instances starting at once don't apply the same file twice
+const migrationLockID = 7_201_620_261_016
+
+const createMigrationTable = `CREATE TABLE IF NOT EXISTS schema_migrations (
+  version TEXT PRIMARY KEY,
+  name TEXT NOT NULL,
+  applied_at TIMESTAMP NOT NULL DEFAULT NOW()
+)`
+
+// Migration is a single embedded SQL file named <version>__<name>.sql
+type Migration struct {
+	Version string
+	Name    string
+	SQL     string
+}
+
+// MigrationState reports whether a migration has been applied
+type MigrationState struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// LoadMigrations returns the embedded migrations ordered by version
+func LoadMigrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]Migration, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+		version, name, ok := strings.Cut(strings.TrimSuffix(entry.Name(), ".sql"), "__")
+		if !ok {
+			return nil, fmt.Errorf("invalid migration file name %q: expected <version>__<name>.sql", entry.Name())
+		}
+		content, err := migrationFiles.ReadFile(path.Join("migrations", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, Migration{
+			Version: version,
+			Name:    name,
+			SQL:     string(content),
+		})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// MigrateUp applies every pending migration, each in its own transaction,
+// and returns the versions that were applied. It refuses to run against a schema
+// created before the runner, whose early migrations would drop its tables
+func MigrateUp(ctx context.Context) ([]string, error) {
+	var applied []string
+	err := withMigrationLock(ctx, func(conn *pgx.Conn) error {
+		states, err := migrationStates(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err := checkMigrationHistory(ctx, conn, states); err != nil {
+			return err
+		}
+		for _, state := range states {
+			if state.AppliedAt != nil {
+				continue
+			}
+			slog.Info("Applying migration",
+				"version", state.Version,
+				"name", state.Name,
+			)
+			if err := applyMigration(ctx, conn, state.Migration, true); err != nil {
+				return fmt.Errorf("migration %s__%s failed: %w", state.Version, state.Name, err)
+			}
+			applied = append(applied, state.Version)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// MigrateBaseline records every embedded migration as applied without running it.
+// It is meant for databases whose schema was created by hand before the runner existed
+func MigrateBaseline(ctx context.Context) ([]string, error) {
+	var recorded []string
+	err := withMigrationLock(ctx, func(conn *pgx.Conn) error {
+		states, err := migrationStates(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, state := range states {
+			if state.AppliedAt != nil {
+				continue
+			}
+			if err := applyMigration(ctx, conn, state.Migration, false); err != nil {
+				return err
+			}
+			recorded = append(recorded, state.Version)
+		}
+		return nil
+	})
+	return recorded, err
+}
+
+// MigrationStatus lists every embedded migration with its applied time, if any
+func MigrationStatus(ctx context.Context) ([]MigrationState, error) {
+	var states []MigrationState
+	err := withMigrationLock(ctx, func(conn *pgx.Conn) error {
+		var err error
+		states, err = migrationStates(ctx, conn)
+		return err
+	})
+	return states, err
+}
+
+func withMigrationLock(ctx context.Context, fn func(conn *pgx.Conn) error) error {
+	pool := GetPool()
+	if pool == nil {
+		return fmt.Errorf("database is not initialized")
+	}
+
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1)", migrationLockID); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer conn.Exec(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockID)
+
+	if _, err := conn.Exec(ctx, createMigrationTable); err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+	return fn(conn.Conn())
+}
+
+func migrationStates(ctx context.Context, conn *pgx.Conn) ([]MigrationState, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := conn.Query(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	appliedAt, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (MigrationState, error) {
+		var state MigrationState
+		var at time.Time
+		err := row.Scan(&state.Version, &at)
+		state.AppliedAt = &at
+		return state, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	applied := make(map[string]*time.Time, len(appliedAt))
+	for _, state := range appliedAt {
+		applied[state.Version] = state.AppliedAt
+	}
+
+	states := make([]MigrationState, 0, len(migrations))
+	for _, migration := range migrations {
+		states = append(states, MigrationState{
+			Migration: migration,
+			AppliedAt: applied[migration.Version],
+		})
+	}
+	return states, nil
+}
+
+// checkMigrationHistory fails when none of the migrations is recorded but the tables of the
+// first ones already exist, i.e. the schema was applied by hand and needs a baseline
+func checkMigrationHistory(ctx context.Context, conn *pgx.Conn, states []MigrationState) error {
+	for _, state := range states {
+		if state.AppliedAt != nil {
+			return nil
+		}
+	}
+
+	var exists bool
+	err := conn.QueryRow(ctx, `SELECT EXISTS (
+  SELECT 1
+  FROM information_schema.tables
+  WHERE table_schema = current_schema()
+    AND table_name IN ('sources', 'news')
+)`).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check for an existing schema: %w", err)
+	}
+	if exists {
+		return fmt.Errorf("database has tables but no migration history: run \"migrate baseline\" once to record the existing schema, or migrations would drop its tables")
+	}
+	return nil
+}
+
+func applyMigration(ctx context.Context, conn *pgx.Conn, migration Migration, run bool) error {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Index builds and backfills are not bound by the runtime statement_timeout
+	if _, err := tx.Exec(ctx, "SET LOCAL statement_timeout = 0"); err != nil {
+		return err
+	}
+
+	if run {
+		// No arguments, so pgx uses the simple protocol and multi-statement files are allowed
+		if _, err := tx.Exec(ctx, migration.SQL); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec(ctx,
+		"INSERT INTO schema_migrations (version, name) VALUES ($1, $2)",
+		migration.Version, migration.Name,
+	); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,5 @@
+-- Enable trigram similarity for related news lookups
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Trigram index on title (used in ListSimilarNews)
+CREATE INDEX IF NOT EXISTS idx_news_title_trgm ON news USING GIN (title gin_trgm_ops);
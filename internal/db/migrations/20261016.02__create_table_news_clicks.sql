@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS news_clicks;
+CREATE TABLE news_clicks (
+  news_id BIGINT NOT NULL,
+  bucket_start TIMESTAMP NOT NULL, -- ชั่วโมงที่นับคลิก (UTC)
+  clicks BIGINT NOT NULL DEFAULT 0,
+  PRIMARY KEY (news_id, bucket_start)
+);
+
+-- Index for trending window scans (used in ListTrendingNews)
+CREATE INDEX IF NOT EXISTS idx_news_clicks_bucket_start ON news_clicks(bucket_start DESC);
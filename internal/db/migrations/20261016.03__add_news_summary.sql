@@ -0,0 +1,2 @@
+-- Short 2-3 sentence summary generated during collection
+ALTER TABLE news ADD COLUMN IF NOT EXISTS summary TEXT;
@@ -0,0 +1,18 @@
+DROP TABLE IF EXISTS news_archive;
+CREATE TABLE news_archive (
+  id BIGINT NOT NULL,
+  title TEXT NOT NULL,
+  link TEXT NOT NULL,
+  source TEXT NOT NULL,
+  image_url TEXT,
+  publish_date TIMESTAMP,
+  fetched_at TIMESTAMP,
+  summary TEXT,
+  archived_at TIMESTAMP DEFAULT NOW() -- เวลาที่ย้ายเข้า archive
+) PARTITION BY RANGE (publish_date);
+
+-- Catch-all for rows without publish_date; monthly partitions are created by the retention job
+CREATE TABLE IF NOT EXISTS news_archive_default PARTITION OF news_archive DEFAULT;
+
+-- Index for archive lookups (used in ListArchivedNews)
+CREATE INDEX IF NOT EXISTS idx_news_archive_source_publish_date ON news_archive(source, publish_date DESC);
@@ -0,0 +1,17 @@
+-- Soft delete flag set from the backoffice; hidden rows are excluded from public reads
+ALTER TABLE news ADD COLUMN IF NOT EXISTS hidden BOOLEAN NOT NULL DEFAULT FALSE;
+-- Archived rows keep the flag so moderated items stay on record but out of /news/archive
+ALTER TABLE news_archive ADD COLUMN IF NOT EXISTS hidden BOOLEAN NOT NULL DEFAULT FALSE;
+
+DROP TABLE IF EXISTS news_moderation_logs;
+CREATE TABLE news_moderation_logs (
+  id BIGSERIAL PRIMARY KEY,
+  news_id BIGINT NOT NULL,
+  action TEXT NOT NULL, -- hide หรือ restore
+  reason TEXT,
+  actor TEXT,
+  created_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Index for audit lookups (used in ListNewsModerationLogs)
+CREATE INDEX IF NOT EXISTS idx_news_moderation_logs_news_id ON news_moderation_logs(news_id, created_at DESC);
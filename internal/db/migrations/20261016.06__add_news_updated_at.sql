@@ -0,0 +1,2 @@
+-- Set when re-collection changes the title or image of an existing item
+ALTER TABLE news ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP;
@@ -0,0 +1,76 @@
+-- Rebuild news as a table range-partitioned by publish month.
+-- Partition keys must be part of every unique constraint, so the primary key
+-- becomes (id, publish_date) and link is unique per publish_date.
+-- Rows without publish_date fall back to fetched_at.
+
+ALTER TABLE news RENAME TO news_unpartitioned;
+ALTER SEQUENCE news_id_seq OWNED BY NONE;
+
+CREATE TABLE news (
+  id BIGINT NOT NULL DEFAULT nextval('news_id_seq'),
+  title TEXT NOT NULL,
+  link TEXT NOT NULL,
+  source TEXT NOT NULL,
+  image_url TEXT,
+  publish_date TIMESTAMP NOT NULL,
+  fetched_at TIMESTAMP DEFAULT NOW(),
+  summary TEXT,
+  hidden BOOLEAN NOT NULL DEFAULT FALSE,
+  updated_at TIMESTAMP,
+  PRIMARY KEY (id, publish_date),
+  UNIQUE (link, publish_date)
+) PARTITION BY RANGE (publish_date);
+
+ALTER SEQUENCE news_id_seq OWNED BY news.id;
+
+-- Catch-all for months whose partition has not been created yet
+CREATE TABLE IF NOT EXISTS news_default PARTITION OF news DEFAULT;
+
+DO $$
+DECLARE
+  month TIMESTAMP;
+BEGIN
+  FOR month IN
+    SELECT DISTINCT date_trunc('month', COALESCE(publish_date, fetched_at, NOW()))
+    FROM news_unpartitioned
+  LOOP
+    EXECUTE format(
+      'CREATE TABLE IF NOT EXISTS %I PARTITION OF news FOR VALUES FROM (%L) TO (%L)',
+      'news_' || to_char(month, 'YYYY_MM'),
+      month,
+      month + INTERVAL '1 month'
+    );
+  END LOOP;
+END $$;
+
+INSERT INTO news (
+    id,
+    title,
+    link,
+    source,
+    image_url,
+    publish_date,
+    fetched_at,
+    summary,
+    hidden,
+    updated_at
+  )
+SELECT id,
+  title,
+  link,
+  source,
+  image_url,
+  COALESCE(publish_date, fetched_at, NOW()),
+  fetched_at,
+  summary,
+  hidden,
+  updated_at
+FROM news_unpartitioned;
+
+DROP TABLE news_unpartitioned;
+
+-- Indexes are declared on the parent and created on every partition
+CREATE INDEX IF NOT EXISTS idx_news_source_publish_date ON news(source, publish_date DESC);
+CREATE INDEX IF NOT EXISTS idx_news_publish_date ON news(publish_date DESC);
+CREATE INDEX IF NOT EXISTS idx_news_fetched_at ON news(fetched_at DESC);
+CREATE INDEX IF NOT EXISTS idx_news_title_trgm ON news USING GIN (title gin_trgm_ops);
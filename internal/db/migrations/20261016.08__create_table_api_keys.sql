@@ -0,0 +1,9 @@
+-- API keys for /internal and /backoffice; only the sha256 hash of each key is stored
+CREATE TABLE IF NOT EXISTS api_keys (
+  id BIGSERIAL PRIMARY KEY,
+  name TEXT NOT NULL,
+  key_prefix TEXT NOT NULL, -- ส่วนต้นของคีย์ ไว้แสดงผลให้แอดมินจำได้
+  key_hash TEXT NOT NULL UNIQUE, -- sha256 ของคีย์ (ไม่เก็บคีย์จริง)
+  created_at TIMESTAMP DEFAULT NOW(),
+  revoked_at TIMESTAMP
+);
@@ -0,0 +1,13 @@
+-- Backoffice accounts that sign in for JWTs; roles are admin, editor or viewer
+CREATE TABLE IF NOT EXISTS backoffice_users (
+  id BIGSERIAL PRIMARY KEY,
+  username TEXT NOT NULL UNIQUE,
+  password_hash TEXT NOT NULL, -- bcrypt
+  role TEXT NOT NULL, -- admin, editor หรือ viewer
+  disabled BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at TIMESTAMP DEFAULT NOW(),
+  updated_at TIMESTAMP
+);
+
+-- API keys carry a role too; keys issued before roles existed keep editor access
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS role TEXT NOT NULL DEFAULT 'editor';
@@ -0,0 +1,24 @@
+-- Outbound webhooks called with newly collected news that matches their filters
+CREATE TABLE IF NOT EXISTS webhooks (
+  id BIGSERIAL PRIMARY KEY,
+  url TEXT NOT NULL,
+  secret TEXT NOT NULL, -- คีย์ HMAC สำหรับเซ็น payload (ต้องเก็บค่าจริงไว้เซ็น)
+  sources TEXT[] NOT NULL DEFAULT '{}', -- ว่าง = ทุก source
+  tags TEXT[] NOT NULL DEFAULT '{}',
+  disabled BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at TIMESTAMP DEFAULT NOW(),
+  updated_at TIMESTAMP
+);
+
+-- Dead-letter log of deliveries that failed every attempt
+CREATE TABLE IF NOT EXISTS webhook_failures (
+  id BIGSERIAL PRIMARY KEY,
+  webhook_id BIGINT NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+  payload JSONB NOT NULL,
+  attempts INT NOT NULL,
+  status_code INT, -- NULL เมื่อไม่ได้รับ response
+  error TEXT NOT NULL,
+  created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_failures_webhook_id ON webhook_failures (webhook_id, id DESC);
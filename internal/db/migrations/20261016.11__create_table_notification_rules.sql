@@ -0,0 +1,12 @@
+-- Admin managed rules that push matching new news to chat channels such as LINE
+CREATE TABLE IF NOT EXISTS notification_rules (
+  id BIGSERIAL PRIMARY KEY,
+  name TEXT NOT NULL,
+  channel TEXT NOT NULL, -- line_notify หรือ line_messaging
+  target TEXT NOT NULL DEFAULT '', -- token ของ LINE Notify หรือ id ผู้รับ (ว่าง = broadcast)
+  keywords TEXT[] NOT NULL DEFAULT '{}', -- ว่าง = ไม่กรองคำ
+  sources TEXT[] NOT NULL DEFAULT '{}', -- ว่าง = ทุก source
+  disabled BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at TIMESTAMP DEFAULT NOW(),
+  updated_at TIMESTAMP
+);
@@ -0,0 +1,3 @@
+-- Telegram and Discord publishers: rules can follow source tags and format their own messages
+ALTER TABLE notification_rules ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE notification_rules ADD COLUMN IF NOT EXISTS template TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,23 @@
+-- Devices registered for FCM push notifications and the queue of pushes waiting to be sent
+CREATE TABLE IF NOT EXISTS push_devices (
+  id BIGSERIAL PRIMARY KEY,
+  token TEXT NOT NULL UNIQUE, -- FCM registration token
+  platform TEXT NOT NULL, -- android, ios หรือ web
+  sources TEXT[] NOT NULL DEFAULT '{}', -- ว่าง = ทุก source
+  keywords TEXT[] NOT NULL DEFAULT '{}', -- ว่าง = ไม่กรองคำ
+  created_at TIMESTAMP DEFAULT NOW(),
+  updated_at TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS push_jobs (
+  id BIGSERIAL PRIMARY KEY,
+  device_id BIGINT NOT NULL REFERENCES push_devices(id) ON DELETE CASCADE,
+  news_id BIGINT NOT NULL,
+  attempts INT NOT NULL DEFAULT 0,
+  last_error TEXT,
+  run_at TIMESTAMP NOT NULL DEFAULT NOW(), -- ไม่ส่งก่อนเวลานี้ (retry backoff / lease ของ worker)
+  created_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Index for workers claiming due jobs (used in ClaimPushJobs)
+CREATE INDEX IF NOT EXISTS idx_push_jobs_run_at ON push_jobs(run_at);
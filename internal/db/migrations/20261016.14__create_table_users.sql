@@ -0,0 +1,20 @@
+-- Reader accounts for the app, signed in with email and password or Google/Apple
+CREATE TABLE IF NOT EXISTS users (
+  id BIGSERIAL PRIMARY KEY,
+  email TEXT UNIQUE, -- ตัวพิมพ์เล็กเสมอ, NULL เมื่อผู้ให้บริการ OAuth ไม่ยืนยันอีเมล
+  password_hash TEXT, -- bcrypt, NULL สำหรับบัญชีที่เข้าด้วย OAuth อย่างเดียว
+  display_name TEXT NOT NULL DEFAULT '',
+  last_login_at TIMESTAMP,
+  created_at TIMESTAMP DEFAULT NOW(),
+  updated_at TIMESTAMP
+);
+
+-- OAuth accounts linked to a user, keyed by the provider's subject id
+CREATE TABLE IF NOT EXISTS user_identities (
+  provider TEXT NOT NULL, -- google หรือ apple
+  subject TEXT NOT NULL,
+  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  email TEXT,
+  created_at TIMESTAMP DEFAULT NOW(),
+  PRIMARY KEY (provider, subject)
+);
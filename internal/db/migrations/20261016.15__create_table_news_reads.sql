@@ -0,0 +1,10 @@
+-- Articles each reader has opened, synced across their devices
+CREATE TABLE IF NOT EXISTS news_reads (
+  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  news_id BIGINT NOT NULL, -- ไม่มี FK เพราะ news แบ่ง partition และข่าวเก่าย้ายไป archive
+  read_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (user_id, news_id)
+);
+
+-- Index for a reader's history, newest first (used in ListNewsReads)
+CREATE INDEX IF NOT EXISTS idx_news_reads_user_read_at ON news_reads(user_id, read_at DESC);
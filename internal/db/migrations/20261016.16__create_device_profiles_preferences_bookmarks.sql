@@ -0,0 +1,21 @@
+-- Readers without an account get a profile keyed by the X-Device-ID their app sends
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS device_id TEXT UNIQUE;
+
+-- Sources and tags a reader follows
+CREATE TABLE IF NOT EXISTS user_preferences (
+  user_id BIGINT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+  sources TEXT[] NOT NULL DEFAULT '{}',
+  tags TEXT[] NOT NULL DEFAULT '{}',
+  updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS bookmarks (
+  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  news_id BIGINT NOT NULL, -- ไม่มี FK เพราะ news แบ่ง partition และข่าวเก่าย้ายไป archive
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (user_id, news_id)
+);
+
+-- Index for a reader's bookmarks, newest first (used in ListBookmarks)
+CREATE INDEX IF NOT EXISTS idx_bookmarks_user_created_at ON bookmarks(user_id, created_at DESC);
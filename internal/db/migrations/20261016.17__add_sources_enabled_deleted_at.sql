@@ -0,0 +1,5 @@
+-- Disabled sources are skipped by the collector; deleted ones are hidden everywhere but
+-- kept so their news still resolve to a source
+ALTER TABLE sources
+ADD COLUMN IF NOT EXISTS enabled BOOLEAN NOT NULL DEFAULT TRUE,
+ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP NULL;
@@ -0,0 +1,34 @@
+-- Tags become a managed taxonomy linked to sources. sources.tags is kept as a copy of the
+-- linked tag names, rewritten whenever the links or a tag change
+ALTER TABLE tags DROP CONSTRAINT IF EXISTS tags_name_key;
+ALTER TABLE tags
+ADD COLUMN IF NOT EXISTS description TEXT NULL,
+ADD COLUMN IF NOT EXISTS created_at TIMESTAMP NOT NULL DEFAULT NOW();
+
+-- Tag names are unique regardless of case
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_name_lower ON tags((LOWER(name)));
+
+CREATE TABLE IF NOT EXISTS source_tags (
+  source_id BIGINT NOT NULL REFERENCES sources(id) ON DELETE CASCADE,
+  tag_id INT NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+  PRIMARY KEY (source_id, tag_id)
+);
+
+-- Index for counting and rewriting the sources of a tag
+CREATE INDEX IF NOT EXISTS idx_source_tags_tag_id ON source_tags(tag_id);
+
+-- Backfill from the comma separated tags of existing sources
+INSERT INTO tags (name)
+SELECT DISTINCT ON (LOWER(TRIM(tag))) LEFT(TRIM(tag), 50)
+FROM sources
+  CROSS JOIN LATERAL unnest(string_to_array(sources.tags, ',')) AS tag
+WHERE TRIM(tag) <> ''
+ON CONFLICT DO NOTHING;
+
+INSERT INTO source_tags (source_id, tag_id)
+SELECT sources.id,
+  tags.id
+FROM sources
+  CROSS JOIN LATERAL unnest(string_to_array(sources.tags, ',')) AS tag
+  JOIN tags ON LOWER(tags.name) = LOWER(LEFT(TRIM(tag), 50))
+ON CONFLICT DO NOTHING;
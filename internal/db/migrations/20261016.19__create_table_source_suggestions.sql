@@ -0,0 +1,26 @@
+-- Sources suggested by readers, waiting for an editor to approve or reject them
+CREATE TABLE IF NOT EXISTS source_suggestions (
+  id BIGSERIAL PRIMARY KEY,
+  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  tags TEXT NULL,
+  rss_url TEXT NOT NULL,
+  note TEXT NOT NULL DEFAULT '',
+  push_token TEXT NULL, -- แจ้งผลการพิจารณาไปยังเครื่องของผู้เสนอ
+  status TEXT NOT NULL DEFAULT 'pending', -- pending, approved หรือ rejected
+  review_reason TEXT NULL,
+  reviewed_by TEXT NULL,
+  reviewed_at TIMESTAMP NULL,
+  source_id BIGINT NULL REFERENCES sources(id) ON DELETE SET NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- A feed can only wait for review once
+CREATE UNIQUE INDEX IF NOT EXISTS idx_source_suggestions_pending_rss_url ON source_suggestions(rss_url)
+WHERE status = 'pending';
+
+-- Index for the review queue, oldest first (used in ListPendingSourceSuggestions)
+CREATE INDEX IF NOT EXISTS idx_source_suggestions_status_id ON source_suggestions(status, id);
+
+-- Index for a reader's own suggestions (used in ListUserSourceSuggestions)
+CREATE INDEX IF NOT EXISTS idx_source_suggestions_user_id ON source_suggestions(user_id, id DESC);
@@ -0,0 +1,5 @@
+-- Where the collector looks for the image of an item, for publishers that don't put their
+-- thumbnail where it usually is. NULL keeps the default order
+ALTER TABLE sources
+ADD COLUMN IF NOT EXISTS image_selector TEXT NULL,
+ADD COLUMN IF NOT EXISTS image_fields TEXT[] NULL;
@@ -0,0 +1,21 @@
+-- Background jobs (collection, retention, cache warming, webhook delivery) run by the job workers
+CREATE TABLE IF NOT EXISTS jobs (
+  id BIGSERIAL PRIMARY KEY,
+  type TEXT NOT NULL, -- collect, retention, cache-warm หรือ webhook-delivery
+  payload JSONB NOT NULL DEFAULT '{}',
+  status TEXT NOT NULL DEFAULT 'pending', -- pending, running, succeeded หรือ failed
+  attempts INT NOT NULL DEFAULT 0,
+  max_attempts INT NOT NULL,
+  last_error TEXT,
+  scheduled_at TIMESTAMP NOT NULL DEFAULT NOW(), -- ไม่รันก่อนเวลานี้ (retry backoff / lease ของ worker)
+  started_at TIMESTAMP,
+  finished_at TIMESTAMP,
+  created_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Index for workers claiming due jobs (used in ClaimJobs)
+CREATE INDEX IF NOT EXISTS idx_jobs_scheduled_at ON jobs(scheduled_at)
+WHERE status IN ('pending', 'running');
+
+-- Index for listing the latest jobs of a type (used in ListJobs)
+CREATE INDEX IF NOT EXISTS idx_jobs_type_id ON jobs(type, id DESC);
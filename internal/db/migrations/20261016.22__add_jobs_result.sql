@@ -0,0 +1,3 @@
+-- What the last attempt of a job did, e.g. the item counts of a collection. NULL for jobs
+-- that report nothing
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS result JSONB;
@@ -0,0 +1,17 @@
+-- Feed items the collector couldn't turn into news (bad dates, oversized fields, encoding
+-- errors), kept with the raw item for admins to inspect and reprocess
+CREATE TABLE IF NOT EXISTS dead_letters (
+  id BIGSERIAL PRIMARY KEY,
+  source_id BIGINT NOT NULL REFERENCES sources (id) ON DELETE CASCADE,
+  item_key TEXT NOT NULL, -- guid ของ item, หรือ link, หรือ sha256 ของ payload
+  link TEXT,
+  payload JSONB NOT NULL, -- item ดิบจาก feed
+  error TEXT NOT NULL,
+  occurrences INT NOT NULL DEFAULT 1, -- จำนวนรอบ collect ที่เจอ item นี้
+  created_at TIMESTAMP DEFAULT NOW(),
+  last_seen_at TIMESTAMP DEFAULT NOW(),
+  UNIQUE (source_id, item_key)
+);
+
+-- Index for listing the latest dead letters of a source (used in ListDeadLetters)
+CREATE INDEX IF NOT EXISTS idx_dead_letters_source_id_id ON dead_letters (source_id, id DESC);
@@ -0,0 +1,23 @@
+-- Daily rollups behind the publisher analytics, written by the analytics job: the totals of
+-- every source, and the clicks of every news with its title and link so they outlive the news
+CREATE TABLE IF NOT EXISTS source_stats_daily (
+  source_id BIGINT NOT NULL REFERENCES sources (id) ON DELETE CASCADE,
+  day TIMESTAMP NOT NULL, -- วันที่นับ (UTC)
+  articles BIGINT NOT NULL DEFAULT 0, -- ข่าวที่เก็บได้ในวันนั้น
+  impressions BIGINT NOT NULL DEFAULT 0, -- จำนวนครั้งที่ข่าวแสดงใน /news
+  clicks BIGINT NOT NULL DEFAULT 0,
+  PRIMARY KEY (source_id, day)
+);
+
+CREATE TABLE IF NOT EXISTS news_clicks_daily (
+  news_id BIGINT NOT NULL,
+  day TIMESTAMP NOT NULL, -- วันที่นับคลิก (UTC)
+  source_id BIGINT NOT NULL REFERENCES sources (id) ON DELETE CASCADE,
+  title TEXT NOT NULL,
+  link TEXT NOT NULL,
+  clicks BIGINT NOT NULL DEFAULT 0,
+  PRIMARY KEY (news_id, day)
+);
+
+-- Index for the top news of a source over a range (used in ListTopSourceNews)
+CREATE INDEX IF NOT EXISTS idx_news_clicks_daily_source_id_day ON news_clicks_daily (source_id, day);
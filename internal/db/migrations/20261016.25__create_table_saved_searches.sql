@@ -0,0 +1,16 @@
+-- Searches readers save to be told when new news match them
+CREATE TABLE IF NOT EXISTS saved_searches (
+  id BIGSERIAL PRIMARY KEY,
+  user_id BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  query TEXT NOT NULL, -- ทุกคำต้องอยู่ใน title หรือ summary
+  sources TEXT[] NOT NULL DEFAULT '{}', -- ว่าง = ทุก source
+  push_token TEXT NULL, -- FCM token ของเครื่องที่รับแจ้งเตือน
+  line_user_id TEXT NULL, -- LINE user id ของผู้อ่านที่เป็นเพื่อนกับ official account
+  notified_at TIMESTAMP NULL, -- ครั้งล่าสุดที่มีข่าวตรงและแจ้งเตือน
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP NULL
+);
+
+-- Index for listing the searches of a reader (used in ListSavedSearches)
+CREATE INDEX IF NOT EXISTS idx_saved_searches_user_id ON saved_searches (user_id, id);
@@ -0,0 +1,15 @@
+-- Near-duplicate stories from different sources, grouped by the clustering step of the
+-- collector. Only the news that joined a story have a row; the first news of a story is its
+-- cluster id and has none
+CREATE TABLE IF NOT EXISTS news_clusters (
+  news_id BIGINT PRIMARY KEY,
+  cluster_id BIGINT NOT NULL, -- id ของข่าวแรกของเรื่องเดียวกัน
+  publish_date TIMESTAMP NOT NULL, -- ของข่าว news_id ใช้ลบตาม retention
+  created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Index for the members of a story (used in ListClusteredNews)
+CREATE INDEX IF NOT EXISTS idx_news_clusters_cluster_id ON news_clusters (cluster_id);
+
+-- Index for retention (used in RemoveNewsClustersBefore)
+CREATE INDEX IF NOT EXISTS idx_news_clusters_publish_date ON news_clusters (publish_date);
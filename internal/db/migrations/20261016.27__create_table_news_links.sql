@@ -0,0 +1,30 @@
+-- Links are unique across all of news again. A partitioned table can only enforce
+-- uniqueness together with its partition key, so since partitioning a link was unique per
+-- publish_date and an item whose pubDate changed was stored twice. Every stored link is
+-- now claimed in news_links, which also tells the partition of its news.
+CREATE TABLE IF NOT EXISTS news_links (
+  link TEXT PRIMARY KEY,
+  news_id BIGINT NOT NULL,
+  publish_date TIMESTAMP NOT NULL -- ของข่าว news_id ใช้หา partition และลบตาม retention
+);
+
+-- Index for retention (used in RemoveNewsLinksByPublishedDate)
+CREATE INDEX IF NOT EXISTS idx_news_links_publish_date ON news_links (publish_date);
+
+-- Links stored more than once keep their first news, which reads and bookmarks refer to
+DELETE FROM news duplicate
+USING news original
+WHERE duplicate.link = original.link
+  AND duplicate.id > original.id;
+
+INSERT INTO news_links (link, news_id, publish_date)
+SELECT link,
+  id,
+  publish_date
+FROM news
+ON CONFLICT (link) DO NOTHING;
+
+ALTER TABLE news DROP CONSTRAINT IF EXISTS news_link_publish_date_key;
+
+-- Index for lookups by link (used in ListNewsByLinks)
+CREATE INDEX IF NOT EXISTS idx_news_link ON news (link);
@@ -0,0 +1,24 @@
+-- Jobs of a type that is queued once: at most one of them may be pending, which CreateJob
+-- relies on to return the pending job instead of queueing another
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS is_unique BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Of the types queued once, keep only the oldest pending job queued before the column existed
+DELETE FROM jobs
+WHERE status = 'pending'
+  AND NOT is_unique
+  AND type IN ('collect', 'retention', 'cache-warm', 'sitemap', 'analytics')
+  AND EXISTS (
+    SELECT 1
+    FROM jobs older
+    WHERE older.type = jobs.type
+      AND older.status = 'pending'
+      AND older.id < jobs.id
+  );
+
+UPDATE jobs
+SET is_unique = TRUE
+WHERE type IN ('collect', 'retention', 'cache-warm', 'sitemap', 'analytics');
+
+-- One pending job per unique type (used in CreateJob)
+CREATE UNIQUE INDEX IF NOT EXISTS idx_jobs_pending_unique_type ON jobs(type)
+WHERE status = 'pending' AND is_unique;
@@ -0,0 +1,148 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// slow queries are logged with their SQL and arguments cut to these lengths, so a bulk
+	// insert doesn't flood the log and long values such as tokens aren't logged whole
+	maxLoggedSQLLength = 2000
+	maxLoggedArgLength = 64
+)
+
+// SlowQueryStat counts the queries of one name that ran over postgres.slowQuery.threshold
+type SlowQueryStat struct {
+	Count       uint64
+	MaxDuration time.Duration
+}
+
+var (
+	slowQueriesMu sync.Mutex
+	slowQueries   = make(map[string]*SlowQueryStat)
+)
+
+// GetSlowQueryStats returns the slow queries counted by query name since the process started
+func GetSlowQueryStats() map[string]SlowQueryStat {
+	slowQueriesMu.Lock()
+	defer slowQueriesMu.Unlock()
+
+	stats := make(map[string]SlowQueryStat, len(slowQueries))
+	for name, stat := range slowQueries {
+		stats[name] = *stat
+	}
+	return stats
+}
+
+func countSlowQuery(name string, elapsed time.Duration) {
+	slowQueriesMu.Lock()
+	defer slowQueriesMu.Unlock()
+
+	stat, ok := slowQueries[name]
+	if !ok {
+		stat = &SlowQueryStat{}
+		slowQueries[name] = stat
+	}
+	stat.Count++
+	stat.MaxDuration = max(stat.MaxDuration, elapsed)
+}
+
+// slowQueryTracer logs and counts the queries that take at least threshold
+type slowQueryTracer struct {
+	threshold time.Duration
+	logArgs   bool
+}
+
+type queryStartKey struct{}
+
+type queryStart struct {
+	at   time.Time
+	sql  string
+	args []any
+}
+
+func (t *slowQueryTracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, queryStartKey{}, queryStart{
+		at:   time.Now(),
+		sql:  data.SQL,
+		args: data.Args,
+	})
+}
+
+func (t *slowQueryTracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	start, ok := ctx.Value(queryStartKey{}).(queryStart)
+	if !ok {
+		return
+	}
+	elapsed := time.Since(start.at)
+	if elapsed < t.threshold {
+		return
+	}
+
+	name := queryName(start.sql)
+	countSlowQuery(name, elapsed)
+
+	attrs := []any{
+		"query", name,
+		"duration_ms", elapsed.Milliseconds(),
+		"rows", data.CommandTag.RowsAffected(),
+		"sql", sanitizeSQL(start.sql),
+	}
+	if t.logArgs {
+		attrs = append(attrs, "args", sanitizeArgs(start.args))
+	}
+	if data.Err != nil {
+		attrs = append(attrs, "error", data.Err)
+	}
+	slog.Warn("Slow query", attrs...)
+}
+
+// queryName returns the sqlc name of a query, from its leading "-- name: X :one" comment
+func queryName(sql string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(sql), "\n")
+	rest, ok := strings.CutPrefix(line, "-- name: ")
+	if !ok {
+		return "unnamed"
+	}
+	name, _, _ := strings.Cut(rest, " ")
+	return name
+}
+
+// sanitizeSQL drops the sqlc name comment and folds the query onto one line
+func sanitizeSQL(sql string) string {
+	sql = strings.TrimSpace(sql)
+	if strings.HasPrefix(sql, "-- name: ") {
+		_, sql, _ = strings.Cut(sql, "\n")
+	}
+	return truncate(strings.Join(strings.Fields(sql), " "), maxLoggedSQLLength)
+}
+
+// sanitizeArgs formats each argument, cut to maxLoggedArgLength; byte slices are only
+// described by their length
+func sanitizeArgs(args []any) []string {
+	sanitized := make([]string, 0, len(args))
+	for _, arg := range args {
+		if b, ok := arg.([]byte); ok {
+			sanitized = append(sanitized, fmt.Sprintf("<%d bytes>", len(b)))
+			continue
+		}
+		sanitized = append(sanitized, truncate(fmt.Sprintf("%v", arg), maxLoggedArgLength))
+	}
+	return sanitized
+}
+
+func truncate(s string, maxRunes int) string {
+	if utf8.RuneCountInString(s) <= maxRunes {
+		return s
+	}
+	runes := []rune(s)
+	return fmt.Sprintf("%s…(%d chars)", string(runes[:maxRunes]), len(runes))
+}
@@ -0,0 +1,19 @@
+package dto
+
+type LoginRequest struct {
+	Username string `json:"username" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken" validate:"required"`
+}
+
+type TokenResponse struct {
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken"`
+	TokenType    string `json:"tokenType"`
+	// ExpiresIn is the access token lifetime in seconds
+	ExpiresIn int64  `json:"expiresIn"`
+	Role      string `json:"role"`
+}
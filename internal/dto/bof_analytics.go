@@ -0,0 +1,39 @@
+package dto
+
+type SourceAnalyticsRequest struct {
+	// From and To are dates in YYYY-MM-DD; To is inclusive
+	From     string  `query:"from"`
+	To       string  `query:"to"`
+	SourceID []int64 `query:"sourceId"`
+	// Interval groups the series by day, week (starting Monday) or month
+	Interval string `query:"interval" validate:"omitempty,oneof=day week month"`
+	// Top is how many of the most clicked articles to list per source
+	Top int32 `query:"top" validate:"omitempty,min=1,max=50"`
+}
+
+type SourceAnalyticsResponse struct {
+	SourceID    int64                    `json:"sourceId"`
+	Source      string                   `json:"source"`
+	Articles    int64                    `json:"articles"`
+	Impressions int64                    `json:"impressions"`
+	Clicks      int64                    `json:"clicks"`
+	CTR         float64                  `json:"ctr"`
+	Series      []SourceAnalyticsPoint   `json:"series"`
+	TopArticles []SourceAnalyticsArticle `json:"topArticles"`
+}
+
+// SourceAnalyticsPoint is one interval of a source's series; Date is its first day
+type SourceAnalyticsPoint struct {
+	Date        string  `json:"date"`
+	Articles    int64   `json:"articles"`
+	Impressions int64   `json:"impressions"`
+	Clicks      int64   `json:"clicks"`
+	CTR         float64 `json:"ctr"`
+}
+
+type SourceAnalyticsArticle struct {
+	ID     int64  `json:"id"`
+	Title  string `json:"title"`
+	Link   string `json:"link"`
+	Clicks int64  `json:"clicks"`
+}
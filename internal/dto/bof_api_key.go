@@ -0,0 +1,28 @@
+package dto
+
+import "time"
+
+type APIKeyCreateRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+	// Role defaults to editor
+	Role string `json:"role" validate:"omitempty,oneof=admin editor viewer"`
+}
+
+// APIKeyCreateResponse is the only time the plain key is returned
+type APIKeyCreateResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
+
+type APIKeyRevokeRequest struct {
+	ID int64 `path:"id" validate:"gt=0"`
+}
+
+type APIKeyResponse struct {
+	ID        int64      `json:"id"`
+	Name      string     `json:"name"`
+	Prefix    string     `json:"prefix"`
+	Role      string     `json:"role"`
+	CreatedAt time.Time  `json:"createdAt"`
+	RevokedAt *time.Time `json:"revokedAt,omitempty"`
+}
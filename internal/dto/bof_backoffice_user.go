@@ -0,0 +1,25 @@
+package dto
+
+import "time"
+
+type BackofficeUserCreateRequest struct {
+	Username string `json:"username" validate:"required,max=100"`
+	Password string `json:"password" validate:"required,min=12,maxbytes=72"`
+	Role     string `json:"role" validate:"required,oneof=admin editor viewer"`
+}
+
+// BackofficeUserUpdateRequest changes only the fields that are sent
+type BackofficeUserUpdateRequest struct {
+	ID       int64   `path:"id" validate:"gt=0"`
+	Role     *string `json:"role" validate:"omitempty,oneof=admin editor viewer"`
+	Disabled *bool   `json:"disabled"`
+}
+
+type BackofficeUserResponse struct {
+	ID        int64      `json:"id"`
+	Username  string     `json:"username"`
+	Role      string     `json:"role"`
+	Disabled  bool       `json:"disabled"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+}
@@ -0,0 +1,53 @@
+package dto
+
+import "time"
+
+type DashboardGetRequest struct {
+	// Top is how many of the busiest sources to list, 10 by default
+	Top int32 `query:"top" validate:"omitempty,min=1,max=50"`
+}
+
+// DashboardResponse has the numbers of the backoffice home page. Articles count visible
+// news; articlesToday are the ones fetched since midnight UTC
+type DashboardResponse struct {
+	TotalArticles  int64                   `json:"totalArticles"`
+	ArticlesToday  int64                   `json:"articlesToday"`
+	Sources        DashboardSourceCounts   `json:"sources"`
+	FailingSources []DashboardFailedSource `json:"failingSources"`
+	TopSources     []DashboardSourceVolume `json:"topSources"`
+	Cache          CacheStats              `json:"cache"`
+	// LastCollection is null until a collection has run
+	LastCollection *DashboardCollectionRun `json:"lastCollection"`
+}
+
+type DashboardSourceCounts struct {
+	Total    int `json:"total"`
+	Enabled  int `json:"enabled"`
+	Disabled int `json:"disabled"`
+}
+
+// DashboardFailedSource is a source whose feed couldn't be collected in the last run
+type DashboardFailedSource struct {
+	Source              string    `json:"source"`
+	Error               string    `json:"error"`
+	ConsecutiveFailures int       `json:"consecutiveFailures"`
+	FailingSince        time.Time `json:"failingSince"`
+}
+
+type DashboardSourceVolume struct {
+	Source        string `json:"source"`
+	Articles      int64  `json:"articles"`
+	ArticlesToday int64  `json:"articlesToday"`
+}
+
+type DashboardCollectionRun struct {
+	StartedAt      time.Time `json:"startedAt"`
+	FinishedAt     time.Time `json:"finishedAt"`
+	DurationMs     int64     `json:"durationMs"`
+	Sources        int       `json:"sources"`
+	FailedSources  int       `json:"failedSources"`
+	FetchedItems   int       `json:"fetchedItems"`
+	NewItems       int       `json:"newItems"`
+	RefreshedItems int       `json:"refreshedItems"`
+	Error          string    `json:"error,omitempty"`
+}
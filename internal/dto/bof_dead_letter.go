@@ -0,0 +1,28 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type DeadLetterListRequest struct {
+	SourceID int64 `query:"sourceId" validate:"omitempty,gt=0"`
+	Limit    int32 `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+type DeadLetterRequest struct {
+	ID int64 `path:"id" validate:"gt=0"`
+}
+
+// DeadLetterResponse is a feed item the collector couldn't store, with the raw item as
+// parsed from the feed and why it was rejected
+type DeadLetterResponse struct {
+	ID          int64           `json:"id"`
+	SourceID    int64           `json:"sourceId"`
+	Link        string          `json:"link,omitempty"`
+	Error       string          `json:"error"`
+	Occurrences int32           `json:"occurrences"`
+	Payload     json.RawMessage `json:"payload"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	LastSeenAt  time.Time       `json:"lastSeenAt"`
+}
@@ -0,0 +1,8 @@
+package dto
+
+type NewsExportRequest struct {
+	// From and To are dates in YYYY-MM-DD; To is inclusive
+	From   string   `query:"from"`
+	To     string   `query:"to"`
+	Source []string `query:"source"`
+}
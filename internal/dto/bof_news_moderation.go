@@ -0,0 +1,21 @@
+package dto
+
+import "time"
+
+type NewsModerationRequest struct {
+	ID     int64  `path:"id" validate:"gt=0"`
+	Reason string `json:"reason" validate:"max=500"`
+}
+
+type NewsModerationLogGetRequest struct {
+	ID int64 `path:"id" validate:"gt=0"`
+}
+
+type NewsModerationLogResponse struct {
+	ID        int64     `json:"id"`
+	NewsID    int64     `json:"newsId"`
+	Action    string    `json:"action"`
+	Reason    string    `json:"reason,omitempty"`
+	Actor     string    `json:"actor,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
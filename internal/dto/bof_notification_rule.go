@@ -0,0 +1,48 @@
+package dto
+
+import "time"
+
+// NotificationRuleCreateRequest pushes new news to a chat channel. When sources or tags are set
+// an item must come from a listed source or carry one of the tags, and when keywords are set
+// its title must contain one of them. Template is a text/template over notify.Item
+type NotificationRuleCreateRequest struct {
+	Name     string   `json:"name" validate:"required,max=100"`
+	Channel  string   `json:"channel" validate:"required,oneof=line_notify line_messaging telegram discord webhook"`
+	Target   string   `json:"target" validate:"max=512"`
+	Keywords []string `json:"keywords" validate:"max=100,dive,required,max=100"`
+	Sources  []string `json:"sources" validate:"max=100,dive,required"`
+	Tags     []string `json:"tags" validate:"max=100,dive,required"`
+	Template string   `json:"template" validate:"max=2000"`
+}
+
+// NotificationRuleUpdateRequest changes only the fields that are sent; the channel is fixed
+type NotificationRuleUpdateRequest struct {
+	ID       int64     `path:"id" validate:"gt=0"`
+	Name     *string   `json:"name" validate:"omitempty,max=100"`
+	Target   *string   `json:"target" validate:"omitempty,max=512"`
+	Keywords *[]string `json:"keywords" validate:"omitempty,max=100,dive,required,max=100"`
+	Sources  *[]string `json:"sources" validate:"omitempty,max=100,dive,required"`
+	Tags     *[]string `json:"tags" validate:"omitempty,max=100,dive,required"`
+	Template *string   `json:"template" validate:"omitempty,max=2000"`
+	Disabled *bool     `json:"disabled"`
+}
+
+type NotificationRuleDeleteRequest struct {
+	ID int64 `path:"id" validate:"gt=0"`
+}
+
+// NotificationRuleResponse masks the target when it is a credential, as LINE Notify tokens
+// and webhook URLs are
+type NotificationRuleResponse struct {
+	ID        int64      `json:"id"`
+	Name      string     `json:"name"`
+	Channel   string     `json:"channel"`
+	Target    string     `json:"target"`
+	Keywords  []string   `json:"keywords"`
+	Sources   []string   `json:"sources"`
+	Tags      []string   `json:"tags"`
+	Template  string     `json:"template"`
+	Disabled  bool       `json:"disabled"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+}
@@ -0,0 +1,15 @@
+package dto
+
+type RankingMetricsGetRequest struct {
+	Days int32 `query:"days" validate:"omitempty,min=1,max=30"`
+}
+
+type RankingMetricsResponse struct {
+	Date             string  `json:"date"`
+	Ranking          string  `json:"ranking"`
+	Requests         int64   `json:"requests"`
+	Impressions      int64   `json:"impressions"`
+	Clicks           int64   `json:"clicks"`
+	CTR              float64 `json:"ctr"`
+	AvgClickPosition float64 `json:"avgClickPosition,omitempty"`
+}
@@ -0,0 +1,27 @@
+package dto
+
+import "time"
+
+type RateLimitOffendersGetRequest struct {
+	Days  int32 `query:"days" validate:"omitempty,min=1,max=7"`
+	Limit int32 `query:"limit" validate:"omitempty,min=1,max=500"`
+}
+
+// RateLimitOffenderResponse is a reader ("user:<id>", "device:<id>") or IP ("ip:<addr>") that
+// went over its limit
+type RateLimitOffenderResponse struct {
+	Subject         string `json:"subject"`
+	LimitedRequests int64  `json:"limitedRequests"`
+	Blocked         bool   `json:"blocked"`
+}
+
+type RateLimitBlockResponse struct {
+	Subject   string    `json:"subject"`
+	Reason    string    `json:"reason"`
+	BlockedAt time.Time `json:"blockedAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+type RateLimitBlockDeleteRequest struct {
+	Subject string `path:"subject" validate:"required,max=200"`
+}
@@ -0,0 +1,83 @@
+package dto
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// BulkCreateSourceRequest is sent as JSON or as a text/csv body with a header row naming the
+// name, tags and rssUrl columns
+type BulkCreateSourceRequest struct {
+	Sources []CreateSourceRequest `json:"sources" validate:"required,min=1,max=500"`
+}
+
+// DecodeCSV reads the sources from a CSV body; columns are matched by header so their
+// order doesn't matter and unknown columns are ignored
+func (r *BulkCreateSourceRequest) DecodeCSV(body io.Reader) error {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		// spreadsheets often save a UTF-8 BOM before the first column
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		columns[strings.ReplaceAll(name, "_", "")] = i
+	}
+	for _, required := range []string{"name", "rssurl"} {
+		if _, ok := columns[required]; !ok {
+			return fmt.Errorf("header must have a %q column", required)
+		}
+	}
+
+	column := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		r.Sources = append(r.Sources, CreateSourceRequest{
+			Name:   column(record, "name"),
+			Tags:   column(record, "tags"),
+			RSSURL: column(record, "rssurl"),
+		})
+	}
+}
+
+type BulkCreateSourceResponse struct {
+	Created int `json:"created"`
+	Failed  int `json:"failed"`
+	// Results has one entry per submitted source, in order
+	Results []BulkCreateSourceResult `json:"results"`
+}
+
+type BulkCreateSourceResult struct {
+	// Row is the 1-based position of the source in the request
+	Row     int          `json:"row"`
+	Name    string       `json:"name"`
+	Success bool         `json:"success"`
+	Source  *Source      `json:"source,omitempty"`
+	Code    string       `json:"code,omitempty"`
+	Error   string       `json:"error,omitempty"`
+	Fields  []FieldError `json:"fields,omitempty"`
+}
@@ -1,9 +1,9 @@
 package dto
 
 type CreateSourceRequest struct {
-	Name   string `json:"name"`
-	Tags   string `json:"tags"`
-	RSSURL string `json:"rssUrl"`
+	Name   string `json:"name" validate:"required,max=100"`
+	Tags   string `json:"tags" validate:"max=255"`
+	RSSURL string `json:"rssUrl" validate:"required,url"`
 }
 
 type CreateSourceResponse struct {
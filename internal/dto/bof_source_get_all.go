@@ -1,8 +1,42 @@
 package dto
 
+import "encoding/json"
+
 type GetAllSourceByPaginationRequest struct {
 	PageLimit  int32 `json:"pageLimit"`
 	PageOffset int32 `json:"pageOffset"`
+	// Name matches sources whose name contains it, ignoring case
+	Name string `json:"name" validate:"max=100"`
+	Tag  string `json:"tag" validate:"max=50"`
+	// Status is enabled, disabled or empty for both
+	Status string `json:"status" validate:"omitempty,oneof=enabled disabled"`
+	// Sort is one of createdAt:desc (default), createdAt:asc, name:asc, name:desc
+	Sort string `json:"sort" validate:"omitempty,oneof=createdAt:desc createdAt:asc name:asc name:desc"`
+}
+
+// GetAllSourceByPaginationResult keeps the list shape of the response, with the total
+// number of matching sources reported in meta.pagination
+type GetAllSourceByPaginationResult struct {
+	Items      []GetAllSourceByPaginationResponse
+	PageLimit  int32 `json:"-"`
+	PageOffset int32 `json:"-"`
+	TotalCount int64 `json:"-"`
+}
+
+func (r GetAllSourceByPaginationResult) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.Items)
+}
+
+func (r GetAllSourceByPaginationResult) Pagination() *Pagination {
+	pagination := &Pagination{
+		Limit:      r.PageLimit,
+		TotalItems: r.TotalCount,
+	}
+	if r.PageLimit > 0 {
+		pagination.Page = r.PageOffset/r.PageLimit + 1
+		pagination.TotalPages = (r.TotalCount + int64(r.PageLimit) - 1) / int64(r.PageLimit)
+	}
+	return pagination
 }
 
 type GetAllSourceByPaginationResponse struct {
@@ -14,4 +48,9 @@ type Source struct {
 	Name   string `json:"name"`
 	Tags   string `json:"tags"`
 	RSSURL string `json:"rssUrl"`
+	// Enabled is false for sources the collector skips
+	Enabled bool `json:"enabled"`
+	// ImageSelector and ImageFields override where the collector finds the image of an item
+	ImageSelector string   `json:"imageSelector,omitempty"`
+	ImageFields   []string `json:"imageFields,omitempty"`
 }
@@ -0,0 +1,26 @@
+package dto
+
+// SourceListRequest takes the filters of GetAllSourceByPaginationRequest as query parameters
+type SourceListRequest struct {
+	PageLimit  int32  `query:"pageLimit" validate:"omitempty,min=1,max=200"`
+	PageOffset int32  `query:"pageOffset" validate:"min=0"`
+	Name       string `query:"name" validate:"max=100"`
+	Tag        string `query:"tag" validate:"max=50"`
+	Status     string `query:"status" validate:"omitempty,oneof=enabled disabled"`
+	Sort       string `query:"sort" validate:"omitempty,oneof=createdAt:desc createdAt:asc name:asc name:desc"`
+}
+
+type SourceListResult struct {
+	Sources    []Source `json:"sources"`
+	TotalCount int64    `json:"totalCount"`
+	PageLimit  int32    `json:"pageLimit"`
+	PageOffset int32    `json:"pageOffset"`
+}
+
+func (r SourceListResult) Pagination() *Pagination {
+	return GetAllSourceByPaginationResult{
+		PageLimit:  r.PageLimit,
+		PageOffset: r.PageOffset,
+		TotalCount: r.TotalCount,
+	}.Pagination()
+}
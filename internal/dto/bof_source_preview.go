@@ -0,0 +1,31 @@
+package dto
+
+import "time"
+
+type SourcePreviewRequest struct {
+	ID int64 `path:"id" validate:"gt=0"`
+	// Limit is how many items to return in feed order, 20 by default
+	Limit int32 `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+type SourcePreviewResponse struct {
+	Source    Source `json:"source"`
+	FeedTitle string `json:"feedTitle"`
+	ItemCount int    `json:"itemCount"`
+	// Items are parsed the way the collector parses them, nothing is stored
+	Items []SourcePreviewItem `json:"items"`
+}
+
+type SourcePreviewItem struct {
+	Title string `json:"title"`
+	Link  string `json:"link"`
+	Image string `json:"image,omitempty"`
+	// ImageFrom tells where the image was found: image, enclosure or content
+	ImageFrom string `json:"imageFrom,omitempty"`
+	// Published is the date as written in the feed and PublishedAt how it was parsed; items
+	// without PublishedAt are collected without a date
+	Published   string     `json:"published,omitempty"`
+	PublishedAt *time.Time `json:"publishedAt,omitempty"`
+	// Collected tells whether the link is already stored
+	Collected bool `json:"collected"`
+}
@@ -0,0 +1,42 @@
+package dto
+
+type SourceSuggestionPendingRequest struct {
+	PageLimit  int32 `query:"pageLimit" validate:"omitempty,min=1,max=200"`
+	PageOffset int32 `query:"pageOffset" validate:"min=0"`
+}
+
+// SourceSuggestionPendingResult lists the suggestions waiting for review, oldest first
+type SourceSuggestionPendingResult struct {
+	Suggestions []SourceSuggestionResponse `json:"suggestions"`
+	TotalCount  int64                      `json:"totalCount"`
+	PageLimit   int32                      `json:"pageLimit"`
+	PageOffset  int32                      `json:"pageOffset"`
+}
+
+func (r SourceSuggestionPendingResult) Pagination() *Pagination {
+	return GetAllSourceByPaginationResult{
+		PageLimit:  r.PageLimit,
+		PageOffset: r.PageOffset,
+		TotalCount: r.TotalCount,
+	}.Pagination()
+}
+
+// SourceSuggestionApproveRequest creates the suggested source; name and tags override the
+// suggested ones when they are sent
+type SourceSuggestionApproveRequest struct {
+	ID     int64   `path:"id" validate:"gt=0"`
+	Name   *string `json:"name" validate:"omitempty,min=1,max=100"`
+	Tags   *string `json:"tags" validate:"omitempty,max=255"`
+	Reason string  `json:"reason" validate:"max=500"`
+}
+
+type SourceSuggestionApproveResponse struct {
+	Suggestion SourceSuggestionResponse `json:"suggestion"`
+	Source     Source                   `json:"source"`
+}
+
+// SourceSuggestionRejectRequest needs a reason, which is shown to the reader
+type SourceSuggestionRejectRequest struct {
+	ID     int64  `path:"id" validate:"gt=0"`
+	Reason string `json:"reason" validate:"required,max=500"`
+}
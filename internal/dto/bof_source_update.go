@@ -0,0 +1,18 @@
+package dto
+
+// UpdateSourceRequest changes only the fields that are sent
+type UpdateSourceRequest struct {
+	ID     int64   `path:"id" validate:"gt=0"`
+	Name   *string `json:"name" validate:"omitempty,min=1,max=100"`
+	Tags   *string `json:"tags" validate:"omitempty,max=255"`
+	RSSURL *string `json:"rssUrl" validate:"omitempty,url"`
+	// ImageSelector is a CSS selector for the image in the item content; empty removes it
+	ImageSelector *string `json:"imageSelector" validate:"omitempty,max=200"`
+	// ImageFields orders where the image is looked for; empty restores the default order
+	ImageFields *[]string `json:"imageFields" validate:"omitempty,max=4,unique,dive,oneof=image enclosure media content"`
+}
+
+// SourceStatusRequest enables, disables or deletes a source
+type SourceStatusRequest struct {
+	ID int64 `path:"id" validate:"gt=0"`
+}
@@ -0,0 +1,23 @@
+package dto
+
+import "time"
+
+type ValidateSourceRequest struct {
+	RSSURL string `json:"rssUrl" validate:"required,url"`
+}
+
+type ValidateSourceResponse struct {
+	FeedTitle string `json:"feedTitle"`
+	ItemCount int    `json:"itemCount"`
+	// Items previews the latest items as they would be collected
+	Items []ValidateSourceItem `json:"items"`
+	// Warnings are problems that don't stop the source from being added
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+type ValidateSourceItem struct {
+	Title       string     `json:"title"`
+	Link        string     `json:"link"`
+	Image       string     `json:"image,omitempty"`
+	PublishedAt *time.Time `json:"publishedAt,omitempty"`
+}
@@ -0,0 +1,29 @@
+package dto
+
+import "time"
+
+// Tag names can't contain commas since sources still list their tags comma separated
+type TagCreateRequest struct {
+	Name        string `json:"name" validate:"required,max=50,excludes=0x2C"`
+	Description string `json:"description" validate:"max=500"`
+}
+
+// TagUpdateRequest changes only the fields that are sent; renaming a tag renames it on
+// every source that has it
+type TagUpdateRequest struct {
+	ID          int32   `path:"id" validate:"gt=0"`
+	Name        *string `json:"name" validate:"omitempty,min=1,max=50,excludes=0x2C"`
+	Description *string `json:"description" validate:"omitempty,max=500"`
+}
+
+type TagDeleteRequest struct {
+	ID int32 `path:"id" validate:"gt=0"`
+}
+
+type TagResponse struct {
+	ID          int32     `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	SourceCount int64     `json:"sourceCount"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
@@ -0,0 +1,64 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WebhookCreateRequest registers a URL for new news; empty sources and tags match every item,
+// otherwise an item matches when its source is listed or carries one of the tags
+type WebhookCreateRequest struct {
+	URL     string   `json:"url" validate:"required,http_url,max=2048"`
+	Sources []string `json:"sources" validate:"max=100,dive,required"`
+	Tags    []string `json:"tags" validate:"max=100,dive,required"`
+}
+
+// WebhookCreateResponse is the only time the signing secret is returned
+type WebhookCreateResponse struct {
+	WebhookResponse
+	Secret string `json:"secret"`
+}
+
+// WebhookUpdateRequest changes only the fields that are sent
+type WebhookUpdateRequest struct {
+	ID       int64     `path:"id" validate:"gt=0"`
+	URL      *string   `json:"url" validate:"omitempty,http_url,max=2048"`
+	Sources  *[]string `json:"sources" validate:"omitempty,max=100,dive,required"`
+	Tags     *[]string `json:"tags" validate:"omitempty,max=100,dive,required"`
+	Disabled *bool     `json:"disabled"`
+}
+
+type WebhookDeleteRequest struct {
+	ID int64 `path:"id" validate:"gt=0"`
+}
+
+type WebhookFailuresRequest struct {
+	ID    int64 `path:"id" validate:"gt=0"`
+	Limit int32 `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+type WebhookResponse struct {
+	ID        int64      `json:"id"`
+	URL       string     `json:"url"`
+	Sources   []string   `json:"sources"`
+	Tags      []string   `json:"tags"`
+	Disabled  bool       `json:"disabled"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+}
+
+// WebhookFailureResponse is a dead-letter entry: a delivery that failed every attempt
+type WebhookFailureResponse struct {
+	ID         int64           `json:"id"`
+	Attempts   int32           `json:"attempts"`
+	StatusCode *int32          `json:"statusCode,omitempty"`
+	Error      string          `json:"error"`
+	Payload    json.RawMessage `json:"payload"`
+	CreatedAt  time.Time       `json:"createdAt"`
+}
+
+// WebhookPayload is the body POSTed to webhooks
+type WebhookPayload struct {
+	Event string                `json:"event"`
+	News  []NewsListGetResponse `json:"news"`
+}
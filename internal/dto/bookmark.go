@@ -0,0 +1,35 @@
+package dto
+
+import "time"
+
+type BookmarkCreateRequest struct {
+	NewsID int64 `json:"newsId" validate:"gt=0"`
+}
+
+type BookmarkDeleteRequest struct {
+	NewsID int64 `path:"newsId" validate:"gt=0"`
+}
+
+type BookmarkListRequest struct {
+	Cursor string `query:"cursor"`
+	Limit  int32  `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+type BookmarkListResult struct {
+	Items      []BookmarkResponse `json:"items"`
+	NextCursor string             `json:"nextCursor,omitempty"`
+}
+
+type BookmarkResponse struct {
+	NewsID    int64     `json:"newsId"`
+	CreatedAt time.Time `json:"createdAt"`
+	// News is left out once the article has been hidden or moved to the archive
+	News *NewsListGetResponse `json:"news,omitempty"`
+}
+
+func (r BookmarkListResult) Pagination() *Pagination {
+	if r.NextCursor == "" {
+		return nil
+	}
+	return &Pagination{NextCursor: r.NextCursor}
+}
@@ -0,0 +1,7 @@
+package dto
+
+type FeedGetRequest struct {
+	Source []string `query:"source"`
+	Tag    []string `query:"tag"`
+	Limit  int32    `query:"limit"`
+}
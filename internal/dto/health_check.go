@@ -0,0 +1,31 @@
+package dto
+
+import "time"
+
+type HealthCheckResponse struct {
+	Status       string             `json:"status"`
+	Dependencies []DependencyHealth `json:"dependencies"`
+}
+
+type DependencyHealth struct {
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	Critical  bool   `json:"critical"`
+	LatencyMs int64  `json:"latencyMs"`
+	Error     string `json:"error,omitempty"`
+}
+
+type ReadinessResponse struct {
+	Status       string            `json:"status"`
+	Dependencies []DependencyState `json:"dependencies"`
+}
+
+type DependencyState struct {
+	Name                string    `json:"name"`
+	Status              string    `json:"status"`
+	Critical            bool      `json:"critical"`
+	LastCheckedAt       time.Time `json:"lastCheckedAt"`
+	LastError           string    `json:"lastError,omitempty"`
+	ConsecutiveFailures int       `json:"consecutiveFailures"`
+	Reconnects          int       `json:"reconnects"`
+}
@@ -0,0 +1,9 @@
+package dto
+
+// IdempotentResponse is a response kept under its Idempotency-Key, replayed to requests that
+// repeat the key
+type IdempotentResponse struct {
+	Status int                 `json:"status"`
+	Header map[string][]string `json:"header"`
+	Body   []byte              `json:"body"`
+}
@@ -0,0 +1,90 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// JobEnqueueRequest queues a background job, to run at runAt or as soon as a worker is free
+type JobEnqueueRequest struct {
+	Type    string          `json:"type" validate:"required,oneof=collect retention cache-warm webhook-delivery backfill sitemap analytics"`
+	Payload json.RawMessage `json:"payload"`
+	RunAt   *time.Time      `json:"runAt"`
+}
+
+// BackfillRequest queues the import of the older items of a source from its feed archive
+type BackfillRequest struct {
+	SourceID int64 `path:"sourceID" validate:"gt=0"`
+	Pages    int   `query:"pages" validate:"omitempty,min=1,max=50"`
+}
+
+type JobListRequest struct {
+	Type   string `query:"type" validate:"omitempty,oneof=collect retention cache-warm webhook-delivery backfill sitemap analytics"`
+	Status string `query:"status" validate:"omitempty,oneof=pending running succeeded failed"`
+	Limit  int32  `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+// JobRetryRequest queues a failed job again with its attempts reset
+type JobRetryRequest struct {
+	ID int64 `path:"id" validate:"gt=0"`
+}
+
+type JobResponse struct {
+	ID          int64           `json:"id"`
+	Type        string          `json:"type"`
+	Payload     json.RawMessage `json:"payload"`
+	Status      string          `json:"status"`
+	Attempts    int32           `json:"attempts"`
+	MaxAttempts int32           `json:"maxAttempts"`
+	LastError   string          `json:"lastError,omitempty"`
+	// ScheduledAt is when a pending job runs next; for a running job it is the end of its lease
+	ScheduledAt time.Time  `json:"scheduledAt"`
+	StartedAt   *time.Time `json:"startedAt,omitempty"`
+	FinishedAt  *time.Time `json:"finishedAt,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	// DurationMs is how long the last attempt of a finished job ran
+	DurationMs *int64 `json:"durationMs,omitempty"`
+	// Result is what the last attempt reported, e.g. a CollectionRunResponse for collect jobs
+	Result json.RawMessage `json:"result,omitempty"`
+}
+
+// CollectionRunResponse is the result of a collect job
+type CollectionRunResponse struct {
+	Sources        int                     `json:"sources"`
+	FailedSources  int                     `json:"failedSources"`
+	FetchedItems   int                     `json:"fetchedItems"`
+	NewItems       int                     `json:"newItems"`
+	RefreshedItems int                     `json:"refreshedItems"`
+	RejectedItems  int                     `json:"rejectedItems"` // kept as dead letters instead of being stored
+	Failures       []DashboardFailedSource `json:"failures,omitempty"`
+}
+
+// RetentionRunResponse is the result of a retention job
+type RetentionRunResponse struct {
+	ArchivedCount int64 `json:"archivedCount"`
+	// RemovedArchivedCount counts the archived news removed past retention.archiveMonths
+	RemovedArchivedCount int64 `json:"removedArchivedCount"`
+}
+
+// BackfillRunResponse is the result of a backfill job
+type BackfillRunResponse struct {
+	SourceID      int64 `json:"sourceId"`
+	Pages         int   `json:"pages"` // archive pages read, the first one included
+	FetchedItems  int   `json:"fetchedItems"`
+	NewItems      int   `json:"newItems"`
+	RejectedItems int   `json:"rejectedItems"`
+	// Paginated is false when the feed ignores the page parameter, so only its first page was read
+	Paginated bool `json:"paginated"`
+}
+
+// AnalyticsRunResponse is the result of an analytics job
+type AnalyticsRunResponse struct {
+	Days    int `json:"days"`    // days rolled up, today included
+	Sources int `json:"sources"` // sources with impressions counted over those days
+}
+
+// SitemapRunResponse is the result of a sitemap job
+type SitemapRunResponse struct {
+	URLs     int `json:"urls"`     // listed in /sitemap.xml, 0 when it is disabled
+	NewsURLs int `json:"newsUrls"` // listed in /news-sitemap.xml, 0 when it is disabled
+}
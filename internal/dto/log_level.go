@@ -0,0 +1,14 @@
+package dto
+
+// LogLevelUpdateRequest sets the level of a module, or the global level without a module.
+// An empty level makes the module follow the global level again
+type LogLevelUpdateRequest struct {
+	Module string `json:"module" validate:"omitempty,max=50"`
+	Level  string `json:"level" validate:"required_without=Module,max=20"`
+}
+
+type LogLevelResponse struct {
+	Level string `json:"level"`
+	// Modules are the modules with a level of their own
+	Modules map[string]string `json:"modules"`
+}
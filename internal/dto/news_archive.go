@@ -0,0 +1,9 @@
+package dto
+
+type NewsArchiveGetRequest struct {
+	Source []string `query:"source" validate:"required,min=1"`
+	// Month is the archived publish month in YYYY-MM
+	Month string `query:"month" validate:"required,datetime=2006-01"`
+	Page  int32  `query:"page"`
+	Limit int32  `query:"limit"`
+}
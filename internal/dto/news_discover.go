@@ -0,0 +1,7 @@
+package dto
+
+type NewsDiscoverGetRequest struct {
+	// Exclude lists the sources the caller already follows
+	Exclude []string `query:"exclude"`
+	Limit   int32    `query:"limit"`
+}
@@ -5,13 +5,73 @@ import "time"
 type NewsListGetRequest struct {
 	Page   int32    `json:"page"`
 	Limit  int32    `json:"limit"`
-	Source []string `json:"source,omitempty"`
+	Source []string `json:"source,omitempty" validate:"required,min=1"`
+	// Cursor is the nextCursor of a previous response and replaces Page; only the default
+	// sort without groupDuplicates supports it
+	Cursor string `json:"cursor,omitempty"`
+	// Sort is one of publishedAt:desc (default), publishedAt:asc, fetchedAt:desc, source:asc
+	Sort string `json:"sort,omitempty"`
+	// GroupBySource returns the latest PerSource items of each source instead of a single page
+	GroupBySource bool  `json:"groupBySource,omitempty"`
+	PerSource     int32 `json:"perSource,omitempty"`
+	// Personalization re-orders the page for the signed-in reader or device profile
+	Personalization bool `json:"personalization,omitempty"`
+	// GroupDuplicates returns one news per story that several sources covered; only the
+	// default sort is supported
+	GroupDuplicates bool `json:"groupDuplicates,omitempty"`
+}
+
+// NewsListQueryRequest is NewsListGetRequest read from the query string of GET /news, whose
+// responses can be revalidated with their ETag
+type NewsListQueryRequest struct {
+	Page            int32    `query:"page"`
+	Limit           int32    `query:"limit"`
+	Source          []string `query:"source" validate:"required,min=1"`
+	Cursor          string   `query:"cursor"`
+	Sort            string   `query:"sort"`
+	GroupBySource   bool     `query:"groupBySource"`
+	PerSource       int32    `query:"perSource"`
+	Personalization bool     `query:"personalization"`
+	GroupDuplicates bool     `query:"groupDuplicates"`
+}
+
+type NewsListGetResult struct {
+	Items      []NewsListGetResponse            `json:"items"`
+	Groups     map[string][]NewsListGetResponse `json:"groups,omitempty"`
+	Page       int32                            `json:"page"`
+	Limit      int32                            `json:"limit"`
+	TotalItems int64                            `json:"totalItems"`
+	TotalPages int64                            `json:"totalPages"`
+	// NextCursor continues the list after Items, set while a full page may be followed by more
+	NextCursor string `json:"nextCursor,omitempty"`
+	// Ranking is personalized or chronological; clicks should echo it back for the metrics
+	Ranking string `json:"ranking,omitempty"`
 }
 
 type NewsListGetResponse struct {
+	ID          int64     `json:"id"`
 	Title       string    `json:"title"`
 	Source      string    `json:"source"`
 	PublishedAt time.Time `json:"publishedAt"`
 	Image       string    `json:"image"`
 	Link        string    `json:"link"`
+	Summary     string    `json:"summary,omitempty"`
+	// ReadState is only set for signed-in readers, telling whether they opened the article
+	ReadState *bool `json:"readState,omitempty"`
+	// AlsoCoveredBy is only set with groupDuplicates: the number of other sources of the story
+	AlsoCoveredBy int64 `json:"alsoCoveredBy,omitempty"`
+}
+
+// Pagination reports the page and cursor of a list; grouped results aren't paginated
+func (r NewsListGetResult) Pagination() *Pagination {
+	if r.Groups != nil || r.Limit == 0 {
+		return nil
+	}
+	return &Pagination{
+		Page:       r.Page,
+		Limit:      r.Limit,
+		TotalItems: r.TotalItems,
+		TotalPages: r.TotalPages,
+		NextCursor: r.NextCursor,
+	}
 }
@@ -0,0 +1,5 @@
+package dto
+
+type NewsBatchGetRequest struct {
+	IDs []int64 `json:"ids" validate:"required,min=1"`
+}
@@ -0,0 +1,23 @@
+package dto
+
+import "time"
+
+type NewsDetailGetRequest struct {
+	ID int64 `path:"id" validate:"gt=0"`
+}
+
+type NewsDetailGetResponse struct {
+	ID          int64                 `json:"id"`
+	Title       string                `json:"title"`
+	Source      string                `json:"source"`
+	PublishedAt time.Time             `json:"publishedAt"`
+	FetchedAt   time.Time             `json:"fetchedAt"`
+	Image       string                `json:"image"`
+	Link        string                `json:"link"`
+	Summary     string                `json:"summary,omitempty"`
+	Related     []NewsListGetResponse `json:"related"`
+}
+
+type NewsRelatedGetRequest struct {
+	ID int64 `path:"id" validate:"gt=0"`
+}
@@ -0,0 +1,37 @@
+package dto
+
+import "time"
+
+type NewsReadMarkRequest struct {
+	NewsIDs []int64 `json:"newsIds" validate:"required,min=1,max=100,dive,gt=0"`
+}
+
+type NewsReadDeleteRequest struct {
+	NewsID int64 `path:"newsId" validate:"gt=0"`
+}
+
+type NewsReadListRequest struct {
+	// Since only returns reads after this RFC 3339 time, for syncing a device's local state
+	Since  string `query:"since" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	Cursor string `query:"cursor"`
+	Limit  int32  `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+type NewsReadListResult struct {
+	Items      []NewsReadResponse `json:"items"`
+	NextCursor string             `json:"nextCursor,omitempty"`
+}
+
+type NewsReadResponse struct {
+	NewsID int64     `json:"newsId"`
+	ReadAt time.Time `json:"readAt"`
+	// News is left out once the article has been hidden or moved to the archive
+	News *NewsListGetResponse `json:"news,omitempty"`
+}
+
+func (r NewsReadListResult) Pagination() *Pagination {
+	if r.NextCursor == "" {
+		return nil
+	}
+	return &Pagination{NextCursor: r.NextCursor}
+}
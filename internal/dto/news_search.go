@@ -0,0 +1,12 @@
+package dto
+
+type NewsSearchRequest struct {
+	Query  string   `query:"q" validate:"required,min=2,max=200"`
+	Source []string `query:"source"`
+	Page   int32    `query:"page" validate:"omitempty,min=1"`
+	Limit  int32    `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+type SearchReindexResponse struct {
+	Indexed int64 `json:"indexed"`
+}
@@ -0,0 +1,18 @@
+package dto
+
+// NewsSocketMessage is sent by /ws clients. A "subscribe" message replaces the
+// connection's filter; empty sources and tags receive every source
+type NewsSocketMessage struct {
+	Type    string   `json:"type"`
+	Sources []string `json:"sources"`
+	Tags    []string `json:"tags"`
+}
+
+// NewsSocketEvent is pushed to /ws clients. Type is "subscribed", "news", "lagged" or "error"
+type NewsSocketEvent struct {
+	Type    string               `json:"type"`
+	Data    *NewsListGetResponse `json:"data,omitempty"`
+	Sources []string             `json:"sources,omitempty"` // the sources a subscription resolved to
+	Dropped int64                `json:"dropped,omitempty"` // events missed because the client fell behind
+	Error   *ErrorResponse       `json:"error,omitempty"`
+}
@@ -0,0 +1,6 @@
+package dto
+
+type NewsStreamRequest struct {
+	// Source limits the stream to these sources; empty streams every source
+	Source []string `query:"source"`
+}
@@ -0,0 +1,23 @@
+package dto
+
+type NewsClickRequest struct {
+	ID int64 `path:"id" validate:"gt=0"`
+	// Ranking and Position tell which ordering of /news the click came from, and where in the page
+	Ranking  string `query:"ranking" validate:"omitempty,oneof=personalized chronological"`
+	Position int32  `query:"position" validate:"omitempty,min=1"`
+}
+
+type NewsTrendingGetRequest struct {
+	Limit int32 `query:"limit"`
+	Hours int32 `query:"hours"`
+}
+
+type NewsTrendingGetResponse struct {
+	NewsListGetResponse
+	Clicks int64 `json:"clicks"`
+}
+
+type AggregateNewsClicksResponse struct {
+	Buckets int `json:"buckets"`
+	News    int `json:"news"`
+}
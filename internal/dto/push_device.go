@@ -0,0 +1,24 @@
+package dto
+
+import "time"
+
+// PushDeviceRegisterRequest opts a device into push notifications, or replaces the filters of
+// a device that is already registered. Empty sources and keywords match every new item
+type PushDeviceRegisterRequest struct {
+	Token    string   `json:"token" validate:"required,max=4096"`
+	Platform string   `json:"platform" validate:"required,oneof=android ios web"`
+	Sources  []string `json:"sources" validate:"max=50,dive,required"`
+	Keywords []string `json:"keywords" validate:"max=50,dive,required,max=100"`
+}
+
+type PushDeviceUnregisterRequest struct {
+	Token string `path:"token" validate:"required,max=4096"`
+}
+
+type PushDeviceResponse struct {
+	Platform  string     `json:"platform"`
+	Sources   []string   `json:"sources"`
+	Keywords  []string   `json:"keywords"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+}
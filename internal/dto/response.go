@@ -1,6 +1,52 @@
 package dto
 
+import "time"
+
+// Response is the envelope of the unversioned (legacy) routes
 type Response struct {
-	Data  any    `json:"data"`
-	Error string `json:"error,omitempty"`
+	Data   any          `json:"data"`
+	Error  string       `json:"error,omitempty"`
+	Fields []FieldError `json:"fields,omitempty"`
+}
+
+// Envelope is the response body of every versioned route
+type Envelope struct {
+	Success bool           `json:"success"`
+	Data    any            `json:"data"`
+	Error   *ErrorResponse `json:"error,omitempty"`
+	Meta    Meta           `json:"meta"`
+}
+
+type ErrorResponse struct {
+	Type    string       `json:"type"`
+	Code    string       `json:"code,omitempty"`
+	Message string       `json:"message"`
+	Details string       `json:"details,omitempty"`
+	Fields  []FieldError `json:"fields,omitempty"`
+}
+
+type Meta struct {
+	RequestID  string      `json:"requestId,omitempty"`
+	Timestamp  time.Time   `json:"timestamp"`
+	Pagination *Pagination `json:"pagination,omitempty"`
+}
+
+type Pagination struct {
+	Page       int32  `json:"page,omitempty"`
+	Limit      int32  `json:"limit,omitempty"`
+	TotalItems int64  `json:"totalItems,omitempty"`
+	TotalPages int64  `json:"totalPages,omitempty"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// Paginated is implemented by responses whose page information belongs in meta.pagination
+type Paginated interface {
+	Pagination() *Pagination
+}
+
+// FieldError describes one request field that failed validation
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
 }
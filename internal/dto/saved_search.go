@@ -0,0 +1,42 @@
+package dto
+
+import "time"
+
+// SavedSearchCreateRequest saves a search of the reader's. New news whose title or summary
+// contain every word of the query, from one of the sources when any are listed, are pushed to
+// pushToken and sent to the LINE user lineUserId; without either the search is only listed
+type SavedSearchCreateRequest struct {
+	Name       string   `json:"name" validate:"max=100"`
+	Query      string   `json:"query" validate:"required,min=2,max=200"`
+	Sources    []string `json:"sources" validate:"max=50,dive,required"`
+	PushToken  string   `json:"pushToken" validate:"max=4096"`
+	LineUserID string   `json:"lineUserId"`
+}
+
+// SavedSearchUpdateRequest changes only the fields that are sent; an empty pushToken or
+// lineUserId stops those notifications
+type SavedSearchUpdateRequest struct {
+	ID         int64     `path:"id" validate:"gt=0"`
+	Name       *string   `json:"name" validate:"omitempty,max=100"`
+	Query      *string   `json:"query" validate:"omitempty,min=2,max=200"`
+	Sources    *[]string `json:"sources" validate:"omitempty,max=50,dive,required"`
+	PushToken  *string   `json:"pushToken" validate:"omitempty,max=4096"`
+	LineUserID *string   `json:"lineUserId"`
+}
+
+type SavedSearchDeleteRequest struct {
+	ID int64 `path:"id" validate:"gt=0"`
+}
+
+type SavedSearchResponse struct {
+	ID      int64    `json:"id"`
+	Name    string   `json:"name"`
+	Query   string   `json:"query"`
+	Sources []string `json:"sources"`
+	// Push and Line tell whether matches are sent there; the token and user id aren't returned
+	Push       bool       `json:"push"`
+	Line       bool       `json:"line"`
+	NotifiedAt *time.Time `json:"notifiedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	UpdatedAt  *time.Time `json:"updatedAt,omitempty"`
+}
@@ -0,0 +1,12 @@
+package dto
+
+type ConfigResponse struct {
+	// Env is the APP_ENV overlay in use
+	Env string `json:"env,omitempty"`
+	// Files are the config files read, the base file first
+	Files []string `json:"files"`
+	// EnvOverrides are the keys set by environment variables
+	EnvOverrides []string `json:"envOverrides"`
+	// Config is the resolved configuration with secrets masked
+	Config map[string]any `json:"config"`
+}
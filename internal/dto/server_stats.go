@@ -0,0 +1,83 @@
+package dto
+
+type ServerStatsResponse struct {
+	Postgres         *PostgresPoolStats  `json:"postgres"`
+	PostgresReplicas []PostgresPoolStats `json:"postgresReplicas,omitempty"`
+	SlowQueries      SlowQueryStats      `json:"slowQueries"`
+	Redis            *RedisPoolStats     `json:"redis"`
+	Cache            CacheStatsResponse  `json:"cache"`
+	Runtime          RuntimeStats        `json:"runtime"`
+}
+
+type PostgresPoolStats struct {
+	TotalConns              int32 `json:"totalConns"`
+	AcquiredConns           int32 `json:"acquiredConns"`
+	IdleConns               int32 `json:"idleConns"`
+	ConstructingConns       int32 `json:"constructingConns"`
+	MaxConns                int32 `json:"maxConns"`
+	AcquireCount            int64 `json:"acquireCount"`
+	AcquireDurationMs       int64 `json:"acquireDurationMs"`
+	EmptyAcquireCount       int64 `json:"emptyAcquireCount"`
+	CanceledAcquireCount    int64 `json:"canceledAcquireCount"`
+	NewConnsCount           int64 `json:"newConnsCount"`
+	MaxLifetimeDestroyCount int64 `json:"maxLifetimeDestroyCount"`
+	MaxIdleDestroyCount     int64 `json:"maxIdleDestroyCount"`
+}
+
+// SlowQueryStats counts the queries over postgres.slowQuery.threshold since the instance
+// that answered started
+type SlowQueryStats struct {
+	Total uint64 `json:"total"`
+	// Queries are counted by sqlc query name, most frequent first
+	Queries []SlowQueryStat `json:"queries"`
+}
+
+type SlowQueryStat struct {
+	Name          string `json:"name"`
+	Count         uint64 `json:"count"`
+	MaxDurationMs int64  `json:"maxDurationMs"`
+}
+
+type RedisPoolStats struct {
+	Hits       uint32 `json:"hits"`
+	Misses     uint32 `json:"misses"`
+	Timeouts   uint32 `json:"timeouts"`
+	TotalConns uint32 `json:"totalConns"`
+	IdleConns  uint32 `json:"idleConns"`
+	StaleConns uint32 `json:"staleConns"`
+}
+
+// CacheStatsResponse counts the cache calls of the instance that answered since it started
+type CacheStatsResponse struct {
+	Total CacheStats `json:"total"`
+	// Prefixes break the counts down by the part of the key before the first colon, most
+	// looked up first
+	Prefixes []CachePrefixStats `json:"prefixes"`
+}
+
+type CachePrefixStats struct {
+	Prefix string `json:"prefix"`
+	CacheStats
+}
+
+type CacheStats struct {
+	Hits   uint64 `json:"hits"`
+	Misses uint64 `json:"misses"`
+	Sets   uint64 `json:"sets"`
+	// Errors are failed lookups and stores, such as Redis being down
+	Errors uint64 `json:"errors"`
+	// HitRate is hits over hits and misses, 0 before the first lookup
+	HitRate float64 `json:"hitRate"`
+}
+
+type RuntimeStats struct {
+	Goroutines  int    `json:"goroutines"`
+	HeapAllocMB uint64 `json:"heapAllocMb"`
+	HeapInuseMB uint64 `json:"heapInuseMb"`
+	HeapObjects uint64 `json:"heapObjects"`
+	SysMB       uint64 `json:"sysMb"`
+	NumGC       uint32 `json:"numGc"`
+	// Panics counts the requests that panicked since the instance started
+	Panics        uint64 `json:"panics"`
+	UptimeSeconds int64  `json:"uptimeSeconds"`
+}
@@ -0,0 +1,10 @@
+package dto
+
+type VersionResponse struct {
+	Commit string `json:"commit"`
+	// BuildTime is when the binary was built, or the commit time when it wasn't set at build
+	BuildTime string `json:"buildTime"`
+	GoVersion string `json:"goVersion"`
+	Profile   string `json:"profile,omitempty"`
+	Modified  bool   `json:"modified,omitempty"`
+}
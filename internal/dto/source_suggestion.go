@@ -0,0 +1,31 @@
+package dto
+
+import "time"
+
+// SourceSuggestionCreateRequest is a reader's suggestion for a new source. With a pushToken
+// the reader's device is told when the suggestion is approved or rejected
+type SourceSuggestionCreateRequest struct {
+	Name      string `json:"name" validate:"required,max=100"`
+	Tags      string `json:"tags" validate:"max=255"`
+	RSSURL    string `json:"rssUrl" validate:"required,url,max=2048"`
+	Note      string `json:"note" validate:"max=1000"`
+	PushToken string `json:"pushToken" validate:"max=4096"`
+}
+
+type SourceSuggestionResponse struct {
+	ID     int64  `json:"id"`
+	Name   string `json:"name"`
+	Tags   string `json:"tags"`
+	RSSURL string `json:"rssUrl"`
+	Note   string `json:"note,omitempty"`
+	// Status is pending, approved or rejected
+	Status       string     `json:"status"`
+	ReviewReason string     `json:"reviewReason,omitempty"`
+	ReviewedAt   *time.Time `json:"reviewedAt,omitempty"`
+	// SourceID is the source created by the approval
+	SourceID  *int64    `json:"sourceId,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	// UserID and ReviewedBy are only shown in the backoffice
+	UserID     int64  `json:"userId,omitempty"`
+	ReviewedBy string `json:"reviewedBy,omitempty"`
+}
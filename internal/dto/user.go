@@ -0,0 +1,43 @@
+package dto
+
+import "time"
+
+type UserRegisterRequest struct {
+	Email       string `json:"email" validate:"required,email,max=254"`
+	Password    string `json:"password" validate:"required,min=8,maxbytes=72"`
+	DisplayName string `json:"displayName" validate:"max=100"`
+}
+
+type UserLoginRequest struct {
+	Email    string `json:"email" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+// UserOAuthLoginRequest signs in with an ID token from the provider's SDK. Apple only shares
+// the user's name with the app on the first sign-in, so the app may pass it along
+type UserOAuthLoginRequest struct {
+	Provider    string `path:"provider" validate:"required,oneof=google apple"`
+	IDToken     string `json:"idToken" validate:"required"`
+	DisplayName string `json:"displayName" validate:"max=100"`
+}
+
+type UserUpdateRequest struct {
+	DisplayName *string `json:"displayName" validate:"omitempty,max=100"`
+}
+
+type UserResponse struct {
+	ID          int64      `json:"id"`
+	Email       string     `json:"email,omitempty"`
+	DisplayName string     `json:"displayName"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	LastLoginAt *time.Time `json:"lastLoginAt,omitempty"`
+}
+
+type UserTokenResponse struct {
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken"`
+	TokenType    string `json:"tokenType"`
+	// ExpiresIn is the access token lifetime in seconds
+	ExpiresIn int64        `json:"expiresIn"`
+	User      UserResponse `json:"user"`
+}
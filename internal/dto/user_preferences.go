@@ -0,0 +1,15 @@
+package dto
+
+import "time"
+
+type UserPreferencesUpdateRequest struct {
+	// Sources and Tags replace the followed lists; send an empty list to clear one
+	Sources []string `json:"sources" validate:"max=100,dive,required,max=100"`
+	Tags    []string `json:"tags" validate:"max=100,dive,required,max=100"`
+}
+
+type UserPreferencesResponse struct {
+	Sources   []string   `json:"sources"`
+	Tags      []string   `json:"tags"`
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+}
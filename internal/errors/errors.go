@@ -9,12 +9,21 @@ import (
 type ErrorType string
 
 const (
-	ValidationError ErrorType = "VALIDATION_ERROR"
-	DatabaseError   ErrorType = "DATABASE_ERROR"
-	RedisError      ErrorType = "REDIS_ERROR"
-	NetworkError    ErrorType = "NETWORK_ERROR"
-	ParseError      ErrorType = "PARSE_ERROR"
-	InternalError   ErrorType = "INTERNAL_ERROR"
+	ValidationError       ErrorType = "VALIDATION_ERROR"
+	NotFoundError         ErrorType = "NOT_FOUND"
+	DatabaseError         ErrorType = "DATABASE_ERROR"
+	RedisError            ErrorType = "REDIS_ERROR"
+	NetworkError          ErrorType = "NETWORK_ERROR"
+	ParseError            ErrorType = "PARSE_ERROR"
+	InternalError         ErrorType = "INTERNAL_ERROR"
+	UnavailableError      ErrorType = "SERVICE_UNAVAILABLE"
+	UnauthorizedError     ErrorType = "UNAUTHORIZED"
+	ForbiddenError        ErrorType = "FORBIDDEN"
+	TooLargeError         ErrorType = "PAYLOAD_TOO_LARGE"
+	TimeoutError          ErrorType = "TIMEOUT"
+	MethodNotAllowedError ErrorType = "METHOD_NOT_ALLOWED"
+	RateLimitedError      ErrorType = "RATE_LIMITED"
+	ConflictError         ErrorType = "CONFLICT"
 )
 
 // AppError represents a structured application error
@@ -104,6 +113,21 @@ func IsType(err error, errType ErrorType) bool {
 	return false
 }
 
+// HasType checks if err or any AppError it wraps is of a specific type
+func HasType(err error, errType ErrorType) bool {
+	for err != nil {
+		if appErr, ok := err.(*AppError); ok && appErr.Type == errType {
+			return true
+		}
+		unwrapper, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			return false
+		}
+		err = unwrapper.Unwrap()
+	}
+	return false
+}
+
 // As is a convenience function for errors.As
 func As(err error, target interface{}) bool {
 	// This would normally use errors.As from Go standard library
@@ -0,0 +1,158 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/onefeed-th/onefeed-th-backend-api/config"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/logger"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/requestid"
+)
+
+// skippedBody replaces bodies above log.maxBodyBytes
+const skippedBody = "[body too large to log]"
+
+// AccessLog writes one entry per request once it is answered. Server errors are logged at
+// error level and client errors at warn level. The request body is added to a
+// log.bodySampleRate share of the entries, redacted
+func AccessLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/health" || r.URL.Path == "/ready" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		cfg := config.GetConfig()
+		start := time.Now()
+
+		var body string
+		var logBody bool
+		if rate := cfg.Log.BodySampleRate; rate >= 1 || (rate > 0 && rand.Float64() < rate) {
+			body, logBody = readLoggedBody(r)
+		}
+
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+
+		status := rec.Status()
+		attrs := []any{
+			"method", r.Method,
+			"path", r.URL.Path,
+			// the mux sets the pattern on the request it served, e.g. "GET /v1/news/{id}"
+			"route", r.Pattern,
+			"status", status,
+			"latency_ms", time.Since(start).Milliseconds(),
+			"bytes", rec.bytes,
+			"ip", clientIP(r, cfg.RateLimit.TrustedProxies),
+			"user_agent", r.UserAgent(),
+			"request_id", requestid.FromContext(r.Context()),
+		}
+		if logBody {
+			attrs = append(attrs, "body", body)
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+		logger.For("http").Log(r.Context(), level, "Request", attrs...)
+	})
+}
+
+// readLoggedBody returns the request body prepared for logging and puts the bytes it read
+// back in front of r.Body. Bodies above log.maxBodyBytes are skipped instead of truncated,
+// since a cut-off JSON document can't be redacted
+func readLoggedBody(r *http.Request) (string, bool) {
+	if r.Body == nil || r.Body == http.NoBody || r.ContentLength == 0 {
+		return "", false
+	}
+
+	cfg := config.GetConfig().Log
+	if cfg.MaxBodyBytes <= 0 {
+		return "", false
+	}
+	if r.ContentLength > cfg.MaxBodyBytes {
+		return skippedBody, true
+	}
+
+	prefix, err := io.ReadAll(io.LimitReader(r.Body, cfg.MaxBodyBytes+1))
+	r.Body = readCloser{io.MultiReader(bytes.NewReader(prefix), r.Body), r.Body}
+	if err != nil {
+		logger.For("http").Error("Error reading request body", "error", err)
+		return "", false
+	}
+	if int64(len(prefix)) > cfg.MaxBodyBytes {
+		return skippedBody, true
+	}
+
+	var body any
+	if json.Unmarshal(prefix, &body) != nil {
+		// not JSON, fall back to the raw body
+		return string(prefix), true
+	}
+	redacted, err := json.Marshal(logger.RedactJSON(body, cfg.RedactFields))
+	if err != nil {
+		return "", false
+	}
+	return string(redacted), true
+}
+
+// readCloser keeps the original body's Close after its first bytes were read for logging
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// statusRecorder captures the status code and response size for the access log
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	if s.status == 0 {
+		s.status = status
+	}
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *statusRecorder) Write(p []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	n, err := s.ResponseWriter.Write(p)
+	s.bytes += n
+	return n, err
+}
+
+// Status is the code sent to the client; handlers that never write answer 200
+func (s *statusRecorder) Status() int {
+	if s.status == 0 {
+		return http.StatusOK
+	}
+	return s.status
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer (e.g. to flush)
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
+
+// Hijack hands the connection over for WebSocket upgrades, which write their own 101
+func (s *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(s.ResponseWriter).Hijack()
+	if err == nil && s.status == 0 {
+		s.status = http.StatusSwitchingProtocols
+	}
+	return conn, rw, err
+}
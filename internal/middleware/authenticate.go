@@ -0,0 +1,147 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/auth"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/httpserver"
+	apperrors "github.com/onefeed-th/onefeed-th-backend-api/internal/errors"
+)
+
+// Authenticator resolves a presented credential to a principal, returning an AppError
+// describing why it was refused (UNAUTHORIZED, or SERVICE_UNAVAILABLE when the store is down)
+type Authenticator func(ctx context.Context, credential string) (auth.Principal, error)
+
+// Authenticate accepts either an X-API-Key header or an "Authorization: Bearer <jwt>"
+// header and stores the resolved principal in the request context
+func Authenticate(apiKey Authenticator, bearer Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var (
+				principal auth.Principal
+				err       error
+			)
+			if key := r.Header.Get(auth.APIKeyHeader); key != "" {
+				principal, err = apiKey(r.Context(), key)
+			} else if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && token != "" {
+				principal, err = bearer(r.Context(), token)
+			} else {
+				err = apperrors.New(apperrors.UnauthorizedError, "missing API key or bearer token").
+					WithCode("MISSING_CREDENTIALS")
+			}
+			if err != nil {
+				httpserver.WriteError(w, r, err)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
+// RequireRole only lets through principals whose role is at least role
+func RequireRole(role auth.Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := auth.PrincipalFromContext(r.Context())
+			if !ok || !principal.HasRole(role) {
+				httpserver.WriteError(w, r, apperrors.New(apperrors.ForbiddenError, fmt.Sprintf("%s role required", role)).
+					WithCode("INSUFFICIENT_ROLE"))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// UserAuthenticator resolves a reader's bearer token to their user id
+type UserAuthenticator func(ctx context.Context, token string) (int64, error)
+
+// RequireUser only lets through requests with a reader's "Authorization: Bearer <jwt>"
+// header and stores the user id in the request context
+func RequireUser(authenticate UserAuthenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || token == "" {
+				httpserver.WriteError(w, r, apperrors.New(apperrors.UnauthorizedError, "missing bearer token").
+					WithCode("MISSING_CREDENTIALS"))
+				return
+			}
+			userID, err := authenticate(r.Context(), token)
+			if err != nil {
+				httpserver.WriteError(w, r, err)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(auth.WithUserID(r.Context(), userID)))
+		})
+	}
+}
+
+// DeviceResolver resolves an X-Device-ID header to the user id of the device's anonymous
+// profile, creating the profile on first use
+type DeviceResolver func(ctx context.Context, deviceID string) (int64, error)
+
+// RequireProfile is RequireUser that also accepts readers without an account, identified by
+// their X-Device-ID header. A bearer token takes precedence over the header
+func RequireProfile(user UserAuthenticator, device DeviceResolver) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID, ok, err := resolveProfile(r, user, device)
+			if err == nil && !ok {
+				err = apperrors.New(apperrors.UnauthorizedError, "missing bearer token or X-Device-ID header").
+					WithCode("MISSING_CREDENTIALS")
+			}
+			if err != nil {
+				httpserver.WriteError(w, r, err)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(auth.WithUserID(r.Context(), userID)))
+		})
+	}
+}
+
+// OptionalProfile is RequireProfile for public routes: requests without credentials pass
+// through anonymously, but credentials that are presented must be valid
+func OptionalProfile(user UserAuthenticator, device DeviceResolver) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// the response depends on the reader, so caches must not share it between them
+			w.Header().Add("Vary", "Authorization, "+auth.DeviceIDHeader)
+			userID, ok, err := resolveProfile(r, user, device)
+			if err != nil {
+				httpserver.WriteError(w, r, err)
+				return
+			}
+			if ok {
+				r = r.WithContext(auth.WithUserID(r.Context(), userID))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// DeviceID keeps the X-Device-ID header of sign-in requests in the context, so the device's
+// anonymous profile can be merged into the account
+func DeviceID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if deviceID := r.Header.Get(auth.DeviceIDHeader); deviceID != "" {
+			r = r.WithContext(auth.WithDeviceID(r.Context(), deviceID))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func resolveProfile(r *http.Request, user UserAuthenticator, device DeviceResolver) (int64, bool, error) {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && token != "" {
+		userID, err := user(r.Context(), token)
+		return userID, err == nil, err
+	}
+	if deviceID := r.Header.Get(auth.DeviceIDHeader); deviceID != "" {
+		userID, err := device(r.Context(), deviceID)
+		return userID, err == nil, err
+	}
+	return 0, false, nil
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// Deprecation marks routes that have a versioned successor: every response carries a
+// Deprecation header, a Link to successorPrefix+path and, when sunset is a valid
+// YYYY-MM-DD date, the Sunset header (RFC 8594) after which the route may be removed
+func Deprecation(sunset string, successorPrefix string) func(http.Handler) http.Handler {
+	var sunsetHeader string
+	if sunset != "" {
+		date, err := time.Parse(time.DateOnly, sunset)
+		if err != nil {
+			slog.Warn("Ignoring invalid sunset date", "sunset", sunset, "error", err)
+		} else {
+			sunsetHeader = date.UTC().Format(http.TimeFormat)
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Deprecation", "true")
+			w.Header().Add("Link", `<`+successorPrefix+r.URL.Path+`>; rel="successor-version"`)
+			if sunsetHeader != "" {
+				w.Header().Set("Sunset", sunsetHeader)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// ETag buffers successful responses, tags them with a weak ETag computed from the body
+// and answers 304 Not Modified to GET and HEAD requests that already hold the same body
+func ETag(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := &bufferedResponseWriter{
+			header: make(http.Header),
+			status: http.StatusOK,
+		}
+		next.ServeHTTP(rec, r)
+
+		for key, values := range rec.header {
+			w.Header()[key] = values
+		}
+
+		if rec.status != http.StatusOK {
+			w.WriteHeader(rec.status)
+			w.Write(rec.body.Bytes())
+			return
+		}
+
+		sum := sha1.Sum(rec.body.Bytes())
+		etag := `W/"` + hex.EncodeToString(sum[:]) + `"`
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Cache-Control", "no-cache")
+
+		safe := r.Method == http.MethodGet || r.Method == http.MethodHead
+		if safe && etagMatches(r.Header.Get("If-None-Match"), etag) {
+			w.Header().Del("Content-Type")
+			w.Header().Del("Content-Length")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.WriteHeader(rec.status)
+		w.Write(rec.body.Bytes())
+	})
+}
+
+// etagMatches implements the weak comparison used by If-None-Match
+func etagMatches(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+type bufferedResponseWriter struct {
+	header http.Header
+	body   bytes.Buffer
+	status int
+}
+
+func (b *bufferedResponseWriter) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferedResponseWriter) Write(p []byte) (int, error) {
+	return b.body.Write(p)
+}
+
+func (b *bufferedResponseWriter) WriteHeader(status int) {
+	b.status = status
+}
@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/onefeed-th/onefeed-th-backend-api/config"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/auth"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/httpserver"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/dto"
+	apperrors "github.com/onefeed-th/onefeed-th-backend-api/internal/errors"
+)
+
+const (
+	// IdempotencyKeyHeader names the header clients set to make a request safe to repeat
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader is set on responses replayed from an earlier request
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+)
+
+// IdempotentRunner runs a request once per key, returning the stored response and true when
+// the key was used before, or an AppError when the key can't be used
+type IdempotentRunner func(ctx context.Context, key, fingerprint string, run func() dto.IdempotentResponse) (dto.IdempotentResponse, bool, error)
+
+// Idempotency lets clients repeat a request with the same Idempotency-Key and get the first
+// response back instead of running it again. Keys are scoped to the caller, method and path,
+// and the query and body fingerprint the request, so a key reused for another request is
+// refused. The body is read up to restServer.maxBodyBytes. Requests without the header run
+// as usual
+func Idempotency(runner IdempotentRunner) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				httpserver.WriteError(w, r, apperrors.Newf(apperrors.ValidationError, "%s must be at most %d characters", IdempotencyKeyHeader, maxIdempotencyKeyLength).
+					WithCode("INVALID_IDEMPOTENCY_KEY"))
+				return
+			}
+
+			if limit := config.GetConfig().RestServer.MaxBodyBytes; limit > 0 {
+				r.Body = http.MaxBytesReader(w, r.Body, limit)
+			}
+			body, err := io.ReadAll(r.Body)
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				httpserver.WriteError(w, r, apperrors.Newf(apperrors.TooLargeError, "request body exceeds %d bytes", maxBytesErr.Limit).
+					WithCode("BODY_TOO_LARGE"))
+				return
+			}
+			if err != nil {
+				httpserver.WriteError(w, r, apperrors.Wrap(err, apperrors.ValidationError, "failed to read request body"))
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			// callers are told apart by their credential, so one can't replay another's response
+			principal, _ := auth.PrincipalFromContext(r.Context())
+			scope := sha256.Sum256([]byte(principal.Subject + " " + r.Method + " " + r.URL.Path + " " + key))
+			fingerprint := sha256.Sum256(append([]byte(r.URL.RawQuery+"\n"), body...))
+
+			response, replayed, err := runner(r.Context(), hex.EncodeToString(scope[:]), hex.EncodeToString(fingerprint[:]), func() dto.IdempotentResponse {
+				rec := &bufferedResponseWriter{
+					header: make(http.Header),
+					status: http.StatusOK,
+				}
+				next.ServeHTTP(rec, r)
+				return dto.IdempotentResponse{
+					Status: rec.status,
+					Header: rec.header,
+					Body:   rec.body.Bytes(),
+				}
+			})
+			if err != nil {
+				httpserver.WriteError(w, r, err)
+				return
+			}
+
+			for key, values := range response.Header {
+				w.Header()[key] = values
+			}
+			if replayed {
+				w.Header().Set(IdempotentReplayedHeader, "true")
+			}
+			w.WriteHeader(response.Status)
+			w.Write(response.Body)
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/auth"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/httpserver"
+)
+
+// RateLimiter counts a request against its reader ("user:<id>", "device:<id>" or empty for
+// anonymous requests) and client IP, returning an AppError and how long to wait when refused
+type RateLimiter func(ctx context.Context, reader, ip string) (time.Duration, error)
+
+// RateLimit throttles requests per reader and per client IP. Readers are identified by a valid
+// bearer token or the X-Device-ID header; an invalid token only counts against the IP and is
+// left for the route to refuse
+func RateLimit(user UserAuthenticator, limit RateLimiter, trustedProxies int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var reader string
+			if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && token != "" {
+				if userID, err := user(r.Context(), token); err == nil {
+					reader = "user:" + strconv.FormatInt(userID, 10)
+				}
+			} else if deviceID := r.Header.Get(auth.DeviceIDHeader); deviceID != "" && len(deviceID) <= 128 {
+				reader = "device:" + deviceID
+			}
+
+			retryAfter, err := limit(r.Context(), reader, clientIP(r, trustedProxies))
+			if err != nil {
+				if retryAfter > 0 {
+					w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Round(time.Second).Seconds())))
+				}
+				httpserver.WriteError(w, r, err)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// clientIP is the X-Forwarded-For entry added by the outermost of trustedProxies proxies,
+// otherwise the address of the connection. Clients can send X-Forwarded-For themselves, so
+// only the entries appended by trusted proxies on the right are believed
+func clientIP(r *http.Request, trustedProxies int) string {
+	if trustedProxies > 0 {
+		var entries []string
+		for _, header := range r.Header.Values("X-Forwarded-For") {
+			for _, entry := range strings.Split(header, ",") {
+				if entry = strings.TrimSpace(entry); entry != "" {
+					entries = append(entries, entry)
+				}
+			}
+		}
+		if len(entries) > 0 {
+			// fewer entries than proxies means the request came in past some of them
+			return entries[max(len(entries)-trustedProxies, 0)]
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
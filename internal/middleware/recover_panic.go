@@ -1,20 +1,69 @@
 package middleware
 
 import (
+	"errors"
 	"log/slog"
 	"net/http"
 	"runtime/debug"
+	"sync/atomic"
+
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/errorreport"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/httpserver"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/requestid"
+	apperrors "github.com/onefeed-th/onefeed-th-backend-api/internal/errors"
 )
 
+// panics counts the panics recovered since the process started
+var panics atomic.Uint64
+
+// PanicCount returns how many requests panicked since the process started
+func PanicCount() uint64 {
+	return panics.Load()
+}
+
+// recoveredPanic carries a panic recovered on another goroutine, such as Timeout's, with
+// the stack of the goroutine it happened on
+type recoveredPanic struct {
+	value any
+	stack []byte
+}
+
+// RecoverPanic turns a panicking request into a 500 error response with the request id,
+// and logs, counts and reports the panic with its stack
 func RecoverPanic(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w}
 		defer func() {
-			if err := recover(); err != nil {
-				slog.Error("panic recovered: %v", err)
-				slog.Error("Stack trace:", "stack", string(debug.Stack()))
-				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			p := recover()
+			if p == nil {
+				return
+			}
+			// net/http uses this panic to abort a response on purpose
+			if err, ok := p.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				panic(p)
+			}
+
+			stack := debug.Stack()
+			if recovered, ok := p.(recoveredPanic); ok {
+				p, stack = recovered.value, recovered.stack
+			}
+			panics.Add(1)
+			slog.Error("Panic recovered",
+				"panic", p,
+				"method", r.Method,
+				"path", r.URL.Path,
+				"request_id", requestid.FromContext(r.Context()),
+				"stack", string(stack),
+			)
+			errorreport.Panic(r, p)
+
+			// the status line is gone once the handler started answering
+			if rec.status != 0 {
+				return
 			}
+			httpserver.WriteError(rec, r, apperrors.New(apperrors.InternalError, "internal server error").
+				WithCode(errorreport.PanicCode))
 		}()
-		next.ServeHTTP(w, r)
+		next.ServeHTTP(rec, r)
 	})
 }
@@ -0,0 +1,139 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/httpserver"
+	apperrors "github.com/onefeed-th/onefeed-th-backend-api/internal/errors"
+)
+
+// maxBufferedBody is how much of a response is held back so a timeout can still replace it
+// with a 504; a larger response is sent as it is written, like http.TimeoutHandler can't do
+const maxBufferedBody = 1 << 20
+
+// Timeout gives each request a deadline. Database and HTTP calls made with the request
+// context are cancelled when it passes, and the client gets a 504 instead of whatever the
+// handler writes afterwards. Responses over maxBufferedBody, or flushed by the handler, are
+// already on their way, so a timeout only cuts them short. A zero duration disables the
+// middleware
+func Timeout(timeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if timeout <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+
+			tw := &timeoutResponseWriter{
+				w:      w,
+				header: make(http.Header),
+				status: http.StatusOK,
+			}
+			done := make(chan struct{})
+			panicked := make(chan any, 1)
+			go func() {
+				defer func() {
+					if p := recover(); p != nil {
+						panicked <- recoveredPanic{value: p, stack: debug.Stack()}
+					}
+				}()
+				next.ServeHTTP(tw, r.WithContext(ctx))
+				close(done)
+			}()
+
+			select {
+			case p := <-panicked:
+				// re-panic on the serving goroutine so RecoverPanic handles it
+				panic(p)
+			case <-done:
+				tw.mu.Lock()
+				defer tw.mu.Unlock()
+				tw.send()
+			case <-ctx.Done():
+				tw.mu.Lock()
+				defer tw.mu.Unlock()
+				tw.timedOut = true
+				if tw.sent || r.Context().Err() != nil {
+					// the response is already underway, or the client went away
+					return
+				}
+				httpserver.WriteError(w, r, apperrors.New(apperrors.TimeoutError, fmt.Sprintf("request did not finish within %s", timeout)).
+					WithCode("REQUEST_TIMEOUT"))
+			}
+		})
+	}
+}
+
+// timeoutResponseWriter buffers the handler's response until it finishes in time, grows
+// past maxBufferedBody or is flushed; writes after the deadline are discarded
+type timeoutResponseWriter struct {
+	mu       sync.Mutex
+	w        http.ResponseWriter
+	header   http.Header
+	body     bytes.Buffer
+	status   int
+	sent     bool // the response went out and writes pass straight through
+	timedOut bool
+}
+
+func (t *timeoutResponseWriter) Header() http.Header {
+	return t.header
+}
+
+func (t *timeoutResponseWriter) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if !t.sent && t.body.Len()+len(p) > maxBufferedBody {
+		t.send()
+	}
+	if t.sent {
+		return t.w.Write(p)
+	}
+	return t.body.Write(p)
+}
+
+func (t *timeoutResponseWriter) WriteHeader(status int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.timedOut && !t.sent {
+		t.status = status
+	}
+}
+
+// Flush sends what was buffered so streaming handlers such as the CSV export reach the
+// client as they go
+func (t *timeoutResponseWriter) Flush() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.timedOut {
+		return
+	}
+	t.send()
+	if flusher, ok := t.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// send writes the header and the buffered body out; callers hold the lock
+func (t *timeoutResponseWriter) send() {
+	if t.sent {
+		return
+	}
+	t.sent = true
+	for key, values := range t.header {
+		t.w.Header()[key] = values
+	}
+	t.w.WriteHeader(t.status)
+	t.w.Write(t.body.Bytes())
+	t.body.Reset()
+}
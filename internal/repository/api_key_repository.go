@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	onefeed_th_sqlc "github.com/onefeed-th/onefeed-th-backend-api/internal/sqlc/onefeed_th_sqlc/db"
+)
+
+type APIKeyRepository interface {
+	CreateAPIKey(ctx context.Context, params onefeed_th_sqlc.CreateApiKeyParams) (onefeed_th_sqlc.ApiKey, error)
+	GetActiveAPIKeyByHash(ctx context.Context, keyHash string) (onefeed_th_sqlc.ApiKey, error)
+	GetAPIKeys(ctx context.Context) ([]onefeed_th_sqlc.ApiKey, error)
+	RevokeAPIKey(ctx context.Context, id int64) (int64, error)
+}
+
+type APIKeyRepositoryImpl struct {
+	pool dbPool
+}
+
+func NewAPIKeyRepository(pool func() *pgxpool.Pool) APIKeyRepository {
+	return &APIKeyRepositoryImpl{
+		pool: pool,
+	}
+}
+
+func (r *APIKeyRepositoryImpl) CreateAPIKey(ctx context.Context, params onefeed_th_sqlc.CreateApiKeyParams) (onefeed_th_sqlc.ApiKey, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := onefeed_th_sqlc.New(r.pool)
+	return query.CreateApiKey(ctx, params)
+}
+
+func (r *APIKeyRepositoryImpl) GetActiveAPIKeyByHash(ctx context.Context, keyHash string) (onefeed_th_sqlc.ApiKey, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) (onefeed_th_sqlc.ApiKey, error) {
+		query := onefeed_th_sqlc.New(r.pool)
+		return query.GetActiveApiKeyByHash(ctx, keyHash)
+	})
+}
+
+func (r *APIKeyRepositoryImpl) GetAPIKeys(ctx context.Context) ([]onefeed_th_sqlc.ApiKey, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) ([]onefeed_th_sqlc.ApiKey, error) {
+		query := onefeed_th_sqlc.New(r.pool)
+		return query.ListApiKeys(ctx)
+	})
+}
+
+func (r *APIKeyRepositoryImpl) RevokeAPIKey(ctx context.Context, id int64) (int64, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := onefeed_th_sqlc.New(r.pool)
+	return query.RevokeApiKey(ctx, id)
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	onefeed_th_sqlc "github.com/onefeed-th/onefeed-th-backend-api/internal/sqlc/onefeed_th_sqlc/db"
+)
+
+type BackofficeUserRepository interface {
+	CreateBackofficeUser(ctx context.Context, params onefeed_th_sqlc.CreateBackofficeUserParams) (onefeed_th_sqlc.BackofficeUser, error)
+	GetBackofficeUserByID(ctx context.Context, id int64) (onefeed_th_sqlc.BackofficeUser, error)
+	GetBackofficeUserByUsername(ctx context.Context, username string) (onefeed_th_sqlc.BackofficeUser, error)
+	GetBackofficeUsers(ctx context.Context) ([]onefeed_th_sqlc.BackofficeUser, error)
+	UpdateBackofficeUser(ctx context.Context, params onefeed_th_sqlc.UpdateBackofficeUserParams) (onefeed_th_sqlc.BackofficeUser, error)
+}
+
+type BackofficeUserRepositoryImpl struct {
+	pool dbPool
+}
+
+func NewBackofficeUserRepository(pool func() *pgxpool.Pool) BackofficeUserRepository {
+	return &BackofficeUserRepositoryImpl{
+		pool: pool,
+	}
+}
+
+func (r *BackofficeUserRepositoryImpl) CreateBackofficeUser(ctx context.Context, params onefeed_th_sqlc.CreateBackofficeUserParams) (onefeed_th_sqlc.BackofficeUser, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := onefeed_th_sqlc.New(r.pool)
+	return query.CreateBackofficeUser(ctx, params)
+}
+
+func (r *BackofficeUserRepositoryImpl) GetBackofficeUserByID(ctx context.Context, id int64) (onefeed_th_sqlc.BackofficeUser, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) (onefeed_th_sqlc.BackofficeUser, error) {
+		query := onefeed_th_sqlc.New(r.pool)
+		return query.GetBackofficeUserByID(ctx, id)
+	})
+}
+
+func (r *BackofficeUserRepositoryImpl) GetBackofficeUserByUsername(ctx context.Context, username string) (onefeed_th_sqlc.BackofficeUser, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) (onefeed_th_sqlc.BackofficeUser, error) {
+		query := onefeed_th_sqlc.New(r.pool)
+		return query.GetBackofficeUserByUsername(ctx, username)
+	})
+}
+
+func (r *BackofficeUserRepositoryImpl) GetBackofficeUsers(ctx context.Context) ([]onefeed_th_sqlc.BackofficeUser, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) ([]onefeed_th_sqlc.BackofficeUser, error) {
+		query := onefeed_th_sqlc.New(r.pool)
+		return query.ListBackofficeUsers(ctx)
+	})
+}
+
+func (r *BackofficeUserRepositoryImpl) UpdateBackofficeUser(ctx context.Context, params onefeed_th_sqlc.UpdateBackofficeUserParams) (onefeed_th_sqlc.BackofficeUser, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := onefeed_th_sqlc.New(r.pool)
+	return query.UpdateBackofficeUser(ctx, params)
+}
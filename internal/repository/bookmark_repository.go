@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	onefeed_th_sqlc "github.com/onefeed-th/onefeed-th-backend-api/internal/sqlc/onefeed_th_sqlc/db"
+)
+
+type BookmarkRepository interface {
+	CreateBookmark(ctx context.Context, params onefeed_th_sqlc.CreateBookmarkParams) error
+	DeleteBookmark(ctx context.Context, params onefeed_th_sqlc.DeleteBookmarkParams) (int64, error)
+	GetBookmarks(ctx context.Context, params onefeed_th_sqlc.ListBookmarksParams) ([]onefeed_th_sqlc.Bookmark, error)
+}
+
+type BookmarkRepositoryImpl struct {
+	pool dbPool
+}
+
+func NewBookmarkRepository(pool func() *pgxpool.Pool) BookmarkRepository {
+	return &BookmarkRepositoryImpl{
+		pool: pool,
+	}
+}
+
+func (r *BookmarkRepositoryImpl) CreateBookmark(ctx context.Context, params onefeed_th_sqlc.CreateBookmarkParams) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := onefeed_th_sqlc.New(r.pool)
+	return query.CreateBookmark(ctx, params)
+}
+
+func (r *BookmarkRepositoryImpl) DeleteBookmark(ctx context.Context, params onefeed_th_sqlc.DeleteBookmarkParams) (int64, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := onefeed_th_sqlc.New(r.pool)
+	return query.DeleteBookmark(ctx, params)
+}
+
+func (r *BookmarkRepositoryImpl) GetBookmarks(ctx context.Context, params onefeed_th_sqlc.ListBookmarksParams) ([]onefeed_th_sqlc.Bookmark, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) ([]onefeed_th_sqlc.Bookmark, error) {
+		query := onefeed_th_sqlc.New(r.pool)
+		return query.ListBookmarks(ctx, params)
+	})
+}
@@ -0,0 +1,171 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/onefeed-th/onefeed-th-backend-api/config"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/db"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/repository"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/repository/memory"
+)
+
+// The contract tests run every repository method against the memory store and, when
+// ONEFEED_TEST_POSTGRES is set, against the Postgres database configured by the usual
+// POSTGRES_* variables. The database is migrated and every table is truncated before
+// each test, so it must be one dedicated to the tests
+const postgresEnvVar = "ONEFEED_TEST_POSTGRES"
+
+var (
+	postgresOnce sync.Once
+	postgresRepo *repository.Repository
+	postgresErr  error
+)
+
+// forEachRepository runs fn against a fresh repository of every available implementation
+func forEachRepository(t *testing.T, fn func(t *testing.T, repo *repository.Repository)) {
+	t.Helper()
+	t.Run("memory", func(t *testing.T) {
+		fn(t, memory.NewRepository())
+	})
+	t.Run("postgres", func(t *testing.T) {
+		fn(t, newPostgresRepository(t))
+	})
+}
+
+func newPostgresRepository(t *testing.T) *repository.Repository {
+	t.Helper()
+	if os.Getenv(postgresEnvVar) == "" {
+		t.Skipf("set %s to run against Postgres", postgresEnvVar)
+	}
+	ctx := context.Background()
+	postgresOnce.Do(func() {
+		if postgresErr = config.Init(ctx, ""); postgresErr != nil {
+			return
+		}
+		if postgresErr = db.InitDB(); postgresErr != nil {
+			return
+		}
+		if _, postgresErr = db.MigrateUp(ctx); postgresErr != nil {
+			return
+		}
+		postgresRepo = repository.NewRepository()
+	})
+	if postgresErr != nil {
+		t.Fatalf("failed to prepare Postgres: %v", postgresErr)
+	}
+	if err := truncateTables(ctx); err != nil {
+		t.Fatalf("failed to truncate tables: %v", err)
+	}
+	return postgresRepo
+}
+
+// truncateTables empties every table but the migration history, seeds included, so each
+// test starts from the same state as a fresh memory store
+func truncateTables(ctx context.Context) error {
+	rows, err := db.GetPool().Query(ctx, `SELECT tablename FROM pg_tables
+WHERE schemaname = current_schema()
+  AND tablename <> 'schema_migrations'`)
+	if err != nil {
+		return err
+	}
+	tables, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return err
+	}
+	identifiers := make([]string, 0, len(tables))
+	for _, table := range tables {
+		identifiers = append(identifiers, pgx.Identifier{table}.Sanitize())
+	}
+	_, err = db.GetPool().Exec(ctx, "TRUNCATE "+strings.Join(identifiers, ", ")+" RESTART IDENTITY CASCADE")
+	return err
+}
+
+// day is midnight UTC offset by n days from today, so rows land in predictable buckets
+func day(n int) time.Time {
+	return time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, n)
+}
+
+func timestamp(t time.Time) pgtype.Timestamp {
+	return pgtype.Timestamp{Time: t, Valid: true}
+}
+
+func text(s string) pgtype.Text {
+	return pgtype.Text{String: s, Valid: true}
+}
+
+// must is called as must(call())(t), failing the test when call returned an error
+func must[T any](value T, err error) func(t *testing.T) T {
+	return func(t *testing.T) T {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return value
+	}
+}
+
+// must2 is must for calls that return two values besides the error
+func must2[A, B any](a A, b B, err error) func(t *testing.T) (A, B) {
+	return func(t *testing.T) (A, B) {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return a, b
+	}
+}
+
+func mustDo(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func expectNoRows(t *testing.T, err error) {
+	t.Helper()
+	if !errors.Is(err, pgx.ErrNoRows) {
+		t.Fatalf("expected pgx.ErrNoRows, got %v", err)
+	}
+}
+
+func expectUniqueViolation(t *testing.T, err error) {
+	t.Helper()
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != "23505" {
+		t.Fatalf("expected a unique violation, got %v", err)
+	}
+}
+
+func expectEqual[T comparable](t *testing.T, name string, got, want T) {
+	t.Helper()
+	if got != want {
+		t.Fatalf("%s = %v, want %v", name, got, want)
+	}
+}
+
+// ids collects the ids of rows in their order
+func ids[T any](rows []T, id func(T) int64) []int64 {
+	out := make([]int64, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, id(row))
+	}
+	return out
+}
+
+func expectIDs(t *testing.T, name string, got, want []int64) {
+	t.Helper()
+	if !slices.Equal(got, want) {
+		t.Fatalf("%s = %v, want %v", name, got, want)
+	}
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	onefeed_th_sqlc "github.com/onefeed-th/onefeed-th-backend-api/internal/sqlc/onefeed_th_sqlc/db"
+)
+
+type DeadLetterRepository interface {
+	DeleteDeadLetter(ctx context.Context, id int64) (int64, error)
+	GetDeadLetterByID(ctx context.Context, id int64) (onefeed_th_sqlc.DeadLetter, error)
+	GetDeadLetters(ctx context.Context, params onefeed_th_sqlc.ListDeadLettersParams) ([]onefeed_th_sqlc.DeadLetter, error)
+	UpdateDeadLetterError(ctx context.Context, params onefeed_th_sqlc.UpdateDeadLetterErrorParams) error
+	UpsertDeadLetter(ctx context.Context, params onefeed_th_sqlc.UpsertDeadLetterParams) error
+}
+
+type DeadLetterRepositoryImpl struct {
+	pool dbPool
+}
+
+func NewDeadLetterRepository(pool func() *pgxpool.Pool) DeadLetterRepository {
+	return &DeadLetterRepositoryImpl{
+		pool: pool,
+	}
+}
+
+func (r *DeadLetterRepositoryImpl) DeleteDeadLetter(ctx context.Context, id int64) (int64, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := onefeed_th_sqlc.New(r.pool)
+	return query.DeleteDeadLetter(ctx, id)
+}
+
+func (r *DeadLetterRepositoryImpl) GetDeadLetterByID(ctx context.Context, id int64) (onefeed_th_sqlc.DeadLetter, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) (onefeed_th_sqlc.DeadLetter, error) {
+		query := onefeed_th_sqlc.New(r.pool)
+		return query.GetDeadLetterByID(ctx, id)
+	})
+}
+
+func (r *DeadLetterRepositoryImpl) GetDeadLetters(ctx context.Context, params onefeed_th_sqlc.ListDeadLettersParams) ([]onefeed_th_sqlc.DeadLetter, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) ([]onefeed_th_sqlc.DeadLetter, error) {
+		query := onefeed_th_sqlc.New(r.pool)
+		return query.ListDeadLetters(ctx, params)
+	})
+}
+
+func (r *DeadLetterRepositoryImpl) UpdateDeadLetterError(ctx context.Context, params onefeed_th_sqlc.UpdateDeadLetterErrorParams) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := onefeed_th_sqlc.New(r.pool)
+	return query.UpdateDeadLetterError(ctx, params)
+}
+
+func (r *DeadLetterRepositoryImpl) UpsertDeadLetter(ctx context.Context, params onefeed_th_sqlc.UpsertDeadLetterParams) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := onefeed_th_sqlc.New(r.pool)
+	return query.UpsertDeadLetter(ctx, params)
+}
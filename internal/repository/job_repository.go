@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/utils/converter"
+	onefeed_th_sqlc "github.com/onefeed-th/onefeed-th-backend-api/internal/sqlc/onefeed_th_sqlc/db"
+)
+
+type JobRepository interface {
+	ClaimJobs(ctx context.Context, params onefeed_th_sqlc.ClaimJobsParams) ([]onefeed_th_sqlc.Job, error)
+	CompleteJob(ctx context.Context, params onefeed_th_sqlc.CompleteJobParams) (int64, error)
+	CreateJob(ctx context.Context, params onefeed_th_sqlc.CreateJobParams) (onefeed_th_sqlc.Job, error)
+	DeleteJobsFinishedBefore(ctx context.Context, before time.Time) (int64, error)
+	ExtendJobLease(ctx context.Context, params onefeed_th_sqlc.ExtendJobLeaseParams) (int64, error)
+	FailJob(ctx context.Context, params onefeed_th_sqlc.FailJobParams) (int64, error)
+	GetJobByID(ctx context.Context, id int64) (onefeed_th_sqlc.Job, error)
+	GetJobs(ctx context.Context, params onefeed_th_sqlc.ListJobsParams) ([]onefeed_th_sqlc.Job, error)
+	RequeueJob(ctx context.Context, params onefeed_th_sqlc.RequeueJobParams) (onefeed_th_sqlc.Job, error)
+	RetryJob(ctx context.Context, params onefeed_th_sqlc.RetryJobParams) (int64, error)
+}
+
+type JobRepositoryImpl struct {
+	pool dbPool
+}
+
+func NewJobRepository(pool func() *pgxpool.Pool) JobRepository {
+	return &JobRepositoryImpl{
+		pool: pool,
+	}
+}
+
+func (r *JobRepositoryImpl) ClaimJobs(ctx context.Context, params onefeed_th_sqlc.ClaimJobsParams) ([]onefeed_th_sqlc.Job, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := onefeed_th_sqlc.New(r.pool)
+	return query.ClaimJobs(ctx, params)
+}
+
+func (r *JobRepositoryImpl) CompleteJob(ctx context.Context, params onefeed_th_sqlc.CompleteJobParams) (int64, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := onefeed_th_sqlc.New(r.pool)
+	return query.CompleteJob(ctx, params)
+}
+
+func (r *JobRepositoryImpl) CreateJob(ctx context.Context, params onefeed_th_sqlc.CreateJobParams) (onefeed_th_sqlc.Job, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := onefeed_th_sqlc.New(r.pool)
+	return query.CreateJob(ctx, params)
+}
+
+func (r *JobRepositoryImpl) DeleteJobsFinishedBefore(ctx context.Context, before time.Time) (int64, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := onefeed_th_sqlc.New(r.pool)
+	return query.DeleteJobsFinishedBefore(ctx, converter.TimeToPGTypeTimestamp(before))
+}
+
+func (r *JobRepositoryImpl) ExtendJobLease(ctx context.Context, params onefeed_th_sqlc.ExtendJobLeaseParams) (int64, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := onefeed_th_sqlc.New(r.pool)
+	return query.ExtendJobLease(ctx, params)
+}
+
+func (r *JobRepositoryImpl) FailJob(ctx context.Context, params onefeed_th_sqlc.FailJobParams) (int64, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := onefeed_th_sqlc.New(r.pool)
+	return query.FailJob(ctx, params)
+}
+
+func (r *JobRepositoryImpl) GetJobByID(ctx context.Context, id int64) (onefeed_th_sqlc.Job, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) (onefeed_th_sqlc.Job, error) {
+		query := onefeed_th_sqlc.New(r.pool)
+		return query.GetJobByID(ctx, id)
+	})
+}
+
+func (r *JobRepositoryImpl) GetJobs(ctx context.Context, params onefeed_th_sqlc.ListJobsParams) ([]onefeed_th_sqlc.Job, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) ([]onefeed_th_sqlc.Job, error) {
+		query := onefeed_th_sqlc.New(r.pool)
+		return query.ListJobs(ctx, params)
+	})
+}
+
+func (r *JobRepositoryImpl) RequeueJob(ctx context.Context, params onefeed_th_sqlc.RequeueJobParams) (onefeed_th_sqlc.Job, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := onefeed_th_sqlc.New(r.pool)
+	return query.RequeueJob(ctx, params)
+}
+
+func (r *JobRepositoryImpl) RetryJob(ctx context.Context, params onefeed_th_sqlc.RetryJobParams) (int64, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := onefeed_th_sqlc.New(r.pool)
+	return query.RetryJob(ctx, params)
+}
@@ -0,0 +1,2808 @@
+// Package memory is an in-process implementation of the repository interfaces used by
+// the "memory" storage driver, so the API can run locally without Postgres.
+// Data lives only as long as the process and every query mirrors its SQL counterpart
+// in internal/sqlc closely enough for development, not for production use.
+package memory
+
+import (
+	"context"
+	"math/rand/v2"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/utils/converter"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/repository"
+	onefeed_th_sqlc "github.com/onefeed-th/onefeed-th-backend-api/internal/sqlc/onefeed_th_sqlc/db"
+)
+
+type clickKey struct {
+	newsID      int64
+	bucketStart time.Time
+}
+
+// Store holds every table in memory and implements all repository interfaces
+type Store struct {
+	mu sync.RWMutex
+
+	sources      []onefeed_th_sqlc.Source
+	news         []onefeed_th_sqlc.News
+	clicks       map[clickKey]int64
+	archive      []onefeed_th_sqlc.NewsArchive
+	moderation   []onefeed_th_sqlc.NewsModerationLog
+	apiKeys      []onefeed_th_sqlc.ApiKey
+	users        []onefeed_th_sqlc.BackofficeUser
+	webhooks     []onefeed_th_sqlc.Webhook
+	failures     []onefeed_th_sqlc.WebhookFailure
+	rules        []onefeed_th_sqlc.NotificationRule
+	devices      []onefeed_th_sqlc.PushDevice
+	pushJobs     []onefeed_th_sqlc.PushJob
+	readers      []onefeed_th_sqlc.User
+	identities   []onefeed_th_sqlc.UserIdentity
+	reads        []onefeed_th_sqlc.NewsRead
+	preferences  []onefeed_th_sqlc.UserPreference
+	bookmarks    []onefeed_th_sqlc.Bookmark
+	tags         []onefeed_th_sqlc.Tag
+	sourceTags   []onefeed_th_sqlc.SourceTag
+	suggestions  []onefeed_th_sqlc.SourceSuggestion
+	jobs         []onefeed_th_sqlc.Job
+	deadLetters  []onefeed_th_sqlc.DeadLetter
+	sourceStats  []onefeed_th_sqlc.SourceStatsDaily
+	dailyClicks  []onefeed_th_sqlc.NewsClicksDaily
+	searches     []onefeed_th_sqlc.SavedSearch
+	clusters     []onefeed_th_sqlc.NewsCluster
+	nextSourceID int64
+	nextNewsID   int64
+	nextLogID    int64
+	nextAPIKeyID int64
+	nextUserID   int64
+	nextHookID   int64
+	nextFailID   int64
+	nextRuleID   int64
+	nextDeviceID int64
+	nextJobID    int64
+	nextReaderID int64
+	nextTagID    int32
+	nextSuggID   int64
+	nextQueueID  int64
+	nextLetterID int64
+	nextSearchID int64
+}
+
+func NewStore() *Store {
+	return &Store{
+		clicks: make(map[clickKey]int64),
+	}
+}
+
+// NewRepository wires a fresh Store into every repository slot
+func NewRepository() *repository.Repository {
+	store := NewStore()
+	return &repository.Repository{
+		SourceRepository:           store,
+		NewsRepository:             store,
+		NewsClickRepository:        store,
+		NewsArchiveRepository:      store,
+		NewsModerationRepository:   store,
+		APIKeyRepository:           store,
+		BackofficeUserRepository:   store,
+		WebhookRepository:          store,
+		NotificationRuleRepository: store,
+		PushRepository:             store,
+		UserRepository:             store,
+		NewsReadRepository:         store,
+		UserPreferenceRepository:   store,
+		BookmarkRepository:         store,
+		TagRepository:              store,
+		SourceSuggestionRepository: store,
+		JobRepository:              store,
+		DeadLetterRepository:       store,
+		SourceStatsRepository:      store,
+		SavedSearchRepository:      store,
+		NewsClusterRepository:      store,
+	}
+}
+
+// Sources
+
+func (s *Store) GetAllSources(ctx context.Context, includeDisabled bool) ([]onefeed_th_sqlc.Source, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return filter(s.sources, func(source onefeed_th_sqlc.Source) bool {
+		return !source.DeletedAt.Valid && (source.Enabled || includeDisabled)
+	}), nil
+}
+
+func (s *Store) GetAllSourcesWithPagination(ctx context.Context, req onefeed_th_sqlc.GetAllSourcesWithPaginationParams) ([]onefeed_th_sqlc.Source, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sources := s.filterSources(req.Pattern, req.Tag, req.Status)
+	sort.SliceStable(sources, func(i, j int) bool {
+		switch req.Sort {
+		case "name:asc":
+			return sources[i].Name < sources[j].Name
+		case "name:desc":
+			return sources[i].Name > sources[j].Name
+		case "createdAt:asc":
+			return sources[i].CreatedAt.Time.Before(sources[j].CreatedAt.Time)
+		}
+		return sources[i].CreatedAt.Time.After(sources[j].CreatedAt.Time)
+	})
+	return paginate(sources, req.PageOffset, req.PageLimit), nil
+}
+
+func (s *Store) CountSources(ctx context.Context, req onefeed_th_sqlc.CountSourcesParams) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return int64(len(s.filterSources(req.Pattern, req.Tag, req.Status))), nil
+}
+
+// filterSources mirrors the WHERE clause shared by GetAllSourcesWithPagination and CountSources
+func (s *Store) filterSources(pattern, tag, status string) []onefeed_th_sqlc.Source {
+	matcher := likePattern(pattern)
+	return filter(s.sources, func(source onefeed_th_sqlc.Source) bool {
+		if source.DeletedAt.Valid || !matcher.MatchString(source.Name) {
+			return false
+		}
+		if status != "" && source.Enabled != (status == "enabled") {
+			return false
+		}
+		return tag == "" || slices.ContainsFunc(s.sourceTags, func(link onefeed_th_sqlc.SourceTag) bool {
+			if link.SourceID != source.ID {
+				return false
+			}
+			i := slices.IndexFunc(s.tags, func(t onefeed_th_sqlc.Tag) bool { return t.ID == link.TagID })
+			return i >= 0 && strings.EqualFold(s.tags[i].Name, tag)
+		})
+	})
+}
+
+func (s *Store) CreateSource(ctx context.Context, req onefeed_th_sqlc.CreateSourceParams) (onefeed_th_sqlc.Source, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextSourceID++
+	source := onefeed_th_sqlc.Source{
+		ID:        s.nextSourceID,
+		Name:      req.Name,
+		Tags:      req.Tags,
+		RssUrl:    req.RssUrl,
+		CreatedAt: converter.TimeToPGTypeTimestamp(time.Now()),
+		Enabled:   true,
+	}
+	s.sources = append(s.sources, source)
+	return s.syncSourceTags(source.ID, req.Tags), nil
+}
+
+func (s *Store) GetSourceByID(ctx context.Context, id int64) (onefeed_th_sqlc.Source, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	source, ok := findByID(s.sources, id, func(source onefeed_th_sqlc.Source) int64 { return source.ID })
+	if !ok || source.DeletedAt.Valid {
+		return onefeed_th_sqlc.Source{}, pgx.ErrNoRows
+	}
+	return source, nil
+}
+
+func (s *Store) UpdateSource(ctx context.Context, req onefeed_th_sqlc.UpdateSourceParams) (onefeed_th_sqlc.Source, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.sources {
+		if s.sources[i].ID != req.ID || s.sources[i].DeletedAt.Valid {
+			continue
+		}
+		if s.sources[i].Name != req.Name {
+			for j := range s.news {
+				if s.news[j].Source == s.sources[i].Name {
+					s.news[j].Source = req.Name
+				}
+			}
+		}
+		s.sources[i].Name = req.Name
+		s.sources[i].RssUrl = req.RssUrl
+		s.sources[i].ImageSelector = req.ImageSelector
+		s.sources[i].ImageFields = req.ImageFields
+		return s.syncSourceTags(req.ID, req.Tags), nil
+	}
+	return onefeed_th_sqlc.Source{}, pgx.ErrNoRows
+}
+
+func (s *Store) SetSourceEnabled(ctx context.Context, req onefeed_th_sqlc.SetSourceEnabledParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.sources {
+		if s.sources[i].ID == req.ID && !s.sources[i].DeletedAt.Valid {
+			s.sources[i].Enabled = req.Enabled
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func (s *Store) DeleteSource(ctx context.Context, id int64) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.sources {
+		if s.sources[i].ID == id && !s.sources[i].DeletedAt.Valid {
+			s.sources[i].DeletedAt = converter.TimeToPGTypeTimestamp(time.Now())
+			s.sources[i].Enabled = false
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+// syncSourceTags mirrors the SQL tag sync: links the source to the named tags, creating
+// missing ones, and rewrites its tags copy. The caller holds the write lock
+func (s *Store) syncSourceTags(sourceID int64, tags pgtype.Text) onefeed_th_sqlc.Source {
+	s.sourceTags = filter(s.sourceTags, func(link onefeed_th_sqlc.SourceTag) bool { return link.SourceID != sourceID })
+	for _, name := range repository.SourceTagNames(tags.String) {
+		i := slices.IndexFunc(s.tags, func(tag onefeed_th_sqlc.Tag) bool { return strings.EqualFold(tag.Name, name) })
+		if i < 0 {
+			s.nextTagID++
+			s.tags = append(s.tags, onefeed_th_sqlc.Tag{
+				ID:        s.nextTagID,
+				Name:      name,
+				CreatedAt: converter.TimeToPGTypeTimestamp(time.Now()),
+			})
+			i = len(s.tags) - 1
+		}
+		s.sourceTags = append(s.sourceTags, onefeed_th_sqlc.SourceTag{SourceID: sourceID, TagID: s.tags[i].ID})
+	}
+	refreshed := s.refreshSourceTags([]int64{sourceID})
+	return refreshed[0]
+}
+
+// refreshSourceTags rewrites the tags copy of the sources from their linked tags. The caller
+// holds the write lock
+func (s *Store) refreshSourceTags(ids []int64) []onefeed_th_sqlc.Source {
+	var refreshed []onefeed_th_sqlc.Source
+	for i := range s.sources {
+		if !slices.Contains(ids, s.sources[i].ID) {
+			continue
+		}
+		var names []string
+		for _, link := range s.sourceTags {
+			if link.SourceID != s.sources[i].ID {
+				continue
+			}
+			if j := slices.IndexFunc(s.tags, func(tag onefeed_th_sqlc.Tag) bool { return tag.ID == link.TagID }); j >= 0 {
+				names = append(names, s.tags[j].Name)
+			}
+		}
+		sort.Strings(names)
+		s.sources[i].Tags = pgtype.Text{String: strings.Join(names, ","), Valid: len(names) > 0}
+		refreshed = append(refreshed, s.sources[i])
+	}
+	return refreshed
+}
+
+// Tags
+
+func (s *Store) GetTags(ctx context.Context) ([]onefeed_th_sqlc.ListTagsWithSourceCountsRow, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rows := make([]onefeed_th_sqlc.ListTagsWithSourceCountsRow, 0, len(s.tags))
+	for _, tag := range s.tags {
+		row := onefeed_th_sqlc.ListTagsWithSourceCountsRow{
+			ID:          tag.ID,
+			Name:        tag.Name,
+			Description: tag.Description,
+			CreatedAt:   tag.CreatedAt,
+		}
+		for _, link := range s.sourceTags {
+			if link.TagID != tag.ID {
+				continue
+			}
+			source, ok := findByID(s.sources, link.SourceID, func(source onefeed_th_sqlc.Source) int64 { return source.ID })
+			if ok && !source.DeletedAt.Valid {
+				row.SourceCount++
+			}
+		}
+		rows = append(rows, row)
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Name < rows[j].Name })
+	return rows, nil
+}
+
+func (s *Store) GetTagByID(ctx context.Context, id int32) (onefeed_th_sqlc.Tag, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tag, ok := findByID(s.tags, int64(id), func(tag onefeed_th_sqlc.Tag) int64 { return int64(tag.ID) })
+	if !ok {
+		return onefeed_th_sqlc.Tag{}, pgx.ErrNoRows
+	}
+	return tag, nil
+}
+
+func (s *Store) CreateTag(ctx context.Context, params onefeed_th_sqlc.CreateTagParams) (onefeed_th_sqlc.Tag, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.tagNameTaken(params.Name, 0) {
+		return onefeed_th_sqlc.Tag{}, &pgconn.PgError{Code: "23505", Message: "duplicate key value violates unique constraint"}
+	}
+	s.nextTagID++
+	tag := onefeed_th_sqlc.Tag{
+		ID:          s.nextTagID,
+		Name:        params.Name,
+		Description: params.Description,
+		CreatedAt:   converter.TimeToPGTypeTimestamp(time.Now()),
+	}
+	s.tags = append(s.tags, tag)
+	return tag, nil
+}
+
+func (s *Store) UpdateTag(ctx context.Context, params onefeed_th_sqlc.UpdateTagParams) (onefeed_th_sqlc.Tag, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.tagNameTaken(params.Name, params.ID) {
+		return onefeed_th_sqlc.Tag{}, &pgconn.PgError{Code: "23505", Message: "duplicate key value violates unique constraint"}
+	}
+	for i := range s.tags {
+		if s.tags[i].ID != params.ID {
+			continue
+		}
+		s.tags[i].Name = params.Name
+		s.tags[i].Description = params.Description
+		s.refreshSourceTags(s.tagSourceIDs(params.ID))
+		return s.tags[i], nil
+	}
+	return onefeed_th_sqlc.Tag{}, pgx.ErrNoRows
+}
+
+func (s *Store) DeleteTag(ctx context.Context, id int32) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	before := len(s.tags)
+	s.tags = filter(s.tags, func(tag onefeed_th_sqlc.Tag) bool { return tag.ID != id })
+	if len(s.tags) == before {
+		return 0, nil
+	}
+	sourceIDs := s.tagSourceIDs(id)
+	s.sourceTags = filter(s.sourceTags, func(link onefeed_th_sqlc.SourceTag) bool { return link.TagID != id })
+	s.refreshSourceTags(sourceIDs)
+	return 1, nil
+}
+
+func (s *Store) tagNameTaken(name string, exceptID int32) bool {
+	return slices.ContainsFunc(s.tags, func(tag onefeed_th_sqlc.Tag) bool {
+		return tag.ID != exceptID && strings.EqualFold(tag.Name, name)
+	})
+}
+
+func (s *Store) tagSourceIDs(id int32) []int64 {
+	var ids []int64
+	for _, link := range s.sourceTags {
+		if link.TagID == id {
+			ids = append(ids, link.SourceID)
+		}
+	}
+	return ids
+}
+
+// Source suggestions
+
+func (s *Store) ApproveSourceSuggestion(ctx context.Context, source onefeed_th_sqlc.CreateSourceParams, review onefeed_th_sqlc.ReviewSourceSuggestionParams) (onefeed_th_sqlc.SourceSuggestion, onefeed_th_sqlc.Source, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.pendingSuggestion(review.ID)
+	if i < 0 {
+		return onefeed_th_sqlc.SourceSuggestion{}, onefeed_th_sqlc.Source{}, pgx.ErrNoRows
+	}
+
+	s.nextSourceID++
+	s.sources = append(s.sources, onefeed_th_sqlc.Source{
+		ID:        s.nextSourceID,
+		Name:      source.Name,
+		Tags:      source.Tags,
+		RssUrl:    source.RssUrl,
+		CreatedAt: converter.TimeToPGTypeTimestamp(time.Now()),
+		Enabled:   true,
+	})
+	created := s.syncSourceTags(s.nextSourceID, source.Tags)
+
+	review.SourceID = pgtype.Int8{Int64: created.ID, Valid: true}
+	return s.reviewSuggestion(i, review), created, nil
+}
+
+func (s *Store) CountPendingSourceSuggestions(ctx context.Context) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return int64(len(s.pendingSuggestions())), nil
+}
+
+func (s *Store) CreateSourceSuggestion(ctx context.Context, params onefeed_th_sqlc.CreateSourceSuggestionParams) (onefeed_th_sqlc.SourceSuggestion, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if slices.ContainsFunc(s.pendingSuggestions(), func(suggestion onefeed_th_sqlc.SourceSuggestion) bool {
+		return suggestion.RssUrl == params.RssUrl
+	}) {
+		return onefeed_th_sqlc.SourceSuggestion{}, &pgconn.PgError{Code: "23505", Message: "duplicate key value violates unique constraint"}
+	}
+	s.nextSuggID++
+	suggestion := onefeed_th_sqlc.SourceSuggestion{
+		ID:        s.nextSuggID,
+		UserID:    params.UserID,
+		Name:      params.Name,
+		Tags:      params.Tags,
+		RssUrl:    params.RssUrl,
+		Note:      params.Note,
+		PushToken: params.PushToken,
+		Status:    "pending",
+		CreatedAt: converter.TimeToPGTypeTimestamp(time.Now()),
+	}
+	s.suggestions = append(s.suggestions, suggestion)
+	return suggestion, nil
+}
+
+func (s *Store) GetPendingSourceSuggestions(ctx context.Context, params onefeed_th_sqlc.ListPendingSourceSuggestionsParams) ([]onefeed_th_sqlc.SourceSuggestion, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return paginate(s.pendingSuggestions(), params.PageOffset, params.PageLimit), nil
+}
+
+func (s *Store) GetSourceSuggestionByID(ctx context.Context, id int64) (onefeed_th_sqlc.SourceSuggestion, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	suggestion, ok := findByID(s.suggestions, id, func(suggestion onefeed_th_sqlc.SourceSuggestion) int64 { return suggestion.ID })
+	if !ok {
+		return onefeed_th_sqlc.SourceSuggestion{}, pgx.ErrNoRows
+	}
+	return suggestion, nil
+}
+
+func (s *Store) GetUserSourceSuggestions(ctx context.Context, params onefeed_th_sqlc.ListUserSourceSuggestionsParams) ([]onefeed_th_sqlc.SourceSuggestion, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	suggestions := filter(s.suggestions, func(suggestion onefeed_th_sqlc.SourceSuggestion) bool {
+		return suggestion.UserID == params.UserID
+	})
+	slices.Reverse(suggestions)
+	return paginate(suggestions, 0, params.PageLimit), nil
+}
+
+func (s *Store) ReviewSourceSuggestion(ctx context.Context, params onefeed_th_sqlc.ReviewSourceSuggestionParams) (onefeed_th_sqlc.SourceSuggestion, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.pendingSuggestion(params.ID)
+	if i < 0 {
+		return onefeed_th_sqlc.SourceSuggestion{}, pgx.ErrNoRows
+	}
+	return s.reviewSuggestion(i, params), nil
+}
+
+// pendingSuggestions returns the suggestions waiting for review, oldest first
+func (s *Store) pendingSuggestions() []onefeed_th_sqlc.SourceSuggestion {
+	return filter(s.suggestions, func(suggestion onefeed_th_sqlc.SourceSuggestion) bool {
+		return suggestion.Status == "pending"
+	})
+}
+
+// pendingSuggestion returns the index of the suggestion when it is still pending, or -1
+func (s *Store) pendingSuggestion(id int64) int {
+	return slices.IndexFunc(s.suggestions, func(suggestion onefeed_th_sqlc.SourceSuggestion) bool {
+		return suggestion.ID == id && suggestion.Status == "pending"
+	})
+}
+
+func (s *Store) reviewSuggestion(i int, params onefeed_th_sqlc.ReviewSourceSuggestionParams) onefeed_th_sqlc.SourceSuggestion {
+	s.suggestions[i].Status = params.Status
+	s.suggestions[i].ReviewReason = params.ReviewReason
+	s.suggestions[i].ReviewedBy = params.ReviewedBy
+	s.suggestions[i].ReviewedAt = converter.TimeToPGTypeTimestamp(time.Now())
+	s.suggestions[i].SourceID = params.SourceID
+	return s.suggestions[i]
+}
+
+// News
+
+func (s *Store) BulkInsertNews(ctx context.Context, params []repository.InsertNewsParams) error {
+	return s.insertNews(params, false)
+}
+
+func (s *Store) BulkUpsertNews(ctx context.Context, params []repository.InsertNewsParams) error {
+	return s.insertNews(params, true)
+}
+
+func (s *Store) insertNews(params []repository.InsertNewsParams, updateExisting bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for _, item := range params {
+		publishDate := now
+		if item.PublishDate != nil {
+			publishDate = *item.PublishDate
+		}
+
+		// Same uniqueness as news_links: one news per link
+		existing := -1
+		for i, n := range s.news {
+			if n.Link == item.Link {
+				existing = i
+				break
+			}
+		}
+		if existing >= 0 {
+			if !updateExisting {
+				continue
+			}
+			n := &s.news[existing]
+			imageChanged := item.ImageUrl != "" && n.ImageUrl.String != item.ImageUrl
+			if n.Title != item.Title || imageChanged {
+				n.Title = item.Title
+				if item.ImageUrl != "" {
+					n.ImageUrl = pgtype.Text{String: item.ImageUrl, Valid: true}
+				}
+				n.UpdatedAt = converter.TimeToPGTypeTimestamp(now)
+			}
+			continue
+		}
+
+		s.nextNewsID++
+		s.news = append(s.news, onefeed_th_sqlc.News{
+			ID:          s.nextNewsID,
+			Title:       item.Title,
+			Link:        item.Link,
+			Source:      item.Source,
+			ImageUrl:    pgtype.Text{String: item.ImageUrl, Valid: true},
+			PublishDate: converter.TimeToPGTypeTimestamp(publishDate),
+			FetchedAt:   converter.TimeToPGTypeTimestamp(now),
+			Summary:     converter.StringToPGTypeTextNull(item.Summary),
+		})
+	}
+	return nil
+}
+
+func (s *Store) GetNews(ctx context.Context, params onefeed_th_sqlc.ListNewsParams, newsSort repository.NewsSort) ([]onefeed_th_sqlc.News, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	news := s.filterNews(func(n onefeed_th_sqlc.News) bool {
+		return !n.Hidden && contains(params.Sources, n.Source)
+	})
+
+	switch newsSort {
+	case repository.NewsSortPublishedAtAsc:
+		sort.SliceStable(news, func(i, j int) bool {
+			return news[i].PublishDate.Time.Before(news[j].PublishDate.Time)
+		})
+	case repository.NewsSortFetchedAtDesc:
+		sort.SliceStable(news, func(i, j int) bool {
+			return news[i].FetchedAt.Time.After(news[j].FetchedAt.Time)
+		})
+	case repository.NewsSortSourceAsc:
+		sort.SliceStable(news, func(i, j int) bool {
+			if news[i].Source != news[j].Source {
+				return news[i].Source < news[j].Source
+			}
+			return news[i].PublishDate.Time.After(news[j].PublishDate.Time)
+		})
+	default:
+		sortByPublishDateAndIDDesc(news)
+	}
+	return paginate(news, params.PageOffset, params.PageLimit), nil
+}
+
+func (s *Store) GetNewsBeforeCursor(ctx context.Context, params onefeed_th_sqlc.ListNewsBeforeCursorParams) ([]onefeed_th_sqlc.News, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	news := s.filterNews(func(n onefeed_th_sqlc.News) bool {
+		if n.Hidden || !contains(params.Sources, n.Source) {
+			return false
+		}
+		// (publish_date, id) < cursor
+		return n.PublishDate.Time.Before(params.PublishDate.Time) ||
+			(n.PublishDate.Time.Equal(params.PublishDate.Time) && n.ID < params.ID)
+	})
+	sortByPublishDateAndIDDesc(news)
+	return paginate(news, 0, params.PageLimit), nil
+}
+
+// EnsureNewsPartitions has nothing to do, the store isn't partitioned
+func (s *Store) EnsureNewsPartitions(ctx context.Context, through time.Time) error {
+	return nil
+}
+
+func (s *Store) RemoveNewsByPublishedDate(ctx context.Context, before pgtype.Timestamp) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.removeNewsBefore(before.Time), nil
+}
+
+func (s *Store) removeNewsBefore(before time.Time) int64 {
+	kept := s.news[:0]
+	var removed int64
+	for _, n := range s.news {
+		if n.PublishDate.Time.Before(before) {
+			removed++
+			continue
+		}
+		kept = append(kept, n)
+	}
+	s.news = kept
+	return removed
+}
+
+func (s *Store) GetAllSource(ctx context.Context) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	seen := make(map[string]struct{})
+	sources := make([]string, 0)
+	for _, n := range s.news {
+		if _, ok := seen[n.Source]; ok {
+			continue
+		}
+		seen[n.Source] = struct{}{}
+		sources = append(sources, n.Source)
+	}
+	return sources, nil
+}
+
+func (s *Store) GetAllMissingLinks(ctx context.Context, links []string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stored := make(map[string]struct{}, len(s.news))
+	for _, n := range s.news {
+		stored[n.Link] = struct{}{}
+	}
+	missing := make([]string, 0, len(links))
+	for _, link := range links {
+		if _, ok := stored[link]; !ok {
+			missing = append(missing, link)
+		}
+	}
+	return missing, nil
+}
+
+func (s *Store) GetNewsByID(ctx context.Context, id int64) (onefeed_th_sqlc.News, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, n := range s.news {
+		if n.ID == id {
+			return n, nil
+		}
+	}
+	return onefeed_th_sqlc.News{}, pgx.ErrNoRows
+}
+
+func (s *Store) GetRelatedNewsBySource(ctx context.Context, params onefeed_th_sqlc.ListRelatedNewsBySourceParams) ([]onefeed_th_sqlc.News, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	news := s.filterNews(func(n onefeed_th_sqlc.News) bool {
+		return !n.Hidden && n.Source == params.Source && n.ID != params.ID
+	})
+	sortByPublishDateDesc(news)
+	return paginate(news, 0, params.PageLimit), nil
+}
+
+func (s *Store) GetNewsByIDs(ctx context.Context, ids []int64) ([]onefeed_th_sqlc.News, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	news := s.filterNews(func(n onefeed_th_sqlc.News) bool {
+		return !n.Hidden && contains(ids, n.ID)
+	})
+	sortByPublishDateDesc(news)
+	return news, nil
+}
+
+func (s *Store) GetNewsByLinks(ctx context.Context, links []string) ([]onefeed_th_sqlc.News, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	news := s.filterNews(func(n onefeed_th_sqlc.News) bool {
+		return !n.Hidden && contains(links, n.Link)
+	})
+	sortByPublishDateDesc(news)
+	return news, nil
+}
+
+func (s *Store) GetSimilarNews(ctx context.Context, params onefeed_th_sqlc.ListSimilarNewsParams) ([]onefeed_th_sqlc.News, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	scores := make(map[int64]float32)
+	news := s.filterNews(func(n onefeed_th_sqlc.News) bool {
+		if n.Hidden || n.ID == params.ID {
+			return false
+		}
+		if n.PublishDate.Time.Before(params.WindowStart.Time) || n.PublishDate.Time.After(params.WindowEnd.Time) {
+			return false
+		}
+		score := trigramSimilarity(n.Title, params.Title)
+		scores[n.ID] = score
+		return score >= params.MinSimilarity
+	})
+	sort.SliceStable(news, func(i, j int) bool {
+		if scores[news[i].ID] != scores[news[j].ID] {
+			return scores[news[i].ID] > scores[news[j].ID]
+		}
+		return news[i].PublishDate.Time.After(news[j].PublishDate.Time)
+	})
+	return paginate(news, 0, params.PageLimit), nil
+}
+
+func (s *Store) GetLatestNewsPerSource(ctx context.Context, params onefeed_th_sqlc.ListLatestNewsPerSourceParams) ([]onefeed_th_sqlc.News, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	news := s.filterNews(func(n onefeed_th_sqlc.News) bool {
+		return !n.Hidden && contains(params.Sources, n.Source)
+	})
+	sort.SliceStable(news, func(i, j int) bool {
+		if news[i].Source != news[j].Source {
+			return news[i].Source < news[j].Source
+		}
+		return news[i].PublishDate.Time.After(news[j].PublishDate.Time)
+	})
+
+	perSource := make(map[string]int32)
+	latest := make([]onefeed_th_sqlc.News, 0, len(news))
+	for _, n := range news {
+		if perSource[n.Source] >= params.PerSource {
+			continue
+		}
+		perSource[n.Source]++
+		latest = append(latest, n)
+	}
+	return latest, nil
+}
+
+func (s *Store) CountNews(ctx context.Context, sources []string) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	news := s.filterNews(func(n onefeed_th_sqlc.News) bool {
+		return !n.Hidden && contains(sources, n.Source)
+	})
+	return int64(len(news)), nil
+}
+
+func (s *Store) SetNewsSummary(ctx context.Context, params onefeed_th_sqlc.SetNewsSummaryParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.news {
+		if s.news[i].ID == params.ID {
+			s.news[i].Summary = params.Summary
+		}
+	}
+	return nil
+}
+
+func (s *Store) GetSourceNewsCounts(ctx context.Context, fetchedSince pgtype.Timestamp) ([]onefeed_th_sqlc.ListSourceNewsCountsRow, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	counts := make(map[string]*onefeed_th_sqlc.ListSourceNewsCountsRow)
+	rows := make([]onefeed_th_sqlc.ListSourceNewsCountsRow, 0)
+	for _, n := range s.filterNews(func(n onefeed_th_sqlc.News) bool { return !n.Hidden }) {
+		row, ok := counts[n.Source]
+		if !ok {
+			row = &onefeed_th_sqlc.ListSourceNewsCountsRow{Source: n.Source}
+			counts[n.Source] = row
+		}
+		row.Total++
+		if !n.FetchedAt.Time.Before(fetchedSince.Time) {
+			row.Recent++
+		}
+	}
+	for _, row := range counts {
+		rows = append(rows, *row)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Total != rows[j].Total {
+			return rows[i].Total > rows[j].Total
+		}
+		return rows[i].Source < rows[j].Source
+	})
+	return rows, nil
+}
+
+func (s *Store) SearchNews(ctx context.Context, params onefeed_th_sqlc.SearchNewsParams) ([]onefeed_th_sqlc.News, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	news := s.searchNews(params.Pattern, params.Sources)
+	sortByPublishDateDesc(news)
+	return paginate(news, params.PageOffset, params.PageLimit), nil
+}
+
+func (s *Store) CountSearchNews(ctx context.Context, params onefeed_th_sqlc.CountSearchNewsParams) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return int64(len(s.searchNews(params.Pattern, params.Sources))), nil
+}
+
+func (s *Store) GetNewsAfterID(ctx context.Context, params onefeed_th_sqlc.ListNewsAfterIDParams) ([]onefeed_th_sqlc.News, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	news := s.filterNews(func(n onefeed_th_sqlc.News) bool {
+		return !n.Hidden && n.ID > params.AfterID
+	})
+	sort.Slice(news, func(i, j int) bool {
+		return news[i].ID < news[j].ID
+	})
+	return paginate(news, 0, params.PageLimit), nil
+}
+
+func (s *Store) GetNewsForExport(ctx context.Context, params onefeed_th_sqlc.ListNewsForExportParams) ([]onefeed_th_sqlc.News, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	news := s.filterNews(func(n onefeed_th_sqlc.News) bool {
+		return n.ID > params.AfterID &&
+			!n.PublishDate.Time.Before(params.FromDate.Time) &&
+			n.PublishDate.Time.Before(params.ToDate.Time) &&
+			(len(params.Sources) == 0 || contains(params.Sources, n.Source))
+	})
+	sort.Slice(news, func(i, j int) bool {
+		return news[i].ID < news[j].ID
+	})
+	return paginate(news, 0, params.PageLimit), nil
+}
+
+func (s *Store) GetRandomRecentNews(ctx context.Context, params onefeed_th_sqlc.ListRandomRecentNewsParams) ([]onefeed_th_sqlc.News, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	news := s.filterNews(func(n onefeed_th_sqlc.News) bool {
+		return !n.Hidden &&
+			!n.PublishDate.Time.Before(params.Since.Time) &&
+			!contains(params.ExcludeSources, n.Source)
+	})
+	rand.Shuffle(len(news), func(i, j int) {
+		news[i], news[j] = news[j], news[i]
+	})
+	return paginate(news, 0, params.PageLimit), nil
+}
+
+// NotifyNewsCreated is a no-op because a memory store is never shared between instances;
+// the service publishes new news to its own subscribers directly in memory mode
+func (s *Store) NotifyNewsCreated(ctx context.Context, payload string) error {
+	return nil
+}
+
+// News clusters
+
+func (s *Store) CountClusteredNews(ctx context.Context, sources []string) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return int64(len(s.clusteredNews(sources))), nil
+}
+
+func (s *Store) CreateNewsClusters(ctx context.Context, params onefeed_th_sqlc.CreateNewsClustersParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := converter.TimeToPGTypeTimestamp(time.Now())
+	for i, newsID := range params.NewsIds {
+		if slices.ContainsFunc(s.clusters, func(cluster onefeed_th_sqlc.NewsCluster) bool { return cluster.NewsID == newsID }) {
+			continue
+		}
+		s.clusters = append(s.clusters, onefeed_th_sqlc.NewsCluster{
+			NewsID:      newsID,
+			ClusterID:   params.ClusterIds[i],
+			PublishDate: params.PublishDates[i],
+			CreatedAt:   now,
+		})
+	}
+	return nil
+}
+
+func (s *Store) GetClusteredNews(ctx context.Context, params onefeed_th_sqlc.ListClusteredNewsParams) ([]onefeed_th_sqlc.ListClusteredNewsRow, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return paginate(s.clusteredNews(params.Sources), params.PageOffset, params.PageLimit), nil
+}
+
+func (s *Store) GetNewsClusters(ctx context.Context, newsIDs []int64) ([]onefeed_th_sqlc.NewsCluster, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return filter(s.clusters, func(cluster onefeed_th_sqlc.NewsCluster) bool {
+		return slices.Contains(newsIDs, cluster.NewsID)
+	}), nil
+}
+
+func (s *Store) RemoveNewsClustersBefore(ctx context.Context, before pgtype.Timestamp) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.clusters = slices.DeleteFunc(s.clusters, func(cluster onefeed_th_sqlc.NewsCluster) bool {
+		return cluster.PublishDate.Time.Before(before.Time)
+	})
+	return nil
+}
+
+// clusteredNews mirrors ListClusteredNews: the first visible news of every story among the
+// sources, newest first, with the number of other sources that covered it
+func (s *Store) clusteredNews(sources []string) []onefeed_th_sqlc.ListClusteredNewsRow {
+	clusterIDs := make(map[int64]int64, len(s.clusters))
+	for _, cluster := range s.clusters {
+		clusterIDs[cluster.NewsID] = cluster.ClusterID
+	}
+
+	news := s.filterNews(func(n onefeed_th_sqlc.News) bool {
+		return !n.Hidden && contains(sources, n.Source)
+	})
+	first := make(map[int64]onefeed_th_sqlc.News)
+	coverage := make(map[int64]map[string]struct{})
+	for _, n := range news {
+		clusterID, ok := clusterIDs[n.ID]
+		if !ok {
+			clusterID = n.ID
+		}
+		if current, ok := first[clusterID]; !ok || n.PublishDate.Time.Before(current.PublishDate.Time) ||
+			(n.PublishDate.Time.Equal(current.PublishDate.Time) && n.ID < current.ID) {
+			first[clusterID] = n
+		}
+		if coverage[clusterID] == nil {
+			coverage[clusterID] = make(map[string]struct{})
+		}
+		coverage[clusterID][n.Source] = struct{}{}
+	}
+
+	rows := make([]onefeed_th_sqlc.ListClusteredNewsRow, 0, len(first))
+	for clusterID, n := range first {
+		rows = append(rows, onefeed_th_sqlc.ListClusteredNewsRow{
+			ID:            n.ID,
+			Title:         n.Title,
+			Link:          n.Link,
+			Source:        n.Source,
+			ImageUrl:      n.ImageUrl,
+			PublishDate:   n.PublishDate,
+			FetchedAt:     n.FetchedAt,
+			Summary:       n.Summary,
+			Hidden:        n.Hidden,
+			UpdatedAt:     n.UpdatedAt,
+			AlsoCoveredBy: int64(len(coverage[clusterID]) - 1),
+		})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if !rows[i].PublishDate.Time.Equal(rows[j].PublishDate.Time) {
+			return rows[i].PublishDate.Time.After(rows[j].PublishDate.Time)
+		}
+		return rows[i].ID > rows[j].ID
+	})
+	return rows
+}
+
+// Clicks
+
+func (s *Store) UpsertNewsClicks(ctx context.Context, params onefeed_th_sqlc.UpsertNewsClicksParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, id := range params.NewsIds {
+		if !s.hasNews(id) || i >= len(params.Clicks) {
+			continue
+		}
+		s.clicks[clickKey{newsID: id, bucketStart: params.BucketStart.Time}] = params.Clicks[i]
+	}
+	return nil
+}
+
+func (s *Store) GetTrendingNews(ctx context.Context, params onefeed_th_sqlc.ListTrendingNewsParams) ([]onefeed_th_sqlc.ListTrendingNewsRow, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	totals := make(map[int64]int64)
+	for key, clicks := range s.clicks {
+		if key.bucketStart.Before(params.Since.Time) {
+			continue
+		}
+		totals[key.newsID] += clicks
+	}
+
+	rows := make([]onefeed_th_sqlc.ListTrendingNewsRow, 0, len(totals))
+	for _, n := range s.news {
+		clicks, ok := totals[n.ID]
+		if !ok || n.Hidden {
+			continue
+		}
+		rows = append(rows, onefeed_th_sqlc.ListTrendingNewsRow{News: n, Clicks: clicks})
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].Clicks != rows[j].Clicks {
+			return rows[i].Clicks > rows[j].Clicks
+		}
+		return rows[i].News.PublishDate.Time.After(rows[j].News.PublishDate.Time)
+	})
+	return paginate(rows, 0, params.PageLimit), nil
+}
+
+func (s *Store) RemoveNewsClicksBefore(ctx context.Context, before pgtype.Timestamp) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key := range s.clicks {
+		if key.bucketStart.Before(before.Time) {
+			delete(s.clicks, key)
+		}
+	}
+	return nil
+}
+
+// Archive
+
+func (s *Store) ArchiveNewsPublishedBefore(ctx context.Context, before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Same month granularity as the partitioned table
+	cutoff := time.Date(before.Year(), before.Month(), 1, 0, 0, 0, 0, before.Location())
+	now := converter.TimeToPGTypeTimestamp(time.Now())
+
+	var archived int64
+	for _, n := range s.news {
+		if !n.PublishDate.Time.Before(cutoff) {
+			continue
+		}
+		s.archive = append(s.archive, onefeed_th_sqlc.NewsArchive{
+			ID:          n.ID,
+			Title:       n.Title,
+			Link:        n.Link,
+			Source:      n.Source,
+			ImageUrl:    n.ImageUrl,
+			PublishDate: n.PublishDate,
+			FetchedAt:   n.FetchedAt,
+			Summary:     n.Summary,
+			ArchivedAt:  now,
+			Hidden:      n.Hidden,
+		})
+		archived++
+	}
+	s.removeNewsBefore(cutoff)
+	return archived, nil
+}
+
+func (s *Store) GetArchivedNews(ctx context.Context, params onefeed_th_sqlc.ListArchivedNewsParams) ([]onefeed_th_sqlc.NewsArchive, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	archived := make([]onefeed_th_sqlc.NewsArchive, 0)
+	for _, n := range s.archive {
+		if n.Hidden || !contains(params.Sources, n.Source) {
+			continue
+		}
+		if n.PublishDate.Time.Before(params.FromDate.Time) || !n.PublishDate.Time.Before(params.ToDate.Time) {
+			continue
+		}
+		archived = append(archived, n)
+	}
+	sort.SliceStable(archived, func(i, j int) bool {
+		return archived[i].PublishDate.Time.After(archived[j].PublishDate.Time)
+	})
+	return paginate(archived, params.PageOffset, params.PageLimit), nil
+}
+
+func (s *Store) RemoveArchivedNewsBefore(ctx context.Context, before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cutoff := time.Date(before.Year(), before.Month(), 1, 0, 0, 0, 0, before.Location())
+	removed := make(map[int64]bool)
+	s.archive = slices.DeleteFunc(s.archive, func(n onefeed_th_sqlc.NewsArchive) bool {
+		if n.PublishDate.Time.Before(cutoff) {
+			removed[n.ID] = true
+			return true
+		}
+		return false
+	})
+	s.bookmarks = slices.DeleteFunc(s.bookmarks, func(b onefeed_th_sqlc.Bookmark) bool {
+		return removed[b.NewsID]
+	})
+	s.reads = slices.DeleteFunc(s.reads, func(r onefeed_th_sqlc.NewsRead) bool {
+		return removed[r.NewsID]
+	})
+	return int64(len(removed)), nil
+}
+
+// Moderation
+
+func (s *Store) SetNewsHidden(ctx context.Context, params onefeed_th_sqlc.SetNewsHiddenParams, log onefeed_th_sqlc.CreateNewsModerationLogParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var affected int64
+	for i := range s.news {
+		if s.news[i].ID == params.ID {
+			s.news[i].Hidden = params.Hidden
+			affected++
+		}
+	}
+	if affected == 0 {
+		return 0, nil
+	}
+
+	s.nextLogID++
+	s.moderation = append(s.moderation, onefeed_th_sqlc.NewsModerationLog{
+		ID:        s.nextLogID,
+		NewsID:    params.ID,
+		Action:    log.Action,
+		Reason:    log.Reason,
+		Actor:     log.Actor,
+		CreatedAt: converter.TimeToPGTypeTimestamp(time.Now()),
+	})
+	return affected, nil
+}
+
+func (s *Store) GetNewsModerationLogs(ctx context.Context, newsID int64) ([]onefeed_th_sqlc.NewsModerationLog, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	logs := make([]onefeed_th_sqlc.NewsModerationLog, 0)
+	for i := len(s.moderation) - 1; i >= 0; i-- {
+		if s.moderation[i].NewsID == newsID {
+			logs = append(logs, s.moderation[i])
+		}
+	}
+	return logs, nil
+}
+
+// API keys
+
+func (s *Store) CreateAPIKey(ctx context.Context, params onefeed_th_sqlc.CreateApiKeyParams) (onefeed_th_sqlc.ApiKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range s.apiKeys {
+		if key.KeyHash == params.KeyHash {
+			return onefeed_th_sqlc.ApiKey{}, &pgconn.PgError{Code: "23505", Message: "duplicate key value violates unique constraint"}
+		}
+	}
+	s.nextAPIKeyID++
+	key := onefeed_th_sqlc.ApiKey{
+		ID:        s.nextAPIKeyID,
+		Name:      params.Name,
+		KeyPrefix: params.KeyPrefix,
+		KeyHash:   params.KeyHash,
+		Role:      params.Role,
+		CreatedAt: converter.TimeToPGTypeTimestamp(time.Now()),
+	}
+	s.apiKeys = append(s.apiKeys, key)
+	return key, nil
+}
+
+func (s *Store) GetActiveAPIKeyByHash(ctx context.Context, keyHash string) (onefeed_th_sqlc.ApiKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, key := range s.apiKeys {
+		if key.KeyHash == keyHash && !key.RevokedAt.Valid {
+			return key, nil
+		}
+	}
+	return onefeed_th_sqlc.ApiKey{}, pgx.ErrNoRows
+}
+
+func (s *Store) GetAPIKeys(ctx context.Context) ([]onefeed_th_sqlc.ApiKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]onefeed_th_sqlc.ApiKey, 0, len(s.apiKeys))
+	for i := len(s.apiKeys) - 1; i >= 0; i-- {
+		keys = append(keys, s.apiKeys[i])
+	}
+	return keys, nil
+}
+
+func (s *Store) RevokeAPIKey(ctx context.Context, id int64) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.apiKeys {
+		if s.apiKeys[i].ID == id && !s.apiKeys[i].RevokedAt.Valid {
+			s.apiKeys[i].RevokedAt = converter.TimeToPGTypeTimestamp(time.Now())
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+// Backoffice users
+
+func (s *Store) CreateBackofficeUser(ctx context.Context, params onefeed_th_sqlc.CreateBackofficeUserParams) (onefeed_th_sqlc.BackofficeUser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, user := range s.users {
+		if user.Username == params.Username {
+			return onefeed_th_sqlc.BackofficeUser{}, &pgconn.PgError{Code: "23505", Message: "duplicate key value violates unique constraint"}
+		}
+	}
+
+	s.nextUserID++
+	user := onefeed_th_sqlc.BackofficeUser{
+		ID:           s.nextUserID,
+		Username:     params.Username,
+		PasswordHash: params.PasswordHash,
+		Role:         params.Role,
+		CreatedAt:    converter.TimeToPGTypeTimestamp(time.Now()),
+	}
+	s.users = append(s.users, user)
+	return user, nil
+}
+
+func (s *Store) GetBackofficeUserByID(ctx context.Context, id int64) (onefeed_th_sqlc.BackofficeUser, error) {
+	return s.findBackofficeUser(func(user onefeed_th_sqlc.BackofficeUser) bool {
+		return user.ID == id
+	})
+}
+
+func (s *Store) GetBackofficeUserByUsername(ctx context.Context, username string) (onefeed_th_sqlc.BackofficeUser, error) {
+	return s.findBackofficeUser(func(user onefeed_th_sqlc.BackofficeUser) bool {
+		return user.Username == username
+	})
+}
+
+func (s *Store) GetBackofficeUsers(ctx context.Context) ([]onefeed_th_sqlc.BackofficeUser, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	users := append([]onefeed_th_sqlc.BackofficeUser(nil), s.users...)
+	sort.SliceStable(users, func(i, j int) bool {
+		return users[i].Username < users[j].Username
+	})
+	return users, nil
+}
+
+func (s *Store) UpdateBackofficeUser(ctx context.Context, params onefeed_th_sqlc.UpdateBackofficeUserParams) (onefeed_th_sqlc.BackofficeUser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.users {
+		if s.users[i].ID == params.ID {
+			s.users[i].Role = params.Role
+			s.users[i].Disabled = params.Disabled
+			s.users[i].UpdatedAt = converter.TimeToPGTypeTimestamp(time.Now())
+			return s.users[i], nil
+		}
+	}
+	return onefeed_th_sqlc.BackofficeUser{}, pgx.ErrNoRows
+}
+
+func (s *Store) findBackofficeUser(match func(user onefeed_th_sqlc.BackofficeUser) bool) (onefeed_th_sqlc.BackofficeUser, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, user := range s.users {
+		if match(user) {
+			return user, nil
+		}
+	}
+	return onefeed_th_sqlc.BackofficeUser{}, pgx.ErrNoRows
+}
+
+// Webhooks
+
+func (s *Store) CreateWebhook(ctx context.Context, params onefeed_th_sqlc.CreateWebhookParams) (onefeed_th_sqlc.Webhook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextHookID++
+	hook := onefeed_th_sqlc.Webhook{
+		ID:        s.nextHookID,
+		Url:       params.Url,
+		Secret:    params.Secret,
+		Sources:   params.Sources,
+		Tags:      params.Tags,
+		CreatedAt: converter.TimeToPGTypeTimestamp(time.Now()),
+	}
+	s.webhooks = append(s.webhooks, hook)
+	return hook, nil
+}
+
+func (s *Store) CreateWebhookFailure(ctx context.Context, params onefeed_th_sqlc.CreateWebhookFailureParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextFailID++
+	s.failures = append(s.failures, onefeed_th_sqlc.WebhookFailure{
+		ID:         s.nextFailID,
+		WebhookID:  params.WebhookID,
+		Payload:    params.Payload,
+		Attempts:   params.Attempts,
+		StatusCode: params.StatusCode,
+		Error:      params.Error,
+		CreatedAt:  converter.TimeToPGTypeTimestamp(time.Now()),
+	})
+	return nil
+}
+
+func (s *Store) DeleteWebhook(ctx context.Context, id int64) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.webhooks {
+		if s.webhooks[i].ID == id {
+			s.webhooks = append(s.webhooks[:i], s.webhooks[i+1:]...)
+			// ON DELETE CASCADE
+			failures := s.failures[:0]
+			for _, failure := range s.failures {
+				if failure.WebhookID != id {
+					failures = append(failures, failure)
+				}
+			}
+			s.failures = failures
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func (s *Store) GetEnabledWebhooks(ctx context.Context) ([]onefeed_th_sqlc.Webhook, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	hooks := make([]onefeed_th_sqlc.Webhook, 0, len(s.webhooks))
+	for _, hook := range s.webhooks {
+		if !hook.Disabled {
+			hooks = append(hooks, hook)
+		}
+	}
+	return hooks, nil
+}
+
+func (s *Store) GetWebhookByID(ctx context.Context, id int64) (onefeed_th_sqlc.Webhook, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, hook := range s.webhooks {
+		if hook.ID == id {
+			return hook, nil
+		}
+	}
+	return onefeed_th_sqlc.Webhook{}, pgx.ErrNoRows
+}
+
+func (s *Store) GetWebhookFailures(ctx context.Context, params onefeed_th_sqlc.ListWebhookFailuresParams) ([]onefeed_th_sqlc.WebhookFailure, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	failures := make([]onefeed_th_sqlc.WebhookFailure, 0)
+	for i := len(s.failures) - 1; i >= 0 && len(failures) < int(params.PageLimit); i-- {
+		if s.failures[i].WebhookID == params.WebhookID {
+			failures = append(failures, s.failures[i])
+		}
+	}
+	return failures, nil
+}
+
+func (s *Store) GetWebhooks(ctx context.Context) ([]onefeed_th_sqlc.Webhook, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]onefeed_th_sqlc.Webhook(nil), s.webhooks...), nil
+}
+
+func (s *Store) UpdateWebhook(ctx context.Context, params onefeed_th_sqlc.UpdateWebhookParams) (onefeed_th_sqlc.Webhook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.webhooks {
+		if s.webhooks[i].ID == params.ID {
+			s.webhooks[i].Url = params.Url
+			s.webhooks[i].Sources = params.Sources
+			s.webhooks[i].Tags = params.Tags
+			s.webhooks[i].Disabled = params.Disabled
+			s.webhooks[i].UpdatedAt = converter.TimeToPGTypeTimestamp(time.Now())
+			return s.webhooks[i], nil
+		}
+	}
+	return onefeed_th_sqlc.Webhook{}, pgx.ErrNoRows
+}
+
+// Notification rules
+
+func (s *Store) CreateNotificationRule(ctx context.Context, params onefeed_th_sqlc.CreateNotificationRuleParams) (onefeed_th_sqlc.NotificationRule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextRuleID++
+	rule := onefeed_th_sqlc.NotificationRule{
+		ID:        s.nextRuleID,
+		Name:      params.Name,
+		Channel:   params.Channel,
+		Target:    params.Target,
+		Keywords:  params.Keywords,
+		Sources:   params.Sources,
+		Tags:      params.Tags,
+		Template:  params.Template,
+		CreatedAt: converter.TimeToPGTypeTimestamp(time.Now()),
+	}
+	s.rules = append(s.rules, rule)
+	return rule, nil
+}
+
+func (s *Store) DeleteNotificationRule(ctx context.Context, id int64) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.rules {
+		if s.rules[i].ID == id {
+			s.rules = append(s.rules[:i], s.rules[i+1:]...)
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func (s *Store) GetEnabledNotificationRules(ctx context.Context) ([]onefeed_th_sqlc.NotificationRule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rules := make([]onefeed_th_sqlc.NotificationRule, 0, len(s.rules))
+	for _, rule := range s.rules {
+		if !rule.Disabled {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+func (s *Store) GetNotificationRuleByID(ctx context.Context, id int64) (onefeed_th_sqlc.NotificationRule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, rule := range s.rules {
+		if rule.ID == id {
+			return rule, nil
+		}
+	}
+	return onefeed_th_sqlc.NotificationRule{}, pgx.ErrNoRows
+}
+
+func (s *Store) GetNotificationRules(ctx context.Context) ([]onefeed_th_sqlc.NotificationRule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]onefeed_th_sqlc.NotificationRule(nil), s.rules...), nil
+}
+
+func (s *Store) UpdateNotificationRule(ctx context.Context, params onefeed_th_sqlc.UpdateNotificationRuleParams) (onefeed_th_sqlc.NotificationRule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.rules {
+		if s.rules[i].ID == params.ID {
+			s.rules[i].Name = params.Name
+			s.rules[i].Target = params.Target
+			s.rules[i].Keywords = params.Keywords
+			s.rules[i].Sources = params.Sources
+			s.rules[i].Tags = params.Tags
+			s.rules[i].Template = params.Template
+			s.rules[i].Disabled = params.Disabled
+			s.rules[i].UpdatedAt = converter.TimeToPGTypeTimestamp(time.Now())
+			return s.rules[i], nil
+		}
+	}
+	return onefeed_th_sqlc.NotificationRule{}, pgx.ErrNoRows
+}
+
+// Push devices and jobs
+
+func (s *Store) ClaimPushJobs(ctx context.Context, params onefeed_th_sqlc.ClaimPushJobsParams) ([]onefeed_th_sqlc.ClaimPushJobsRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rows := make([]onefeed_th_sqlc.ClaimPushJobsRow, 0)
+	for i := range s.pushJobs {
+		if len(rows) == int(params.PageLimit) {
+			break
+		}
+		job := &s.pushJobs[i]
+		if job.RunAt.Time.After(params.Now.Time) {
+			continue
+		}
+		job.Attempts++
+		job.RunAt = params.LeaseUntil
+
+		device, ok := findByID(s.devices, job.DeviceID, func(device onefeed_th_sqlc.PushDevice) int64 { return device.ID })
+		if !ok {
+			continue
+		}
+		news, ok := findByID(s.news, job.NewsID, func(news onefeed_th_sqlc.News) int64 { return news.ID })
+		if !ok {
+			continue
+		}
+		rows = append(rows, onefeed_th_sqlc.ClaimPushJobsRow{
+			ID:       job.ID,
+			DeviceID: job.DeviceID,
+			NewsID:   job.NewsID,
+			Attempts: job.Attempts,
+			Token:    device.Token,
+			Title:    news.Title,
+			Link:     news.Link,
+			Source:   news.Source,
+		})
+	}
+	return rows, nil
+}
+
+func (s *Store) CreatePushJobs(ctx context.Context, params onefeed_th_sqlc.CreatePushJobsParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := converter.TimeToPGTypeTimestamp(time.Now())
+	for i := range params.DeviceIds {
+		s.nextJobID++
+		s.pushJobs = append(s.pushJobs, onefeed_th_sqlc.PushJob{
+			ID:        s.nextJobID,
+			DeviceID:  params.DeviceIds[i],
+			NewsID:    params.NewsIds[i],
+			RunAt:     now,
+			CreatedAt: now,
+		})
+	}
+	return nil
+}
+
+func (s *Store) DeletePushDeviceByID(ctx context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deletePushDevice(func(device onefeed_th_sqlc.PushDevice) bool { return device.ID == id })
+	return nil
+}
+
+func (s *Store) DeletePushDeviceByToken(ctx context.Context, token string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.deletePushDevice(func(device onefeed_th_sqlc.PushDevice) bool { return device.Token == token }), nil
+}
+
+func (s *Store) DeletePushJob(ctx context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pushJobs = slices.DeleteFunc(s.pushJobs, func(job onefeed_th_sqlc.PushJob) bool { return job.ID == id })
+	return nil
+}
+
+func (s *Store) GetPushDevices(ctx context.Context) ([]onefeed_th_sqlc.PushDevice, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]onefeed_th_sqlc.PushDevice(nil), s.devices...), nil
+}
+
+func (s *Store) RetryPushJob(ctx context.Context, params onefeed_th_sqlc.RetryPushJobParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.pushJobs {
+		if s.pushJobs[i].ID == params.ID {
+			s.pushJobs[i].LastError = params.LastError
+			s.pushJobs[i].RunAt = params.RunAt
+		}
+	}
+	return nil
+}
+
+func (s *Store) UpsertPushDevice(ctx context.Context, params onefeed_th_sqlc.UpsertPushDeviceParams) (onefeed_th_sqlc.PushDevice, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := converter.TimeToPGTypeTimestamp(time.Now())
+	for i := range s.devices {
+		if s.devices[i].Token == params.Token {
+			s.devices[i].Platform = params.Platform
+			s.devices[i].Sources = params.Sources
+			s.devices[i].Keywords = params.Keywords
+			s.devices[i].UpdatedAt = now
+			return s.devices[i], nil
+		}
+	}
+
+	s.nextDeviceID++
+	device := onefeed_th_sqlc.PushDevice{
+		ID:        s.nextDeviceID,
+		Token:     params.Token,
+		Platform:  params.Platform,
+		Sources:   params.Sources,
+		Keywords:  params.Keywords,
+		CreatedAt: now,
+	}
+	s.devices = append(s.devices, device)
+	return device, nil
+}
+
+// Jobs
+
+func (s *Store) ClaimJobs(ctx context.Context, params onefeed_th_sqlc.ClaimJobsParams) ([]onefeed_th_sqlc.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	due := make([]*onefeed_th_sqlc.Job, 0)
+	for i := range s.jobs {
+		job := &s.jobs[i]
+		if (job.Status == "pending" || job.Status == "running") && !job.ScheduledAt.Time.After(params.Now.Time) {
+			due = append(due, job)
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool {
+		return due[i].ScheduledAt.Time.Before(due[j].ScheduledAt.Time)
+	})
+
+	claimed := make([]onefeed_th_sqlc.Job, 0)
+	for _, job := range due {
+		if len(claimed) == int(params.PageLimit) {
+			break
+		}
+		job.Status = "running"
+		job.Attempts++
+		job.StartedAt = params.Now
+		job.ScheduledAt = params.LeaseUntil
+		claimed = append(claimed, *job)
+	}
+	return claimed, nil
+}
+
+func (s *Store) CompleteJob(ctx context.Context, params onefeed_th_sqlc.CompleteJobParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.updateRunningJob(params.ID, params.Attempts, func(job *onefeed_th_sqlc.Job) {
+		job.Status = "succeeded"
+		job.Result = params.Result
+		job.FinishedAt = converter.TimeToPGTypeTimestamp(time.Now())
+	}), nil
+}
+
+func (s *Store) CreateJob(ctx context.Context, params onefeed_th_sqlc.CreateJobParams) (onefeed_th_sqlc.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if pending := s.pendingUniqueJob(params.Type, 0); params.IsUnique && pending != nil {
+		if pending.ScheduledAt.Time.After(params.ScheduledAt.Time) {
+			pending.ScheduledAt = params.ScheduledAt
+		}
+		return *pending, nil
+	}
+
+	s.nextQueueID++
+	job := onefeed_th_sqlc.Job{
+		ID:          s.nextQueueID,
+		Type:        params.Type,
+		Payload:     params.Payload,
+		Status:      "pending",
+		MaxAttempts: params.MaxAttempts,
+		ScheduledAt: params.ScheduledAt,
+		CreatedAt:   converter.TimeToPGTypeTimestamp(time.Now()),
+		IsUnique:    params.IsUnique,
+	}
+	s.jobs = append(s.jobs, job)
+	return job, nil
+}
+
+func (s *Store) DeleteJobsFinishedBefore(ctx context.Context, before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := len(s.jobs)
+	s.jobs = slices.DeleteFunc(s.jobs, func(job onefeed_th_sqlc.Job) bool {
+		return (job.Status == "succeeded" || job.Status == "failed") && job.FinishedAt.Time.Before(before)
+	})
+	return int64(count - len(s.jobs)), nil
+}
+
+func (s *Store) ExtendJobLease(ctx context.Context, params onefeed_th_sqlc.ExtendJobLeaseParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.updateRunningJob(params.ID, params.Attempts, func(job *onefeed_th_sqlc.Job) {
+		job.ScheduledAt = params.LeaseUntil
+	}), nil
+}
+
+func (s *Store) FailJob(ctx context.Context, params onefeed_th_sqlc.FailJobParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.updateRunningJob(params.ID, params.Attempts, func(job *onefeed_th_sqlc.Job) {
+		job.Status = "failed"
+		job.LastError = params.LastError
+		job.Result = params.Result
+		job.FinishedAt = converter.TimeToPGTypeTimestamp(time.Now())
+	}), nil
+}
+
+func (s *Store) GetJobByID(ctx context.Context, id int64) (onefeed_th_sqlc.Job, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	job, ok := findByID(s.jobs, id, func(job onefeed_th_sqlc.Job) int64 { return job.ID })
+	if !ok {
+		return onefeed_th_sqlc.Job{}, pgx.ErrNoRows
+	}
+	return job, nil
+}
+
+func (s *Store) GetJobs(ctx context.Context, params onefeed_th_sqlc.ListJobsParams) ([]onefeed_th_sqlc.Job, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	jobs := filter(s.jobs, func(job onefeed_th_sqlc.Job) bool {
+		return (params.Type == "" || job.Type == params.Type) &&
+			(params.Status == "" || job.Status == params.Status)
+	})
+	slices.Reverse(jobs)
+	return paginate(jobs, 0, params.PageLimit), nil
+}
+
+func (s *Store) RequeueJob(ctx context.Context, params onefeed_th_sqlc.RequeueJobParams) (onefeed_th_sqlc.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.jobs {
+		job := &s.jobs[i]
+		if job.ID != params.ID || job.Status != "failed" {
+			continue
+		}
+		if job.IsUnique && s.pendingUniqueJob(job.Type, job.ID) != nil {
+			return onefeed_th_sqlc.Job{}, &pgconn.PgError{Code: "23505", Message: "duplicate key value violates unique constraint"}
+		}
+		job.Status = "pending"
+		job.Attempts = 0
+		job.LastError = pgtype.Text{}
+		job.Result = nil
+		job.ScheduledAt = params.ScheduledAt
+		job.StartedAt = pgtype.Timestamp{}
+		job.FinishedAt = pgtype.Timestamp{}
+		return *job, nil
+	}
+	return onefeed_th_sqlc.Job{}, pgx.ErrNoRows
+}
+
+func (s *Store) RetryJob(ctx context.Context, params onefeed_th_sqlc.RetryJobParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, job := range s.jobs {
+		if job.ID == params.ID && job.Status == "running" && job.Attempts == params.Attempts &&
+			job.IsUnique && s.pendingUniqueJob(job.Type, job.ID) != nil {
+			return 0, &pgconn.PgError{Code: "23505", Message: "duplicate key value violates unique constraint"}
+		}
+	}
+	return s.updateRunningJob(params.ID, params.Attempts, func(job *onefeed_th_sqlc.Job) {
+		job.Status = "pending"
+		job.LastError = params.LastError
+		job.Result = params.Result
+		job.ScheduledAt = params.ScheduledAt
+	}), nil
+}
+
+// updateRunningJob applies update to the job with id while it is running the given attempt,
+// returning the rows affected; callers hold the write lock
+func (s *Store) updateRunningJob(id int64, attempts int32, update func(job *onefeed_th_sqlc.Job)) int64 {
+	for i := range s.jobs {
+		job := &s.jobs[i]
+		if job.ID == id && job.Status == "running" && job.Attempts == attempts {
+			update(job)
+			return 1
+		}
+	}
+	return 0
+}
+
+// pendingUniqueJob returns the pending job of a unique type other than except, which
+// idx_jobs_pending_unique_type allows one of; callers hold the lock
+func (s *Store) pendingUniqueJob(jobType string, except int64) *onefeed_th_sqlc.Job {
+	for i := range s.jobs {
+		job := &s.jobs[i]
+		if job.ID != except && job.Type == jobType && job.Status == "pending" && job.IsUnique {
+			return job
+		}
+	}
+	return nil
+}
+
+// Dead letters
+
+func (s *Store) DeleteDeadLetter(ctx context.Context, id int64) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := len(s.deadLetters)
+	s.deadLetters = slices.DeleteFunc(s.deadLetters, func(letter onefeed_th_sqlc.DeadLetter) bool {
+		return letter.ID == id
+	})
+	return int64(count - len(s.deadLetters)), nil
+}
+
+func (s *Store) GetDeadLetterByID(ctx context.Context, id int64) (onefeed_th_sqlc.DeadLetter, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	letter, ok := findByID(s.deadLetters, id, func(letter onefeed_th_sqlc.DeadLetter) int64 { return letter.ID })
+	if !ok {
+		return onefeed_th_sqlc.DeadLetter{}, pgx.ErrNoRows
+	}
+	return letter, nil
+}
+
+func (s *Store) GetDeadLetters(ctx context.Context, params onefeed_th_sqlc.ListDeadLettersParams) ([]onefeed_th_sqlc.DeadLetter, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	letters := filter(s.deadLetters, func(letter onefeed_th_sqlc.DeadLetter) bool {
+		return params.SourceID == 0 || letter.SourceID == params.SourceID
+	})
+	slices.Reverse(letters)
+	return paginate(letters, 0, params.PageLimit), nil
+}
+
+func (s *Store) UpdateDeadLetterError(ctx context.Context, params onefeed_th_sqlc.UpdateDeadLetterErrorParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.deadLetters {
+		if s.deadLetters[i].ID == params.ID {
+			s.deadLetters[i].Error = params.Error
+			s.deadLetters[i].LastSeenAt = converter.TimeToPGTypeTimestamp(time.Now())
+		}
+	}
+	return nil
+}
+
+func (s *Store) UpsertDeadLetter(ctx context.Context, params onefeed_th_sqlc.UpsertDeadLetterParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := converter.TimeToPGTypeTimestamp(time.Now())
+	for i := range s.deadLetters {
+		letter := &s.deadLetters[i]
+		if letter.SourceID != params.SourceID || letter.ItemKey != params.ItemKey {
+			continue
+		}
+		letter.Payload = params.Payload
+		letter.Error = params.Error
+		letter.Occurrences++
+		letter.LastSeenAt = now
+		return nil
+	}
+
+	s.nextLetterID++
+	s.deadLetters = append(s.deadLetters, onefeed_th_sqlc.DeadLetter{
+		ID:          s.nextLetterID,
+		SourceID:    params.SourceID,
+		ItemKey:     params.ItemKey,
+		Link:        params.Link,
+		Payload:     params.Payload,
+		Error:       params.Error,
+		Occurrences: 1,
+		CreatedAt:   now,
+		LastSeenAt:  now,
+	})
+	return nil
+}
+
+// Saved searches
+
+func (s *Store) ClearSavedSearchPushToken(ctx context.Context, pushToken pgtype.Text) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.searches {
+		if s.searches[i].PushToken.Valid && s.searches[i].PushToken.String == pushToken.String {
+			s.searches[i].PushToken = pgtype.Text{}
+		}
+	}
+	return nil
+}
+
+func (s *Store) CountSavedSearches(ctx context.Context, userID int64) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return int64(len(s.userSearches(userID))), nil
+}
+
+func (s *Store) CreateSavedSearch(ctx context.Context, params onefeed_th_sqlc.CreateSavedSearchParams) (onefeed_th_sqlc.SavedSearch, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextSearchID++
+	search := onefeed_th_sqlc.SavedSearch{
+		ID:         s.nextSearchID,
+		UserID:     params.UserID,
+		Name:       params.Name,
+		Query:      params.Query,
+		Sources:    slices.Clone(params.Sources),
+		PushToken:  params.PushToken,
+		LineUserID: params.LineUserID,
+		CreatedAt:  converter.TimeToPGTypeTimestamp(time.Now()),
+	}
+	s.searches = append(s.searches, search)
+	return search, nil
+}
+
+func (s *Store) DeleteSavedSearch(ctx context.Context, params onefeed_th_sqlc.DeleteSavedSearchParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	before := len(s.searches)
+	s.searches = slices.DeleteFunc(s.searches, func(search onefeed_th_sqlc.SavedSearch) bool {
+		return search.ID == params.ID && search.UserID == params.UserID
+	})
+	return int64(before - len(s.searches)), nil
+}
+
+func (s *Store) GetNotifyingSavedSearches(ctx context.Context) ([]onefeed_th_sqlc.SavedSearch, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return filter(s.searches, func(search onefeed_th_sqlc.SavedSearch) bool {
+		return search.PushToken.Valid || search.LineUserID.Valid
+	}), nil
+}
+
+func (s *Store) GetSavedSearch(ctx context.Context, params onefeed_th_sqlc.GetSavedSearchParams) (onefeed_th_sqlc.SavedSearch, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	search, ok := findByID(s.searches, params.ID, func(search onefeed_th_sqlc.SavedSearch) int64 { return search.ID })
+	if !ok || search.UserID != params.UserID {
+		return onefeed_th_sqlc.SavedSearch{}, pgx.ErrNoRows
+	}
+	return search, nil
+}
+
+func (s *Store) GetSavedSearches(ctx context.Context, userID int64) ([]onefeed_th_sqlc.SavedSearch, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.userSearches(userID), nil
+}
+
+func (s *Store) TouchSavedSearchesNotified(ctx context.Context, ids []int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := converter.TimeToPGTypeTimestamp(time.Now())
+	for i := range s.searches {
+		if slices.Contains(ids, s.searches[i].ID) {
+			s.searches[i].NotifiedAt = now
+		}
+	}
+	return nil
+}
+
+func (s *Store) UpdateSavedSearch(ctx context.Context, params onefeed_th_sqlc.UpdateSavedSearchParams) (onefeed_th_sqlc.SavedSearch, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.searches {
+		if s.searches[i].ID == params.ID && s.searches[i].UserID == params.UserID {
+			s.searches[i].Name = params.Name
+			s.searches[i].Query = params.Query
+			s.searches[i].Sources = slices.Clone(params.Sources)
+			s.searches[i].PushToken = params.PushToken
+			s.searches[i].LineUserID = params.LineUserID
+			s.searches[i].UpdatedAt = converter.TimeToPGTypeTimestamp(time.Now())
+			return s.searches[i], nil
+		}
+	}
+	return onefeed_th_sqlc.SavedSearch{}, pgx.ErrNoRows
+}
+
+// userSearches returns the searches of a user, oldest first
+func (s *Store) userSearches(userID int64) []onefeed_th_sqlc.SavedSearch {
+	return filter(s.searches, func(search onefeed_th_sqlc.SavedSearch) bool { return search.UserID == userID })
+}
+
+// Source stats
+
+func (s *Store) AggregateSourceStats(ctx context.Context, params onefeed_th_sqlc.UpsertSourceStatsDailyParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	day := params.Day.Time
+	end := day.AddDate(0, 0, 1)
+	sourceIDs := make(map[string]int64)
+	for _, source := range s.sources {
+		if !source.DeletedAt.Valid {
+			sourceIDs[source.Name] = source.ID
+		}
+	}
+
+	// AggregateNewsClicksDaily
+	totals := make(map[int64]int64)
+	for key, clicks := range s.clicks {
+		if !key.bucketStart.Before(day) && key.bucketStart.Before(end) {
+			totals[key.newsID] += clicks
+		}
+	}
+	sourceClicks := make(map[int64]int64)
+	for _, n := range s.news {
+		clicks, ok := totals[n.ID]
+		sourceID, known := sourceIDs[n.Source]
+		if !ok || !known {
+			continue
+		}
+		row := onefeed_th_sqlc.NewsClicksDaily{
+			NewsID:   n.ID,
+			Day:      params.Day,
+			SourceID: sourceID,
+			Title:    n.Title,
+			Link:     n.Link,
+			Clicks:   clicks,
+		}
+		i := slices.IndexFunc(s.dailyClicks, func(c onefeed_th_sqlc.NewsClicksDaily) bool {
+			return c.NewsID == n.ID && c.Day.Time.Equal(day)
+		})
+		if i >= 0 {
+			s.dailyClicks[i] = row
+		} else {
+			s.dailyClicks = append(s.dailyClicks, row)
+		}
+	}
+	for _, c := range s.dailyClicks {
+		if c.Day.Time.Equal(day) {
+			sourceClicks[c.SourceID] += c.Clicks
+		}
+	}
+
+	// UpsertSourceStatsDaily
+	articles := make(map[string]int64)
+	for _, n := range s.news {
+		if !n.FetchedAt.Time.Before(day) && n.FetchedAt.Time.Before(end) {
+			articles[n.Source]++
+		}
+	}
+	impressions := make(map[string]int64)
+	for i, source := range params.ImpressionSources {
+		if i < len(params.Impressions) {
+			impressions[source] = params.Impressions[i]
+		}
+	}
+	for name, id := range sourceIDs {
+		row := onefeed_th_sqlc.SourceStatsDaily{
+			SourceID:    id,
+			Day:         params.Day,
+			Articles:    articles[name],
+			Impressions: impressions[name],
+			Clicks:      sourceClicks[id],
+		}
+		i := slices.IndexFunc(s.sourceStats, func(stats onefeed_th_sqlc.SourceStatsDaily) bool {
+			return stats.SourceID == id && stats.Day.Time.Equal(day)
+		})
+		if i >= 0 {
+			s.sourceStats[i] = row
+		} else {
+			s.sourceStats = append(s.sourceStats, row)
+		}
+	}
+	return nil
+}
+
+func (s *Store) GetSourceStats(ctx context.Context, params onefeed_th_sqlc.ListSourceStatsParams) ([]onefeed_th_sqlc.ListSourceStatsRow, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	type statsKey struct {
+		sourceID int64
+		bucket   time.Time
+	}
+	totals := make(map[statsKey]*onefeed_th_sqlc.ListSourceStatsRow)
+	rows := make([]*onefeed_th_sqlc.ListSourceStatsRow, 0)
+	for _, stats := range s.sourceStats {
+		if stats.Day.Time.Before(params.FromDate.Time) || !stats.Day.Time.Before(params.ToDate.Time) {
+			continue
+		}
+		if len(params.SourceIds) > 0 && !slices.Contains(params.SourceIds, stats.SourceID) {
+			continue
+		}
+		key := statsKey{sourceID: stats.SourceID, bucket: truncDate(params.Interval, stats.Day.Time)}
+		row, ok := totals[key]
+		if !ok {
+			row = &onefeed_th_sqlc.ListSourceStatsRow{
+				SourceID: key.sourceID,
+				Bucket:   converter.TimeToPGTypeTimestamp(key.bucket),
+			}
+			totals[key] = row
+			rows = append(rows, row)
+		}
+		row.Articles += stats.Articles
+		row.Impressions += stats.Impressions
+		row.Clicks += stats.Clicks
+	}
+
+	result := make([]onefeed_th_sqlc.ListSourceStatsRow, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, *row)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].SourceID != result[j].SourceID {
+			return result[i].SourceID < result[j].SourceID
+		}
+		return result[i].Bucket.Time.Before(result[j].Bucket.Time)
+	})
+	return result, nil
+}
+
+func (s *Store) GetTopSourceNews(ctx context.Context, params onefeed_th_sqlc.ListTopSourceNewsParams) ([]onefeed_th_sqlc.ListTopSourceNewsRow, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	totals := make(map[int64]*onefeed_th_sqlc.ListTopSourceNewsRow)
+	for _, c := range s.dailyClicks {
+		if c.Day.Time.Before(params.FromDate.Time) || !c.Day.Time.Before(params.ToDate.Time) {
+			continue
+		}
+		if len(params.SourceIds) > 0 && !slices.Contains(params.SourceIds, c.SourceID) {
+			continue
+		}
+		row, ok := totals[c.NewsID]
+		if !ok {
+			row = &onefeed_th_sqlc.ListTopSourceNewsRow{SourceID: c.SourceID, NewsID: c.NewsID, Title: c.Title, Link: c.Link}
+			totals[c.NewsID] = row
+		}
+		row.Clicks += c.Clicks
+	}
+
+	rows := make([]onefeed_th_sqlc.ListTopSourceNewsRow, 0, len(totals))
+	for _, row := range totals {
+		rows = append(rows, *row)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].SourceID != rows[j].SourceID {
+			return rows[i].SourceID < rows[j].SourceID
+		}
+		if rows[i].Clicks != rows[j].Clicks {
+			return rows[i].Clicks > rows[j].Clicks
+		}
+		return rows[i].NewsID > rows[j].NewsID
+	})
+
+	result := make([]onefeed_th_sqlc.ListTopSourceNewsRow, 0, len(rows))
+	position := 0
+	for i, row := range rows {
+		if i == 0 || row.SourceID != rows[i-1].SourceID {
+			position = 0
+		}
+		position++
+		if position <= int(params.TopLimit) {
+			result = append(result, row)
+		}
+	}
+	return result, nil
+}
+
+func (s *Store) RemoveNewsClicksDailyBefore(ctx context.Context, before pgtype.Timestamp) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.dailyClicks = slices.DeleteFunc(s.dailyClicks, func(c onefeed_th_sqlc.NewsClicksDaily) bool {
+		return c.Day.Time.Before(before.Time)
+	})
+	return nil
+}
+
+// Users (readers)
+
+func (s *Store) CreateDeviceUser(ctx context.Context, deviceID string) (onefeed_th_sqlc.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := converter.TimeToPGTypeTimestamp(time.Now())
+	for i := range s.readers {
+		if s.readers[i].DeviceID.Valid && s.readers[i].DeviceID.String == deviceID {
+			s.readers[i].LastLoginAt = now
+			return s.readers[i], nil
+		}
+	}
+
+	s.nextReaderID++
+	user := onefeed_th_sqlc.User{
+		ID:          s.nextReaderID,
+		DeviceID:    pgtype.Text{String: deviceID, Valid: true},
+		LastLoginAt: now,
+		CreatedAt:   now,
+	}
+	s.readers = append(s.readers, user)
+	return user, nil
+}
+
+func (s *Store) CreateUser(ctx context.Context, params onefeed_th_sqlc.CreateUserParams) (onefeed_th_sqlc.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if params.Email.Valid && slices.ContainsFunc(s.readers, func(user onefeed_th_sqlc.User) bool {
+		return user.Email.Valid && user.Email.String == params.Email.String
+	}) {
+		return onefeed_th_sqlc.User{}, &pgconn.PgError{Code: "23505", Message: "duplicate key value violates unique constraint"}
+	}
+
+	now := converter.TimeToPGTypeTimestamp(time.Now())
+	s.nextReaderID++
+	user := onefeed_th_sqlc.User{
+		ID:           s.nextReaderID,
+		Email:        params.Email,
+		PasswordHash: params.PasswordHash,
+		DisplayName:  params.DisplayName,
+		LastLoginAt:  now,
+		CreatedAt:    now,
+	}
+	s.readers = append(s.readers, user)
+	return user, nil
+}
+
+func (s *Store) CreateUserIdentity(ctx context.Context, params onefeed_th_sqlc.CreateUserIdentityParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if slices.ContainsFunc(s.identities, func(identity onefeed_th_sqlc.UserIdentity) bool {
+		return identity.Provider == params.Provider && identity.Subject == params.Subject
+	}) {
+		return &pgconn.PgError{Code: "23505", Message: "duplicate key value violates unique constraint"}
+	}
+	s.identities = append(s.identities, onefeed_th_sqlc.UserIdentity{
+		Provider:  params.Provider,
+		Subject:   params.Subject,
+		UserID:    params.UserID,
+		Email:     params.Email,
+		CreatedAt: converter.TimeToPGTypeTimestamp(time.Now()),
+	})
+	return nil
+}
+
+func (s *Store) DeleteUser(ctx context.Context, id int64) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.deleteReader(id), nil
+}
+
+func (s *Store) GetUserByDeviceID(ctx context.Context, deviceID string) (onefeed_th_sqlc.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, user := range s.readers {
+		if user.DeviceID.Valid && user.DeviceID.String == deviceID {
+			return user, nil
+		}
+	}
+	return onefeed_th_sqlc.User{}, pgx.ErrNoRows
+}
+
+func (s *Store) GetUserByEmail(ctx context.Context, email string) (onefeed_th_sqlc.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, user := range s.readers {
+		if user.Email.Valid && user.Email.String == email {
+			return user, nil
+		}
+	}
+	return onefeed_th_sqlc.User{}, pgx.ErrNoRows
+}
+
+func (s *Store) GetUserByID(ctx context.Context, id int64) (onefeed_th_sqlc.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if user, ok := findByID(s.readers, id, func(user onefeed_th_sqlc.User) int64 { return user.ID }); ok {
+		return user, nil
+	}
+	return onefeed_th_sqlc.User{}, pgx.ErrNoRows
+}
+
+func (s *Store) GetUserByIdentity(ctx context.Context, params onefeed_th_sqlc.GetUserByIdentityParams) (onefeed_th_sqlc.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, identity := range s.identities {
+		if identity.Provider == params.Provider && identity.Subject == params.Subject {
+			if user, ok := findByID(s.readers, identity.UserID, func(user onefeed_th_sqlc.User) int64 { return user.ID }); ok {
+				return user, nil
+			}
+		}
+	}
+	return onefeed_th_sqlc.User{}, pgx.ErrNoRows
+}
+
+func (s *Store) LinkUserIdentity(ctx context.Context, params onefeed_th_sqlc.CreateUserIdentityParams) error {
+	if err := s.CreateUserIdentity(ctx, params); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.readers {
+		if s.readers[i].ID == params.UserID {
+			s.readers[i].PasswordHash = pgtype.Text{}
+			s.readers[i].UpdatedAt = converter.TimeToPGTypeTimestamp(time.Now())
+		}
+	}
+	return nil
+}
+
+func (s *Store) MergeUsers(ctx context.Context, fromID, toID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// the account keeps its own preferences
+	if !slices.ContainsFunc(s.preferences, func(pref onefeed_th_sqlc.UserPreference) bool { return pref.UserID == toID }) {
+		for _, pref := range s.preferences {
+			if pref.UserID == fromID {
+				pref.UserID = toID
+				s.preferences = append(s.preferences, pref)
+				break
+			}
+		}
+	}
+	for _, bookmark := range s.bookmarks {
+		if bookmark.UserID == fromID && !slices.ContainsFunc(s.bookmarks, func(existing onefeed_th_sqlc.Bookmark) bool {
+			return existing.UserID == toID && existing.NewsID == bookmark.NewsID
+		}) {
+			bookmark.UserID = toID
+			s.bookmarks = append(s.bookmarks, bookmark)
+		}
+	}
+	for _, read := range s.reads {
+		if read.UserID != fromID {
+			continue
+		}
+		i := slices.IndexFunc(s.reads, func(existing onefeed_th_sqlc.NewsRead) bool {
+			return existing.UserID == toID && existing.NewsID == read.NewsID
+		})
+		switch {
+		case i < 0:
+			read.UserID = toID
+			s.reads = append(s.reads, read)
+		case read.ReadAt.Time.Before(s.reads[i].ReadAt.Time):
+			s.reads[i].ReadAt = read.ReadAt
+		}
+	}
+	for i := range s.suggestions {
+		if s.suggestions[i].UserID == fromID {
+			s.suggestions[i].UserID = toID
+		}
+	}
+	for i := range s.searches {
+		if s.searches[i].UserID == fromID {
+			s.searches[i].UserID = toID
+		}
+	}
+
+	s.deleteReader(fromID)
+	return nil
+}
+
+func (s *Store) UpdateUserLastLogin(ctx context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.readers {
+		if s.readers[i].ID == id {
+			s.readers[i].LastLoginAt = converter.TimeToPGTypeTimestamp(time.Now())
+		}
+	}
+	return nil
+}
+
+func (s *Store) UpdateUserProfile(ctx context.Context, params onefeed_th_sqlc.UpdateUserProfileParams) (onefeed_th_sqlc.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.readers {
+		if s.readers[i].ID == params.ID {
+			s.readers[i].DisplayName = params.DisplayName
+			s.readers[i].UpdatedAt = converter.TimeToPGTypeTimestamp(time.Now())
+			return s.readers[i], nil
+		}
+	}
+	return onefeed_th_sqlc.User{}, pgx.ErrNoRows
+}
+
+// Preferences and bookmarks
+
+func (s *Store) GetUserPreferences(ctx context.Context, userID int64) (onefeed_th_sqlc.UserPreference, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, pref := range s.preferences {
+		if pref.UserID == userID {
+			return pref, nil
+		}
+	}
+	return onefeed_th_sqlc.UserPreference{}, pgx.ErrNoRows
+}
+
+func (s *Store) UpsertUserPreferences(ctx context.Context, params onefeed_th_sqlc.UpsertUserPreferencesParams) (onefeed_th_sqlc.UserPreference, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pref := onefeed_th_sqlc.UserPreference{
+		UserID:    params.UserID,
+		Sources:   params.Sources,
+		Tags:      params.Tags,
+		UpdatedAt: converter.TimeToPGTypeTimestamp(time.Now()),
+	}
+	for i := range s.preferences {
+		if s.preferences[i].UserID == params.UserID {
+			s.preferences[i] = pref
+			return pref, nil
+		}
+	}
+	s.preferences = append(s.preferences, pref)
+	return pref, nil
+}
+
+func (s *Store) CreateBookmark(ctx context.Context, params onefeed_th_sqlc.CreateBookmarkParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if slices.ContainsFunc(s.bookmarks, func(bookmark onefeed_th_sqlc.Bookmark) bool {
+		return bookmark.UserID == params.UserID && bookmark.NewsID == params.NewsID
+	}) {
+		return nil
+	}
+	s.bookmarks = append(s.bookmarks, onefeed_th_sqlc.Bookmark{
+		UserID:    params.UserID,
+		NewsID:    params.NewsID,
+		CreatedAt: converter.TimeToPGTypeTimestamp(time.Now().Truncate(time.Microsecond)),
+	})
+	return nil
+}
+
+func (s *Store) DeleteBookmark(ctx context.Context, params onefeed_th_sqlc.DeleteBookmarkParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	before := len(s.bookmarks)
+	s.bookmarks = slices.DeleteFunc(s.bookmarks, func(bookmark onefeed_th_sqlc.Bookmark) bool {
+		return bookmark.UserID == params.UserID && bookmark.NewsID == params.NewsID
+	})
+	return int64(before - len(s.bookmarks)), nil
+}
+
+func (s *Store) GetBookmarks(ctx context.Context, params onefeed_th_sqlc.ListBookmarksParams) ([]onefeed_th_sqlc.Bookmark, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var bookmarks []onefeed_th_sqlc.Bookmark
+	for _, bookmark := range s.bookmarks {
+		if bookmark.UserID != params.UserID ||
+			(params.BeforeCreatedAt.Valid && !keyBefore(bookmark.CreatedAt.Time, bookmark.NewsID, params.BeforeCreatedAt.Time, params.BeforeNewsID)) {
+			continue
+		}
+		bookmarks = append(bookmarks, bookmark)
+	}
+	sort.SliceStable(bookmarks, func(i, j int) bool {
+		return keyBefore(bookmarks[j].CreatedAt.Time, bookmarks[j].NewsID, bookmarks[i].CreatedAt.Time, bookmarks[i].NewsID)
+	})
+	return paginate(bookmarks, 0, params.PageLimit), nil
+}
+
+// Read history
+
+func (s *Store) CreateNewsReads(ctx context.Context, params onefeed_th_sqlc.CreateNewsReadsParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// microseconds like a Postgres TIMESTAMP, so read history cursors compare the same way
+	now := converter.TimeToPGTypeTimestamp(time.Now().Truncate(time.Microsecond))
+	for _, newsID := range params.NewsIds {
+		if slices.ContainsFunc(s.reads, func(read onefeed_th_sqlc.NewsRead) bool {
+			return read.UserID == params.UserID && read.NewsID == newsID
+		}) {
+			continue
+		}
+		s.reads = append(s.reads, onefeed_th_sqlc.NewsRead{
+			UserID: params.UserID,
+			NewsID: newsID,
+			ReadAt: now,
+		})
+	}
+	return nil
+}
+
+func (s *Store) DeleteNewsRead(ctx context.Context, params onefeed_th_sqlc.DeleteNewsReadParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	before := len(s.reads)
+	s.reads = slices.DeleteFunc(s.reads, func(read onefeed_th_sqlc.NewsRead) bool {
+		return read.UserID == params.UserID && read.NewsID == params.NewsID
+	})
+	return int64(before - len(s.reads)), nil
+}
+
+func (s *Store) GetNewsReads(ctx context.Context, params onefeed_th_sqlc.ListNewsReadsParams) ([]onefeed_th_sqlc.ListNewsReadsRow, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var rows []onefeed_th_sqlc.ListNewsReadsRow
+	for _, read := range s.reads {
+		if read.UserID != params.UserID ||
+			(params.Since.Valid && !read.ReadAt.Time.After(params.Since.Time)) ||
+			(params.BeforeReadAt.Valid && !keyBefore(read.ReadAt.Time, read.NewsID, params.BeforeReadAt.Time, params.BeforeNewsID)) {
+			continue
+		}
+		rows = append(rows, onefeed_th_sqlc.ListNewsReadsRow{NewsID: read.NewsID, ReadAt: read.ReadAt})
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		return keyBefore(rows[j].ReadAt.Time, rows[j].NewsID, rows[i].ReadAt.Time, rows[i].NewsID)
+	})
+	return paginate(rows, 0, params.PageLimit), nil
+}
+
+func (s *Store) GetReadNewsIDs(ctx context.Context, params onefeed_th_sqlc.ListReadNewsIDsParams) ([]int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var ids []int64
+	for _, read := range s.reads {
+		if read.UserID == params.UserID && slices.Contains(params.NewsIds, read.NewsID) {
+			ids = append(ids, read.NewsID)
+		}
+	}
+	return ids, nil
+}
+
+func (s *Store) GetReadSourceCounts(ctx context.Context, params onefeed_th_sqlc.ListReadSourceCountsParams) ([]onefeed_th_sqlc.ListReadSourceCountsRow, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var recent []onefeed_th_sqlc.NewsRead
+	for _, read := range s.reads {
+		if read.UserID == params.UserID {
+			recent = append(recent, read)
+		}
+	}
+	sort.SliceStable(recent, func(i, j int) bool {
+		return recent[i].ReadAt.Time.After(recent[j].ReadAt.Time)
+	})
+
+	counts := make(map[string]int64)
+	var sources []string
+	for _, read := range paginate(recent, 0, params.PageLimit) {
+		news, ok := findByID(s.news, read.NewsID, func(news onefeed_th_sqlc.News) int64 { return news.ID })
+		if !ok {
+			continue
+		}
+		if counts[news.Source] == 0 {
+			sources = append(sources, news.Source)
+		}
+		counts[news.Source]++
+	}
+
+	rows := make([]onefeed_th_sqlc.ListReadSourceCountsRow, 0, len(sources))
+	for _, source := range sources {
+		rows = append(rows, onefeed_th_sqlc.ListReadSourceCountsRow{Source: source, Reads: counts[source]})
+	}
+	return rows, nil
+}
+
+// helpers
+
+// keyBefore compares like the keyset row comparison (at, newsID) < (beforeAt, beforeNewsID)
+func keyBefore(at time.Time, newsID int64, beforeAt time.Time, beforeNewsID int64) bool {
+	if at.Equal(beforeAt) {
+		return newsID < beforeNewsID
+	}
+	return at.Before(beforeAt)
+}
+
+// deleteReader removes a user and, like ON DELETE CASCADE, everything stored for them
+func (s *Store) deleteReader(id int64) int64 {
+	before := len(s.readers)
+	s.readers = slices.DeleteFunc(s.readers, func(user onefeed_th_sqlc.User) bool { return user.ID == id })
+	s.identities = slices.DeleteFunc(s.identities, func(identity onefeed_th_sqlc.UserIdentity) bool { return identity.UserID == id })
+	s.reads = slices.DeleteFunc(s.reads, func(read onefeed_th_sqlc.NewsRead) bool { return read.UserID == id })
+	s.preferences = slices.DeleteFunc(s.preferences, func(pref onefeed_th_sqlc.UserPreference) bool { return pref.UserID == id })
+	s.bookmarks = slices.DeleteFunc(s.bookmarks, func(bookmark onefeed_th_sqlc.Bookmark) bool { return bookmark.UserID == id })
+	s.suggestions = slices.DeleteFunc(s.suggestions, func(suggestion onefeed_th_sqlc.SourceSuggestion) bool { return suggestion.UserID == id })
+	s.searches = slices.DeleteFunc(s.searches, func(search onefeed_th_sqlc.SavedSearch) bool { return search.UserID == id })
+	return int64(before - len(s.readers))
+}
+
+// deletePushDevice removes matching devices and, like ON DELETE CASCADE, their jobs
+func (s *Store) deletePushDevice(match func(onefeed_th_sqlc.PushDevice) bool) int64 {
+	var deleted []int64
+	s.devices = slices.DeleteFunc(s.devices, func(device onefeed_th_sqlc.PushDevice) bool {
+		if match(device) {
+			deleted = append(deleted, device.ID)
+			return true
+		}
+		return false
+	})
+	s.pushJobs = slices.DeleteFunc(s.pushJobs, func(job onefeed_th_sqlc.PushJob) bool {
+		return slices.Contains(deleted, job.DeviceID)
+	})
+	return int64(len(deleted))
+}
+
+// findByID returns the row whose id matches
+func findByID[T any](rows []T, id int64, rowID func(T) int64) (T, bool) {
+	for _, row := range rows {
+		if rowID(row) == id {
+			return row, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// filter returns a copy of the rows that match
+func filter[T any](rows []T, match func(T) bool) []T {
+	result := make([]T, 0, len(rows))
+	for _, row := range rows {
+		if match(row) {
+			result = append(result, row)
+		}
+	}
+	return result
+}
+
+// searchNews mirrors title ILIKE pattern with an optional source filter
+func (s *Store) searchNews(pattern string, sources []string) []onefeed_th_sqlc.News {
+	title := likePattern(pattern)
+	return s.filterNews(func(n onefeed_th_sqlc.News) bool {
+		return !n.Hidden &&
+			title.MatchString(n.Title) &&
+			(len(sources) == 0 || contains(sources, n.Source))
+	})
+}
+
+// likePattern turns an ILIKE pattern (%, _ and backslash escapes) into a regexp
+func likePattern(pattern string) *regexp.Regexp {
+	var expr strings.Builder
+	expr.WriteString("(?is)^")
+	escaped := false
+	for _, r := range pattern {
+		switch {
+		case escaped:
+			expr.WriteString(regexp.QuoteMeta(string(r)))
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '%':
+			expr.WriteString(".*")
+		case r == '_':
+			expr.WriteString(".")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	expr.WriteString("$")
+	return regexp.MustCompile(expr.String())
+}
+
+func (s *Store) filterNews(keep func(n onefeed_th_sqlc.News) bool) []onefeed_th_sqlc.News {
+	news := make([]onefeed_th_sqlc.News, 0)
+	for _, n := range s.news {
+		if keep(n) {
+			news = append(news, n)
+		}
+	}
+	return news
+}
+
+func (s *Store) hasNews(id int64) bool {
+	for _, n := range s.news {
+		if n.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func sortByPublishDateDesc(news []onefeed_th_sqlc.News) {
+	sort.SliceStable(news, func(i, j int) bool {
+		return news[i].PublishDate.Time.After(news[j].PublishDate.Time)
+	})
+}
+
+// sortByPublishDateAndIDDesc is the order of ListNews, ties broken by id
+func sortByPublishDateAndIDDesc(news []onefeed_th_sqlc.News) {
+	sort.Slice(news, func(i, j int) bool {
+		if !news[i].PublishDate.Time.Equal(news[j].PublishDate.Time) {
+			return news[i].PublishDate.Time.After(news[j].PublishDate.Time)
+		}
+		return news[i].ID > news[j].ID
+	})
+}
+
+// truncDate mirrors date_trunc for the day, week and month intervals
+func truncDate(interval string, t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	switch interval {
+	case "week":
+		// weeks start on Monday
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case "month":
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	}
+	return day
+}
+
+func paginate[T any](items []T, offset, limit int32) []T {
+	if offset < 0 {
+		offset = 0
+	}
+	if int(offset) >= len(items) {
+		return []T{}
+	}
+	items = items[offset:]
+	if limit >= 0 && int(limit) < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
+
+func contains[T comparable](items []T, item T) bool {
+	for _, candidate := range items {
+		if candidate == item {
+			return true
+		}
+	}
+	return false
+}
+
+// trigramSimilarity approximates pg_trgm similarity(): each word is padded with two
+// leading spaces and one trailing space and the trigram sets are compared by Jaccard index
+func trigramSimilarity(a, b string) float32 {
+	ta, tb := trigrams(a), trigrams(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+	shared := 0
+	for t := range ta {
+		if _, ok := tb[t]; ok {
+			shared++
+		}
+	}
+	return float32(shared) / float32(len(ta)+len(tb)-shared)
+}
+
+func trigrams(s string) map[string]struct{} {
+	set := make(map[string]struct{})
+	for _, word := range strings.Fields(strings.ToLower(s)) {
+		runes := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			set[string(runes[i:i+3])] = struct{}{}
+		}
+	}
+	return set
+}
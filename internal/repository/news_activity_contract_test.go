@@ -0,0 +1,246 @@
+package repository_test
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/repository"
+	onefeed_th_sqlc "github.com/onefeed-th/onefeed-th-backend-api/internal/sqlc/onefeed_th_sqlc/db"
+)
+
+func TestNewsClicks(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repo *repository.Repository) {
+		ctx := context.Background()
+		news := insertNews(t, repo,
+			newsItem("a", "thairath", "First", day(-3)),
+			newsItem("b", "thairath", "Second", day(-2)),
+			newsItem("c", "thairath", "Hidden", day(-1)),
+		)
+		a, b, c := news["a"].ID, news["b"].ID, news["c"].ID
+		hideNews(t, repo, c)
+
+		// clicks of unknown news are dropped, and a bucket is overwritten rather than added to
+		mustDo(t, repo.NewsClickRepository.UpsertNewsClicks(ctx, onefeed_th_sqlc.UpsertNewsClicksParams{
+			BucketStart: timestamp(day(0)), NewsIds: []int64{a, b, c, 999_999}, Clicks: []int64{5, 9, 50, 7},
+		}))
+		mustDo(t, repo.NewsClickRepository.UpsertNewsClicks(ctx, onefeed_th_sqlc.UpsertNewsClicksParams{
+			BucketStart: timestamp(day(0)), NewsIds: []int64{a}, Clicks: []int64{12},
+		}))
+		mustDo(t, repo.NewsClickRepository.UpsertNewsClicks(ctx, onefeed_th_sqlc.UpsertNewsClicksParams{
+			BucketStart: timestamp(day(-5)), NewsIds: []int64{b}, Clicks: []int64{100},
+		}))
+
+		trending := must(repo.NewsClickRepository.GetTrendingNews(ctx, onefeed_th_sqlc.ListTrendingNewsParams{
+			Since: timestamp(day(-1)), PageLimit: 10,
+		}))(t)
+		expectIDs(t, "trending", ids(trending, func(row onefeed_th_sqlc.ListTrendingNewsRow) int64 { return row.News.ID }), []int64{a, b})
+		expectEqual(t, "clicks", trending[0].Clicks, 12)
+
+		trending = must(repo.NewsClickRepository.GetTrendingNews(ctx, onefeed_th_sqlc.ListTrendingNewsParams{
+			Since: timestamp(day(-7)), PageLimit: 1,
+		}))(t)
+		expectEqual(t, "top clicks", trending[0].Clicks, 109)
+
+		mustDo(t, repo.NewsClickRepository.RemoveNewsClicksBefore(ctx, timestamp(day(-1))))
+		trending = must(repo.NewsClickRepository.GetTrendingNews(ctx, onefeed_th_sqlc.ListTrendingNewsParams{
+			Since: timestamp(day(-7)), PageLimit: 10,
+		}))(t)
+		expectEqual(t, "clicks after removal", trending[1].Clicks, 9)
+	})
+}
+
+func TestNewsClusters(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repo *repository.Repository) {
+		ctx := context.Background()
+		news := insertNews(t, repo,
+			newsItem("a", "thairath", "Flood in Bangkok", day(-3)),
+			newsItem("b", "matichon", "Bangkok floods", day(-2)),
+			newsItem("c", "thairath", "Election results", day(-1)),
+		)
+		a, b, c := news["a"], news["b"], news["c"]
+		all := []string{"thairath", "matichon"}
+
+		mustDo(t, repo.NewsClusterRepository.CreateNewsClusters(ctx, onefeed_th_sqlc.CreateNewsClustersParams{
+			NewsIds: []int64{b.ID}, ClusterIds: []int64{a.ID}, PublishDates: []pgtype.Timestamp{b.PublishDate},
+		}))
+		// a news keeps the cluster it was first given
+		mustDo(t, repo.NewsClusterRepository.CreateNewsClusters(ctx, onefeed_th_sqlc.CreateNewsClustersParams{
+			NewsIds: []int64{b.ID}, ClusterIds: []int64{c.ID}, PublishDates: []pgtype.Timestamp{b.PublishDate},
+		}))
+
+		clusters := must(repo.NewsClusterRepository.GetNewsClusters(ctx, []int64{a.ID, b.ID}))(t)
+		expectEqual(t, "clusters", len(clusters), 1)
+		expectEqual(t, "cluster", clusters[0].ClusterID, a.ID)
+
+		rows := must(repo.NewsClusterRepository.GetClusteredNews(ctx, onefeed_th_sqlc.ListClusteredNewsParams{
+			Sources: all, PageLimit: 10,
+		}))(t)
+		expectIDs(t, "clustered", ids(rows, func(row onefeed_th_sqlc.ListClusteredNewsRow) int64 { return row.ID }), []int64{c.ID, a.ID})
+		expectEqual(t, "also covered by", rows[1].AlsoCoveredBy, 1)
+		expectEqual(t, "clustered count", must(repo.NewsClusterRepository.CountClusteredNews(ctx, all))(t), 2)
+
+		rows = must(repo.NewsClusterRepository.GetClusteredNews(ctx, onefeed_th_sqlc.ListClusteredNewsParams{
+			Sources: []string{"matichon"}, PageLimit: 10,
+		}))(t)
+		expectIDs(t, "clustered by source", ids(rows, func(row onefeed_th_sqlc.ListClusteredNewsRow) int64 { return row.ID }), []int64{b.ID})
+		expectEqual(t, "also covered by one source", rows[0].AlsoCoveredBy, 0)
+
+		mustDo(t, repo.NewsClusterRepository.RemoveNewsClustersBefore(ctx, timestamp(day(0))))
+		expectEqual(t, "count after removal", must(repo.NewsClusterRepository.CountClusteredNews(ctx, all))(t), 3)
+	})
+}
+
+func TestNewsModeration(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repo *repository.Repository) {
+		ctx := context.Background()
+		news := insertNews(t, repo, newsItem("a", "thairath", "First", day(-1)))
+		id := news["a"].ID
+
+		affected := must(repo.NewsModerationRepository.SetNewsHidden(ctx,
+			onefeed_th_sqlc.SetNewsHiddenParams{Hidden: true, ID: 999_999},
+			onefeed_th_sqlc.CreateNewsModerationLogParams{Action: "hide"},
+		))(t)
+		expectEqual(t, "unknown news", affected, 0)
+		expectEqual(t, "logs of unknown news", len(must(repo.NewsModerationRepository.GetNewsModerationLogs(ctx, 999_999))(t)), 0)
+
+		must(repo.NewsModerationRepository.SetNewsHidden(ctx,
+			onefeed_th_sqlc.SetNewsHiddenParams{Hidden: true, ID: id},
+			onefeed_th_sqlc.CreateNewsModerationLogParams{Action: "hide", Reason: text("spam"), Actor: text("admin")},
+		))(t)
+		expectEqual(t, "visible news", must(repo.NewsRepository.CountNews(ctx, []string{"thairath"}))(t), 0)
+		must(repo.NewsModerationRepository.SetNewsHidden(ctx,
+			onefeed_th_sqlc.SetNewsHiddenParams{Hidden: false, ID: id},
+			onefeed_th_sqlc.CreateNewsModerationLogParams{Action: "unhide", Actor: text("admin")},
+		))(t)
+		expectEqual(t, "visible news", must(repo.NewsRepository.CountNews(ctx, []string{"thairath"}))(t), 1)
+
+		logs := must(repo.NewsModerationRepository.GetNewsModerationLogs(ctx, id))(t)
+		expectEqual(t, "logs", len(logs), 2)
+		expectEqual(t, "latest action", logs[0].Action, "unhide")
+		expectEqual(t, "news id", logs[1].NewsID, id)
+		expectEqual(t, "reason", logs[1].Reason, text("spam"))
+		expectEqual(t, "actor", logs[1].Actor, text("admin"))
+	})
+}
+
+func TestNewsArchive(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repo *repository.Repository) {
+		ctx := context.Background()
+		news := insertNews(t, repo,
+			newsItem("a", "thairath", "Old", day(-70)),
+			newsItem("b", "thairath", "Recent", day(-1)),
+		)
+		a, b := news["a"].ID, news["b"].ID
+		user := must(repo.UserRepository.CreateDeviceUser(ctx, "device-1"))(t)
+		for _, id := range []int64{a, b} {
+			mustDo(t, repo.BookmarkRepository.CreateBookmark(ctx, onefeed_th_sqlc.CreateBookmarkParams{UserID: user.ID, NewsID: id}))
+		}
+		mustDo(t, repo.NewsReadRepository.CreateNewsReads(ctx, onefeed_th_sqlc.CreateNewsReadsParams{UserID: user.ID, NewsIds: []int64{a, b}}))
+
+		// archiving works by whole months before the one of the cutoff
+		archived := must(repo.NewsArchiveRepository.ArchiveNewsPublishedBefore(ctx, day(-30)))(t)
+		expectEqual(t, "archived", archived, 1)
+		_, err := repo.NewsRepository.GetNewsByID(ctx, a)
+		expectNoRows(t, err)
+		missing := must(repo.NewsRepository.GetAllMissingLinks(ctx, []string{"a", "b"}))(t)
+		expectEqual(t, "missing links", slices.Equal(missing, []string{"a"}), true)
+
+		rows := must(repo.NewsArchiveRepository.GetArchivedNews(ctx, onefeed_th_sqlc.ListArchivedNewsParams{
+			Sources: []string{"thairath"}, FromDate: timestamp(day(-100)), ToDate: timestamp(day(1)), PageLimit: 10,
+		}))(t)
+		expectIDs(t, "archived news", ids(rows, func(n onefeed_th_sqlc.NewsArchive) int64 { return n.ID }), []int64{a})
+		expectEqual(t, "archived title", rows[0].Title, "Old")
+
+		removed := must(repo.NewsArchiveRepository.RemoveArchivedNewsBefore(ctx, day(0)))(t)
+		expectEqual(t, "removed", removed, 1)
+		rows = must(repo.NewsArchiveRepository.GetArchivedNews(ctx, onefeed_th_sqlc.ListArchivedNewsParams{
+			Sources: []string{"thairath"}, FromDate: timestamp(day(-100)), ToDate: timestamp(day(1)), PageLimit: 10,
+		}))(t)
+		expectEqual(t, "archived news after removal", len(rows), 0)
+
+		// the bookmarks and reads of removed news go with them
+		bookmarks := must(repo.BookmarkRepository.GetBookmarks(ctx, onefeed_th_sqlc.ListBookmarksParams{UserID: user.ID, PageLimit: 10}))(t)
+		expectIDs(t, "bookmarks", ids(bookmarks, func(b onefeed_th_sqlc.Bookmark) int64 { return b.NewsID }), []int64{b})
+		reads := must(repo.NewsReadRepository.GetReadNewsIDs(ctx, onefeed_th_sqlc.ListReadNewsIDsParams{UserID: user.ID, NewsIds: []int64{a, b}}))(t)
+		expectIDs(t, "reads", reads, []int64{b})
+
+		expectEqual(t, "nothing left to remove", must(repo.NewsArchiveRepository.RemoveArchivedNewsBefore(ctx, day(0)))(t), 0)
+	})
+}
+
+func TestNewsReads(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repo *repository.Repository) {
+		ctx := context.Background()
+		news := insertNews(t, repo,
+			newsItem("a", "thairath", "First", day(-3)),
+			newsItem("b", "matichon", "Second", day(-2)),
+			newsItem("c", "thairath", "Third", day(-1)),
+		)
+		a, b, c := news["a"].ID, news["b"].ID, news["c"].ID
+		user := must(repo.UserRepository.CreateDeviceUser(ctx, "device-1"))(t)
+		other := must(repo.UserRepository.CreateDeviceUser(ctx, "device-2"))(t)
+
+		mustDo(t, repo.NewsReadRepository.CreateNewsReads(ctx, onefeed_th_sqlc.CreateNewsReadsParams{UserID: user.ID, NewsIds: []int64{a, b}}))
+		// keeps the second read time apart from the first at microsecond precision
+		time.Sleep(time.Millisecond)
+		mustDo(t, repo.NewsReadRepository.CreateNewsReads(ctx, onefeed_th_sqlc.CreateNewsReadsParams{UserID: user.ID, NewsIds: []int64{b, c}}))
+		mustDo(t, repo.NewsReadRepository.CreateNewsReads(ctx, onefeed_th_sqlc.CreateNewsReadsParams{UserID: other.ID, NewsIds: []int64{a}}))
+
+		read := must(repo.NewsReadRepository.GetReadNewsIDs(ctx, onefeed_th_sqlc.ListReadNewsIDsParams{UserID: user.ID, NewsIds: []int64{a, c, 999_999}}))(t)
+		slices.Sort(read)
+		expectIDs(t, "read ids", read, []int64{a, c})
+
+		rows := must(repo.NewsReadRepository.GetNewsReads(ctx, onefeed_th_sqlc.ListNewsReadsParams{UserID: user.ID, PageLimit: 10}))(t)
+		readID := func(row onefeed_th_sqlc.ListNewsReadsRow) int64 { return row.NewsID }
+		expectIDs(t, "reads", ids(rows, readID), []int64{c, b, a})
+		page := must(repo.NewsReadRepository.GetNewsReads(ctx, onefeed_th_sqlc.ListNewsReadsParams{
+			UserID: user.ID, BeforeReadAt: rows[0].ReadAt, BeforeNewsID: rows[0].NewsID, PageLimit: 10,
+		}))(t)
+		expectIDs(t, "reads before cursor", ids(page, readID), []int64{b, a})
+		page = must(repo.NewsReadRepository.GetNewsReads(ctx, onefeed_th_sqlc.ListNewsReadsParams{
+			UserID: user.ID, Since: rows[1].ReadAt, PageLimit: 10,
+		}))(t)
+		expectIDs(t, "reads since", ids(page, readID), []int64{c})
+
+		counts := must(repo.NewsReadRepository.GetReadSourceCounts(ctx, onefeed_th_sqlc.ListReadSourceCountsParams{UserID: user.ID, PageLimit: 10}))(t)
+		slices.SortFunc(counts, func(x, y onefeed_th_sqlc.ListReadSourceCountsRow) int { return strings.Compare(x.Source, y.Source) })
+		want := []onefeed_th_sqlc.ListReadSourceCountsRow{{Source: "matichon", Reads: 1}, {Source: "thairath", Reads: 2}}
+		expectEqual(t, "read source counts", slices.Equal(counts, want), true)
+
+		expectEqual(t, "deleted", must(repo.NewsReadRepository.DeleteNewsRead(ctx, onefeed_th_sqlc.DeleteNewsReadParams{UserID: user.ID, NewsID: a}))(t), 1)
+		expectEqual(t, "deleted again", must(repo.NewsReadRepository.DeleteNewsRead(ctx, onefeed_th_sqlc.DeleteNewsReadParams{UserID: user.ID, NewsID: a}))(t), 0)
+		read = must(repo.NewsReadRepository.GetReadNewsIDs(ctx, onefeed_th_sqlc.ListReadNewsIDsParams{UserID: other.ID, NewsIds: []int64{a}}))(t)
+		expectIDs(t, "reads of another user", read, []int64{a})
+	})
+}
+
+func TestBookmarks(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repo *repository.Repository) {
+		ctx := context.Background()
+		news := insertNews(t, repo,
+			newsItem("a", "thairath", "First", day(-2)),
+			newsItem("b", "thairath", "Second", day(-1)),
+		)
+		a, b := news["a"].ID, news["b"].ID
+		user := must(repo.UserRepository.CreateDeviceUser(ctx, "device-1"))(t)
+
+		for _, id := range []int64{a, b, a} {
+			mustDo(t, repo.BookmarkRepository.CreateBookmark(ctx, onefeed_th_sqlc.CreateBookmarkParams{UserID: user.ID, NewsID: id}))
+		}
+		bookmarkID := func(b onefeed_th_sqlc.Bookmark) int64 { return b.NewsID }
+		bookmarks := must(repo.BookmarkRepository.GetBookmarks(ctx, onefeed_th_sqlc.ListBookmarksParams{UserID: user.ID, PageLimit: 10}))(t)
+		expectIDs(t, "bookmarks", ids(bookmarks, bookmarkID), []int64{b, a})
+
+		page := must(repo.BookmarkRepository.GetBookmarks(ctx, onefeed_th_sqlc.ListBookmarksParams{
+			UserID: user.ID, BeforeCreatedAt: bookmarks[0].CreatedAt, BeforeNewsID: bookmarks[0].NewsID, PageLimit: 10,
+		}))(t)
+		expectIDs(t, "bookmarks before cursor", ids(page, bookmarkID), []int64{a})
+
+		expectEqual(t, "deleted", must(repo.BookmarkRepository.DeleteBookmark(ctx, onefeed_th_sqlc.DeleteBookmarkParams{UserID: user.ID, NewsID: a}))(t), 1)
+		expectEqual(t, "deleted again", must(repo.BookmarkRepository.DeleteBookmark(ctx, onefeed_th_sqlc.DeleteBookmarkParams{UserID: user.ID, NewsID: a}))(t), 0)
+	})
+}
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/utils/converter"
+	onefeed_th_sqlc "github.com/onefeed-th/onefeed-th-backend-api/internal/sqlc/onefeed_th_sqlc/db"
+)
+
+type NewsArchiveRepository interface {
+	ArchiveNewsPublishedBefore(ctx context.Context, before time.Time) (int64, error)
+	GetArchivedNews(ctx context.Context, params onefeed_th_sqlc.ListArchivedNewsParams) ([]onefeed_th_sqlc.NewsArchive, error)
+	RemoveArchivedNewsBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+type NewsArchiveRepositoryImpl struct {
+	pool dbPool
+}
+
+func NewNewsArchiveRepository(pool func() *pgxpool.Pool) NewsArchiveRepository {
+	return &NewsArchiveRepositoryImpl{
+		pool: pool,
+	}
+}
+
+// ArchiveNewsPublishedBefore copies every news month that ended before the month of
+// before into the news_archive partitions and then drops those news partitions, all in
+// a single transaction. Retention is therefore month-granular: rows stay in news until
+// their whole month has expired
+func (r *NewsArchiveRepositoryImpl) ArchiveNewsPublishedBefore(ctx context.Context, before time.Time) (int64, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Moving a month of rows can legitimately outlast the pool-wide statement_timeout
+	if _, err := tx.Exec(ctx, "SET LOCAL statement_timeout = 0"); err != nil {
+		return 0, err
+	}
+
+	query := onefeed_th_sqlc.New(r.pool).WithTx(tx)
+	cutoffMonth := monthStart(before)
+	cutoff := converter.TimeToPGTypeTimestamp(cutoffMonth)
+
+	months, err := query.ListNewsMonthsBefore(ctx, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	for _, month := range months {
+		if !month.Valid {
+			continue
+		}
+		if err := ensureMonthlyPartition(ctx, tx, "news_archive", month.Time); err != nil {
+			return 0, err
+		}
+	}
+
+	archived, err := query.ArchiveNewsPublishedBefore(ctx, cutoff)
+	if err != nil {
+		return 0, err
+	}
+
+	partitions, err := listMonthlyPartitionsBefore(ctx, tx, "news", cutoffMonth)
+	if err != nil {
+		return 0, err
+	}
+	for _, partition := range partitions {
+		if _, err := tx.Exec(ctx, "DROP TABLE IF EXISTS "+pgx.Identifier{partition}.Sanitize()); err != nil {
+			return 0, fmt.Errorf("failed to drop partition %s: %w", partition, err)
+		}
+	}
+
+	// Expired rows that landed in news_default are the only ones left to delete
+	if _, err := query.RemoveNewsByPublishedDate(ctx, cutoff); err != nil {
+		return 0, err
+	}
+	// news_links mirrors news, so the links of the archived news are released with them
+	if _, err := query.RemoveNewsLinksByPublishedDate(ctx, cutoff); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return archived, nil
+}
+
+func (r *NewsArchiveRepositoryImpl) GetArchivedNews(ctx context.Context, params onefeed_th_sqlc.ListArchivedNewsParams) ([]onefeed_th_sqlc.NewsArchive, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) ([]onefeed_th_sqlc.NewsArchive, error) {
+		query := onefeed_th_sqlc.New(r.pool)
+		return query.ListArchivedNews(ctx, params)
+	})
+}
+
+// RemoveArchivedNewsBefore removes every archived news month that ended before the month of
+// before, together with the bookmarks, reads and tags of its news, in a single transaction.
+// Like archiving it is month-granular, whole news_archive partitions are dropped
+func (r *NewsArchiveRepositoryImpl) RemoveArchivedNewsBefore(ctx context.Context, before time.Time) (int64, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "SET LOCAL statement_timeout = 0"); err != nil {
+		return 0, err
+	}
+
+	query := onefeed_th_sqlc.New(r.pool).WithTx(tx)
+	cutoffMonth := monthStart(before)
+	cutoff := converter.TimeToPGTypeTimestamp(cutoffMonth)
+
+	removed, err := query.CountArchivedNewsBefore(ctx, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	if removed == 0 {
+		return 0, nil
+	}
+	if _, err := query.RemoveArchivedNewsBookmarks(ctx, cutoff); err != nil {
+		return 0, err
+	}
+	if _, err := query.RemoveArchivedNewsReads(ctx, cutoff); err != nil {
+		return 0, err
+	}
+	if _, err := query.RemoveArchivedNewsTags(ctx, cutoff); err != nil {
+		return 0, err
+	}
+
+	partitions, err := listMonthlyPartitionsBefore(ctx, tx, "news_archive", cutoffMonth)
+	if err != nil {
+		return 0, err
+	}
+	for _, partition := range partitions {
+		if _, err := tx.Exec(ctx, "DROP TABLE IF EXISTS "+pgx.Identifier{partition}.Sanitize()); err != nil {
+			return 0, fmt.Errorf("failed to drop partition %s: %w", partition, err)
+		}
+	}
+
+	// Expired rows that landed in news_archive_default are the only ones left to delete
+	if _, err := query.RemoveArchivedNewsByPublishedDate(ctx, cutoff); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return removed, nil
+}
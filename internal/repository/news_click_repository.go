@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	onefeed_th_sqlc "github.com/onefeed-th/onefeed-th-backend-api/internal/sqlc/onefeed_th_sqlc/db"
+)
+
+type NewsClickRepository interface {
+	UpsertNewsClicks(ctx context.Context, params onefeed_th_sqlc.UpsertNewsClicksParams) error
+	GetTrendingNews(ctx context.Context, params onefeed_th_sqlc.ListTrendingNewsParams) ([]onefeed_th_sqlc.ListTrendingNewsRow, error)
+	RemoveNewsClicksBefore(ctx context.Context, before pgtype.Timestamp) error
+}
+
+type NewsClickRepositoryImpl struct {
+	pool dbPool
+}
+
+func NewNewsClickRepository(pool func() *pgxpool.Pool) NewsClickRepository {
+	return &NewsClickRepositoryImpl{
+		pool: pool,
+	}
+}
+
+func (r *NewsClickRepositoryImpl) UpsertNewsClicks(ctx context.Context, params onefeed_th_sqlc.UpsertNewsClicksParams) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := onefeed_th_sqlc.New(r.pool)
+	return query.UpsertNewsClicks(ctx, params)
+}
+
+func (r *NewsClickRepositoryImpl) GetTrendingNews(ctx context.Context, params onefeed_th_sqlc.ListTrendingNewsParams) ([]onefeed_th_sqlc.ListTrendingNewsRow, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) ([]onefeed_th_sqlc.ListTrendingNewsRow, error) {
+		query := onefeed_th_sqlc.New(r.pool)
+		return query.ListTrendingNews(ctx, params)
+	})
+}
+
+func (r *NewsClickRepositoryImpl) RemoveNewsClicksBefore(ctx context.Context, before pgtype.Timestamp) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := onefeed_th_sqlc.New(r.pool)
+	return query.RemoveNewsClicksBefore(ctx, before)
+}
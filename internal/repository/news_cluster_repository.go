@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	onefeed_th_sqlc "github.com/onefeed-th/onefeed-th-backend-api/internal/sqlc/onefeed_th_sqlc/db"
+)
+
+type NewsClusterRepository interface {
+	CountClusteredNews(ctx context.Context, sources []string) (int64, error)
+	CreateNewsClusters(ctx context.Context, params onefeed_th_sqlc.CreateNewsClustersParams) error
+	GetClusteredNews(ctx context.Context, params onefeed_th_sqlc.ListClusteredNewsParams) ([]onefeed_th_sqlc.ListClusteredNewsRow, error)
+	GetNewsClusters(ctx context.Context, newsIDs []int64) ([]onefeed_th_sqlc.NewsCluster, error)
+	RemoveNewsClustersBefore(ctx context.Context, before pgtype.Timestamp) error
+}
+
+type NewsClusterRepositoryImpl struct {
+	pool     dbPool
+	readPool dbPool
+}
+
+func NewNewsClusterRepository(pool, readPool func() *pgxpool.Pool) NewsClusterRepository {
+	return &NewsClusterRepositoryImpl{
+		pool:     pool,
+		readPool: readPool,
+	}
+}
+
+func (r *NewsClusterRepositoryImpl) CountClusteredNews(ctx context.Context, sources []string) (int64, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) (int64, error) {
+		query := onefeed_th_sqlc.New(r.readPool)
+		return query.CountClusteredNews(ctx, sources)
+	})
+}
+
+func (r *NewsClusterRepositoryImpl) CreateNewsClusters(ctx context.Context, params onefeed_th_sqlc.CreateNewsClustersParams) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := onefeed_th_sqlc.New(r.pool)
+	return query.CreateNewsClusters(ctx, params)
+}
+
+func (r *NewsClusterRepositoryImpl) GetClusteredNews(ctx context.Context, params onefeed_th_sqlc.ListClusteredNewsParams) ([]onefeed_th_sqlc.ListClusteredNewsRow, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) ([]onefeed_th_sqlc.ListClusteredNewsRow, error) {
+		query := onefeed_th_sqlc.New(r.readPool)
+		return query.ListClusteredNews(ctx, params)
+	})
+}
+
+// GetNewsClusters reads from the primary, since the clustering step looks up news it has
+// just clustered
+func (r *NewsClusterRepositoryImpl) GetNewsClusters(ctx context.Context, newsIDs []int64) ([]onefeed_th_sqlc.NewsCluster, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) ([]onefeed_th_sqlc.NewsCluster, error) {
+		query := onefeed_th_sqlc.New(r.pool)
+		return query.ListNewsClusters(ctx, newsIDs)
+	})
+}
+
+func (r *NewsClusterRepositoryImpl) RemoveNewsClustersBefore(ctx context.Context, before pgtype.Timestamp) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := onefeed_th_sqlc.New(r.pool)
+	return query.RemoveNewsClustersBefore(ctx, before)
+}
@@ -0,0 +1,288 @@
+package repository_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/repository"
+	onefeed_th_sqlc "github.com/onefeed-th/onefeed-th-backend-api/internal/sqlc/onefeed_th_sqlc/db"
+)
+
+func newsItem(link, source, title string, published time.Time) repository.InsertNewsParams {
+	return repository.InsertNewsParams{
+		Title:       title,
+		Link:        link,
+		Source:      source,
+		ImageUrl:    "https://img.example.com/" + link + ".jpg",
+		PublishDate: &published,
+	}
+}
+
+// insertNews stores the items and returns them by link as they were stored
+func insertNews(t *testing.T, repo *repository.Repository, items ...repository.InsertNewsParams) map[string]onefeed_th_sqlc.News {
+	t.Helper()
+	ctx := context.Background()
+	mustDo(t, repo.NewsRepository.BulkInsertNews(ctx, items))
+
+	links := make([]string, 0, len(items))
+	for _, item := range items {
+		links = append(links, item.Link)
+	}
+	stored := must(repo.NewsRepository.GetNewsByLinks(ctx, links))(t)
+	byLink := make(map[string]onefeed_th_sqlc.News, len(stored))
+	for _, n := range stored {
+		byLink[n.Link] = n
+	}
+	return byLink
+}
+
+func hideNews(t *testing.T, repo *repository.Repository, id int64) {
+	t.Helper()
+	affected := must(repo.NewsModerationRepository.SetNewsHidden(context.Background(),
+		onefeed_th_sqlc.SetNewsHiddenParams{Hidden: true, ID: id},
+		onefeed_th_sqlc.CreateNewsModerationLogParams{Action: "hide"},
+	))(t)
+	expectEqual(t, "hidden rows", affected, 1)
+}
+
+func newsID(n onefeed_th_sqlc.News) int64 { return n.ID }
+
+func TestNewsLinksStayUnique(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repo *repository.Repository) {
+		ctx := context.Background()
+		news := insertNews(t, repo,
+			newsItem("a", "thairath", "First", day(-40)),
+			newsItem("b", "thairath", "Second", day(-1)),
+		)
+		expectEqual(t, "stored news", len(news), 2)
+
+		// the same link published in another month must not create a second row
+		mustDo(t, repo.NewsRepository.BulkInsertNews(ctx, []repository.InsertNewsParams{
+			newsItem("a", "thairath", "First again", day(-2)),
+			newsItem("c", "matichon", "Third", day(-1)),
+			newsItem("c", "matichon", "Third", day(-1)),
+		}))
+		stored := must(repo.NewsRepository.GetNewsByLinks(ctx, []string{"a", "b", "c"}))(t)
+		expectEqual(t, "stored news", len(stored), 3)
+		again := must(repo.NewsRepository.GetNewsByID(ctx, news["a"].ID))(t)
+		expectEqual(t, "title", again.Title, "First")
+
+		missing := must(repo.NewsRepository.GetAllMissingLinks(ctx, []string{"a", "d", "c", "e"}))(t)
+		if !slices.Equal(missing, []string{"d", "e"}) && !slices.Equal(missing, []string{"e", "d"}) {
+			t.Fatalf("missing links = %v, want d and e", missing)
+		}
+	})
+}
+
+func TestNewsUpsertRefreshesExistingLinks(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repo *repository.Repository) {
+		ctx := context.Background()
+		news := insertNews(t, repo, newsItem("a", "thairath", "First", day(-3)))
+		if news["a"].UpdatedAt.Valid {
+			t.Fatalf("a new news has updated_at set")
+		}
+
+		retitled := newsItem("a", "thairath", "First, edited", day(-2))
+		retitled.ImageUrl = ""
+		mustDo(t, repo.NewsRepository.BulkUpsertNews(ctx, []repository.InsertNewsParams{
+			retitled,
+			newsItem("b", "thairath", "Second", day(-1)),
+		}))
+
+		refreshed := must(repo.NewsRepository.GetNewsByID(ctx, news["a"].ID))(t)
+		expectEqual(t, "title", refreshed.Title, "First, edited")
+		expectEqual(t, "image", refreshed.ImageUrl.String, news["a"].ImageUrl.String)
+		expectEqual(t, "publish date", refreshed.PublishDate.Time.Equal(news["a"].PublishDate.Time), true)
+		if !refreshed.UpdatedAt.Valid {
+			t.Fatalf("a refreshed news has no updated_at")
+		}
+		expectEqual(t, "news count", must(repo.NewsRepository.CountNews(ctx, []string{"thairath"}))(t), 2)
+	})
+}
+
+func TestNewsUndatedItemsAreDatedNow(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repo *repository.Repository) {
+		item := newsItem("a", "thairath", "Undated", time.Time{})
+		item.PublishDate = nil
+		news := insertNews(t, repo, item)
+		if !news["a"].PublishDate.Valid || news["a"].PublishDate.Time.Before(day(-1)) {
+			t.Fatalf("publish date = %v, want around now", news["a"].PublishDate)
+		}
+	})
+}
+
+func TestNewsListings(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repo *repository.Repository) {
+		ctx := context.Background()
+		news := insertNews(t, repo,
+			newsItem("a", "thairath", "Flood in Bangkok", day(-3)),
+			newsItem("b", "matichon", "Election results", day(-2)),
+			newsItem("c", "thairath", "Bangkok traffic", day(-1)),
+			newsItem("d", "khaosod", "Hidden story", day(0)),
+			newsItem("e", "thairath", "Hidden flood", day(0)),
+		)
+		hideNews(t, repo, news["e"].ID)
+		a, b, c, d := news["a"].ID, news["b"].ID, news["c"].ID, news["d"].ID
+		all := []string{"thairath", "matichon", "khaosod"}
+
+		list := func(sort repository.NewsSort, offset, limit int32) []int64 {
+			rows := must(repo.NewsRepository.GetNews(ctx, onefeed_th_sqlc.ListNewsParams{
+				Sources: all, PageOffset: offset, PageLimit: limit,
+			}, sort))(t)
+			return ids(rows, newsID)
+		}
+		expectIDs(t, "published desc", list(repository.NewsSortPublishedAtDesc, 0, 10), []int64{d, c, b, a})
+		expectIDs(t, "published asc", list(repository.NewsSortPublishedAtAsc, 0, 10), []int64{a, b, c, d})
+		expectIDs(t, "source asc", list(repository.NewsSortSourceAsc, 0, 10), []int64{d, b, c, a})
+		expectIDs(t, "second page", list(repository.NewsSortPublishedAtDesc, 2, 2), []int64{b, a})
+		expectEqual(t, "fetched desc", len(list(repository.NewsSortFetchedAtDesc, 0, 10)), 4)
+
+		before := func(key onefeed_th_sqlc.News, limit int32) []int64 {
+			rows := must(repo.NewsRepository.GetNewsBeforeCursor(ctx, onefeed_th_sqlc.ListNewsBeforeCursorParams{
+				Sources: all, PublishDate: key.PublishDate, ID: key.ID, PageLimit: limit,
+			}))(t)
+			return ids(rows, newsID)
+		}
+		expectIDs(t, "before d", before(news["d"], 2), []int64{c, b})
+		expectIDs(t, "before c", before(news["c"], 10), []int64{b, a})
+		expectIDs(t, "before a", before(news["a"], 10), nil)
+
+		expectEqual(t, "count", must(repo.NewsRepository.CountNews(ctx, []string{"thairath"}))(t), 2)
+
+		hidden := must(repo.NewsRepository.GetNewsByID(ctx, news["e"].ID))(t)
+		expectEqual(t, "hidden", hidden.Hidden, true)
+		_, err := repo.NewsRepository.GetNewsByID(ctx, 999_999)
+		expectNoRows(t, err)
+
+		related := must(repo.NewsRepository.GetRelatedNewsBySource(ctx, onefeed_th_sqlc.ListRelatedNewsBySourceParams{
+			Source: "thairath", ID: a, PageLimit: 10,
+		}))(t)
+		expectIDs(t, "related", ids(related, newsID), []int64{c})
+
+		byIDs := must(repo.NewsRepository.GetNewsByIDs(ctx, []int64{a, c, news["e"].ID}))(t)
+		expectIDs(t, "by ids", ids(byIDs, newsID), []int64{c, a})
+
+		byLinks := must(repo.NewsRepository.GetNewsByLinks(ctx, []string{"b", "e"}))(t)
+		expectIDs(t, "by links", ids(byLinks, newsID), []int64{b})
+
+		latest := must(repo.NewsRepository.GetLatestNewsPerSource(ctx, onefeed_th_sqlc.ListLatestNewsPerSourceParams{
+			Sources: all, PerSource: 1,
+		}))(t)
+		expectIDs(t, "latest per source", ids(latest, newsID), []int64{d, b, c})
+
+		sources := must(repo.NewsRepository.GetAllSource(ctx))(t)
+		slices.Sort(sources)
+		expectEqual(t, "sources", slices.Equal(sources, []string{"khaosod", "matichon", "thairath"}), true)
+
+		counts := must(repo.NewsRepository.GetSourceNewsCounts(ctx, timestamp(day(-1))))(t)
+		want := []onefeed_th_sqlc.ListSourceNewsCountsRow{
+			{Source: "thairath", Total: 2, Recent: 2},
+			{Source: "khaosod", Total: 1, Recent: 1},
+			{Source: "matichon", Total: 1, Recent: 1},
+		}
+		expectEqual(t, "source counts", slices.Equal(counts, want), true)
+		counts = must(repo.NewsRepository.GetSourceNewsCounts(ctx, timestamp(day(2))))(t)
+		expectEqual(t, "recent counts", counts[0].Recent, 0)
+	})
+}
+
+func TestNewsSearch(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repo *repository.Repository) {
+		ctx := context.Background()
+		news := insertNews(t, repo,
+			newsItem("a", "thairath", "Flood in Bangkok", day(-3)),
+			newsItem("b", "matichon", "Bangkok flood update", day(-2)),
+			newsItem("c", "thairath", "Election results", day(-1)),
+			newsItem("d", "thairath", "Hidden flood", day(0)),
+		)
+		hideNews(t, repo, news["d"].ID)
+
+		found := must(repo.NewsRepository.SearchNews(ctx, onefeed_th_sqlc.SearchNewsParams{
+			Pattern: "%FLOOD%", PageLimit: 10,
+		}))(t)
+		expectIDs(t, "search", ids(found, newsID), []int64{news["b"].ID, news["a"].ID})
+		found = must(repo.NewsRepository.SearchNews(ctx, onefeed_th_sqlc.SearchNewsParams{
+			Pattern: "%flood%", Sources: []string{"thairath"}, PageLimit: 10,
+		}))(t)
+		expectIDs(t, "search by source", ids(found, newsID), []int64{news["a"].ID})
+		found = must(repo.NewsRepository.SearchNews(ctx, onefeed_th_sqlc.SearchNewsParams{
+			Pattern: "%flood%", PageLimit: 1, PageOffset: 1,
+		}))(t)
+		expectIDs(t, "search page", ids(found, newsID), []int64{news["a"].ID})
+
+		count := must(repo.NewsRepository.CountSearchNews(ctx, onefeed_th_sqlc.CountSearchNewsParams{Pattern: "%flood%"}))(t)
+		expectEqual(t, "search count", count, 2)
+
+		similar := must(repo.NewsRepository.GetSimilarNews(ctx, onefeed_th_sqlc.ListSimilarNewsParams{
+			ID:            news["a"].ID,
+			WindowStart:   timestamp(day(-7)),
+			WindowEnd:     timestamp(day(1)),
+			Title:         "Flood in Bangkok",
+			MinSimilarity: 0.3,
+			PageLimit:     10,
+		}))(t)
+		expectIDs(t, "similar", ids(similar, newsID), []int64{news["b"].ID})
+	})
+}
+
+func TestNewsFeedsAndExport(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repo *repository.Repository) {
+		ctx := context.Background()
+		news := insertNews(t, repo,
+			newsItem("a", "thairath", "Old", day(-40)),
+			newsItem("b", "matichon", "Recent", day(-2)),
+			newsItem("c", "thairath", "Hidden", day(-1)),
+			newsItem("d", "khaosod", "Latest", day(0)),
+		)
+		hideNews(t, repo, news["c"].ID)
+		a, b, c, d := news["a"].ID, news["b"].ID, news["c"].ID, news["d"].ID
+
+		after := must(repo.NewsRepository.GetNewsAfterID(ctx, onefeed_th_sqlc.ListNewsAfterIDParams{AfterID: a, PageLimit: 10}))(t)
+		expectIDs(t, "after id", ids(after, newsID), []int64{b, d})
+
+		// exports include hidden news
+		exported := must(repo.NewsRepository.GetNewsForExport(ctx, onefeed_th_sqlc.ListNewsForExportParams{
+			FromDate: timestamp(day(-7)), ToDate: timestamp(day(1)), PageLimit: 10,
+		}))(t)
+		expectIDs(t, "export", ids(exported, newsID), []int64{b, c, d})
+		exported = must(repo.NewsRepository.GetNewsForExport(ctx, onefeed_th_sqlc.ListNewsForExportParams{
+			AfterID: b, FromDate: timestamp(day(-7)), ToDate: timestamp(day(1)), Sources: []string{"thairath"}, PageLimit: 10,
+		}))(t)
+		expectIDs(t, "export by source", ids(exported, newsID), []int64{c})
+
+		random := must(repo.NewsRepository.GetRandomRecentNews(ctx, onefeed_th_sqlc.ListRandomRecentNewsParams{
+			Since: timestamp(day(-7)), ExcludeSources: []string{"khaosod"}, PageLimit: 10,
+		}))(t)
+		expectIDs(t, "random recent", ids(random, newsID), []int64{b})
+
+		mustDo(t, repo.NewsRepository.SetNewsSummary(ctx, onefeed_th_sqlc.SetNewsSummaryParams{Summary: text("A summary"), ID: d}))
+		summarized := must(repo.NewsRepository.GetNewsByID(ctx, d))(t)
+		expectEqual(t, "summary", summarized.Summary, text("A summary"))
+
+		mustDo(t, repo.NewsRepository.NotifyNewsCreated(ctx, `{"ids":[1]}`))
+	})
+}
+
+func TestNewsRemovalReleasesLinks(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repo *repository.Repository) {
+		ctx := context.Background()
+		news := insertNews(t, repo,
+			newsItem("a", "thairath", "Old", day(-40)),
+			newsItem("b", "thairath", "Recent", day(-1)),
+		)
+		mustDo(t, repo.NewsRepository.EnsureNewsPartitions(ctx, day(60)))
+
+		removed := must(repo.NewsRepository.RemoveNewsByPublishedDate(ctx, timestamp(day(-30))))(t)
+		expectEqual(t, "removed", removed, 1)
+		_, err := repo.NewsRepository.GetNewsByID(ctx, news["a"].ID)
+		expectNoRows(t, err)
+		missing := must(repo.NewsRepository.GetAllMissingLinks(ctx, []string{"a", "b"}))(t)
+		expectEqual(t, "missing links", slices.Equal(missing, []string{"a"}), true)
+
+		// a removed link can be collected again
+		again := insertNews(t, repo, newsItem("a", "thairath", "Old, collected again", day(-1)))
+		expectEqual(t, "title", again["a"].Title, "Old, collected again")
+	})
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	onefeed_th_sqlc "github.com/onefeed-th/onefeed-th-backend-api/internal/sqlc/onefeed_th_sqlc/db"
+)
+
+const (
+	NewsModerationActionHide    = "hide"
+	NewsModerationActionRestore = "restore"
+)
+
+type NewsModerationRepository interface {
+	SetNewsHidden(ctx context.Context, params onefeed_th_sqlc.SetNewsHiddenParams, log onefeed_th_sqlc.CreateNewsModerationLogParams) (int64, error)
+	GetNewsModerationLogs(ctx context.Context, newsID int64) ([]onefeed_th_sqlc.NewsModerationLog, error)
+}
+
+type NewsModerationRepositoryImpl struct {
+	pool dbPool
+}
+
+func NewNewsModerationRepository(pool func() *pgxpool.Pool) NewsModerationRepository {
+	return &NewsModerationRepositoryImpl{
+		pool: pool,
+	}
+}
+
+// SetNewsHidden flips the hidden flag and records the audit entry in the same transaction.
+// Nothing is logged when the news item does not exist
+func (r *NewsModerationRepositoryImpl) SetNewsHidden(ctx context.Context, params onefeed_th_sqlc.SetNewsHiddenParams, log onefeed_th_sqlc.CreateNewsModerationLogParams) (int64, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := onefeed_th_sqlc.New(r.pool).WithTx(tx)
+
+	affected, err := query.SetNewsHidden(ctx, params)
+	if err != nil {
+		return 0, err
+	}
+	if affected == 0 {
+		return 0, nil
+	}
+
+	log.NewsID = params.ID
+	if _, err := query.CreateNewsModerationLog(ctx, log); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return affected, nil
+}
+
+func (r *NewsModerationRepositoryImpl) GetNewsModerationLogs(ctx context.Context, newsID int64) ([]onefeed_th_sqlc.NewsModerationLog, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) ([]onefeed_th_sqlc.NewsModerationLog, error) {
+		query := onefeed_th_sqlc.New(r.pool)
+		return query.ListNewsModerationLogs(ctx, newsID)
+	})
+}
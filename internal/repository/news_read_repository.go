@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	onefeed_th_sqlc "github.com/onefeed-th/onefeed-th-backend-api/internal/sqlc/onefeed_th_sqlc/db"
+)
+
+type NewsReadRepository interface {
+	CreateNewsReads(ctx context.Context, params onefeed_th_sqlc.CreateNewsReadsParams) error
+	DeleteNewsRead(ctx context.Context, params onefeed_th_sqlc.DeleteNewsReadParams) (int64, error)
+	GetNewsReads(ctx context.Context, params onefeed_th_sqlc.ListNewsReadsParams) ([]onefeed_th_sqlc.ListNewsReadsRow, error)
+	GetReadNewsIDs(ctx context.Context, params onefeed_th_sqlc.ListReadNewsIDsParams) ([]int64, error)
+	GetReadSourceCounts(ctx context.Context, params onefeed_th_sqlc.ListReadSourceCountsParams) ([]onefeed_th_sqlc.ListReadSourceCountsRow, error)
+}
+
+type NewsReadRepositoryImpl struct {
+	pool dbPool
+}
+
+func NewNewsReadRepository(pool func() *pgxpool.Pool) NewsReadRepository {
+	return &NewsReadRepositoryImpl{
+		pool: pool,
+	}
+}
+
+func (r *NewsReadRepositoryImpl) CreateNewsReads(ctx context.Context, params onefeed_th_sqlc.CreateNewsReadsParams) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := onefeed_th_sqlc.New(r.pool)
+	return query.CreateNewsReads(ctx, params)
+}
+
+func (r *NewsReadRepositoryImpl) DeleteNewsRead(ctx context.Context, params onefeed_th_sqlc.DeleteNewsReadParams) (int64, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := onefeed_th_sqlc.New(r.pool)
+	return query.DeleteNewsRead(ctx, params)
+}
+
+func (r *NewsReadRepositoryImpl) GetNewsReads(ctx context.Context, params onefeed_th_sqlc.ListNewsReadsParams) ([]onefeed_th_sqlc.ListNewsReadsRow, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) ([]onefeed_th_sqlc.ListNewsReadsRow, error) {
+		query := onefeed_th_sqlc.New(r.pool)
+		return query.ListNewsReads(ctx, params)
+	})
+}
+
+func (r *NewsReadRepositoryImpl) GetReadNewsIDs(ctx context.Context, params onefeed_th_sqlc.ListReadNewsIDsParams) ([]int64, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) ([]int64, error) {
+		query := onefeed_th_sqlc.New(r.pool)
+		return query.ListReadNewsIDs(ctx, params)
+	})
+}
+
+func (r *NewsReadRepositoryImpl) GetReadSourceCounts(ctx context.Context, params onefeed_th_sqlc.ListReadSourceCountsParams) ([]onefeed_th_sqlc.ListReadSourceCountsRow, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) ([]onefeed_th_sqlc.ListReadSourceCountsRow, error) {
+		query := onefeed_th_sqlc.New(r.pool)
+		return query.ListReadSourceCounts(ctx, params)
+	})
+}
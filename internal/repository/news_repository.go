@@ -2,53 +2,412 @@ package repository
 
 import (
 	"context"
+	"fmt"
+	"time"
 
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/utils/converter"
 	onefeed_th_sqlc "github.com/onefeed-th/onefeed-th-backend-api/internal/sqlc/onefeed_th_sqlc/db"
 )
 
+// NewsSort selects the ordering used when listing news
+type NewsSort string
+
+const (
+	NewsSortPublishedAtDesc NewsSort = "publishedAt:desc"
+	NewsSortPublishedAtAsc  NewsSort = "publishedAt:asc"
+	NewsSortFetchedAtDesc   NewsSort = "fetchedAt:desc"
+	NewsSortSourceAsc       NewsSort = "source:asc"
+)
+
+// IsValid reports whether the sort is one of the supported orderings
+func (s NewsSort) IsValid() bool {
+	switch s {
+	case NewsSortPublishedAtDesc, NewsSortPublishedAtAsc, NewsSortFetchedAtDesc, NewsSortSourceAsc:
+		return true
+	}
+	return false
+}
+
+// InsertNewsParams is a single collected item to be stored
+type InsertNewsParams struct {
+	Title       string
+	Link        string
+	Source      string
+	ImageUrl    string
+	PublishDate *time.Time
+	Summary     string
+}
+
+// bulkInsertNewsBatchSize bounds the array size sent per statement
+const bulkInsertNewsBatchSize = 100
+
 type NewsRepository interface {
-	BulkInsertNews(ctx context.Context, stringBuilder string, args []interface{}) error
-	GetNews(ctx context.Context, params onefeed_th_sqlc.ListNewsParams) ([]onefeed_th_sqlc.News, error)
-	RemoveNewsByPublishedDate(ctx context.Context) error
+	BulkInsertNews(ctx context.Context, params []InsertNewsParams) error
+	BulkUpsertNews(ctx context.Context, params []InsertNewsParams) error
+	GetNews(ctx context.Context, params onefeed_th_sqlc.ListNewsParams, sort NewsSort) ([]onefeed_th_sqlc.News, error)
+	// GetNewsBeforeCursor continues the publishedAt:desc order of GetNews past a cursor
+	GetNewsBeforeCursor(ctx context.Context, params onefeed_th_sqlc.ListNewsBeforeCursorParams) ([]onefeed_th_sqlc.News, error)
+	RemoveNewsByPublishedDate(ctx context.Context, before pgtype.Timestamp) (int64, error)
 	GetAllSource(ctx context.Context) ([]string, error)
 	GetAllMissingLinks(ctx context.Context, links []string) ([]string, error)
+	GetNewsByID(ctx context.Context, id int64) (onefeed_th_sqlc.News, error)
+	GetRelatedNewsBySource(ctx context.Context, params onefeed_th_sqlc.ListRelatedNewsBySourceParams) ([]onefeed_th_sqlc.News, error)
+	GetNewsByIDs(ctx context.Context, ids []int64) ([]onefeed_th_sqlc.News, error)
+	GetNewsByLinks(ctx context.Context, links []string) ([]onefeed_th_sqlc.News, error)
+	GetSimilarNews(ctx context.Context, params onefeed_th_sqlc.ListSimilarNewsParams) ([]onefeed_th_sqlc.News, error)
+	GetLatestNewsPerSource(ctx context.Context, params onefeed_th_sqlc.ListLatestNewsPerSourceParams) ([]onefeed_th_sqlc.News, error)
+	CountNews(ctx context.Context, sources []string) (int64, error)
+	// GetSourceNewsCounts counts the visible news of every source, in total and fetched since
+	GetSourceNewsCounts(ctx context.Context, fetchedSince pgtype.Timestamp) ([]onefeed_th_sqlc.ListSourceNewsCountsRow, error)
+	SearchNews(ctx context.Context, params onefeed_th_sqlc.SearchNewsParams) ([]onefeed_th_sqlc.News, error)
+	CountSearchNews(ctx context.Context, params onefeed_th_sqlc.CountSearchNewsParams) (int64, error)
+	GetNewsAfterID(ctx context.Context, params onefeed_th_sqlc.ListNewsAfterIDParams) ([]onefeed_th_sqlc.News, error)
+	GetNewsForExport(ctx context.Context, params onefeed_th_sqlc.ListNewsForExportParams) ([]onefeed_th_sqlc.News, error)
+	GetRandomRecentNews(ctx context.Context, params onefeed_th_sqlc.ListRandomRecentNewsParams) ([]onefeed_th_sqlc.News, error)
+	SetNewsSummary(ctx context.Context, params onefeed_th_sqlc.SetNewsSummaryParams) error
+	NotifyNewsCreated(ctx context.Context, payload string) error
+	// EnsureNewsPartitions creates the monthly partitions from the current month through the
+	// month of through, and those of months whose rows are waiting in news_default
+	EnsureNewsPartitions(ctx context.Context, through time.Time) error
 }
 
 type NewsRepositoryImpl struct {
-	pool *pgxpool.Pool
+	pool     dbPool
+	readPool dbPool
 }
 
-func NewNewsRepository(pool *pgxpool.Pool) NewsRepository {
+func NewNewsRepository(pool, readPool func() *pgxpool.Pool) NewsRepository {
 	return &NewsRepositoryImpl{
-		pool: pool,
+		pool:     pool,
+		readPool: readPool,
 	}
 }
 
-func (r *NewsRepositoryImpl) BulkInsertNews(ctx context.Context, stringBuilder string, args []interface{}) error {
-	_, err := r.pool.Exec(ctx, stringBuilder, args...)
-	if err != nil {
-		return err
+// BulkInsertNews stores new items and skips links that already exist
+func (r *NewsRepositoryImpl) BulkInsertNews(ctx context.Context, params []InsertNewsParams) error {
+	query := onefeed_th_sqlc.New(r.pool)
+	for i := 0; i < len(params); i += bulkInsertNewsBatchSize {
+		end := min(i+bulkInsertNewsBatchSize, len(params))
+		batchCtx, cancel := withQueryTimeout(ctx)
+		err := query.InsertNewsBatch(batchCtx, onefeed_th_sqlc.InsertNewsBatchParams(toNewsBatchColumns(params[i:end])))
+		cancel()
+		if err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
-func (r *NewsRepositoryImpl) GetNews(ctx context.Context, params onefeed_th_sqlc.ListNewsParams) ([]onefeed_th_sqlc.News, error) {
+// BulkUpsertNews stores new items and refreshes the title and image of existing
+// links when they changed, bumping updated_at
+func (r *NewsRepositoryImpl) BulkUpsertNews(ctx context.Context, params []InsertNewsParams) error {
 	query := onefeed_th_sqlc.New(r.pool)
-	return query.ListNews(ctx, params)
+	for i := 0; i < len(params); i += bulkInsertNewsBatchSize {
+		end := min(i+bulkInsertNewsBatchSize, len(params))
+		batchCtx, cancel := withQueryTimeout(ctx)
+		err := query.UpsertNewsBatch(batchCtx, onefeed_th_sqlc.UpsertNewsBatchParams(toNewsBatchColumns(params[i:end])))
+		cancel()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-func (r *NewsRepositoryImpl) RemoveNewsByPublishedDate(ctx context.Context) error {
-	query := onefeed_th_sqlc.New(r.pool)
-	return query.RemoveNewsByPublishedDate(ctx)
+// EnsureNewsPartitions runs each month in its own transaction, so the rows moved out of
+// news_default for one month aren't held back by a failure of another
+func (r *NewsRepositoryImpl) EnsureNewsPartitions(ctx context.Context, through time.Time) error {
+	months, err := r.newsDefaultMonths(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list news_default months: %w", err)
+	}
+	for month := monthStart(time.Now()); !month.After(through); month = month.AddDate(0, 1, 0) {
+		months = append(months, month)
+	}
+
+	for _, month := range months {
+		if err := r.ensureNewsPartition(ctx, month); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *NewsRepositoryImpl) newsDefaultMonths(ctx context.Context) ([]time.Time, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+	return listDefaultPartitionMonths(ctx, tx, "news")
+}
+
+func (r *NewsRepositoryImpl) ensureNewsPartition(ctx context.Context, month time.Time) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Moving the rows of a busy month out of news_default can outlast statement_timeout
+	if _, err := tx.Exec(ctx, "SET LOCAL statement_timeout = 0"); err != nil {
+		return err
+	}
+	if err := ensureMonthlyPartition(ctx, tx, "news", month); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// toNewsBatchColumns turns rows into the column arrays expected by the unnest based batch queries
+func toNewsBatchColumns(params []InsertNewsParams) onefeed_th_sqlc.InsertNewsBatchParams {
+	columns := onefeed_th_sqlc.InsertNewsBatchParams{
+		Titles:       make([]string, 0, len(params)),
+		Links:        make([]string, 0, len(params)),
+		Sources:      make([]string, 0, len(params)),
+		ImageUrls:    make([]string, 0, len(params)),
+		PublishDates: make([]pgtype.Timestamp, 0, len(params)),
+		Summaries:    make([]string, 0, len(params)),
+	}
+	for _, item := range params {
+		columns.Titles = append(columns.Titles, item.Title)
+		columns.Links = append(columns.Links, item.Link)
+		columns.Sources = append(columns.Sources, item.Source)
+		columns.ImageUrls = append(columns.ImageUrls, item.ImageUrl)
+		columns.PublishDates = append(columns.PublishDates, converter.TimePointerToPGTypeTimestamp(item.PublishDate))
+		columns.Summaries = append(columns.Summaries, item.Summary)
+	}
+	return columns
+}
+
+func (r *NewsRepositoryImpl) GetNews(ctx context.Context, params onefeed_th_sqlc.ListNewsParams, sort NewsSort) ([]onefeed_th_sqlc.News, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) ([]onefeed_th_sqlc.News, error) {
+		query := onefeed_th_sqlc.New(r.readPool)
+		switch sort {
+		case NewsSortPublishedAtAsc:
+			return query.ListNewsOrderByPublishedAsc(ctx, onefeed_th_sqlc.ListNewsOrderByPublishedAscParams(params))
+		case NewsSortFetchedAtDesc:
+			return query.ListNewsOrderByFetchedAt(ctx, onefeed_th_sqlc.ListNewsOrderByFetchedAtParams(params))
+		case NewsSortSourceAsc:
+			return query.ListNewsOrderBySource(ctx, onefeed_th_sqlc.ListNewsOrderBySourceParams(params))
+		default:
+			return query.ListNews(ctx, params)
+		}
+	})
+}
+
+func (r *NewsRepositoryImpl) GetNewsBeforeCursor(ctx context.Context, params onefeed_th_sqlc.ListNewsBeforeCursorParams) ([]onefeed_th_sqlc.News, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) ([]onefeed_th_sqlc.News, error) {
+		query := onefeed_th_sqlc.New(r.readPool)
+		return query.ListNewsBeforeCursor(ctx, params)
+	})
+}
+
+func (r *NewsRepositoryImpl) RemoveNewsByPublishedDate(ctx context.Context, before pgtype.Timestamp) (int64, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := onefeed_th_sqlc.New(r.pool).WithTx(tx)
+	removed, err := query.RemoveNewsByPublishedDate(ctx, before)
+	if err != nil {
+		return 0, err
+	}
+	// the links are released with their news so they can be collected again
+	if _, err := query.RemoveNewsLinksByPublishedDate(ctx, before); err != nil {
+		return 0, err
+	}
+	return removed, tx.Commit(ctx)
 }
 
 func (r *NewsRepositoryImpl) GetAllSource(ctx context.Context) ([]string, error) {
-	query := onefeed_th_sqlc.New(r.pool)
-	return query.GetAllSource(ctx)
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) ([]string, error) {
+		query := onefeed_th_sqlc.New(r.readPool)
+		return query.GetAllSource(ctx)
+	})
 }
 
 func (r *NewsRepositoryImpl) GetAllMissingLinks(ctx context.Context, links []string) ([]string, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) ([]string, error) {
+		query := onefeed_th_sqlc.New(r.pool)
+		return query.GetAllMissingLinks(ctx, links)
+	})
+}
+
+func (r *NewsRepositoryImpl) GetNewsByID(ctx context.Context, id int64) (onefeed_th_sqlc.News, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) (onefeed_th_sqlc.News, error) {
+		query := onefeed_th_sqlc.New(r.pool)
+		return query.GetNewsByID(ctx, id)
+	})
+}
+
+func (r *NewsRepositoryImpl) GetRelatedNewsBySource(ctx context.Context, params onefeed_th_sqlc.ListRelatedNewsBySourceParams) ([]onefeed_th_sqlc.News, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) ([]onefeed_th_sqlc.News, error) {
+		query := onefeed_th_sqlc.New(r.pool)
+		return query.ListRelatedNewsBySource(ctx, params)
+	})
+}
+
+func (r *NewsRepositoryImpl) GetNewsByIDs(ctx context.Context, ids []int64) ([]onefeed_th_sqlc.News, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) ([]onefeed_th_sqlc.News, error) {
+		query := onefeed_th_sqlc.New(r.pool)
+		return query.ListNewsByIDs(ctx, ids)
+	})
+}
+
+// GetNewsByLinks reads from the primary because it runs right after an insert, which a
+// replica may not have applied yet
+func (r *NewsRepositoryImpl) GetNewsByLinks(ctx context.Context, links []string) ([]onefeed_th_sqlc.News, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) ([]onefeed_th_sqlc.News, error) {
+		query := onefeed_th_sqlc.New(r.pool)
+		return query.ListNewsByLinks(ctx, links)
+	})
+}
+
+func (r *NewsRepositoryImpl) GetSimilarNews(ctx context.Context, params onefeed_th_sqlc.ListSimilarNewsParams) ([]onefeed_th_sqlc.News, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) ([]onefeed_th_sqlc.News, error) {
+		query := onefeed_th_sqlc.New(r.pool)
+		return query.ListSimilarNews(ctx, params)
+	})
+}
+
+func (r *NewsRepositoryImpl) GetLatestNewsPerSource(ctx context.Context, params onefeed_th_sqlc.ListLatestNewsPerSourceParams) ([]onefeed_th_sqlc.News, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := withRetry(ctx, func(ctx context.Context) ([]onefeed_th_sqlc.ListLatestNewsPerSourceRow, error) {
+		query := onefeed_th_sqlc.New(r.pool)
+		return query.ListLatestNewsPerSource(ctx, params)
+	})
+	if err != nil {
+		return nil, err
+	}
+	news := make([]onefeed_th_sqlc.News, 0, len(rows))
+	for _, row := range rows {
+		news = append(news, onefeed_th_sqlc.News(row))
+	}
+	return news, nil
+}
+
+func (r *NewsRepositoryImpl) CountNews(ctx context.Context, sources []string) (int64, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) (int64, error) {
+		query := onefeed_th_sqlc.New(r.readPool)
+		return query.CountNews(ctx, sources)
+	})
+}
+
+func (r *NewsRepositoryImpl) GetSourceNewsCounts(ctx context.Context, fetchedSince pgtype.Timestamp) ([]onefeed_th_sqlc.ListSourceNewsCountsRow, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) ([]onefeed_th_sqlc.ListSourceNewsCountsRow, error) {
+		query := onefeed_th_sqlc.New(r.readPool)
+		return query.ListSourceNewsCounts(ctx, fetchedSince)
+	})
+}
+
+func (r *NewsRepositoryImpl) SearchNews(ctx context.Context, params onefeed_th_sqlc.SearchNewsParams) ([]onefeed_th_sqlc.News, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) ([]onefeed_th_sqlc.News, error) {
+		query := onefeed_th_sqlc.New(r.readPool)
+		return query.SearchNews(ctx, params)
+	})
+}
+
+func (r *NewsRepositoryImpl) CountSearchNews(ctx context.Context, params onefeed_th_sqlc.CountSearchNewsParams) (int64, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) (int64, error) {
+		query := onefeed_th_sqlc.New(r.readPool)
+		return query.CountSearchNews(ctx, params)
+	})
+}
+
+// GetNewsAfterID walks every visible item in id order, e.g. to rebuild the search index
+func (r *NewsRepositoryImpl) GetNewsAfterID(ctx context.Context, params onefeed_th_sqlc.ListNewsAfterIDParams) ([]onefeed_th_sqlc.News, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) ([]onefeed_th_sqlc.News, error) {
+		query := onefeed_th_sqlc.New(r.readPool)
+		return query.ListNewsAfterID(ctx, params)
+	})
+}
+
+func (r *NewsRepositoryImpl) GetNewsForExport(ctx context.Context, params onefeed_th_sqlc.ListNewsForExportParams) ([]onefeed_th_sqlc.News, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) ([]onefeed_th_sqlc.News, error) {
+		query := onefeed_th_sqlc.New(r.pool)
+		return query.ListNewsForExport(ctx, params)
+	})
+}
+
+func (r *NewsRepositoryImpl) GetRandomRecentNews(ctx context.Context, params onefeed_th_sqlc.ListRandomRecentNewsParams) ([]onefeed_th_sqlc.News, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) ([]onefeed_th_sqlc.News, error) {
+		query := onefeed_th_sqlc.New(r.pool)
+		return query.ListRandomRecentNews(ctx, params)
+	})
+}
+
+// SetNewsSummary stores the generated summary of a news item
+func (r *NewsRepositoryImpl) SetNewsSummary(ctx context.Context, params onefeed_th_sqlc.SetNewsSummaryParams) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := onefeed_th_sqlc.New(r.pool)
+	return query.SetNewsSummary(ctx, params)
+}
+
+// NotifyNewsCreated publishes the ids of newly collected news on the news_created channel
+func (r *NewsRepositoryImpl) NotifyNewsCreated(ctx context.Context, payload string) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
 	query := onefeed_th_sqlc.New(r.pool)
-	return query.GetAllMissingLinks(ctx, links)
+	return query.NotifyNewsCreated(ctx, payload)
 }
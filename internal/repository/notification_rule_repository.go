@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	onefeed_th_sqlc "github.com/onefeed-th/onefeed-th-backend-api/internal/sqlc/onefeed_th_sqlc/db"
+)
+
+type NotificationRuleRepository interface {
+	CreateNotificationRule(ctx context.Context, params onefeed_th_sqlc.CreateNotificationRuleParams) (onefeed_th_sqlc.NotificationRule, error)
+	DeleteNotificationRule(ctx context.Context, id int64) (int64, error)
+	GetEnabledNotificationRules(ctx context.Context) ([]onefeed_th_sqlc.NotificationRule, error)
+	GetNotificationRuleByID(ctx context.Context, id int64) (onefeed_th_sqlc.NotificationRule, error)
+	GetNotificationRules(ctx context.Context) ([]onefeed_th_sqlc.NotificationRule, error)
+	UpdateNotificationRule(ctx context.Context, params onefeed_th_sqlc.UpdateNotificationRuleParams) (onefeed_th_sqlc.NotificationRule, error)
+}
+
+type NotificationRuleRepositoryImpl struct {
+	pool dbPool
+}
+
+func NewNotificationRuleRepository(pool func() *pgxpool.Pool) NotificationRuleRepository {
+	return &NotificationRuleRepositoryImpl{
+		pool: pool,
+	}
+}
+
+func (r *NotificationRuleRepositoryImpl) CreateNotificationRule(ctx context.Context, params onefeed_th_sqlc.CreateNotificationRuleParams) (onefeed_th_sqlc.NotificationRule, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := onefeed_th_sqlc.New(r.pool)
+	return query.CreateNotificationRule(ctx, params)
+}
+
+func (r *NotificationRuleRepositoryImpl) DeleteNotificationRule(ctx context.Context, id int64) (int64, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := onefeed_th_sqlc.New(r.pool)
+	return query.DeleteNotificationRule(ctx, id)
+}
+
+func (r *NotificationRuleRepositoryImpl) GetEnabledNotificationRules(ctx context.Context) ([]onefeed_th_sqlc.NotificationRule, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) ([]onefeed_th_sqlc.NotificationRule, error) {
+		query := onefeed_th_sqlc.New(r.pool)
+		return query.ListEnabledNotificationRules(ctx)
+	})
+}
+
+func (r *NotificationRuleRepositoryImpl) GetNotificationRuleByID(ctx context.Context, id int64) (onefeed_th_sqlc.NotificationRule, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) (onefeed_th_sqlc.NotificationRule, error) {
+		query := onefeed_th_sqlc.New(r.pool)
+		return query.GetNotificationRuleByID(ctx, id)
+	})
+}
+
+func (r *NotificationRuleRepositoryImpl) GetNotificationRules(ctx context.Context) ([]onefeed_th_sqlc.NotificationRule, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) ([]onefeed_th_sqlc.NotificationRule, error) {
+		query := onefeed_th_sqlc.New(r.pool)
+		return query.ListNotificationRules(ctx)
+	})
+}
+
+func (r *NotificationRuleRepositoryImpl) UpdateNotificationRule(ctx context.Context, params onefeed_th_sqlc.UpdateNotificationRuleParams) (onefeed_th_sqlc.NotificationRule, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := onefeed_th_sqlc.New(r.pool)
+	return query.UpdateNotificationRule(ctx, params)
+}
@@ -0,0 +1,328 @@
+package repository_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/repository"
+	onefeed_th_sqlc "github.com/onefeed-th/onefeed-th-backend-api/internal/sqlc/onefeed_th_sqlc/db"
+)
+
+func TestAPIKeys(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repo *repository.Repository) {
+		ctx := context.Background()
+		create := func(name, hash string) onefeed_th_sqlc.ApiKey {
+			return must(repo.APIKeyRepository.CreateAPIKey(ctx, onefeed_th_sqlc.CreateApiKeyParams{
+				Name: name, KeyPrefix: hash[:4], KeyHash: hash, Role: "editor",
+			}))(t)
+		}
+		first := create("collector", "hash-1")
+		second := create("dashboard", "hash-2")
+		_, err := repo.APIKeyRepository.CreateAPIKey(ctx, onefeed_th_sqlc.CreateApiKeyParams{Name: "copy", KeyPrefix: "hash", KeyHash: "hash-1", Role: "viewer"})
+		expectUniqueViolation(t, err)
+
+		keys := must(repo.APIKeyRepository.GetAPIKeys(ctx))(t)
+		expectEqual(t, "keys", len(keys), 2)
+		expectEqual(t, "active", must(repo.APIKeyRepository.GetActiveAPIKeyByHash(ctx, "hash-2"))(t).ID, second.ID)
+
+		expectEqual(t, "revoked", must(repo.APIKeyRepository.RevokeAPIKey(ctx, first.ID))(t), 1)
+		expectEqual(t, "revoked again", must(repo.APIKeyRepository.RevokeAPIKey(ctx, first.ID))(t), 0)
+		_, err = repo.APIKeyRepository.GetActiveAPIKeyByHash(ctx, "hash-1")
+		expectNoRows(t, err)
+		// revoked keys stay listed for the audit trail
+		expectEqual(t, "keys after revoke", len(must(repo.APIKeyRepository.GetAPIKeys(ctx))(t)), 2)
+	})
+}
+
+func TestBackofficeUsers(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repo *repository.Repository) {
+		ctx := context.Background()
+		create := func(username, role string) onefeed_th_sqlc.BackofficeUser {
+			return must(repo.BackofficeUserRepository.CreateBackofficeUser(ctx, onefeed_th_sqlc.CreateBackofficeUserParams{
+				Username: username, PasswordHash: "hash", Role: role,
+			}))(t)
+		}
+		editor := create("somsri", "editor")
+		admin := create("anan", "admin")
+		_, err := repo.BackofficeUserRepository.CreateBackofficeUser(ctx, onefeed_th_sqlc.CreateBackofficeUserParams{Username: "somsri", PasswordHash: "hash", Role: "viewer"})
+		expectUniqueViolation(t, err)
+
+		userID := func(user onefeed_th_sqlc.BackofficeUser) int64 { return user.ID }
+		expectIDs(t, "by username", ids(must(repo.BackofficeUserRepository.GetBackofficeUsers(ctx))(t), userID), []int64{admin.ID, editor.ID})
+		expectEqual(t, "by id", must(repo.BackofficeUserRepository.GetBackofficeUserByID(ctx, editor.ID))(t).Username, "somsri")
+		expectEqual(t, "by name", must(repo.BackofficeUserRepository.GetBackofficeUserByUsername(ctx, "anan"))(t).ID, admin.ID)
+		_, err = repo.BackofficeUserRepository.GetBackofficeUserByUsername(ctx, "nobody")
+		expectNoRows(t, err)
+
+		updated := must(repo.BackofficeUserRepository.UpdateBackofficeUser(ctx, onefeed_th_sqlc.UpdateBackofficeUserParams{Role: "viewer", Disabled: true, ID: editor.ID}))(t)
+		expectEqual(t, "role", updated.Role, "viewer")
+		expectEqual(t, "disabled", updated.Disabled, true)
+		expectEqual(t, "updated at", updated.UpdatedAt.Valid, true)
+		_, err = repo.BackofficeUserRepository.UpdateBackofficeUser(ctx, onefeed_th_sqlc.UpdateBackofficeUserParams{Role: "viewer", ID: 999_999})
+		expectNoRows(t, err)
+	})
+}
+
+func TestWebhooks(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repo *repository.Repository) {
+		ctx := context.Background()
+		create := func(url string) onefeed_th_sqlc.Webhook {
+			return must(repo.WebhookRepository.CreateWebhook(ctx, onefeed_th_sqlc.CreateWebhookParams{
+				Url: url, Secret: "secret", Sources: []string{"thairath"}, Tags: []string{},
+			}))(t)
+		}
+		first := create("https://hooks.example.com/1")
+		second := create("https://hooks.example.com/2")
+		hookID := func(hook onefeed_th_sqlc.Webhook) int64 { return hook.ID }
+
+		updated := must(repo.WebhookRepository.UpdateWebhook(ctx, onefeed_th_sqlc.UpdateWebhookParams{
+			Url: "https://hooks.example.com/one", Sources: []string{}, Tags: []string{"news"}, Disabled: true, ID: first.ID,
+		}))(t)
+		expectEqual(t, "url", updated.Url, "https://hooks.example.com/one")
+		expectEqual(t, "secret kept", updated.Secret, "secret")
+		expectEqual(t, "tags", slices.Equal(updated.Tags, []string{"news"}), true)
+		_, err := repo.WebhookRepository.UpdateWebhook(ctx, onefeed_th_sqlc.UpdateWebhookParams{Url: "x", Sources: []string{}, Tags: []string{}, ID: 999_999})
+		expectNoRows(t, err)
+
+		expectIDs(t, "webhooks", ids(must(repo.WebhookRepository.GetWebhooks(ctx))(t), hookID), []int64{first.ID, second.ID})
+		expectIDs(t, "enabled", ids(must(repo.WebhookRepository.GetEnabledWebhooks(ctx))(t), hookID), []int64{second.ID})
+		expectEqual(t, "by id", must(repo.WebhookRepository.GetWebhookByID(ctx, second.ID))(t).Url, "https://hooks.example.com/2")
+
+		for attempts := int32(1); attempts <= 3; attempts++ {
+			mustDo(t, repo.WebhookRepository.CreateWebhookFailure(ctx, onefeed_th_sqlc.CreateWebhookFailureParams{
+				WebhookID: first.ID, Payload: []byte(`{}`), Attempts: attempts, StatusCode: pgtype.Int4{Int32: 500, Valid: true}, Error: "server error",
+			}))
+		}
+		failures := must(repo.WebhookRepository.GetWebhookFailures(ctx, onefeed_th_sqlc.ListWebhookFailuresParams{WebhookID: first.ID, PageLimit: 2}))(t)
+		expectEqual(t, "failures", len(failures), 2)
+		expectEqual(t, "latest failure first", failures[0].Attempts, 3)
+		expectEqual(t, "other failures", len(must(repo.WebhookRepository.GetWebhookFailures(ctx, onefeed_th_sqlc.ListWebhookFailuresParams{WebhookID: second.ID, PageLimit: 10}))(t)), 0)
+
+		// failures go with their webhook
+		expectEqual(t, "deleted", must(repo.WebhookRepository.DeleteWebhook(ctx, first.ID))(t), 1)
+		expectEqual(t, "deleted again", must(repo.WebhookRepository.DeleteWebhook(ctx, first.ID))(t), 0)
+		_, err = repo.WebhookRepository.GetWebhookByID(ctx, first.ID)
+		expectNoRows(t, err)
+		expectEqual(t, "failures after delete", len(must(repo.WebhookRepository.GetWebhookFailures(ctx, onefeed_th_sqlc.ListWebhookFailuresParams{WebhookID: first.ID, PageLimit: 10}))(t)), 0)
+	})
+}
+
+func TestNotificationRules(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repo *repository.Repository) {
+		ctx := context.Background()
+		create := func(name string) onefeed_th_sqlc.NotificationRule {
+			return must(repo.NotificationRuleRepository.CreateNotificationRule(ctx, onefeed_th_sqlc.CreateNotificationRuleParams{
+				Name: name, Channel: "telegram", Target: "chat-1", Keywords: []string{"flood"}, Sources: []string{}, Tags: []string{},
+			}))(t)
+		}
+		first := create("floods")
+		second := create("elections")
+		ruleID := func(rule onefeed_th_sqlc.NotificationRule) int64 { return rule.ID }
+
+		updated := must(repo.NotificationRuleRepository.UpdateNotificationRule(ctx, onefeed_th_sqlc.UpdateNotificationRuleParams{
+			Name: "all floods", Target: "chat-2", Keywords: []string{}, Sources: []string{"thairath"}, Tags: []string{}, Template: "{{.Title}}", Disabled: true, ID: first.ID,
+		}))(t)
+		expectEqual(t, "name", updated.Name, "all floods")
+		expectEqual(t, "channel kept", updated.Channel, "telegram")
+		expectEqual(t, "template", updated.Template, "{{.Title}}")
+		_, err := repo.NotificationRuleRepository.UpdateNotificationRule(ctx, onefeed_th_sqlc.UpdateNotificationRuleParams{
+			Name: "x", Keywords: []string{}, Sources: []string{}, Tags: []string{}, ID: 999_999,
+		})
+		expectNoRows(t, err)
+
+		expectIDs(t, "rules", ids(must(repo.NotificationRuleRepository.GetNotificationRules(ctx))(t), ruleID), []int64{first.ID, second.ID})
+		expectIDs(t, "enabled", ids(must(repo.NotificationRuleRepository.GetEnabledNotificationRules(ctx))(t), ruleID), []int64{second.ID})
+		expectEqual(t, "by id", must(repo.NotificationRuleRepository.GetNotificationRuleByID(ctx, second.ID))(t).Name, "elections")
+
+		expectEqual(t, "deleted", must(repo.NotificationRuleRepository.DeleteNotificationRule(ctx, first.ID))(t), 1)
+		expectEqual(t, "deleted again", must(repo.NotificationRuleRepository.DeleteNotificationRule(ctx, first.ID))(t), 0)
+		_, err = repo.NotificationRuleRepository.GetNotificationRuleByID(ctx, first.ID)
+		expectNoRows(t, err)
+	})
+}
+
+func TestPush(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repo *repository.Repository) {
+		ctx := context.Background()
+		news := insertNews(t, repo, newsItem("a", "thairath", "Flood warning", day(-1)))
+		upsert := func(token, platform string) onefeed_th_sqlc.PushDevice {
+			return must(repo.PushRepository.UpsertPushDevice(ctx, onefeed_th_sqlc.UpsertPushDeviceParams{
+				Token: token, Platform: platform, Sources: []string{}, Keywords: []string{},
+			}))(t)
+		}
+		phone := upsert("token-1", "android")
+		tablet := upsert("token-2", "ios")
+		// registering a known token again updates the device
+		again := upsert("token-1", "web")
+		expectEqual(t, "same device", again.ID, phone.ID)
+		expectEqual(t, "platform", again.Platform, "web")
+		deviceID := func(device onefeed_th_sqlc.PushDevice) int64 { return device.ID }
+		expectIDs(t, "devices", ids(must(repo.PushRepository.GetPushDevices(ctx))(t), deviceID), []int64{phone.ID, tablet.ID})
+
+		mustDo(t, repo.PushRepository.CreatePushJobs(ctx, onefeed_th_sqlc.CreatePushJobsParams{
+			DeviceIds: []int64{phone.ID, tablet.ID}, NewsIds: []int64{news["a"].ID, news["a"].ID},
+		}))
+		// a day ahead, so the jobs are due whatever the time zone of the database
+		now := time.Now().AddDate(0, 0, 1)
+		claim := func(at time.Time, limit int32) []onefeed_th_sqlc.ClaimPushJobsRow {
+			return must(repo.PushRepository.ClaimPushJobs(ctx, onefeed_th_sqlc.ClaimPushJobsParams{
+				LeaseUntil: timestamp(at.Add(time.Minute)), Now: timestamp(at), PageLimit: limit,
+			}))(t)
+		}
+		claimed := claim(now, 1)
+		expectEqual(t, "claimed", len(claimed), 1)
+		expectEqual(t, "token", claimed[0].Token, "token-1")
+		expectEqual(t, "title", claimed[0].Title, "Flood warning")
+		expectEqual(t, "attempts", claimed[0].Attempts, 1)
+		// a leased job is not claimed again until its lease runs out
+		next := claim(now, 10)
+		expectEqual(t, "claimed next", len(next), 1)
+		expectEqual(t, "next token", next[0].Token, "token-2")
+		expectEqual(t, "nothing due", len(claim(now, 10)), 0)
+
+		mustDo(t, repo.PushRepository.RetryPushJob(ctx, onefeed_th_sqlc.RetryPushJobParams{LastError: text("unavailable"), RunAt: timestamp(now.Add(time.Hour)), ID: claimed[0].ID}))
+		mustDo(t, repo.PushRepository.DeletePushJob(ctx, next[0].ID))
+		expectEqual(t, "retry not due", len(claim(now.Add(30*time.Minute), 10)), 0)
+		retried := claim(now.Add(2*time.Hour), 10)
+		expectEqual(t, "retried", len(retried), 1)
+		expectEqual(t, "retried attempts", retried[0].Attempts, 2)
+
+		// deleting a device drops its jobs
+		expectEqual(t, "deleted by token", must(repo.PushRepository.DeletePushDeviceByToken(ctx, "token-1"))(t), 1)
+		expectEqual(t, "deleted by token again", must(repo.PushRepository.DeletePushDeviceByToken(ctx, "token-1"))(t), 0)
+		expectEqual(t, "jobs after delete", len(claim(now.Add(4*time.Hour), 10)), 0)
+		mustDo(t, repo.PushRepository.DeletePushDeviceByID(ctx, tablet.ID))
+		expectEqual(t, "devices after delete", len(must(repo.PushRepository.GetPushDevices(ctx))(t)), 0)
+	})
+}
+
+func TestJobs(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repo *repository.Repository) {
+		ctx := context.Background()
+		now := time.Now()
+		create := func(jobType string, at time.Time, unique bool) onefeed_th_sqlc.Job {
+			return must(repo.JobRepository.CreateJob(ctx, onefeed_th_sqlc.CreateJobParams{
+				Type: jobType, Payload: []byte(`{}`), MaxAttempts: 3, ScheduledAt: timestamp(at), IsUnique: unique,
+			}))(t)
+		}
+		claim := func(at time.Time, limit int32) []onefeed_th_sqlc.Job {
+			return must(repo.JobRepository.ClaimJobs(ctx, onefeed_th_sqlc.ClaimJobsParams{
+				Now: timestamp(at), LeaseUntil: timestamp(at.Add(time.Minute)), PageLimit: limit,
+			}))(t)
+		}
+		jobID := func(job onefeed_th_sqlc.Job) int64 { return job.ID }
+
+		later := create("collect", now.Add(-time.Minute), true)
+		earlier := create("retention", now.Add(-time.Hour), true)
+		future := create("backfill", now.Add(time.Hour), false)
+		// a unique type with a pending job returns that job, only ever moved earlier
+		expectEqual(t, "queued once", create("collect", now.Add(time.Hour), true).ID, later.ID)
+		moved := create("collect", now.Add(-2*time.Minute), true)
+		expectEqual(t, "queued once again", moved.ID, later.ID)
+		expectEqual(t, "moved up", moved.ScheduledAt.Time.Before(now.Add(-time.Minute)), true)
+
+		// due jobs are claimed in the order they were scheduled
+		claimed := claim(now, 10)
+		expectIDs(t, "claimed", ids(claimed, jobID), []int64{earlier.ID, later.ID})
+		expectEqual(t, "status", claimed[0].Status, "running")
+		expectEqual(t, "attempts", claimed[0].Attempts, 1)
+		expectEqual(t, "nothing due", len(claim(now, 10)), 0)
+		// jobs whose lease ran out are claimed again, ties broken by id
+		expired := claim(now.Add(2*time.Minute), 10)
+		expectIDs(t, "expired lease", ids(expired, jobID), []int64{later.ID, earlier.ID})
+		expectEqual(t, "attempts again", expired[0].Attempts, 2)
+
+		// only the worker holding the current lease extends or settles it
+		expectEqual(t, "stale extend", must(repo.JobRepository.ExtendJobLease(ctx, onefeed_th_sqlc.ExtendJobLeaseParams{LeaseUntil: timestamp(now.Add(time.Hour)), ID: later.ID, Attempts: 1}))(t), 0)
+		expectEqual(t, "extended", must(repo.JobRepository.ExtendJobLease(ctx, onefeed_th_sqlc.ExtendJobLeaseParams{LeaseUntil: timestamp(now.Add(time.Hour)), ID: later.ID, Attempts: 2}))(t), 1)
+		expectEqual(t, "extended lease kept", len(claim(now.Add(150*time.Second), 10)), 0)
+		expectEqual(t, "stale complete", must(repo.JobRepository.CompleteJob(ctx, onefeed_th_sqlc.CompleteJobParams{ID: later.ID, Attempts: 1}))(t), 0)
+		expectEqual(t, "completed", must(repo.JobRepository.CompleteJob(ctx, onefeed_th_sqlc.CompleteJobParams{Result: []byte(`{"collected":3}`), ID: later.ID, Attempts: 2}))(t), 1)
+		expectEqual(t, "completed once", must(repo.JobRepository.CompleteJob(ctx, onefeed_th_sqlc.CompleteJobParams{ID: later.ID, Attempts: 2}))(t), 0)
+		expectEqual(t, "stale fail", must(repo.JobRepository.FailJob(ctx, onefeed_th_sqlc.FailJobParams{LastError: text("timeout"), ID: earlier.ID, Attempts: 1}))(t), 0)
+		expectEqual(t, "failed", must(repo.JobRepository.FailJob(ctx, onefeed_th_sqlc.FailJobParams{LastError: text("timeout"), ID: earlier.ID, Attempts: 2}))(t), 1)
+		expectEqual(t, "succeeded", must(repo.JobRepository.GetJobByID(ctx, later.ID))(t).Status, "succeeded")
+		failed := must(repo.JobRepository.GetJobByID(ctx, earlier.ID))(t)
+		expectEqual(t, "failed status", failed.Status, "failed")
+		expectEqual(t, "error", failed.LastError, text("timeout"))
+		expectEqual(t, "finished", failed.FinishedAt.Valid, true)
+
+		expectIDs(t, "listed", ids(must(repo.JobRepository.GetJobs(ctx, onefeed_th_sqlc.ListJobsParams{PageLimit: 10}))(t), jobID), []int64{future.ID, earlier.ID, later.ID})
+		expectIDs(t, "by type", ids(must(repo.JobRepository.GetJobs(ctx, onefeed_th_sqlc.ListJobsParams{Type: "collect", PageLimit: 10}))(t), jobID), []int64{later.ID})
+		expectIDs(t, "by status", ids(must(repo.JobRepository.GetJobs(ctx, onefeed_th_sqlc.ListJobsParams{Status: "failed", PageLimit: 10}))(t), jobID), []int64{earlier.ID})
+
+		// only failed jobs are requeued, starting over, and not while their type is pending again
+		_, err := repo.JobRepository.RequeueJob(ctx, onefeed_th_sqlc.RequeueJobParams{ScheduledAt: timestamp(now), ID: later.ID})
+		expectNoRows(t, err)
+		blocking := create("retention", now, true)
+		_, err = repo.JobRepository.RequeueJob(ctx, onefeed_th_sqlc.RequeueJobParams{ScheduledAt: timestamp(now), ID: earlier.ID})
+		expectUniqueViolation(t, err)
+		expectIDs(t, "blocking", ids(claim(now, 10), jobID), []int64{blocking.ID})
+		expectEqual(t, "blocking completed", must(repo.JobRepository.CompleteJob(ctx, onefeed_th_sqlc.CompleteJobParams{ID: blocking.ID, Attempts: 1}))(t), 1)
+		requeued := must(repo.JobRepository.RequeueJob(ctx, onefeed_th_sqlc.RequeueJobParams{ScheduledAt: timestamp(now), ID: earlier.ID}))(t)
+		expectEqual(t, "requeued", requeued.Status, "pending")
+		expectEqual(t, "requeued attempts", requeued.Attempts, 0)
+		expectEqual(t, "requeued error", requeued.LastError.Valid, false)
+
+		retrying := claim(now.Add(2*time.Hour), 10)
+		expectIDs(t, "retrying", ids(retrying, jobID), []int64{earlier.ID, future.ID})
+
+		// a unique job isn't retried while another of its type is pending
+		queued := create("retention", now, true)
+		_, err = repo.JobRepository.RetryJob(ctx, onefeed_th_sqlc.RetryJobParams{LastError: text("busy"), ScheduledAt: timestamp(now.Add(3 * time.Hour)), ID: earlier.ID, Attempts: 1})
+		expectUniqueViolation(t, err)
+		expectIDs(t, "queued", ids(claim(now, 10), jobID), []int64{queued.ID})
+		expectEqual(t, "queued completed", must(repo.JobRepository.CompleteJob(ctx, onefeed_th_sqlc.CompleteJobParams{ID: queued.ID, Attempts: 1}))(t), 1)
+		expectEqual(t, "failed instead", must(repo.JobRepository.FailJob(ctx, onefeed_th_sqlc.FailJobParams{LastError: text("busy"), ID: earlier.ID, Attempts: 1}))(t), 1)
+
+		expectEqual(t, "stale retry", must(repo.JobRepository.RetryJob(ctx, onefeed_th_sqlc.RetryJobParams{LastError: text("busy"), ScheduledAt: timestamp(now.Add(3 * time.Hour)), ID: future.ID, Attempts: 2}))(t), 0)
+		expectEqual(t, "retry", must(repo.JobRepository.RetryJob(ctx, onefeed_th_sqlc.RetryJobParams{LastError: text("busy"), ScheduledAt: timestamp(now.Add(3 * time.Hour)), ID: future.ID, Attempts: 1}))(t), 1)
+		retried := must(repo.JobRepository.GetJobByID(ctx, future.ID))(t)
+		expectEqual(t, "retried", retried.Status, "pending")
+		expectEqual(t, "retried attempts", retried.Attempts, 1)
+		expectEqual(t, "retry not due", len(claim(now.Add(150*time.Minute), 10)), 0)
+
+		expectEqual(t, "nothing finished that long ago", must(repo.JobRepository.DeleteJobsFinishedBefore(ctx, now.AddDate(0, 0, -2)))(t), 0)
+		expectEqual(t, "finished deleted", must(repo.JobRepository.DeleteJobsFinishedBefore(ctx, now.AddDate(0, 0, 2)))(t), 4)
+		_, err = repo.JobRepository.GetJobByID(ctx, later.ID)
+		expectNoRows(t, err)
+	})
+}
+
+func TestDeadLetters(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repo *repository.Repository) {
+		ctx := context.Background()
+		thairath := createSource(t, repo, "thairath", "news")
+		matichon := createSource(t, repo, "matichon", "news")
+		upsert := func(sourceID int64, key, reason string) {
+			mustDo(t, repo.DeadLetterRepository.UpsertDeadLetter(ctx, onefeed_th_sqlc.UpsertDeadLetterParams{
+				SourceID: sourceID, ItemKey: key, Link: text("https://example.com/" + key), Payload: []byte(`{}`), Error: reason,
+			}))
+		}
+		upsert(thairath.ID, "item-1", "missing title")
+		upsert(matichon.ID, "item-1", "missing title")
+		// failing again updates the entry of the item
+		upsert(thairath.ID, "item-1", "bad date")
+		letterID := func(letter onefeed_th_sqlc.DeadLetter) int64 { return letter.ID }
+
+		letters := must(repo.DeadLetterRepository.GetDeadLetters(ctx, onefeed_th_sqlc.ListDeadLettersParams{PageLimit: 10}))(t)
+		expectEqual(t, "letters", len(letters), 2)
+		bySource := must(repo.DeadLetterRepository.GetDeadLetters(ctx, onefeed_th_sqlc.ListDeadLettersParams{SourceID: thairath.ID, PageLimit: 10}))(t)
+		expectEqual(t, "by source", len(bySource), 1)
+		letter := bySource[0]
+		expectEqual(t, "occurrences", letter.Occurrences, 2)
+		expectEqual(t, "error", letter.Error, "bad date")
+		expectIDs(t, "newest first", ids(letters, letterID), []int64{letters[0].ID, letter.ID})
+
+		mustDo(t, repo.DeadLetterRepository.UpdateDeadLetterError(ctx, onefeed_th_sqlc.UpdateDeadLetterErrorParams{Error: "still bad", ID: letter.ID}))
+		expectEqual(t, "updated error", must(repo.DeadLetterRepository.GetDeadLetterByID(ctx, letter.ID))(t).Error, "still bad")
+
+		expectEqual(t, "deleted", must(repo.DeadLetterRepository.DeleteDeadLetter(ctx, letter.ID))(t), 1)
+		expectEqual(t, "deleted again", must(repo.DeadLetterRepository.DeleteDeadLetter(ctx, letter.ID))(t), 0)
+		_, err := repo.DeadLetterRepository.GetDeadLetterByID(ctx, letter.ID)
+		expectNoRows(t, err)
+	})
+}
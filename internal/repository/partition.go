@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const partitionMonthLayout = "2006_01"
+
+// monthStart truncates t to the first instant of its month using the wall clock,
+// matching how pgx stores time.Time into TIMESTAMP columns
+func monthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// ensureMonthlyPartition creates the <parent>_YYYY_MM partition covering month. Postgres
+// refuses to create a partition for rows <parent>_default already holds, so the partition is
+// built detached, those rows are moved into it and it is attached afterwards
+func ensureMonthlyPartition(ctx context.Context, tx pgx.Tx, parent string, month time.Time) error {
+	start := monthStart(month)
+	end := start.AddDate(0, 1, 0)
+	name := parent + "_" + start.Format(partitionMonthLayout)
+
+	var exists bool
+	if err := tx.QueryRow(ctx, "SELECT to_regclass($1) IS NOT NULL", name).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to look up partition %s: %w", name, err)
+	}
+	if exists {
+		return nil
+	}
+
+	partition := pgx.Identifier{name}.Sanitize()
+	defaultPartition := pgx.Identifier{parent + "_default"}.Sanitize()
+	from, to := start.Format("2006-01-02"), end.Format("2006-01-02")
+	stmts := []string{
+		fmt.Sprintf(`CREATE TABLE %s (LIKE %s INCLUDING DEFAULTS INCLUDING CONSTRAINTS)`, partition, pgx.Identifier{parent}.Sanitize()),
+		fmt.Sprintf(`WITH moved AS (
+  DELETE FROM %s
+  WHERE publish_date >= '%s'
+    AND publish_date < '%s'
+  RETURNING *
+)
+INSERT INTO %s
+SELECT *
+FROM moved`, defaultPartition, from, to, partition),
+		fmt.Sprintf(`ALTER TABLE %s ATTACH PARTITION %s FOR VALUES FROM ('%s') TO ('%s')`, pgx.Identifier{parent}.Sanitize(), partition, from, to),
+	}
+	for _, stmt := range stmts {
+		if _, err := tx.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to create %s partition for %s: %w", parent, start.Format("2006-01"), err)
+		}
+	}
+	return nil
+}
+
+// listDefaultPartitionMonths returns the months of the rows that landed in <parent>_default
+// because their partition didn't exist yet
+func listDefaultPartitionMonths(ctx context.Context, tx pgx.Tx, parent string) ([]time.Time, error) {
+	rows, err := tx.Query(ctx, fmt.Sprintf(`SELECT DISTINCT date_trunc('month', publish_date)::TIMESTAMP
+FROM %s
+WHERE publish_date IS NOT NULL`, pgx.Identifier{parent + "_default"}.Sanitize()))
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[time.Time])
+}
+
+// listMonthlyPartitionsBefore returns the <parent>_YYYY_MM partitions whose whole
+// range ends on or before cutoff; the default partition is never included
+func listMonthlyPartitionsBefore(ctx context.Context, tx pgx.Tx, parent string, cutoff time.Time) ([]string, error) {
+	rows, err := tx.Query(ctx, `SELECT c.relname
+FROM pg_inherits i
+  JOIN pg_class c ON c.oid = i.inhrelid
+WHERE i.inhparent = $1::regclass
+  AND c.relname ~ ('^' || $1 || '_[0-9]{4}_[0-9]{2}$')`, parent)
+	if err != nil {
+		return nil, err
+	}
+	names, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, err
+	}
+
+	expired := make([]string, 0, len(names))
+	for _, name := range names {
+		month, err := time.Parse(partitionMonthLayout, name[len(parent)+1:])
+		if err != nil {
+			continue
+		}
+		if !month.AddDate(0, 1, 0).After(cutoff) {
+			expired = append(expired, name)
+		}
+	}
+	return expired, nil
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	apperrors "github.com/onefeed-th/onefeed-th-backend-api/internal/errors"
+)
+
+// dbPool resolves the connection pool on every call, so repositories built while the
+// database is down return an UnavailableError instead of panicking on a nil pool and
+// start working as soon as the pool is (re)initialized. It satisfies onefeed_th_sqlc.DBTX
+type dbPool func() *pgxpool.Pool
+
+func errDatabaseUnavailable() error {
+	return apperrors.New(apperrors.UnavailableError, "database is unavailable").
+		WithCode("DB_UNAVAILABLE")
+}
+
+func (p dbPool) get() (*pgxpool.Pool, error) {
+	pool := p()
+	if pool == nil {
+		return nil, errDatabaseUnavailable()
+	}
+	return pool, nil
+}
+
+func (p dbPool) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	pool, err := p.get()
+	if err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	return pool.Exec(ctx, sql, args...)
+}
+
+func (p dbPool) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	pool, err := p.get()
+	if err != nil {
+		return nil, err
+	}
+	return pool.Query(ctx, sql, args...)
+}
+
+func (p dbPool) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	pool, err := p.get()
+	if err != nil {
+		return errRow{err: err}
+	}
+	return pool.QueryRow(ctx, sql, args...)
+}
+
+func (p dbPool) Begin(ctx context.Context) (pgx.Tx, error) {
+	pool, err := p.get()
+	if err != nil {
+		return nil, err
+	}
+	return pool.Begin(ctx)
+}
+
+// errRow defers the unavailable error to Scan, matching how pgx reports QueryRow failures
+type errRow struct {
+	err error
+}
+
+func (r errRow) Scan(dest ...any) error {
+	return r.err
+}
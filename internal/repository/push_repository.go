@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	onefeed_th_sqlc "github.com/onefeed-th/onefeed-th-backend-api/internal/sqlc/onefeed_th_sqlc/db"
+)
+
+type PushRepository interface {
+	ClaimPushJobs(ctx context.Context, params onefeed_th_sqlc.ClaimPushJobsParams) ([]onefeed_th_sqlc.ClaimPushJobsRow, error)
+	CreatePushJobs(ctx context.Context, params onefeed_th_sqlc.CreatePushJobsParams) error
+	DeletePushDeviceByID(ctx context.Context, id int64) error
+	DeletePushDeviceByToken(ctx context.Context, token string) (int64, error)
+	DeletePushJob(ctx context.Context, id int64) error
+	GetPushDevices(ctx context.Context) ([]onefeed_th_sqlc.PushDevice, error)
+	RetryPushJob(ctx context.Context, params onefeed_th_sqlc.RetryPushJobParams) error
+	UpsertPushDevice(ctx context.Context, params onefeed_th_sqlc.UpsertPushDeviceParams) (onefeed_th_sqlc.PushDevice, error)
+}
+
+type PushRepositoryImpl struct {
+	pool dbPool
+}
+
+func NewPushRepository(pool func() *pgxpool.Pool) PushRepository {
+	return &PushRepositoryImpl{
+		pool: pool,
+	}
+}
+
+func (r *PushRepositoryImpl) ClaimPushJobs(ctx context.Context, params onefeed_th_sqlc.ClaimPushJobsParams) ([]onefeed_th_sqlc.ClaimPushJobsRow, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := onefeed_th_sqlc.New(r.pool)
+	return query.ClaimPushJobs(ctx, params)
+}
+
+func (r *PushRepositoryImpl) CreatePushJobs(ctx context.Context, params onefeed_th_sqlc.CreatePushJobsParams) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := onefeed_th_sqlc.New(r.pool)
+	return query.CreatePushJobs(ctx, params)
+}
+
+func (r *PushRepositoryImpl) DeletePushDeviceByID(ctx context.Context, id int64) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := onefeed_th_sqlc.New(r.pool)
+	return query.DeletePushDeviceByID(ctx, id)
+}
+
+func (r *PushRepositoryImpl) DeletePushDeviceByToken(ctx context.Context, token string) (int64, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := onefeed_th_sqlc.New(r.pool)
+	return query.DeletePushDeviceByToken(ctx, token)
+}
+
+func (r *PushRepositoryImpl) DeletePushJob(ctx context.Context, id int64) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := onefeed_th_sqlc.New(r.pool)
+	return query.DeletePushJob(ctx, id)
+}
+
+func (r *PushRepositoryImpl) GetPushDevices(ctx context.Context) ([]onefeed_th_sqlc.PushDevice, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) ([]onefeed_th_sqlc.PushDevice, error) {
+		query := onefeed_th_sqlc.New(r.pool)
+		return query.ListPushDevices(ctx)
+	})
+}
+
+func (r *PushRepositoryImpl) RetryPushJob(ctx context.Context, params onefeed_th_sqlc.RetryPushJobParams) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := onefeed_th_sqlc.New(r.pool)
+	return query.RetryPushJob(ctx, params)
+}
+
+func (r *PushRepositoryImpl) UpsertPushDevice(ctx context.Context, params onefeed_th_sqlc.UpsertPushDeviceParams) (onefeed_th_sqlc.PushDevice, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := onefeed_th_sqlc.New(r.pool)
+	return query.UpsertPushDevice(ctx, params)
+}
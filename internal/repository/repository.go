@@ -1,17 +1,76 @@
 package repository
 
-import "github.com/onefeed-th/onefeed-th-backend-api/internal/db"
+import (
+	"context"
+	"time"
+
+	"github.com/onefeed-th/onefeed-th-backend-api/config"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/db"
+)
 
 type Repository struct {
-	SourceRepository SourceRepository
-	NewsRepository   NewsRepository
+	SourceRepository           SourceRepository
+	NewsRepository             NewsRepository
+	NewsClickRepository        NewsClickRepository
+	NewsArchiveRepository      NewsArchiveRepository
+	NewsModerationRepository   NewsModerationRepository
+	APIKeyRepository           APIKeyRepository
+	BackofficeUserRepository   BackofficeUserRepository
+	WebhookRepository          WebhookRepository
+	NotificationRuleRepository NotificationRuleRepository
+	PushRepository             PushRepository
+	UserRepository             UserRepository
+	NewsReadRepository         NewsReadRepository
+	UserPreferenceRepository   UserPreferenceRepository
+	BookmarkRepository         BookmarkRepository
+	TagRepository              TagRepository
+	SourceSuggestionRepository SourceSuggestionRepository
+	JobRepository              JobRepository
+	DeadLetterRepository       DeadLetterRepository
+	SourceStatsRepository      SourceStatsRepository
+	SavedSearchRepository      SavedSearchRepository
+	NewsClusterRepository      NewsClusterRepository
 }
 
+// queryTimeout bounds each repository call; zero leaves the caller's context untouched
+var queryTimeout time.Duration
+
 func NewRepository() *Repository {
-	pool := db.GetPool()
+	cfg := config.GetConfig().Postgres
+	queryTimeout = time.Duration(cfg.QueryTimeout) * time.Second
+	retryAttempts = max(cfg.Retry.MaxAttempts, 1)
+	retryBackoff = time.Duration(cfg.Retry.Backoff) * time.Millisecond
 
+	// Pools are resolved per call so a database that comes up after startup is picked up;
+	// read-heavy listings go through db.GetReadPool so they can be served by replicas
 	return &Repository{
-		SourceRepository: NewSourceRepository(pool),
-		NewsRepository:   NewNewsRepository(pool),
+		SourceRepository:           NewSourceRepository(db.GetPool, db.GetReadPool),
+		NewsRepository:             NewNewsRepository(db.GetPool, db.GetReadPool),
+		NewsClickRepository:        NewNewsClickRepository(db.GetPool),
+		NewsArchiveRepository:      NewNewsArchiveRepository(db.GetPool),
+		NewsModerationRepository:   NewNewsModerationRepository(db.GetPool),
+		APIKeyRepository:           NewAPIKeyRepository(db.GetPool),
+		BackofficeUserRepository:   NewBackofficeUserRepository(db.GetPool),
+		WebhookRepository:          NewWebhookRepository(db.GetPool),
+		NotificationRuleRepository: NewNotificationRuleRepository(db.GetPool),
+		PushRepository:             NewPushRepository(db.GetPool),
+		UserRepository:             NewUserRepository(db.GetPool),
+		NewsReadRepository:         NewNewsReadRepository(db.GetPool),
+		UserPreferenceRepository:   NewUserPreferenceRepository(db.GetPool),
+		BookmarkRepository:         NewBookmarkRepository(db.GetPool),
+		TagRepository:              NewTagRepository(db.GetPool, db.GetReadPool),
+		SourceSuggestionRepository: NewSourceSuggestionRepository(db.GetPool),
+		JobRepository:              NewJobRepository(db.GetPool),
+		DeadLetterRepository:       NewDeadLetterRepository(db.GetPool),
+		SourceStatsRepository:      NewSourceStatsRepository(db.GetPool),
+		SavedSearchRepository:      NewSavedSearchRepository(db.GetPool),
+		NewsClusterRepository:      NewNewsClusterRepository(db.GetPool, db.GetReadPool),
+	}
+}
+
+func withQueryTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if queryTimeout <= 0 {
+		return ctx, func() {}
 	}
+	return context.WithTimeout(ctx, queryTimeout)
 }
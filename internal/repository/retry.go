@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// retryAttempts and retryBackoff are set from postgres.retry in NewRepository
+var (
+	retryAttempts = 1
+	retryBackoff  = 100 * time.Millisecond
+)
+
+// withRetry re-runs an idempotent read when Postgres reports a transient failure,
+// doubling the backoff between attempts. Writes must not go through it
+func withRetry[T any](ctx context.Context, fn func(ctx context.Context) (T, error)) (T, error) {
+	backoff := retryBackoff
+	for attempt := 1; ; attempt++ {
+		result, err := fn(ctx)
+		if err == nil || attempt >= retryAttempts || !isTransientError(err) {
+			return result, err
+		}
+
+		slog.Warn("Transient database error, retrying",
+			"attempt", attempt,
+			"max_attempts", retryAttempts,
+			"backoff", backoff,
+			"error", err,
+		)
+
+		select {
+		case <-ctx.Done():
+			return result, err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// isTransientError reports errors that are expected to clear on their own:
+// serialization failures, deadlocks, server shutdowns during failover and dropped connections
+func isTransientError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "40001", // serialization_failure
+			"40P01", // deadlock_detected
+			"57P01", // admin_shutdown
+			"57P02", // crash_shutdown
+			"57P03": // cannot_connect_now
+			return true
+		}
+		// Class 08 - connection exception
+		return strings.HasPrefix(pgErr.Code, "08")
+	}
+
+	if pgconn.SafeToRetry(err) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	var connectErr *pgconn.ConnectError
+	return errors.As(err, &connectErr) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
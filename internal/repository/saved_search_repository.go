@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	onefeed_th_sqlc "github.com/onefeed-th/onefeed-th-backend-api/internal/sqlc/onefeed_th_sqlc/db"
+)
+
+type SavedSearchRepository interface {
+	ClearSavedSearchPushToken(ctx context.Context, pushToken pgtype.Text) error
+	CountSavedSearches(ctx context.Context, userID int64) (int64, error)
+	CreateSavedSearch(ctx context.Context, params onefeed_th_sqlc.CreateSavedSearchParams) (onefeed_th_sqlc.SavedSearch, error)
+	DeleteSavedSearch(ctx context.Context, params onefeed_th_sqlc.DeleteSavedSearchParams) (int64, error)
+	GetNotifyingSavedSearches(ctx context.Context) ([]onefeed_th_sqlc.SavedSearch, error)
+	GetSavedSearch(ctx context.Context, params onefeed_th_sqlc.GetSavedSearchParams) (onefeed_th_sqlc.SavedSearch, error)
+	GetSavedSearches(ctx context.Context, userID int64) ([]onefeed_th_sqlc.SavedSearch, error)
+	TouchSavedSearchesNotified(ctx context.Context, ids []int64) error
+	UpdateSavedSearch(ctx context.Context, params onefeed_th_sqlc.UpdateSavedSearchParams) (onefeed_th_sqlc.SavedSearch, error)
+}
+
+type SavedSearchRepositoryImpl struct {
+	pool dbPool
+}
+
+func NewSavedSearchRepository(pool func() *pgxpool.Pool) SavedSearchRepository {
+	return &SavedSearchRepositoryImpl{
+		pool: pool,
+	}
+}
+
+func (r *SavedSearchRepositoryImpl) ClearSavedSearchPushToken(ctx context.Context, pushToken pgtype.Text) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := onefeed_th_sqlc.New(r.pool)
+	return query.ClearSavedSearchPushToken(ctx, pushToken)
+}
+
+func (r *SavedSearchRepositoryImpl) CountSavedSearches(ctx context.Context, userID int64) (int64, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) (int64, error) {
+		query := onefeed_th_sqlc.New(r.pool)
+		return query.CountSavedSearches(ctx, userID)
+	})
+}
+
+func (r *SavedSearchRepositoryImpl) CreateSavedSearch(ctx context.Context, params onefeed_th_sqlc.CreateSavedSearchParams) (onefeed_th_sqlc.SavedSearch, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := onefeed_th_sqlc.New(r.pool)
+	return query.CreateSavedSearch(ctx, params)
+}
+
+func (r *SavedSearchRepositoryImpl) DeleteSavedSearch(ctx context.Context, params onefeed_th_sqlc.DeleteSavedSearchParams) (int64, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := onefeed_th_sqlc.New(r.pool)
+	return query.DeleteSavedSearch(ctx, params)
+}
+
+func (r *SavedSearchRepositoryImpl) GetNotifyingSavedSearches(ctx context.Context) ([]onefeed_th_sqlc.SavedSearch, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) ([]onefeed_th_sqlc.SavedSearch, error) {
+		query := onefeed_th_sqlc.New(r.pool)
+		return query.ListNotifyingSavedSearches(ctx)
+	})
+}
+
+func (r *SavedSearchRepositoryImpl) GetSavedSearch(ctx context.Context, params onefeed_th_sqlc.GetSavedSearchParams) (onefeed_th_sqlc.SavedSearch, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) (onefeed_th_sqlc.SavedSearch, error) {
+		query := onefeed_th_sqlc.New(r.pool)
+		return query.GetSavedSearch(ctx, params)
+	})
+}
+
+func (r *SavedSearchRepositoryImpl) GetSavedSearches(ctx context.Context, userID int64) ([]onefeed_th_sqlc.SavedSearch, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) ([]onefeed_th_sqlc.SavedSearch, error) {
+		query := onefeed_th_sqlc.New(r.pool)
+		return query.ListSavedSearches(ctx, userID)
+	})
+}
+
+func (r *SavedSearchRepositoryImpl) TouchSavedSearchesNotified(ctx context.Context, ids []int64) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := onefeed_th_sqlc.New(r.pool)
+	return query.TouchSavedSearchesNotified(ctx, ids)
+}
+
+func (r *SavedSearchRepositoryImpl) UpdateSavedSearch(ctx context.Context, params onefeed_th_sqlc.UpdateSavedSearchParams) (onefeed_th_sqlc.SavedSearch, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := onefeed_th_sqlc.New(r.pool)
+	return query.UpdateSavedSearch(ctx, params)
+}
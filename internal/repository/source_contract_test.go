@@ -0,0 +1,256 @@
+package repository_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/repository"
+	onefeed_th_sqlc "github.com/onefeed-th/onefeed-th-backend-api/internal/sqlc/onefeed_th_sqlc/db"
+)
+
+func sourceID(source onefeed_th_sqlc.Source) int64 { return source.ID }
+
+func createSource(t *testing.T, repo *repository.Repository, name, tags string) onefeed_th_sqlc.Source {
+	t.Helper()
+	return must(repo.SourceRepository.CreateSource(context.Background(), onefeed_th_sqlc.CreateSourceParams{
+		Name:   name,
+		Tags:   pgtype.Text{String: tags, Valid: tags != ""},
+		RssUrl: text("https://" + name + ".example.com/rss"),
+	}))(t)
+}
+
+func TestSources(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repo *repository.Repository) {
+		ctx := context.Background()
+		// tags are deduplicated regardless of case and stored by their canonical names
+		thairath := createSource(t, repo, "thairath", "news, sport ,News")
+		expectEqual(t, "tags", thairath.Tags, text("news,sport"))
+		expectEqual(t, "enabled", thairath.Enabled, true)
+		matichon := createSource(t, repo, "matichon", "Sport")
+		expectEqual(t, "canonical tags", matichon.Tags, text("sport"))
+		khaosod := createSource(t, repo, "khaosod", "")
+
+		stored := must(repo.SourceRepository.GetSourceByID(ctx, thairath.ID))(t)
+		expectEqual(t, "name", stored.Name, "thairath")
+		expectEqual(t, "rss url", stored.RssUrl, thairath.RssUrl)
+		_, err := repo.SourceRepository.GetSourceByID(ctx, 999_999)
+		expectNoRows(t, err)
+
+		page := func(params onefeed_th_sqlc.GetAllSourcesWithPaginationParams) []int64 {
+			if params.Pattern == "" {
+				params.Pattern = "%"
+			}
+			params.PageLimit = 10
+			return ids(must(repo.SourceRepository.GetAllSourcesWithPagination(ctx, params))(t), sourceID)
+		}
+		expectIDs(t, "newest first", page(onefeed_th_sqlc.GetAllSourcesWithPaginationParams{}), []int64{khaosod.ID, matichon.ID, thairath.ID})
+		expectIDs(t, "oldest first", page(onefeed_th_sqlc.GetAllSourcesWithPaginationParams{Sort: "createdAt:asc"}), []int64{thairath.ID, matichon.ID, khaosod.ID})
+		expectIDs(t, "by name", page(onefeed_th_sqlc.GetAllSourcesWithPaginationParams{Sort: "name:asc"}), []int64{khaosod.ID, matichon.ID, thairath.ID})
+		expectIDs(t, "by name desc", page(onefeed_th_sqlc.GetAllSourcesWithPaginationParams{Sort: "name:desc"}), []int64{thairath.ID, matichon.ID, khaosod.ID})
+		expectIDs(t, "by tag", page(onefeed_th_sqlc.GetAllSourcesWithPaginationParams{Tag: "SPORT", Sort: "name:asc"}), []int64{matichon.ID, thairath.ID})
+		expectIDs(t, "by pattern", page(onefeed_th_sqlc.GetAllSourcesWithPaginationParams{Pattern: "%RATH%"}), []int64{thairath.ID})
+		second := must(repo.SourceRepository.GetAllSourcesWithPagination(ctx, onefeed_th_sqlc.GetAllSourcesWithPaginationParams{
+			Pattern: "%", Sort: "name:asc", PageLimit: 1, PageOffset: 1,
+		}))(t)
+		expectIDs(t, "second page", ids(second, sourceID), []int64{matichon.ID})
+		expectEqual(t, "count by tag", must(repo.SourceRepository.CountSources(ctx, onefeed_th_sqlc.CountSourcesParams{Pattern: "%", Tag: "sport"}))(t), 2)
+
+		expectEqual(t, "disabled", must(repo.SourceRepository.SetSourceEnabled(ctx, onefeed_th_sqlc.SetSourceEnabledParams{Enabled: false, ID: matichon.ID}))(t), 1)
+		expectEqual(t, "disabling unknown", must(repo.SourceRepository.SetSourceEnabled(ctx, onefeed_th_sqlc.SetSourceEnabledParams{Enabled: false, ID: 999_999}))(t), 0)
+		enabled := must(repo.SourceRepository.GetAllSources(ctx, false))(t)
+		all := must(repo.SourceRepository.GetAllSources(ctx, true))(t)
+		expectEqual(t, "enabled sources", len(enabled), 2)
+		expectEqual(t, "all sources", len(all), 3)
+		expectIDs(t, "disabled status", page(onefeed_th_sqlc.GetAllSourcesWithPaginationParams{Status: "disabled"}), []int64{matichon.ID})
+		expectEqual(t, "enabled count", must(repo.SourceRepository.CountSources(ctx, onefeed_th_sqlc.CountSourcesParams{Pattern: "%", Status: "enabled"}))(t), 2)
+
+		// renaming a source moves its news along
+		insertNews(t, repo, newsItem("a", "thairath", "First", day(-1)))
+		renamed := must(repo.SourceRepository.UpdateSource(ctx, onefeed_th_sqlc.UpdateSourceParams{
+			Name:          "thairath-online",
+			Tags:          text("news"),
+			RssUrl:        text("https://thairath.example.com/feed"),
+			ImageSelector: text("meta[property='og:image']"),
+			ImageFields:   []string{"media:content"},
+			ID:            thairath.ID,
+		}))(t)
+		expectEqual(t, "renamed", renamed.Name, "thairath-online")
+		expectEqual(t, "retagged", renamed.Tags, text("news"))
+		expectEqual(t, "image selector", renamed.ImageSelector, text("meta[property='og:image']"))
+		expectEqual(t, "image fields", slices.Equal(renamed.ImageFields, []string{"media:content"}), true)
+		expectEqual(t, "moved news", must(repo.NewsRepository.CountNews(ctx, []string{"thairath-online"}))(t), 1)
+		_, err = repo.SourceRepository.UpdateSource(ctx, onefeed_th_sqlc.UpdateSourceParams{Name: "unknown", ID: 999_999})
+		expectNoRows(t, err)
+
+		// deleted sources are kept but hidden from every listing
+		expectEqual(t, "deleted", must(repo.SourceRepository.DeleteSource(ctx, khaosod.ID))(t), 1)
+		expectEqual(t, "deleted again", must(repo.SourceRepository.DeleteSource(ctx, khaosod.ID))(t), 0)
+		_, err = repo.SourceRepository.GetSourceByID(ctx, khaosod.ID)
+		expectNoRows(t, err)
+		_, err = repo.SourceRepository.UpdateSource(ctx, onefeed_th_sqlc.UpdateSourceParams{Name: "khaosod", ID: khaosod.ID})
+		expectNoRows(t, err)
+		expectEqual(t, "enabling deleted", must(repo.SourceRepository.SetSourceEnabled(ctx, onefeed_th_sqlc.SetSourceEnabledParams{Enabled: true, ID: khaosod.ID}))(t), 0)
+		expectEqual(t, "all sources after delete", len(must(repo.SourceRepository.GetAllSources(ctx, true))(t)), 2)
+		expectEqual(t, "count after delete", must(repo.SourceRepository.CountSources(ctx, onefeed_th_sqlc.CountSourcesParams{Pattern: "%"}))(t), 2)
+	})
+}
+
+func TestTags(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repo *repository.Repository) {
+		ctx := context.Background()
+		thairath := createSource(t, repo, "thairath", "news,sport")
+		matichon := createSource(t, repo, "matichon", "sport")
+		deleted := createSource(t, repo, "khaosod", "sport")
+		must(repo.SourceRepository.DeleteSource(ctx, deleted.ID))(t)
+
+		tags := must(repo.TagRepository.GetTags(ctx))(t)
+		expectEqual(t, "tags", len(tags), 2)
+		expectEqual(t, "first tag", tags[0].Name, "news")
+		expectEqual(t, "news sources", tags[0].SourceCount, 1)
+		expectEqual(t, "sport sources", tags[1].SourceCount, 2)
+		sport := tags[1].ID
+
+		politics := must(repo.TagRepository.CreateTag(ctx, onefeed_th_sqlc.CreateTagParams{Name: "politics", Description: text("Politics news")}))(t)
+		stored := must(repo.TagRepository.GetTagByID(ctx, politics.ID))(t)
+		expectEqual(t, "description", stored.Description, text("Politics news"))
+		_, err := repo.TagRepository.CreateTag(ctx, onefeed_th_sqlc.CreateTagParams{Name: "Sport"})
+		expectUniqueViolation(t, err)
+		_, err = repo.TagRepository.GetTagByID(ctx, 9_999)
+		expectNoRows(t, err)
+
+		// renaming a tag rewrites the tags copy of its sources
+		renamed := must(repo.TagRepository.UpdateTag(ctx, onefeed_th_sqlc.UpdateTagParams{Name: "sports", ID: sport}))(t)
+		expectEqual(t, "renamed", renamed.Name, "sports")
+		expectEqual(t, "source tags", must(repo.SourceRepository.GetSourceByID(ctx, thairath.ID))(t).Tags, text("news,sports"))
+		_, err = repo.TagRepository.UpdateTag(ctx, onefeed_th_sqlc.UpdateTagParams{Name: "Politics", ID: sport})
+		expectUniqueViolation(t, err)
+		_, err = repo.TagRepository.UpdateTag(ctx, onefeed_th_sqlc.UpdateTagParams{Name: "unknown", ID: 9_999})
+		expectNoRows(t, err)
+
+		expectEqual(t, "deleted", must(repo.TagRepository.DeleteTag(ctx, sport))(t), 1)
+		expectEqual(t, "deleted again", must(repo.TagRepository.DeleteTag(ctx, sport))(t), 0)
+		expectEqual(t, "source tags after delete", must(repo.SourceRepository.GetSourceByID(ctx, thairath.ID))(t).Tags, text("news"))
+		expectEqual(t, "untagged source", must(repo.SourceRepository.GetSourceByID(ctx, matichon.ID))(t).Tags.Valid, false)
+	})
+}
+
+func TestSourceSuggestions(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repo *repository.Repository) {
+		ctx := context.Background()
+		user := must(repo.UserRepository.CreateDeviceUser(ctx, "device-1"))(t)
+		suggest := func(name, rssURL string) (onefeed_th_sqlc.SourceSuggestion, error) {
+			return repo.SourceSuggestionRepository.CreateSourceSuggestion(ctx, onefeed_th_sqlc.CreateSourceSuggestionParams{
+				UserID: user.ID, Name: name, Tags: text("news"), RssUrl: rssURL, Note: "please add", PushToken: text("token"),
+			})
+		}
+		first := must(suggest("prachatai", "https://prachatai.example.com/rss"))(t)
+		expectEqual(t, "status", first.Status, "pending")
+		_, err := suggest("prachatai again", "https://prachatai.example.com/rss")
+		expectUniqueViolation(t, err)
+		second := must(suggest("spam", "https://spam.example.com/rss"))(t)
+		suggestionID := func(s onefeed_th_sqlc.SourceSuggestion) int64 { return s.ID }
+
+		expectEqual(t, "pending", must(repo.SourceSuggestionRepository.CountPendingSourceSuggestions(ctx))(t), 2)
+		pending := must(repo.SourceSuggestionRepository.GetPendingSourceSuggestions(ctx, onefeed_th_sqlc.ListPendingSourceSuggestionsParams{PageLimit: 10}))(t)
+		expectIDs(t, "pending", ids(pending, suggestionID), []int64{first.ID, second.ID})
+		pending = must(repo.SourceSuggestionRepository.GetPendingSourceSuggestions(ctx, onefeed_th_sqlc.ListPendingSourceSuggestionsParams{PageLimit: 1, PageOffset: 1}))(t)
+		expectIDs(t, "pending page", ids(pending, suggestionID), []int64{second.ID})
+		mine := must(repo.SourceSuggestionRepository.GetUserSourceSuggestions(ctx, onefeed_th_sqlc.ListUserSourceSuggestionsParams{UserID: user.ID, PageLimit: 10}))(t)
+		expectIDs(t, "user suggestions", ids(mine, suggestionID), []int64{second.ID, first.ID})
+
+		rejected := must(repo.SourceSuggestionRepository.ReviewSourceSuggestion(ctx, onefeed_th_sqlc.ReviewSourceSuggestionParams{
+			Status: "rejected", ReviewReason: text("spam"), ReviewedBy: text("admin"), ID: second.ID,
+		}))(t)
+		expectEqual(t, "rejected", rejected.Status, "rejected")
+		expectEqual(t, "reviewed at", rejected.ReviewedAt.Valid, true)
+		_, err = repo.SourceSuggestionRepository.ReviewSourceSuggestion(ctx, onefeed_th_sqlc.ReviewSourceSuggestionParams{Status: "approved", ID: second.ID})
+		expectNoRows(t, err)
+		// only pending suggestions hold their feed
+		must(suggest("spam again", "https://spam.example.com/rss"))(t)
+
+		suggestion, source := must2(repo.SourceSuggestionRepository.ApproveSourceSuggestion(ctx,
+			onefeed_th_sqlc.CreateSourceParams{Name: "prachatai", Tags: text("news,Politics"), RssUrl: text(first.RssUrl)},
+			onefeed_th_sqlc.ReviewSourceSuggestionParams{Status: "approved", ReviewedBy: text("admin"), ID: first.ID},
+		))(t)
+		expectEqual(t, "approved", suggestion.Status, "approved")
+		expectEqual(t, "source id", suggestion.SourceID, pgtype.Int8{Int64: source.ID, Valid: true})
+		expectEqual(t, "source", must(repo.SourceRepository.GetSourceByID(ctx, source.ID))(t).Name, "prachatai")
+		expectEqual(t, "stored suggestion", must(repo.SourceSuggestionRepository.GetSourceSuggestionByID(ctx, first.ID))(t).Status, "approved")
+
+		// a suggestion that is no longer pending creates no source
+		_, _, err = repo.SourceSuggestionRepository.ApproveSourceSuggestion(ctx,
+			onefeed_th_sqlc.CreateSourceParams{Name: "prachatai twice"},
+			onefeed_th_sqlc.ReviewSourceSuggestionParams{Status: "approved", ID: first.ID},
+		)
+		expectNoRows(t, err)
+		expectEqual(t, "sources", len(must(repo.SourceRepository.GetAllSources(ctx, true))(t)), 1)
+		_, err = repo.SourceSuggestionRepository.GetSourceSuggestionByID(ctx, 999_999)
+		expectNoRows(t, err)
+	})
+}
+
+func TestSourceStats(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repo *repository.Repository) {
+		ctx := context.Background()
+		thairath := createSource(t, repo, "thairath", "")
+		matichon := createSource(t, repo, "matichon", "")
+		deleted := createSource(t, repo, "khaosod", "")
+		must(repo.SourceRepository.DeleteSource(ctx, deleted.ID))(t)
+		news := insertNews(t, repo,
+			newsItem("a", "thairath", "First", day(-1)),
+			newsItem("b", "thairath", "Second", day(-1)),
+			newsItem("c", "matichon", "Third", day(-1)),
+		)
+
+		// the day the news were fetched on, as the store's own clock saw it
+		fetched := news["a"].FetchedAt.Time.Truncate(24 * time.Hour)
+		mustDo(t, repo.NewsClickRepository.UpsertNewsClicks(ctx, onefeed_th_sqlc.UpsertNewsClicksParams{
+			BucketStart: timestamp(fetched.Add(time.Hour)),
+			NewsIds:     []int64{news["a"].ID, news["b"].ID, news["c"].ID},
+			Clicks:      []int64{4, 6, 3},
+		}))
+		aggregate := onefeed_th_sqlc.UpsertSourceStatsDailyParams{
+			Day: timestamp(fetched), ImpressionSources: []string{"thairath", "khaosod"}, Impressions: []int64{10, 5},
+		}
+		mustDo(t, repo.SourceStatsRepository.AggregateSourceStats(ctx, aggregate))
+		// aggregating a day again replaces its totals
+		mustDo(t, repo.SourceStatsRepository.AggregateSourceStats(ctx, aggregate))
+
+		stats := must(repo.SourceStatsRepository.GetSourceStats(ctx, onefeed_th_sqlc.ListSourceStatsParams{
+			Interval: "day", FromDate: timestamp(fetched), ToDate: timestamp(fetched.AddDate(0, 0, 1)),
+		}))(t)
+		want := []onefeed_th_sqlc.ListSourceStatsRow{
+			{SourceID: thairath.ID, Bucket: timestamp(fetched), Articles: 2, Impressions: 10, Clicks: 10},
+			{SourceID: matichon.ID, Bucket: timestamp(fetched), Articles: 1, Impressions: 0, Clicks: 3},
+		}
+		expectEqual(t, "stats", len(stats), len(want))
+		for i := range want {
+			expectEqual(t, "source", stats[i].SourceID, want[i].SourceID)
+			expectEqual(t, "bucket", stats[i].Bucket.Time.Equal(want[i].Bucket.Time), true)
+			expectEqual(t, "articles", stats[i].Articles, want[i].Articles)
+			expectEqual(t, "impressions", stats[i].Impressions, want[i].Impressions)
+			expectEqual(t, "clicks", stats[i].Clicks, want[i].Clicks)
+		}
+		monthly := must(repo.SourceStatsRepository.GetSourceStats(ctx, onefeed_th_sqlc.ListSourceStatsParams{
+			Interval: "month", FromDate: timestamp(fetched), ToDate: timestamp(fetched.AddDate(0, 0, 1)), SourceIds: []int64{matichon.ID},
+		}))(t)
+		expectEqual(t, "monthly stats", len(monthly), 1)
+		expectEqual(t, "month bucket", monthly[0].Bucket.Time.Day(), 1)
+
+		top := must(repo.SourceStatsRepository.GetTopSourceNews(ctx, onefeed_th_sqlc.ListTopSourceNewsParams{
+			FromDate: timestamp(fetched), ToDate: timestamp(fetched.AddDate(0, 0, 1)), TopLimit: 1,
+		}))(t)
+		expectIDs(t, "top news", ids(top, func(row onefeed_th_sqlc.ListTopSourceNewsRow) int64 { return row.NewsID }), []int64{news["b"].ID, news["c"].ID})
+		expectEqual(t, "top title", top[0].Title, "Second")
+		expectEqual(t, "top clicks", top[0].Clicks, 6)
+
+		mustDo(t, repo.SourceStatsRepository.RemoveNewsClicksDailyBefore(ctx, timestamp(fetched.AddDate(0, 0, 1))))
+		top = must(repo.SourceStatsRepository.GetTopSourceNews(ctx, onefeed_th_sqlc.ListTopSourceNewsParams{
+			FromDate: timestamp(fetched), ToDate: timestamp(fetched.AddDate(0, 0, 1)), TopLimit: 1,
+		}))(t)
+		expectEqual(t, "top news after removal", len(top), 0)
+	})
+}
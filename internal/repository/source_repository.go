@@ -2,38 +2,198 @@ package repository
 
 import (
 	"context"
+	"strings"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 	onefeed_th_sqlc "github.com/onefeed-th/onefeed-th-backend-api/internal/sqlc/onefeed_th_sqlc/db"
 )
 
 type SourceRepository interface {
-	GetAllSources(ctx context.Context) ([]onefeed_th_sqlc.Source, error)
+	// GetAllSources never returns deleted sources, and disabled ones only with includeDisabled
+	GetAllSources(ctx context.Context, includeDisabled bool) ([]onefeed_th_sqlc.Source, error)
 	GetAllSourcesWithPagination(ctx context.Context, req onefeed_th_sqlc.GetAllSourcesWithPaginationParams) ([]onefeed_th_sqlc.Source, error)
+	CountSources(ctx context.Context, req onefeed_th_sqlc.CountSourcesParams) (int64, error)
+	// CreateSource and UpdateSource link the source to the tags named in req.Tags, creating
+	// missing ones, and store their canonical names
 	CreateSource(ctx context.Context, req onefeed_th_sqlc.CreateSourceParams) (onefeed_th_sqlc.Source, error)
+	GetSourceByID(ctx context.Context, id int64) (onefeed_th_sqlc.Source, error)
+	// UpdateSource also moves the source's news to its new name when it is renamed
+	UpdateSource(ctx context.Context, req onefeed_th_sqlc.UpdateSourceParams) (onefeed_th_sqlc.Source, error)
+	SetSourceEnabled(ctx context.Context, req onefeed_th_sqlc.SetSourceEnabledParams) (int64, error)
+	// DeleteSource soft deletes: the row is kept so the source's news still resolve to it
+	DeleteSource(ctx context.Context, id int64) (int64, error)
 }
 
 type SourceRepositoryImpl struct {
-	pool *pgxpool.Pool
+	pool     dbPool
+	readPool dbPool
 }
 
-func NewSourceRepository(pool *pgxpool.Pool) SourceRepository {
+func NewSourceRepository(pool, readPool func() *pgxpool.Pool) SourceRepository {
 	return &SourceRepositoryImpl{
-		pool: pool,
+		pool:     pool,
+		readPool: readPool,
 	}
 }
 
-func (r *SourceRepositoryImpl) GetAllSources(ctx context.Context) ([]onefeed_th_sqlc.Source, error) {
-	query := onefeed_th_sqlc.New(r.pool)
-	return query.GetAllSources(ctx)
+func (r *SourceRepositoryImpl) GetAllSources(ctx context.Context, includeDisabled bool) ([]onefeed_th_sqlc.Source, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) ([]onefeed_th_sqlc.Source, error) {
+		query := onefeed_th_sqlc.New(r.readPool)
+		return query.GetAllSources(ctx, includeDisabled)
+	})
 }
 
 func (r *SourceRepositoryImpl) CreateSource(ctx context.Context, req onefeed_th_sqlc.CreateSourceParams) (onefeed_th_sqlc.Source, error) {
-	query := onefeed_th_sqlc.New(r.pool)
-	return query.CreateSource(ctx, req)
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return onefeed_th_sqlc.Source{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := onefeed_th_sqlc.New(r.pool).WithTx(tx)
+	source, err := query.CreateSource(ctx, req)
+	if err != nil {
+		return onefeed_th_sqlc.Source{}, err
+	}
+	source, err = syncSourceTags(ctx, query, source.ID, req.Tags)
+	if err != nil {
+		return onefeed_th_sqlc.Source{}, err
+	}
+
+	return source, tx.Commit(ctx)
 }
 
 func (r *SourceRepositoryImpl) GetAllSourcesWithPagination(ctx context.Context, req onefeed_th_sqlc.GetAllSourcesWithPaginationParams) ([]onefeed_th_sqlc.Source, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) ([]onefeed_th_sqlc.Source, error) {
+		query := onefeed_th_sqlc.New(r.pool)
+		return query.GetAllSourcesWithPagination(ctx, req)
+	})
+}
+
+func (r *SourceRepositoryImpl) CountSources(ctx context.Context, req onefeed_th_sqlc.CountSourcesParams) (int64, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) (int64, error) {
+		query := onefeed_th_sqlc.New(r.pool)
+		return query.CountSources(ctx, req)
+	})
+}
+
+func (r *SourceRepositoryImpl) GetSourceByID(ctx context.Context, id int64) (onefeed_th_sqlc.Source, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) (onefeed_th_sqlc.Source, error) {
+		query := onefeed_th_sqlc.New(r.pool)
+		return query.GetSourceByID(ctx, id)
+	})
+}
+
+func (r *SourceRepositoryImpl) UpdateSource(ctx context.Context, req onefeed_th_sqlc.UpdateSourceParams) (onefeed_th_sqlc.Source, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return onefeed_th_sqlc.Source{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := onefeed_th_sqlc.New(r.pool).WithTx(tx)
+
+	current, err := query.GetSourceByID(ctx, req.ID)
+	if err != nil {
+		return onefeed_th_sqlc.Source{}, err
+	}
+	source, err := query.UpdateSource(ctx, req)
+	if err != nil {
+		return onefeed_th_sqlc.Source{}, err
+	}
+	source, err = syncSourceTags(ctx, query, source.ID, req.Tags)
+	if err != nil {
+		return onefeed_th_sqlc.Source{}, err
+	}
+	if current.Name != source.Name {
+		if _, err := query.RenameNewsSource(ctx, onefeed_th_sqlc.RenameNewsSourceParams{
+			NewSource: source.Name,
+			OldSource: current.Name,
+		}); err != nil {
+			return onefeed_th_sqlc.Source{}, err
+		}
+	}
+
+	return source, tx.Commit(ctx)
+}
+
+func (r *SourceRepositoryImpl) SetSourceEnabled(ctx context.Context, req onefeed_th_sqlc.SetSourceEnabledParams) (int64, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := onefeed_th_sqlc.New(r.pool)
+	return query.SetSourceEnabled(ctx, req)
+}
+
+func (r *SourceRepositoryImpl) DeleteSource(ctx context.Context, id int64) (int64, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
 	query := onefeed_th_sqlc.New(r.pool)
-	return query.GetAllSourcesWithPagination(ctx, req)
+	return query.DeleteSource(ctx, id)
+}
+
+// SourceTagNames splits the comma separated tags of a source, dropping blanks and repeats
+// regardless of case
+func SourceTagNames(tags string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(tags, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+		names = append(names, name)
+	}
+	return names
+}
+
+// syncSourceTags replaces the tag links of a source with the tags named in tags and
+// rewrites its tags copy from them
+func syncSourceTags(ctx context.Context, query *onefeed_th_sqlc.Queries, sourceID int64, tags pgtype.Text) (onefeed_th_sqlc.Source, error) {
+	if err := query.DeleteSourceTags(ctx, sourceID); err != nil {
+		return onefeed_th_sqlc.Source{}, err
+	}
+	for _, name := range SourceTagNames(tags.String) {
+		tag, err := query.EnsureTag(ctx, name)
+		if err != nil {
+			return onefeed_th_sqlc.Source{}, err
+		}
+		if err := query.AddSourceTag(ctx, onefeed_th_sqlc.AddSourceTagParams{
+			SourceID: sourceID,
+			TagID:    tag.ID,
+		}); err != nil {
+			return onefeed_th_sqlc.Source{}, err
+		}
+	}
+
+	sources, err := query.RefreshSourceTags(ctx, []int64{sourceID})
+	if err != nil {
+		return onefeed_th_sqlc.Source{}, err
+	}
+	if len(sources) == 0 {
+		return onefeed_th_sqlc.Source{}, pgx.ErrNoRows
+	}
+	return sources[0], nil
 }
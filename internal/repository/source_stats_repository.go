@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	onefeed_th_sqlc "github.com/onefeed-th/onefeed-th-backend-api/internal/sqlc/onefeed_th_sqlc/db"
+)
+
+type SourceStatsRepository interface {
+	AggregateSourceStats(ctx context.Context, params onefeed_th_sqlc.UpsertSourceStatsDailyParams) error
+	GetSourceStats(ctx context.Context, params onefeed_th_sqlc.ListSourceStatsParams) ([]onefeed_th_sqlc.ListSourceStatsRow, error)
+	GetTopSourceNews(ctx context.Context, params onefeed_th_sqlc.ListTopSourceNewsParams) ([]onefeed_th_sqlc.ListTopSourceNewsRow, error)
+	RemoveNewsClicksDailyBefore(ctx context.Context, before pgtype.Timestamp) error
+}
+
+type SourceStatsRepositoryImpl struct {
+	pool dbPool
+}
+
+func NewSourceStatsRepository(pool func() *pgxpool.Pool) SourceStatsRepository {
+	return &SourceStatsRepositoryImpl{
+		pool: pool,
+	}
+}
+
+// AggregateSourceStats rolls the clicks of a day up per news and then writes the totals of
+// the day per source, in a single transaction. Both are absolute, so a day can be aggregated
+// again as its counts grow
+func (r *SourceStatsRepositoryImpl) AggregateSourceStats(ctx context.Context, params onefeed_th_sqlc.UpsertSourceStatsDailyParams) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := onefeed_th_sqlc.New(r.pool).WithTx(tx)
+	if err := query.AggregateNewsClicksDaily(ctx, params.Day); err != nil {
+		return err
+	}
+	if err := query.UpsertSourceStatsDaily(ctx, params); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *SourceStatsRepositoryImpl) GetSourceStats(ctx context.Context, params onefeed_th_sqlc.ListSourceStatsParams) ([]onefeed_th_sqlc.ListSourceStatsRow, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) ([]onefeed_th_sqlc.ListSourceStatsRow, error) {
+		query := onefeed_th_sqlc.New(r.pool)
+		return query.ListSourceStats(ctx, params)
+	})
+}
+
+func (r *SourceStatsRepositoryImpl) GetTopSourceNews(ctx context.Context, params onefeed_th_sqlc.ListTopSourceNewsParams) ([]onefeed_th_sqlc.ListTopSourceNewsRow, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) ([]onefeed_th_sqlc.ListTopSourceNewsRow, error) {
+		query := onefeed_th_sqlc.New(r.pool)
+		return query.ListTopSourceNews(ctx, params)
+	})
+}
+
+func (r *SourceStatsRepositoryImpl) RemoveNewsClicksDailyBefore(ctx context.Context, before pgtype.Timestamp) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := onefeed_th_sqlc.New(r.pool)
+	return query.RemoveNewsClicksDailyBefore(ctx, before)
+}
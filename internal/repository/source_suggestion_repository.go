@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	onefeed_th_sqlc "github.com/onefeed-th/onefeed-th-backend-api/internal/sqlc/onefeed_th_sqlc/db"
+)
+
+type SourceSuggestionRepository interface {
+	// ApproveSourceSuggestion creates the source and marks the suggestion approved in one
+	// transaction; it returns pgx.ErrNoRows when the suggestion is no longer pending
+	ApproveSourceSuggestion(ctx context.Context, source onefeed_th_sqlc.CreateSourceParams, review onefeed_th_sqlc.ReviewSourceSuggestionParams) (onefeed_th_sqlc.SourceSuggestion, onefeed_th_sqlc.Source, error)
+	CountPendingSourceSuggestions(ctx context.Context) (int64, error)
+	CreateSourceSuggestion(ctx context.Context, params onefeed_th_sqlc.CreateSourceSuggestionParams) (onefeed_th_sqlc.SourceSuggestion, error)
+	GetPendingSourceSuggestions(ctx context.Context, params onefeed_th_sqlc.ListPendingSourceSuggestionsParams) ([]onefeed_th_sqlc.SourceSuggestion, error)
+	GetSourceSuggestionByID(ctx context.Context, id int64) (onefeed_th_sqlc.SourceSuggestion, error)
+	GetUserSourceSuggestions(ctx context.Context, params onefeed_th_sqlc.ListUserSourceSuggestionsParams) ([]onefeed_th_sqlc.SourceSuggestion, error)
+	// ReviewSourceSuggestion returns pgx.ErrNoRows when the suggestion is no longer pending
+	ReviewSourceSuggestion(ctx context.Context, params onefeed_th_sqlc.ReviewSourceSuggestionParams) (onefeed_th_sqlc.SourceSuggestion, error)
+}
+
+type SourceSuggestionRepositoryImpl struct {
+	pool dbPool
+}
+
+func NewSourceSuggestionRepository(pool func() *pgxpool.Pool) SourceSuggestionRepository {
+	return &SourceSuggestionRepositoryImpl{
+		pool: pool,
+	}
+}
+
+func (r *SourceSuggestionRepositoryImpl) ApproveSourceSuggestion(ctx context.Context, source onefeed_th_sqlc.CreateSourceParams, review onefeed_th_sqlc.ReviewSourceSuggestionParams) (onefeed_th_sqlc.SourceSuggestion, onefeed_th_sqlc.Source, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return onefeed_th_sqlc.SourceSuggestion{}, onefeed_th_sqlc.Source{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := onefeed_th_sqlc.New(r.pool).WithTx(tx)
+	created, err := query.CreateSource(ctx, source)
+	if err != nil {
+		return onefeed_th_sqlc.SourceSuggestion{}, onefeed_th_sqlc.Source{}, err
+	}
+	created, err = syncSourceTags(ctx, query, created.ID, source.Tags)
+	if err != nil {
+		return onefeed_th_sqlc.SourceSuggestion{}, onefeed_th_sqlc.Source{}, err
+	}
+
+	review.SourceID = pgtype.Int8{Int64: created.ID, Valid: true}
+	suggestion, err := query.ReviewSourceSuggestion(ctx, review)
+	if err != nil {
+		return onefeed_th_sqlc.SourceSuggestion{}, onefeed_th_sqlc.Source{}, err
+	}
+
+	return suggestion, created, tx.Commit(ctx)
+}
+
+func (r *SourceSuggestionRepositoryImpl) CountPendingSourceSuggestions(ctx context.Context) (int64, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) (int64, error) {
+		query := onefeed_th_sqlc.New(r.pool)
+		return query.CountPendingSourceSuggestions(ctx)
+	})
+}
+
+func (r *SourceSuggestionRepositoryImpl) CreateSourceSuggestion(ctx context.Context, params onefeed_th_sqlc.CreateSourceSuggestionParams) (onefeed_th_sqlc.SourceSuggestion, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := onefeed_th_sqlc.New(r.pool)
+	return query.CreateSourceSuggestion(ctx, params)
+}
+
+func (r *SourceSuggestionRepositoryImpl) GetPendingSourceSuggestions(ctx context.Context, params onefeed_th_sqlc.ListPendingSourceSuggestionsParams) ([]onefeed_th_sqlc.SourceSuggestion, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) ([]onefeed_th_sqlc.SourceSuggestion, error) {
+		query := onefeed_th_sqlc.New(r.pool)
+		return query.ListPendingSourceSuggestions(ctx, params)
+	})
+}
+
+func (r *SourceSuggestionRepositoryImpl) GetSourceSuggestionByID(ctx context.Context, id int64) (onefeed_th_sqlc.SourceSuggestion, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) (onefeed_th_sqlc.SourceSuggestion, error) {
+		query := onefeed_th_sqlc.New(r.pool)
+		return query.GetSourceSuggestionByID(ctx, id)
+	})
+}
+
+func (r *SourceSuggestionRepositoryImpl) GetUserSourceSuggestions(ctx context.Context, params onefeed_th_sqlc.ListUserSourceSuggestionsParams) ([]onefeed_th_sqlc.SourceSuggestion, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) ([]onefeed_th_sqlc.SourceSuggestion, error) {
+		query := onefeed_th_sqlc.New(r.pool)
+		return query.ListUserSourceSuggestions(ctx, params)
+	})
+}
+
+func (r *SourceSuggestionRepositoryImpl) ReviewSourceSuggestion(ctx context.Context, params onefeed_th_sqlc.ReviewSourceSuggestionParams) (onefeed_th_sqlc.SourceSuggestion, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := onefeed_th_sqlc.New(r.pool)
+	return query.ReviewSourceSuggestion(ctx, params)
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	onefeed_th_sqlc "github.com/onefeed-th/onefeed-th-backend-api/internal/sqlc/onefeed_th_sqlc/db"
+)
+
+type TagRepository interface {
+	// GetTags lists every tag with the number of sources linked to it, deleted sources aside
+	GetTags(ctx context.Context) ([]onefeed_th_sqlc.ListTagsWithSourceCountsRow, error)
+	GetTagByID(ctx context.Context, id int32) (onefeed_th_sqlc.Tag, error)
+	CreateTag(ctx context.Context, params onefeed_th_sqlc.CreateTagParams) (onefeed_th_sqlc.Tag, error)
+	// UpdateTag and DeleteTag also rewrite the tags copy of the sources linked to the tag
+	UpdateTag(ctx context.Context, params onefeed_th_sqlc.UpdateTagParams) (onefeed_th_sqlc.Tag, error)
+	DeleteTag(ctx context.Context, id int32) (int64, error)
+}
+
+type TagRepositoryImpl struct {
+	pool     dbPool
+	readPool dbPool
+}
+
+func NewTagRepository(pool, readPool func() *pgxpool.Pool) TagRepository {
+	return &TagRepositoryImpl{
+		pool:     pool,
+		readPool: readPool,
+	}
+}
+
+func (r *TagRepositoryImpl) GetTags(ctx context.Context) ([]onefeed_th_sqlc.ListTagsWithSourceCountsRow, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) ([]onefeed_th_sqlc.ListTagsWithSourceCountsRow, error) {
+		query := onefeed_th_sqlc.New(r.readPool)
+		return query.ListTagsWithSourceCounts(ctx)
+	})
+}
+
+func (r *TagRepositoryImpl) GetTagByID(ctx context.Context, id int32) (onefeed_th_sqlc.Tag, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) (onefeed_th_sqlc.Tag, error) {
+		query := onefeed_th_sqlc.New(r.pool)
+		return query.GetTagByID(ctx, id)
+	})
+}
+
+func (r *TagRepositoryImpl) CreateTag(ctx context.Context, params onefeed_th_sqlc.CreateTagParams) (onefeed_th_sqlc.Tag, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := onefeed_th_sqlc.New(r.pool)
+	return query.CreateTag(ctx, params)
+}
+
+func (r *TagRepositoryImpl) UpdateTag(ctx context.Context, params onefeed_th_sqlc.UpdateTagParams) (onefeed_th_sqlc.Tag, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return onefeed_th_sqlc.Tag{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := onefeed_th_sqlc.New(r.pool).WithTx(tx)
+	tag, err := query.UpdateTag(ctx, params)
+	if err != nil {
+		return onefeed_th_sqlc.Tag{}, err
+	}
+	sourceIDs, err := query.ListTagSourceIDs(ctx, tag.ID)
+	if err != nil {
+		return onefeed_th_sqlc.Tag{}, err
+	}
+	if _, err := query.RefreshSourceTags(ctx, sourceIDs); err != nil {
+		return onefeed_th_sqlc.Tag{}, err
+	}
+
+	return tag, tx.Commit(ctx)
+}
+
+func (r *TagRepositoryImpl) DeleteTag(ctx context.Context, id int32) (int64, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := onefeed_th_sqlc.New(r.pool).WithTx(tx)
+	sourceIDs, err := query.ListTagSourceIDs(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+	// the links go with the tag
+	deleted, err := query.DeleteTag(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := query.RefreshSourceTags(ctx, sourceIDs); err != nil {
+		return 0, err
+	}
+
+	return deleted, tx.Commit(ctx)
+}
@@ -0,0 +1,185 @@
+package repository_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/repository"
+	onefeed_th_sqlc "github.com/onefeed-th/onefeed-th-backend-api/internal/sqlc/onefeed_th_sqlc/db"
+)
+
+func TestUsers(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repo *repository.Repository) {
+		ctx := context.Background()
+		device := must(repo.UserRepository.CreateDeviceUser(ctx, "device-1"))(t)
+		expectEqual(t, "device id", device.DeviceID, text("device-1"))
+		// a known device signs in as the same user
+		again := must(repo.UserRepository.CreateDeviceUser(ctx, "device-1"))(t)
+		expectEqual(t, "same device user", again.ID, device.ID)
+		expectEqual(t, "by device", must(repo.UserRepository.GetUserByDeviceID(ctx, "device-1"))(t).ID, device.ID)
+		_, err := repo.UserRepository.GetUserByDeviceID(ctx, "device-2")
+		expectNoRows(t, err)
+
+		member := must(repo.UserRepository.CreateUser(ctx, onefeed_th_sqlc.CreateUserParams{
+			Email: text("somchai@example.com"), PasswordHash: text("hash"), DisplayName: "Somchai",
+		}))(t)
+		expectEqual(t, "last login", member.LastLoginAt.Valid, true)
+		_, err = repo.UserRepository.CreateUser(ctx, onefeed_th_sqlc.CreateUserParams{Email: text("somchai@example.com"), DisplayName: "Again"})
+		expectUniqueViolation(t, err)
+		// users without an email don't collide
+		must(repo.UserRepository.CreateUser(ctx, onefeed_th_sqlc.CreateUserParams{DisplayName: "No email"}))(t)
+		must(repo.UserRepository.CreateUser(ctx, onefeed_th_sqlc.CreateUserParams{DisplayName: "No email either"}))(t)
+
+		expectEqual(t, "by email", must(repo.UserRepository.GetUserByEmail(ctx, "somchai@example.com"))(t).ID, member.ID)
+		_, err = repo.UserRepository.GetUserByEmail(ctx, "nobody@example.com")
+		expectNoRows(t, err)
+		expectEqual(t, "by id", must(repo.UserRepository.GetUserByID(ctx, member.ID))(t).DisplayName, "Somchai")
+		_, err = repo.UserRepository.GetUserByID(ctx, 999_999)
+		expectNoRows(t, err)
+
+		identity := onefeed_th_sqlc.CreateUserIdentityParams{Provider: "google", Subject: "sub-1", UserID: member.ID, Email: text("somchai@example.com")}
+		mustDo(t, repo.UserRepository.CreateUserIdentity(ctx, identity))
+		expectUniqueViolation(t, repo.UserRepository.CreateUserIdentity(ctx, identity))
+		byIdentity := must(repo.UserRepository.GetUserByIdentity(ctx, onefeed_th_sqlc.GetUserByIdentityParams{Provider: "google", Subject: "sub-1"}))(t)
+		expectEqual(t, "by identity", byIdentity.ID, member.ID)
+		_, err = repo.UserRepository.GetUserByIdentity(ctx, onefeed_th_sqlc.GetUserByIdentityParams{Provider: "apple", Subject: "sub-1"})
+		expectNoRows(t, err)
+
+		// linking a provider account to a registered user drops the password
+		mustDo(t, repo.UserRepository.LinkUserIdentity(ctx, onefeed_th_sqlc.CreateUserIdentityParams{Provider: "apple", Subject: "sub-2", UserID: member.ID}))
+		linked := must(repo.UserRepository.GetUserByIdentity(ctx, onefeed_th_sqlc.GetUserByIdentityParams{Provider: "apple", Subject: "sub-2"}))(t)
+		expectEqual(t, "linked", linked.ID, member.ID)
+		expectEqual(t, "password dropped", linked.PasswordHash.Valid, false)
+		expectUniqueViolation(t, repo.UserRepository.LinkUserIdentity(ctx, onefeed_th_sqlc.CreateUserIdentityParams{Provider: "google", Subject: "sub-1", UserID: member.ID}))
+
+		mustDo(t, repo.UserRepository.UpdateUserLastLogin(ctx, member.ID))
+		expectEqual(t, "last login moved", !must(repo.UserRepository.GetUserByID(ctx, member.ID))(t).LastLoginAt.Time.Before(member.LastLoginAt.Time), true)
+		profile := must(repo.UserRepository.UpdateUserProfile(ctx, onefeed_th_sqlc.UpdateUserProfileParams{DisplayName: "Somchai J.", ID: member.ID}))(t)
+		expectEqual(t, "display name", profile.DisplayName, "Somchai J.")
+		expectEqual(t, "updated at", profile.UpdatedAt.Valid, true)
+		_, err = repo.UserRepository.UpdateUserProfile(ctx, onefeed_th_sqlc.UpdateUserProfileParams{DisplayName: "Nobody", ID: 999_999})
+		expectNoRows(t, err)
+
+		// deleting a user takes their identities along
+		expectEqual(t, "deleted", must(repo.UserRepository.DeleteUser(ctx, member.ID))(t), 1)
+		expectEqual(t, "deleted again", must(repo.UserRepository.DeleteUser(ctx, member.ID))(t), 0)
+		_, err = repo.UserRepository.GetUserByIdentity(ctx, onefeed_th_sqlc.GetUserByIdentityParams{Provider: "google", Subject: "sub-1"})
+		expectNoRows(t, err)
+	})
+}
+
+func TestUserPreferences(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repo *repository.Repository) {
+		ctx := context.Background()
+		user := must(repo.UserRepository.CreateDeviceUser(ctx, "device-1"))(t)
+		_, err := repo.UserPreferenceRepository.GetUserPreferences(ctx, user.ID)
+		expectNoRows(t, err)
+
+		must(repo.UserPreferenceRepository.UpsertUserPreferences(ctx, onefeed_th_sqlc.UpsertUserPreferencesParams{
+			UserID: user.ID, Sources: []string{"thairath"}, Tags: []string{"news"},
+		}))(t)
+		updated := must(repo.UserPreferenceRepository.UpsertUserPreferences(ctx, onefeed_th_sqlc.UpsertUserPreferencesParams{
+			UserID: user.ID, Sources: []string{"matichon", "khaosod"}, Tags: []string{},
+		}))(t)
+		expectEqual(t, "updated sources", slices.Equal(updated.Sources, []string{"matichon", "khaosod"}), true)
+
+		stored := must(repo.UserPreferenceRepository.GetUserPreferences(ctx, user.ID))(t)
+		expectEqual(t, "sources", slices.Equal(stored.Sources, []string{"matichon", "khaosod"}), true)
+		expectEqual(t, "tags", len(stored.Tags), 0)
+		expectEqual(t, "updated at", stored.UpdatedAt.Valid, true)
+	})
+}
+
+func TestMergeUsers(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repo *repository.Repository) {
+		ctx := context.Background()
+		news := insertNews(t, repo,
+			newsItem("a", "thairath", "First", day(-2)),
+			newsItem("b", "thairath", "Second", day(-1)),
+		)
+		a, b := news["a"].ID, news["b"].ID
+		device := must(repo.UserRepository.CreateDeviceUser(ctx, "device-1"))(t)
+		member := must(repo.UserRepository.CreateUser(ctx, onefeed_th_sqlc.CreateUserParams{Email: text("somchai@example.com"), DisplayName: "Somchai"}))(t)
+
+		must(repo.UserPreferenceRepository.UpsertUserPreferences(ctx, onefeed_th_sqlc.UpsertUserPreferencesParams{UserID: device.ID, Sources: []string{"thairath"}, Tags: []string{}}))(t)
+		mustDo(t, repo.BookmarkRepository.CreateBookmark(ctx, onefeed_th_sqlc.CreateBookmarkParams{UserID: device.ID, NewsID: a}))
+		mustDo(t, repo.BookmarkRepository.CreateBookmark(ctx, onefeed_th_sqlc.CreateBookmarkParams{UserID: member.ID, NewsID: a}))
+		mustDo(t, repo.BookmarkRepository.CreateBookmark(ctx, onefeed_th_sqlc.CreateBookmarkParams{UserID: device.ID, NewsID: b}))
+		mustDo(t, repo.NewsReadRepository.CreateNewsReads(ctx, onefeed_th_sqlc.CreateNewsReadsParams{UserID: device.ID, NewsIds: []int64{a, b}}))
+		mustDo(t, repo.NewsReadRepository.CreateNewsReads(ctx, onefeed_th_sqlc.CreateNewsReadsParams{UserID: member.ID, NewsIds: []int64{a}}))
+		suggestion := must(repo.SourceSuggestionRepository.CreateSourceSuggestion(ctx, onefeed_th_sqlc.CreateSourceSuggestionParams{
+			UserID: device.ID, Name: "prachatai", RssUrl: "https://prachatai.example.com/rss",
+		}))(t)
+		search := must(repo.SavedSearchRepository.CreateSavedSearch(ctx, onefeed_th_sqlc.CreateSavedSearchParams{
+			UserID: device.ID, Name: "Floods", Query: "flood", Sources: []string{},
+		}))(t)
+
+		mustDo(t, repo.UserRepository.MergeUsers(ctx, device.ID, member.ID))
+
+		_, err := repo.UserRepository.GetUserByID(ctx, device.ID)
+		expectNoRows(t, err)
+		prefs := must(repo.UserPreferenceRepository.GetUserPreferences(ctx, member.ID))(t)
+		expectEqual(t, "merged preferences", slices.Equal(prefs.Sources, []string{"thairath"}), true)
+		bookmarks := must(repo.BookmarkRepository.GetBookmarks(ctx, onefeed_th_sqlc.ListBookmarksParams{UserID: member.ID, PageLimit: 10}))(t)
+		merged := ids(bookmarks, func(b onefeed_th_sqlc.Bookmark) int64 { return b.NewsID })
+		slices.Sort(merged)
+		expectIDs(t, "merged bookmarks", merged, []int64{a, b})
+		reads := must(repo.NewsReadRepository.GetReadNewsIDs(ctx, onefeed_th_sqlc.ListReadNewsIDsParams{UserID: member.ID, NewsIds: []int64{a, b}}))(t)
+		slices.Sort(reads)
+		expectIDs(t, "merged reads", reads, []int64{a, b})
+		expectEqual(t, "moved suggestion", must(repo.SourceSuggestionRepository.GetSourceSuggestionByID(ctx, suggestion.ID))(t).UserID, member.ID)
+		expectEqual(t, "moved search", must(repo.SavedSearchRepository.GetSavedSearch(ctx, onefeed_th_sqlc.GetSavedSearchParams{ID: search.ID, UserID: member.ID}))(t).Name, "Floods")
+
+		// the account keeps its own preferences over those of the merged device
+		other := must(repo.UserRepository.CreateDeviceUser(ctx, "device-2"))(t)
+		must(repo.UserPreferenceRepository.UpsertUserPreferences(ctx, onefeed_th_sqlc.UpsertUserPreferencesParams{UserID: other.ID, Sources: []string{"matichon"}, Tags: []string{}}))(t)
+		mustDo(t, repo.UserRepository.MergeUsers(ctx, other.ID, member.ID))
+		prefs = must(repo.UserPreferenceRepository.GetUserPreferences(ctx, member.ID))(t)
+		expectEqual(t, "kept preferences", slices.Equal(prefs.Sources, []string{"thairath"}), true)
+	})
+}
+
+func TestSavedSearches(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repo *repository.Repository) {
+		ctx := context.Background()
+		user := must(repo.UserRepository.CreateDeviceUser(ctx, "device-1"))(t)
+		other := must(repo.UserRepository.CreateDeviceUser(ctx, "device-2"))(t)
+		create := func(userID int64, name string, pushToken, lineUserID pgtype.Text) onefeed_th_sqlc.SavedSearch {
+			return must(repo.SavedSearchRepository.CreateSavedSearch(ctx, onefeed_th_sqlc.CreateSavedSearchParams{
+				UserID: userID, Name: name, Query: name, Sources: []string{"thairath"}, PushToken: pushToken, LineUserID: lineUserID,
+			}))(t)
+		}
+		pushed := create(user.ID, "flood", text("token"), pgtype.Text{})
+		quiet := create(user.ID, "election", pgtype.Text{}, pgtype.Text{})
+		lined := create(other.ID, "traffic", pgtype.Text{}, text("line-user"))
+		searchID := func(s onefeed_th_sqlc.SavedSearch) int64 { return s.ID }
+
+		expectEqual(t, "count", must(repo.SavedSearchRepository.CountSavedSearches(ctx, user.ID))(t), 2)
+		expectIDs(t, "searches", ids(must(repo.SavedSearchRepository.GetSavedSearches(ctx, user.ID))(t), searchID), []int64{pushed.ID, quiet.ID})
+		expectIDs(t, "notifying", ids(must(repo.SavedSearchRepository.GetNotifyingSavedSearches(ctx))(t), searchID), []int64{pushed.ID, lined.ID})
+		_, err := repo.SavedSearchRepository.GetSavedSearch(ctx, onefeed_th_sqlc.GetSavedSearchParams{ID: lined.ID, UserID: user.ID})
+		expectNoRows(t, err)
+
+		updated := must(repo.SavedSearchRepository.UpdateSavedSearch(ctx, onefeed_th_sqlc.UpdateSavedSearchParams{
+			Name: "elections", Query: "election", Sources: []string{"matichon"}, LineUserID: text("line-user"), ID: quiet.ID, UserID: user.ID,
+		}))(t)
+		expectEqual(t, "updated name", updated.Name, "elections")
+		expectEqual(t, "updated sources", slices.Equal(updated.Sources, []string{"matichon"}), true)
+		_, err = repo.SavedSearchRepository.UpdateSavedSearch(ctx, onefeed_th_sqlc.UpdateSavedSearchParams{Name: "stolen", ID: lined.ID, UserID: user.ID})
+		expectNoRows(t, err)
+
+		mustDo(t, repo.SavedSearchRepository.TouchSavedSearchesNotified(ctx, []int64{pushed.ID}))
+		touched := must(repo.SavedSearchRepository.GetSavedSearch(ctx, onefeed_th_sqlc.GetSavedSearchParams{ID: pushed.ID, UserID: user.ID}))(t)
+		expectEqual(t, "notified", touched.NotifiedAt.Valid, true)
+
+		// an unregistered push token stops notifying every search that used it
+		mustDo(t, repo.SavedSearchRepository.ClearSavedSearchPushToken(ctx, text("token")))
+		expectIDs(t, "notifying after clear", ids(must(repo.SavedSearchRepository.GetNotifyingSavedSearches(ctx))(t), searchID), []int64{quiet.ID, lined.ID})
+
+		expectEqual(t, "deleted by another user", must(repo.SavedSearchRepository.DeleteSavedSearch(ctx, onefeed_th_sqlc.DeleteSavedSearchParams{ID: pushed.ID, UserID: other.ID}))(t), 0)
+		expectEqual(t, "deleted", must(repo.SavedSearchRepository.DeleteSavedSearch(ctx, onefeed_th_sqlc.DeleteSavedSearchParams{ID: pushed.ID, UserID: user.ID}))(t), 1)
+		expectEqual(t, "count after delete", must(repo.SavedSearchRepository.CountSavedSearches(ctx, user.ID))(t), 1)
+	})
+}
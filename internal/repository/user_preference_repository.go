@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	onefeed_th_sqlc "github.com/onefeed-th/onefeed-th-backend-api/internal/sqlc/onefeed_th_sqlc/db"
+)
+
+type UserPreferenceRepository interface {
+	GetUserPreferences(ctx context.Context, userID int64) (onefeed_th_sqlc.UserPreference, error)
+	UpsertUserPreferences(ctx context.Context, params onefeed_th_sqlc.UpsertUserPreferencesParams) (onefeed_th_sqlc.UserPreference, error)
+}
+
+type UserPreferenceRepositoryImpl struct {
+	pool dbPool
+}
+
+func NewUserPreferenceRepository(pool func() *pgxpool.Pool) UserPreferenceRepository {
+	return &UserPreferenceRepositoryImpl{
+		pool: pool,
+	}
+}
+
+func (r *UserPreferenceRepositoryImpl) GetUserPreferences(ctx context.Context, userID int64) (onefeed_th_sqlc.UserPreference, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) (onefeed_th_sqlc.UserPreference, error) {
+		query := onefeed_th_sqlc.New(r.pool)
+		return query.GetUserPreferences(ctx, userID)
+	})
+}
+
+func (r *UserPreferenceRepositoryImpl) UpsertUserPreferences(ctx context.Context, params onefeed_th_sqlc.UpsertUserPreferencesParams) (onefeed_th_sqlc.UserPreference, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := onefeed_th_sqlc.New(r.pool)
+	return query.UpsertUserPreferences(ctx, params)
+}
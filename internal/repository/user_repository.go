@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	onefeed_th_sqlc "github.com/onefeed-th/onefeed-th-backend-api/internal/sqlc/onefeed_th_sqlc/db"
+)
+
+type UserRepository interface {
+	CreateDeviceUser(ctx context.Context, deviceID string) (onefeed_th_sqlc.User, error)
+	CreateUser(ctx context.Context, params onefeed_th_sqlc.CreateUserParams) (onefeed_th_sqlc.User, error)
+	CreateUserIdentity(ctx context.Context, params onefeed_th_sqlc.CreateUserIdentityParams) error
+	DeleteUser(ctx context.Context, id int64) (int64, error)
+	GetUserByDeviceID(ctx context.Context, deviceID string) (onefeed_th_sqlc.User, error)
+	GetUserByEmail(ctx context.Context, email string) (onefeed_th_sqlc.User, error)
+	GetUserByID(ctx context.Context, id int64) (onefeed_th_sqlc.User, error)
+	GetUserByIdentity(ctx context.Context, params onefeed_th_sqlc.GetUserByIdentityParams) (onefeed_th_sqlc.User, error)
+	LinkUserIdentity(ctx context.Context, params onefeed_th_sqlc.CreateUserIdentityParams) error
+	MergeUsers(ctx context.Context, fromID, toID int64) error
+	UpdateUserLastLogin(ctx context.Context, id int64) error
+	UpdateUserProfile(ctx context.Context, params onefeed_th_sqlc.UpdateUserProfileParams) (onefeed_th_sqlc.User, error)
+}
+
+type UserRepositoryImpl struct {
+	pool dbPool
+}
+
+func NewUserRepository(pool func() *pgxpool.Pool) UserRepository {
+	return &UserRepositoryImpl{
+		pool: pool,
+	}
+}
+
+func (r *UserRepositoryImpl) CreateDeviceUser(ctx context.Context, deviceID string) (onefeed_th_sqlc.User, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := onefeed_th_sqlc.New(r.pool)
+	return query.CreateDeviceUser(ctx, pgtype.Text{String: deviceID, Valid: true})
+}
+
+func (r *UserRepositoryImpl) CreateUser(ctx context.Context, params onefeed_th_sqlc.CreateUserParams) (onefeed_th_sqlc.User, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := onefeed_th_sqlc.New(r.pool)
+	return query.CreateUser(ctx, params)
+}
+
+func (r *UserRepositoryImpl) CreateUserIdentity(ctx context.Context, params onefeed_th_sqlc.CreateUserIdentityParams) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := onefeed_th_sqlc.New(r.pool)
+	return query.CreateUserIdentity(ctx, params)
+}
+
+func (r *UserRepositoryImpl) DeleteUser(ctx context.Context, id int64) (int64, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := onefeed_th_sqlc.New(r.pool)
+	return query.DeleteUser(ctx, id)
+}
+
+func (r *UserRepositoryImpl) GetUserByDeviceID(ctx context.Context, deviceID string) (onefeed_th_sqlc.User, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) (onefeed_th_sqlc.User, error) {
+		query := onefeed_th_sqlc.New(r.pool)
+		return query.GetUserByDeviceID(ctx, pgtype.Text{String: deviceID, Valid: true})
+	})
+}
+
+func (r *UserRepositoryImpl) GetUserByEmail(ctx context.Context, email string) (onefeed_th_sqlc.User, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) (onefeed_th_sqlc.User, error) {
+		query := onefeed_th_sqlc.New(r.pool)
+		return query.GetUserByEmail(ctx, pgtype.Text{String: email, Valid: true})
+	})
+}
+
+func (r *UserRepositoryImpl) GetUserByID(ctx context.Context, id int64) (onefeed_th_sqlc.User, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) (onefeed_th_sqlc.User, error) {
+		query := onefeed_th_sqlc.New(r.pool)
+		return query.GetUserByID(ctx, id)
+	})
+}
+
+func (r *UserRepositoryImpl) GetUserByIdentity(ctx context.Context, params onefeed_th_sqlc.GetUserByIdentityParams) (onefeed_th_sqlc.User, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) (onefeed_th_sqlc.User, error) {
+		query := onefeed_th_sqlc.New(r.pool)
+		return query.GetUserByIdentity(ctx, params)
+	})
+}
+
+// LinkUserIdentity links a provider account to an existing user and drops the user's
+// password in the same transaction, so whoever registered the email can't sign in anymore
+func (r *UserRepositoryImpl) LinkUserIdentity(ctx context.Context, params onefeed_th_sqlc.CreateUserIdentityParams) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := onefeed_th_sqlc.New(r.pool).WithTx(tx)
+
+	if err := query.CreateUserIdentity(ctx, params); err != nil {
+		return err
+	}
+	if err := query.ClearUserPassword(ctx, params.UserID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// MergeUsers moves the preferences, bookmarks, read history, source suggestions and saved
+// searches of one user into another and deletes it, all in one transaction
+func (r *UserRepositoryImpl) MergeUsers(ctx context.Context, fromID, toID int64) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := onefeed_th_sqlc.New(r.pool).WithTx(tx)
+
+	if err := query.MergeUserPreferences(ctx, onefeed_th_sqlc.MergeUserPreferencesParams{ToUserID: toID, FromUserID: fromID}); err != nil {
+		return err
+	}
+	if err := query.MergeBookmarks(ctx, onefeed_th_sqlc.MergeBookmarksParams{ToUserID: toID, FromUserID: fromID}); err != nil {
+		return err
+	}
+	if err := query.MergeNewsReads(ctx, onefeed_th_sqlc.MergeNewsReadsParams{ToUserID: toID, FromUserID: fromID}); err != nil {
+		return err
+	}
+	if err := query.MergeSourceSuggestions(ctx, onefeed_th_sqlc.MergeSourceSuggestionsParams{ToUserID: toID, FromUserID: fromID}); err != nil {
+		return err
+	}
+	if err := query.MergeSavedSearches(ctx, onefeed_th_sqlc.MergeSavedSearchesParams{ToUserID: toID, FromUserID: fromID}); err != nil {
+		return err
+	}
+	if _, err := query.DeleteUser(ctx, fromID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *UserRepositoryImpl) UpdateUserLastLogin(ctx context.Context, id int64) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := onefeed_th_sqlc.New(r.pool)
+	return query.UpdateUserLastLogin(ctx, id)
+}
+
+func (r *UserRepositoryImpl) UpdateUserProfile(ctx context.Context, params onefeed_th_sqlc.UpdateUserProfileParams) (onefeed_th_sqlc.User, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := onefeed_th_sqlc.New(r.pool)
+	return query.UpdateUserProfile(ctx, params)
+}
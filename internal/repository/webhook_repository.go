@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	onefeed_th_sqlc "github.com/onefeed-th/onefeed-th-backend-api/internal/sqlc/onefeed_th_sqlc/db"
+)
+
+type WebhookRepository interface {
+	CreateWebhook(ctx context.Context, params onefeed_th_sqlc.CreateWebhookParams) (onefeed_th_sqlc.Webhook, error)
+	CreateWebhookFailure(ctx context.Context, params onefeed_th_sqlc.CreateWebhookFailureParams) error
+	DeleteWebhook(ctx context.Context, id int64) (int64, error)
+	GetEnabledWebhooks(ctx context.Context) ([]onefeed_th_sqlc.Webhook, error)
+	GetWebhookByID(ctx context.Context, id int64) (onefeed_th_sqlc.Webhook, error)
+	GetWebhookFailures(ctx context.Context, params onefeed_th_sqlc.ListWebhookFailuresParams) ([]onefeed_th_sqlc.WebhookFailure, error)
+	GetWebhooks(ctx context.Context) ([]onefeed_th_sqlc.Webhook, error)
+	UpdateWebhook(ctx context.Context, params onefeed_th_sqlc.UpdateWebhookParams) (onefeed_th_sqlc.Webhook, error)
+}
+
+type WebhookRepositoryImpl struct {
+	pool dbPool
+}
+
+func NewWebhookRepository(pool func() *pgxpool.Pool) WebhookRepository {
+	return &WebhookRepositoryImpl{
+		pool: pool,
+	}
+}
+
+func (r *WebhookRepositoryImpl) CreateWebhook(ctx context.Context, params onefeed_th_sqlc.CreateWebhookParams) (onefeed_th_sqlc.Webhook, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := onefeed_th_sqlc.New(r.pool)
+	return query.CreateWebhook(ctx, params)
+}
+
+func (r *WebhookRepositoryImpl) CreateWebhookFailure(ctx context.Context, params onefeed_th_sqlc.CreateWebhookFailureParams) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := onefeed_th_sqlc.New(r.pool)
+	return query.CreateWebhookFailure(ctx, params)
+}
+
+func (r *WebhookRepositoryImpl) DeleteWebhook(ctx context.Context, id int64) (int64, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := onefeed_th_sqlc.New(r.pool)
+	return query.DeleteWebhook(ctx, id)
+}
+
+func (r *WebhookRepositoryImpl) GetEnabledWebhooks(ctx context.Context) ([]onefeed_th_sqlc.Webhook, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) ([]onefeed_th_sqlc.Webhook, error) {
+		query := onefeed_th_sqlc.New(r.pool)
+		return query.ListEnabledWebhooks(ctx)
+	})
+}
+
+func (r *WebhookRepositoryImpl) GetWebhookByID(ctx context.Context, id int64) (onefeed_th_sqlc.Webhook, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) (onefeed_th_sqlc.Webhook, error) {
+		query := onefeed_th_sqlc.New(r.pool)
+		return query.GetWebhookByID(ctx, id)
+	})
+}
+
+func (r *WebhookRepositoryImpl) GetWebhookFailures(ctx context.Context, params onefeed_th_sqlc.ListWebhookFailuresParams) ([]onefeed_th_sqlc.WebhookFailure, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) ([]onefeed_th_sqlc.WebhookFailure, error) {
+		query := onefeed_th_sqlc.New(r.pool)
+		return query.ListWebhookFailures(ctx, params)
+	})
+}
+
+func (r *WebhookRepositoryImpl) GetWebhooks(ctx context.Context) ([]onefeed_th_sqlc.Webhook, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return withRetry(ctx, func(ctx context.Context) ([]onefeed_th_sqlc.Webhook, error) {
+		query := onefeed_th_sqlc.New(r.pool)
+		return query.ListWebhooks(ctx)
+	})
+}
+
+func (r *WebhookRepositoryImpl) UpdateWebhook(ctx context.Context, params onefeed_th_sqlc.UpdateWebhookParams) (onefeed_th_sqlc.Webhook, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := onefeed_th_sqlc.New(r.pool)
+	return query.UpdateWebhook(ctx, params)
+}
@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/httpserver"
+)
+
+// registerDebug serves the runtime profiles of net/http/pprof and the expvar counters.
+// pprof.Index only serves the profiles itself under /debug/pprof/, so each profile gets
+// its own route here; the index links to them relatively
+func registerDebug(debug *httpserver.Router) {
+	debug.Get("/pprof/{$}", http.HandlerFunc(pprof.Index))
+	debug.Get("/pprof/cmdline", http.HandlerFunc(pprof.Cmdline))
+	debug.Get("/pprof/profile", http.HandlerFunc(pprof.Profile))
+	debug.Get("/pprof/symbol", http.HandlerFunc(pprof.Symbol))
+	debug.Post("/pprof/symbol", http.HandlerFunc(pprof.Symbol))
+	debug.Get("/pprof/trace", http.HandlerFunc(pprof.Trace))
+	debug.Get("/pprof/{profile}", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pprof.Handler(r.PathValue("profile")).ServeHTTP(w, r)
+	}))
+	debug.Get("/vars", expvar.Handler())
+}
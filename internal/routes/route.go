@@ -2,49 +2,423 @@ package routes
 
 import (
 	"net/http"
+	"time"
 
+	"github.com/onefeed-th/onefeed-th-backend-api/config"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/auth"
 	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/httpserver"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/middleware"
 	"github.com/onefeed-th/onefeed-th-backend-api/internal/service"
 )
 
 func RegisterRoutes(service service.Service) http.Handler {
 	mux := http.NewServeMux()
-	r := httpserver.NewRouter(mux)
+	root := httpserver.NewRouter(mux)
 
-	mux.Handle("/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	// /internal jobs run much longer than regular requests, so they get their own deadline
+	timeouts := config.GetConfig().RestServer.Timeout
+	timeout := middleware.Timeout(time.Duration(timeouts.Default) * time.Second)
+	jobsTimeout := middleware.Timeout(time.Duration(timeouts.Internal) * time.Second)
+	r := root.With(timeout)
+
+	root.NotFound(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusNotFound)
 	}))
 
 	// Server
+	registerProbes(r, service)
+
+	// docs
+	{
+		r.Get("/openapi.json", root.OpenAPIHandler("OneFeed API", "v1"))
+		r.Get("/docs", httpserver.SwaggerUIHandler("OneFeed API", "/openapi.json"))
+	}
+
+	// sitemaps of the companion website, which passes them on from its own root
+	{
+		sitemaps := r.With(middleware.ETag)
+		sitemaps.Get("/sitemap.xml",
+			httpserver.NewEndpoint(
+				service.GetSitemap,
+			),
+		)
+		sitemaps.Get("/news-sitemap.xml",
+			httpserver.NewEndpoint(
+				service.GetNewsSitemap,
+			),
+		)
+	}
+
+	// The API is served under /v1 and, until the mobile app has moved over, on the legacy
+	// unversioned paths that announce their successor with Deprecation and Sunset headers.
+	// The version is set before the timeout and panics are recovered inside the version
+	// group, so a 504 or a recovered panic is still answered in the version's envelope
+	v1 := root.Version("v1")
+	v1.Use(middleware.RecoverPanic)
+	registerAPI(v1.With(timeout), v1.With(jobsTimeout), v1, service)
+
+	legacy := root.With(middleware.Deprecation(config.GetConfig().RestServer.LegacySunset, "/v1"), middleware.RecoverPanic)
+	registerAPI(legacy.With(timeout), legacy.With(jobsTimeout), legacy, service)
+
+	return mux
+}
+
+// RegisterProbeRoutes serves only the health, readiness and version checks, for instances
+// that run the background workers without the API
+func RegisterProbeRoutes(service service.Service) http.Handler {
+	mux := http.NewServeMux()
+	root := httpserver.NewRouter(mux)
+	timeout := middleware.Timeout(time.Duration(config.GetConfig().RestServer.Timeout.Default) * time.Second)
+
+	root.NotFound(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	registerProbes(root.With(timeout), service)
+	return mux
+}
+
+func registerProbes(r *httpserver.Router, service service.Service) {
+	r.Get("/health",
+		httpserver.NewEndpoint(
+			service.HealthCheck,
+		),
+	)
+	r.Get("/ready",
+		httpserver.NewEndpoint(
+			service.Readiness,
+		),
+	)
+	r.Get("/version",
+		httpserver.NewEndpoint(
+			service.GetVersion,
+		),
+	)
+}
+
+// registerAPI registers every versioned route; jobs carries the longer /internal timeout
+// and stream has no timeout at all for connections that stay open
+func registerAPI(r, jobs, stream *httpserver.Router, service service.Service) {
+	// /internal and /backoffice require an API key or backoffice token when auth is enabled.
+	// Viewers can read, editors can also change sources and news, admins manage access
+	viewer := r.Group("")
+	editor := r.Group("")
+	admin := r.Group("")
+	// exports stream for as long as /internal jobs may run
+	exports := jobs.Group("")
+	if config.GetConfig().Auth.Enabled {
+		authenticate := middleware.Authenticate(service.AuthenticateAPIKey, service.AuthenticateToken)
+		viewer.Use(authenticate, middleware.RequireRole(auth.RoleViewer))
+		exports.Use(authenticate, middleware.RequireRole(auth.RoleViewer))
+		editor.Use(authenticate, middleware.RequireRole(auth.RoleEditor))
+		admin.Use(authenticate, middleware.RequireRole(auth.RoleAdmin))
+		jobs.Use(authenticate, middleware.RequireRole(auth.RoleEditor))
+	}
+
+	// profiles run for up to the requested seconds, so they get the /internal timeout too.
+	// Config validation refuses debug without auth; they are never served to anyone but admins
+	if config.GetConfig().RestServer.Debug && config.GetConfig().Auth.Enabled {
+		debug := jobs.Group("/internal/debug")
+		debug.Use(middleware.RequireRole(auth.RoleAdmin))
+		registerDebug(debug)
+	}
+
+	// every other route is public and throttled per reader and client IP
+	if cfg := config.GetConfig().RateLimit; cfg.Enabled {
+		limit := middleware.RateLimit(service.AuthenticateUserToken, service.CheckRateLimit, cfg.TrustedProxies)
+		r = r.With(limit)
+		stream = stream.With(limit)
+	}
+
+	// auth
 	{
-		r.Get("/health",
+		r.Post("/auth/login",
+			httpserver.NewEndpoint(
+				service.Login,
+			),
+		)
+		r.Post("/auth/refresh",
 			httpserver.NewEndpoint(
-				service.HealthCheck,
+				service.RefreshToken,
 			),
 		)
 	}
 
 	// collector
 	{
-		r.Post("/internal/collect",
+		internal := jobs.Group("/internal")
+		// mutations answer a repeated Idempotency-Key with the first response
+		mutation := internal.With(middleware.Idempotency(service.RunIdempotent))
+		// collection, retention and backfills are queued as background jobs
+		mutation.Post("/collect",
+			httpserver.NewEndpoint(
+				service.EnqueueCollect,
+			),
+		)
+		mutation.Post("/delete-old-news",
+			httpserver.NewEndpoint(
+				service.EnqueueRetention,
+			),
+		)
+		mutation.Post("/backfill/{sourceID}",
+			httpserver.NewEndpoint(
+				service.EnqueueBackfill,
+			),
+		)
+		mutation.Post("/aggregate-clicks",
+			httpserver.NewEndpoint(
+				service.AggregateNewsClicks,
+			),
+		)
+		mutation.Post("/reindex-search",
+			httpserver.NewEndpoint(
+				service.ReindexSearch,
+			),
+		)
+		internal.Get("/stats",
+			httpserver.NewEndpoint(
+				service.GetServerStats,
+			),
+		)
+		internal.Get("/cache/stats",
 			httpserver.NewEndpoint(
-				service.CollectNewsFromSource,
+				service.GetCacheStats,
 			),
 		)
-		r.Post("/internal/delete-old-news",
+		mutation.Post("/jobs",
 			httpserver.NewEndpoint(
-				service.RemoveOldNews,
+				service.EnqueueJob,
+			),
+		)
+		internal.Get("/jobs",
+			httpserver.NewEndpoint(
+				service.GetJobs,
+			),
+		)
+		mutation.Post("/jobs/{id}/retry",
+			httpserver.NewEndpoint(
+				service.RetryJob,
 			),
 		)
 	}
 
 	// news
 	{
-		r.Post("/news",
+		cached := r.With(middleware.ETag)
+		// news lists carry readState for readers with an account or device profile
+		reader := r.With(middleware.OptionalProfile(service.AuthenticateUserToken, service.AuthenticateDevice))
+		reader.With(middleware.ETag).Get("/news",
+			httpserver.NewEndpoint(
+				service.QueryNews,
+			),
+		)
+		reader.Post("/news",
 			httpserver.NewEndpoint(
 				service.GetNews,
 			),
 		)
+		stream.Get("/news/stream",
+			httpserver.NewEndpoint(
+				service.StreamNews,
+			),
+		)
+		stream.Get("/ws",
+			httpserver.NewEndpoint(
+				service.ConnectNewsSocket,
+			),
+		)
+		reader.Get("/news/search",
+			httpserver.NewEndpoint(
+				service.SearchNews,
+			),
+		)
+		r.Get("/news/trending",
+			httpserver.NewEndpoint(
+				service.GetTrendingNews,
+			),
+		)
+		reader.Get("/news/discover",
+			httpserver.NewEndpoint(
+				service.DiscoverNews,
+			),
+		)
+		reader.Get("/news/archive",
+			httpserver.NewEndpoint(
+				service.GetArchivedNews,
+			),
+		)
+		reader.Post("/news/batch",
+			httpserver.NewEndpoint(
+				service.GetNewsByIDs,
+			),
+		)
+		cached.Get("/news/{id}",
+			httpserver.NewEndpoint(
+				service.GetNewsDetail,
+			),
+		)
+		reader.Get("/news/{id}/related",
+			httpserver.NewEndpoint(
+				service.GetRelatedNews,
+			),
+		)
+		reader.Post("/news/{id}/click",
+			httpserver.NewEndpoint(
+				service.RecordNewsClick,
+			),
+		)
+	}
+
+	// users (readers)
+	{
+		// signing in from a device with an anonymous profile merges it into the account
+		signIn := r.With(middleware.DeviceID)
+		signIn.Post("/users/register",
+			httpserver.NewEndpoint(
+				service.RegisterUser,
+			),
+		)
+		signIn.Post("/users/login",
+			httpserver.NewEndpoint(
+				service.LoginUser,
+			),
+		)
+		signIn.Post("/users/oauth/{provider}",
+			httpserver.NewEndpoint(
+				service.LoginUserWithOAuth,
+			),
+		)
+		r.Post("/users/refresh",
+			httpserver.NewEndpoint(
+				service.RefreshUserToken,
+			),
+		)
+
+		me := r.Group("/users/me")
+		me.Use(middleware.RequireUser(service.AuthenticateUserToken))
+		me.Get("",
+			httpserver.NewEndpoint(
+				service.GetCurrentUser,
+			),
+		)
+		me.Patch("",
+			httpserver.NewEndpoint(
+				service.UpdateCurrentUser,
+			),
+		)
+		me.Delete("",
+			httpserver.NewEndpoint(
+				service.DeleteCurrentUser,
+			),
+		)
+
+		// readers without an account keep these on their device profile
+		profile := r.Group("/users/me")
+		profile.Use(middleware.RequireProfile(service.AuthenticateUserToken, service.AuthenticateDevice))
+		profile.Get("/reads",
+			httpserver.NewEndpoint(
+				service.GetReadHistory,
+			),
+		)
+		profile.Post("/reads",
+			httpserver.NewEndpoint(
+				service.MarkNewsRead,
+			),
+		)
+		profile.Delete("/reads/{newsId}",
+			httpserver.NewEndpoint(
+				service.UnmarkNewsRead,
+			),
+		)
+		profile.Get("/preferences",
+			httpserver.NewEndpoint(
+				service.GetPreferences,
+			),
+		)
+		profile.Put("/preferences",
+			httpserver.NewEndpoint(
+				service.UpdatePreferences,
+			),
+		)
+		profile.Get("/bookmarks",
+			httpserver.NewEndpoint(
+				service.GetBookmarks,
+			),
+		)
+		profile.Post("/bookmarks",
+			httpserver.NewEndpoint(
+				service.AddBookmark,
+			),
+		)
+		profile.Delete("/bookmarks/{newsId}",
+			httpserver.NewEndpoint(
+				service.RemoveBookmark,
+			),
+		)
+		profile.Get("/source-suggestions",
+			httpserver.NewEndpoint(
+				service.GetMySourceSuggestions,
+			),
+		)
+		profile.Get("/searches",
+			httpserver.NewEndpoint(
+				service.GetSavedSearches,
+			),
+		)
+		profile.Post("/searches",
+			httpserver.NewEndpoint(
+				service.CreateSavedSearch,
+			),
+		)
+		profile.Patch("/searches/{id}",
+			httpserver.NewEndpoint(
+				service.UpdateSavedSearch,
+			),
+		)
+		profile.Delete("/searches/{id}",
+			httpserver.NewEndpoint(
+				service.DeleteSavedSearch,
+			),
+		)
+	}
+
+	// source suggestions, from readers with an account or device profile
+	{
+		suggest := r.With(middleware.RequireProfile(service.AuthenticateUserToken, service.AuthenticateDevice))
+		suggest.Post("/sources/suggest",
+			httpserver.NewEndpoint(
+				service.SuggestSource,
+			),
+		)
+	}
+
+	// push notifications
+	{
+		r.Post("/devices",
+			httpserver.NewEndpoint(
+				service.RegisterPushDevice,
+			),
+		)
+		r.Delete("/devices/{token}",
+			httpserver.NewEndpoint(
+				service.UnregisterPushDevice,
+			),
+		)
+	}
+
+	// feeds
+	{
+		feeds := r.Group("/feeds")
+		feeds.Use(middleware.ETag)
+		feeds.Get("/rss",
+			httpserver.NewEndpoint(
+				service.GetRSSFeed,
+			),
+		)
+		feeds.Get("/atom",
+			httpserver.NewEndpoint(
+				service.GetAtomFeed,
+			),
+		)
 	}
 
 	// tags
@@ -58,17 +432,290 @@ func RegisterRoutes(service service.Service) http.Handler {
 
 	// backoffice
 	{
-		r.Post("/backoffice/get-sources",
+		readOnly := viewer.Group("/backoffice")
+		readOnly.Get("/dashboard",
+			httpserver.NewEndpoint(
+				service.GetDashboard,
+			),
+		)
+		readOnly.Post("/get-sources",
 			httpserver.NewEndpoint(
 				service.GetAllSourceByPagination,
 			),
 		)
-		r.Post("/backoffice/create-source",
+		readOnly.Get("/sources",
+			httpserver.NewEndpoint(
+				service.GetSources,
+			),
+		)
+		readOnly.Get("/sources/{id}/preview",
+			httpserver.NewEndpoint(
+				service.PreviewSource,
+			),
+		)
+		readOnly.Get("/sources/pending",
+			httpserver.NewEndpoint(
+				service.GetPendingSourceSuggestions,
+			),
+		)
+		exports.Get("/backoffice/news/export",
+			httpserver.NewEndpoint(
+				service.ExportNews,
+			),
+		)
+		readOnly.Get("/news/{id}/audit",
+			httpserver.NewEndpoint(
+				service.GetNewsModerationLogs,
+			),
+		)
+		readOnly.Get("/tags",
+			httpserver.NewEndpoint(
+				service.GetBackofficeTags,
+			),
+		)
+		readOnly.Get("/ranking-metrics",
+			httpserver.NewEndpoint(
+				service.GetRankingMetrics,
+			),
+		)
+		readOnly.Get("/analytics/sources",
+			httpserver.NewEndpoint(
+				service.GetSourceAnalytics,
+			),
+		)
+		readOnly.Get("/rate-limits/offenders",
+			httpserver.NewEndpoint(
+				service.GetRateLimitOffenders,
+			),
+		)
+		readOnly.Get("/rate-limits/blocks",
+			httpserver.NewEndpoint(
+				service.GetRateLimitBlocks,
+			),
+		)
+
+		backoffice := editor.Group("/backoffice")
+		backoffice.Post("/create-source",
 			httpserver.NewEndpoint(
 				service.CreateSource,
 			),
 		)
+		backoffice.Post("/sources/bulk",
+			httpserver.NewEndpoint(
+				service.BulkCreateSources,
+			),
+		)
+		backoffice.Post("/sources/validate",
+			httpserver.NewEndpoint(
+				service.ValidateSource,
+			),
+		)
+		backoffice.Post("/sources/pending/{id}/approve",
+			httpserver.NewEndpoint(
+				service.ApproveSourceSuggestion,
+			),
+		)
+		backoffice.Post("/sources/pending/{id}/reject",
+			httpserver.NewEndpoint(
+				service.RejectSourceSuggestion,
+			),
+		)
+		backoffice.Put("/sources/{id}",
+			httpserver.NewEndpoint(
+				service.UpdateSource,
+			),
+		)
+		backoffice.Delete("/sources/{id}",
+			httpserver.NewEndpoint(
+				service.DeleteSource,
+			),
+		)
+		backoffice.Post("/sources/{id}/enable",
+			httpserver.NewEndpoint(
+				service.EnableSource,
+			),
+		)
+		backoffice.Post("/sources/{id}/disable",
+			httpserver.NewEndpoint(
+				service.DisableSource,
+			),
+		)
+		backoffice.Post("/tags",
+			httpserver.NewEndpoint(
+				service.CreateTag,
+			),
+		)
+		backoffice.Put("/tags/{id}",
+			httpserver.NewEndpoint(
+				service.UpdateTag,
+			),
+		)
+		backoffice.Delete("/tags/{id}",
+			httpserver.NewEndpoint(
+				service.DeleteTag,
+			),
+		)
+		backoffice.Delete("/news/{id}",
+			httpserver.NewEndpoint(
+				service.HideNews,
+			),
+		)
+		backoffice.Post("/news/{id}/restore",
+			httpserver.NewEndpoint(
+				service.RestoreNews,
+			),
+		)
 	}
 
-	return mux
+	// configuration, which names hosts and accounts even with the secrets masked
+	{
+		admin.Get("/internal/config",
+			httpserver.NewEndpoint(
+				service.GetConfig,
+			),
+		)
+	}
+
+	// log levels
+	{
+		admin.Get("/internal/log-level",
+			httpserver.NewEndpoint(
+				service.GetLogLevels,
+			),
+		)
+		admin.Put("/internal/log-level",
+			httpserver.NewEndpoint(
+				service.UpdateLogLevel,
+			),
+		)
+	}
+
+	// api keys
+	{
+		apiKeys := admin.Group("/backoffice/api-keys")
+		apiKeys.Get("",
+			httpserver.NewEndpoint(
+				service.GetAPIKeys,
+			),
+		)
+		apiKeys.Post("",
+			httpserver.NewEndpoint(
+				service.CreateAPIKey,
+			),
+		)
+		apiKeys.Delete("/{id}",
+			httpserver.NewEndpoint(
+				service.RevokeAPIKey,
+			),
+		)
+	}
+
+	// rate limits
+	{
+		admin.Delete("/backoffice/rate-limits/blocks/{subject}",
+			httpserver.NewEndpoint(
+				service.RemoveRateLimitBlock,
+			),
+		)
+	}
+
+	// webhooks
+	{
+		webhooks := admin.Group("/backoffice/webhooks")
+		webhooks.Get("",
+			httpserver.NewEndpoint(
+				service.GetWebhooks,
+			),
+		)
+		webhooks.Post("",
+			httpserver.NewEndpoint(
+				service.CreateWebhook,
+			),
+		)
+		webhooks.Patch("/{id}",
+			httpserver.NewEndpoint(
+				service.UpdateWebhook,
+			),
+		)
+		webhooks.Delete("/{id}",
+			httpserver.NewEndpoint(
+				service.DeleteWebhook,
+			),
+		)
+		webhooks.Get("/{id}/failures",
+			httpserver.NewEndpoint(
+				service.GetWebhookFailures,
+			),
+		)
+	}
+
+	// dead letters, the feed items the collector rejected
+	{
+		deadLetters := admin.Group("/backoffice/dead-letters")
+		deadLetters.Get("",
+			httpserver.NewEndpoint(
+				service.GetDeadLetters,
+			),
+		)
+		deadLetters.Get("/{id}",
+			httpserver.NewEndpoint(
+				service.GetDeadLetter,
+			),
+		)
+		deadLetters.Post("/{id}/reprocess",
+			httpserver.NewEndpoint(
+				service.ReprocessDeadLetter,
+			),
+		)
+		deadLetters.Delete("/{id}",
+			httpserver.NewEndpoint(
+				service.DeleteDeadLetter,
+			),
+		)
+	}
+
+	// notification rules
+	{
+		rules := admin.Group("/backoffice/notification-rules")
+		rules.Get("",
+			httpserver.NewEndpoint(
+				service.GetNotificationRules,
+			),
+		)
+		rules.Post("",
+			httpserver.NewEndpoint(
+				service.CreateNotificationRule,
+			),
+		)
+		rules.Patch("/{id}",
+			httpserver.NewEndpoint(
+				service.UpdateNotificationRule,
+			),
+		)
+		rules.Delete("/{id}",
+			httpserver.NewEndpoint(
+				service.DeleteNotificationRule,
+			),
+		)
+	}
+
+	// backoffice users
+	{
+		users := admin.Group("/backoffice/users")
+		users.Get("",
+			httpserver.NewEndpoint(
+				service.GetBackofficeUsers,
+			),
+		)
+		users.Post("",
+			httpserver.NewEndpoint(
+				service.CreateBackofficeUser,
+			),
+		)
+		users.Patch("/{id}",
+			httpserver.NewEndpoint(
+				service.UpdateBackofficeUser,
+			),
+		)
+	}
 }
@@ -0,0 +1,307 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/logger"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/utils/converter"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/dto"
+	apperrors "github.com/onefeed-th/onefeed-th-backend-api/internal/errors"
+	onefeed_th_sqlc "github.com/onefeed-th/onefeed-th-backend-api/internal/sqlc/onefeed_th_sqlc/db"
+)
+
+// AnalyticsService reports per-source statistics from the daily rollups of the analytics job
+type AnalyticsService interface {
+	GetSourceAnalytics(ctx context.Context, req dto.SourceAnalyticsRequest) ([]dto.SourceAnalyticsResponse, error)
+}
+
+const (
+	// impressions are counted per source in a Redis hash per UTC day and rolled up into
+	// source_stats_daily by the analytics job; the key leaves out "news", whose keys every
+	// collection drops
+	impressionsKeyPrefix = "analytics:impressions:day="
+	impressionsLayout    = "20060102"
+	impressionsTTL       = 8 * 24 * time.Hour
+
+	// analyticsDays is how many days each run rolls up again, today included, so clicks
+	// flushed late and a missed run are caught up
+	analyticsDays = 3
+	// analyticsRunDelay lets the clicks of the last hour of a day reach Redis before it is
+	// rolled up a final time
+	analyticsRunDelay = 15 * time.Minute
+	// analyticsArticleRetention is how long the daily clicks per article are kept for the top
+	// articles; the per-source totals are kept as long as their source
+	analyticsArticleRetention = 400 * 24 * time.Hour
+
+	defaultAnalyticsDays = 30
+	maxAnalyticsRange    = 366 * 24 * time.Hour
+	defaultAnalyticsTop  = 5
+)
+
+// recordSourceImpressions counts the news of a served page of /news against their sources
+func (s *service) recordSourceImpressions(ctx context.Context, items []dto.NewsListGetResponse) {
+	counts := make(map[string]int64)
+	for _, item := range items {
+		counts[item.Source]++
+	}
+
+	key := impressionsKeyPrefix + time.Now().UTC().Format(impressionsLayout)
+	for source, incr := range counts {
+		if err := s.redis.HashIncrBy(ctx, key, source, incr, impressionsTTL); err != nil {
+			slog.Warn("Failed to record source impressions",
+				"key", key,
+				"source", source,
+				"error", err,
+			)
+			return
+		}
+	}
+}
+
+// aggregateAnalytics flushes the click counters, then rolls the last analyticsDays days up per
+// source. The rollups are absolute totals, so running the job repeatedly is safe
+func (s *service) aggregateAnalytics(ctx context.Context) (dto.AnalyticsRunResponse, error) {
+	var result dto.AnalyticsRunResponse
+	if _, err := s.AggregateNewsClicks(ctx, dto.BlankRequest{}); err != nil {
+		return result, err
+	}
+
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	sources := make(map[string]struct{})
+	for i := range analyticsDays {
+		day := today.AddDate(0, 0, -i)
+		key := impressionsKeyPrefix + day.Format(impressionsLayout)
+		counters, err := s.redis.HashGetAll(ctx, key)
+		if err != nil {
+			return result, apperrors.Wrapf(err, apperrors.RedisError, "failed to read impressions %s", key).
+				WithCode("CACHE_GET_FAILED").
+				WithCaller()
+		}
+
+		names := make([]string, 0, len(counters))
+		impressions := make([]int64, 0, len(counters))
+		for source, value := range counters {
+			count, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				continue
+			}
+			names = append(names, source)
+			impressions = append(impressions, count)
+			sources[source] = struct{}{}
+		}
+
+		err = s.repo.SourceStatsRepository.AggregateSourceStats(ctx, onefeed_th_sqlc.UpsertSourceStatsDailyParams{
+			Day:               converter.TimeToPGTypeTimestamp(day),
+			ImpressionSources: names,
+			Impressions:       impressions,
+		})
+		if err != nil {
+			return result, apperrors.Wrap(err, apperrors.DatabaseError, "failed to store source stats").
+				WithCode("DB_UPSERT_FAILED").
+				WithDetails(fmt.Sprintf("day: %s", day.Format(time.DateOnly))).
+				WithCaller()
+		}
+		result.Days++
+	}
+	result.Sources = len(sources)
+
+	before := converter.TimeToPGTypeTimestamp(today.Add(-analyticsArticleRetention))
+	if err := s.repo.SourceStatsRepository.RemoveNewsClicksDailyBefore(ctx, before); err != nil {
+		slog.Warn("Failed to prune old article click rollups", "error", err)
+	}
+
+	logger.For("jobs").Info("Source analytics aggregated",
+		"days", result.Days,
+		"sources", result.Sources,
+	)
+	return result, nil
+}
+
+// nextAnalyticsRun is shortly after the next UTC midnight, when the day before is complete
+func nextAnalyticsRun(now time.Time) time.Time {
+	now = now.UTC()
+	return time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC).Add(analyticsRunDelay)
+}
+
+// GetSourceAnalytics reports per source the news collected, their impressions in /news and
+// their clicks over a range of days, as totals, a series and the most clicked articles.
+// Today only counts up to the last run of the analytics job
+func (s *service) GetSourceAnalytics(ctx context.Context, req dto.SourceAnalyticsRequest) ([]dto.SourceAnalyticsResponse, error) {
+	now := time.Now().UTC()
+	to := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+	from := to.AddDate(0, 0, -defaultAnalyticsDays)
+
+	if req.From != "" {
+		parsed, err := time.Parse(time.DateOnly, req.From)
+		if err != nil {
+			return nil, apperrors.Wrap(err, apperrors.ValidationError, "from must be YYYY-MM-DD").
+				WithCode("INVALID_FROM").
+				WithCaller()
+		}
+		from = parsed
+	}
+	if req.To != "" {
+		parsed, err := time.Parse(time.DateOnly, req.To)
+		if err != nil {
+			return nil, apperrors.Wrap(err, apperrors.ValidationError, "to must be YYYY-MM-DD").
+				WithCode("INVALID_TO").
+				WithCaller()
+		}
+		to = parsed.AddDate(0, 0, 1)
+	}
+
+	if !from.Before(to) {
+		return nil, apperrors.New(apperrors.ValidationError, "from must be before to").
+			WithCode("INVALID_RANGE").
+			WithCaller()
+	}
+	if to.Sub(from) > maxAnalyticsRange {
+		return nil, apperrors.New(apperrors.ValidationError, "analytics range must not exceed one year").
+			WithCode("RANGE_TOO_LARGE").
+			WithCaller()
+	}
+	if req.Interval == "" {
+		req.Interval = "day"
+	}
+	if req.Top == 0 {
+		req.Top = defaultAnalyticsTop
+	}
+
+	all, err := s.cachedSources(ctx)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve sources").
+			WithCode("DB_QUERY_FAILED").
+			WithCaller()
+	}
+	sources := all
+	if len(req.SourceID) > 0 {
+		sources = make([]onefeed_th_sqlc.Source, 0, len(req.SourceID))
+		for _, id := range req.SourceID {
+			i := slices.IndexFunc(all, func(source onefeed_th_sqlc.Source) bool { return source.ID == id })
+			if i < 0 {
+				return nil, apperrors.Newf(apperrors.NotFoundError, "source %d not found", id).
+					WithCode("SOURCE_NOT_FOUND")
+			}
+			sources = append(sources, all[i])
+		}
+	}
+
+	stats, err := s.repo.SourceStatsRepository.GetSourceStats(ctx, onefeed_th_sqlc.ListSourceStatsParams{
+		Interval:  req.Interval,
+		FromDate:  converter.TimeToPGTypeTimestamp(from),
+		ToDate:    converter.TimeToPGTypeTimestamp(to),
+		SourceIds: req.SourceID,
+	})
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve source stats").
+			WithCode("DB_QUERY_FAILED").
+			WithCaller()
+	}
+	top, err := s.repo.SourceStatsRepository.GetTopSourceNews(ctx, onefeed_th_sqlc.ListTopSourceNewsParams{
+		FromDate:  converter.TimeToPGTypeTimestamp(from),
+		ToDate:    converter.TimeToPGTypeTimestamp(to),
+		SourceIds: req.SourceID,
+		TopLimit:  req.Top,
+	})
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve top articles").
+			WithCode("DB_QUERY_FAILED").
+			WithCaller()
+	}
+
+	buckets := analyticsBuckets(req.Interval, from, to)
+	responses := make([]dto.SourceAnalyticsResponse, 0, len(sources))
+	positions := make(map[int64]int, len(sources))
+	for _, source := range sources {
+		positions[source.ID] = len(responses)
+		series := make([]dto.SourceAnalyticsPoint, 0, len(buckets))
+		for _, bucket := range buckets {
+			series = append(series, dto.SourceAnalyticsPoint{Date: bucket.Format(time.DateOnly)})
+		}
+		responses = append(responses, dto.SourceAnalyticsResponse{
+			SourceID:    source.ID,
+			Source:      source.Name,
+			Series:      series,
+			TopArticles: []dto.SourceAnalyticsArticle{},
+		})
+	}
+
+	for _, row := range stats {
+		i, ok := positions[row.SourceID]
+		if !ok {
+			continue
+		}
+		response := &responses[i]
+		response.Articles += row.Articles
+		response.Impressions += row.Impressions
+		response.Clicks += row.Clicks
+
+		j := slices.IndexFunc(buckets, func(bucket time.Time) bool { return bucket.Equal(row.Bucket.Time) })
+		if j < 0 {
+			continue
+		}
+		point := &response.Series[j]
+		point.Articles = row.Articles
+		point.Impressions = row.Impressions
+		point.Clicks = row.Clicks
+		point.CTR = clickThroughRate(row.Clicks, row.Impressions)
+	}
+	for _, row := range top {
+		if i, ok := positions[row.SourceID]; ok {
+			responses[i].TopArticles = append(responses[i].TopArticles, dto.SourceAnalyticsArticle{
+				ID:     row.NewsID,
+				Title:  row.Title,
+				Link:   row.Link,
+				Clicks: row.Clicks,
+			})
+		}
+	}
+	for i := range responses {
+		responses[i].CTR = clickThroughRate(responses[i].Clicks, responses[i].Impressions)
+	}
+
+	sort.SliceStable(responses, func(i, j int) bool {
+		return responses[i].Clicks > responses[j].Clicks
+	})
+	return responses, nil
+}
+
+// analyticsBuckets returns the first day of every interval from from up to to, aligned the
+// way date_trunc aligns them: weeks start on Monday, months on their first day
+func analyticsBuckets(interval string, from, to time.Time) []time.Time {
+	start := from
+	switch interval {
+	case "week":
+		start = from.AddDate(0, 0, -(int(from.Weekday())+6)%7)
+	case "month":
+		start = time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	var buckets []time.Time
+	for bucket := start; bucket.Before(to); {
+		buckets = append(buckets, bucket)
+		switch interval {
+		case "week":
+			bucket = bucket.AddDate(0, 0, 7)
+		case "month":
+			bucket = bucket.AddDate(0, 1, 0)
+		default:
+			bucket = bucket.AddDate(0, 0, 1)
+		}
+	}
+	return buckets
+}
+
+func clickThroughRate(clicks, impressions int64) float64 {
+	if impressions == 0 {
+		return 0
+	}
+	return float64(clicks) / float64(impressions)
+}
@@ -0,0 +1,265 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/onefeed-th/onefeed-th-backend-api/config"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/auth"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/utils/converter"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/dto"
+	apperrors "github.com/onefeed-th/onefeed-th-backend-api/internal/errors"
+	onefeed_th_sqlc "github.com/onefeed-th/onefeed-th-backend-api/internal/sqlc/onefeed_th_sqlc/db"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type AuthService interface {
+	AuthenticateAPIKey(ctx context.Context, key string) (auth.Principal, error)
+	AuthenticateToken(ctx context.Context, token string) (auth.Principal, error)
+	Login(ctx context.Context, req dto.LoginRequest) (dto.TokenResponse, error)
+	RefreshToken(ctx context.Context, req dto.RefreshTokenRequest) (dto.TokenResponse, error)
+	CreateAPIKey(ctx context.Context, req dto.APIKeyCreateRequest) (dto.APIKeyCreateResponse, error)
+	GetAPIKeys(ctx context.Context, req dto.BlankRequest) ([]dto.APIKeyResponse, error)
+	RevokeAPIKey(ctx context.Context, req dto.APIKeyRevokeRequest) (any, error)
+}
+
+// configAdminName identifies callers using a bootstrap key from auth.adminKeyHashes
+const configAdminName = "config-admin"
+
+// AuthenticateAPIKey resolves key to a principal. Hashes listed in auth.adminKeyHashes
+// are admins; keys issued through the backoffice carry the role they were created with
+func (s *service) AuthenticateAPIKey(ctx context.Context, key string) (auth.Principal, error) {
+	hash := auth.HashAPIKey(key)
+	for _, adminHash := range config.GetConfig().Auth.AdminKeyHashes {
+		if auth.HashMatches(hash, adminHash) {
+			return auth.Principal{Name: configAdminName, Role: auth.RoleAdmin, Subject: "admin-key:" + hash}, nil
+		}
+	}
+
+	apiKey, err := s.repo.APIKeyRepository.GetActiveAPIKeyByHash(ctx, hash)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return auth.Principal{}, apperrors.New(apperrors.UnauthorizedError, "invalid API key").
+			WithCode("INVALID_API_KEY")
+	}
+	if err != nil {
+		slog.Error("Failed to look up API key", "error", err)
+		return auth.Principal{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to verify API key").
+			WithCode("DB_QUERY_FAILED").
+			WithCaller()
+	}
+	return auth.Principal{
+		Name:    apiKey.Name,
+		Role:    auth.Role(apiKey.Role),
+		Subject: fmt.Sprintf("api-key:%d", apiKey.ID),
+	}, nil
+}
+
+func (s *service) CreateAPIKey(ctx context.Context, req dto.APIKeyCreateRequest) (dto.APIKeyCreateResponse, error) {
+	key, prefix, err := auth.GenerateAPIKey()
+	if err != nil {
+		return dto.APIKeyCreateResponse{}, apperrors.Wrap(err, apperrors.InternalError, "failed to generate API key").
+			WithCode("API_KEY_GENERATION_FAILED").
+			WithCaller()
+	}
+
+	role := auth.RoleEditor
+	if req.Role != "" {
+		role = auth.Role(req.Role)
+	}
+
+	apiKey, err := s.repo.APIKeyRepository.CreateAPIKey(ctx, onefeed_th_sqlc.CreateApiKeyParams{
+		Name:      req.Name,
+		KeyPrefix: prefix,
+		KeyHash:   auth.HashAPIKey(key),
+		Role:      string(role),
+	})
+	if err != nil {
+		return dto.APIKeyCreateResponse{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to store API key").
+			WithCode("DB_INSERT_FAILED").
+			WithCaller()
+	}
+
+	slog.Info("API key created",
+		"id", apiKey.ID,
+		"name", apiKey.Name,
+		"role", apiKey.Role,
+		"actor", actorFromContext(ctx),
+	)
+	return dto.APIKeyCreateResponse{
+		APIKeyResponse: toAPIKeyResponse(apiKey),
+		Key:            key,
+	}, nil
+}
+
+func (s *service) GetAPIKeys(ctx context.Context, req dto.BlankRequest) ([]dto.APIKeyResponse, error) {
+	keys, err := s.repo.APIKeyRepository.GetAPIKeys(ctx)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve API keys from database").
+			WithCode("DB_QUERY_FAILED").
+			WithCaller()
+	}
+
+	responses := make([]dto.APIKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		responses = append(responses, toAPIKeyResponse(key))
+	}
+	return responses, nil
+}
+
+func (s *service) RevokeAPIKey(ctx context.Context, req dto.APIKeyRevokeRequest) (any, error) {
+	affected, err := s.repo.APIKeyRepository.RevokeAPIKey(ctx, req.ID)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.DatabaseError, "failed to revoke API key").
+			WithCode("DB_UPDATE_FAILED").
+			WithDetails(fmt.Sprintf("id: %d", req.ID)).
+			WithCaller()
+	}
+	if affected == 0 {
+		return nil, apperrors.Newf(apperrors.NotFoundError, "active API key %d not found", req.ID).
+			WithCode("API_KEY_NOT_FOUND").
+			WithCaller()
+	}
+
+	slog.Info("API key revoked",
+		"id", req.ID,
+		"actor", actorFromContext(ctx),
+	)
+	return nil, nil
+}
+
+// AuthenticateToken verifies a backoffice access token. Access tokens are stateless, so a
+// disabled user keeps access until the token expires (auth.jwt.accessTokenTTL)
+func (s *service) AuthenticateToken(ctx context.Context, token string) (auth.Principal, error) {
+	secret := config.GetConfig().Auth.JWT.Secret
+	if secret == "" {
+		return auth.Principal{}, errJWTNotConfigured()
+	}
+
+	claims, err := auth.ParseToken([]byte(secret), token, auth.TokenTypeAccess)
+	if err != nil {
+		return auth.Principal{}, apperrors.Wrap(err, apperrors.UnauthorizedError, "invalid bearer token").
+			WithCode("INVALID_TOKEN")
+	}
+	return auth.Principal{Name: claims.Name, Role: claims.Role, Subject: "backoffice-user:" + claims.Subject}, nil
+}
+
+func (s *service) Login(ctx context.Context, req dto.LoginRequest) (dto.TokenResponse, error) {
+	if config.GetConfig().Auth.JWT.Secret == "" {
+		return dto.TokenResponse{}, errJWTNotConfigured()
+	}
+
+	user, err := s.repo.BackofficeUserRepository.GetBackofficeUserByUsername(ctx, req.Username)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return dto.TokenResponse{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve backoffice user").
+			WithCode("DB_QUERY_FAILED").
+			WithCaller()
+	}
+
+	// Unknown users are checked against a dummy hash so response time doesn't reveal usernames
+	passwordHash := dummyPasswordHash
+	if err == nil {
+		passwordHash = []byte(user.PasswordHash)
+	}
+	if bcrypt.CompareHashAndPassword(passwordHash, []byte(req.Password)) != nil || err != nil || user.Disabled {
+		slog.Warn("Backoffice login failed", "username", req.Username)
+		return dto.TokenResponse{}, apperrors.New(apperrors.UnauthorizedError, "invalid username or password").
+			WithCode("INVALID_CREDENTIALS")
+	}
+
+	slog.Info("Backoffice login", "username", user.Username, "role", user.Role)
+	return issueTokens(user)
+}
+
+// RefreshToken exchanges a refresh token for a new token pair. The user is reloaded so
+// role changes apply and disabled users are refused
+func (s *service) RefreshToken(ctx context.Context, req dto.RefreshTokenRequest) (dto.TokenResponse, error) {
+	secret := config.GetConfig().Auth.JWT.Secret
+	if secret == "" {
+		return dto.TokenResponse{}, errJWTNotConfigured()
+	}
+
+	claims, err := auth.ParseToken([]byte(secret), req.RefreshToken, auth.TokenTypeRefresh)
+	if err != nil {
+		return dto.TokenResponse{}, apperrors.Wrap(err, apperrors.UnauthorizedError, "invalid refresh token").
+			WithCode("INVALID_TOKEN")
+	}
+	userID, err := claims.UserID()
+	if err != nil {
+		return dto.TokenResponse{}, apperrors.Wrap(err, apperrors.UnauthorizedError, "invalid refresh token").
+			WithCode("INVALID_TOKEN")
+	}
+
+	user, err := s.repo.BackofficeUserRepository.GetBackofficeUserByID(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && user.Disabled) {
+		return dto.TokenResponse{}, apperrors.New(apperrors.UnauthorizedError, "user is disabled or no longer exists").
+			WithCode("INVALID_TOKEN")
+	}
+	if err != nil {
+		return dto.TokenResponse{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve backoffice user").
+			WithCode("DB_QUERY_FAILED").
+			WithCaller()
+	}
+	return issueTokens(user)
+}
+
+// dummyPasswordHash is a bcrypt hash of a random value, compared when a username is unknown
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("onefeed-dummy-password"), bcrypt.DefaultCost)
+
+func issueTokens(user onefeed_th_sqlc.BackofficeUser) (dto.TokenResponse, error) {
+	cfg := config.GetConfig().Auth.JWT
+	secret := []byte(cfg.Secret)
+	accessTTL := time.Duration(cfg.AccessTokenTTL) * time.Minute
+	refreshTTL := time.Duration(cfg.RefreshTokenTTL) * time.Hour
+	role := auth.Role(user.Role)
+
+	accessToken, _, err := auth.IssueToken(secret, user.ID, user.Username, role, auth.TokenTypeAccess, accessTTL)
+	if err != nil {
+		return dto.TokenResponse{}, apperrors.Wrap(err, apperrors.InternalError, "failed to issue access token").
+			WithCode("TOKEN_ISSUE_FAILED").
+			WithCaller()
+	}
+	refreshToken, _, err := auth.IssueToken(secret, user.ID, user.Username, role, auth.TokenTypeRefresh, refreshTTL)
+	if err != nil {
+		return dto.TokenResponse{}, apperrors.Wrap(err, apperrors.InternalError, "failed to issue refresh token").
+			WithCode("TOKEN_ISSUE_FAILED").
+			WithCaller()
+	}
+
+	return dto.TokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(accessTTL.Seconds()),
+		Role:         user.Role,
+	}, nil
+}
+
+func errJWTNotConfigured() error {
+	return apperrors.New(apperrors.UnavailableError, "backoffice login is not configured").
+		WithCode("BACKOFFICE_LOGIN_NOT_CONFIGURED")
+}
+
+// actorFromContext names the authenticated caller for audit logs, or "" when auth is off
+func actorFromContext(ctx context.Context) string {
+	principal, _ := auth.PrincipalFromContext(ctx)
+	return principal.Name
+}
+
+func toAPIKeyResponse(key onefeed_th_sqlc.ApiKey) dto.APIKeyResponse {
+	response := dto.APIKeyResponse{
+		ID:        key.ID,
+		Name:      key.Name,
+		Prefix:    key.KeyPrefix,
+		Role:      key.Role,
+		CreatedAt: converter.PGTypeTimestampToTime(key.CreatedAt),
+	}
+	if key.RevokedAt.Valid {
+		revokedAt := key.RevokedAt.Time
+		response.RevokedAt = &revokedAt
+	}
+	return response
+}
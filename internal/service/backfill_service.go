@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/mmcdole/gofeed"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/logger"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/dto"
+)
+
+const (
+	defaultBackfillPages = 10
+	maxBackfillPages     = 50
+
+	// backfillPageParam pages through a feed archive the way WordPress does, e.g. /feed/?paged=2
+	backfillPageParam = "paged"
+)
+
+// backfillJob is the payload of a backfill job
+type backfillJob struct {
+	SourceID int64 `json:"sourceId"`
+	Pages    int   `json:"pages,omitempty"`
+}
+
+// backfillSource imports the items of up to the requested pages of a source's feed archive,
+// so a new source doesn't start out with only the items its feed serves now. The walk stops
+// at the first page that fails, is empty or only repeats earlier items, which is also how
+// feeds without an archive end up read once
+func (s *service) backfillSource(ctx context.Context, payload []byte) (dto.BackfillRunResponse, error) {
+	log := logger.For("collector")
+
+	var job backfillJob
+	if err := json.Unmarshal(payload, &job); err != nil {
+		return dto.BackfillRunResponse{}, permanent(fmt.Errorf("invalid backfill payload: %w", err))
+	}
+	if job.SourceID == 0 {
+		return dto.BackfillRunResponse{}, permanent(errors.New("backfill payload has no sourceId"))
+	}
+	pages := job.Pages
+	if pages <= 0 {
+		pages = defaultBackfillPages
+	}
+	pages = min(pages, maxBackfillPages)
+
+	source, err := s.repo.SourceRepository.GetSourceByID(ctx, job.SourceID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return dto.BackfillRunResponse{}, permanent(fmt.Errorf("source %d not found", job.SourceID))
+	}
+	if err != nil {
+		return dto.BackfillRunResponse{}, err
+	}
+
+	parser := gofeed.NewParser()
+	parser.Client = &http.Client{
+		Timeout: 30 * time.Second,
+	}
+
+	result := dto.BackfillRunResponse{SourceID: source.ID}
+	rules := sourceImageRules(source)
+	cutoff := newsRetentionCutoff(time.Now())
+	seen := make(map[string]struct{})
+	var (
+		items    []bulkInsertNewsParams
+		links    []string
+		rejected []rejectedItem
+	)
+	for page := 1; page <= pages; page++ {
+		pageURL, err := archivePageURL(source.RssUrl.String, page)
+		if err != nil {
+			return result, permanent(err)
+		}
+
+		pageCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		feed, err := parser.ParseURLWithContext(pageURL, pageCtx)
+		cancel()
+		if err != nil {
+			if page == 1 {
+				return result, fmt.Errorf("failed to fetch feed of source %d: %w", source.ID, err)
+			}
+			// archives answer past their last page with an error, e.g. 404 on WordPress
+			log.Debug("Backfill stopped at a failing page", "source", source.Name, "page", page, "error", err)
+			break
+		}
+
+		added := 0
+		var pageRejected []rejectedItem
+		for _, item := range feed.Items {
+			news := newsFromFeedItem(item, source.Name, rules)
+			if news.Link != "" {
+				if _, ok := seen[news.Link]; ok {
+					continue
+				}
+				seen[news.Link] = struct{}{}
+				added++
+			}
+
+			if err := validateFeedItem(item, news); err != nil {
+				pageRejected = append(pageRejected, rejectedItem{item: item, link: news.Link, err: err})
+				continue
+			}
+			if pastRetention(news, cutoff) {
+				continue
+			}
+			items = append(items, news)
+			links = append(links, news.Link)
+		}
+		if added == 0 {
+			break
+		}
+		result.FetchedItems += len(feed.Items)
+		rejected = append(rejected, pageRejected...)
+		result.Pages = page
+		if page > 1 {
+			result.Paginated = true
+		}
+	}
+
+	result.RejectedItems = len(rejected)
+	s.recordDeadLetters(ctx, source, rejected)
+
+	// GetAllMissingLinks returns the links not stored yet
+	newLinks, err := s.repo.NewsRepository.GetAllMissingLinks(ctx, links)
+	if err != nil {
+		return result, fmt.Errorf("failed to check existing links: %w", err)
+	}
+	newLinkSet := make(map[string]struct{}, len(newLinks))
+	for _, link := range newLinks {
+		newLinkSet[link] = struct{}{}
+	}
+	newsInserts := make([]bulkInsertNewsParams, 0, len(newLinks))
+	for _, item := range items {
+		if _, ok := newLinkSet[item.Link]; ok {
+			newsInserts = append(newsInserts, item)
+		}
+	}
+
+	for i := range newsInserts {
+		summary, err := s.summarizer.Summarize(ctx, newsInserts[i].Title, newsInserts[i].Content)
+		if err != nil {
+			log.Warn("Failed to summarize news",
+				"source", source.Name,
+				"link", newsInserts[i].Link,
+				"error", err,
+			)
+			continue
+		}
+		newsInserts[i].Summary = summary
+	}
+	if err := s.insertNews(ctx, newsInserts, false); err != nil {
+		return result, err
+	}
+	result.NewItems = len(newsInserts)
+
+	log.Info("Source backfilled",
+		"source", source.Name,
+		"pages", result.Pages,
+		"paginated", result.Paginated,
+		"fetched_news", result.FetchedItems,
+		"new_news", result.NewItems,
+		"rejected_news", result.RejectedItems,
+	)
+	if result.NewItems == 0 {
+		return result, nil
+	}
+
+	// backfilled items are old news, so they aren't announced to webhooks, streams or devices
+	if err := s.redis.RemoveKeyContaining(ctx, "news"); err != nil {
+		log.Error("Error removing news cache keys", "error", err)
+	}
+	s.notifyNewsChanged(ctx, newsChangeCollect)
+	inserted := make([]string, 0, len(newsInserts))
+	for _, item := range newsInserts {
+		inserted = append(inserted, item.Link)
+	}
+	go s.indexCollectedNews(context.WithoutCancel(ctx), inserted)
+	return result, nil
+}
+
+// archivePageURL returns the address of a page of a feed archive; the first page is the feed
+func archivePageURL(feedURL string, page int) (string, error) {
+	if page == 1 {
+		return feedURL, nil
+	}
+	u, err := url.Parse(feedURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid feed url %q: %w", feedURL, err)
+	}
+	query := u.Query()
+	query.Set(backfillPageParam, strconv.Itoa(page))
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/utils/converter"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/dto"
+	apperrors "github.com/onefeed-th/onefeed-th-backend-api/internal/errors"
+	onefeed_th_sqlc "github.com/onefeed-th/onefeed-th-backend-api/internal/sqlc/onefeed_th_sqlc/db"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type BackofficeUserService interface {
+	CreateBackofficeUser(ctx context.Context, req dto.BackofficeUserCreateRequest) (dto.BackofficeUserResponse, error)
+	GetBackofficeUsers(ctx context.Context, req dto.BlankRequest) ([]dto.BackofficeUserResponse, error)
+	UpdateBackofficeUser(ctx context.Context, req dto.BackofficeUserUpdateRequest) (dto.BackofficeUserResponse, error)
+}
+
+func (s *service) CreateBackofficeUser(ctx context.Context, req dto.BackofficeUserCreateRequest) (dto.BackofficeUserResponse, error) {
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return dto.BackofficeUserResponse{}, apperrors.Wrap(err, apperrors.InternalError, "failed to hash password").
+			WithCode("PASSWORD_HASH_FAILED").
+			WithCaller()
+	}
+
+	user, err := s.repo.BackofficeUserRepository.CreateBackofficeUser(ctx, onefeed_th_sqlc.CreateBackofficeUserParams{
+		Username:     req.Username,
+		PasswordHash: string(passwordHash),
+		Role:         req.Role,
+	})
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return dto.BackofficeUserResponse{}, apperrors.Newf(apperrors.ValidationError, "username %q is already taken", req.Username).
+			WithCode("USERNAME_TAKEN")
+	}
+	if err != nil {
+		return dto.BackofficeUserResponse{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to store backoffice user").
+			WithCode("DB_INSERT_FAILED").
+			WithCaller()
+	}
+
+	slog.Info("Backoffice user created",
+		"id", user.ID,
+		"username", user.Username,
+		"role", user.Role,
+		"actor", actorFromContext(ctx),
+	)
+	return toBackofficeUserResponse(user), nil
+}
+
+func (s *service) GetBackofficeUsers(ctx context.Context, req dto.BlankRequest) ([]dto.BackofficeUserResponse, error) {
+	users, err := s.repo.BackofficeUserRepository.GetBackofficeUsers(ctx)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve backoffice users from database").
+			WithCode("DB_QUERY_FAILED").
+			WithCaller()
+	}
+
+	responses := make([]dto.BackofficeUserResponse, 0, len(users))
+	for _, user := range users {
+		responses = append(responses, toBackofficeUserResponse(user))
+	}
+	return responses, nil
+}
+
+// UpdateBackofficeUser changes a user's role or disables them. Tokens already issued stay
+// valid until they expire, but a disabled user can't log in or refresh
+func (s *service) UpdateBackofficeUser(ctx context.Context, req dto.BackofficeUserUpdateRequest) (dto.BackofficeUserResponse, error) {
+	user, err := s.repo.BackofficeUserRepository.GetBackofficeUserByID(ctx, req.ID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return dto.BackofficeUserResponse{}, apperrors.Newf(apperrors.NotFoundError, "backoffice user %d not found", req.ID).
+			WithCode("BACKOFFICE_USER_NOT_FOUND")
+	}
+	if err != nil {
+		return dto.BackofficeUserResponse{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve backoffice user").
+			WithCode("DB_QUERY_FAILED").
+			WithCaller()
+	}
+
+	params := onefeed_th_sqlc.UpdateBackofficeUserParams{
+		Role:     user.Role,
+		Disabled: user.Disabled,
+		ID:       user.ID,
+	}
+	if req.Role != nil {
+		params.Role = *req.Role
+	}
+	if req.Disabled != nil {
+		params.Disabled = *req.Disabled
+	}
+
+	user, err = s.repo.BackofficeUserRepository.UpdateBackofficeUser(ctx, params)
+	if err != nil {
+		return dto.BackofficeUserResponse{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to update backoffice user").
+			WithCode("DB_UPDATE_FAILED").
+			WithCaller()
+	}
+
+	slog.Info("Backoffice user updated",
+		"id", user.ID,
+		"username", user.Username,
+		"role", user.Role,
+		"disabled", user.Disabled,
+		"actor", actorFromContext(ctx),
+	)
+	return toBackofficeUserResponse(user), nil
+}
+
+func toBackofficeUserResponse(user onefeed_th_sqlc.BackofficeUser) dto.BackofficeUserResponse {
+	response := dto.BackofficeUserResponse{
+		ID:        user.ID,
+		Username:  user.Username,
+		Role:      user.Role,
+		Disabled:  user.Disabled,
+		CreatedAt: converter.PGTypeTimestampToTime(user.CreatedAt),
+	}
+	if user.UpdatedAt.Valid {
+		updatedAt := user.UpdatedAt.Time
+		response.UpdatedAt = &updatedAt
+	}
+	return response
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/auth"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/utils/converter"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/dto"
+	apperrors "github.com/onefeed-th/onefeed-th-backend-api/internal/errors"
+	onefeed_th_sqlc "github.com/onefeed-th/onefeed-th-backend-api/internal/sqlc/onefeed_th_sqlc/db"
+)
+
+type ClickService interface {
+	RecordNewsClick(ctx context.Context, req dto.NewsClickRequest) (any, error)
+	GetTrendingNews(ctx context.Context, req dto.NewsTrendingGetRequest) ([]dto.NewsTrendingGetResponse, error)
+	AggregateNewsClicks(ctx context.Context, req dto.BlankRequest) (dto.AggregateNewsClicksResponse, error)
+}
+
+const (
+	// clicks are counted in Redis hashes per hour and flushed to news_clicks by AggregateNewsClicks
+	clickBucketKeyPrefix = "clicks:bucket="
+	clickBucketLayout    = "2006010215"
+	clickBucketTTL       = 48 * time.Hour
+	clickRetention       = 7 * 24 * time.Hour
+
+	defaultTrendingHours = 6
+	maxTrendingHours     = 72
+)
+
+func (s *service) RecordNewsClick(ctx context.Context, req dto.NewsClickRequest) (any, error) {
+	key := clickBucketKeyPrefix + time.Now().UTC().Format(clickBucketLayout)
+	if err := s.redis.HashIncrBy(ctx, key, strconv.FormatInt(req.ID, 10), 1, clickBucketTTL); err != nil {
+		slog.Error("Failed to record click",
+			"id", req.ID,
+			"key", key,
+			"error", err,
+		)
+		return nil, apperrors.Wrap(err, apperrors.RedisError, "failed to record click").
+			WithCode("CLICK_RECORD_FAILED").
+			WithCaller()
+	}
+
+	if req.Ranking != "" {
+		s.recordRankingClick(ctx, req.Ranking, req.Position)
+	}
+	// a click by a known reader also feeds their read history, which personalization learns from
+	if userID, ok := auth.UserIDFromContext(ctx); ok {
+		if err := s.repo.NewsReadRepository.CreateNewsReads(ctx, onefeed_th_sqlc.CreateNewsReadsParams{
+			UserID:  userID,
+			NewsIds: []int64{req.ID},
+		}); err != nil {
+			slog.Warn("Failed to record click as read",
+				"id", req.ID,
+				"user_id", userID,
+				"error", err,
+			)
+		}
+	}
+	return nil, nil
+}
+
+func (s *service) GetTrendingNews(ctx context.Context, req dto.NewsTrendingGetRequest) ([]dto.NewsTrendingGetResponse, error) {
+	if req.Limit <= 0 || req.Limit > 100 {
+		req.Limit = 20
+	}
+	if req.Hours <= 0 {
+		req.Hours = defaultTrendingHours
+	}
+	if req.Hours > maxTrendingHours {
+		req.Hours = maxTrendingHours
+	}
+
+	since := time.Now().UTC().Add(-time.Duration(req.Hours) * time.Hour).Truncate(time.Hour)
+	rows, err := s.repo.NewsClickRepository.GetTrendingNews(ctx, onefeed_th_sqlc.ListTrendingNewsParams{
+		Since:     converter.TimeToPGTypeTimestamp(since),
+		PageLimit: req.Limit,
+	})
+	if err != nil {
+		slog.Error("Database query failed",
+			"hours", req.Hours,
+			"limit", req.Limit,
+			"error", err,
+		)
+		return nil, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve trending news from database").
+			WithCode("DB_QUERY_FAILED").
+			WithCaller()
+	}
+
+	responses := make([]dto.NewsTrendingGetResponse, 0, len(rows))
+	for _, row := range rows {
+		responses = append(responses, dto.NewsTrendingGetResponse{
+			NewsListGetResponse: toNewsListGetResponse(row.News),
+			Clicks:              row.Clicks,
+		})
+	}
+	return responses, nil
+}
+
+// AggregateNewsClicks flushes the hourly Redis click counters into the news_clicks rollup table.
+// Counters are written as absolute totals, so running the job repeatedly is safe.
+func (s *service) AggregateNewsClicks(ctx context.Context, req dto.BlankRequest) (dto.AggregateNewsClicksResponse, error) {
+	var result dto.AggregateNewsClicksResponse
+
+	keys, err := s.redis.ScanKeys(ctx, clickBucketKeyPrefix+"*")
+	if err != nil {
+		return result, apperrors.Wrap(err, apperrors.RedisError, "failed to list click buckets").
+			WithCode("CLICK_SCAN_FAILED").
+			WithCaller()
+	}
+
+	currentBucket := time.Now().UTC().Truncate(time.Hour)
+	for _, key := range keys {
+		bucket, err := time.Parse(clickBucketLayout, strings.TrimPrefix(key, clickBucketKeyPrefix))
+		if err != nil {
+			slog.Warn("Skipping malformed click bucket", "key", key, "error", err)
+			continue
+		}
+
+		counters, err := s.redis.HashGetAll(ctx, key)
+		if err != nil {
+			return result, apperrors.Wrapf(err, apperrors.RedisError, "failed to read click bucket %s", key).
+				WithCode("CLICK_READ_FAILED").
+				WithCaller()
+		}
+
+		newsIDs := make([]int64, 0, len(counters))
+		clicks := make([]int64, 0, len(counters))
+		for field, value := range counters {
+			id, idErr := strconv.ParseInt(field, 10, 64)
+			count, countErr := strconv.ParseInt(value, 10, 64)
+			if idErr != nil || countErr != nil {
+				continue
+			}
+			newsIDs = append(newsIDs, id)
+			clicks = append(clicks, count)
+		}
+
+		if len(newsIDs) > 0 {
+			err = s.repo.NewsClickRepository.UpsertNewsClicks(ctx, onefeed_th_sqlc.UpsertNewsClicksParams{
+				BucketStart: converter.TimeToPGTypeTimestamp(bucket),
+				NewsIds:     newsIDs,
+				Clicks:      clicks,
+			})
+			if err != nil {
+				return result, apperrors.Wrap(err, apperrors.DatabaseError, "failed to store click rollup").
+					WithCode("DB_UPSERT_FAILED").
+					WithDetails(fmt.Sprintf("bucket: %s", key)).
+					WithCaller()
+			}
+		}
+
+		// Closed buckets will not receive more clicks, so they can be dropped once persisted
+		if bucket.Before(currentBucket) {
+			if err := s.redis.Delete(ctx, key); err != nil {
+				slog.Warn("Failed to remove flushed click bucket", "key", key, "error", err)
+			}
+		}
+
+		result.Buckets++
+		result.News += len(newsIDs)
+	}
+
+	before := converter.TimeToPGTypeTimestamp(currentBucket.Add(-clickRetention))
+	if err := s.repo.NewsClickRepository.RemoveNewsClicksBefore(ctx, before); err != nil {
+		slog.Warn("Failed to prune old click rollups", "error", err)
+	}
+
+	slog.Info("Click aggregation completed",
+		"buckets", result.Buckets,
+		"news", result.News,
+	)
+	return result, nil
+}
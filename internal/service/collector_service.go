@@ -3,15 +3,19 @@ package service
 import (
 	"context"
 	"fmt"
-	"log/slog"
 	"net/http"
+	"slices"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/mmcdole/gofeed"
+	"github.com/onefeed-th/onefeed-th-backend-api/config"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/logger"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/utils/converter"
 	"github.com/onefeed-th/onefeed-th-backend-api/internal/dto"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/repository"
 	onefeed_th_sqlc "github.com/onefeed-th/onefeed-th-backend-api/internal/sqlc/onefeed_th_sqlc/db"
 )
 
@@ -19,21 +23,45 @@ type CollectorService interface {
 	CollectNewsFromSource(ctx context.Context, req dto.BlankRequest) (any, error)
 }
 
+const (
+	// collectorLockTTL outlasts a collection, which fetches for at most 5 minutes and then
+	// summarizes for at most collectorSummarizeTimeout
+	collectorLockTTL = 10 * time.Minute
+	// collectorSummarizeTimeout bounds the summaries generated after a collection's insert
+	collectorSummarizeTimeout = 4 * time.Minute
+)
+
 type bulkInsertNewsParams struct {
 	Title       string
 	Link        string
 	Source      string
 	ImageUrl    string
 	PublishDate *time.Time
+	Summary     string
+	// Content is the raw description used for summarization; it is not stored
+	Content string
 }
 
 func (s *service) CollectNewsFromSource(ctx context.Context, req dto.BlankRequest) (any, error) {
-	sources, err := s.repo.SourceRepository.GetAllSources(ctx)
+	log := logger.For("collector")
+
+	// replicas share the cron trigger, so only one of them collects at a time
+	lock, err := s.lockJob(ctx, "collector", collectorLockTTL)
+	if err != nil {
+		return nil, err
+	}
+	defer s.unlockJob(ctx, lock)
+
+	// disabled sources are kept but no longer collected
+	sources, err := s.repo.SourceRepository.GetAllSources(ctx, false)
 	if err != nil {
-		slog.Error("Failed to get sources", "error", err)
+		log.Error("Failed to get sources", "error", err)
 		return dto.Response{}, err
 	}
 
+	updateExisting := config.GetConfig().Collector.UpdateExisting
+	cutoff := newsRetentionCutoff(time.Now())
+
 	// Pre-allocate slice with estimated capacity (avg 20 items per source)
 	var wg sync.WaitGroup
 
@@ -44,15 +72,25 @@ func (s *service) CollectNewsFromSource(ctx context.Context, req dto.BlankReques
 	parser := gofeed.NewParser()
 	parser.Client = httpClient
 
-	slog.Info("Starting news collection",
+	log.Info("Starting news collection",
 		"source_count", len(sources),
 	)
+	run := collectionRun{
+		StartedAt: time.Now(),
+		Sources:   len(sources),
+	}
 
 	// Create a context with timeout for the entire collection process
 	collectCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
 	defer cancel()
 
 	results := make([][]bulkInsertNewsParams, len(sources))
+	createdLinks := make([][]string, len(sources))
+	// per source counts and errors for the run summary
+	fetched := make([]int, len(sources))
+	refreshed := make([]int, len(sources))
+	failures := make([]string, len(sources))
+	rejected := make([][]rejectedItem, len(sources))
 	for i, source := range sources {
 		wg.Add(1)
 		go func(i int, src onefeed_th_sqlc.Source) {
@@ -61,7 +99,7 @@ func (s *service) CollectNewsFromSource(ctx context.Context, req dto.BlankReques
 			// Check if context is already cancelled
 			select {
 			case <-collectCtx.Done():
-				slog.Warn("Context cancelled for source",
+				log.Warn("Context cancelled for source",
 					"source", src.Name,
 					"error", collectCtx.Err(),
 				)
@@ -75,11 +113,12 @@ func (s *service) CollectNewsFromSource(ctx context.Context, req dto.BlankReques
 
 			feeds, err := parser.ParseURLWithContext(src.RssUrl.String, feedCtx)
 			if err != nil {
-				slog.Error("Error parsing RSS feed",
+				log.Error("Error parsing RSS feed",
 					"source", src.Name,
 					"rss_url", src.RssUrl.String,
 					"error", err,
 				)
+				failures[i] = err.Error()
 				return
 			}
 
@@ -88,23 +127,30 @@ func (s *service) CollectNewsFromSource(ctx context.Context, req dto.BlankReques
 			newsInserts := make([]bulkInsertNewsParams, 0, len(feeds.Items))
 			links := make([]string, 0, len(feeds.Items))
 
+			rules := sourceImageRules(src)
 			for _, item := range feeds.Items {
 				// Check for cancellation during processing
 				select {
 				case <-feedCtx.Done():
-					slog.Warn("Feed processing cancelled",
+					log.Warn("Feed processing cancelled",
 						"source", src.Name,
 					)
 					return
 				default:
 				}
 
-				news := bulkInsertNewsParams{
-					Title:       item.Title,
-					Link:        sanitizeLink(item.Link),
-					Source:      src.Name,
-					ImageUrl:    extractImage(item),
-					PublishDate: item.PublishedParsed,
+				news := newsFromFeedItem(item, src.Name, rules)
+				if err := validateFeedItem(item, news); err != nil {
+					log.Warn("Feed item rejected",
+						"source", src.Name,
+						"link", news.Link,
+						"error", err,
+					)
+					rejected[i] = append(rejected[i], rejectedItem{item: item, link: news.Link, err: err})
+					continue
+				}
+				if pastRetention(news, cutoff) {
+					continue
 				}
 				localItems = append(localItems, news)
 				links = append(links, news.Link)
@@ -113,34 +159,40 @@ func (s *service) CollectNewsFromSource(ctx context.Context, req dto.BlankReques
 			// check existing links in db
 			existingLinks, err := s.repo.NewsRepository.GetAllMissingLinks(ctx, links)
 			if err != nil {
-				slog.Error("Error checking existing links:", "error", err)
+				log.Error("Error checking existing links:", "error", err)
+				failures[i] = err.Error()
 				return
 			}
 
 			// filter localItems to only include new links
-			if len(existingLinks) > 0 {
-				existingLinkSet := make(map[string]struct{}, len(existingLinks))
-				for _, link := range existingLinks {
-					existingLinkSet[link] = struct{}{}
-				}
-				filteredNews := make([]bulkInsertNewsParams, 0, len(existingLinks))
-				for _, item := range localItems {
-					if _, exists := existingLinkSet[item.Link]; exists {
-						// TODO: add some upload image to server (s3, cloudflare r2) with async or not will design later
-						filteredNews = append(filteredNews, item)
-					}
+			refreshItems := make([]bulkInsertNewsParams, 0)
+			existingLinkSet := make(map[string]struct{}, len(existingLinks))
+			for _, link := range existingLinks {
+				existingLinkSet[link] = struct{}{}
+			}
+			for _, item := range localItems {
+				if _, exists := existingLinkSet[item.Link]; exists {
+					// TODO: add some upload image to server (s3, cloudflare r2) with async or not will design later
+					newsInserts = append(newsInserts, item)
+				} else if updateExisting {
+					refreshItems = append(refreshItems, item)
 				}
-				newsInserts = filteredNews
 			}
 
-			slog.Info("Fetched items from source",
+			log.Info("Fetched items from source",
 				"source", src.Name,
 				"fetched_news", len(feeds.Items),
 				"new_news", len(newsInserts),
+				"refreshed_news", len(refreshItems),
 			)
 
 			// Append to main slice without mutex
-			results[i] = newsInserts
+			fetched[i] = len(feeds.Items)
+			refreshed[i] = len(refreshItems)
+			results[i] = append(newsInserts, refreshItems...)
+			for _, item := range newsInserts {
+				createdLinks[i] = append(createdLinks[i], item.Link)
+			}
 		}(i, source)
 	}
 
@@ -154,12 +206,29 @@ func (s *service) CollectNewsFromSource(ctx context.Context, req dto.BlankReques
 	select {
 	case <-done:
 		// All goroutines completed normally
-		slog.Debug("All RSS feeds processed successfully")
+		log.Debug("All RSS feeds processed successfully")
 	case <-collectCtx.Done():
-		slog.Error("Collection timed out", "error", collectCtx.Err())
+		log.Error("Collection timed out", "error", collectCtx.Err())
+		// sources still being fetched would race with the summary, so only the error is kept
+		run.Error = collectCtx.Err().Error()
+		s.recordCollectionRun(ctx, run)
 		return nil, fmt.Errorf("news collection timed out: %w", collectCtx.Err())
 	}
 
+	for i, source := range sources {
+		run.FetchedItems += fetched[i]
+		run.NewItems += len(createdLinks[i])
+		run.RefreshedItems += refreshed[i]
+		run.RejectedItems += len(rejected[i])
+		s.recordDeadLetters(ctx, source, rejected[i])
+		if failures[i] != "" {
+			run.Failures = append(run.Failures, sourceFailure{
+				Source: source.Name,
+				Error:  failures[i],
+			})
+		}
+	}
+
 	// Combine all results and average source item with *20
 	newsItems := make([]bulkInsertNewsParams, 0, len(sources)*20)
 	for _, item := range results {
@@ -167,57 +236,275 @@ func (s *service) CollectNewsFromSource(ctx context.Context, req dto.BlankReques
 	}
 
 	// insert into database
-	slog.Info("Inserting news items into database",
+	log.Info("Inserting news items into database",
 		"total_news", len(newsItems),
 	)
 
-	err = s.insertNewsWithBatch(ctx, newsItems)
+	err = s.insertNews(ctx, dedupeNewsByLink(newsItems), updateExisting)
 	if err != nil {
-		slog.Error("Error inserting news items into database", "error", err)
+		log.Error("Error inserting news items into database", "error", err)
+		run.Error = err.Error()
+		s.recordCollectionRun(ctx, run)
 		return nil, err
 	}
+	run = s.recordCollectionRun(ctx, run)
+
+	// the created news are clustered before the cache is cleared, so pages grouping duplicates
+	// don't list them as stories of their own
+	created := s.createdNews(ctx, slices.Concat(createdLinks...))
+	s.summarizeNews(ctx, created, newsItems)
+	s.clusterNews(ctx, created)
 
 	// Clear news cache
 	err = s.redis.RemoveKeyContaining(ctx, "news")
 	if err != nil {
-		slog.Error("Error removing news cache keys", "error", err)
+		log.Error("Error removing news cache keys", "error", err)
 		return nil, err
 	}
+	s.notifyNewsChanged(ctx, newsChangeCollect)
+	if len(created) > 0 {
+		s.publishCreatedNews(ctx, created)
+		go s.dispatchWebhooks(context.WithoutCancel(ctx), created)
+		go s.dispatchNotifications(context.WithoutCancel(ctx), created)
+		go s.enqueuePushJobs(context.WithoutCancel(ctx), created)
+		go s.dispatchSavedSearches(context.WithoutCancel(ctx), created)
+	}
+	if s.searchIndex != nil {
+		collected := make([]string, 0, len(newsItems))
+		for _, item := range newsItems {
+			collected = append(collected, item.Link)
+		}
+		go s.indexCollectedNews(context.WithoutCancel(ctx), collected)
+	}
 
-	slog.Info("News collection completed successfully",
+	log.Info("News collection completed successfully",
 		"total_items", len(newsItems),
 		"source_count", len(sources),
 	)
 
-	return nil, nil
+	response := dto.CollectionRunResponse{
+		Sources:        run.Sources,
+		FailedSources:  len(run.Failures),
+		FetchedItems:   run.FetchedItems,
+		NewItems:       run.NewItems,
+		RefreshedItems: run.RefreshedItems,
+		RejectedItems:  run.RejectedItems,
+	}
+	for _, failure := range run.Failures {
+		response.Failures = append(response.Failures, dto.DashboardFailedSource(failure))
+	}
+	return response, nil
 }
 
-func extractImage(item *gofeed.Item) string {
-	if item.Image != nil {
-		return item.Image.URL
+// summarizeNews generates and stores the summaries of the news a collection created, a few at
+// a time. It runs after the insert so a slow summarizer doesn't eat into the fetch of the
+// feeds; news it doesn't get to within collectorSummarizeTimeout are left without a summary.
+func (s *service) summarizeNews(ctx context.Context, created []onefeed_th_sqlc.News, items []bulkInsertNewsParams) {
+	if len(created) == 0 {
+		return
 	}
+	log := logger.For("collector")
 
-	if len(item.Enclosures) > 0 {
-		return item.Enclosures[0].URL
+	// the feed content isn't stored, so it is taken from the collected items
+	contents := make(map[string]string, len(items))
+	for _, item := range items {
+		contents[item.Link] = item.Content
 	}
 
-	html := item.Description
-	if html == "" {
-		html = item.Content
+	ctx, cancel := context.WithTimeout(ctx, collectorSummarizeTimeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, max(config.GetConfig().Summarizer.Concurrency, 1))
+	for i := range created {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			log.Warn("Summarization timed out",
+				"summarized_news", i,
+				"created_news", len(created),
+			)
+			break
+		}
+
+		wg.Add(1)
+		go func(news *onefeed_th_sqlc.News) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			summary, err := s.summarizer.Summarize(ctx, news.Title, contents[news.Link])
+			if err != nil {
+				log.Warn("Failed to summarize news",
+					"source", news.Source,
+					"link", news.Link,
+					"error", err,
+				)
+				return
+			}
+			if summary == "" {
+				return
+			}
+
+			params := onefeed_th_sqlc.SetNewsSummaryParams{
+				Summary: converter.StringToPGTypeTextNull(summary),
+				ID:      news.ID,
+			}
+			if err := s.repo.NewsRepository.SetNewsSummary(ctx, params); err != nil {
+				log.Warn("Failed to store news summary",
+					"news_id", news.ID,
+					"error", err,
+				)
+				return
+			}
+			// the created news are announced after this, so they carry the summary
+			news.Summary = params.Summary
+		}(&created[i])
 	}
+	wg.Wait()
+}
+
+// createdNews loads the rows inserted for links, so their ids can be announced
+func (s *service) createdNews(ctx context.Context, links []string) []onefeed_th_sqlc.News {
+	if len(links) == 0 {
+		return nil
+	}
+
+	news, err := s.repo.NewsRepository.GetNewsByLinks(ctx, links)
+	if err != nil {
+		logger.For("collector").Warn("Failed to load created news", "error", err)
+		return nil
+	}
+	return news
+}
 
-	if html != "" {
-		doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
-		if err == nil {
-			if imgSrc, exists := doc.Find("img").First().Attr("src"); exists {
-				return imgSrc
+// imageRules tells extractImage where a publisher puts the image of its items
+type imageRules struct {
+	// Selector finds the image in the item content instead of its first <img>
+	Selector string
+	// Fields are looked at in order: image, enclosure, media and content
+	Fields []string
+}
+
+func sourceImageRules(source onefeed_th_sqlc.Source) imageRules {
+	return imageRules{
+		Selector: converter.PGTypeTextToString(source.ImageSelector),
+		Fields:   source.ImageFields,
+	}
+}
+
+func extractImage(item *gofeed.Item, rules imageRules) string {
+	image, _ := findImage(item, rules)
+	return image
+}
+
+// findImage returns the image of a feed item and the field it was found in. Without rules it
+// looks at the item image, its first enclosure and the first <img> of its content; with a
+// selector and no fields the content is looked at first
+func findImage(item *gofeed.Item, rules imageRules) (string, string) {
+	fields := rules.Fields
+	if len(fields) == 0 {
+		fields = []string{"image", "enclosure", "content"}
+		if rules.Selector != "" {
+			fields = []string{"content", "image", "enclosure"}
+		}
+	}
+
+	for _, field := range fields {
+		var image string
+		switch field {
+		case "image":
+			if item.Image != nil {
+				image = item.Image.URL
+			}
+		case "enclosure":
+			if len(item.Enclosures) > 0 {
+				image = item.Enclosures[0].URL
 			}
+		case "media":
+			image = mediaImage(item)
+		case "content":
+			image = contentImage(itemContent(item), rules.Selector)
+		}
+		if image != "" {
+			return image, field
 		}
 	}
+	return "", ""
+}
 
+// mediaImage reads the Media RSS thumbnail of an item, or else its first image media:content
+func mediaImage(item *gofeed.Item) string {
+	media := item.Extensions["media"]
+	for _, thumbnail := range media["thumbnail"] {
+		if url := thumbnail.Attrs["url"]; url != "" {
+			return url
+		}
+	}
+	for _, content := range media["content"] {
+		if content.Attrs["medium"] == "image" || strings.HasPrefix(content.Attrs["type"], "image/") {
+			return content.Attrs["url"]
+		}
+	}
 	return ""
 }
 
+// contentImage returns the first element of the HTML matching the selector, <img> by default,
+// read from its src, data-src, content or href attribute
+func contentImage(html, selector string) string {
+	if html == "" {
+		return ""
+	}
+	if selector == "" {
+		selector = "img"
+	}
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		return ""
+	}
+	match := doc.Find(selector).First()
+	for _, attr := range []string{"src", "data-src", "content", "href"} {
+		if value, exists := match.Attr(attr); exists && value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// newsRetentionCutoff is the publish date before which news are past retention
+func newsRetentionCutoff(now time.Time) time.Time {
+	return now.AddDate(0, 0, -newsRetentionDays)
+}
+
+// pastRetention reports whether news were published before cutoff. Feeds keep serving such
+// items after the retention job archived them and released their links, so they are skipped
+// rather than collected again as new news
+func pastRetention(news bulkInsertNewsParams, cutoff time.Time) bool {
+	return news.PublishDate != nil && news.PublishDate.Before(cutoff)
+}
+
+// newsFromFeedItem turns a feed item of source into the news to store
+func newsFromFeedItem(item *gofeed.Item, source string, rules imageRules) bulkInsertNewsParams {
+	return bulkInsertNewsParams{
+		Title:       item.Title,
+		Link:        sanitizeLink(item.Link),
+		Source:      source,
+		ImageUrl:    extractImage(item, rules),
+		PublishDate: item.PublishedParsed,
+		Content:     itemContent(item),
+	}
+}
+
+// itemContent returns the richest text body available on a feed item
+func itemContent(item *gofeed.Item) string {
+	if item.Description != "" {
+		return item.Description
+	}
+	return item.Content
+}
+
 func sanitizeLink(raw string) string {
 	if raw == "" {
 		return ""
@@ -229,50 +516,42 @@ func sanitizeLink(raw string) string {
 	return raw
 }
 
-func (s *service) insertNewsWithBatch(ctx context.Context, newsItems []bulkInsertNewsParams) error {
-	const batchSize = 100
-
-	for i := 0; i < len(newsItems); i += batchSize {
-		end := min(i+batchSize, len(newsItems))
-		batch := newsItems[i:end]
-
-		// Pre-allocate slice capacity for better memory efficiency
-		args := make([]interface{}, 0, len(batch)*5)
-
-		// Pre-allocate strings.Builder with estimated capacity
-		var sb strings.Builder
-		// Estimate: base query + (placeholder chars * items) + commas
-		estimatedSize := 80 + (len(batch) * 25) + len(batch)
-		sb.Grow(estimatedSize)
-
-		sb.WriteString(`INSERT INTO news (title, link, source, image_url, publish_date, fetched_at) VALUES `)
-
-		for j, item := range batch {
-			argPos := j*5 + 1
-			sb.WriteString(fmt.Sprintf("($%d,$%d,$%d,$%d,$%d,NOW())",
-				argPos, argPos+1, argPos+2, argPos+3, argPos+4))
-			if j < len(batch)-1 {
-				sb.WriteString(",")
-			}
-
-			// Append to pre-allocated slice
-			args = append(args,
-				item.Title,
-				item.Link,
-				item.Source,
-				item.ImageUrl,
-				item.PublishDate,
-			)
+// dedupeNewsByLink keeps the first item per link, since ON CONFLICT DO UPDATE
+// cannot touch the same row twice within one statement
+func dedupeNewsByLink(newsItems []bulkInsertNewsParams) []bulkInsertNewsParams {
+	seen := make(map[string]struct{}, len(newsItems))
+	deduped := make([]bulkInsertNewsParams, 0, len(newsItems))
+	for _, item := range newsItems {
+		if _, ok := seen[item.Link]; ok {
+			continue
 		}
+		seen[item.Link] = struct{}{}
+		deduped = append(deduped, item)
+	}
+	return deduped
+}
 
-		sb.WriteString(" ON CONFLICT (link) DO NOTHING;")
-
-		// Exec batch insert
-		err := s.repo.NewsRepository.BulkInsertNews(ctx, sb.String(), args)
-		if err != nil {
-			return fmt.Errorf("batch insert failed: %w", err)
-		}
+func (s *service) insertNews(ctx context.Context, newsItems []bulkInsertNewsParams, updateExisting bool) error {
+	params := make([]repository.InsertNewsParams, 0, len(newsItems))
+	for _, item := range newsItems {
+		params = append(params, repository.InsertNewsParams{
+			Title:       item.Title,
+			Link:        item.Link,
+			Source:      item.Source,
+			ImageUrl:    item.ImageUrl,
+			PublishDate: item.PublishDate,
+			Summary:     item.Summary,
+		})
 	}
 
+	var err error
+	if updateExisting {
+		err = s.repo.NewsRepository.BulkUpsertNews(ctx, params)
+	} else {
+		err = s.repo.NewsRepository.BulkInsertNews(ctx, params)
+	}
+	if err != nil {
+		return fmt.Errorf("batch insert failed: %w", err)
+	}
 	return nil
 }
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/rds"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/dto"
+	apperrors "github.com/onefeed-th/onefeed-th-backend-api/internal/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+type DashboardService interface {
+	GetDashboard(ctx context.Context, req dto.DashboardGetRequest) (dto.DashboardResponse, error)
+}
+
+const (
+	// the summary of the latest collection; the key must not contain "news", which is cleared
+	// after every collection
+	collectionRunKey = "collector:lastRun"
+
+	defaultDashboardTopSources = 10
+)
+
+// collectionRun summarizes a run of CollectNewsFromSource
+type collectionRun struct {
+	StartedAt      time.Time       `json:"startedAt"`
+	FinishedAt     time.Time       `json:"finishedAt"`
+	Sources        int             `json:"sources"`
+	FetchedItems   int             `json:"fetchedItems"`
+	NewItems       int             `json:"newItems"`
+	RefreshedItems int             `json:"refreshedItems"`
+	RejectedItems  int             `json:"rejectedItems"`
+	Error          string          `json:"error,omitempty"`
+	Failures       []sourceFailure `json:"failures"`
+}
+
+// sourceFailure is a source whose feed couldn't be collected. ConsecutiveFailures and
+// FailingSince carry over from the previous run while the source keeps failing
+type sourceFailure struct {
+	Source              string    `json:"source"`
+	Error               string    `json:"error"`
+	ConsecutiveFailures int       `json:"consecutiveFailures"`
+	FailingSince        time.Time `json:"failingSince"`
+}
+
+// recordCollectionRun saves the summary of a collection for the dashboard and returns it with
+// its failure streaks. It is best effort, a collection doesn't fail because its summary
+// couldn't be saved
+func (s *service) recordCollectionRun(ctx context.Context, run collectionRun) collectionRun {
+	run.FinishedAt = time.Now()
+
+	var previous collectionRun
+	if err := s.redis.Get(ctx, collectionRunKey, &previous); err != nil && !errors.Is(err, redis.Nil) {
+		slog.Warn("Failed to read the previous collection run", "error", err)
+	}
+	// a run that timed out has no failures, keep the streaks of the previous one
+	if run.Error != "" && run.Failures == nil {
+		run.Failures = previous.Failures
+	}
+	streaks := make(map[string]sourceFailure, len(previous.Failures))
+	for _, failure := range previous.Failures {
+		streaks[failure.Source] = failure
+	}
+	for i, failure := range run.Failures {
+		if failure.ConsecutiveFailures > 0 {
+			continue
+		}
+		run.Failures[i].ConsecutiveFailures = 1
+		run.Failures[i].FailingSince = run.StartedAt
+		if streak, ok := streaks[failure.Source]; ok {
+			run.Failures[i].ConsecutiveFailures = streak.ConsecutiveFailures + 1
+			run.Failures[i].FailingSince = streak.FailingSince
+		}
+	}
+
+	if err := s.redis.Set(ctx, collectionRunKey, run); err != nil {
+		slog.Warn("Failed to record the collection run",
+			"cache_key", collectionRunKey,
+			"error_code", "CACHE_SET_FAILED",
+			"error", err,
+		)
+	}
+	return run
+}
+
+// GetDashboard gathers the numbers of the backoffice home page in one call
+func (s *service) GetDashboard(ctx context.Context, req dto.DashboardGetRequest) (dto.DashboardResponse, error) {
+	if req.Top <= 0 {
+		req.Top = defaultDashboardTopSources
+	}
+
+	sources, err := s.cachedSources(ctx)
+	if err != nil {
+		return dto.DashboardResponse{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve sources").
+			WithCode("DB_QUERY_FAILED").
+			WithCaller()
+	}
+	response := dto.DashboardResponse{
+		Sources:        dto.DashboardSourceCounts{Total: len(sources)},
+		FailingSources: []dto.DashboardFailedSource{},
+		TopSources:     []dto.DashboardSourceVolume{},
+	}
+	for _, source := range sources {
+		if source.Enabled {
+			response.Sources.Enabled++
+		} else {
+			response.Sources.Disabled++
+		}
+	}
+
+	midnight := time.Now().UTC().Truncate(24 * time.Hour)
+	counts, err := s.repo.NewsRepository.GetSourceNewsCounts(ctx, pgtype.Timestamp{Time: midnight, Valid: true})
+	if err != nil {
+		return dto.DashboardResponse{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to count news").
+			WithCode("DB_QUERY_FAILED").
+			WithCaller()
+	}
+	for i, row := range counts {
+		response.TotalArticles += row.Total
+		response.ArticlesToday += row.Recent
+		if i < int(req.Top) {
+			response.TopSources = append(response.TopSources, dto.DashboardSourceVolume{
+				Source:        row.Source,
+				Articles:      row.Total,
+				ArticlesToday: row.Recent,
+			})
+		}
+	}
+
+	response.Cache = toCacheStats(rds.GetCacheStats())
+
+	var run collectionRun
+	err = s.redis.Get(ctx, collectionRunKey, &run)
+	if errors.Is(err, redis.Nil) {
+		return response, nil
+	}
+	if err != nil {
+		return dto.DashboardResponse{}, apperrors.Wrap(err, apperrors.RedisError, "failed to retrieve the last collection run").
+			WithCode("CACHE_GET_FAILED").
+			WithCaller()
+	}
+	for _, failure := range run.Failures {
+		response.FailingSources = append(response.FailingSources, dto.DashboardFailedSource(failure))
+	}
+	response.LastCollection = &dto.DashboardCollectionRun{
+		StartedAt:      run.StartedAt,
+		FinishedAt:     run.FinishedAt,
+		DurationMs:     run.FinishedAt.Sub(run.StartedAt).Milliseconds(),
+		Sources:        run.Sources,
+		FailedSources:  len(run.Failures),
+		FetchedItems:   run.FetchedItems,
+		NewItems:       run.NewItems,
+		RefreshedItems: run.RefreshedItems,
+		Error:          run.Error,
+	}
+	return response, nil
+}
@@ -0,0 +1,266 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/mmcdole/gofeed"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/logger"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/utils/converter"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/dto"
+	apperrors "github.com/onefeed-th/onefeed-th-backend-api/internal/errors"
+	onefeed_th_sqlc "github.com/onefeed-th/onefeed-th-backend-api/internal/sqlc/onefeed_th_sqlc/db"
+)
+
+// DeadLetterService lets admins inspect the feed items the collector rejected, reprocess them
+// once the cause is fixed, or discard them
+type DeadLetterService interface {
+	GetDeadLetters(ctx context.Context, req dto.DeadLetterListRequest) ([]dto.DeadLetterResponse, error)
+	GetDeadLetter(ctx context.Context, req dto.DeadLetterRequest) (dto.DeadLetterResponse, error)
+	ReprocessDeadLetter(ctx context.Context, req dto.DeadLetterRequest) (dto.NewsListGetResponse, error)
+	DeleteDeadLetter(ctx context.Context, req dto.DeadLetterRequest) (any, error)
+}
+
+const (
+	defaultDeadLetterLimit = 20
+
+	// limits of what a feed item may hold to be stored as news
+	maxNewsTitleLength = 1000 // characters
+	maxNewsLinkLength  = 2048 // bytes
+	// items dated further ahead than this carry a bad date rather than scheduled news
+	maxPublishDateSkew = 24 * time.Hour
+)
+
+// rejectedItem is a feed item that failed validateFeedItem
+type rejectedItem struct {
+	item *gofeed.Item
+	link string
+	err  error
+}
+
+// validateFeedItem checks that the news made from item can be stored: items with a bad date,
+// oversized fields or invalid encoding are rejected with the reason
+func validateFeedItem(item *gofeed.Item, news bulkInsertNewsParams) error {
+	switch {
+	case !utf8.ValidString(news.Title) || !utf8.ValidString(news.Link) || !utf8.ValidString(news.ImageUrl):
+		return errors.New("title, link or image is not valid UTF-8")
+	case strings.TrimSpace(news.Title) == "":
+		return errors.New("missing title")
+	case utf8.RuneCountInString(news.Title) > maxNewsTitleLength:
+		return fmt.Errorf("title is longer than %d characters", maxNewsTitleLength)
+	case news.Link == "":
+		return errors.New("missing link")
+	case len(news.Link) > maxNewsLinkLength:
+		return fmt.Errorf("link is longer than %d bytes", maxNewsLinkLength)
+	case item.Published != "" && item.PublishedParsed == nil:
+		return fmt.Errorf("unparseable publish date %q", item.Published)
+	case news.PublishDate != nil && news.PublishDate.After(time.Now().Add(maxPublishDateSkew)):
+		return fmt.Errorf("publish date %s is in the future", news.PublishDate.Format(time.RFC3339))
+	}
+	if u, err := url.Parse(news.Link); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("link %q is not an http(s) URL", news.Link)
+	}
+	return nil
+}
+
+// recordDeadLetters keeps the items of source that the collector rejected. It is best effort,
+// a collection doesn't fail because its dead letters couldn't be saved
+func (s *service) recordDeadLetters(ctx context.Context, source onefeed_th_sqlc.Source, items []rejectedItem) {
+	for _, rejected := range items {
+		payload, err := json.Marshal(rejected.item)
+		if err != nil {
+			logger.For("collector").Warn("Failed to encode rejected feed item",
+				"source", source.Name,
+				"link", rejected.link,
+				"error", err,
+			)
+			continue
+		}
+		err = s.repo.DeadLetterRepository.UpsertDeadLetter(ctx, onefeed_th_sqlc.UpsertDeadLetterParams{
+			SourceID: source.ID,
+			ItemKey:  deadLetterKey(rejected.item, payload),
+			Link:     converter.StringToPGTypeTextNull(strings.ToValidUTF8(rejected.link, "\uFFFD")),
+			Payload:  payload,
+			Error:    rejected.err.Error(),
+		})
+		if err != nil {
+			logger.For("collector").Warn("Failed to record dead letter",
+				"source", source.Name,
+				"link", rejected.link,
+				"error", err,
+			)
+		}
+	}
+}
+
+// deadLetterKey identifies a rejected item across collections by its guid, its link, or else
+// its content. It is hashed, since the fields of a rejected item may be of any length
+func deadLetterKey(item *gofeed.Item, payload []byte) string {
+	identity := payload
+	if item.GUID != "" {
+		identity = []byte("guid:" + item.GUID)
+	} else if item.Link != "" {
+		identity = []byte("link:" + item.Link)
+	}
+	sum := sha256.Sum256(identity)
+	return hex.EncodeToString(sum[:])
+}
+
+// GetDeadLetters lists the newest rejected feed items, optionally of one source
+func (s *service) GetDeadLetters(ctx context.Context, req dto.DeadLetterListRequest) ([]dto.DeadLetterResponse, error) {
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultDeadLetterLimit
+	}
+	letters, err := s.repo.DeadLetterRepository.GetDeadLetters(ctx, onefeed_th_sqlc.ListDeadLettersParams{
+		SourceID:  req.SourceID,
+		PageLimit: limit,
+	})
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve dead letters from database").
+			WithCode("DB_QUERY_FAILED").
+			WithCaller()
+	}
+
+	responses := make([]dto.DeadLetterResponse, 0, len(letters))
+	for _, letter := range letters {
+		responses = append(responses, toDeadLetterResponse(letter))
+	}
+	return responses, nil
+}
+
+func (s *service) GetDeadLetter(ctx context.Context, req dto.DeadLetterRequest) (dto.DeadLetterResponse, error) {
+	letter, err := s.getDeadLetter(ctx, req.ID)
+	if err != nil {
+		return dto.DeadLetterResponse{}, err
+	}
+	return toDeadLetterResponse(letter), nil
+}
+
+// ReprocessDeadLetter runs a rejected item through the collector again, with the current
+// rules of its source. An item that passes is stored and leaves the dead letters; one that
+// still fails keeps its entry with the new error
+func (s *service) ReprocessDeadLetter(ctx context.Context, req dto.DeadLetterRequest) (dto.NewsListGetResponse, error) {
+	letter, err := s.getDeadLetter(ctx, req.ID)
+	if err != nil {
+		return dto.NewsListGetResponse{}, err
+	}
+	source, err := s.repo.SourceRepository.GetSourceByID(ctx, letter.SourceID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return dto.NewsListGetResponse{}, apperrors.Newf(apperrors.NotFoundError, "source %d of dead letter %d no longer exists", letter.SourceID, letter.ID).
+			WithCode("SOURCE_NOT_FOUND")
+	}
+	if err != nil {
+		return dto.NewsListGetResponse{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve source").
+			WithCode("DB_QUERY_FAILED").
+			WithCaller()
+	}
+
+	var item gofeed.Item
+	if err := json.Unmarshal(letter.Payload, &item); err != nil {
+		return dto.NewsListGetResponse{}, apperrors.Wrap(err, apperrors.ParseError, "failed to decode dead letter payload").
+			WithCode("INVALID_DEAD_LETTER").
+			WithCaller()
+	}
+	news := newsFromFeedItem(&item, source.Name, sourceImageRules(source))
+	if err := validateFeedItem(&item, news); err != nil {
+		if updateErr := s.repo.DeadLetterRepository.UpdateDeadLetterError(ctx, onefeed_th_sqlc.UpdateDeadLetterErrorParams{
+			Error: err.Error(),
+			ID:    letter.ID,
+		}); updateErr != nil {
+			logger.For("collector").Warn("Failed to update dead letter", "id", letter.ID, "error", updateErr)
+		}
+		return dto.NewsListGetResponse{}, apperrors.Wrap(err, apperrors.ValidationError, "feed item is still rejected").
+			WithCode("DEAD_LETTER_REJECTED")
+	}
+	if pastRetention(news, newsRetentionCutoff(time.Now())) {
+		return dto.NewsListGetResponse{}, apperrors.Newf(apperrors.ValidationError, "feed item was published more than %d days ago", newsRetentionDays).
+			WithCode("DEAD_LETTER_EXPIRED")
+	}
+
+	if summary, err := s.summarizer.Summarize(ctx, news.Title, news.Content); err != nil {
+		logger.For("collector").Warn("Failed to summarize news", "source", source.Name, "link", news.Link, "error", err)
+	} else {
+		news.Summary = summary
+	}
+	if err := s.insertNews(ctx, []bulkInsertNewsParams{news}, false); err != nil {
+		return dto.NewsListGetResponse{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to store reprocessed news").
+			WithCode("DB_INSERT_FAILED").
+			WithCaller()
+	}
+	if _, err := s.repo.DeadLetterRepository.DeleteDeadLetter(ctx, letter.ID); err != nil {
+		logger.For("collector").Warn("Failed to remove reprocessed dead letter", "id", letter.ID, "error", err)
+	}
+	s.notifyNewsChanged(ctx, newsChangeCollect)
+
+	logger.For("collector").Info("Dead letter reprocessed",
+		"id", letter.ID,
+		"source", source.Name,
+		"link", news.Link,
+		"actor", actorFromContext(ctx),
+	)
+	created := s.createdNews(ctx, []string{news.Link})
+	if len(created) == 0 {
+		return dto.NewsListGetResponse{}, apperrors.Newf(apperrors.NotFoundError, "news %s not found after reprocessing", news.Link).
+			WithCode("NEWS_NOT_FOUND")
+	}
+	return toNewsListGetResponse(created[0]), nil
+}
+
+// DeleteDeadLetter discards a rejected item; it is recorded again if its feed still serves it
+func (s *service) DeleteDeadLetter(ctx context.Context, req dto.DeadLetterRequest) (any, error) {
+	affected, err := s.repo.DeadLetterRepository.DeleteDeadLetter(ctx, req.ID)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.DatabaseError, "failed to delete dead letter").
+			WithCode("DB_DELETE_FAILED").
+			WithDetails(fmt.Sprintf("id: %d", req.ID)).
+			WithCaller()
+	}
+	if affected == 0 {
+		return nil, apperrors.Newf(apperrors.NotFoundError, "dead letter %d not found", req.ID).
+			WithCode("DEAD_LETTER_NOT_FOUND")
+	}
+
+	logger.For("collector").Info("Dead letter discarded",
+		"id", req.ID,
+		"actor", actorFromContext(ctx),
+	)
+	return nil, nil
+}
+
+func (s *service) getDeadLetter(ctx context.Context, id int64) (onefeed_th_sqlc.DeadLetter, error) {
+	letter, err := s.repo.DeadLetterRepository.GetDeadLetterByID(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return letter, apperrors.Newf(apperrors.NotFoundError, "dead letter %d not found", id).
+			WithCode("DEAD_LETTER_NOT_FOUND")
+	}
+	if err != nil {
+		return letter, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve dead letter").
+			WithCode("DB_QUERY_FAILED").
+			WithDetails(fmt.Sprintf("id: %d", id)).
+			WithCaller()
+	}
+	return letter, nil
+}
+
+func toDeadLetterResponse(letter onefeed_th_sqlc.DeadLetter) dto.DeadLetterResponse {
+	return dto.DeadLetterResponse{
+		ID:          letter.ID,
+		SourceID:    letter.SourceID,
+		Link:        converter.PGTypeTextToString(letter.Link),
+		Error:       letter.Error,
+		Occurrences: letter.Occurrences,
+		Payload:     json.RawMessage(letter.Payload),
+		CreatedAt:   converter.PGTypeTimestampToTime(letter.CreatedAt),
+		LastSeenAt:  converter.PGTypeTimestampToTime(letter.LastSeenAt),
+	}
+}
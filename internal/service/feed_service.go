@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/onefeed-th/onefeed-th-backend-api/config"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/syndication"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/utils/converter"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/dto"
+	apperrors "github.com/onefeed-th/onefeed-th-backend-api/internal/errors"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/repository"
+	onefeed_th_sqlc "github.com/onefeed-th/onefeed-th-backend-api/internal/sqlc/onefeed_th_sqlc/db"
+	"github.com/redis/go-redis/v9"
+)
+
+type FeedService interface {
+	GetRSSFeed(ctx context.Context, req dto.FeedGetRequest) (syndication.Document, error)
+	GetAtomFeed(ctx context.Context, req dto.FeedGetRequest) (syndication.Document, error)
+}
+
+func (s *service) GetRSSFeed(ctx context.Context, req dto.FeedGetRequest) (syndication.Document, error) {
+	feed, err := s.buildFeed(ctx, req, "/feeds/rss")
+	if err != nil {
+		return syndication.Document{}, err
+	}
+	return syndication.Document{Feed: feed, Format: syndication.FormatRSS}, nil
+}
+
+func (s *service) GetAtomFeed(ctx context.Context, req dto.FeedGetRequest) (syndication.Document, error) {
+	feed, err := s.buildFeed(ctx, req, "/feeds/atom")
+	if err != nil {
+		return syndication.Document{}, err
+	}
+	return syndication.Document{Feed: feed, Format: syndication.FormatAtom}, nil
+}
+
+func (s *service) buildFeed(ctx context.Context, req dto.FeedGetRequest, selfPath string) (syndication.Feed, error) {
+	cfg := config.GetConfig().Feed
+	if req.Limit <= 0 || req.Limit > 100 {
+		req.Limit = cfg.Limit
+	}
+
+	sources, err := s.resolveFeedSources(ctx, req)
+	if err != nil {
+		return syndication.Feed{}, err
+	}
+
+	feed := syndication.Feed{
+		Title:       cfg.Title,
+		Link:        cfg.Link,
+		SelfLink:    strings.TrimRight(cfg.Link, "/") + selfPath,
+		Description: cfg.Description,
+	}
+	if len(sources) == 0 {
+		return feed, nil
+	}
+
+	var items []dto.NewsListGetResponse
+	redisKey := fmt.Sprintf("news:feed:source=%v:limit=%d", sources, req.Limit)
+
+	err = s.redis.Get(ctx, redisKey, &items)
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			slog.Warn("Cache retrieval failed, continuing with database query",
+				"cache_key", redisKey,
+				"error_code", "CACHE_GET_FAILED",
+				"error", err,
+			)
+		}
+
+		news, err := s.repo.NewsRepository.GetNews(ctx, onefeed_th_sqlc.ListNewsParams{
+			Sources:    sources,
+			PageOffset: 0,
+			PageLimit:  req.Limit,
+		}, repository.NewsSortPublishedAtDesc)
+		if err != nil {
+			return syndication.Feed{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve news for feed").
+				WithCode("DB_QUERY_FAILED").
+				WithCaller()
+		}
+
+		items = make([]dto.NewsListGetResponse, 0, len(news))
+		for _, item := range news {
+			items = append(items, toNewsListGetResponse(item))
+		}
+
+		if err := s.redis.SetWithExpiredTime(ctx, redisKey, items, cacheTTL(redisKey)); err != nil {
+			slog.Warn("Failed to cache feed items",
+				"cache_key", redisKey,
+				"error_code", "CACHE_SET_FAILED",
+				"error", err,
+			)
+		}
+	}
+
+	feed.Items = make([]syndication.Item, 0, len(items))
+	for _, item := range items {
+		feed.Items = append(feed.Items, syndication.Item{
+			Title:       item.Title,
+			Link:        item.Link,
+			Source:      item.Source,
+			Image:       item.Image,
+			Summary:     item.Summary,
+			PublishedAt: item.PublishedAt,
+		})
+		if item.PublishedAt.After(feed.Updated) {
+			feed.Updated = item.PublishedAt
+		}
+	}
+	return feed, nil
+}
+
+// resolveFeedSources turns the source/tag filters into the list of source names to query.
+// Without filters every configured source is included.
+func (s *service) resolveFeedSources(ctx context.Context, req dto.FeedGetRequest) ([]string, error) {
+	if len(req.Source) > 0 && len(req.Tag) == 0 {
+		return req.Source, nil
+	}
+
+	all, err := s.cachedSources(ctx)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve sources").
+			WithCode("DB_QUERY_FAILED").
+			WithCaller()
+	}
+
+	names := make([]string, 0, len(all))
+	for _, source := range all {
+		if len(req.Source) > 0 && !slices.Contains(req.Source, source.Name) {
+			continue
+		}
+		if len(req.Tag) > 0 && !sourceHasAnyTag(converter.PGTypeTextToString(source.Tags), req.Tag) {
+			continue
+		}
+		names = append(names, source.Name)
+	}
+	return names, nil
+}
+
+// sourceHasAnyTag matches against the comma separated tags column of sources
+func sourceHasAnyTag(sourceTags string, tags []string) bool {
+	for _, tag := range strings.Split(sourceTags, ",") {
+		tag = strings.TrimSpace(tag)
+		for _, want := range tags {
+			if strings.EqualFold(tag, want) {
+				return true
+			}
+		}
+	}
+	return false
+}
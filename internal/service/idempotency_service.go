@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/onefeed-th/onefeed-th-backend-api/config"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/rds"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/dto"
+	apperrors "github.com/onefeed-th/onefeed-th-backend-api/internal/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+// IdempotencyService runs /internal requests once per Idempotency-Key, so a retried or
+// double-fired request gets the original response instead of queueing the job again
+type IdempotencyService interface {
+	RunIdempotent(ctx context.Context, key, fingerprint string, run func() dto.IdempotentResponse) (dto.IdempotentResponse, bool, error)
+}
+
+const (
+	// responses are kept under the response key for jobs.idempotencyWindow; the lock key is
+	// held while the first request runs
+	idempotencyResponseKeyPrefix = "idempotency:response:"
+	idempotencyLockKeyPrefix     = "idempotency:"
+
+	// idempotencyLockTTL bounds the lock when the /internal timeout is disabled
+	idempotencyLockTTL = 10 * time.Minute
+)
+
+// idempotentResult is stored under idempotencyResponseKeyPrefix+key
+type idempotentResult struct {
+	// Fingerprint identifies the request the key was first used with
+	Fingerprint string                 `json:"fingerprint"`
+	Response    dto.IdempotentResponse `json:"response"`
+}
+
+// RunIdempotent calls run once per key within jobs.idempotencyWindow and returns its response,
+// or the stored response and true when the key was used before. A key repeated while its first
+// request runs, or with another request, is a ConflictError. Server errors aren't stored, so
+// the request can be retried with the same key. When Redis is down requests run as if they
+// had no key
+func (s *service) RunIdempotent(ctx context.Context, key, fingerprint string, run func() dto.IdempotentResponse) (dto.IdempotentResponse, bool, error) {
+	if result, ok, err := s.idempotentResult(ctx, key, fingerprint); ok || err != nil {
+		return result, ok, err
+	}
+
+	ttl := idempotencyLockTTL
+	if timeout := config.GetConfig().RestServer.Timeout.Internal; timeout > 0 {
+		ttl = time.Duration(timeout) * time.Second
+	}
+	lock, err := s.redis.Lock(ctx, idempotencyLockKeyPrefix+key, ttl)
+	if errors.Is(err, rds.ErrLockHeld) {
+		return dto.IdempotentResponse{}, false, apperrors.New(apperrors.ConflictError, "a request with this Idempotency-Key is still running").
+			WithCode("IDEMPOTENT_REQUEST_IN_PROGRESS")
+	}
+	if err != nil {
+		slog.Warn("Failed to lock idempotency key, running the request without it", "error", err)
+		return run(), false, nil
+	}
+	defer s.unlockJob(ctx, lock)
+
+	// the first request may have finished between the lookup and the lock
+	if result, ok, err := s.idempotentResult(ctx, key, fingerprint); ok || err != nil {
+		return result, ok, err
+	}
+
+	response := run()
+	if response.Status >= http.StatusInternalServerError {
+		return response, false, nil
+	}
+	window := time.Duration(config.GetConfig().Jobs.IdempotencyWindow) * time.Second
+	result := idempotentResult{Fingerprint: fingerprint, Response: response}
+	if err := s.redis.SetWithExpiredTime(context.WithoutCancel(ctx), idempotencyResponseKeyPrefix+key, result, window); err != nil {
+		slog.Warn("Failed to store idempotent response", "error", err)
+	}
+	return response, false, nil
+}
+
+// idempotentResult looks up the response stored under key; a lookup that fails is treated
+// as a miss
+func (s *service) idempotentResult(ctx context.Context, key, fingerprint string) (dto.IdempotentResponse, bool, error) {
+	var result idempotentResult
+	err := s.redis.Get(ctx, idempotencyResponseKeyPrefix+key, &result)
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			slog.Warn("Failed to look up idempotent response", "error", err)
+		}
+		return dto.IdempotentResponse{}, false, nil
+	}
+	if result.Fingerprint != fingerprint {
+		return dto.IdempotentResponse{}, false, apperrors.New(apperrors.ConflictError, "Idempotency-Key was already used with a different request").
+			WithCode("IDEMPOTENCY_KEY_REUSED")
+	}
+	return result.Response, true, nil
+}
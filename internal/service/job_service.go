@@ -0,0 +1,530 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/onefeed-th/onefeed-th-backend-api/config"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/logger"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/utils/converter"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/dto"
+	apperrors "github.com/onefeed-th/onefeed-th-backend-api/internal/errors"
+	onefeed_th_sqlc "github.com/onefeed-th/onefeed-th-backend-api/internal/sqlc/onefeed_th_sqlc/db"
+)
+
+type JobService interface {
+	EnqueueJob(ctx context.Context, req dto.JobEnqueueRequest) (dto.JobResponse, error)
+	EnqueueCollect(ctx context.Context, req dto.BlankRequest) (dto.JobResponse, error)
+	EnqueueRetention(ctx context.Context, req dto.BlankRequest) (dto.JobResponse, error)
+	EnqueueBackfill(ctx context.Context, req dto.BackfillRequest) (dto.JobResponse, error)
+	GetJobs(ctx context.Context, req dto.JobListRequest) ([]dto.JobResponse, error)
+	RetryJob(ctx context.Context, req dto.JobRetryRequest) (dto.JobResponse, error)
+	RunJobWorkers(ctx context.Context)
+}
+
+const (
+	jobTypeCollect         = "collect"
+	jobTypeRetention       = "retention"
+	jobTypeCacheWarm       = "cache-warm"
+	jobTypeWebhookDelivery = "webhook-delivery"
+	jobTypeBackfill        = "backfill"
+	jobTypeSitemap         = "sitemap"
+	jobTypeAnalytics       = "analytics"
+
+	defaultJobListLimit = 20
+)
+
+// jobHandler runs the jobs of one type
+type jobHandler struct {
+	// run returns what the job did, kept as its result, or nil when it reports nothing
+	run func(ctx context.Context, payload []byte) (any, error)
+	// unique types are queued once: enqueueing one while another is pending returns that one,
+	// which idx_jobs_pending_unique_type enforces across instances
+	unique bool
+	// retry returns the attempts and first backoff of the type; jobs.maxAttempts and
+	// jobs.backoff when nil
+	retry func() (maxAttempts int, backoff time.Duration)
+	// failed runs once a job used up its attempts, e.g. to keep a dead-letter record
+	failed func(ctx context.Context, job onefeed_th_sqlc.Job, err error)
+	// next makes a unique type recurring: it returns when the type runs again after now, or
+	// false while it shouldn't. Runs are queued as the workers start and after each run settles
+	next func(now time.Time) (time.Time, bool)
+}
+
+func (h jobHandler) retryPolicy() (int, time.Duration) {
+	if h.retry != nil {
+		return h.retry()
+	}
+	cfg := config.GetConfig().Jobs
+	return cfg.MaxAttempts, time.Duration(cfg.Backoff) * time.Second
+}
+
+// permanentJobError fails a job without using up its remaining attempts
+type permanentJobError struct {
+	error
+}
+
+func (e *permanentJobError) Unwrap() error {
+	return e.error
+}
+
+func permanent(err error) error {
+	return &permanentJobError{err}
+}
+
+// noResult adapts a job that reports nothing to jobHandler.run
+func noResult(run func(ctx context.Context, payload []byte) error) func(ctx context.Context, payload []byte) (any, error) {
+	return func(ctx context.Context, payload []byte) (any, error) {
+		return nil, run(ctx, payload)
+	}
+}
+
+// newJobHandlers registers what every job type runs
+func (s *service) newJobHandlers() map[string]jobHandler {
+	return map[string]jobHandler{
+		jobTypeCollect: {
+			run: func(ctx context.Context, payload []byte) (any, error) {
+				return s.CollectNewsFromSource(ctx, dto.BlankRequest{})
+			},
+			unique: true,
+		},
+		jobTypeRetention: {
+			run: func(ctx context.Context, payload []byte) (any, error) {
+				return s.RemoveOldNews(ctx, dto.BlankRequest{})
+			},
+			unique: true,
+		},
+		jobTypeCacheWarm: {
+			run:    noResult(s.warmNewsCache),
+			unique: true,
+		},
+		jobTypeWebhookDelivery: {
+			run: noResult(s.deliverWebhookJob),
+			retry: func() (int, time.Duration) {
+				cfg := config.GetConfig().Webhook
+				return cfg.MaxAttempts, time.Duration(cfg.Backoff) * time.Second
+			},
+			failed: s.recordWebhookFailure,
+		},
+		jobTypeBackfill: {
+			run: func(ctx context.Context, payload []byte) (any, error) {
+				return s.backfillSource(ctx, payload)
+			},
+		},
+		jobTypeSitemap: {
+			run: func(ctx context.Context, payload []byte) (any, error) {
+				return s.refreshSitemaps(ctx)
+			},
+			unique: true,
+			next: func(now time.Time) (time.Time, bool) {
+				cfg := config.GetConfig().Sitemap
+				return now.Add(time.Duration(cfg.Interval) * time.Second), cfg.Enabled || cfg.News
+			},
+		},
+		jobTypeAnalytics: {
+			run: func(ctx context.Context, payload []byte) (any, error) {
+				return s.aggregateAnalytics(ctx)
+			},
+			unique: true,
+			next: func(now time.Time) (time.Time, bool) {
+				return nextAnalyticsRun(now), true
+			},
+		},
+	}
+}
+
+// EnqueueJob queues a job of any type with the given payload
+func (s *service) EnqueueJob(ctx context.Context, req dto.JobEnqueueRequest) (dto.JobResponse, error) {
+	runAt := time.Now()
+	if req.RunAt != nil {
+		runAt = *req.RunAt
+	}
+	job, err := s.enqueueJob(ctx, req.Type, req.Payload, runAt)
+	if err != nil {
+		return dto.JobResponse{}, err
+	}
+	return toJobResponse(job), nil
+}
+
+// EnqueueCollect queues a news collection, or returns the one already waiting
+func (s *service) EnqueueCollect(ctx context.Context, req dto.BlankRequest) (dto.JobResponse, error) {
+	job, err := s.enqueueJob(ctx, jobTypeCollect, nil, time.Now())
+	if err != nil {
+		return dto.JobResponse{}, err
+	}
+	return toJobResponse(job), nil
+}
+
+// EnqueueRetention queues the archiving of old news, or returns the run already waiting
+func (s *service) EnqueueRetention(ctx context.Context, req dto.BlankRequest) (dto.JobResponse, error) {
+	job, err := s.enqueueJob(ctx, jobTypeRetention, nil, time.Now())
+	if err != nil {
+		return dto.JobResponse{}, err
+	}
+	return toJobResponse(job), nil
+}
+
+// EnqueueBackfill queues the import of the older items of a source from its feed archive
+func (s *service) EnqueueBackfill(ctx context.Context, req dto.BackfillRequest) (dto.JobResponse, error) {
+	if _, err := s.getSource(ctx, req.SourceID); err != nil {
+		return dto.JobResponse{}, err
+	}
+	payload, _ := json.Marshal(backfillJob{SourceID: req.SourceID, Pages: req.Pages})
+	job, err := s.enqueueJob(ctx, jobTypeBackfill, payload, time.Now())
+	if err != nil {
+		return dto.JobResponse{}, err
+	}
+
+	logger.For("jobs").Info("Backfill queued",
+		"job_id", job.ID,
+		"source_id", req.SourceID,
+		"actor", actorFromContext(ctx),
+	)
+	return toJobResponse(job), nil
+}
+
+// GetJobs lists the newest jobs, optionally of one type and status
+func (s *service) GetJobs(ctx context.Context, req dto.JobListRequest) ([]dto.JobResponse, error) {
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultJobListLimit
+	}
+	jobs, err := s.repo.JobRepository.GetJobs(ctx, onefeed_th_sqlc.ListJobsParams{
+		Type:      req.Type,
+		Status:    req.Status,
+		PageLimit: limit,
+	})
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve jobs from database").
+			WithCode("DB_QUERY_FAILED").
+			WithCaller()
+	}
+
+	responses := make([]dto.JobResponse, 0, len(jobs))
+	for _, job := range jobs {
+		responses = append(responses, toJobResponse(job))
+	}
+	return responses, nil
+}
+
+// RetryJob queues a failed job again with its attempts reset
+func (s *service) RetryJob(ctx context.Context, req dto.JobRetryRequest) (dto.JobResponse, error) {
+	job, err := s.repo.JobRepository.RequeueJob(ctx, onefeed_th_sqlc.RequeueJobParams{
+		ScheduledAt: converter.TimeToPGTypeTimestamp(time.Now()),
+		ID:          req.ID,
+	})
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return dto.JobResponse{}, apperrors.Newf(apperrors.ConflictError, "job %d is of a type queued once and another is already pending", req.ID).
+			WithCode("JOB_ALREADY_PENDING")
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		// either there is no such job or it hasn't failed
+		job, err = s.repo.JobRepository.GetJobByID(ctx, req.ID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return dto.JobResponse{}, apperrors.Newf(apperrors.NotFoundError, "job %d not found", req.ID).
+				WithCode("JOB_NOT_FOUND")
+		}
+		if err == nil {
+			return dto.JobResponse{}, apperrors.Newf(apperrors.ConflictError, "job %d is %s, only failed jobs can be retried", req.ID, job.Status).
+				WithCode("JOB_NOT_FAILED")
+		}
+	}
+	if err != nil {
+		return dto.JobResponse{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retry job").
+			WithCode("DB_UPDATE_FAILED").
+			WithDetails(fmt.Sprintf("id: %d", req.ID)).
+			WithCaller()
+	}
+
+	logger.For("jobs").Info("Job queued for retry",
+		"job_id", job.ID,
+		"type", job.Type,
+		"actor", actorFromContext(ctx),
+	)
+	return toJobResponse(job), nil
+}
+
+// enqueueJob queues a job of jobType to run at runAt. A unique type that is already
+// pending isn't queued twice, the pending job is returned instead, moved up to runAt when it
+// was scheduled later
+func (s *service) enqueueJob(ctx context.Context, jobType string, payload []byte, runAt time.Time) (onefeed_th_sqlc.Job, error) {
+	handler, ok := s.jobs[jobType]
+	if !ok {
+		return onefeed_th_sqlc.Job{}, apperrors.Newf(apperrors.ValidationError, "unknown job type %q", jobType).
+			WithCode("INVALID_JOB_TYPE")
+	}
+
+	if len(payload) == 0 {
+		payload = []byte("{}")
+	}
+	maxAttempts, _ := handler.retryPolicy()
+	job, err := s.repo.JobRepository.CreateJob(ctx, onefeed_th_sqlc.CreateJobParams{
+		Type:        jobType,
+		Payload:     payload,
+		MaxAttempts: int32(max(maxAttempts, 1)),
+		ScheduledAt: converter.TimeToPGTypeTimestamp(runAt),
+		IsUnique:    handler.unique,
+	})
+	if err != nil {
+		return job, apperrors.Wrap(err, apperrors.DatabaseError, "failed to store job").
+			WithCode("DB_INSERT_FAILED").
+			WithCaller()
+	}
+
+	logger.For("jobs").Debug("Job queued", "job_id", job.ID, "type", job.Type)
+	return job, nil
+}
+
+// scheduleJob queues the next run of a recurring job type, unless one is pending
+func (s *service) scheduleJob(ctx context.Context, jobType string) {
+	handler, ok := s.jobs[jobType]
+	if !ok || handler.next == nil {
+		return
+	}
+	runAt, ok := handler.next(time.Now())
+	if !ok {
+		return
+	}
+	if _, err := s.enqueueJob(context.WithoutCancel(ctx), jobType, nil, runAt); err != nil {
+		logger.For("jobs").Warn("Failed to schedule job", "type", jobType, "error", err)
+	}
+}
+
+// RunJobWorkers runs queued jobs until ctx is done, up to jobs.workers at a time. Every
+// instance may run them; jobs are claimed with a lease so each runs on a single worker
+func (s *service) RunJobWorkers(ctx context.Context) {
+	workers := config.GetConfig().Jobs.Workers
+	if workers <= 0 {
+		return
+	}
+
+	for jobType := range s.jobs {
+		s.scheduleJob(ctx, jobType)
+	}
+
+	slots := make(chan struct{}, workers)
+	ticker := time.NewTicker(time.Duration(max(config.GetConfig().Jobs.PollInterval, 1)) * time.Second)
+	defer ticker.Stop()
+	for {
+		// only claim what the free workers can start right away, so a long collection
+		// doesn't hold back the jobs claimed with it
+		if free := workers - len(slots); free > 0 {
+			for _, job := range s.claimJobs(ctx, free) {
+				slots <- struct{}{}
+				go func() {
+					defer func() { <-slots }()
+					s.runJob(ctx, job)
+				}()
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *service) claimJobs(ctx context.Context, limit int) []onefeed_th_sqlc.Job {
+	now := time.Now()
+	lease := time.Duration(config.GetConfig().Jobs.Lease) * time.Second
+	jobs, err := s.repo.JobRepository.ClaimJobs(ctx, onefeed_th_sqlc.ClaimJobsParams{
+		Now:        converter.TimeToPGTypeTimestamp(now),
+		LeaseUntil: converter.TimeToPGTypeTimestamp(now.Add(lease)),
+		PageLimit:  int32(limit),
+	})
+	if err != nil && ctx.Err() == nil {
+		logger.For("jobs").Error("Failed to claim jobs", "error", err)
+	}
+	return jobs
+}
+
+// runJob runs one claimed job and settles it: failures wait out a doubling backoff until the
+// job's attempts are used up, then it is marked failed. The lease is extended while the job
+// runs, and a job whose lease was lost anyway is left to the worker that claimed it again
+func (s *service) runJob(ctx context.Context, job onefeed_th_sqlc.Job) {
+	log := logger.For("jobs")
+	handler, ok := s.jobs[job.Type]
+
+	start := time.Now()
+	var (
+		result any
+		err    error
+	)
+	switch {
+	case !ok:
+		err = permanent(fmt.Errorf("unknown job type %q", job.Type))
+	case job.Attempts > job.MaxAttempts:
+		// the worker running its last attempt stopped before settling it
+		err = permanent(errors.New("lease expired on the last attempt"))
+	default:
+		runCtx, cancel := context.WithCancel(ctx)
+		go s.keepJobLease(runCtx, job, cancel)
+		result, err = handler.run(runCtx, job.Payload)
+		cancel()
+	}
+	var resultJSON []byte
+	if result != nil {
+		var marshalErr error
+		if resultJSON, marshalErr = json.Marshal(result); marshalErr != nil {
+			log.Warn("Failed to encode job result", "job_id", job.ID, "type", job.Type, "error", marshalErr)
+		}
+	}
+
+	// a job cut short by a shutdown is still settled, and retried by another instance
+	ctx = context.WithoutCancel(ctx)
+	var (
+		settled      int64
+		settleErr    error
+		permanentErr *permanentJobError
+		pgErr        *pgconn.PgError
+	)
+	switch {
+	case err == nil:
+		log.Info("Job succeeded",
+			"job_id", job.ID,
+			"type", job.Type,
+			"attempts", job.Attempts,
+			"duration", time.Since(start),
+		)
+		settled, settleErr = s.repo.JobRepository.CompleteJob(ctx, onefeed_th_sqlc.CompleteJobParams{
+			Result:   resultJSON,
+			ID:       job.ID,
+			Attempts: job.Attempts,
+		})
+	case !errors.As(err, &permanentErr) && job.Attempts < job.MaxAttempts:
+		_, backoff := handler.retryPolicy()
+		backoff <<= job.Attempts - 1
+		log.Warn("Job failed, retrying",
+			"job_id", job.ID,
+			"type", job.Type,
+			"attempts", job.Attempts,
+			"retry_in", backoff,
+			"error", err,
+		)
+		settled, settleErr = s.repo.JobRepository.RetryJob(ctx, onefeed_th_sqlc.RetryJobParams{
+			LastError:   pgtype.Text{String: err.Error(), Valid: true},
+			Result:      resultJSON,
+			ScheduledAt: converter.TimeToPGTypeTimestamp(time.Now().Add(backoff)),
+			ID:          job.ID,
+			Attempts:    job.Attempts,
+		})
+		if errors.As(settleErr, &pgErr) && pgErr.Code == "23505" {
+			// a run of the same unique type was queued meanwhile and takes over the retry
+			settled, settleErr = s.repo.JobRepository.FailJob(ctx, onefeed_th_sqlc.FailJobParams{
+				LastError: pgtype.Text{String: err.Error(), Valid: true},
+				Result:    resultJSON,
+				ID:        job.ID,
+				Attempts:  job.Attempts,
+			})
+		}
+	default:
+		log.Error("Job failed",
+			"job_id", job.ID,
+			"type", job.Type,
+			"attempts", job.Attempts,
+			"error", err,
+		)
+		settled, settleErr = s.repo.JobRepository.FailJob(ctx, onefeed_th_sqlc.FailJobParams{
+			LastError: pgtype.Text{String: err.Error(), Valid: true},
+			Result:    resultJSON,
+			ID:        job.ID,
+			Attempts:  job.Attempts,
+		})
+		if settleErr == nil && settled > 0 && handler.failed != nil {
+			handler.failed(ctx, job, err)
+		}
+	}
+	switch {
+	case settleErr != nil:
+		log.Error("Failed to settle job", "job_id", job.ID, "error", settleErr)
+	case settled == 0:
+		log.Warn("Job lease was lost, leaving the job to the worker that claimed it again",
+			"job_id", job.ID,
+			"type", job.Type,
+			"attempts", job.Attempts,
+		)
+	}
+	s.scheduleJob(ctx, job.Type)
+}
+
+// keepJobLease extends the lease of a running job every third of jobs.lease until ctx is
+// done, so a job that runs longer than one lease isn't claimed by another worker. The job is
+// cancelled when its lease was lost anyway, e.g. after the instance stalled past it
+func (s *service) keepJobLease(ctx context.Context, job onefeed_th_sqlc.Job, cancel context.CancelFunc) {
+	log := logger.For("jobs")
+	lease := time.Duration(config.GetConfig().Jobs.Lease) * time.Second
+	ticker := time.NewTicker(lease / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		extended, err := s.repo.JobRepository.ExtendJobLease(ctx, onefeed_th_sqlc.ExtendJobLeaseParams{
+			LeaseUntil: converter.TimeToPGTypeTimestamp(time.Now().Add(lease)),
+			ID:         job.ID,
+			Attempts:   job.Attempts,
+		})
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Warn("Failed to extend job lease", "job_id", job.ID, "type", job.Type, "error", err)
+			}
+			continue
+		}
+		if extended == 0 {
+			log.Warn("Job lease was lost, stopping the job", "job_id", job.ID, "type", job.Type)
+			cancel()
+			return
+		}
+	}
+}
+
+// removeFinishedJobs drops the records of jobs finished more than jobs.retentionDays ago
+func (s *service) removeFinishedJobs(ctx context.Context) {
+	days := config.GetConfig().Jobs.RetentionDays
+	removed, err := s.repo.JobRepository.DeleteJobsFinishedBefore(ctx, time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		logger.For("jobs").Warn("Failed to remove finished jobs", "error", err)
+		return
+	}
+	if removed > 0 {
+		logger.For("jobs").Info("Removed finished jobs", "retention_days", days, "removed_count", removed)
+	}
+}
+
+func toJobResponse(job onefeed_th_sqlc.Job) dto.JobResponse {
+	response := dto.JobResponse{
+		ID:          job.ID,
+		Type:        job.Type,
+		Payload:     json.RawMessage(job.Payload),
+		Status:      job.Status,
+		Attempts:    job.Attempts,
+		MaxAttempts: job.MaxAttempts,
+		LastError:   converter.PGTypeTextToString(job.LastError),
+		ScheduledAt: converter.PGTypeTimestampToTime(job.ScheduledAt),
+		CreatedAt:   converter.PGTypeTimestampToTime(job.CreatedAt),
+		Result:      json.RawMessage(job.Result),
+	}
+	if job.StartedAt.Valid {
+		startedAt := job.StartedAt.Time
+		response.StartedAt = &startedAt
+	}
+	if job.FinishedAt.Valid {
+		finishedAt := job.FinishedAt.Time
+		response.FinishedAt = &finishedAt
+		if job.StartedAt.Valid {
+			duration := finishedAt.Sub(job.StartedAt.Time).Milliseconds()
+			response.DurationMs = &duration
+		}
+	}
+	return response
+}
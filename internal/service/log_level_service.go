@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/logger"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/dto"
+	apperrors "github.com/onefeed-th/onefeed-th-backend-api/internal/errors"
+)
+
+// LogLevelService changes the log levels of the answering instance until its next restart
+// or a config reload that changes log.level or log.levels
+type LogLevelService interface {
+	GetLogLevels(ctx context.Context, req dto.BlankRequest) (dto.LogLevelResponse, error)
+	UpdateLogLevel(ctx context.Context, req dto.LogLevelUpdateRequest) (dto.LogLevelResponse, error)
+}
+
+func (s *service) GetLogLevels(ctx context.Context, req dto.BlankRequest) (dto.LogLevelResponse, error) {
+	return logLevels(), nil
+}
+
+func (s *service) UpdateLogLevel(ctx context.Context, req dto.LogLevelUpdateRequest) (dto.LogLevelResponse, error) {
+	module := strings.ToLower(req.Module)
+	if module != "" && req.Level == "" {
+		logger.SetModuleLevel(module, nil)
+		slog.Info("Log level reset", "log_module", module)
+		return logLevels(), nil
+	}
+
+	level, err := logger.ParseLevel(req.Level)
+	if err != nil {
+		return dto.LogLevelResponse{}, apperrors.Wrap(err, apperrors.ValidationError, "level must be debug, info, warn or error").
+			WithCode("INVALID_LOG_LEVEL")
+	}
+	if module == "" {
+		logger.SetLevel(level)
+	} else {
+		logger.SetModuleLevel(module, &level)
+	}
+	slog.Info("Log level changed", "log_module", module, "level", level.String())
+	return logLevels(), nil
+}
+
+func logLevels() dto.LogLevelResponse {
+	response := dto.LogLevelResponse{
+		Level:   logger.Level().String(),
+		Modules: map[string]string{},
+	}
+	for module, level := range logger.ModuleLevels() {
+		response.Modules[module] = level.String()
+	}
+	return response
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/utils/converter"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/dto"
+	apperrors "github.com/onefeed-th/onefeed-th-backend-api/internal/errors"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/repository"
+	onefeed_th_sqlc "github.com/onefeed-th/onefeed-th-backend-api/internal/sqlc/onefeed_th_sqlc/db"
+)
+
+type ModerationService interface {
+	HideNews(ctx context.Context, req dto.NewsModerationRequest) (any, error)
+	RestoreNews(ctx context.Context, req dto.NewsModerationRequest) (any, error)
+	GetNewsModerationLogs(ctx context.Context, req dto.NewsModerationLogGetRequest) ([]dto.NewsModerationLogResponse, error)
+}
+
+func (s *service) HideNews(ctx context.Context, req dto.NewsModerationRequest) (any, error) {
+	return nil, s.setNewsHidden(ctx, req, true)
+}
+
+func (s *service) RestoreNews(ctx context.Context, req dto.NewsModerationRequest) (any, error) {
+	return nil, s.setNewsHidden(ctx, req, false)
+}
+
+func (s *service) setNewsHidden(ctx context.Context, req dto.NewsModerationRequest, hidden bool) error {
+	action := repository.NewsModerationActionRestore
+	if hidden {
+		action = repository.NewsModerationActionHide
+	}
+	// Audit entries name the authenticated caller, never a name the caller picks
+	actor := actorFromContext(ctx)
+
+	affected, err := s.repo.NewsModerationRepository.SetNewsHidden(ctx,
+		onefeed_th_sqlc.SetNewsHiddenParams{
+			Hidden: hidden,
+			ID:     req.ID,
+		},
+		onefeed_th_sqlc.CreateNewsModerationLogParams{
+			Action: action,
+			Reason: converter.StringToPGTypeTextNull(req.Reason),
+			Actor:  converter.StringToPGTypeTextNull(actor),
+		},
+	)
+	if err != nil {
+		slog.Error("Failed to update news visibility",
+			"id", req.ID,
+			"action", action,
+			"error", err,
+		)
+		return apperrors.Wrap(err, apperrors.DatabaseError, "failed to update news visibility").
+			WithCode("DB_UPDATE_FAILED").
+			WithDetails(fmt.Sprintf("id: %d, action: %s", req.ID, action)).
+			WithCaller()
+	}
+	if affected == 0 {
+		return apperrors.Newf(apperrors.NotFoundError, "news %d not found", req.ID).
+			WithCode("NEWS_NOT_FOUND").
+			WithCaller()
+	}
+
+	// Every cached list, detail and feed may contain the item, so drop them all
+	if err := s.redis.RemoveKeyContaining(ctx, "news"); err != nil {
+		slog.Warn("Failed to remove news cache keys", "error", err)
+	}
+	s.notifyNewsChanged(ctx, newsChangeModeration)
+
+	slog.Info("News visibility updated",
+		"id", req.ID,
+		"action", action,
+		"actor", actor,
+	)
+	return nil
+}
+
+func (s *service) GetNewsModerationLogs(ctx context.Context, req dto.NewsModerationLogGetRequest) ([]dto.NewsModerationLogResponse, error) {
+	logs, err := s.repo.NewsModerationRepository.GetNewsModerationLogs(ctx, req.ID)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve moderation logs from database").
+			WithCode("DB_QUERY_FAILED").
+			WithDetails(fmt.Sprintf("id: %d", req.ID)).
+			WithCaller()
+	}
+
+	responses := make([]dto.NewsModerationLogResponse, 0, len(logs))
+	for _, log := range logs {
+		responses = append(responses, dto.NewsModerationLogResponse{
+			ID:        log.ID,
+			NewsID:    log.NewsID,
+			Action:    log.Action,
+			Reason:    converter.PGTypeTextToString(log.Reason),
+			Actor:     converter.PGTypeTextToString(log.Actor),
+			CreatedAt: converter.PGTypeTimestampToTime(log.CreatedAt),
+		})
+	}
+	return responses, nil
+}
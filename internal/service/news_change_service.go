@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/dto"
+)
+
+const (
+	newsChangeCollect    = "collect"
+	newsChangeModeration = "moderation"
+	newsChangeRetention  = "retention"
+)
+
+// newsChange is the payload of a cache warm job
+type newsChange struct {
+	Reason string `json:"reason"`
+}
+
+// notifyNewsChanged queues a cache warm job after news changed. It runs after the cache keys
+// were removed, so the job warms from fresh data
+func (s *service) notifyNewsChanged(ctx context.Context, reason string) {
+	payload, _ := json.Marshal(newsChange{Reason: reason})
+	if _, err := s.enqueueJob(ctx, jobTypeCacheWarm, payload, time.Now()); err != nil {
+		slog.Warn("Failed to enqueue cache warm job",
+			"reason", reason,
+			"error", err,
+		)
+	}
+}
+
+// warmNewsCache drops cached news and warms the responses that don't depend on client
+// parameters
+func (s *service) warmNewsCache(ctx context.Context, payload []byte) error {
+	var change newsChange
+	if err := json.Unmarshal(payload, &change); err != nil {
+		return permanent(err)
+	}
+	slog.Info("News changed, refreshing cache", "reason", change.Reason)
+
+	if err := s.redis.RemoveKeyContaining(ctx, "news"); err != nil {
+		return err
+	}
+	if _, err := s.GetRSSFeed(ctx, dto.FeedGetRequest{}); err != nil {
+		return err
+	}
+	if _, err := s.GetTrendingNews(ctx, dto.NewsTrendingGetRequest{}); err != nil {
+		return err
+	}
+	return nil
+}
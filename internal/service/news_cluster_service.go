@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/onefeed-th/onefeed-th-backend-api/config"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/logger"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/utils/converter"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/dto"
+	apperrors "github.com/onefeed-th/onefeed-th-backend-api/internal/errors"
+	onefeed_th_sqlc "github.com/onefeed-th/onefeed-th-backend-api/internal/sqlc/onefeed_th_sqlc/db"
+)
+
+// clusterCandidates is how many similar titles are looked at for a news; the most similar
+// ones are often from the same source, which don't make a duplicate
+const clusterCandidates = 10
+
+// clusterNews puts every created news into the story of the most similar title another source
+// published within clustering.window of it. A story is identified by the id of the news that
+// started it, which gets no row of its own; news without a match start a story themselves
+func (s *service) clusterNews(ctx context.Context, news []onefeed_th_sqlc.News) {
+	cfg := config.GetConfig().Clustering
+	if !cfg.Enabled || len(news) == 0 {
+		return
+	}
+
+	log := logger.For("collector")
+	window := time.Duration(cfg.Window) * time.Hour
+	// the stories of this run, so news collected together join each other
+	clusters := make(map[int64]int64, len(news))
+	var params onefeed_th_sqlc.CreateNewsClustersParams
+	for _, item := range news {
+		published := item.PublishDate.Time
+		if !item.PublishDate.Valid {
+			published = item.FetchedAt.Time
+		}
+
+		similar, err := s.repo.NewsRepository.GetSimilarNews(ctx, onefeed_th_sqlc.ListSimilarNewsParams{
+			ID:            item.ID,
+			WindowStart:   converter.TimeToPGTypeTimestamp(published.Add(-window)),
+			WindowEnd:     converter.TimeToPGTypeTimestamp(published.Add(window)),
+			Title:         item.Title,
+			MinSimilarity: float32(cfg.MinSimilarity),
+			PageLimit:     clusterCandidates,
+		})
+		if err != nil {
+			log.Warn("Failed to find similar news, skipping clustering", "error", err)
+			return
+		}
+		i := slices.IndexFunc(similar, func(match onefeed_th_sqlc.News) bool { return match.Source != item.Source })
+		if i < 0 {
+			continue
+		}
+
+		match := similar[i]
+		clusterID, ok := clusters[match.ID]
+		if !ok {
+			stored, err := s.repo.NewsClusterRepository.GetNewsClusters(ctx, []int64{match.ID})
+			if err != nil {
+				log.Warn("Failed to load news clusters, skipping clustering", "error", err)
+				return
+			}
+			clusterID = match.ID
+			if len(stored) > 0 {
+				clusterID = stored[0].ClusterID
+			}
+		}
+		// a news of this run matched one that already joined its story
+		if clusterID == item.ID {
+			continue
+		}
+
+		clusters[item.ID] = clusterID
+		params.NewsIds = append(params.NewsIds, item.ID)
+		params.ClusterIds = append(params.ClusterIds, clusterID)
+		params.PublishDates = append(params.PublishDates, converter.TimeToPGTypeTimestamp(published))
+	}
+	if len(params.NewsIds) == 0 {
+		return
+	}
+
+	if err := s.repo.NewsClusterRepository.CreateNewsClusters(ctx, params); err != nil {
+		log.Warn("Failed to store news clusters", "error", err)
+		return
+	}
+	log.Info("Duplicate news clustered", "clustered_news", len(params.NewsIds))
+}
+
+// listClusteredNews returns a page of stories: the first news of each, with the number of other
+// sources that covered it
+func (s *service) listClusteredNews(ctx context.Context, req dto.NewsListGetRequest) ([]dto.NewsListGetResponse, error) {
+	rows, err := s.repo.NewsClusterRepository.GetClusteredNews(ctx, onefeed_th_sqlc.ListClusteredNewsParams{
+		Sources:    req.Source,
+		PageOffset: (req.Page - 1) * req.Limit,
+		PageLimit:  req.Limit,
+	})
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve clustered news from database").
+			WithCode("DB_QUERY_FAILED").
+			WithDetails(fmt.Sprintf("sources: %v, page: %d, limit: %d", req.Source, req.Page, req.Limit)).
+			WithCaller()
+	}
+
+	responses := make([]dto.NewsListGetResponse, 0, len(rows))
+	for _, row := range rows {
+		response := toNewsListGetResponse(onefeed_th_sqlc.News{
+			ID:          row.ID,
+			Title:       row.Title,
+			Link:        row.Link,
+			Source:      row.Source,
+			ImageUrl:    row.ImageUrl,
+			PublishDate: row.PublishDate,
+			FetchedAt:   row.FetchedAt,
+			Summary:     row.Summary,
+			Hidden:      row.Hidden,
+			UpdatedAt:   row.UpdatedAt,
+		})
+		response.AlsoCoveredBy = row.AlsoCoveredBy
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
+// countClusteredNews returns the number of stories for the sources, cached alongside the pages
+func (s *service) countClusteredNews(ctx context.Context, sources []string) (int64, error) {
+	return loadCached(ctx, s.redis, fmt.Sprintf("news:count:clustered:source=%v", sources), func() (int64, error) {
+		return s.repo.NewsClusterRepository.CountClusteredNews(ctx, sources)
+	})
+}
+
+// removeExpiredNewsClusters drops the clusters of the news past retention
+func (s *service) removeExpiredNewsClusters(ctx context.Context, before time.Time) {
+	if err := s.repo.NewsClusterRepository.RemoveNewsClustersBefore(ctx, converter.TimeToPGTypeTimestamp(before)); err != nil {
+		slog.Warn("Failed to remove expired news clusters", "error", err)
+	}
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/auth"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/utils/converter"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/dto"
+	apperrors "github.com/onefeed-th/onefeed-th-backend-api/internal/errors"
+	onefeed_th_sqlc "github.com/onefeed-th/onefeed-th-backend-api/internal/sqlc/onefeed_th_sqlc/db"
+)
+
+type NewsReadService interface {
+	MarkNewsRead(ctx context.Context, req dto.NewsReadMarkRequest) (any, error)
+	UnmarkNewsRead(ctx context.Context, req dto.NewsReadDeleteRequest) (any, error)
+	GetReadHistory(ctx context.Context, req dto.NewsReadListRequest) (dto.NewsReadListResult, error)
+}
+
+const defaultReadHistoryLimit = 20
+
+// MarkNewsRead records articles the reader opened. Apps may send reads made offline in
+// one batch; articles already marked keep their first read time
+func (s *service) MarkNewsRead(ctx context.Context, req dto.NewsReadMarkRequest) (any, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.repo.NewsReadRepository.CreateNewsReads(ctx, onefeed_th_sqlc.CreateNewsReadsParams{
+		UserID:  userID,
+		NewsIds: req.NewsIDs,
+	})
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.DatabaseError, "failed to store read history").
+			WithCode("DB_INSERT_FAILED").
+			WithCaller()
+	}
+	return nil, nil
+}
+
+func (s *service) UnmarkNewsRead(ctx context.Context, req dto.NewsReadDeleteRequest) (any, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	affected, err := s.repo.NewsReadRepository.DeleteNewsRead(ctx, onefeed_th_sqlc.DeleteNewsReadParams{
+		UserID: userID,
+		NewsID: req.NewsID,
+	})
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.DatabaseError, "failed to delete read history").
+			WithCode("DB_DELETE_FAILED").
+			WithCaller()
+	}
+	if affected == 0 {
+		return nil, apperrors.Newf(apperrors.NotFoundError, "news %d is not marked as read", req.NewsID).
+			WithCode("NEWS_READ_NOT_FOUND")
+	}
+	return nil, nil
+}
+
+// GetReadHistory lists the reader's reads, newest first, with the articles still available
+func (s *service) GetReadHistory(ctx context.Context, req dto.NewsReadListRequest) (dto.NewsReadListResult, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return dto.NewsReadListResult{}, err
+	}
+	if req.Limit <= 0 {
+		req.Limit = defaultReadHistoryLimit
+	}
+
+	params := onefeed_th_sqlc.ListNewsReadsParams{
+		UserID:    userID,
+		PageLimit: req.Limit,
+	}
+	if req.Since != "" {
+		// validated as RFC 3339 by the request
+		since, _ := time.Parse(time.RFC3339, req.Since)
+		params.Since = converter.TimeToPGTypeTimestamp(since.UTC())
+	}
+	if req.Cursor != "" {
+		readAt, newsID, err := decodeKeysetCursor(req.Cursor)
+		if err != nil {
+			return dto.NewsReadListResult{}, apperrors.Wrap(err, apperrors.ValidationError, "invalid cursor").
+				WithCode("INVALID_CURSOR").
+				WithCaller()
+		}
+		params.BeforeReadAt = converter.TimeToPGTypeTimestamp(readAt)
+		params.BeforeNewsID = newsID
+	}
+
+	reads, err := s.repo.NewsReadRepository.GetNewsReads(ctx, params)
+	if err != nil {
+		return dto.NewsReadListResult{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve read history").
+			WithCode("DB_QUERY_FAILED").
+			WithCaller()
+	}
+
+	ids := make([]int64, 0, len(reads))
+	for _, read := range reads {
+		ids = append(ids, read.NewsID)
+	}
+	byID, err := s.newsByIDs(ctx, ids)
+	if err != nil {
+		return dto.NewsReadListResult{}, err
+	}
+
+	result := dto.NewsReadListResult{Items: make([]dto.NewsReadResponse, 0, len(reads))}
+	for _, read := range reads {
+		response := dto.NewsReadResponse{
+			NewsID: read.NewsID,
+			ReadAt: converter.PGTypeTimestampToTime(read.ReadAt),
+		}
+		if item, ok := byID[read.NewsID]; ok {
+			listItem := toNewsListGetResponse(item)
+			response.News = &listItem
+		}
+		result.Items = append(result.Items, response)
+	}
+	if len(reads) == int(req.Limit) {
+		last := reads[len(reads)-1]
+		result.NextCursor = encodeKeysetCursor(last.ReadAt.Time, last.NewsID)
+	}
+	return result, nil
+}
+
+// setReadState flags which items the signed-in reader has read. Anonymous requests are
+// left untouched, and a failed lookup only drops the flags
+func (s *service) setReadState(ctx context.Context, items []dto.NewsListGetResponse) {
+	userID, ok := auth.UserIDFromContext(ctx)
+	if !ok || len(items) == 0 {
+		return
+	}
+
+	ids := make([]int64, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	readIDs, err := s.repo.NewsReadRepository.GetReadNewsIDs(ctx, onefeed_th_sqlc.ListReadNewsIDsParams{
+		UserID:  userID,
+		NewsIds: ids,
+	})
+	if err != nil {
+		slog.Warn("Failed to load read state, returning news without it",
+			"user_id", userID,
+			"error", err,
+		)
+		return
+	}
+
+	read := make(map[int64]bool, len(readIDs))
+	for _, id := range readIDs {
+		read[id] = true
+	}
+	for i := range items {
+		state := read[items[i].ID]
+		items[i].ReadState = &state
+	}
+}
+
+// newsByIDs loads the visible articles of a reader's list, keyed by id
+func (s *service) newsByIDs(ctx context.Context, ids []int64) (map[int64]onefeed_th_sqlc.News, error) {
+	news, err := s.repo.NewsRepository.GetNewsByIDs(ctx, ids)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve news from database").
+			WithCode("DB_QUERY_FAILED").
+			WithCaller()
+	}
+	byID := make(map[int64]onefeed_th_sqlc.News, len(news))
+	for _, item := range news {
+		byID[item.ID] = item
+	}
+	return byID, nil
+}
+
+// Keyset cursors of read history, bookmarks and news lists carry the last item's time, in
+// microseconds as Postgres keeps it, and its news id
+func encodeKeysetCursor(at time.Time, newsID int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("at:%d:%d", at.UnixMicro(), newsID)))
+}
+
+func decodeKeysetCursor(cursor string) (time.Time, int64, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	var micros, newsID int64
+	if _, err := fmt.Sscanf(string(raw), "at:%d:%d", &micros, &newsID); err != nil {
+		return time.Time{}, 0, err
+	}
+	return time.UnixMicro(micros).UTC(), newsID, nil
+}
@@ -5,52 +5,147 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"sort"
+	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/onefeed-th/onefeed-th-backend-api/config"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/auth"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/rds"
 	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/utils/converter"
 	"github.com/onefeed-th/onefeed-th-backend-api/internal/dto"
 	apperrors "github.com/onefeed-th/onefeed-th-backend-api/internal/errors"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/repository"
 	onefeed_th_sqlc "github.com/onefeed-th/onefeed-th-backend-api/internal/sqlc/onefeed_th_sqlc/db"
 	"github.com/redis/go-redis/v9"
 )
 
 type NewsService interface {
-	GetNews(ctx context.Context, req dto.NewsListGetRequest) ([]dto.NewsListGetResponse, error)
+	GetNews(ctx context.Context, req dto.NewsListGetRequest) (dto.NewsListGetResult, error)
+	QueryNews(ctx context.Context, req dto.NewsListQueryRequest) (dto.NewsListGetResult, error)
 	RemoveOldNews(ctx context.Context, req dto.BlankRequest) (any, error)
+	GetNewsDetail(ctx context.Context, req dto.NewsDetailGetRequest) (dto.NewsDetailGetResponse, error)
+	GetNewsByIDs(ctx context.Context, req dto.NewsBatchGetRequest) ([]dto.NewsListGetResponse, error)
+	GetRelatedNews(ctx context.Context, req dto.NewsRelatedGetRequest) ([]dto.NewsListGetResponse, error)
+	DiscoverNews(ctx context.Context, req dto.NewsDiscoverGetRequest) ([]dto.NewsListGetResponse, error)
+	GetArchivedNews(ctx context.Context, req dto.NewsArchiveGetRequest) (dto.NewsListGetResult, error)
 }
 
-func (s *service) GetNews(ctx context.Context, req dto.NewsListGetRequest) ([]dto.NewsListGetResponse, error) {
-	if len(req.Source) == 0 {
-		return nil, apperrors.New(apperrors.ValidationError, "source is required").
-			WithCode("MISSING_SOURCE").
-			WithCaller()
+const (
+	newsRetentionDays  = 30
+	archiveMonthLayout = "2006-01"
+	// retentionLockTTL outlasts archiving a month of news
+	retentionLockTTL = 30 * time.Minute
+	// newsPartitionMonthsAhead is how many months past the current one have their news
+	// partition created by the retention job, ahead of the collections inserting into them
+	newsPartitionMonthsAhead = 2
+
+	defaultNewsPerSource = 5
+	maxNewsPerSource     = 20
+
+	relatedNewsLimit = 5
+	maxBatchNewsIDs  = 100
+
+	similarNewsLimit         = 10
+	similarNewsWindow        = 3 * 24 * time.Hour
+	similarNewsMinSimilarity = 0.3
+
+	// discover samples from a bounded recent window so ORDER BY random() stays cheap
+	discoverWindow       = 48 * time.Hour
+	defaultDiscoverLimit = 20
+)
+
+func (s *service) GetNews(ctx context.Context, req dto.NewsListGetRequest) (dto.NewsListGetResult, error) {
+	if req.GroupBySource {
+		return s.getNewsGroupedBySource(ctx, req)
 	}
 
-	if req.Page <= 0 {
+	if req.Page <= 0 && req.Cursor == "" {
 		req.Page = 1
 	}
 	if req.Limit <= 0 || req.Limit > 100 {
 		req.Limit = 20
 	}
 
+	sort := repository.NewsSortPublishedAtDesc
+	if req.Sort != "" {
+		sort = repository.NewsSort(req.Sort)
+		if !sort.IsValid() {
+			return dto.NewsListGetResult{}, apperrors.Newf(apperrors.ValidationError, "unsupported sort %q", req.Sort).
+				WithCode("INVALID_SORT").
+				WithCaller()
+		}
+	}
+	if req.GroupDuplicates && sort != repository.NewsSortPublishedAtDesc {
+		return dto.NewsListGetResult{}, apperrors.Newf(apperrors.ValidationError, "sort %q is not supported with groupDuplicates", req.Sort).
+			WithCode("INVALID_SORT").
+			WithCaller()
+	}
+
+	// the default order is also read by keyset, continuing from the last item of a page
+	keyset := sort == repository.NewsSortPublishedAtDesc && !req.GroupDuplicates
+	var (
+		cursorPublishedAt time.Time
+		cursorID          int64
+	)
+	if req.Cursor != "" {
+		if !keyset {
+			return dto.NewsListGetResult{}, apperrors.New(apperrors.ValidationError, "cursor is only supported with the default sort and without groupDuplicates").
+				WithCode("INVALID_CURSOR").
+				WithCaller()
+		}
+		var err error
+		if cursorPublishedAt, cursorID, err = decodeKeysetCursor(req.Cursor); err != nil {
+			return dto.NewsListGetResult{}, apperrors.Wrap(err, apperrors.ValidationError, "invalid cursor").
+				WithCode("INVALID_CURSOR").
+				WithCaller()
+		}
+	}
+
+	// finish completes a page of news into the result, the cursor taken before personalization
+	// reorders the items
+	finish := func(responses []dto.NewsListGetResponse) dto.NewsListGetResult {
+		result := s.buildNewsListResult(ctx, req, responses)
+		if keyset && len(responses) == int(req.Limit) {
+			last := responses[len(responses)-1]
+			result.NextCursor = encodeKeysetCursor(last.PublishedAt, last.ID)
+		}
+		return s.rankNewsListResult(ctx, req, result)
+	}
+
 	var responses []dto.NewsListGetResponse
-	redisKey := fmt.Sprintf("news:source=%v:page=%d:limit=%d", req.Source, req.Page, req.Limit)
+	redisKey := fmt.Sprintf("news:source=%v:page=%d:limit=%d:sort=%s", req.Source, req.Page, req.Limit, sort)
+	if req.Cursor != "" {
+		redisKey = fmt.Sprintf("news:source=%v:cursor=%s:limit=%d", req.Source, req.Cursor, req.Limit)
+	}
+	if req.GroupDuplicates {
+		redisKey = fmt.Sprintf("news:clustered:source=%v:page=%d:limit=%d", req.Source, req.Page, req.Limit)
+	}
 
 	slog.Debug("Starting news retrieval",
 		"sources", req.Source,
 		"page", converter.Int32ToInt(req.Page),
 		"limit", converter.Int32ToInt(req.Limit),
+		"sort", sort,
 		"cache_key", redisKey,
 	)
 
 	// Try to get from cache first
 	err := s.redis.Get(ctx, redisKey, &responses)
+	if errors.Is(err, rds.ErrCachedEmpty) {
+		// Sources without news, or a page past the end, recently queried
+		slog.Debug("Cache hit for an empty page",
+			"cache_key", redisKey,
+		)
+		return finish([]dto.NewsListGetResponse{}), nil
+	}
 	if err == nil && len(responses) > 0 {
 		// Cache hit - return cached data
 		slog.Info("Cache hit",
 			"cache_key", redisKey,
 			"items_count", len(responses),
 		)
-		return responses, nil
+		return finish(responses), nil
 	}
 	if err != nil && !errors.Is(err, redis.Nil) {
 		// Continue to database query on Redis error, but wrap error for monitoring
@@ -71,11 +166,79 @@ func (s *service) GetNews(ctx context.Context, req dto.NewsListGetRequest) ([]dt
 		"cache_key", redisKey,
 	)
 
+	switch {
+	case req.GroupDuplicates:
+		responses, err = s.listClusteredNews(ctx, req)
+	case req.Cursor != "":
+		responses, err = s.listNewsBeforeCursor(ctx, req, cursorPublishedAt, cursorID)
+	default:
+		responses, err = s.listNews(ctx, req, sort)
+	}
+	if err != nil {
+		return dto.NewsListGetResult{}, err
+	}
+
+	// Cache the result for future requests; empty pages only briefly, with a marker that older
+	// empty entries don't match
+	emptyTTL := time.Duration(config.GetConfig().Cache.EmptyTTL) * time.Second
+	switch {
+	case len(responses) > 0:
+		err = s.redis.SetWithExpiredTime(ctx, redisKey, responses, cacheTTL(redisKey))
+	case emptyTTL > 0:
+		err = s.redis.SetEmpty(ctx, redisKey, emptyTTL)
+	}
+	if err != nil {
+		slog.Warn("Failed to cache news data",
+			"cache_key", redisKey,
+			"items_count", len(responses),
+			"error_code", "CACHE_SET_FAILED",
+			"error", err,
+		)
+		// Don't fail the request if caching fails
+	} else {
+		slog.Debug("Successfully cached news data",
+			"cache_key", redisKey,
+			"items_count", len(responses),
+		)
+	}
+
+	return finish(responses), nil
+}
+
+// QueryNews is GetNews for GET /news
+func (s *service) QueryNews(ctx context.Context, req dto.NewsListQueryRequest) (dto.NewsListGetResult, error) {
+	return s.GetNews(ctx, dto.NewsListGetRequest(req))
+}
+
+// listNewsBeforeCursor returns the page of news that follows the cursor's news
+func (s *service) listNewsBeforeCursor(ctx context.Context, req dto.NewsListGetRequest, publishedAt time.Time, id int64) ([]dto.NewsListGetResponse, error) {
+	news, err := s.repo.NewsRepository.GetNewsBeforeCursor(ctx, onefeed_th_sqlc.ListNewsBeforeCursorParams{
+		Sources:     req.Source,
+		PublishDate: converter.TimeToPGTypeTimestamp(publishedAt),
+		ID:          id,
+		PageLimit:   req.Limit,
+	})
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve news from database").
+			WithCode("DB_QUERY_FAILED").
+			WithDetails(fmt.Sprintf("sources: %v, limit: %d", req.Source, req.Limit)).
+			WithCaller()
+	}
+
+	responses := make([]dto.NewsListGetResponse, 0, len(news))
+	for _, item := range news {
+		responses = append(responses, toNewsListGetResponse(item))
+	}
+	return responses, nil
+}
+
+// listNews returns a page of news from the database
+func (s *service) listNews(ctx context.Context, req dto.NewsListGetRequest, sort repository.NewsSort) ([]dto.NewsListGetResponse, error) {
 	news, err := s.repo.NewsRepository.GetNews(ctx, onefeed_th_sqlc.ListNewsParams{
 		Sources:    req.Source,
 		PageOffset: (req.Page - 1) * req.Limit,
 		PageLimit:  req.Limit,
-	})
+	}, sort)
 	if err != nil {
 		slog.Error("Database query failed",
 			"sources", req.Source,
@@ -91,55 +254,579 @@ func (s *service) GetNews(ctx context.Context, req dto.NewsListGetRequest) ([]dt
 	}
 
 	// Build response from database data
-	responses = make([]dto.NewsListGetResponse, 0, len(news))
+	responses := make([]dto.NewsListGetResponse, 0, len(news))
 	for _, item := range news {
-		responses = append(responses, dto.NewsListGetResponse{
-			Title:       item.Title,
-			Source:      item.Source,
-			PublishedAt: converter.PGTypeTimestampToTime(item.PublishDate),
-			Link:        item.Link,
-			Image:       item.ImageUrl.String,
-		})
+		responses = append(responses, toNewsListGetResponse(item))
+	}
+	return responses, nil
+}
+
+// rankNewsListResult personalizes the page when asked to and the reader is known, and counts
+// it for the ranking metrics. Anonymous pages stay chronological and aren't counted.
+func (s *service) rankNewsListResult(ctx context.Context, req dto.NewsListGetRequest, result dto.NewsListGetResult) dto.NewsListGetResult {
+	userID, ok := auth.UserIDFromContext(ctx)
+	if !ok {
+		return result
+	}
+
+	result.Ranking = rankingChronological
+	if req.Personalization {
+		if err := s.rankNews(ctx, result.Items); err != nil {
+			slog.Warn("Failed to personalize news, returning chronological order",
+				"user_id", userID,
+				"error", err,
+			)
+		} else {
+			result.Ranking = rankingPersonalized
+		}
+	}
+	s.recordRankingImpressions(ctx, result.Ranking, len(result.Items))
+	return result
+}
+
+// buildNewsListResult attaches pagination metadata and the reader's read state to a page of
+// news and counts its impressions per source. A failed count only drops the totals; the page
+// itself is still returned.
+func (s *service) buildNewsListResult(ctx context.Context, req dto.NewsListGetRequest, items []dto.NewsListGetResponse) dto.NewsListGetResult {
+	s.setReadState(ctx, items)
+	s.recordSourceImpressions(ctx, items)
+	result := dto.NewsListGetResult{
+		Items: items,
+		Page:  req.Page,
+		Limit: req.Limit,
 	}
 
-	// Cache the result for future requests
-	err = s.redis.Set(ctx, redisKey, responses)
+	count := s.countNews
+	if req.GroupDuplicates {
+		count = s.countClusteredNews
+	}
+	total, err := count(ctx, req.Source)
 	if err != nil {
-		slog.Warn("Failed to cache news data",
+		slog.Warn("Failed to count news, returning page without totals",
+			"sources", req.Source,
+			"error", err,
+		)
+		return result
+	}
+
+	result.TotalItems = total
+	result.TotalPages = (total + int64(req.Limit) - 1) / int64(req.Limit)
+	return result
+}
+
+// countNews returns the number of stored news for the sources, cached alongside the pages
+func (s *service) countNews(ctx context.Context, sources []string) (int64, error) {
+	return loadCached(ctx, s.redis, fmt.Sprintf("news:count:source=%v", sources), func() (int64, error) {
+		return s.repo.NewsRepository.CountNews(ctx, sources)
+	})
+}
+
+// getNewsGroupedBySource returns the latest items of every requested source in a single query
+func (s *service) getNewsGroupedBySource(ctx context.Context, req dto.NewsListGetRequest) (dto.NewsListGetResult, error) {
+	if req.PerSource <= 0 {
+		req.PerSource = defaultNewsPerSource
+	}
+	if req.PerSource > maxNewsPerSource {
+		req.PerSource = maxNewsPerSource
+	}
+
+	// each source's items are cached on their own, so requests for overlapping sets of sources
+	// share them and only the sources missing from the cache are queried
+	groups := make(map[string][]dto.NewsListGetResponse, len(req.Source))
+	keys := make([]string, len(req.Source))
+	dests := make([]any, len(req.Source))
+	items := make([][]dto.NewsListGetResponse, len(req.Source))
+	for i, source := range req.Source {
+		keys[i] = fmt.Sprintf("news:grouped:source=%s:perSource=%d", source, req.PerSource)
+		dests[i] = &items[i]
+	}
+
+	var missing []string
+	for i, err := range s.redis.GetMany(ctx, keys, dests) {
+		if err == nil {
+			groups[req.Source[i]] = items[i]
+			continue
+		}
+		if !errors.Is(err, redis.Nil) {
+			slog.Warn("Cache retrieval failed, continuing with database query",
+				"cache_key", keys[i],
+				"error_code", "CACHE_GET_FAILED",
+				"error", err,
+			)
+		}
+		missing = append(missing, req.Source[i])
+	}
+	if len(missing) == 0 {
+		slog.Info("Cache hit",
+			"sources_count", len(req.Source),
+		)
+		for _, items := range groups {
+			s.setReadState(ctx, items)
+		}
+		return dto.NewsListGetResult{Groups: groups}, nil
+	}
+
+	news, err := s.repo.NewsRepository.GetLatestNewsPerSource(ctx, onefeed_th_sqlc.ListLatestNewsPerSourceParams{
+		Sources:   missing,
+		PerSource: req.PerSource,
+	})
+	if err != nil {
+		slog.Error("Database query failed",
+			"sources", missing,
+			"per_source", req.PerSource,
+			"error", err,
+		)
+		return dto.NewsListGetResult{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve news from database").
+			WithCode("DB_QUERY_FAILED").
+			WithDetails(fmt.Sprintf("sources: %v, perSource: %d", missing, req.PerSource)).
+			WithCaller()
+	}
+
+	// Every requested source gets a key, even when it has no items yet
+	loaded := make(map[string][]dto.NewsListGetResponse, len(missing))
+	for _, source := range missing {
+		loaded[source] = []dto.NewsListGetResponse{}
+	}
+	for _, item := range news {
+		loaded[item.Source] = append(loaded[item.Source], toNewsListGetResponse(item))
+	}
+
+	values := make(map[string]any, len(loaded))
+	for source, items := range loaded {
+		groups[source] = items
+		values[fmt.Sprintf("news:grouped:source=%s:perSource=%d", source, req.PerSource)] = items
+	}
+	if err := s.redis.SetMany(ctx, values, cacheTTL("news:grouped")); err != nil {
+		slog.Warn("Failed to cache grouped news data",
+			"sources", missing,
+			"error_code", "CACHE_SET_FAILED",
+			"error", err,
+		)
+	}
+
+	for _, items := range groups {
+		s.setReadState(ctx, items)
+	}
+	return dto.NewsListGetResult{Groups: groups}, nil
+}
+
+func (s *service) GetNewsDetail(ctx context.Context, req dto.NewsDetailGetRequest) (dto.NewsDetailGetResponse, error) {
+	var response dto.NewsDetailGetResponse
+	redisKey := fmt.Sprintf("news:detail:id=%d", req.ID)
+
+	err := s.redis.Get(ctx, redisKey, &response)
+	if err == nil {
+		slog.Info("Cache hit",
+			"cache_key", redisKey,
+		)
+		return response, nil
+	}
+	if !errors.Is(err, redis.Nil) {
+		slog.Warn("Cache retrieval failed, continuing with database query",
+			"cache_key", redisKey,
+			"error_code", "CACHE_GET_FAILED",
+			"error", err,
+		)
+	}
+
+	news, err := s.repo.NewsRepository.GetNewsByID(ctx, req.ID)
+	// Hidden items are reported as missing so moderated articles are not reachable by id
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && news.Hidden) {
+		return dto.NewsDetailGetResponse{}, apperrors.Newf(apperrors.NotFoundError, "news %d not found", req.ID).
+			WithCode("NEWS_NOT_FOUND").
+			WithCaller()
+	}
+	if err != nil {
+		slog.Error("Database query failed",
+			"id", req.ID,
+			"error", err,
+		)
+		return dto.NewsDetailGetResponse{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve news from database").
+			WithCode("DB_QUERY_FAILED").
+			WithDetails(fmt.Sprintf("id: %d", req.ID)).
+			WithCaller()
+	}
+
+	related, err := s.repo.NewsRepository.GetRelatedNewsBySource(ctx, onefeed_th_sqlc.ListRelatedNewsBySourceParams{
+		Source:    news.Source,
+		ID:        news.ID,
+		PageLimit: relatedNewsLimit,
+	})
+	if err != nil {
+		// Related items are supplementary, so the detail is still returned without them
+		slog.Warn("Failed to retrieve related news",
+			"id", req.ID,
+			"source", news.Source,
+			"error", err,
+		)
+	}
+
+	response = dto.NewsDetailGetResponse{
+		ID:          news.ID,
+		Title:       news.Title,
+		Source:      news.Source,
+		PublishedAt: converter.PGTypeTimestampToTime(news.PublishDate),
+		FetchedAt:   converter.PGTypeTimestampToTime(news.FetchedAt),
+		Image:       news.ImageUrl.String,
+		Link:        news.Link,
+		Summary:     converter.PGTypeTextToString(news.Summary),
+		Related:     make([]dto.NewsListGetResponse, 0, len(related)),
+	}
+	for _, item := range related {
+		response.Related = append(response.Related, toNewsListGetResponse(item))
+	}
+
+	if err := s.redis.SetWithExpiredTime(ctx, redisKey, response, cacheTTL(redisKey)); err != nil {
+		slog.Warn("Failed to cache news detail",
 			"cache_key", redisKey,
-			"items_count", len(responses),
 			"error_code", "CACHE_SET_FAILED",
 			"error", err,
 		)
-		// Don't fail the request if caching fails
-	} else {
-		slog.Debug("Successfully cached news data",
+	}
+
+	return response, nil
+}
+
+func (s *service) GetNewsByIDs(ctx context.Context, req dto.NewsBatchGetRequest) ([]dto.NewsListGetResponse, error) {
+	// Drop duplicates so the limit applies to distinct articles
+	seen := make(map[int64]struct{}, len(req.IDs))
+	ids := make([]int64, 0, len(req.IDs))
+	for _, id := range req.IDs {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		ids = append(ids, id)
+	}
+
+	if len(ids) > maxBatchNewsIDs {
+		return nil, apperrors.Newf(apperrors.ValidationError, "at most %d ids are allowed", maxBatchNewsIDs).
+			WithCode("TOO_MANY_IDS").
+			WithCaller()
+	}
+
+	// articles are cached one by one, so only those missing from the cache are queried
+	keys := make([]string, len(ids))
+	dests := make([]any, len(ids))
+	cached := make([]dto.NewsListGetResponse, len(ids))
+	for i, id := range ids {
+		keys[i] = fmt.Sprintf("news:item:id=%d", id)
+		dests[i] = &cached[i]
+	}
+
+	responses := make([]dto.NewsListGetResponse, 0, len(ids))
+	var missing []int64
+	for i, err := range s.redis.GetMany(ctx, keys, dests) {
+		if err == nil {
+			responses = append(responses, cached[i])
+			continue
+		}
+		if !errors.Is(err, redis.Nil) {
+			slog.Warn("Cache retrieval failed, continuing with database query",
+				"cache_key", keys[i],
+				"error_code", "CACHE_GET_FAILED",
+				"error", err,
+			)
+		}
+		missing = append(missing, ids[i])
+	}
+
+	if len(missing) > 0 {
+		news, err := s.repo.NewsRepository.GetNewsByIDs(ctx, missing)
+		if err != nil {
+			slog.Error("Database query failed",
+				"ids_count", len(missing),
+				"error", err,
+			)
+			return nil, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve news from database").
+				WithCode("DB_QUERY_FAILED").
+				WithDetails(fmt.Sprintf("ids_count: %d", len(missing))).
+				WithCaller()
+		}
+
+		values := make(map[string]any, len(news))
+		for _, item := range news {
+			response := toNewsListGetResponse(item)
+			responses = append(responses, response)
+			values[fmt.Sprintf("news:item:id=%d", item.ID)] = response
+		}
+		if err := s.redis.SetMany(ctx, values, cacheTTL("news:item")); err != nil {
+			slog.Warn("Failed to cache news items",
+				"ids_count", len(values),
+				"error_code", "CACHE_SET_FAILED",
+				"error", err,
+			)
+		}
+	}
+
+	// latest first, as the query orders them
+	sort.SliceStable(responses, func(i, j int) bool {
+		return responses[i].PublishedAt.After(responses[j].PublishedAt)
+	})
+	s.setReadState(ctx, responses)
+	return responses, nil
+}
+
+func (s *service) GetRelatedNews(ctx context.Context, req dto.NewsRelatedGetRequest) ([]dto.NewsListGetResponse, error) {
+	var responses []dto.NewsListGetResponse
+	redisKey := fmt.Sprintf("news:related:id=%d", req.ID)
+
+	err := s.redis.Get(ctx, redisKey, &responses)
+	if err == nil {
+		slog.Info("Cache hit",
 			"cache_key", redisKey,
 			"items_count", len(responses),
 		)
+		s.setReadState(ctx, responses)
+		return responses, nil
+	}
+	if !errors.Is(err, redis.Nil) {
+		slog.Warn("Cache retrieval failed, continuing with database query",
+			"cache_key", redisKey,
+			"error_code", "CACHE_GET_FAILED",
+			"error", err,
+		)
 	}
 
+	news, err := s.repo.NewsRepository.GetNewsByID(ctx, req.ID)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && news.Hidden) {
+		return nil, apperrors.Newf(apperrors.NotFoundError, "news %d not found", req.ID).
+			WithCode("NEWS_NOT_FOUND").
+			WithCaller()
+	}
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve news from database").
+			WithCode("DB_QUERY_FAILED").
+			WithDetails(fmt.Sprintf("id: %d", req.ID)).
+			WithCaller()
+	}
+
+	// Search around the article's own publish time so old articles still find their peers
+	anchor := converter.PGTypeTimestampToTime(news.PublishDate)
+	if anchor.IsZero() {
+		anchor = converter.PGTypeTimestampToTime(news.FetchedAt)
+	}
+
+	similar, err := s.repo.NewsRepository.GetSimilarNews(ctx, onefeed_th_sqlc.ListSimilarNewsParams{
+		ID:            news.ID,
+		WindowStart:   converter.TimeToPGTypeTimestamp(anchor.Add(-similarNewsWindow)),
+		WindowEnd:     converter.TimeToPGTypeTimestamp(anchor.Add(similarNewsWindow)),
+		Title:         news.Title,
+		MinSimilarity: similarNewsMinSimilarity,
+		PageLimit:     similarNewsLimit,
+	})
+	if err != nil {
+		slog.Error("Database query failed",
+			"id", req.ID,
+			"error", err,
+		)
+		return nil, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve related news from database").
+			WithCode("DB_QUERY_FAILED").
+			WithDetails(fmt.Sprintf("id: %d", req.ID)).
+			WithCaller()
+	}
+
+	responses = make([]dto.NewsListGetResponse, 0, len(similar))
+	for _, item := range similar {
+		responses = append(responses, toNewsListGetResponse(item))
+	}
+
+	if err := s.redis.SetWithExpiredTime(ctx, redisKey, responses, cacheTTL(redisKey)); err != nil {
+		slog.Warn("Failed to cache related news",
+			"cache_key", redisKey,
+			"items_count", len(responses),
+			"error_code", "CACHE_SET_FAILED",
+			"error", err,
+		)
+	}
+
+	s.setReadState(ctx, responses)
 	return responses, nil
 }
 
+func (s *service) DiscoverNews(ctx context.Context, req dto.NewsDiscoverGetRequest) ([]dto.NewsListGetResponse, error) {
+	if req.Limit <= 0 || req.Limit > 50 {
+		req.Limit = defaultDiscoverLimit
+	}
+	if req.Exclude == nil {
+		req.Exclude = []string{}
+	}
+
+	news, err := s.repo.NewsRepository.GetRandomRecentNews(ctx, onefeed_th_sqlc.ListRandomRecentNewsParams{
+		Since:          converter.TimeToPGTypeTimestamp(time.Now().Add(-discoverWindow)),
+		ExcludeSources: req.Exclude,
+		PageLimit:      req.Limit,
+	})
+	if err != nil {
+		slog.Error("Database query failed",
+			"exclude", req.Exclude,
+			"limit", req.Limit,
+			"error", err,
+		)
+		return nil, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve news from database").
+			WithCode("DB_QUERY_FAILED").
+			WithCaller()
+	}
+
+	responses := make([]dto.NewsListGetResponse, 0, len(news))
+	for _, item := range news {
+		responses = append(responses, toNewsListGetResponse(item))
+	}
+	s.setReadState(ctx, responses)
+	return responses, nil
+}
+
+func toNewsListGetResponse(item onefeed_th_sqlc.News) dto.NewsListGetResponse {
+	return dto.NewsListGetResponse{
+		ID:          item.ID,
+		Title:       item.Title,
+		Source:      item.Source,
+		PublishedAt: converter.PGTypeTimestampToTime(item.PublishDate),
+		Link:        item.Link,
+		Image:       item.ImageUrl.String,
+		Summary:     converter.PGTypeTextToString(item.Summary),
+	}
+}
+
 func (s *service) RemoveOldNews(ctx context.Context, req dto.BlankRequest) (any, error) {
+	lock, err := s.lockJob(ctx, "retention", retentionLockTTL)
+	if err != nil {
+		return nil, err
+	}
+	defer s.unlockJob(ctx, lock)
+
 	slog.Info("Starting old news removal",
-		"retention_days", 30,
+		"retention_days", newsRetentionDays,
 	)
 
-	err := s.repo.NewsRepository.RemoveNewsByPublishedDate(ctx)
+	// Partitions are never created on the insert path; until they are, rows wait in news_default
+	if err := s.repo.NewsRepository.EnsureNewsPartitions(ctx, time.Now().AddDate(0, newsPartitionMonthsAhead, 0)); err != nil {
+		slog.Warn("Failed to create news partitions, new rows stay in news_default",
+			"error", err,
+		)
+	}
+
+	// Expired news are moved into the monthly archive partitions rather than dropped.
+	// Whole news partitions are dropped, so a month is archived once all of it is past retention
+	before := newsRetentionCutoff(time.Now())
+	archived, err := s.repo.NewsArchiveRepository.ArchiveNewsPublishedBefore(ctx, before)
 	if err != nil {
-		slog.Error("Failed to remove old news",
-			"retention_days", 30,
+		slog.Error("Failed to archive old news",
+			"retention_days", newsRetentionDays,
 			"error", err,
 		)
-		return nil, apperrors.Wrap(err, apperrors.DatabaseError, "failed to remove old news").
-			WithCode("DB_DELETE_FAILED").
+		return nil, apperrors.Wrap(err, apperrors.DatabaseError, "failed to archive old news").
+			WithCode("DB_ARCHIVE_FAILED").
 			WithCaller()
 	}
 
-	slog.Info("Successfully removed old news",
-		"retention_days", 30,
+	var removed int64
+	if months := config.GetConfig().Retention.ArchiveMonths; months > 0 {
+		removed, err = s.repo.NewsArchiveRepository.RemoveArchivedNewsBefore(ctx, time.Now().AddDate(0, -months, 0))
+		if err != nil {
+			slog.Error("Failed to remove expired archived news",
+				"archive_months", months,
+				"error", err,
+			)
+			return nil, apperrors.Wrap(err, apperrors.DatabaseError, "failed to remove expired archived news").
+				WithCode("DB_DELETE_FAILED").
+				WithCaller()
+		}
+	}
+
+	// cached pages, details and related lists may still hold the news that moved or went away;
+	// they are dropped here rather than left to the cache-warm job queued below
+	if err := s.redis.RemoveKeyContaining(ctx, "news"); err != nil {
+		slog.Warn("Failed to remove news cache keys", "error", err)
+	}
+	s.notifyNewsChanged(ctx, newsChangeRetention)
+	s.removeExpiredSearchDocuments(ctx, before)
+	s.removeExpiredNewsClusters(ctx, before)
+	s.removeFinishedJobs(ctx)
+
+	slog.Info("Successfully archived old news",
+		"retention_days", newsRetentionDays,
+		"archived_count", archived,
+		"removed_archived_count", removed,
 	)
-	return nil, nil
+	return dto.RetentionRunResponse{ArchivedCount: archived, RemovedArchivedCount: removed}, nil
+}
+
+func (s *service) GetArchivedNews(ctx context.Context, req dto.NewsArchiveGetRequest) (dto.NewsListGetResult, error) {
+	month, err := time.Parse(archiveMonthLayout, req.Month)
+	if err != nil {
+		return dto.NewsListGetResult{}, apperrors.Wrap(err, apperrors.ValidationError, "month must be YYYY-MM").
+			WithCode("INVALID_MONTH").
+			WithCaller()
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Limit <= 0 || req.Limit > 100 {
+		req.Limit = 20
+	}
+
+	var responses []dto.NewsListGetResponse
+	redisKey := fmt.Sprintf("news:archive:source=%v:month=%s:page=%d:limit=%d", req.Source, req.Month, req.Page, req.Limit)
+
+	err = s.redis.Get(ctx, redisKey, &responses)
+	if err == nil {
+		slog.Info("Cache hit",
+			"cache_key", redisKey,
+			"items_count", len(responses),
+		)
+		s.setReadState(ctx, responses)
+		return dto.NewsListGetResult{Items: responses, Page: req.Page, Limit: req.Limit}, nil
+	}
+	if !errors.Is(err, redis.Nil) {
+		slog.Warn("Cache retrieval failed, continuing with database query",
+			"cache_key", redisKey,
+			"error_code", "CACHE_GET_FAILED",
+			"error", err,
+		)
+	}
+
+	archived, err := s.repo.NewsArchiveRepository.GetArchivedNews(ctx, onefeed_th_sqlc.ListArchivedNewsParams{
+		Sources:    req.Source,
+		FromDate:   converter.TimeToPGTypeTimestamp(month),
+		ToDate:     converter.TimeToPGTypeTimestamp(month.AddDate(0, 1, 0)),
+		PageOffset: (req.Page - 1) * req.Limit,
+		PageLimit:  req.Limit,
+	})
+	if err != nil {
+		slog.Error("Database query failed",
+			"sources", req.Source,
+			"month", req.Month,
+			"error", err,
+		)
+		return dto.NewsListGetResult{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve archived news from database").
+			WithCode("DB_QUERY_FAILED").
+			WithDetails(fmt.Sprintf("sources: %v, month: %s", req.Source, req.Month)).
+			WithCaller()
+	}
+
+	responses = make([]dto.NewsListGetResponse, 0, len(archived))
+	for _, item := range archived {
+		responses = append(responses, dto.NewsListGetResponse{
+			ID:          item.ID,
+			Title:       item.Title,
+			Source:      item.Source,
+			PublishedAt: converter.PGTypeTimestampToTime(item.PublishDate),
+			Image:       converter.PGTypeTextToString(item.ImageUrl),
+			Link:        item.Link,
+			Summary:     converter.PGTypeTextToString(item.Summary),
+		})
+	}
+
+	// Archived months never change apart from retention runs, which clear these keys
+	if err := s.redis.SetWithExpiredTime(ctx, redisKey, responses, cacheTTL(redisKey)); err != nil {
+		slog.Warn("Failed to cache archived news",
+			"cache_key", redisKey,
+			"error_code", "CACHE_SET_FAILED",
+			"error", err,
+		)
+	}
+
+	s.setReadState(ctx, responses)
+	return dto.NewsListGetResult{Items: responses, Page: req.Page, Limit: req.Limit}, nil
 }
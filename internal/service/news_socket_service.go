@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/onefeed-th/onefeed-th-backend-api/config"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/dto"
+	apperrors "github.com/onefeed-th/onefeed-th-backend-api/internal/errors"
+)
+
+type NewsSocketService interface {
+	ConnectNewsSocket(ctx context.Context, req dto.BlankRequest) (*NewsSocket, error)
+}
+
+// NewsSocket is a WebSocket connection that pushes newly collected news matching the
+// filter the client subscribed with
+type NewsSocket struct {
+	service *service
+	cfg     config.Config
+}
+
+// newsSocketCommand carries a parsed client message from the reading goroutine to the
+// writing one, which owns every write to the connection
+type newsSocketCommand struct {
+	sources []string // nil when the message didn't change the filter
+	all     bool
+	reply   dto.NewsSocketEvent
+}
+
+func (s *service) ConnectNewsSocket(ctx context.Context, req dto.BlankRequest) (*NewsSocket, error) {
+	cfg := *config.GetConfig()
+	// the slot is released when the connection ends in Upgrade
+	if n := s.newsSockets.Add(1); cfg.WebSocket.MaxConnections > 0 && n > int64(cfg.WebSocket.MaxConnections) {
+		s.newsSockets.Add(-1)
+		return nil, apperrors.New(apperrors.UnavailableError, "too many WebSocket connections").
+			WithCode("TOO_MANY_CONNECTIONS")
+	}
+
+	return &NewsSocket{service: s, cfg: cfg}, nil
+}
+
+func (n *NewsSocket) Upgrade(w http.ResponseWriter, r *http.Request) error {
+	defer n.service.newsSockets.Add(-1)
+
+	upgrader := websocket.Upgrader{CheckOrigin: n.checkOrigin}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// the upgrader has already answered the client
+		return err
+	}
+	defer conn.Close()
+
+	sub := n.service.newsStream.Subscribe(n.cfg.Stream.BufferSize)
+	if sub == nil {
+		return closeNewsSocket(conn, websocket.CloseGoingAway, "server is shutting down")
+	}
+	defer sub.Close()
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	pingInterval := time.Duration(n.cfg.WebSocket.PingInterval) * time.Second
+	writeTimeout := time.Duration(n.cfg.WebSocket.WriteTimeout) * time.Second
+	conn.SetReadLimit(n.cfg.WebSocket.MaxMessageBytes)
+	if pingInterval > 0 {
+		conn.SetReadDeadline(time.Now().Add(2 * pingInterval))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(2 * pingInterval))
+		})
+	}
+
+	commands := make(chan newsSocketCommand)
+	readDone := make(chan error, 1)
+	go func() {
+		readDone <- n.read(ctx, conn, commands)
+	}()
+
+	write := func(event dto.NewsSocketEvent) error {
+		if writeTimeout > 0 {
+			conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+		}
+		return conn.WriteJSON(event)
+	}
+
+	var ping <-chan time.Time
+	if pingInterval > 0 {
+		ticker := time.NewTicker(pingInterval)
+		defer ticker.Stop()
+		ping = ticker.C
+	}
+
+	// nothing is sent until the client subscribes
+	var (
+		subscribed bool
+		all        bool
+		sources    []string
+		dropped    int64
+	)
+	for {
+		select {
+		case err := <-readDone:
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway, websocket.CloseNoStatusReceived) {
+				return err
+			}
+			return nil
+		case cmd := <-commands:
+			if cmd.reply.Type == "subscribed" {
+				subscribed, all, sources = true, cmd.all, cmd.sources
+			}
+			if err := write(cmd.reply); err != nil {
+				return err
+			}
+		case <-ping:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeTimeout)); err != nil {
+				return err
+			}
+		case item, ok := <-sub.C:
+			if !ok {
+				// the hub closed on shutdown
+				return closeNewsSocket(conn, websocket.CloseGoingAway, "server is shutting down")
+			}
+			// tell the client it missed events so it can catch up with POST /news
+			if missed := sub.Dropped(); missed > dropped {
+				if err := write(dto.NewsSocketEvent{Type: "lagged", Dropped: missed - dropped}); err != nil {
+					return err
+				}
+				dropped = missed
+			}
+			if !subscribed || (!all && !slices.Contains(sources, item.Source)) {
+				continue
+			}
+			if err := write(dto.NewsSocketEvent{Type: "news", Data: &item}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// read handles client messages until the connection fails or closes
+func (n *NewsSocket) read(ctx context.Context, conn *websocket.Conn, commands chan<- newsSocketCommand) error {
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+
+		cmd := n.command(ctx, data)
+		select {
+		case commands <- cmd:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// command turns a client message into the filter change and reply for the writer
+func (n *NewsSocket) command(ctx context.Context, data []byte) newsSocketCommand {
+	var msg dto.NewsSocketMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return newsSocketError(apperrors.Wrap(err, apperrors.ParseError, "message is not valid JSON").
+			WithCode("INVALID_MESSAGE"))
+	}
+	if msg.Type != "subscribe" {
+		return newsSocketError(apperrors.Newf(apperrors.ValidationError, "unknown message type %q", msg.Type).
+			WithCode("INVALID_MESSAGE"))
+	}
+	if limit := n.cfg.WebSocket.MaxFilterSize; limit > 0 && len(msg.Sources)+len(msg.Tags) > limit {
+		return newsSocketError(apperrors.Newf(apperrors.ValidationError, "a subscription may list at most %d sources and tags", limit).
+			WithCode("FILTER_TOO_LARGE"))
+	}
+
+	if len(msg.Sources) == 0 && len(msg.Tags) == 0 {
+		// no filter also covers sources added after subscribing
+		return newsSocketCommand{all: true, reply: dto.NewsSocketEvent{Type: "subscribed"}}
+	}
+	sources, err := n.service.resolveFeedSources(ctx, dto.FeedGetRequest{Source: msg.Sources, Tag: msg.Tags})
+	if err != nil {
+		slog.Error("Failed to resolve WebSocket subscription", "error", err)
+		return newsSocketError(err)
+	}
+	return newsSocketCommand{
+		sources: sources,
+		reply:   dto.NewsSocketEvent{Type: "subscribed", Sources: sources},
+	}
+}
+
+// checkOrigin accepts clients without an Origin header, the API's own host and the
+// configured origins
+func (n *NewsSocket) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if u, err := url.Parse(origin); err == nil && u.Host == r.Host {
+		return true
+	}
+	return slices.Contains(n.cfg.WebSocket.AllowedOrigins, "*") ||
+		slices.Contains(n.cfg.WebSocket.AllowedOrigins, origin)
+}
+
+func newsSocketError(err error) newsSocketCommand {
+	response := &dto.ErrorResponse{
+		Type:    string(apperrors.InternalError),
+		Message: err.Error(),
+	}
+	var appErr *apperrors.AppError
+	if errors.As(err, &appErr) {
+		response = &dto.ErrorResponse{
+			Type:    string(appErr.Type),
+			Code:    appErr.Code,
+			Message: appErr.Message,
+		}
+	}
+	return newsSocketCommand{reply: dto.NewsSocketEvent{Type: "error", Error: response}}
+}
+
+func closeNewsSocket(conn *websocket.Conn, code int, reason string) error {
+	return conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(time.Second))
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/onefeed-th/onefeed-th-backend-api/config"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/stream"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/dto"
+	apperrors "github.com/onefeed-th/onefeed-th-backend-api/internal/errors"
+	onefeed_th_sqlc "github.com/onefeed-th/onefeed-th-backend-api/internal/sqlc/onefeed_th_sqlc/db"
+)
+
+type NewsStreamService interface {
+	StreamNews(ctx context.Context, req dto.NewsStreamRequest) (*NewsEventStream, error)
+	HandleNewsCreated(ctx context.Context, payload string)
+	CloseNewsStreams()
+}
+
+// newsCreatedBatchSize keeps each news_created payload well below the 8000 byte NOTIFY limit
+const newsCreatedBatchSize = 500
+
+// publishCreatedNews announces newly inserted news to the stream subscribers of every
+// instance. The ids are sent through Postgres NOTIFY; the memory driver has no other
+// instances, so it hands them to its own subscribers directly
+func (s *service) publishCreatedNews(ctx context.Context, news []onefeed_th_sqlc.News) {
+	ids := make([]int64, 0, len(news))
+	for _, item := range news {
+		ids = append(ids, item.ID)
+	}
+	for batch := range slices.Chunk(ids, newsCreatedBatchSize) {
+		payload, err := json.Marshal(batch)
+		if err != nil {
+			slog.Warn("Failed to encode created news ids", "error", err)
+			return
+		}
+
+		if config.GetConfig().Storage.Driver == config.StorageDriverMemory {
+			s.HandleNewsCreated(ctx, string(payload))
+			continue
+		}
+		if err := s.repo.NewsRepository.NotifyNewsCreated(ctx, string(payload)); err != nil {
+			slog.Warn("Failed to publish news created notification", "error", err)
+		}
+	}
+}
+
+// HandleNewsCreated reacts to a news_created notification by pushing the new items to
+// every stream connected to this instance
+func (s *service) HandleNewsCreated(ctx context.Context, payload string) {
+	var ids []int64
+	if err := json.Unmarshal([]byte(payload), &ids); err != nil {
+		slog.Warn("Ignoring malformed news created notification", "payload", payload, "error", err)
+		return
+	}
+	if s.newsStream.Count() == 0 {
+		return
+	}
+
+	news, err := s.repo.NewsRepository.GetNewsByIDs(ctx, ids)
+	if err != nil {
+		slog.Warn("Failed to load created news for streaming", "error", err)
+		return
+	}
+	// oldest first, so clients can prepend events as they arrive
+	for i := len(news) - 1; i >= 0; i-- {
+		s.newsStream.Publish(toNewsListGetResponse(news[i]))
+	}
+}
+
+// CloseNewsStreams ends every open stream, e.g. on shutdown
+func (s *service) CloseNewsStreams() {
+	s.newsStream.Close()
+}
+
+// NewsEventStream is a Server-Sent Events response that writes a "news" event for every
+// newly collected item until the client disconnects
+type NewsEventStream struct {
+	ctx       context.Context
+	sub       *stream.Subscription[dto.NewsListGetResponse]
+	sources   []string
+	keepAlive time.Duration
+}
+
+func (s *service) StreamNews(ctx context.Context, req dto.NewsStreamRequest) (*NewsEventStream, error) {
+	cfg := config.GetConfig().Stream
+	sub := s.newsStream.Subscribe(cfg.BufferSize)
+	if sub == nil {
+		return nil, apperrors.New(apperrors.UnavailableError, "server is shutting down").
+			WithCode("STREAM_CLOSED")
+	}
+
+	return &NewsEventStream{
+		ctx:       ctx,
+		sub:       sub,
+		sources:   req.Source,
+		keepAlive: time.Duration(cfg.KeepAlive) * time.Second,
+	}, nil
+}
+
+func (e *NewsEventStream) Render(w http.ResponseWriter) error {
+	defer e.sub.Close()
+
+	controller := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	// stop nginx-style proxies from buffering the stream
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	// an initial comment lets the client know the stream is open
+	if _, err := fmt.Fprint(w, ": connected\n\n"); err != nil {
+		return err
+	}
+	if err := controller.Flush(); err != nil {
+		return err
+	}
+
+	var keepAlive <-chan time.Time
+	if e.keepAlive > 0 {
+		ticker := time.NewTicker(e.keepAlive)
+		defer ticker.Stop()
+		keepAlive = ticker.C
+	}
+
+	for {
+		select {
+		case <-e.ctx.Done():
+			return nil
+		case <-keepAlive:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return err
+			}
+		case item, ok := <-e.sub.C:
+			if !ok {
+				// the hub closed on shutdown
+				return nil
+			}
+			if len(e.sources) > 0 && !slices.Contains(e.sources, item.Source) {
+				continue
+			}
+			data, err := json.Marshal(item)
+			if err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintf(w, "id: %d\nevent: news\ndata: %s\n\n", item.ID, data); err != nil {
+				return err
+			}
+		}
+		if err := controller.Flush(); err != nil {
+			return err
+		}
+	}
+}
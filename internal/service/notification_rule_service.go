@@ -0,0 +1,313 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/onefeed-th/onefeed-th-backend-api/config"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/notify"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/utils/converter"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/dto"
+	apperrors "github.com/onefeed-th/onefeed-th-backend-api/internal/errors"
+	onefeed_th_sqlc "github.com/onefeed-th/onefeed-th-backend-api/internal/sqlc/onefeed_th_sqlc/db"
+)
+
+type NotificationRuleService interface {
+	CreateNotificationRule(ctx context.Context, req dto.NotificationRuleCreateRequest) (dto.NotificationRuleResponse, error)
+	GetNotificationRules(ctx context.Context, req dto.BlankRequest) ([]dto.NotificationRuleResponse, error)
+	UpdateNotificationRule(ctx context.Context, req dto.NotificationRuleUpdateRequest) (dto.NotificationRuleResponse, error)
+	DeleteNotificationRule(ctx context.Context, req dto.NotificationRuleDeleteRequest) (any, error)
+}
+
+func (s *service) CreateNotificationRule(ctx context.Context, req dto.NotificationRuleCreateRequest) (dto.NotificationRuleResponse, error) {
+	if err := validateNotificationTarget(req.Channel, req.Target); err != nil {
+		return dto.NotificationRuleResponse{}, err
+	}
+	if err := validateNotificationTemplate(req.Template); err != nil {
+		return dto.NotificationRuleResponse{}, err
+	}
+
+	rule, err := s.repo.NotificationRuleRepository.CreateNotificationRule(ctx, onefeed_th_sqlc.CreateNotificationRuleParams{
+		Name:     req.Name,
+		Channel:  req.Channel,
+		Target:   req.Target,
+		Keywords: nonNilStrings(req.Keywords),
+		Sources:  nonNilStrings(req.Sources),
+		Tags:     nonNilStrings(req.Tags),
+		Template: req.Template,
+	})
+	if err != nil {
+		return dto.NotificationRuleResponse{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to store notification rule").
+			WithCode("DB_INSERT_FAILED").
+			WithCaller()
+	}
+
+	slog.Info("Notification rule created",
+		"id", rule.ID,
+		"name", rule.Name,
+		"channel", rule.Channel,
+		"actor", actorFromContext(ctx),
+	)
+	return toNotificationRuleResponse(rule), nil
+}
+
+func (s *service) GetNotificationRules(ctx context.Context, req dto.BlankRequest) ([]dto.NotificationRuleResponse, error) {
+	rules, err := s.repo.NotificationRuleRepository.GetNotificationRules(ctx)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve notification rules from database").
+			WithCode("DB_QUERY_FAILED").
+			WithCaller()
+	}
+
+	responses := make([]dto.NotificationRuleResponse, 0, len(rules))
+	for _, rule := range rules {
+		responses = append(responses, toNotificationRuleResponse(rule))
+	}
+	return responses, nil
+}
+
+func (s *service) UpdateNotificationRule(ctx context.Context, req dto.NotificationRuleUpdateRequest) (dto.NotificationRuleResponse, error) {
+	rule, err := s.repo.NotificationRuleRepository.GetNotificationRuleByID(ctx, req.ID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return dto.NotificationRuleResponse{}, apperrors.Newf(apperrors.NotFoundError, "notification rule %d not found", req.ID).
+			WithCode("NOTIFICATION_RULE_NOT_FOUND")
+	}
+	if err != nil {
+		return dto.NotificationRuleResponse{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve notification rule").
+			WithCode("DB_QUERY_FAILED").
+			WithCaller()
+	}
+
+	params := onefeed_th_sqlc.UpdateNotificationRuleParams{
+		Name:     rule.Name,
+		Target:   rule.Target,
+		Keywords: rule.Keywords,
+		Sources:  rule.Sources,
+		Tags:     rule.Tags,
+		Template: rule.Template,
+		Disabled: rule.Disabled,
+		ID:       rule.ID,
+	}
+	if req.Name != nil {
+		params.Name = *req.Name
+	}
+	if req.Target != nil {
+		if err := validateNotificationTarget(rule.Channel, *req.Target); err != nil {
+			return dto.NotificationRuleResponse{}, err
+		}
+		params.Target = *req.Target
+	}
+	if req.Keywords != nil {
+		params.Keywords = nonNilStrings(*req.Keywords)
+	}
+	if req.Sources != nil {
+		params.Sources = nonNilStrings(*req.Sources)
+	}
+	if req.Tags != nil {
+		params.Tags = nonNilStrings(*req.Tags)
+	}
+	if req.Template != nil {
+		if err := validateNotificationTemplate(*req.Template); err != nil {
+			return dto.NotificationRuleResponse{}, err
+		}
+		params.Template = *req.Template
+	}
+	if req.Disabled != nil {
+		params.Disabled = *req.Disabled
+	}
+
+	rule, err = s.repo.NotificationRuleRepository.UpdateNotificationRule(ctx, params)
+	if err != nil {
+		return dto.NotificationRuleResponse{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to update notification rule").
+			WithCode("DB_UPDATE_FAILED").
+			WithCaller()
+	}
+
+	slog.Info("Notification rule updated",
+		"id", rule.ID,
+		"name", rule.Name,
+		"disabled", rule.Disabled,
+		"actor", actorFromContext(ctx),
+	)
+	return toNotificationRuleResponse(rule), nil
+}
+
+func (s *service) DeleteNotificationRule(ctx context.Context, req dto.NotificationRuleDeleteRequest) (any, error) {
+	affected, err := s.repo.NotificationRuleRepository.DeleteNotificationRule(ctx, req.ID)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.DatabaseError, "failed to delete notification rule").
+			WithCode("DB_DELETE_FAILED").
+			WithDetails(fmt.Sprintf("id: %d", req.ID)).
+			WithCaller()
+	}
+	if affected == 0 {
+		return nil, apperrors.Newf(apperrors.NotFoundError, "notification rule %d not found", req.ID).
+			WithCode("NOTIFICATION_RULE_NOT_FOUND")
+	}
+
+	slog.Info("Notification rule deleted",
+		"id", req.ID,
+		"actor", actorFromContext(ctx),
+	)
+	return nil, nil
+}
+
+// dispatchNotifications pushes newly created news to every enabled rule that matches,
+// at most notify.maxItemsPerRule items per rule. Like webhooks it runs in the background,
+// and each rule is sent on its own since senders wait out their rate limits
+func (s *service) dispatchNotifications(ctx context.Context, news []onefeed_th_sqlc.News) {
+	rules, err := s.repo.NotificationRuleRepository.GetEnabledNotificationRules(ctx)
+	if err != nil {
+		slog.Error("Failed to load notification rules", "error", err)
+		return
+	}
+	if len(rules) == 0 {
+		return
+	}
+
+	// tag filters and the {{.Tags}} template field use the tags of an item's source
+	sources, err := s.cachedSources(ctx)
+	if err != nil {
+		slog.Error("Failed to load sources for notification rules", "error", err)
+		return
+	}
+	sourceTags := make(map[string]string, len(sources))
+	for _, source := range sources {
+		sourceTags[source.Name] = converter.PGTypeTextToString(source.Tags)
+	}
+
+	limit := max(config.GetConfig().Notify.MaxItemsPerRule, 1)
+	for _, rule := range rules {
+		sender, ok := s.notifiers[rule.Channel]
+		if !ok {
+			slog.Warn("Notification rule has an unknown channel", "rule_id", rule.ID, "channel", rule.Channel)
+			continue
+		}
+		tmpl, err := notify.ParseTemplate(rule.Template)
+		if err != nil {
+			slog.Warn("Notification rule has an invalid template", "rule_id", rule.ID, "error", err)
+			continue
+		}
+
+		messages := make([]string, 0, limit)
+		for _, item := range news {
+			if len(messages) == limit {
+				break
+			}
+			if !notificationRuleMatches(rule, item, sourceTags[item.Source]) {
+				continue
+			}
+			message, err := notify.Render(tmpl, notify.Item{
+				Title:       item.Title,
+				Link:        item.Link,
+				Source:      item.Source,
+				Tags:        sourceTags[item.Source],
+				PublishedAt: converter.PGTypeTimestampToTime(item.PublishDate),
+			})
+			if err != nil {
+				slog.Warn("Failed to render notification", "rule_id", rule.ID, "news_id", item.ID, "error", err)
+				continue
+			}
+			messages = append(messages, message)
+		}
+		if len(messages) == 0 {
+			continue
+		}
+
+		go func() {
+			if err := sender.Send(ctx, rule.Target, messages); err != nil {
+				slog.Warn("Notification delivery failed",
+					"rule_id", rule.ID,
+					"channel", rule.Channel,
+					"news", len(messages),
+					"error", err,
+				)
+				return
+			}
+			slog.Debug("Notification delivered", "rule_id", rule.ID, "news", len(messages))
+		}()
+	}
+}
+
+// notificationRuleMatches reports whether item passes the rule's filters: it must come from
+// one of the sources or carry one of the tags, and have one of the keywords in its title
+// (case-insensitive). Empty filters match everything
+func notificationRuleMatches(rule onefeed_th_sqlc.NotificationRule, item onefeed_th_sqlc.News, sourceTags string) bool {
+	if (len(rule.Sources) > 0 || len(rule.Tags) > 0) &&
+		!slices.Contains(rule.Sources, item.Source) &&
+		!(len(rule.Tags) > 0 && sourceHasAnyTag(sourceTags, rule.Tags)) {
+		return false
+	}
+	if len(rule.Keywords) == 0 {
+		return true
+	}
+	title := strings.ToLower(item.Title)
+	return slices.ContainsFunc(rule.Keywords, func(keyword string) bool {
+		return strings.Contains(title, strings.ToLower(keyword))
+	})
+}
+
+// validateNotificationTarget checks the target against what the channel expects; only LINE
+// Messaging may leave it empty, which broadcasts
+func validateNotificationTarget(channel, target string) error {
+	switch channel {
+	case notify.ChannelLineNotify, notify.ChannelTelegram:
+		if target == "" {
+			return apperrors.Newf(apperrors.ValidationError, "target is required for %s rules", channel).
+				WithCode("NOTIFICATION_TARGET_REQUIRED")
+		}
+	case notify.ChannelDiscord, notify.ChannelWebhook:
+		if u, err := url.Parse(target); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return apperrors.Newf(apperrors.ValidationError, "target must be a webhook URL for %s rules", channel).
+				WithCode("NOTIFICATION_TARGET_INVALID")
+		}
+	}
+	return nil
+}
+
+func validateNotificationTemplate(template string) error {
+	if _, err := notify.ParseTemplate(template); err != nil {
+		return apperrors.New(apperrors.ValidationError, "template is not a valid message template").
+			WithCode("NOTIFICATION_TEMPLATE_INVALID").
+			WithDetails(err.Error())
+	}
+	return nil
+}
+
+func toNotificationRuleResponse(rule onefeed_th_sqlc.NotificationRule) dto.NotificationRuleResponse {
+	target := rule.Target
+	if rule.Channel == notify.ChannelLineNotify || rule.Channel == notify.ChannelDiscord || rule.Channel == notify.ChannelWebhook {
+		target = maskSecret(target)
+	}
+	response := dto.NotificationRuleResponse{
+		ID:        rule.ID,
+		Name:      rule.Name,
+		Channel:   rule.Channel,
+		Target:    target,
+		Keywords:  nonNilStrings(rule.Keywords),
+		Sources:   nonNilStrings(rule.Sources),
+		Tags:      nonNilStrings(rule.Tags),
+		Template:  rule.Template,
+		Disabled:  rule.Disabled,
+		CreatedAt: converter.PGTypeTimestampToTime(rule.CreatedAt),
+	}
+	if rule.UpdatedAt.Valid {
+		updatedAt := rule.UpdatedAt.Time
+		response.UpdatedAt = &updatedAt
+	}
+	return response
+}
+
+// maskSecret keeps only the last four characters so admins can tell tokens apart
+func maskSecret(secret string) string {
+	if len(secret) <= 4 {
+		return strings.Repeat("*", len(secret))
+	}
+	return "****" + secret[len(secret)-4:]
+}
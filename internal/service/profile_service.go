@@ -0,0 +1,254 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"regexp"
+	"slices"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/auth"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/utils/converter"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/dto"
+	apperrors "github.com/onefeed-th/onefeed-th-backend-api/internal/errors"
+	onefeed_th_sqlc "github.com/onefeed-th/onefeed-th-backend-api/internal/sqlc/onefeed_th_sqlc/db"
+)
+
+// ProfileService holds what readers keep with or without an account. Readers without one
+// are identified by the X-Device-ID their app sends and get a lightweight device profile
+type ProfileService interface {
+	AuthenticateDevice(ctx context.Context, deviceID string) (int64, error)
+	GetPreferences(ctx context.Context, req dto.BlankRequest) (dto.UserPreferencesResponse, error)
+	UpdatePreferences(ctx context.Context, req dto.UserPreferencesUpdateRequest) (dto.UserPreferencesResponse, error)
+	GetBookmarks(ctx context.Context, req dto.BookmarkListRequest) (dto.BookmarkListResult, error)
+	AddBookmark(ctx context.Context, req dto.BookmarkCreateRequest) (any, error)
+	RemoveBookmark(ctx context.Context, req dto.BookmarkDeleteRequest) (any, error)
+}
+
+const defaultBookmarkLimit = 20
+
+// deviceIDPattern accepts UUIDs and the install ids of the mobile SDKs; short ids are refused
+// because anyone who knows a device id can read its profile
+var deviceIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{16,128}$`)
+
+// AuthenticateDevice resolves an X-Device-ID header to its profile, creating it on first use
+func (s *service) AuthenticateDevice(ctx context.Context, deviceID string) (int64, error) {
+	if !deviceIDPattern.MatchString(deviceID) {
+		return 0, apperrors.Newf(apperrors.ValidationError, "%s must be 16-128 letters, digits, '-' or '_'", auth.DeviceIDHeader).
+			WithCode("INVALID_DEVICE_ID")
+	}
+
+	user, err := s.repo.UserRepository.GetUserByDeviceID(ctx, deviceID)
+	if err == nil {
+		return user.ID, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return 0, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve device profile").
+			WithCode("DB_QUERY_FAILED").
+			WithCaller()
+	}
+
+	user, err = s.repo.UserRepository.CreateDeviceUser(ctx, deviceID)
+	if err != nil {
+		return 0, apperrors.Wrap(err, apperrors.DatabaseError, "failed to store device profile").
+			WithCode("DB_INSERT_FAILED").
+			WithCaller()
+	}
+	slog.Info("Device profile created", "user_id", user.ID)
+	return user.ID, nil
+}
+
+func (s *service) GetPreferences(ctx context.Context, req dto.BlankRequest) (dto.UserPreferencesResponse, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return dto.UserPreferencesResponse{}, err
+	}
+
+	pref, err := s.repo.UserPreferenceRepository.GetUserPreferences(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return dto.UserPreferencesResponse{Sources: []string{}, Tags: []string{}}, nil
+	}
+	if err != nil {
+		return dto.UserPreferencesResponse{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve preferences").
+			WithCode("DB_QUERY_FAILED").
+			WithCaller()
+	}
+	return toUserPreferencesResponse(pref), nil
+}
+
+func (s *service) UpdatePreferences(ctx context.Context, req dto.UserPreferencesUpdateRequest) (dto.UserPreferencesResponse, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return dto.UserPreferencesResponse{}, err
+	}
+
+	pref, err := s.repo.UserPreferenceRepository.UpsertUserPreferences(ctx, onefeed_th_sqlc.UpsertUserPreferencesParams{
+		UserID:  userID,
+		Sources: dedupeStrings(req.Sources),
+		Tags:    dedupeStrings(req.Tags),
+	})
+	if err != nil {
+		return dto.UserPreferencesResponse{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to store preferences").
+			WithCode("DB_UPDATE_FAILED").
+			WithCaller()
+	}
+	return toUserPreferencesResponse(pref), nil
+}
+
+func (s *service) GetBookmarks(ctx context.Context, req dto.BookmarkListRequest) (dto.BookmarkListResult, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return dto.BookmarkListResult{}, err
+	}
+	if req.Limit <= 0 {
+		req.Limit = defaultBookmarkLimit
+	}
+
+	params := onefeed_th_sqlc.ListBookmarksParams{
+		UserID:    userID,
+		PageLimit: req.Limit,
+	}
+	if req.Cursor != "" {
+		createdAt, newsID, err := decodeKeysetCursor(req.Cursor)
+		if err != nil {
+			return dto.BookmarkListResult{}, apperrors.Wrap(err, apperrors.ValidationError, "invalid cursor").
+				WithCode("INVALID_CURSOR").
+				WithCaller()
+		}
+		params.BeforeCreatedAt = converter.TimeToPGTypeTimestamp(createdAt)
+		params.BeforeNewsID = newsID
+	}
+
+	bookmarks, err := s.repo.BookmarkRepository.GetBookmarks(ctx, params)
+	if err != nil {
+		return dto.BookmarkListResult{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve bookmarks").
+			WithCode("DB_QUERY_FAILED").
+			WithCaller()
+	}
+
+	ids := make([]int64, 0, len(bookmarks))
+	for _, bookmark := range bookmarks {
+		ids = append(ids, bookmark.NewsID)
+	}
+	byID, err := s.newsByIDs(ctx, ids)
+	if err != nil {
+		return dto.BookmarkListResult{}, err
+	}
+
+	result := dto.BookmarkListResult{Items: make([]dto.BookmarkResponse, 0, len(bookmarks))}
+	for _, bookmark := range bookmarks {
+		response := dto.BookmarkResponse{
+			NewsID:    bookmark.NewsID,
+			CreatedAt: converter.PGTypeTimestampToTime(bookmark.CreatedAt),
+		}
+		if item, ok := byID[bookmark.NewsID]; ok {
+			listItem := toNewsListGetResponse(item)
+			response.News = &listItem
+		}
+		result.Items = append(result.Items, response)
+	}
+	if len(bookmarks) == int(req.Limit) {
+		last := bookmarks[len(bookmarks)-1]
+		result.NextCursor = encodeKeysetCursor(last.CreatedAt.Time, last.NewsID)
+	}
+	return result, nil
+}
+
+// AddBookmark saves an article; saving it again keeps the original bookmark
+func (s *service) AddBookmark(ctx context.Context, req dto.BookmarkCreateRequest) (any, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	news, err := s.repo.NewsRepository.GetNewsByID(ctx, req.NewsID)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && news.Hidden) {
+		return nil, apperrors.Newf(apperrors.NotFoundError, "news %d not found", req.NewsID).
+			WithCode("NEWS_NOT_FOUND")
+	}
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve news from database").
+			WithCode("DB_QUERY_FAILED").
+			WithCaller()
+	}
+
+	err = s.repo.BookmarkRepository.CreateBookmark(ctx, onefeed_th_sqlc.CreateBookmarkParams{
+		UserID: userID,
+		NewsID: req.NewsID,
+	})
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.DatabaseError, "failed to store bookmark").
+			WithCode("DB_INSERT_FAILED").
+			WithCaller()
+	}
+	return nil, nil
+}
+
+func (s *service) RemoveBookmark(ctx context.Context, req dto.BookmarkDeleteRequest) (any, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	affected, err := s.repo.BookmarkRepository.DeleteBookmark(ctx, onefeed_th_sqlc.DeleteBookmarkParams{
+		UserID: userID,
+		NewsID: req.NewsID,
+	})
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.DatabaseError, "failed to delete bookmark").
+			WithCode("DB_DELETE_FAILED").
+			WithCaller()
+	}
+	if affected == 0 {
+		return nil, apperrors.Newf(apperrors.NotFoundError, "news %d is not bookmarked", req.NewsID).
+			WithCode("BOOKMARK_NOT_FOUND")
+	}
+	return nil, nil
+}
+
+// mergeDeviceProfile moves the profile of the device a reader signs in from into their
+// account. It is best effort: after a failure the device profile stays as it was and is
+// merged on the next sign-in
+func (s *service) mergeDeviceProfile(ctx context.Context, userID int64) {
+	deviceID, ok := auth.DeviceIDFromContext(ctx)
+	if !ok || !deviceIDPattern.MatchString(deviceID) {
+		return
+	}
+
+	device, err := s.repo.UserRepository.GetUserByDeviceID(ctx, deviceID)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && device.ID == userID) {
+		return
+	}
+	if err == nil {
+		err = s.repo.UserRepository.MergeUsers(ctx, device.ID, userID)
+	}
+	if err != nil {
+		slog.Warn("Failed to merge device profile into account", "user_id", userID, "error", err)
+		return
+	}
+	slog.Info("Device profile merged into account", "device_user_id", device.ID, "user_id", userID)
+}
+
+func toUserPreferencesResponse(pref onefeed_th_sqlc.UserPreference) dto.UserPreferencesResponse {
+	response := dto.UserPreferencesResponse{
+		Sources: nonNilStrings(pref.Sources),
+		Tags:    nonNilStrings(pref.Tags),
+	}
+	if pref.UpdatedAt.Valid {
+		updatedAt := pref.UpdatedAt.Time
+		response.UpdatedAt = &updatedAt
+	}
+	return response
+}
+
+// dedupeStrings drops repeated values, keeping the first occurrence of each
+func dedupeStrings(values []string) []string {
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if !slices.Contains(result, value) {
+			result = append(result, value)
+		}
+	}
+	return result
+}
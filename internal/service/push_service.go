@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/onefeed-th/onefeed-th-backend-api/config"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/fcm"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/utils/converter"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/dto"
+	apperrors "github.com/onefeed-th/onefeed-th-backend-api/internal/errors"
+	onefeed_th_sqlc "github.com/onefeed-th/onefeed-th-backend-api/internal/sqlc/onefeed_th_sqlc/db"
+)
+
+type PushService interface {
+	RegisterPushDevice(ctx context.Context, req dto.PushDeviceRegisterRequest) (dto.PushDeviceResponse, error)
+	UnregisterPushDevice(ctx context.Context, req dto.PushDeviceUnregisterRequest) (any, error)
+	RunPushWorker(ctx context.Context)
+}
+
+const (
+	// pushJobLease is how long a claimed job stays with one worker before another may retry it
+	pushJobLease = 5 * time.Minute
+	// pushConcurrency bounds the FCM requests a worker has in flight
+	pushConcurrency = 10
+	// pushJobsPerInsert bounds the rows written by one CreatePushJobs call
+	pushJobsPerInsert = 1000
+)
+
+func (s *service) RegisterPushDevice(ctx context.Context, req dto.PushDeviceRegisterRequest) (dto.PushDeviceResponse, error) {
+	device, err := s.repo.PushRepository.UpsertPushDevice(ctx, onefeed_th_sqlc.UpsertPushDeviceParams{
+		Token:    req.Token,
+		Platform: req.Platform,
+		Sources:  nonNilStrings(req.Sources),
+		Keywords: nonNilStrings(req.Keywords),
+	})
+	if err != nil {
+		return dto.PushDeviceResponse{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to store push device").
+			WithCode("DB_INSERT_FAILED").
+			WithCaller()
+	}
+
+	response := dto.PushDeviceResponse{
+		Platform:  device.Platform,
+		Sources:   nonNilStrings(device.Sources),
+		Keywords:  nonNilStrings(device.Keywords),
+		CreatedAt: converter.PGTypeTimestampToTime(device.CreatedAt),
+	}
+	if device.UpdatedAt.Valid {
+		updatedAt := device.UpdatedAt.Time
+		response.UpdatedAt = &updatedAt
+	}
+	return response, nil
+}
+
+func (s *service) UnregisterPushDevice(ctx context.Context, req dto.PushDeviceUnregisterRequest) (any, error) {
+	affected, err := s.repo.PushRepository.DeletePushDeviceByToken(ctx, req.Token)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.DatabaseError, "failed to delete push device").
+			WithCode("DB_DELETE_FAILED").
+			WithCaller()
+	}
+	if affected == 0 {
+		return nil, apperrors.New(apperrors.NotFoundError, "push device not found").
+			WithCode("PUSH_DEVICE_NOT_FOUND")
+	}
+	return nil, nil
+}
+
+// enqueuePushJobs queues a push for every device that matches newly created news, at most
+// fcm.maxItemsPerDevice per device. Nothing is queued while FCM isn't configured
+func (s *service) enqueuePushJobs(ctx context.Context, news []onefeed_th_sqlc.News) {
+	if s.push == nil {
+		return
+	}
+
+	devices, err := s.repo.PushRepository.GetPushDevices(ctx)
+	if err != nil {
+		slog.Error("Failed to load push devices", "error", err)
+		return
+	}
+
+	limit := max(config.GetConfig().FCM.MaxItemsPerDevice, 1)
+	var deviceIDs, newsIDs []int64
+	for _, device := range devices {
+		matched := 0
+		for _, item := range news {
+			if matched == limit {
+				break
+			}
+			if pushDeviceMatches(device, item) {
+				deviceIDs = append(deviceIDs, device.ID)
+				newsIDs = append(newsIDs, item.ID)
+				matched++
+			}
+		}
+	}
+
+	for start := 0; start < len(deviceIDs); start += pushJobsPerInsert {
+		end := min(start+pushJobsPerInsert, len(deviceIDs))
+		err := s.repo.PushRepository.CreatePushJobs(ctx, onefeed_th_sqlc.CreatePushJobsParams{
+			DeviceIds: deviceIDs[start:end],
+			NewsIds:   newsIDs[start:end],
+		})
+		if err != nil {
+			slog.Error("Failed to enqueue push jobs", "error", err)
+			return
+		}
+	}
+	if len(deviceIDs) > 0 {
+		slog.Info("Push jobs enqueued", "jobs", len(deviceIDs), "devices", len(devices))
+	}
+}
+
+// pushDeviceMatches applies a device's source and keyword filters like notification rules do
+func pushDeviceMatches(device onefeed_th_sqlc.PushDevice, item onefeed_th_sqlc.News) bool {
+	if len(device.Sources) > 0 && !slices.Contains(device.Sources, item.Source) {
+		return false
+	}
+	if len(device.Keywords) == 0 {
+		return true
+	}
+	title := strings.ToLower(item.Title)
+	return slices.ContainsFunc(device.Keywords, func(keyword string) bool {
+		return strings.Contains(title, strings.ToLower(keyword))
+	})
+}
+
+// RunPushWorker sends queued push jobs until ctx is done. Every instance may run one; jobs
+// are claimed with a lease so each is sent by a single worker
+func (s *service) RunPushWorker(ctx context.Context) {
+	if s.push == nil {
+		return
+	}
+
+	cfg := config.GetConfig().FCM
+	ticker := time.NewTicker(time.Duration(max(cfg.PollInterval, 1)) * time.Second)
+	defer ticker.Stop()
+	for {
+		s.processPushJobs(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// processPushJobs claims and sends due jobs until a claim comes back short
+func (s *service) processPushJobs(ctx context.Context) {
+	batchSize := max(config.GetConfig().FCM.BatchSize, 1)
+	for ctx.Err() == nil {
+		now := time.Now()
+		jobs, err := s.repo.PushRepository.ClaimPushJobs(ctx, onefeed_th_sqlc.ClaimPushJobsParams{
+			LeaseUntil: converter.TimeToPGTypeTimestamp(now.Add(pushJobLease)),
+			Now:        converter.TimeToPGTypeTimestamp(now),
+			PageLimit:  int32(batchSize),
+		})
+		if err != nil {
+			slog.Error("Failed to claim push jobs", "error", err)
+			return
+		}
+
+		var wg sync.WaitGroup
+		slots := make(chan struct{}, pushConcurrency)
+		for _, job := range jobs {
+			slots <- struct{}{}
+			wg.Add(1)
+			go func() {
+				defer func() {
+					<-slots
+					wg.Done()
+				}()
+				s.sendPushJob(ctx, job)
+			}()
+		}
+		wg.Wait()
+
+		if len(jobs) < batchSize {
+			return
+		}
+	}
+}
+
+// sendPushJob sends one job and settles it: done jobs are removed, devices whose token
+// expired are forgotten and retryable failures wait out a doubling backoff
+func (s *service) sendPushJob(ctx context.Context, job onefeed_th_sqlc.ClaimPushJobsRow) {
+	cfg := config.GetConfig().FCM
+	err := s.push.Send(ctx, job.Token, fcm.Message{
+		Title: job.Source,
+		Body:  job.Title,
+		Data: map[string]string{
+			"newsId": strconv.FormatInt(job.NewsID, 10),
+			"link":   job.Link,
+			"source": job.Source,
+		},
+	})
+
+	var sendErr *fcm.SendError
+	switch {
+	case err == nil:
+		err = s.repo.PushRepository.DeletePushJob(ctx, job.ID)
+	case errors.Is(err, fcm.ErrUnregistered):
+		slog.Info("Removing push device with an expired token", "device_id", job.DeviceID)
+		err = s.repo.PushRepository.DeletePushDeviceByID(ctx, job.DeviceID)
+	case (!errors.As(err, &sendErr) || sendErr.Retryable()) && int(job.Attempts) < cfg.MaxAttempts:
+		backoff := time.Duration(cfg.Backoff) * time.Second << (job.Attempts - 1)
+		slog.Debug("Push failed, retrying", "job_id", job.ID, "attempts", job.Attempts, "error", err)
+		err = s.repo.PushRepository.RetryPushJob(ctx, onefeed_th_sqlc.RetryPushJobParams{
+			LastError: pgtype.Text{String: err.Error(), Valid: true},
+			RunAt:     converter.TimeToPGTypeTimestamp(time.Now().Add(backoff)),
+			ID:        job.ID,
+		})
+	default:
+		slog.Warn("Push failed", "job_id", job.ID, "device_id", job.DeviceID, "attempts", job.Attempts, "error", err)
+		err = s.repo.PushRepository.DeletePushJob(ctx, job.ID)
+	}
+	if err != nil {
+		slog.Error("Failed to settle push job", "job_id", job.ID, "error", err)
+	}
+}
@@ -0,0 +1,256 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/onefeed-th/onefeed-th-backend-api/config"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/auth"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/utils/converter"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/dto"
+	apperrors "github.com/onefeed-th/onefeed-th-backend-api/internal/errors"
+	onefeed_th_sqlc "github.com/onefeed-th/onefeed-th-backend-api/internal/sqlc/onefeed_th_sqlc/db"
+)
+
+type RankingService interface {
+	GetRankingMetrics(ctx context.Context, req dto.RankingMetricsGetRequest) ([]dto.RankingMetricsResponse, error)
+}
+
+const (
+	rankingPersonalized  = "personalized"
+	rankingChronological = "chronological"
+
+	// ranking metrics are counted in a Redis hash per day, fields are prefixed with the ranking
+	rankingMetricsKeyPrefix = "ranking:metrics:day="
+	rankingMetricsLayout    = "20060102"
+	rankingMetricsTTL       = 35 * 24 * time.Hour
+
+	defaultRankingMetricsDays = 7
+)
+
+// readerAffinity is what the ranking knows about a reader: the share of their recent reads per
+// source and tag, and the sources and tags they follow in their preferences
+type readerAffinity struct {
+	SourceShare     map[string]float64 `json:"sourceShare"`
+	TagShare        map[string]float64 `json:"tagShare"`
+	FollowedSources []string           `json:"followedSources"`
+	FollowedTags    []string           `json:"followedTags"`
+	SourceTags      map[string]string  `json:"sourceTags"`
+}
+
+// rankNews re-orders a page of news for the reader in ctx by
+// recency × (1 + source affinity) × (1 + topic affinity). Only the page is re-ordered, so
+// paging through a personalized list still visits every item exactly once.
+func (s *service) rankNews(ctx context.Context, items []dto.NewsListGetResponse) error {
+	userID, ok := auth.UserIDFromContext(ctx)
+	if !ok || len(items) == 0 {
+		return nil
+	}
+
+	affinity, err := s.readerAffinity(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	cfg := config.GetConfig().Personalization
+	halfLife := float64(cfg.RecencyHalfLife)
+	if halfLife <= 0 {
+		halfLife = 12
+	}
+	now := time.Now()
+
+	scores := make(map[int64]float64, len(items))
+	for _, item := range items {
+		age := math.Max(now.Sub(item.PublishedAt).Hours(), 0)
+		recency := math.Pow(0.5, age/halfLife)
+
+		source := 1 + cfg.HistoryWeight*affinity.SourceShare[item.Source]
+		if slices.Contains(affinity.FollowedSources, item.Source) {
+			source += cfg.FollowBoost
+		}
+
+		var tagShare float64
+		var followed bool
+		for _, tag := range strings.Split(affinity.SourceTags[item.Source], ",") {
+			tag = strings.ToLower(strings.TrimSpace(tag))
+			if tag == "" {
+				continue
+			}
+			tagShare = math.Max(tagShare, affinity.TagShare[tag])
+			followed = followed || slices.Contains(affinity.FollowedTags, tag)
+		}
+		topic := 1 + cfg.HistoryWeight*tagShare
+		if followed {
+			topic += cfg.FollowBoost
+		}
+
+		scores[item.ID] = recency * source * topic
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return scores[items[i].ID] > scores[items[j].ID]
+	})
+	return nil
+}
+
+// readerAffinity learns the reader's affinities from their preferences and latest reads. It is
+// cached for personalization.cacheTTL, so new reads and follows show up after at most that long;
+// 0 disables the cache.
+func (s *service) readerAffinity(ctx context.Context, userID int64) (readerAffinity, error) {
+	cfg := config.GetConfig().Personalization
+	var affinity readerAffinity
+	redisKey := fmt.Sprintf("personalization:user=%d", userID)
+	if cfg.CacheTTL > 0 {
+		if err := s.redis.Get(ctx, redisKey, &affinity); err == nil && affinity.SourceTags != nil {
+			return affinity, nil
+		}
+	}
+
+	affinity = readerAffinity{
+		SourceShare: make(map[string]float64),
+		TagShare:    make(map[string]float64),
+		SourceTags:  make(map[string]string),
+	}
+
+	sources, err := s.cachedSources(ctx)
+	if err != nil {
+		return readerAffinity{}, fmt.Errorf("failed to retrieve sources: %w", err)
+	}
+	for _, source := range sources {
+		affinity.SourceTags[source.Name] = converter.PGTypeTextToString(source.Tags)
+	}
+
+	pref, err := s.repo.UserPreferenceRepository.GetUserPreferences(ctx, userID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return readerAffinity{}, fmt.Errorf("failed to retrieve preferences: %w", err)
+	}
+	affinity.FollowedSources = pref.Sources
+	for _, tag := range pref.Tags {
+		affinity.FollowedTags = append(affinity.FollowedTags, strings.ToLower(tag))
+	}
+
+	counts, err := s.repo.NewsReadRepository.GetReadSourceCounts(ctx, onefeed_th_sqlc.ListReadSourceCountsParams{
+		UserID:    userID,
+		PageLimit: int32(cfg.HistorySize),
+	})
+	if err != nil {
+		return readerAffinity{}, fmt.Errorf("failed to retrieve read history: %w", err)
+	}
+	var reads int64
+	for _, row := range counts {
+		reads += row.Reads
+	}
+	for _, row := range counts {
+		share := float64(row.Reads) / float64(reads)
+		affinity.SourceShare[row.Source] = share
+		for _, tag := range strings.Split(affinity.SourceTags[row.Source], ",") {
+			tag = strings.ToLower(strings.TrimSpace(tag))
+			if tag != "" {
+				affinity.TagShare[tag] += share
+			}
+		}
+	}
+
+	if cfg.CacheTTL <= 0 {
+		return affinity, nil
+	}
+	if err := s.redis.SetWithExpiredTime(ctx, redisKey, affinity, time.Duration(cfg.CacheTTL)*time.Second); err != nil {
+		slog.Warn("Failed to cache reader affinity",
+			"cache_key", redisKey,
+			"error_code", "CACHE_SET_FAILED",
+			"error", err,
+		)
+	}
+	return affinity, nil
+}
+
+// recordRankingImpressions counts a served page of /news against its ranking, so the
+// click-through of personalized and chronological lists can be compared
+func (s *service) recordRankingImpressions(ctx context.Context, ranking string, items int) {
+	key := rankingMetricsKeyPrefix + time.Now().UTC().Format(rankingMetricsLayout)
+	for field, incr := range map[string]int64{
+		ranking + ":requests":    1,
+		ranking + ":impressions": int64(items),
+	} {
+		if err := s.redis.HashIncrBy(ctx, key, field, incr, rankingMetricsTTL); err != nil {
+			slog.Warn("Failed to record ranking metrics",
+				"key", key,
+				"field", field,
+				"error", err,
+			)
+			return
+		}
+	}
+}
+
+// recordRankingClick counts a click on a news list; the position is optional
+func (s *service) recordRankingClick(ctx context.Context, ranking string, position int32) {
+	key := rankingMetricsKeyPrefix + time.Now().UTC().Format(rankingMetricsLayout)
+	fields := map[string]int64{ranking + ":clicks": 1}
+	if position > 0 {
+		fields[ranking+":clickPositionSum"] = int64(position)
+		fields[ranking+":positionedClicks"] = 1
+	}
+	for field, incr := range fields {
+		if err := s.redis.HashIncrBy(ctx, key, field, incr, rankingMetricsTTL); err != nil {
+			slog.Warn("Failed to record ranking metrics",
+				"key", key,
+				"field", field,
+				"error", err,
+			)
+			return
+		}
+	}
+}
+
+// GetRankingMetrics reports per day and ranking how often /news was served and clicked
+func (s *service) GetRankingMetrics(ctx context.Context, req dto.RankingMetricsGetRequest) ([]dto.RankingMetricsResponse, error) {
+	if req.Days <= 0 {
+		req.Days = defaultRankingMetricsDays
+	}
+
+	today := time.Now().UTC()
+	responses := make([]dto.RankingMetricsResponse, 0, 2*req.Days)
+	for day := range converter.Int32ToInt(req.Days) {
+		date := today.AddDate(0, 0, -day)
+		key := rankingMetricsKeyPrefix + date.Format(rankingMetricsLayout)
+		fields, err := s.redis.HashGetAll(ctx, key)
+		if err != nil {
+			return nil, apperrors.Wrap(err, apperrors.RedisError, "failed to retrieve ranking metrics").
+				WithCode("CACHE_GET_FAILED").
+				WithDetails(fmt.Sprintf("key: %s", key)).
+				WithCaller()
+		}
+
+		for _, ranking := range []string{rankingPersonalized, rankingChronological} {
+			count := func(name string) int64 {
+				n, _ := strconv.ParseInt(fields[ranking+":"+name], 10, 64)
+				return n
+			}
+			metrics := dto.RankingMetricsResponse{
+				Date:        date.Format(time.DateOnly),
+				Ranking:     ranking,
+				Requests:    count("requests"),
+				Impressions: count("impressions"),
+				Clicks:      count("clicks"),
+			}
+			if metrics.Impressions > 0 {
+				metrics.CTR = float64(metrics.Clicks) / float64(metrics.Impressions)
+			}
+			if positioned := count("positionedClicks"); positioned > 0 {
+				metrics.AvgClickPosition = float64(count("clickPositionSum")) / float64(positioned)
+			}
+			responses = append(responses, metrics)
+		}
+	}
+	return responses, nil
+}
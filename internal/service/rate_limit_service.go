@@ -0,0 +1,266 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/onefeed-th/onefeed-th-backend-api/config"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/dto"
+	apperrors "github.com/onefeed-th/onefeed-th-backend-api/internal/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+// RateLimitService throttles public routes per reader and client IP, and lets admins review
+// who got throttled and lift blocks
+type RateLimitService interface {
+	CheckRateLimit(ctx context.Context, reader, ip string) (time.Duration, error)
+	GetRateLimitOffenders(ctx context.Context, req dto.RateLimitOffendersGetRequest) ([]dto.RateLimitOffenderResponse, error)
+	GetRateLimitBlocks(ctx context.Context, req dto.BlankRequest) ([]dto.RateLimitBlockResponse, error)
+	RemoveRateLimitBlock(ctx context.Context, req dto.RateLimitBlockDeleteRequest) (any, error)
+}
+
+const (
+	// requests are counted per subject in a key per minute; minutes that went over the limit
+	// are counted per hour towards a block, and limited requests per day for review
+	rateLimitRequestsKeyPrefix   = "ratelimit:requests:"
+	rateLimitViolationsKeyPrefix = "ratelimit:violations:"
+	rateLimitOffendersKeyPrefix  = "ratelimit:offenders:day="
+	rateLimitBlockKeyPrefix      = "ratelimit:block:"
+
+	rateLimitOffendersTTL     = 8 * 24 * time.Hour
+	defaultRateLimitOffenders = 100
+)
+
+// rateLimitBlock is stored under rateLimitBlockKeyPrefix+subject until it expires
+type rateLimitBlock struct {
+	Subject   string    `json:"subject"`
+	Reason    string    `json:"reason"`
+	BlockedAt time.Time `json:"blockedAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// CheckRateLimit counts a request against the reader (when known) and the client IP. It fails
+// open: when Redis is down requests go through unthrottled
+func (s *service) CheckRateLimit(ctx context.Context, reader, ip string) (time.Duration, error) {
+	cfg := config.GetConfig().RateLimit
+	subjects := make([]string, 0, 2)
+	limits := make([]int, 0, 2)
+	if reader != "" {
+		subjects = append(subjects, reader)
+		limits = append(limits, cfg.PerReader)
+	}
+	if ip != "" {
+		subjects = append(subjects, "ip:"+ip)
+		limits = append(limits, cfg.PerIP)
+	}
+
+	for _, subject := range subjects {
+		var block rateLimitBlock
+		err := s.redis.Get(ctx, rateLimitBlockKeyPrefix+subject, &block)
+		if err == nil {
+			return time.Until(block.ExpiresAt), apperrors.Newf(apperrors.ForbiddenError, "%s is blocked until %s", subject, block.ExpiresAt.Format(time.RFC3339)).
+				WithCode("CLIENT_BLOCKED")
+		}
+		if !errors.Is(err, redis.Nil) {
+			slog.Warn("Failed to check rate limit block, letting the request through",
+				"subject", subject,
+				"error", err,
+			)
+			return 0, nil
+		}
+	}
+
+	now := time.Now().UTC()
+	for i, subject := range subjects {
+		if limits[i] <= 0 {
+			continue
+		}
+		key := rateLimitRequestsKeyPrefix + subject + ":minute=" + now.Format("200601021504")
+		count, err := s.redis.IncrBy(ctx, key, 1, 2*time.Minute)
+		if err != nil {
+			slog.Warn("Failed to count request for rate limit, letting the request through",
+				"subject", subject,
+				"error", err,
+			)
+			return 0, nil
+		}
+		if count <= int64(limits[i]) {
+			continue
+		}
+
+		s.recordRateLimitViolation(ctx, subject, count == int64(limits[i])+1, now)
+		retryAfter := now.Truncate(time.Minute).Add(time.Minute).Sub(now)
+		return retryAfter, apperrors.Newf(apperrors.RateLimitedError, "more than %d requests per minute", limits[i]).
+			WithCode("RATE_LIMITED")
+	}
+	return 0, nil
+}
+
+// recordRateLimitViolation counts a limited request for review and, on the first one of a
+// minute, counts the minute towards a block
+func (s *service) recordRateLimitViolation(ctx context.Context, subject string, newMinute bool, now time.Time) {
+	cfg := config.GetConfig().RateLimit
+	offendersKey := rateLimitOffendersKeyPrefix + now.Format("20060102")
+	if err := s.redis.HashIncrBy(ctx, offendersKey, subject, 1, rateLimitOffendersTTL); err != nil {
+		slog.Warn("Failed to record rate limit offender", "subject", subject, "error", err)
+	}
+	if !newMinute || cfg.BlockThreshold <= 0 {
+		return
+	}
+
+	violationsKey := rateLimitViolationsKeyPrefix + subject + ":hour=" + now.Format("2006010215")
+	minutes, err := s.redis.IncrBy(ctx, violationsKey, 1, 2*time.Hour)
+	if err != nil {
+		slog.Warn("Failed to record rate limit violation", "subject", subject, "error", err)
+		return
+	}
+	if minutes < int64(cfg.BlockThreshold) {
+		return
+	}
+
+	duration := time.Duration(cfg.BlockDuration) * time.Minute
+	block := rateLimitBlock{
+		Subject:   subject,
+		Reason:    fmt.Sprintf("minutes over the rate limit this hour: %d", minutes),
+		BlockedAt: now,
+		ExpiresAt: now.Add(duration),
+	}
+	if err := s.redis.SetWithExpiredTime(ctx, rateLimitBlockKeyPrefix+subject, block, duration); err != nil {
+		slog.Warn("Failed to block client", "subject", subject, "error", err)
+		return
+	}
+	slog.Warn("Client blocked for exceeding the rate limit",
+		"subject", subject,
+		"minutes_over_limit", minutes,
+		"expires_at", block.ExpiresAt,
+	)
+}
+
+// GetRateLimitOffenders lists the subjects with the most limited requests over the last days
+func (s *service) GetRateLimitOffenders(ctx context.Context, req dto.RateLimitOffendersGetRequest) ([]dto.RateLimitOffenderResponse, error) {
+	if req.Days <= 0 {
+		req.Days = 1
+	}
+	if req.Limit <= 0 {
+		req.Limit = defaultRateLimitOffenders
+	}
+
+	limited := make(map[string]int64)
+	today := time.Now().UTC()
+	for day := range int(req.Days) {
+		key := rateLimitOffendersKeyPrefix + today.AddDate(0, 0, -day).Format("20060102")
+		fields, err := s.redis.HashGetAll(ctx, key)
+		if err != nil {
+			return nil, apperrors.Wrap(err, apperrors.RedisError, "failed to retrieve rate limit offenders").
+				WithCode("CACHE_GET_FAILED").
+				WithDetails(fmt.Sprintf("key: %s", key)).
+				WithCaller()
+		}
+		for subject, value := range fields {
+			count, _ := strconv.ParseInt(value, 10, 64)
+			limited[subject] += count
+		}
+	}
+
+	blocks, err := s.rateLimitBlocks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	blocked := make(map[string]bool, len(blocks))
+	for _, block := range blocks {
+		blocked[block.Subject] = true
+	}
+
+	responses := make([]dto.RateLimitOffenderResponse, 0, len(limited))
+	for subject, count := range limited {
+		responses = append(responses, dto.RateLimitOffenderResponse{
+			Subject:         subject,
+			LimitedRequests: count,
+			Blocked:         blocked[subject],
+		})
+	}
+	sort.Slice(responses, func(i, j int) bool {
+		if responses[i].LimitedRequests != responses[j].LimitedRequests {
+			return responses[i].LimitedRequests > responses[j].LimitedRequests
+		}
+		return responses[i].Subject < responses[j].Subject
+	})
+	if len(responses) > int(req.Limit) {
+		responses = responses[:req.Limit]
+	}
+	return responses, nil
+}
+
+func (s *service) GetRateLimitBlocks(ctx context.Context, req dto.BlankRequest) ([]dto.RateLimitBlockResponse, error) {
+	blocks, err := s.rateLimitBlocks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.RateLimitBlockResponse, 0, len(blocks))
+	for _, block := range blocks {
+		responses = append(responses, dto.RateLimitBlockResponse(block))
+	}
+	return responses, nil
+}
+
+// RemoveRateLimitBlock unblocks a subject and forgets the minutes it went over the limit this
+// hour, so it isn't blocked again by its next limited request
+func (s *service) RemoveRateLimitBlock(ctx context.Context, req dto.RateLimitBlockDeleteRequest) (any, error) {
+	var block rateLimitBlock
+	err := s.redis.Get(ctx, rateLimitBlockKeyPrefix+req.Subject, &block)
+	if errors.Is(err, redis.Nil) {
+		return nil, apperrors.Newf(apperrors.NotFoundError, "%s is not blocked", req.Subject).
+			WithCode("BLOCK_NOT_FOUND")
+	}
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.RedisError, "failed to retrieve block").
+			WithCode("CACHE_GET_FAILED").
+			WithCaller()
+	}
+
+	violations := rateLimitViolationsKeyPrefix + req.Subject + ":hour=" + time.Now().UTC().Format("2006010215")
+	if err := s.redis.Delete(ctx, rateLimitBlockKeyPrefix+req.Subject, violations); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.RedisError, "failed to remove block").
+			WithCode("CACHE_DELETE_FAILED").
+			WithCaller()
+	}
+	slog.Info("Client unblocked", "subject", req.Subject)
+	return nil, nil
+}
+
+// rateLimitBlocks returns the active blocks, latest first
+func (s *service) rateLimitBlocks(ctx context.Context) ([]rateLimitBlock, error) {
+	keys, err := s.redis.ScanKeys(ctx, rateLimitBlockKeyPrefix+"*")
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.RedisError, "failed to list blocks").
+			WithCode("CACHE_GET_FAILED").
+			WithCaller()
+	}
+
+	blocks := make([]rateLimitBlock, 0, len(keys))
+	for _, key := range keys {
+		var block rateLimitBlock
+		if err := s.redis.Get(ctx, key, &block); err != nil {
+			// expired between the scan and the read
+			if !errors.Is(err, redis.Nil) {
+				slog.Warn("Failed to read block", "key", key, "error", err)
+			}
+			continue
+		}
+		if block.Subject == "" {
+			block.Subject = strings.TrimPrefix(key, rateLimitBlockKeyPrefix)
+		}
+		blocks = append(blocks, block)
+	}
+	sort.Slice(blocks, func(i, j int) bool {
+		return blocks[i].BlockedAt.After(blocks[j].BlockedAt)
+	})
+	return blocks, nil
+}
@@ -0,0 +1,360 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/onefeed-th/onefeed-th-backend-api/config"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/fcm"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/notify"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/utils/converter"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/dto"
+	apperrors "github.com/onefeed-th/onefeed-th-backend-api/internal/errors"
+	onefeed_th_sqlc "github.com/onefeed-th/onefeed-th-backend-api/internal/sqlc/onefeed_th_sqlc/db"
+)
+
+// SavedSearchService keeps the searches of a reader, whose new matches the collector sends to
+// the reader's device or LINE account
+type SavedSearchService interface {
+	GetSavedSearches(ctx context.Context, req dto.BlankRequest) ([]dto.SavedSearchResponse, error)
+	CreateSavedSearch(ctx context.Context, req dto.SavedSearchCreateRequest) (dto.SavedSearchResponse, error)
+	UpdateSavedSearch(ctx context.Context, req dto.SavedSearchUpdateRequest) (dto.SavedSearchResponse, error)
+	DeleteSavedSearch(ctx context.Context, req dto.SavedSearchDeleteRequest) (any, error)
+}
+
+// lineUserIDPattern only accepts the id of a single LINE user, so a reader can't send their
+// matches to a group or, with an empty target, broadcast them to every friend of the account
+var lineUserIDPattern = regexp.MustCompile(`^U[0-9a-f]{32}$`)
+
+func (s *service) GetSavedSearches(ctx context.Context, req dto.BlankRequest) ([]dto.SavedSearchResponse, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	searches, err := s.repo.SavedSearchRepository.GetSavedSearches(ctx, userID)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve saved searches").
+			WithCode("DB_QUERY_FAILED").
+			WithCaller()
+	}
+
+	responses := make([]dto.SavedSearchResponse, 0, len(searches))
+	for _, search := range searches {
+		responses = append(responses, toSavedSearchResponse(search))
+	}
+	return responses, nil
+}
+
+// CreateSavedSearch saves a search, named after its query unless a name is sent
+func (s *service) CreateSavedSearch(ctx context.Context, req dto.SavedSearchCreateRequest) (dto.SavedSearchResponse, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return dto.SavedSearchResponse{}, err
+	}
+	query, err := savedSearchQuery(req.Query)
+	if err != nil {
+		return dto.SavedSearchResponse{}, err
+	}
+	if err := validateLineUserID(req.LineUserID); err != nil {
+		return dto.SavedSearchResponse{}, err
+	}
+
+	if limit := config.GetConfig().SavedSearch.MaxPerReader; limit > 0 {
+		count, err := s.repo.SavedSearchRepository.CountSavedSearches(ctx, userID)
+		if err != nil {
+			return dto.SavedSearchResponse{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to count saved searches").
+				WithCode("DB_QUERY_FAILED").
+				WithCaller()
+		}
+		if count >= int64(limit) {
+			return dto.SavedSearchResponse{}, apperrors.Newf(apperrors.RateLimitedError, "at most %d searches can be saved", limit).
+				WithCode("TOO_MANY_SAVED_SEARCHES")
+		}
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = query
+	}
+	search, err := s.repo.SavedSearchRepository.CreateSavedSearch(ctx, onefeed_th_sqlc.CreateSavedSearchParams{
+		UserID:     userID,
+		Name:       name,
+		Query:      query,
+		Sources:    nonNilStrings(req.Sources),
+		PushToken:  converter.StringToPGTypeTextNull(req.PushToken),
+		LineUserID: converter.StringToPGTypeTextNull(req.LineUserID),
+	})
+	if err != nil {
+		return dto.SavedSearchResponse{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to store saved search").
+			WithCode("DB_INSERT_FAILED").
+			WithCaller()
+	}
+
+	slog.Info("Search saved",
+		"id", search.ID,
+		"user_id", userID,
+		"query", search.Query,
+	)
+	return toSavedSearchResponse(search), nil
+}
+
+func (s *service) UpdateSavedSearch(ctx context.Context, req dto.SavedSearchUpdateRequest) (dto.SavedSearchResponse, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return dto.SavedSearchResponse{}, err
+	}
+
+	search, err := s.repo.SavedSearchRepository.GetSavedSearch(ctx, onefeed_th_sqlc.GetSavedSearchParams{
+		ID:     req.ID,
+		UserID: userID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return dto.SavedSearchResponse{}, apperrors.Newf(apperrors.NotFoundError, "saved search %d not found", req.ID).
+			WithCode("SAVED_SEARCH_NOT_FOUND")
+	}
+	if err != nil {
+		return dto.SavedSearchResponse{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve saved search").
+			WithCode("DB_QUERY_FAILED").
+			WithDetails(fmt.Sprintf("id: %d", req.ID)).
+			WithCaller()
+	}
+
+	params := onefeed_th_sqlc.UpdateSavedSearchParams{
+		Name:       search.Name,
+		Query:      search.Query,
+		Sources:    search.Sources,
+		PushToken:  search.PushToken,
+		LineUserID: search.LineUserID,
+		ID:         search.ID,
+		UserID:     userID,
+	}
+	if req.Name != nil {
+		params.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Query != nil {
+		if params.Query, err = savedSearchQuery(*req.Query); err != nil {
+			return dto.SavedSearchResponse{}, err
+		}
+	}
+	if params.Name == "" {
+		params.Name = params.Query
+	}
+	if req.Sources != nil {
+		params.Sources = nonNilStrings(*req.Sources)
+	}
+	if req.PushToken != nil {
+		params.PushToken = converter.StringToPGTypeTextNull(*req.PushToken)
+	}
+	if req.LineUserID != nil {
+		if err := validateLineUserID(*req.LineUserID); err != nil {
+			return dto.SavedSearchResponse{}, err
+		}
+		params.LineUserID = converter.StringToPGTypeTextNull(*req.LineUserID)
+	}
+
+	search, err = s.repo.SavedSearchRepository.UpdateSavedSearch(ctx, params)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return dto.SavedSearchResponse{}, apperrors.Newf(apperrors.NotFoundError, "saved search %d not found", req.ID).
+			WithCode("SAVED_SEARCH_NOT_FOUND")
+	}
+	if err != nil {
+		return dto.SavedSearchResponse{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to update saved search").
+			WithCode("DB_UPDATE_FAILED").
+			WithDetails(fmt.Sprintf("id: %d", req.ID)).
+			WithCaller()
+	}
+	return toSavedSearchResponse(search), nil
+}
+
+func (s *service) DeleteSavedSearch(ctx context.Context, req dto.SavedSearchDeleteRequest) (any, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	affected, err := s.repo.SavedSearchRepository.DeleteSavedSearch(ctx, onefeed_th_sqlc.DeleteSavedSearchParams{
+		ID:     req.ID,
+		UserID: userID,
+	})
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.DatabaseError, "failed to delete saved search").
+			WithCode("DB_DELETE_FAILED").
+			WithDetails(fmt.Sprintf("id: %d", req.ID)).
+			WithCaller()
+	}
+	if affected == 0 {
+		return nil, apperrors.Newf(apperrors.NotFoundError, "saved search %d not found", req.ID).
+			WithCode("SAVED_SEARCH_NOT_FOUND")
+	}
+	return nil, nil
+}
+
+// dispatchSavedSearches sends newly created news to the readers whose saved searches they
+// match, at most savedSearch.maxItemsPerSearch per search. Pushes need FCM and LINE messages
+// a channel access token; searches whose token FCM reports as expired stop being pushed
+func (s *service) dispatchSavedSearches(ctx context.Context, news []onefeed_th_sqlc.News) {
+	searches, err := s.repo.SavedSearchRepository.GetNotifyingSavedSearches(ctx)
+	if err != nil {
+		slog.Error("Failed to load saved searches", "error", err)
+		return
+	}
+	if len(searches) == 0 {
+		return
+	}
+
+	line := s.notifiers[notify.ChannelLineMessaging]
+	if config.GetConfig().Line.ChannelAccessToken == "" {
+		line = nil
+	}
+	tmpl, err := notify.ParseTemplate("")
+	if err != nil {
+		slog.Error("Failed to parse the saved search template", "error", err)
+		return
+	}
+
+	limit := max(config.GetConfig().SavedSearch.MaxItemsPerSearch, 1)
+	expired := make(map[string]bool)
+	var notified []int64
+	for _, search := range searches {
+		var matches []onefeed_th_sqlc.News
+		for _, item := range news {
+			if len(matches) == limit {
+				break
+			}
+			if savedSearchMatches(search, item) {
+				matches = append(matches, item)
+			}
+		}
+		if len(matches) == 0 {
+			continue
+		}
+
+		sent := false
+		if s.push != nil && search.PushToken.Valid && !expired[search.PushToken.String] {
+			for _, item := range matches {
+				err := s.push.Send(ctx, search.PushToken.String, fcm.Message{
+					Title: search.Name,
+					Body:  item.Title,
+					Data: map[string]string{
+						"type":     "saved_search",
+						"searchId": strconv.FormatInt(search.ID, 10),
+						"newsId":   strconv.FormatInt(item.ID, 10),
+						"link":     item.Link,
+						"source":   item.Source,
+					},
+				})
+				if errors.Is(err, fcm.ErrUnregistered) {
+					slog.Info("Forgetting an expired push token of saved searches", "search_id", search.ID)
+					expired[search.PushToken.String] = true
+					if err := s.repo.SavedSearchRepository.ClearSavedSearchPushToken(ctx, search.PushToken); err != nil {
+						slog.Error("Failed to clear saved search push token", "search_id", search.ID, "error", err)
+					}
+					break
+				}
+				if err != nil {
+					slog.Warn("Saved search push failed", "search_id", search.ID, "news_id", item.ID, "error", err)
+					continue
+				}
+				sent = true
+			}
+		}
+
+		if line != nil && search.LineUserID.Valid {
+			messages := make([]string, 0, len(matches))
+			for _, item := range matches {
+				message, err := notify.Render(tmpl, notify.Item{
+					Title:       item.Title,
+					Link:        item.Link,
+					Source:      item.Source,
+					PublishedAt: converter.PGTypeTimestampToTime(item.PublishDate),
+				})
+				if err != nil {
+					slog.Warn("Failed to render saved search message", "search_id", search.ID, "news_id", item.ID, "error", err)
+					continue
+				}
+				messages = append(messages, search.Name+"\n"+message)
+			}
+			if len(messages) > 0 {
+				if err := line.Send(ctx, search.LineUserID.String, messages); err != nil {
+					slog.Warn("Saved search LINE message failed", "search_id", search.ID, "error", err)
+				} else {
+					sent = true
+				}
+			}
+		}
+
+		if sent {
+			notified = append(notified, search.ID)
+		}
+	}
+
+	if len(notified) == 0 {
+		return
+	}
+	if err := s.repo.SavedSearchRepository.TouchSavedSearchesNotified(ctx, notified); err != nil {
+		slog.Error("Failed to mark saved searches notified", "error", err)
+	}
+	slog.Info("Saved search matches sent", "searches", len(notified))
+}
+
+// savedSearchMatches tells whether every word of a search's query is in the title or summary
+// of item, from one of its sources when it lists any
+func savedSearchMatches(search onefeed_th_sqlc.SavedSearch, item onefeed_th_sqlc.News) bool {
+	if len(search.Sources) > 0 && !slices.Contains(search.Sources, item.Source) {
+		return false
+	}
+	text := strings.ToLower(item.Title + "\n" + item.Summary.String)
+	for _, term := range strings.Fields(strings.ToLower(search.Query)) {
+		if !strings.Contains(text, term) {
+			return false
+		}
+	}
+	return true
+}
+
+// savedSearchQuery collapses the whitespace of a query, which must keep at least two characters
+func savedSearchQuery(query string) (string, error) {
+	query = strings.Join(strings.Fields(query), " ")
+	if len([]rune(query)) < 2 {
+		return "", apperrors.New(apperrors.ValidationError, "query must have at least 2 characters").
+			WithCode("INVALID_QUERY")
+	}
+	return query, nil
+}
+
+func validateLineUserID(id string) error {
+	if id != "" && !lineUserIDPattern.MatchString(id) {
+		return apperrors.New(apperrors.ValidationError, "lineUserId must be a LINE user id, U followed by 32 hex digits").
+			WithCode("INVALID_LINE_USER_ID")
+	}
+	return nil
+}
+
+func toSavedSearchResponse(search onefeed_th_sqlc.SavedSearch) dto.SavedSearchResponse {
+	response := dto.SavedSearchResponse{
+		ID:        search.ID,
+		Name:      search.Name,
+		Query:     search.Query,
+		Sources:   nonNilStrings(search.Sources),
+		Push:      search.PushToken.Valid,
+		Line:      search.LineUserID.Valid,
+		CreatedAt: converter.PGTypeTimestampToTime(search.CreatedAt),
+	}
+	if search.NotifiedAt.Valid {
+		notifiedAt := search.NotifiedAt.Time
+		response.NotifiedAt = &notifiedAt
+	}
+	if search.UpdatedAt.Valid {
+		updatedAt := search.UpdatedAt.Time
+		response.UpdatedAt = &updatedAt
+	}
+	return response
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/onefeed-th/onefeed-th-backend-api/config"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/opensearch"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/utils/converter"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/dto"
+	apperrors "github.com/onefeed-th/onefeed-th-backend-api/internal/errors"
+	onefeed_th_sqlc "github.com/onefeed-th/onefeed-th-backend-api/internal/sqlc/onefeed_th_sqlc/db"
+)
+
+type SearchService interface {
+	SearchNews(ctx context.Context, req dto.NewsSearchRequest) (dto.NewsListGetResult, error)
+	ReindexSearch(ctx context.Context, req dto.BlankRequest) (dto.SearchReindexResponse, error)
+}
+
+// searchIndexBatchSize is how many news are sent per bulk request
+const searchIndexBatchSize = 500
+
+// SearchNews matches titles (and summaries in OpenSearch). With search.useOpenSearch the
+// results are ranked by relevance, otherwise they are the newest titles containing the query
+func (s *service) SearchNews(ctx context.Context, req dto.NewsSearchRequest) (dto.NewsListGetResult, error) {
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Limit <= 0 {
+		req.Limit = 20
+	}
+
+	if config.GetConfig().Search.UseOpenSearch && s.searchIndex != nil {
+		result, err := s.searchNewsInIndex(ctx, req)
+		if err == nil {
+			s.setReadState(ctx, result.Items)
+			return result, nil
+		}
+		slog.Warn("OpenSearch query failed, falling back to Postgres", "error", err)
+	}
+
+	pattern := "%" + escapeLikePattern(req.Query) + "%"
+	news, err := s.repo.NewsRepository.SearchNews(ctx, onefeed_th_sqlc.SearchNewsParams{
+		Pattern:    pattern,
+		Sources:    nonNilStrings(req.Source),
+		PageLimit:  req.Limit,
+		PageOffset: (req.Page - 1) * req.Limit,
+	})
+	if err != nil {
+		return dto.NewsListGetResult{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to search news").
+			WithCode("DB_QUERY_FAILED").
+			WithCaller()
+	}
+	total, err := s.repo.NewsRepository.CountSearchNews(ctx, onefeed_th_sqlc.CountSearchNewsParams{
+		Pattern: pattern,
+		Sources: nonNilStrings(req.Source),
+	})
+	if err != nil {
+		return dto.NewsListGetResult{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to count search results").
+			WithCode("DB_QUERY_FAILED").
+			WithCaller()
+	}
+
+	result := searchResult(req, news, total)
+	s.setReadState(ctx, result.Items)
+	return result, nil
+}
+
+func (s *service) searchNewsInIndex(ctx context.Context, req dto.NewsSearchRequest) (dto.NewsListGetResult, error) {
+	result, err := s.searchIndex.Search(ctx, opensearch.Query{
+		Text:    req.Query,
+		Sources: req.Source,
+		From:    int((req.Page - 1) * req.Limit),
+		Size:    int(req.Limit),
+	})
+	if err != nil {
+		return dto.NewsListGetResult{}, err
+	}
+
+	// the index only ranks; rows are loaded from Postgres so hidden items stay out
+	news, err := s.repo.NewsRepository.GetNewsByIDs(ctx, result.IDs)
+	if err != nil {
+		return dto.NewsListGetResult{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to load search results").
+			WithCode("DB_QUERY_FAILED").
+			WithCaller()
+	}
+	byID := make(map[int64]onefeed_th_sqlc.News, len(news))
+	for _, item := range news {
+		byID[item.ID] = item
+	}
+	ranked := make([]onefeed_th_sqlc.News, 0, len(news))
+	for _, id := range result.IDs {
+		if item, ok := byID[id]; ok {
+			ranked = append(ranked, item)
+		}
+	}
+
+	return searchResult(req, ranked, result.Total), nil
+}
+
+func searchResult(req dto.NewsSearchRequest, news []onefeed_th_sqlc.News, total int64) dto.NewsListGetResult {
+	items := make([]dto.NewsListGetResponse, 0, len(news))
+	for _, item := range news {
+		items = append(items, toNewsListGetResponse(item))
+	}
+	return dto.NewsListGetResult{
+		Items:      items,
+		Page:       req.Page,
+		Limit:      req.Limit,
+		TotalItems: total,
+		TotalPages: (total + int64(req.Limit) - 1) / int64(req.Limit),
+	}
+}
+
+// ReindexSearch copies every visible news item into OpenSearch, e.g. after enabling it
+func (s *service) ReindexSearch(ctx context.Context, req dto.BlankRequest) (dto.SearchReindexResponse, error) {
+	if s.searchIndex == nil {
+		return dto.SearchReindexResponse{}, apperrors.New(apperrors.ValidationError, "search.openSearch.url is not configured").
+			WithCode("SEARCH_INDEX_DISABLED")
+	}
+
+	var response dto.SearchReindexResponse
+	var afterID int64
+	for {
+		news, err := s.repo.NewsRepository.GetNewsAfterID(ctx, onefeed_th_sqlc.ListNewsAfterIDParams{
+			AfterID:   afterID,
+			PageLimit: searchIndexBatchSize,
+		})
+		if err != nil {
+			return response, apperrors.Wrap(err, apperrors.DatabaseError, "failed to read news for reindexing").
+				WithCode("DB_QUERY_FAILED").
+				WithCaller()
+		}
+		if len(news) == 0 {
+			break
+		}
+
+		if err := s.searchIndex.Index(ctx, toSearchDocuments(news)); err != nil {
+			return response, apperrors.Wrap(err, apperrors.UnavailableError, "failed to index news").
+				WithCode("SEARCH_INDEX_FAILED").
+				WithDetails(fmt.Sprintf("indexed before failing: %d", response.Indexed)).
+				WithCaller()
+		}
+		response.Indexed += int64(len(news))
+		afterID = news[len(news)-1].ID
+	}
+
+	slog.Info("Search index rebuilt", "indexed", response.Indexed)
+	return response, nil
+}
+
+// indexCollectedNews mirrors news inserted or refreshed by a collection into OpenSearch
+func (s *service) indexCollectedNews(ctx context.Context, links []string) {
+	if s.searchIndex == nil || len(links) == 0 {
+		return
+	}
+
+	news, err := s.repo.NewsRepository.GetNewsByLinks(ctx, links)
+	if err != nil {
+		slog.Warn("Failed to load collected news for indexing", "error", err)
+		return
+	}
+	for batch := range slices.Chunk(news, searchIndexBatchSize) {
+		if err := s.searchIndex.Index(ctx, toSearchDocuments(batch)); err != nil {
+			slog.Warn("Failed to index collected news", "error", err)
+			return
+		}
+	}
+	slog.Debug("Indexed collected news", "count", len(news))
+}
+
+// removeExpiredSearchDocuments drops news that left retention from OpenSearch
+func (s *service) removeExpiredSearchDocuments(ctx context.Context, before time.Time) {
+	if s.searchIndex == nil {
+		return
+	}
+
+	deleted, err := s.searchIndex.DeletePublishedBefore(ctx, before)
+	if err != nil {
+		slog.Warn("Failed to remove expired news from the search index", "error", err)
+		return
+	}
+	slog.Info("Removed expired news from the search index", "deleted", deleted)
+}
+
+func toSearchDocuments(news []onefeed_th_sqlc.News) []opensearch.Document {
+	docs := make([]opensearch.Document, 0, len(news))
+	for _, item := range news {
+		docs = append(docs, opensearch.Document{
+			ID:          item.ID,
+			Title:       item.Title,
+			Summary:     converter.PGTypeTextToString(item.Summary),
+			Source:      item.Source,
+			Link:        item.Link,
+			Image:       converter.PGTypeTextToString(item.ImageUrl),
+			PublishedAt: converter.PGTypeTimestampToTime(item.PublishDate),
+		})
+	}
+	return docs
+}
+
+// escapeLikePattern makes user input match literally inside an ILIKE pattern
+func escapeLikePattern(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
@@ -2,14 +2,303 @@ package service
 
 import (
 	"context"
+	"log/slog"
+	"os"
+	"runtime"
+	"sort"
+	"time"
 
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/onefeed-th/onefeed-th-backend-api/config"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/buildinfo"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/rds"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/supervisor"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/db"
 	"github.com/onefeed-th/onefeed-th-backend-api/internal/dto"
+	apperrors "github.com/onefeed-th/onefeed-th-backend-api/internal/errors"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/middleware"
 )
 
 type ServerService interface {
-	HealthCheck(ctx context.Context, req dto.BlankRequest) (string, error)
+	HealthCheck(ctx context.Context, req dto.BlankRequest) (dto.HealthCheckResponse, error)
+	Readiness(ctx context.Context, req dto.BlankRequest) (dto.ReadinessResponse, error)
+	GetServerStats(ctx context.Context, req dto.BlankRequest) (dto.ServerStatsResponse, error)
+	GetCacheStats(ctx context.Context, req dto.BlankRequest) (dto.CacheStatsResponse, error)
+	GetVersion(ctx context.Context, req dto.BlankRequest) (dto.VersionResponse, error)
+	GetConfig(ctx context.Context, req dto.BlankRequest) (dto.ConfigResponse, error)
 }
 
-func (s *service) HealthCheck(ctx context.Context, req dto.BlankRequest) (string, error) {
-	return "OK", nil
+// startedAt is used to report process uptime
+var startedAt = time.Now()
+
+// healthCheckTimeout bounds each dependency ping so /health answers quickly even when a dependency hangs
+const healthCheckTimeout = 2 * time.Second
+
+const (
+	healthStatusOK       = "ok"
+	healthStatusDegraded = "degraded"
+	healthStatusDown     = "down"
+
+	dependencyStatusUp       = "up"
+	dependencyStatusDown     = "down"
+	dependencyStatusDisabled = "disabled"
+)
+
+// GetVersion reports which build is running
+func (s *service) GetVersion(ctx context.Context, req dto.BlankRequest) (dto.VersionResponse, error) {
+	info := buildinfo.Get()
+	return dto.VersionResponse{
+		Commit:    info.Commit,
+		BuildTime: info.BuildTime,
+		GoVersion: info.GoVersion,
+		Profile:   info.Profile,
+		Modified:  info.Modified,
+	}, nil
+}
+
+// GetConfig reports the configuration this instance runs with, after defaults, config files,
+// environment variables and secrets were applied, with the secrets masked
+func (s *service) GetConfig(ctx context.Context, req dto.BlankRequest) (dto.ConfigResponse, error) {
+	cfg := config.GetConfig()
+	response := dto.ConfigResponse{
+		Env:          os.Getenv(config.AppEnvVar),
+		Files:        cfg.Files(),
+		EnvOverrides: config.EnvOverrides(),
+		Config:       cfg.Redacted(),
+	}
+	if response.Files == nil {
+		response.Files = []string{}
+	}
+	if response.EnvOverrides == nil {
+		response.EnvOverrides = []string{}
+	}
+	return response, nil
+}
+
+// HealthCheck pings Postgres and Redis and reports their status and latency.
+// Postgres is critical and turns the response into a 503; Redis only degrades it,
+// since every cache read already falls back to the database
+func (s *service) HealthCheck(ctx context.Context, req dto.BlankRequest) (dto.HealthCheckResponse, error) {
+	response := dto.HealthCheckResponse{Status: healthStatusOK}
+
+	if config.GetConfig().Storage.Driver == config.StorageDriverMemory {
+		response.Dependencies = []dto.DependencyHealth{
+			{Name: "postgres", Status: dependencyStatusDisabled, Critical: true},
+			{Name: "redis", Status: dependencyStatusDisabled},
+		}
+		return response, nil
+	}
+
+	response.Dependencies = []dto.DependencyHealth{
+		checkDependency(ctx, "postgres", true, db.Ping),
+		checkDependency(ctx, "redis", false, rds.Ping),
+	}
+
+	for _, dependency := range response.Dependencies {
+		if dependency.Status == dependencyStatusUp {
+			continue
+		}
+		if dependency.Critical {
+			response.Status = healthStatusDown
+			break
+		}
+		response.Status = healthStatusDegraded
+	}
+
+	if response.Status == healthStatusDown {
+		return response, apperrors.New(apperrors.UnavailableError, "critical dependency is unavailable").
+			WithCode("HEALTH_CHECK_FAILED")
+	}
+	return response, nil
+}
+
+// Readiness reports the connection state kept by the supervisor, which pings and reconnects
+// dependencies in the background. Without a running supervisor (memory driver or a zero
+// healthCheck.interval) it falls back to the live HealthCheck
+func (s *service) Readiness(ctx context.Context, req dto.BlankRequest) (dto.ReadinessResponse, error) {
+	states := supervisor.States()
+	if len(states) == 0 {
+		health, err := s.HealthCheck(ctx, req)
+		response := dto.ReadinessResponse{Status: health.Status}
+		for _, dependency := range health.Dependencies {
+			response.Dependencies = append(response.Dependencies, dto.DependencyState{
+				Name:     dependency.Name,
+				Status:   dependency.Status,
+				Critical: dependency.Critical,
+			})
+		}
+		return response, err
+	}
+
+	response := dto.ReadinessResponse{Status: healthStatusOK}
+	for _, state := range states {
+		response.Dependencies = append(response.Dependencies, dto.DependencyState{
+			Name:                state.Name,
+			Status:              state.Status,
+			Critical:            state.Critical,
+			LastCheckedAt:       state.LastCheckedAt,
+			LastError:           state.LastError,
+			ConsecutiveFailures: state.ConsecutiveFailures,
+			Reconnects:          state.Reconnects,
+		})
+
+		// unknown means the first check has not finished yet, which is not ready either
+		if state.Status == supervisor.StatusUp {
+			continue
+		}
+		if state.Critical {
+			response.Status = healthStatusDown
+		} else if response.Status != healthStatusDown {
+			response.Status = healthStatusDegraded
+		}
+	}
+
+	if response.Status == healthStatusDown {
+		return response, apperrors.New(apperrors.UnavailableError, "critical dependency is unavailable").
+			WithCode("NOT_READY")
+	}
+	return response, nil
+}
+
+func checkDependency(ctx context.Context, name string, critical bool, ping func(ctx context.Context) error) dto.DependencyHealth {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := ping(ctx)
+	health := dto.DependencyHealth{
+		Name:      name,
+		Status:    dependencyStatusUp,
+		Critical:  critical,
+		LatencyMs: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		slog.Warn("Health check failed",
+			"dependency", name,
+			"critical", critical,
+			"error", err,
+		)
+		health.Status = dependencyStatusDown
+		health.Error = err.Error()
+	}
+	return health
+}
+
+// GetServerStats reports connection pool and runtime numbers for production triage.
+// Pools that are not initialized (e.g. with the memory storage driver) are returned as null
+func (s *service) GetServerStats(ctx context.Context, req dto.BlankRequest) (dto.ServerStatsResponse, error) {
+	var response dto.ServerStatsResponse
+
+	if stat := db.GetPoolStats(); stat != nil {
+		response.Postgres = toPostgresPoolStats(stat)
+	}
+	for _, stat := range db.GetReplicaPoolStats() {
+		response.PostgresReplicas = append(response.PostgresReplicas, *toPostgresPoolStats(stat))
+	}
+	response.SlowQueries = slowQueryStats()
+
+	if stat := rds.GetRedisStats(); stat != nil {
+		response.Redis = &dto.RedisPoolStats{
+			Hits:       stat.Hits,
+			Misses:     stat.Misses,
+			Timeouts:   stat.Timeouts,
+			TotalConns: stat.TotalConns,
+			IdleConns:  stat.IdleConns,
+			StaleConns: stat.StaleConns,
+		}
+	}
+
+	response.Cache = cacheStats()
+
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	response.Runtime = dto.RuntimeStats{
+		Goroutines:    runtime.NumGoroutine(),
+		HeapAllocMB:   mem.HeapAlloc / 1024 / 1024,
+		HeapInuseMB:   mem.HeapInuse / 1024 / 1024,
+		HeapObjects:   mem.HeapObjects,
+		SysMB:         mem.Sys / 1024 / 1024,
+		NumGC:         mem.NumGC,
+		Panics:        middleware.PanicCount(),
+		UptimeSeconds: int64(time.Since(startedAt).Seconds()),
+	}
+
+	return response, nil
+}
+
+// GetCacheStats reports the cache hits, misses and errors per key prefix, to measure how well
+// each cache works
+func (s *service) GetCacheStats(ctx context.Context, req dto.BlankRequest) (dto.CacheStatsResponse, error) {
+	return cacheStats(), nil
+}
+
+func cacheStats() dto.CacheStatsResponse {
+	response := dto.CacheStatsResponse{
+		Total:    toCacheStats(rds.GetCacheStats()),
+		Prefixes: []dto.CachePrefixStats{},
+	}
+	for prefix, stat := range rds.GetCacheStatsByPrefix() {
+		response.Prefixes = append(response.Prefixes, dto.CachePrefixStats{
+			Prefix:     prefix,
+			CacheStats: toCacheStats(stat),
+		})
+	}
+	sort.Slice(response.Prefixes, func(i, j int) bool {
+		a, b := response.Prefixes[i], response.Prefixes[j]
+		if a.Hits+a.Misses != b.Hits+b.Misses {
+			return a.Hits+a.Misses > b.Hits+b.Misses
+		}
+		return a.Prefix < b.Prefix
+	})
+	return response
+}
+
+func slowQueryStats() dto.SlowQueryStats {
+	response := dto.SlowQueryStats{Queries: []dto.SlowQueryStat{}}
+	for name, stat := range db.GetSlowQueryStats() {
+		response.Total += stat.Count
+		response.Queries = append(response.Queries, dto.SlowQueryStat{
+			Name:          name,
+			Count:         stat.Count,
+			MaxDurationMs: stat.MaxDuration.Milliseconds(),
+		})
+	}
+	sort.Slice(response.Queries, func(i, j int) bool {
+		a, b := response.Queries[i], response.Queries[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Name < b.Name
+	})
+	return response
+}
+
+func toCacheStats(stat rds.CacheStats) dto.CacheStats {
+	stats := dto.CacheStats{
+		Hits:   stat.Hits,
+		Misses: stat.Misses,
+		Sets:   stat.Sets,
+		Errors: stat.Errors,
+	}
+	if lookups := stat.Hits + stat.Misses; lookups > 0 {
+		stats.HitRate = float64(stat.Hits) / float64(lookups)
+	}
+	return stats
+}
+
+func toPostgresPoolStats(stat *pgxpool.Stat) *dto.PostgresPoolStats {
+	return &dto.PostgresPoolStats{
+		TotalConns:              stat.TotalConns(),
+		AcquiredConns:           stat.AcquiredConns(),
+		IdleConns:               stat.IdleConns(),
+		ConstructingConns:       stat.ConstructingConns(),
+		MaxConns:                stat.MaxConns(),
+		AcquireCount:            stat.AcquireCount(),
+		AcquireDurationMs:       stat.AcquireDuration().Milliseconds(),
+		EmptyAcquireCount:       stat.EmptyAcquireCount(),
+		CanceledAcquireCount:    stat.CanceledAcquireCount(),
+		NewConnsCount:           stat.NewConnsCount(),
+		MaxLifetimeDestroyCount: stat.MaxLifetimeDestroyCount(),
+		MaxIdleDestroyCount:     stat.MaxIdleDestroyCount(),
+	}
 }
@@ -1,26 +1,175 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/onefeed-th/onefeed-th-backend-api/config"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/fcm"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/notify"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/oidc"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/opensearch"
 	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/rds"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/stream"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/summarizer"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/webhook"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/dto"
+	apperrors "github.com/onefeed-th/onefeed-th-backend-api/internal/errors"
 	"github.com/onefeed-th/onefeed-th-backend-api/internal/repository"
+	"github.com/redis/go-redis/v9"
 )
 
 type Service interface {
 	ServerService
 	CollectorService
 	NewsService
+	ClickService
+	FeedService
+	ExportService
+	ModerationService
 	TagService
 	SourceService
+	SourceSuggestionService
+	SavedSearchService
+	AuthService
+	BackofficeUserService
+	NewsStreamService
+	NewsSocketService
+	WebhookService
+	SearchService
+	NotificationRuleService
+	PushService
+	UserService
+	NewsReadService
+	ProfileService
+	RankingService
+	RateLimitService
+	DashboardService
+	LogLevelService
+	JobService
+	IdempotencyService
+	DeadLetterService
+	SitemapService
+	AnalyticsService
 }
 
 type service struct {
-	repo  *repository.Repository
-	redis rds.RedisClient
+	repo       *repository.Repository
+	redis      rds.RedisClient
+	summarizer summarizer.Summarizer
+	newsStream *stream.Hub[dto.NewsListGetResponse]
+	// newsSockets counts open /ws connections against webSocket.maxConnections
+	newsSockets atomic.Int64
+	webhooks    *webhook.Sender
+	// searchIndex is nil unless search.openSearch.url is set
+	searchIndex *opensearch.Client
+	// notifiers are keyed by notification rule channel
+	notifiers map[string]notify.Sender
+	// push is nil unless fcm.credentialsFile is set
+	push *fcm.Client
+	// oauth holds an ID token verifier per configured sign-in provider
+	oauth map[string]*oidc.Verifier
+	// jobs are the handlers of the background job types
+	jobs map[string]jobHandler
 }
 
 func NewService(repo *repository.Repository) Service {
-	return &service{
-		repo:  repo,
-		redis: rds.NewRedisClient(),
+	s := &service{
+		repo:        repo,
+		redis:       rds.NewRedisClient(),
+		summarizer:  summarizer.New(),
+		newsStream:  stream.NewHub[dto.NewsListGetResponse](),
+		webhooks:    webhook.NewSender(),
+		searchIndex: opensearch.New(),
+		notifiers:   notify.NewSenders(),
+		push:        fcm.New(),
+		oauth:       newOAuthVerifiers(),
+	}
+	s.jobs = s.newJobHandlers()
+	return s
+}
+
+// lockJob takes the lock of a job that must run on one instance at a time, and returns a
+// ConflictError while another instance runs it. When Redis fails the job runs without the
+// lock, since a duplicate run costs less than a missed one; the lock is nil then
+func (s *service) lockJob(ctx context.Context, name string, ttl time.Duration) (*rds.Lock, error) {
+	lock, err := s.redis.Lock(ctx, name, ttl)
+	if errors.Is(err, rds.ErrLockHeld) {
+		return nil, apperrors.Newf(apperrors.ConflictError, "%s is already running", name).
+			WithCode("JOB_IN_PROGRESS")
+	}
+	if err != nil {
+		slog.Warn("Failed to lock job, running it unlocked",
+			"job", name,
+			"error", err,
+		)
+		return nil, nil
+	}
+	return lock, nil
+}
+
+// unlockJob releases a lock taken by lockJob
+func (s *service) unlockJob(ctx context.Context, lock *rds.Lock) {
+	if lock == nil {
+		return
+	}
+	if err := lock.Unlock(context.WithoutCancel(ctx)); err != nil {
+		slog.Warn("Failed to unlock job",
+			"job", lock.Key(),
+			"error", err,
+		)
+	}
+}
+
+// cacheTTL is how long a cached response lives, configured per domain: the part of its key
+// before the first colon. Keys outside a configured domain don't expire
+func cacheTTL(key string) time.Duration {
+	cfg := config.GetConfig().Cache
+	domain, _, _ := strings.Cut(key, ":")
+	switch domain {
+	case "news":
+		return time.Duration(cfg.News.TTL) * time.Second
+	case "tags":
+		return time.Duration(cfg.Tags.TTL) * time.Second
+	case "sources":
+		return time.Duration(cfg.Sources.TTL) * time.Second
+	case "sitemap":
+		// sitemaps are regenerated every interval; the slack covers a late or failed run
+		return 2 * time.Duration(config.GetConfig().Sitemap.Interval) * time.Second
+	}
+	return 0
+}
+
+// loadCached returns the value cached under key, or loads it and caches it for cacheTTL. Cache
+// failures only cost the load
+func loadCached[T any](ctx context.Context, cache rds.RedisClient, key string, load func() (T, error)) (T, error) {
+	var value T
+	err := cache.Get(ctx, key, &value)
+	if err == nil {
+		return value, nil
+	}
+	if !errors.Is(err, redis.Nil) {
+		slog.Warn("Cache retrieval failed, continuing with database query",
+			"cache_key", key,
+			"error_code", "CACHE_GET_FAILED",
+			"error", err,
+		)
+	}
+
+	value, err = load()
+	if err != nil {
+		return value, err
+	}
+	if err := cache.SetWithExpiredTime(ctx, key, value, cacheTTL(key)); err != nil {
+		slog.Warn("Failed to cache query result",
+			"cache_key", key,
+			"error_code", "CACHE_SET_FAILED",
+			"error", err,
+		)
 	}
+	return value, nil
 }
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/onefeed-th/onefeed-th-backend-api/config"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/logger"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/sitemap"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/dto"
+	apperrors "github.com/onefeed-th/onefeed-th-backend-api/internal/errors"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/repository"
+	onefeed_th_sqlc "github.com/onefeed-th/onefeed-th-backend-api/internal/sqlc/onefeed_th_sqlc/db"
+)
+
+// SitemapService serves the sitemaps of the companion website, regenerated by the sitemap job
+// every sitemap.interval and cached in between
+type SitemapService interface {
+	GetSitemap(ctx context.Context, req dto.BlankRequest) (sitemap.Document, error)
+	GetNewsSitemap(ctx context.Context, req dto.BlankRequest) (sitemap.Document, error)
+}
+
+const (
+	// the keys leave out "news", whose keys every collection drops
+	sitemapCacheKey     = "sitemap:articles"
+	newsSitemapCacheKey = "sitemap:recent"
+
+	// Google News only reads the articles published in the last two days
+	newsSitemapWindow = 48 * time.Hour
+)
+
+func (s *service) GetSitemap(ctx context.Context, req dto.BlankRequest) (sitemap.Document, error) {
+	if !config.GetConfig().Sitemap.Enabled {
+		return nil, apperrors.New(apperrors.NotFoundError, "sitemap is disabled").
+			WithCode("SITEMAP_DISABLED")
+	}
+	return loadCached(ctx, s.redis, sitemapCacheKey, func() (sitemap.Document, error) {
+		s.scheduleJob(ctx, jobTypeSitemap)
+		doc, _, err := s.buildSitemap(ctx)
+		return doc, err
+	})
+}
+
+func (s *service) GetNewsSitemap(ctx context.Context, req dto.BlankRequest) (sitemap.Document, error) {
+	if !config.GetConfig().Sitemap.News {
+		return nil, apperrors.New(apperrors.NotFoundError, "news sitemap is disabled").
+			WithCode("SITEMAP_DISABLED")
+	}
+	return loadCached(ctx, s.redis, newsSitemapCacheKey, func() (sitemap.Document, error) {
+		s.scheduleJob(ctx, jobTypeSitemap)
+		doc, _, err := s.buildNewsSitemap(ctx)
+		return doc, err
+	})
+}
+
+// refreshSitemaps regenerates the enabled sitemaps into the cache. The job runs again every
+// sitemap.interval; once both are disabled the runs stop and the next request for a sitemap
+// starts them again
+func (s *service) refreshSitemaps(ctx context.Context) (dto.SitemapRunResponse, error) {
+	cfg := config.GetConfig().Sitemap
+	var result dto.SitemapRunResponse
+	if !cfg.Enabled && !cfg.News {
+		return result, nil
+	}
+
+	if cfg.Enabled {
+		doc, count, err := s.buildSitemap(ctx)
+		if err != nil {
+			return result, err
+		}
+		s.cacheSitemap(ctx, sitemapCacheKey, doc)
+		result.URLs = count
+	}
+	if cfg.News {
+		doc, count, err := s.buildNewsSitemap(ctx)
+		if err != nil {
+			return result, err
+		}
+		s.cacheSitemap(ctx, newsSitemapCacheKey, doc)
+		result.NewsURLs = count
+	}
+	return result, nil
+}
+
+func (s *service) cacheSitemap(ctx context.Context, key string, doc sitemap.Document) {
+	if err := s.redis.SetWithExpiredTime(ctx, key, doc, cacheTTL(key)); err != nil {
+		logger.For("jobs").Warn("Failed to cache sitemap",
+			"cache_key", key,
+			"error_code", "CACHE_SET_FAILED",
+			"error", err,
+		)
+	}
+}
+
+// buildSitemap lists the website and its newest sitemap.maxUrls articles
+func (s *service) buildSitemap(ctx context.Context) (sitemap.Document, int, error) {
+	cfg := config.GetConfig().Sitemap
+	news, err := s.sitemapNews(ctx, int32(min(cfg.MaxURLs, sitemap.MaxURLs-1)))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	home := sitemap.URL{Loc: strings.TrimRight(cfg.BaseURL, "/") + "/"}
+	if len(news) > 0 {
+		home.LastMod = news[0].PublishDate.Time
+	}
+	urls := append(make([]sitemap.URL, 0, len(news)+1), home)
+	for _, item := range news {
+		urls = append(urls, sitemap.URL{
+			Loc:     articleURL(cfg.BaseURL, cfg.ArticlePath, item.ID),
+			LastMod: item.PublishDate.Time,
+		})
+	}
+
+	doc, err := sitemap.Write(urls)
+	if err != nil {
+		return nil, 0, apperrors.Wrap(err, apperrors.InternalError, "failed to write sitemap").
+			WithCode("SITEMAP_WRITE_FAILED").
+			WithCaller()
+	}
+	return doc, len(news), nil
+}
+
+// buildNewsSitemap lists the articles published in the last two days for Google News
+func (s *service) buildNewsSitemap(ctx context.Context) (sitemap.Document, int, error) {
+	cfg := config.GetConfig().Sitemap
+	news, err := s.sitemapNews(ctx, sitemap.MaxNewsURLs)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	since := time.Now().Add(-newsSitemapWindow)
+	urls := make([]sitemap.NewsURL, 0, len(news))
+	for _, item := range news {
+		// news come newest first
+		if item.PublishDate.Time.Before(since) {
+			break
+		}
+		urls = append(urls, sitemap.NewsURL{
+			Loc:         articleURL(cfg.BaseURL, cfg.ArticlePath, item.ID),
+			Title:       item.Title,
+			PublishedAt: item.PublishDate.Time,
+		})
+	}
+
+	doc, err := sitemap.WriteNews(sitemap.Publication{Name: cfg.PublicationName, Language: cfg.Language}, urls)
+	if err != nil {
+		return nil, 0, apperrors.Wrap(err, apperrors.InternalError, "failed to write news sitemap").
+			WithCode("SITEMAP_WRITE_FAILED").
+			WithCaller()
+	}
+	return doc, len(urls), nil
+}
+
+// sitemapNews loads the newest visible news of the enabled sources
+func (s *service) sitemapNews(ctx context.Context, limit int32) ([]onefeed_th_sqlc.News, error) {
+	sources, err := s.resolveFeedSources(ctx, dto.FeedGetRequest{})
+	if err != nil || len(sources) == 0 {
+		return nil, err
+	}
+
+	news, err := s.repo.NewsRepository.GetNews(ctx, onefeed_th_sqlc.ListNewsParams{
+		Sources:   sources,
+		PageLimit: limit,
+	}, repository.NewsSortPublishedAtDesc)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve news for the sitemap").
+			WithCode("DB_QUERY_FAILED").
+			WithCaller()
+	}
+	return news, nil
+}
+
+// articleURL is the page of a news on the website
+func articleURL(baseURL, articlePath string, id int64) string {
+	return strings.TrimRight(baseURL, "/") + strings.ReplaceAll(articlePath, "{id}", strconv.FormatInt(id, 10))
+}
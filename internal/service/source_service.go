@@ -2,42 +2,174 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sort"
+	"time"
 
+	"github.com/andybalholm/cascadia"
+	"github.com/jackc/pgx/v5"
+	"github.com/mmcdole/gofeed"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/httpserver"
 	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/utils/converter"
 	"github.com/onefeed-th/onefeed-th-backend-api/internal/dto"
+	apperrors "github.com/onefeed-th/onefeed-th-backend-api/internal/errors"
 	onefeed_th_sqlc "github.com/onefeed-th/onefeed-th-backend-api/internal/sqlc/onefeed_th_sqlc/db"
 )
 
 type SourceService interface {
-	GetAllSourceByPagination(ctx context.Context, req dto.GetAllSourceByPaginationRequest) ([]dto.GetAllSourceByPaginationResponse, error)
+	GetAllSourceByPagination(ctx context.Context, req dto.GetAllSourceByPaginationRequest) (dto.GetAllSourceByPaginationResult, error)
+	GetSources(ctx context.Context, req dto.SourceListRequest) (dto.SourceListResult, error)
 	CreateSource(ctx context.Context, req dto.CreateSourceRequest) (dto.CreateSourceResponse, error)
+	UpdateSource(ctx context.Context, req dto.UpdateSourceRequest) (dto.Source, error)
+	EnableSource(ctx context.Context, req dto.SourceStatusRequest) (any, error)
+	DisableSource(ctx context.Context, req dto.SourceStatusRequest) (any, error)
+	DeleteSource(ctx context.Context, req dto.SourceStatusRequest) (any, error)
+	ValidateSource(ctx context.Context, req dto.ValidateSourceRequest) (dto.ValidateSourceResponse, error)
+	BulkCreateSources(ctx context.Context, req dto.BulkCreateSourceRequest) (dto.BulkCreateSourceResponse, error)
+	PreviewSource(ctx context.Context, req dto.SourcePreviewRequest) (dto.SourcePreviewResponse, error)
 }
 
-func (s *service) GetAllSourceByPagination(ctx context.Context, req dto.GetAllSourceByPaginationRequest) ([]dto.GetAllSourceByPaginationResponse, error) {
-	sources, err := s.repo.SourceRepository.GetAllSourcesWithPagination(ctx, onefeed_th_sqlc.GetAllSourcesWithPaginationParams{
+const (
+	defaultSourceListLimit = 50
+
+	// sourcePreviewItems is how many of the latest items ValidateSource returns
+	sourcePreviewItems = 5
+	// defaultSourcePreviewLimit is how many items PreviewSource returns by default
+	defaultSourcePreviewLimit = 20
+
+	// source lists and tags change rarely; they are cached under these keys until a source or
+	// tag changes
+	sourcesCacheKeyPrefix = "sources:"
+	allSourcesCacheKey    = sourcesCacheKeyPrefix + "all"
+	tagsCacheKey          = "tags:all"
+)
+
+// sourcePage is a cached page of listSources
+type sourcePage struct {
+	Sources []dto.Source `json:"sources"`
+	Total   int64        `json:"total"`
+}
+
+// GetAllSourceByPagination answers the legacy listing, which wraps every source in its own
+// single-element list; GetSources is its replacement
+func (s *service) GetAllSourceByPagination(ctx context.Context, req dto.GetAllSourceByPaginationRequest) (dto.GetAllSourceByPaginationResult, error) {
+	sources, total, err := s.listSources(ctx, dto.SourceListRequest{
 		PageLimit:  req.PageLimit,
 		PageOffset: req.PageOffset,
+		Name:       req.Name,
+		Tag:        req.Tag,
+		Status:     req.Status,
+		Sort:       req.Sort,
 	})
 	if err != nil {
-		return nil, err
+		return dto.GetAllSourceByPaginationResult{}, err
+	}
+
+	res := dto.GetAllSourceByPaginationResult{
+		PageLimit:  req.PageLimit,
+		PageOffset: req.PageOffset,
+		TotalCount: total,
 	}
-	var res []dto.GetAllSourceByPaginationResponse
 	for _, source := range sources {
-		res = append(res, dto.GetAllSourceByPaginationResponse{
-			Sources: []dto.Source{
-				{
-					ID:     int64(source.ID),
-					Name:   source.Name,
-					Tags:   converter.PGTypeTextToString(source.Tags),
-					RSSURL: converter.PGTypeTextToString(source.RssUrl),
-				},
-			},
+		res.Items = append(res.Items, dto.GetAllSourceByPaginationResponse{
+			Sources: []dto.Source{source},
 		})
 	}
 	return res, nil
 }
 
+func (s *service) GetSources(ctx context.Context, req dto.SourceListRequest) (dto.SourceListResult, error) {
+	if req.PageLimit <= 0 {
+		req.PageLimit = defaultSourceListLimit
+	}
+
+	sources, total, err := s.listSources(ctx, req)
+	if err != nil {
+		return dto.SourceListResult{}, err
+	}
+	return dto.SourceListResult{
+		Sources:    sources,
+		TotalCount: total,
+		PageLimit:  req.PageLimit,
+		PageOffset: req.PageOffset,
+	}, nil
+}
+
+// listSources returns a page of the sources matching the filters and how many match in total
+func (s *service) listSources(ctx context.Context, req dto.SourceListRequest) ([]dto.Source, int64, error) {
+	redisKey := fmt.Sprintf("%slist:name=%s:tag=%s:status=%s:sort=%s:limit=%d:offset=%d",
+		sourcesCacheKeyPrefix, req.Name, req.Tag, req.Status, req.Sort, req.PageLimit, req.PageOffset)
+	page, err := loadCached(ctx, s.redis, redisKey, func() (sourcePage, error) {
+		pattern := "%" + escapeLikePattern(req.Name) + "%"
+		rows, err := s.repo.SourceRepository.GetAllSourcesWithPagination(ctx, onefeed_th_sqlc.GetAllSourcesWithPaginationParams{
+			Pattern:    pattern,
+			Tag:        req.Tag,
+			Status:     req.Status,
+			Sort:       req.Sort,
+			PageLimit:  req.PageLimit,
+			PageOffset: req.PageOffset,
+		})
+		if err != nil {
+			return sourcePage{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve sources").
+				WithCode("DB_QUERY_FAILED").
+				WithCaller()
+		}
+		total, err := s.repo.SourceRepository.CountSources(ctx, onefeed_th_sqlc.CountSourcesParams{
+			Pattern: pattern,
+			Tag:     req.Tag,
+			Status:  req.Status,
+		})
+		if err != nil {
+			return sourcePage{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to count sources").
+				WithCode("DB_QUERY_FAILED").
+				WithCaller()
+		}
+
+		page := sourcePage{
+			Sources: make([]dto.Source, 0, len(rows)),
+			Total:   total,
+		}
+		for _, row := range rows {
+			page.Sources = append(page.Sources, toSourceResponse(row))
+		}
+		return page, nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return page.Sources, page.Total, nil
+}
+
+// cachedSources returns every source that isn't deleted, disabled ones included, for read
+// paths; checks before a write read the database instead
+func (s *service) cachedSources(ctx context.Context) ([]onefeed_th_sqlc.Source, error) {
+	return loadCached(ctx, s.redis, allSourcesCacheKey, func() ([]onefeed_th_sqlc.Source, error) {
+		return s.repo.SourceRepository.GetAllSources(ctx, true)
+	})
+}
+
+// invalidateSourceCaches drops the cached source lists and tags after a source or tag changed
+func (s *service) invalidateSourceCaches(ctx context.Context) {
+	for _, prefix := range []string{sourcesCacheKeyPrefix, tagsCacheKey} {
+		if err := s.redis.RemoveKeyContaining(ctx, prefix); err != nil {
+			slog.Warn("Failed to invalidate source cache",
+				"prefix", prefix,
+				"error_code", "CACHE_DELETE_FAILED",
+				"error", err,
+			)
+		}
+	}
+}
+
 func (s *service) CreateSource(ctx context.Context, req dto.CreateSourceRequest) (dto.CreateSourceResponse, error) {
+	if err := validateSourceTags(req.Tags); err != nil {
+		return dto.CreateSourceResponse{}, err
+	}
+
 	source, err := s.repo.SourceRepository.CreateSource(ctx, onefeed_th_sqlc.CreateSourceParams{
 		Name:   req.Name,
 		Tags:   converter.StringToPGTypeTextNull(req.Tags),
@@ -46,6 +178,7 @@ func (s *service) CreateSource(ctx context.Context, req dto.CreateSourceRequest)
 	if err != nil {
 		return dto.CreateSourceResponse{}, err
 	}
+	s.invalidateSourceCaches(ctx)
 	return dto.CreateSourceResponse{
 		ID:     int64(source.ID),
 		Name:   source.Name,
@@ -53,3 +186,392 @@ func (s *service) CreateSource(ctx context.Context, req dto.CreateSourceRequest)
 		RSSURL: converter.PGTypeTextToString(source.RssUrl),
 	}, nil
 }
+
+// BulkCreateSources validates and inserts each source on its own, so a bad row is reported
+// without stopping the rows around it
+func (s *service) BulkCreateSources(ctx context.Context, req dto.BulkCreateSourceRequest) (dto.BulkCreateSourceResponse, error) {
+	sources, err := s.repo.SourceRepository.GetAllSources(ctx, true)
+	if err != nil {
+		return dto.BulkCreateSourceResponse{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve sources").
+			WithCode("DB_QUERY_FAILED").
+			WithCaller()
+	}
+	// news only reference their source by name, so two sources can't share one
+	taken := make(map[string]bool, len(sources)+len(req.Sources))
+	for _, source := range sources {
+		taken[source.Name] = true
+	}
+
+	res := dto.BulkCreateSourceResponse{
+		Results: make([]dto.BulkCreateSourceResult, 0, len(req.Sources)),
+	}
+	for i, item := range req.Sources {
+		result := dto.BulkCreateSourceResult{
+			Row:  i + 1,
+			Name: item.Name,
+		}
+
+		fields, err := httpserver.ValidateStruct(item)
+		tagErr := validateSourceTags(item.Tags)
+		switch {
+		case err != nil || len(fields) > 0:
+			result.Code = "INVALID_SOURCE"
+			result.Error = "source validation failed"
+			result.Fields = fields
+		case tagErr != nil:
+			result.Code = tagErr.Code
+			result.Error = tagErr.Message
+		case taken[item.Name]:
+			result.Code = "SOURCE_NAME_TAKEN"
+			result.Error = fmt.Sprintf("source name %q is already taken", item.Name)
+		default:
+			source, err := s.repo.SourceRepository.CreateSource(ctx, onefeed_th_sqlc.CreateSourceParams{
+				Name:   item.Name,
+				Tags:   converter.StringToPGTypeTextNull(item.Tags),
+				RssUrl: converter.StringToPGTypeTextNull(item.RSSURL),
+			})
+			if err != nil {
+				slog.Error("Failed to create source in bulk import",
+					"row", result.Row,
+					"name", item.Name,
+					"error_code", "DB_INSERT_FAILED",
+					"error", err,
+				)
+				result.Code = "DB_INSERT_FAILED"
+				result.Error = "failed to create source"
+				break
+			}
+			taken[source.Name] = true
+			created := toSourceResponse(source)
+			result.Success = true
+			result.Source = &created
+		}
+
+		if result.Success {
+			res.Created++
+		} else {
+			res.Failed++
+		}
+		res.Results = append(res.Results, result)
+	}
+
+	if res.Created > 0 {
+		s.invalidateSourceCaches(ctx)
+	}
+	slog.Info("Sources imported",
+		"created", res.Created,
+		"failed", res.Failed,
+		"actor", actorFromContext(ctx),
+	)
+	return res, nil
+}
+
+func (s *service) UpdateSource(ctx context.Context, req dto.UpdateSourceRequest) (dto.Source, error) {
+	source, err := s.getSource(ctx, req.ID)
+	if err != nil {
+		return dto.Source{}, err
+	}
+
+	params := onefeed_th_sqlc.UpdateSourceParams{
+		Name:   source.Name,
+		Tags:   source.Tags,
+		RssUrl: source.RssUrl,
+		ID:     source.ID,
+
+		ImageSelector: source.ImageSelector,
+		ImageFields:   source.ImageFields,
+	}
+	if req.Name != nil {
+		params.Name = *req.Name
+	}
+	if req.Tags != nil {
+		if err := validateSourceTags(*req.Tags); err != nil {
+			return dto.Source{}, err
+		}
+		params.Tags = converter.StringToPGTypeTextNull(*req.Tags)
+	}
+	if req.RSSURL != nil {
+		params.RssUrl = converter.StringToPGTypeTextNull(*req.RSSURL)
+	}
+	if req.ImageSelector != nil {
+		if _, err := cascadia.Compile(*req.ImageSelector); *req.ImageSelector != "" && err != nil {
+			return dto.Source{}, apperrors.Wrap(err, apperrors.ValidationError, "image selector is not a valid CSS selector").
+				WithCode("INVALID_IMAGE_SELECTOR")
+		}
+		params.ImageSelector = converter.StringToPGTypeTextNull(*req.ImageSelector)
+	}
+	if req.ImageFields != nil {
+		params.ImageFields = *req.ImageFields
+		if len(params.ImageFields) == 0 {
+			params.ImageFields = nil
+		}
+	}
+
+	renamed := params.Name != source.Name
+	if renamed {
+		// news only reference their source by name, so two sources can't share one
+		sources, err := s.repo.SourceRepository.GetAllSources(ctx, true)
+		if err != nil {
+			return dto.Source{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve sources").
+				WithCode("DB_QUERY_FAILED").
+				WithCaller()
+		}
+		for _, other := range sources {
+			if other.ID != source.ID && other.Name == params.Name {
+				return dto.Source{}, apperrors.Newf(apperrors.ValidationError, "source name %q is already taken", params.Name).
+					WithCode("SOURCE_NAME_TAKEN")
+			}
+		}
+	}
+
+	updated, err := s.repo.SourceRepository.UpdateSource(ctx, params)
+	if err != nil {
+		return dto.Source{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to update source").
+			WithCode("DB_UPDATE_FAILED").
+			WithDetails(fmt.Sprintf("id: %d", req.ID)).
+			WithCaller()
+	}
+
+	s.invalidateSourceCaches(ctx)
+	// renaming moves the source's news, so every cached list, detail and feed may be stale
+	if renamed {
+		if err := s.redis.RemoveKeyContaining(ctx, "news"); err != nil {
+			slog.Warn("Failed to invalidate news cache after renaming source",
+				"id", updated.ID,
+				"error_code", "CACHE_DELETE_FAILED",
+				"error", err,
+			)
+		}
+	}
+
+	slog.Info("Source updated",
+		"id", updated.ID,
+		"name", updated.Name,
+		"previous_name", source.Name,
+		"actor", actorFromContext(ctx),
+	)
+	return toSourceResponse(updated), nil
+}
+
+func (s *service) EnableSource(ctx context.Context, req dto.SourceStatusRequest) (any, error) {
+	return nil, s.setSourceEnabled(ctx, req.ID, true)
+}
+
+// DisableSource stops collecting the source; its news and settings stay as they are
+func (s *service) DisableSource(ctx context.Context, req dto.SourceStatusRequest) (any, error) {
+	return nil, s.setSourceEnabled(ctx, req.ID, false)
+}
+
+func (s *service) setSourceEnabled(ctx context.Context, id int64, enabled bool) error {
+	affected, err := s.repo.SourceRepository.SetSourceEnabled(ctx, onefeed_th_sqlc.SetSourceEnabledParams{
+		Enabled: enabled,
+		ID:      id,
+	})
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.DatabaseError, "failed to update source").
+			WithCode("DB_UPDATE_FAILED").
+			WithDetails(fmt.Sprintf("id: %d", id)).
+			WithCaller()
+	}
+	if affected == 0 {
+		return apperrors.Newf(apperrors.NotFoundError, "source %d not found", id).
+			WithCode("SOURCE_NOT_FOUND")
+	}
+
+	s.invalidateSourceCaches(ctx)
+	slog.Info("Source status changed",
+		"id", id,
+		"enabled", enabled,
+		"actor", actorFromContext(ctx),
+	)
+	return nil
+}
+
+// DeleteSource hides the source from listings and the collector. Its news are kept until
+// the retention job removes them
+func (s *service) DeleteSource(ctx context.Context, req dto.SourceStatusRequest) (any, error) {
+	affected, err := s.repo.SourceRepository.DeleteSource(ctx, req.ID)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.DatabaseError, "failed to delete source").
+			WithCode("DB_DELETE_FAILED").
+			WithDetails(fmt.Sprintf("id: %d", req.ID)).
+			WithCaller()
+	}
+	if affected == 0 {
+		return nil, apperrors.Newf(apperrors.NotFoundError, "source %d not found", req.ID).
+			WithCode("SOURCE_NOT_FOUND")
+	}
+
+	s.invalidateSourceCaches(ctx)
+	slog.Info("Source deleted",
+		"id", req.ID,
+		"actor", actorFromContext(ctx),
+	)
+	return nil, nil
+}
+
+// ValidateSource fetches and parses an RSS URL the way the collector would, so dead or
+// malformed feeds are caught before the source is added
+func (s *service) ValidateSource(ctx context.Context, req dto.ValidateSourceRequest) (dto.ValidateSourceResponse, error) {
+	feed, err := fetchFeed(ctx, req.RSSURL)
+	if err != nil {
+		return dto.ValidateSourceResponse{}, err
+	}
+
+	res := dto.ValidateSourceResponse{
+		FeedTitle: feed.Title,
+		ItemCount: len(feed.Items),
+		Items:     make([]dto.ValidateSourceItem, 0, sourcePreviewItems),
+	}
+
+	items := append([]*gofeed.Item(nil), feed.Items...)
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].PublishedParsed == nil || items[j].PublishedParsed == nil {
+			return items[i].PublishedParsed != nil
+		}
+		return items[i].PublishedParsed.After(*items[j].PublishedParsed)
+	})
+	undated := 0
+	for _, item := range items {
+		if item.PublishedParsed == nil {
+			undated++
+		}
+		if len(res.Items) < sourcePreviewItems {
+			res.Items = append(res.Items, dto.ValidateSourceItem{
+				Title:       item.Title,
+				Link:        sanitizeLink(item.Link),
+				Image:       extractImage(item, imageRules{}),
+				PublishedAt: item.PublishedParsed,
+			})
+		}
+	}
+
+	if len(items) == 0 {
+		res.Warnings = append(res.Warnings, "the feed has no items")
+	}
+	if undated > 0 {
+		res.Warnings = append(res.Warnings, fmt.Sprintf("%d items have no publish date and will be dated by their collection", undated))
+	}
+	sources, err := s.repo.SourceRepository.GetAllSources(ctx, true)
+	if err != nil {
+		return dto.ValidateSourceResponse{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve sources").
+			WithCode("DB_QUERY_FAILED").
+			WithCaller()
+	}
+	for _, source := range sources {
+		if source.RssUrl.String == req.RSSURL {
+			res.Warnings = append(res.Warnings, fmt.Sprintf("the URL is already used by source %q", source.Name))
+		}
+	}
+	return res, nil
+}
+
+// PreviewSource fetches the feed of a source live and parses it the way the collector would,
+// without storing anything, so image extraction and date parsing can be checked per publisher
+func (s *service) PreviewSource(ctx context.Context, req dto.SourcePreviewRequest) (dto.SourcePreviewResponse, error) {
+	if req.Limit <= 0 {
+		req.Limit = defaultSourcePreviewLimit
+	}
+
+	source, err := s.getSource(ctx, req.ID)
+	if err != nil {
+		return dto.SourcePreviewResponse{}, err
+	}
+	if !source.RssUrl.Valid || source.RssUrl.String == "" {
+		return dto.SourcePreviewResponse{}, apperrors.Newf(apperrors.ValidationError, "source %d has no RSS URL", req.ID).
+			WithCode("SOURCE_WITHOUT_FEED")
+	}
+
+	feed, err := fetchFeed(ctx, source.RssUrl.String)
+	if err != nil {
+		return dto.SourcePreviewResponse{}, err
+	}
+
+	res := dto.SourcePreviewResponse{
+		Source:    toSourceResponse(source),
+		FeedTitle: feed.Title,
+		ItemCount: len(feed.Items),
+		Items:     make([]dto.SourcePreviewItem, 0, min(len(feed.Items), int(req.Limit))),
+	}
+	links := make([]string, 0, cap(res.Items))
+	for _, item := range feed.Items[:cap(res.Items)] {
+		image, imageFrom := findImage(item, sourceImageRules(source))
+		res.Items = append(res.Items, dto.SourcePreviewItem{
+			Title:       item.Title,
+			Link:        sanitizeLink(item.Link),
+			Image:       image,
+			ImageFrom:   imageFrom,
+			Published:   item.Published,
+			PublishedAt: item.PublishedParsed,
+		})
+		links = append(links, sanitizeLink(item.Link))
+	}
+
+	// GetAllMissingLinks returns the links that aren't stored yet
+	missing, err := s.repo.NewsRepository.GetAllMissingLinks(ctx, links)
+	if err != nil {
+		return dto.SourcePreviewResponse{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to check collected links").
+			WithCode("DB_QUERY_FAILED").
+			WithCaller()
+	}
+	for i := range res.Items {
+		res.Items[i].Collected = !slices.Contains(missing, res.Items[i].Link)
+	}
+	return res, nil
+}
+
+// fetchFeed fetches and parses a feed with the collector's timeout, turning failures into
+// validation errors that tell why the feed can't be collected
+func fetchFeed(ctx context.Context, rssURL string) (*gofeed.Feed, error) {
+	parser := gofeed.NewParser()
+	parser.Client = &http.Client{
+		Timeout: 30 * time.Second,
+	}
+
+	feedCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	feed, err := parser.ParseURLWithContext(rssURL, feedCtx)
+	if err != nil {
+		var httpErr gofeed.HTTPError
+		switch {
+		case errors.As(err, &httpErr):
+			return nil, apperrors.Newf(apperrors.ValidationError, "feed returned HTTP %d", httpErr.StatusCode).
+				WithCode("FEED_HTTP_ERROR")
+		case errors.Is(err, gofeed.ErrFeedTypeNotDetected):
+			return nil, apperrors.New(apperrors.ValidationError, "URL is not an RSS, Atom or JSON feed").
+				WithCode("FEED_NOT_DETECTED")
+		default:
+			return nil, apperrors.Wrap(err, apperrors.ValidationError, "failed to fetch or parse feed").
+				WithCode("FEED_FETCH_FAILED")
+		}
+	}
+	return feed, nil
+}
+
+func (s *service) getSource(ctx context.Context, id int64) (onefeed_th_sqlc.Source, error) {
+	source, err := s.repo.SourceRepository.GetSourceByID(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return source, apperrors.Newf(apperrors.NotFoundError, "source %d not found", id).
+			WithCode("SOURCE_NOT_FOUND")
+	}
+	if err != nil {
+		return source, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve source").
+			WithCode("DB_QUERY_FAILED").
+			WithCaller()
+	}
+	return source, nil
+}
+
+func toSourceResponse(source onefeed_th_sqlc.Source) dto.Source {
+	return dto.Source{
+		ID:      source.ID,
+		Name:    source.Name,
+		Tags:    converter.PGTypeTextToString(source.Tags),
+		RSSURL:  converter.PGTypeTextToString(source.RssUrl),
+		Enabled: source.Enabled,
+
+		ImageSelector: converter.PGTypeTextToString(source.ImageSelector),
+		ImageFields:   source.ImageFields,
+	}
+}
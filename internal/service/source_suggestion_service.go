@@ -0,0 +1,368 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/onefeed-th/onefeed-th-backend-api/config"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/auth"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/fcm"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/utils/converter"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/dto"
+	apperrors "github.com/onefeed-th/onefeed-th-backend-api/internal/errors"
+	onefeed_th_sqlc "github.com/onefeed-th/onefeed-th-backend-api/internal/sqlc/onefeed_th_sqlc/db"
+)
+
+// SourceSuggestionService lets readers suggest sources, which editors approve or reject
+type SourceSuggestionService interface {
+	SuggestSource(ctx context.Context, req dto.SourceSuggestionCreateRequest) (dto.SourceSuggestionResponse, error)
+	GetMySourceSuggestions(ctx context.Context, req dto.BlankRequest) ([]dto.SourceSuggestionResponse, error)
+	GetPendingSourceSuggestions(ctx context.Context, req dto.SourceSuggestionPendingRequest) (dto.SourceSuggestionPendingResult, error)
+	ApproveSourceSuggestion(ctx context.Context, req dto.SourceSuggestionApproveRequest) (dto.SourceSuggestionApproveResponse, error)
+	RejectSourceSuggestion(ctx context.Context, req dto.SourceSuggestionRejectRequest) (dto.SourceSuggestionResponse, error)
+}
+
+const (
+	suggestionPending  = "pending"
+	suggestionApproved = "approved"
+	suggestionRejected = "rejected"
+
+	// suggestionHistoryLimit is how many of their latest suggestions a reader gets back
+	suggestionHistoryLimit = 100
+)
+
+// SuggestSource queues a source suggested by the reader in ctx for review. The feed isn't
+// fetched here, reviewers check it with /backoffice/sources/validate
+func (s *service) SuggestSource(ctx context.Context, req dto.SourceSuggestionCreateRequest) (dto.SourceSuggestionResponse, error) {
+	userID, ok := auth.UserIDFromContext(ctx)
+	if !ok {
+		return dto.SourceSuggestionResponse{}, apperrors.New(apperrors.UnauthorizedError, "missing reader profile").
+			WithCode("MISSING_CREDENTIALS")
+	}
+	if err := validateSourceTags(req.Tags); err != nil {
+		return dto.SourceSuggestionResponse{}, err
+	}
+
+	sources, err := s.repo.SourceRepository.GetAllSources(ctx, true)
+	if err != nil {
+		return dto.SourceSuggestionResponse{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve sources").
+			WithCode("DB_QUERY_FAILED").
+			WithCaller()
+	}
+	for _, source := range sources {
+		if source.RssUrl.String == req.RSSURL {
+			return dto.SourceSuggestionResponse{}, apperrors.New(apperrors.ValidationError, "this feed is already a source").
+				WithCode("SOURCE_EXISTS")
+		}
+	}
+
+	if limit := config.GetConfig().SourceSuggestion.MaxPendingPerReader; limit > 0 {
+		suggestions, err := s.repo.SourceSuggestionRepository.GetUserSourceSuggestions(ctx, onefeed_th_sqlc.ListUserSourceSuggestionsParams{
+			UserID:    userID,
+			PageLimit: suggestionHistoryLimit,
+		})
+		if err != nil {
+			return dto.SourceSuggestionResponse{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve suggestions").
+				WithCode("DB_QUERY_FAILED").
+				WithCaller()
+		}
+		pending := 0
+		for _, suggestion := range suggestions {
+			if suggestion.Status == suggestionPending {
+				pending++
+			}
+		}
+		if pending >= limit {
+			return dto.SourceSuggestionResponse{}, apperrors.Newf(apperrors.RateLimitedError, "at most %d suggestions can wait for review at once", limit).
+				WithCode("TOO_MANY_PENDING_SUGGESTIONS")
+		}
+	}
+
+	suggestion, err := s.repo.SourceSuggestionRepository.CreateSourceSuggestion(ctx, onefeed_th_sqlc.CreateSourceSuggestionParams{
+		UserID:    userID,
+		Name:      req.Name,
+		Tags:      converter.StringToPGTypeTextNull(req.Tags),
+		RssUrl:    req.RSSURL,
+		Note:      req.Note,
+		PushToken: converter.StringToPGTypeTextNull(req.PushToken),
+	})
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return dto.SourceSuggestionResponse{}, apperrors.New(apperrors.ValidationError, "this feed is already waiting for review").
+			WithCode("SUGGESTION_PENDING")
+	}
+	if err != nil {
+		return dto.SourceSuggestionResponse{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to store suggestion").
+			WithCode("DB_INSERT_FAILED").
+			WithCaller()
+	}
+
+	slog.Info("Source suggested",
+		"id", suggestion.ID,
+		"name", suggestion.Name,
+		"user_id", userID,
+	)
+	go s.notifyReviewers(context.WithoutCancel(ctx), suggestion)
+
+	response := toSourceSuggestionResponse(suggestion)
+	response.UserID = 0
+	return response, nil
+}
+
+// GetMySourceSuggestions lets the reader follow up on their latest suggestions
+func (s *service) GetMySourceSuggestions(ctx context.Context, req dto.BlankRequest) ([]dto.SourceSuggestionResponse, error) {
+	userID, _ := auth.UserIDFromContext(ctx)
+	suggestions, err := s.repo.SourceSuggestionRepository.GetUserSourceSuggestions(ctx, onefeed_th_sqlc.ListUserSourceSuggestionsParams{
+		UserID:    userID,
+		PageLimit: suggestionHistoryLimit,
+	})
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve suggestions").
+			WithCode("DB_QUERY_FAILED").
+			WithCaller()
+	}
+
+	responses := make([]dto.SourceSuggestionResponse, 0, len(suggestions))
+	for _, suggestion := range suggestions {
+		response := toSourceSuggestionResponse(suggestion)
+		response.UserID = 0
+		response.ReviewedBy = ""
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
+func (s *service) GetPendingSourceSuggestions(ctx context.Context, req dto.SourceSuggestionPendingRequest) (dto.SourceSuggestionPendingResult, error) {
+	if req.PageLimit <= 0 {
+		req.PageLimit = defaultSourceListLimit
+	}
+
+	suggestions, err := s.repo.SourceSuggestionRepository.GetPendingSourceSuggestions(ctx, onefeed_th_sqlc.ListPendingSourceSuggestionsParams{
+		PageLimit:  req.PageLimit,
+		PageOffset: req.PageOffset,
+	})
+	if err != nil {
+		return dto.SourceSuggestionPendingResult{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve suggestions").
+			WithCode("DB_QUERY_FAILED").
+			WithCaller()
+	}
+	total, err := s.repo.SourceSuggestionRepository.CountPendingSourceSuggestions(ctx)
+	if err != nil {
+		return dto.SourceSuggestionPendingResult{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to count suggestions").
+			WithCode("DB_QUERY_FAILED").
+			WithCaller()
+	}
+
+	res := dto.SourceSuggestionPendingResult{
+		Suggestions: make([]dto.SourceSuggestionResponse, 0, len(suggestions)),
+		TotalCount:  total,
+		PageLimit:   req.PageLimit,
+		PageOffset:  req.PageOffset,
+	}
+	for _, suggestion := range suggestions {
+		res.Suggestions = append(res.Suggestions, toSourceSuggestionResponse(suggestion))
+	}
+	return res, nil
+}
+
+// ApproveSourceSuggestion creates the suggested source, with the editor's name and tags when
+// they are sent, and tells the reader
+func (s *service) ApproveSourceSuggestion(ctx context.Context, req dto.SourceSuggestionApproveRequest) (dto.SourceSuggestionApproveResponse, error) {
+	suggestion, err := s.getPendingSourceSuggestion(ctx, req.ID)
+	if err != nil {
+		return dto.SourceSuggestionApproveResponse{}, err
+	}
+
+	params := onefeed_th_sqlc.CreateSourceParams{
+		Name:   suggestion.Name,
+		Tags:   suggestion.Tags,
+		RssUrl: converter.StringToPGTypeTextNull(suggestion.RssUrl),
+	}
+	if req.Name != nil {
+		params.Name = *req.Name
+	}
+	if req.Tags != nil {
+		params.Tags = converter.StringToPGTypeTextNull(*req.Tags)
+	}
+	if err := validateSourceTags(params.Tags.String); err != nil {
+		return dto.SourceSuggestionApproveResponse{}, err
+	}
+
+	// news only reference their source by name, so two sources can't share one
+	sources, err := s.repo.SourceRepository.GetAllSources(ctx, true)
+	if err != nil {
+		return dto.SourceSuggestionApproveResponse{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve sources").
+			WithCode("DB_QUERY_FAILED").
+			WithCaller()
+	}
+	for _, source := range sources {
+		if source.Name == params.Name {
+			return dto.SourceSuggestionApproveResponse{}, apperrors.Newf(apperrors.ValidationError, "source name %q is already taken", params.Name).
+				WithCode("SOURCE_NAME_TAKEN")
+		}
+	}
+
+	approved, source, err := s.repo.SourceSuggestionRepository.ApproveSourceSuggestion(ctx, params, onefeed_th_sqlc.ReviewSourceSuggestionParams{
+		Status:       suggestionApproved,
+		ReviewReason: converter.StringToPGTypeTextNull(req.Reason),
+		ReviewedBy:   converter.StringToPGTypeTextNull(actorFromContext(ctx)),
+		ID:           req.ID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return dto.SourceSuggestionApproveResponse{}, apperrors.Newf(apperrors.ValidationError, "suggestion %d was already reviewed", req.ID).
+			WithCode("SUGGESTION_ALREADY_REVIEWED")
+	}
+	if err != nil {
+		return dto.SourceSuggestionApproveResponse{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to approve suggestion").
+			WithCode("DB_UPDATE_FAILED").
+			WithDetails(fmt.Sprintf("id: %d", req.ID)).
+			WithCaller()
+	}
+
+	s.invalidateSourceCaches(ctx)
+	slog.Info("Source suggestion approved",
+		"id", approved.ID,
+		"source_id", source.ID,
+		"name", source.Name,
+		"actor", actorFromContext(ctx),
+	)
+	go s.notifySuggester(context.WithoutCancel(ctx), approved)
+
+	return dto.SourceSuggestionApproveResponse{
+		Suggestion: toSourceSuggestionResponse(approved),
+		Source:     toSourceResponse(source),
+	}, nil
+}
+
+func (s *service) RejectSourceSuggestion(ctx context.Context, req dto.SourceSuggestionRejectRequest) (dto.SourceSuggestionResponse, error) {
+	if _, err := s.getPendingSourceSuggestion(ctx, req.ID); err != nil {
+		return dto.SourceSuggestionResponse{}, err
+	}
+
+	rejected, err := s.repo.SourceSuggestionRepository.ReviewSourceSuggestion(ctx, onefeed_th_sqlc.ReviewSourceSuggestionParams{
+		Status:       suggestionRejected,
+		ReviewReason: converter.StringToPGTypeTextNull(req.Reason),
+		ReviewedBy:   converter.StringToPGTypeTextNull(actorFromContext(ctx)),
+		ID:           req.ID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return dto.SourceSuggestionResponse{}, apperrors.Newf(apperrors.ValidationError, "suggestion %d was already reviewed", req.ID).
+			WithCode("SUGGESTION_ALREADY_REVIEWED")
+	}
+	if err != nil {
+		return dto.SourceSuggestionResponse{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to reject suggestion").
+			WithCode("DB_UPDATE_FAILED").
+			WithDetails(fmt.Sprintf("id: %d", req.ID)).
+			WithCaller()
+	}
+
+	slog.Info("Source suggestion rejected",
+		"id", rejected.ID,
+		"reason", req.Reason,
+		"actor", actorFromContext(ctx),
+	)
+	go s.notifySuggester(context.WithoutCancel(ctx), rejected)
+
+	return toSourceSuggestionResponse(rejected), nil
+}
+
+func (s *service) getPendingSourceSuggestion(ctx context.Context, id int64) (onefeed_th_sqlc.SourceSuggestion, error) {
+	suggestion, err := s.repo.SourceSuggestionRepository.GetSourceSuggestionByID(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return suggestion, apperrors.Newf(apperrors.NotFoundError, "suggestion %d not found", id).
+			WithCode("SUGGESTION_NOT_FOUND")
+	}
+	if err != nil {
+		return suggestion, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve suggestion").
+			WithCode("DB_QUERY_FAILED").
+			WithDetails(fmt.Sprintf("id: %d", id)).
+			WithCaller()
+	}
+	if suggestion.Status != suggestionPending {
+		return suggestion, apperrors.Newf(apperrors.ValidationError, "suggestion %d was already %s", id, suggestion.Status).
+			WithCode("SUGGESTION_ALREADY_REVIEWED")
+	}
+	return suggestion, nil
+}
+
+// notifyReviewers posts a new suggestion to sourceSuggestion.notifyChannel, when it is set
+func (s *service) notifyReviewers(ctx context.Context, suggestion onefeed_th_sqlc.SourceSuggestion) {
+	cfg := config.GetConfig().SourceSuggestion
+	if cfg.NotifyChannel == "" {
+		return
+	}
+	sender, ok := s.notifiers[cfg.NotifyChannel]
+	if !ok {
+		slog.Warn("Source suggestion notifications have an unknown channel", "channel", cfg.NotifyChannel)
+		return
+	}
+
+	message := fmt.Sprintf("New source suggestion #%d: %s\n%s", suggestion.ID, suggestion.Name, suggestion.RssUrl)
+	if suggestion.Note != "" {
+		message += "\n" + suggestion.Note
+	}
+	if err := sender.Send(ctx, cfg.NotifyTarget, []string{message}); err != nil {
+		slog.Warn("Source suggestion notification failed",
+			"id", suggestion.ID,
+			"channel", cfg.NotifyChannel,
+			"error", err,
+		)
+	}
+}
+
+// notifySuggester pushes the decision to the device that made the suggestion, when it sent
+// a push token and push notifications are configured
+func (s *service) notifySuggester(ctx context.Context, suggestion onefeed_th_sqlc.SourceSuggestion) {
+	if s.push == nil || !suggestion.PushToken.Valid {
+		return
+	}
+
+	msg := fcm.Message{
+		Title: "Your source suggestion was approved",
+		Body:  fmt.Sprintf("%s is now a source on OneFeed", suggestion.Name),
+		Data: map[string]string{
+			"type":         "source_suggestion",
+			"suggestionId": strconv.FormatInt(suggestion.ID, 10),
+			"status":       suggestion.Status,
+		},
+	}
+	if suggestion.Status == suggestionRejected {
+		msg.Title = "Your source suggestion was not accepted"
+		msg.Body = fmt.Sprintf("%s: %s", suggestion.Name, suggestion.ReviewReason.String)
+	}
+	if err := s.push.Send(ctx, suggestion.PushToken.String, msg); err != nil {
+		slog.Warn("Source suggestion push failed",
+			"id", suggestion.ID,
+			"error", err,
+		)
+	}
+}
+
+func toSourceSuggestionResponse(suggestion onefeed_th_sqlc.SourceSuggestion) dto.SourceSuggestionResponse {
+	response := dto.SourceSuggestionResponse{
+		ID:           suggestion.ID,
+		Name:         suggestion.Name,
+		Tags:         converter.PGTypeTextToString(suggestion.Tags),
+		RSSURL:       suggestion.RssUrl,
+		Note:         suggestion.Note,
+		Status:       suggestion.Status,
+		ReviewReason: converter.PGTypeTextToString(suggestion.ReviewReason),
+		CreatedAt:    converter.PGTypeTimestampToTime(suggestion.CreatedAt),
+		UserID:       suggestion.UserID,
+		ReviewedBy:   converter.PGTypeTextToString(suggestion.ReviewedBy),
+	}
+	if suggestion.ReviewedAt.Valid {
+		reviewedAt := suggestion.ReviewedAt.Time
+		response.ReviewedAt = &reviewedAt
+	}
+	if suggestion.SourceID.Valid {
+		sourceID := suggestion.SourceID.Int64
+		response.SourceID = &sourceID
+	}
+	return response
+}
@@ -2,14 +2,199 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"unicode/utf8"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/utils/converter"
 	"github.com/onefeed-th/onefeed-th-backend-api/internal/dto"
+	apperrors "github.com/onefeed-th/onefeed-th-backend-api/internal/errors"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/repository"
+	onefeed_th_sqlc "github.com/onefeed-th/onefeed-th-backend-api/internal/sqlc/onefeed_th_sqlc/db"
 )
 
 type TagService interface {
-	GetAllTags(ctx context.Context, req dto.BlankRequest) ([]string, error)
+	GetAllTags(ctx context.Context, req dto.BlankRequest) ([]dto.TagResponse, error)
+	GetBackofficeTags(ctx context.Context, req dto.BlankRequest) ([]dto.TagResponse, error)
+	CreateTag(ctx context.Context, req dto.TagCreateRequest) (dto.TagResponse, error)
+	UpdateTag(ctx context.Context, req dto.TagUpdateRequest) (dto.TagResponse, error)
+	DeleteTag(ctx context.Context, req dto.TagDeleteRequest) (any, error)
 }
 
-func (s *service) GetAllTags(ctx context.Context, req dto.BlankRequest) ([]string, error) {
-	return s.repo.NewsRepository.GetAllSource(ctx)
+// maxTagNameLength matches the tags.name column
+const maxTagNameLength = 50
+
+// GetAllTags lists the tags readers can filter by, those of at least one source
+func (s *service) GetAllTags(ctx context.Context, req dto.BlankRequest) ([]dto.TagResponse, error) {
+	tags, err := s.getTags(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.TagResponse, 0, len(tags))
+	for _, tag := range tags {
+		if tag.SourceCount > 0 {
+			responses = append(responses, tag)
+		}
+	}
+	return responses, nil
+}
+
+func (s *service) GetBackofficeTags(ctx context.Context, req dto.BlankRequest) ([]dto.TagResponse, error) {
+	return s.getTags(ctx)
+}
+
+func (s *service) CreateTag(ctx context.Context, req dto.TagCreateRequest) (dto.TagResponse, error) {
+	tag, err := s.repo.TagRepository.CreateTag(ctx, onefeed_th_sqlc.CreateTagParams{
+		Name:        req.Name,
+		Description: converter.StringToPGTypeTextNull(req.Description),
+	})
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return dto.TagResponse{}, apperrors.Newf(apperrors.ValidationError, "tag %q already exists", req.Name).
+			WithCode("TAG_NAME_TAKEN")
+	}
+	if err != nil {
+		return dto.TagResponse{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to store tag").
+			WithCode("DB_INSERT_FAILED").
+			WithCaller()
+	}
+
+	s.invalidateSourceCaches(ctx)
+	slog.Info("Tag created",
+		"id", tag.ID,
+		"name", tag.Name,
+		"actor", actorFromContext(ctx),
+	)
+	return toTagResponse(tag, 0), nil
+}
+
+func (s *service) UpdateTag(ctx context.Context, req dto.TagUpdateRequest) (dto.TagResponse, error) {
+	tag, err := s.repo.TagRepository.GetTagByID(ctx, req.ID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return dto.TagResponse{}, apperrors.Newf(apperrors.NotFoundError, "tag %d not found", req.ID).
+			WithCode("TAG_NOT_FOUND")
+	}
+	if err != nil {
+		return dto.TagResponse{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve tag").
+			WithCode("DB_QUERY_FAILED").
+			WithCaller()
+	}
+
+	params := onefeed_th_sqlc.UpdateTagParams{
+		Name:        tag.Name,
+		Description: tag.Description,
+		ID:          tag.ID,
+	}
+	if req.Name != nil {
+		params.Name = *req.Name
+	}
+	if req.Description != nil {
+		params.Description = converter.StringToPGTypeTextNull(*req.Description)
+	}
+
+	updated, err := s.repo.TagRepository.UpdateTag(ctx, params)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return dto.TagResponse{}, apperrors.Newf(apperrors.ValidationError, "tag %q already exists", params.Name).
+			WithCode("TAG_NAME_TAKEN")
+	}
+	if err != nil {
+		return dto.TagResponse{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to update tag").
+			WithCode("DB_UPDATE_FAILED").
+			WithDetails(fmt.Sprintf("id: %d", req.ID)).
+			WithCaller()
+	}
+
+	s.invalidateSourceCaches(ctx)
+	slog.Info("Tag updated",
+		"id", updated.ID,
+		"name", updated.Name,
+		"previous_name", tag.Name,
+		"actor", actorFromContext(ctx),
+	)
+	return s.getTag(ctx, updated)
+}
+
+// DeleteTag removes the tag from every source that has it
+func (s *service) DeleteTag(ctx context.Context, req dto.TagDeleteRequest) (any, error) {
+	deleted, err := s.repo.TagRepository.DeleteTag(ctx, req.ID)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.DatabaseError, "failed to delete tag").
+			WithCode("DB_DELETE_FAILED").
+			WithDetails(fmt.Sprintf("id: %d", req.ID)).
+			WithCaller()
+	}
+	if deleted == 0 {
+		return nil, apperrors.Newf(apperrors.NotFoundError, "tag %d not found", req.ID).
+			WithCode("TAG_NOT_FOUND")
+	}
+
+	s.invalidateSourceCaches(ctx)
+	slog.Info("Tag deleted",
+		"id", req.ID,
+		"actor", actorFromContext(ctx),
+	)
+	return nil, nil
+}
+
+// getTags returns every tag with its source count, cached until a source or tag changes
+func (s *service) getTags(ctx context.Context) ([]dto.TagResponse, error) {
+	return loadCached(ctx, s.redis, tagsCacheKey, func() ([]dto.TagResponse, error) {
+		rows, err := s.repo.TagRepository.GetTags(ctx)
+		if err != nil {
+			return nil, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve tags").
+				WithCode("DB_QUERY_FAILED").
+				WithCaller()
+		}
+
+		responses := make([]dto.TagResponse, 0, len(rows))
+		for _, row := range rows {
+			responses = append(responses, toTagResponse(onefeed_th_sqlc.Tag{
+				ID:          row.ID,
+				Name:        row.Name,
+				Description: row.Description,
+				CreatedAt:   row.CreatedAt,
+			}, row.SourceCount))
+		}
+		return responses, nil
+	})
+}
+
+// getTag returns tag with its source count
+func (s *service) getTag(ctx context.Context, tag onefeed_th_sqlc.Tag) (dto.TagResponse, error) {
+	tags, err := s.getTags(ctx)
+	if err != nil {
+		return dto.TagResponse{}, err
+	}
+	for _, response := range tags {
+		if response.ID == tag.ID {
+			return response, nil
+		}
+	}
+	return toTagResponse(tag, 0), nil
+}
+
+// validateSourceTags checks the comma separated tags of a source before they become tags
+func validateSourceTags(tags string) *apperrors.AppError {
+	for _, name := range repository.SourceTagNames(tags) {
+		if utf8.RuneCountInString(name) > maxTagNameLength {
+			return apperrors.Newf(apperrors.ValidationError, "tag %q is longer than %d characters", name, maxTagNameLength).
+				WithCode("INVALID_TAG")
+		}
+	}
+	return nil
+}
+
+func toTagResponse(tag onefeed_th_sqlc.Tag, sourceCount int64) dto.TagResponse {
+	return dto.TagResponse{
+		ID:          tag.ID,
+		Name:        tag.Name,
+		Description: converter.PGTypeTextToString(tag.Description),
+		SourceCount: sourceCount,
+		CreatedAt:   tag.CreatedAt.Time,
+	}
 }
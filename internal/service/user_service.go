@@ -0,0 +1,413 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/onefeed-th/onefeed-th-backend-api/config"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/auth"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/oidc"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/utils/converter"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/dto"
+	apperrors "github.com/onefeed-th/onefeed-th-backend-api/internal/errors"
+	onefeed_th_sqlc "github.com/onefeed-th/onefeed-th-backend-api/internal/sqlc/onefeed_th_sqlc/db"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type UserService interface {
+	RegisterUser(ctx context.Context, req dto.UserRegisterRequest) (dto.UserTokenResponse, error)
+	LoginUser(ctx context.Context, req dto.UserLoginRequest) (dto.UserTokenResponse, error)
+	LoginUserWithOAuth(ctx context.Context, req dto.UserOAuthLoginRequest) (dto.UserTokenResponse, error)
+	RefreshUserToken(ctx context.Context, req dto.RefreshTokenRequest) (dto.UserTokenResponse, error)
+	AuthenticateUserToken(ctx context.Context, token string) (int64, error)
+	GetCurrentUser(ctx context.Context, req dto.BlankRequest) (dto.UserResponse, error)
+	UpdateCurrentUser(ctx context.Context, req dto.UserUpdateRequest) (dto.UserResponse, error)
+	DeleteCurrentUser(ctx context.Context, req dto.BlankRequest) (any, error)
+}
+
+const (
+	oauthProviderGoogle = "google"
+	oauthProviderApple  = "apple"
+)
+
+// newOAuthVerifiers returns an ID token verifier for every provider with client ids configured
+func newOAuthVerifiers() map[string]*oidc.Verifier {
+	cfg := config.GetConfig().Auth.OAuth
+	verifiers := make(map[string]*oidc.Verifier)
+	if len(cfg.Google.ClientIDs) > 0 {
+		verifiers[oauthProviderGoogle] = oidc.NewVerifier(cfg.Google.JWKSURL,
+			[]string{"https://accounts.google.com", "accounts.google.com"}, cfg.Google.ClientIDs)
+	}
+	if len(cfg.Apple.ClientIDs) > 0 {
+		verifiers[oauthProviderApple] = oidc.NewVerifier(cfg.Apple.JWKSURL,
+			[]string{"https://appleid.apple.com"}, cfg.Apple.ClientIDs)
+	}
+	return verifiers
+}
+
+func (s *service) RegisterUser(ctx context.Context, req dto.UserRegisterRequest) (dto.UserTokenResponse, error) {
+	if config.GetConfig().Auth.JWT.Secret == "" {
+		return dto.UserTokenResponse{}, errUserLoginNotConfigured()
+	}
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return dto.UserTokenResponse{}, apperrors.Wrap(err, apperrors.InternalError, "failed to hash password").
+			WithCode("PASSWORD_HASH_FAILED").
+			WithCaller()
+	}
+
+	email := normalizeEmail(req.Email)
+	user, err := s.repo.UserRepository.CreateUser(ctx, onefeed_th_sqlc.CreateUserParams{
+		Email:        pgtype.Text{String: email, Valid: true},
+		PasswordHash: pgtype.Text{String: string(passwordHash), Valid: true},
+		DisplayName:  req.DisplayName,
+	})
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return dto.UserTokenResponse{}, apperrors.New(apperrors.ValidationError, "an account with this email already exists").
+			WithCode("EMAIL_TAKEN")
+	}
+	if err != nil {
+		return dto.UserTokenResponse{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to store user").
+			WithCode("DB_INSERT_FAILED").
+			WithCaller()
+	}
+
+	slog.Info("User registered", "user_id", user.ID, "method", "password")
+	s.mergeDeviceProfile(ctx, user.ID)
+	return issueUserTokens(user)
+}
+
+func (s *service) LoginUser(ctx context.Context, req dto.UserLoginRequest) (dto.UserTokenResponse, error) {
+	if config.GetConfig().Auth.JWT.Secret == "" {
+		return dto.UserTokenResponse{}, errUserLoginNotConfigured()
+	}
+
+	user, err := s.repo.UserRepository.GetUserByEmail(ctx, normalizeEmail(req.Email))
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return dto.UserTokenResponse{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve user").
+			WithCode("DB_QUERY_FAILED").
+			WithCaller()
+	}
+
+	// Unknown emails and OAuth-only accounts are checked against a dummy hash so response
+	// time doesn't reveal which emails have a password
+	passwordHash := dummyPasswordHash
+	if err == nil && user.PasswordHash.Valid {
+		passwordHash = []byte(user.PasswordHash.String)
+	}
+	if bcrypt.CompareHashAndPassword(passwordHash, []byte(req.Password)) != nil || err != nil || !user.PasswordHash.Valid {
+		return dto.UserTokenResponse{}, apperrors.New(apperrors.UnauthorizedError, "invalid email or password").
+			WithCode("INVALID_CREDENTIALS")
+	}
+
+	s.recordUserLogin(ctx, user.ID)
+	s.mergeDeviceProfile(ctx, user.ID)
+	return issueUserTokens(user)
+}
+
+// LoginUserWithOAuth signs a reader in with a Google or Apple ID token. The first sign-in
+// links the provider account to the user with the same verified email, dropping the user's
+// password, or creates a user
+func (s *service) LoginUserWithOAuth(ctx context.Context, req dto.UserOAuthLoginRequest) (dto.UserTokenResponse, error) {
+	if config.GetConfig().Auth.JWT.Secret == "" {
+		return dto.UserTokenResponse{}, errUserLoginNotConfigured()
+	}
+	verifier, ok := s.oauth[req.Provider]
+	if !ok {
+		return dto.UserTokenResponse{}, apperrors.Newf(apperrors.ValidationError, "sign in with %s is not configured", req.Provider).
+			WithCode("OAUTH_PROVIDER_NOT_CONFIGURED")
+	}
+
+	identity, err := verifier.Verify(ctx, req.IDToken)
+	if err != nil {
+		return dto.UserTokenResponse{}, apperrors.Wrap(err, apperrors.UnauthorizedError, "invalid ID token").
+			WithCode("INVALID_ID_TOKEN")
+	}
+
+	user, err := s.repo.UserRepository.GetUserByIdentity(ctx, onefeed_th_sqlc.GetUserByIdentityParams{
+		Provider: req.Provider,
+		Subject:  identity.Subject,
+	})
+	if err == nil {
+		s.recordUserLogin(ctx, user.ID)
+		s.mergeDeviceProfile(ctx, user.ID)
+		return issueUserTokens(user)
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return dto.UserTokenResponse{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve user").
+			WithCode("DB_QUERY_FAILED").
+			WithCaller()
+	}
+
+	user, created, err := s.userForIdentity(ctx, identity, req.DisplayName)
+	if err != nil {
+		return dto.UserTokenResponse{}, err
+	}
+	link := onefeed_th_sqlc.CreateUserIdentityParams{
+		Provider: req.Provider,
+		Subject:  identity.Subject,
+		UserID:   user.ID,
+		Email:    pgtype.Text{String: identity.Email, Valid: identity.Email != ""},
+	}
+	if created {
+		err = s.repo.UserRepository.CreateUserIdentity(ctx, link)
+	} else {
+		// nobody verified that whoever registered the email owns it, so the password goes
+		err = s.repo.UserRepository.LinkUserIdentity(ctx, link)
+	}
+	if err != nil {
+		if created {
+			// don't leave an account behind that nothing can sign in to
+			if _, err := s.repo.UserRepository.DeleteUser(ctx, user.ID); err != nil {
+				slog.Error("Failed to remove user after linking failed", "user_id", user.ID, "error", err)
+			}
+		}
+		return dto.UserTokenResponse{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to link sign-in provider").
+			WithCode("DB_INSERT_FAILED").
+			WithCaller()
+	}
+
+	slog.Info("User signed in with a new provider account",
+		"user_id", user.ID,
+		"provider", req.Provider,
+		"created", created,
+	)
+	s.mergeDeviceProfile(ctx, user.ID)
+	return issueUserTokens(user)
+}
+
+// userForIdentity finds the user owning the identity's verified email or creates one
+func (s *service) userForIdentity(ctx context.Context, identity oidc.Identity, displayName string) (onefeed_th_sqlc.User, bool, error) {
+	email := pgtype.Text{}
+	if identity.Email != "" && identity.EmailVerified {
+		email = pgtype.Text{String: normalizeEmail(identity.Email), Valid: true}
+		user, err := s.repo.UserRepository.GetUserByEmail(ctx, email.String)
+		if err == nil {
+			s.recordUserLogin(ctx, user.ID)
+			return user, false, nil
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return user, false, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve user").
+				WithCode("DB_QUERY_FAILED").
+				WithCaller()
+		}
+	}
+
+	if displayName == "" {
+		displayName = identity.Name
+	}
+	user, err := s.repo.UserRepository.CreateUser(ctx, onefeed_th_sqlc.CreateUserParams{
+		Email:       email,
+		DisplayName: displayName,
+	})
+	if err != nil {
+		return user, false, apperrors.Wrap(err, apperrors.DatabaseError, "failed to store user").
+			WithCode("DB_INSERT_FAILED").
+			WithCaller()
+	}
+	return user, true, nil
+}
+
+// RefreshUserToken exchanges a reader's refresh token for a new token pair
+func (s *service) RefreshUserToken(ctx context.Context, req dto.RefreshTokenRequest) (dto.UserTokenResponse, error) {
+	secret := config.GetConfig().Auth.JWT.Secret
+	if secret == "" {
+		return dto.UserTokenResponse{}, errUserLoginNotConfigured()
+	}
+
+	claims, err := auth.ParseToken([]byte(secret), req.RefreshToken, auth.TokenTypeUserRefresh)
+	if err != nil {
+		return dto.UserTokenResponse{}, apperrors.Wrap(err, apperrors.UnauthorizedError, "invalid refresh token").
+			WithCode("INVALID_TOKEN")
+	}
+	userID, err := claims.UserID()
+	if err != nil {
+		return dto.UserTokenResponse{}, apperrors.Wrap(err, apperrors.UnauthorizedError, "invalid refresh token").
+			WithCode("INVALID_TOKEN")
+	}
+
+	user, err := s.repo.UserRepository.GetUserByID(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return dto.UserTokenResponse{}, apperrors.New(apperrors.UnauthorizedError, "user no longer exists").
+			WithCode("INVALID_TOKEN")
+	}
+	if err != nil {
+		return dto.UserTokenResponse{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve user").
+			WithCode("DB_QUERY_FAILED").
+			WithCaller()
+	}
+	return issueUserTokens(user)
+}
+
+// AuthenticateUserToken verifies a reader's access token. Like backoffice tokens they are
+// stateless and stay valid until they expire, even after the account is deleted
+func (s *service) AuthenticateUserToken(ctx context.Context, token string) (int64, error) {
+	secret := config.GetConfig().Auth.JWT.Secret
+	if secret == "" {
+		return 0, errUserLoginNotConfigured()
+	}
+
+	claims, err := auth.ParseToken([]byte(secret), token, auth.TokenTypeUserAccess)
+	if err != nil {
+		return 0, apperrors.Wrap(err, apperrors.UnauthorizedError, "invalid bearer token").
+			WithCode("INVALID_TOKEN")
+	}
+	userID, err := claims.UserID()
+	if err != nil {
+		return 0, apperrors.Wrap(err, apperrors.UnauthorizedError, "invalid bearer token").
+			WithCode("INVALID_TOKEN")
+	}
+	return userID, nil
+}
+
+func (s *service) GetCurrentUser(ctx context.Context, req dto.BlankRequest) (dto.UserResponse, error) {
+	user, err := s.currentUser(ctx)
+	if err != nil {
+		return dto.UserResponse{}, err
+	}
+	return toUserResponse(user), nil
+}
+
+func (s *service) UpdateCurrentUser(ctx context.Context, req dto.UserUpdateRequest) (dto.UserResponse, error) {
+	user, err := s.currentUser(ctx)
+	if err != nil {
+		return dto.UserResponse{}, err
+	}
+	if req.DisplayName == nil {
+		return toUserResponse(user), nil
+	}
+
+	user, err = s.repo.UserRepository.UpdateUserProfile(ctx, onefeed_th_sqlc.UpdateUserProfileParams{
+		DisplayName: *req.DisplayName,
+		ID:          user.ID,
+	})
+	if err != nil {
+		return dto.UserResponse{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to update user").
+			WithCode("DB_UPDATE_FAILED").
+			WithCaller()
+	}
+	return toUserResponse(user), nil
+}
+
+// DeleteCurrentUser removes the reader's account along with everything stored for them
+func (s *service) DeleteCurrentUser(ctx context.Context, req dto.BlankRequest) (any, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	affected, err := s.repo.UserRepository.DeleteUser(ctx, userID)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.DatabaseError, "failed to delete user").
+			WithCode("DB_DELETE_FAILED").
+			WithCaller()
+	}
+	if affected == 0 {
+		return nil, errUserNotFound()
+	}
+
+	slog.Info("User deleted their account", "user_id", userID)
+	return nil, nil
+}
+
+// currentUser loads the signed-in reader
+func (s *service) currentUser(ctx context.Context) (onefeed_th_sqlc.User, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return onefeed_th_sqlc.User{}, err
+	}
+
+	user, err := s.repo.UserRepository.GetUserByID(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return user, errUserNotFound()
+	}
+	if err != nil {
+		return user, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve user").
+			WithCode("DB_QUERY_FAILED").
+			WithCaller()
+	}
+	return user, nil
+}
+
+// recordUserLogin is best effort; a failed update shouldn't fail the sign-in
+func (s *service) recordUserLogin(ctx context.Context, userID int64) {
+	if err := s.repo.UserRepository.UpdateUserLastLogin(ctx, userID); err != nil {
+		slog.Warn("Failed to record user login", "user_id", userID, "error", err)
+	}
+}
+
+// currentUserID returns the reader set by middleware.RequireUser
+func currentUserID(ctx context.Context) (int64, error) {
+	userID, ok := auth.UserIDFromContext(ctx)
+	if !ok {
+		return 0, apperrors.New(apperrors.UnauthorizedError, "missing bearer token").
+			WithCode("MISSING_CREDENTIALS")
+	}
+	return userID, nil
+}
+
+func issueUserTokens(user onefeed_th_sqlc.User) (dto.UserTokenResponse, error) {
+	cfg := config.GetConfig().Auth.JWT
+	secret := []byte(cfg.Secret)
+	accessTTL := time.Duration(cfg.AccessTokenTTL) * time.Minute
+	refreshTTL := time.Duration(cfg.RefreshTokenTTL) * time.Hour
+	name := "user-" + strconv.FormatInt(user.ID, 10)
+
+	accessToken, _, err := auth.IssueToken(secret, user.ID, name, "", auth.TokenTypeUserAccess, accessTTL)
+	if err != nil {
+		return dto.UserTokenResponse{}, apperrors.Wrap(err, apperrors.InternalError, "failed to issue access token").
+			WithCode("TOKEN_ISSUE_FAILED").
+			WithCaller()
+	}
+	refreshToken, _, err := auth.IssueToken(secret, user.ID, name, "", auth.TokenTypeUserRefresh, refreshTTL)
+	if err != nil {
+		return dto.UserTokenResponse{}, apperrors.Wrap(err, apperrors.InternalError, "failed to issue refresh token").
+			WithCode("TOKEN_ISSUE_FAILED").
+			WithCaller()
+	}
+
+	return dto.UserTokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(accessTTL.Seconds()),
+		User:         toUserResponse(user),
+	}, nil
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func errUserLoginNotConfigured() error {
+	return apperrors.New(apperrors.UnavailableError, "user login is not configured").
+		WithCode("USER_LOGIN_NOT_CONFIGURED")
+}
+
+func errUserNotFound() error {
+	return apperrors.New(apperrors.NotFoundError, "user not found").
+		WithCode("USER_NOT_FOUND")
+}
+
+func toUserResponse(user onefeed_th_sqlc.User) dto.UserResponse {
+	response := dto.UserResponse{
+		ID:          user.ID,
+		Email:       user.Email.String,
+		DisplayName: user.DisplayName,
+		CreatedAt:   converter.PGTypeTimestampToTime(user.CreatedAt),
+	}
+	if user.LastLoginAt.Valid {
+		lastLoginAt := user.LastLoginAt.Time
+		response.LastLoginAt = &lastLoginAt
+	}
+	return response
+}
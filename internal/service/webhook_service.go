@@ -0,0 +1,357 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/onefeed-th/onefeed-th-backend-api/config"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/utils/converter"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/webhook"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/dto"
+	apperrors "github.com/onefeed-th/onefeed-th-backend-api/internal/errors"
+	onefeed_th_sqlc "github.com/onefeed-th/onefeed-th-backend-api/internal/sqlc/onefeed_th_sqlc/db"
+)
+
+type WebhookService interface {
+	CreateWebhook(ctx context.Context, req dto.WebhookCreateRequest) (dto.WebhookCreateResponse, error)
+	GetWebhooks(ctx context.Context, req dto.BlankRequest) ([]dto.WebhookResponse, error)
+	UpdateWebhook(ctx context.Context, req dto.WebhookUpdateRequest) (dto.WebhookResponse, error)
+	DeleteWebhook(ctx context.Context, req dto.WebhookDeleteRequest) (any, error)
+	GetWebhookFailures(ctx context.Context, req dto.WebhookFailuresRequest) ([]dto.WebhookFailureResponse, error)
+}
+
+const (
+	webhookEventNewsCreated = "news.created"
+
+	defaultWebhookFailureLimit = 20
+)
+
+func (s *service) CreateWebhook(ctx context.Context, req dto.WebhookCreateRequest) (dto.WebhookCreateResponse, error) {
+	secret, err := webhook.GenerateSecret()
+	if err != nil {
+		return dto.WebhookCreateResponse{}, apperrors.Wrap(err, apperrors.InternalError, "failed to generate webhook secret").
+			WithCode("WEBHOOK_SECRET_GENERATION_FAILED").
+			WithCaller()
+	}
+
+	hook, err := s.repo.WebhookRepository.CreateWebhook(ctx, onefeed_th_sqlc.CreateWebhookParams{
+		Url:     req.URL,
+		Secret:  secret,
+		Sources: nonNilStrings(req.Sources),
+		Tags:    nonNilStrings(req.Tags),
+	})
+	if err != nil {
+		return dto.WebhookCreateResponse{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to store webhook").
+			WithCode("DB_INSERT_FAILED").
+			WithCaller()
+	}
+
+	slog.Info("Webhook created",
+		"id", hook.ID,
+		"url", hook.Url,
+		"actor", actorFromContext(ctx),
+	)
+	return dto.WebhookCreateResponse{
+		WebhookResponse: toWebhookResponse(hook),
+		Secret:          secret,
+	}, nil
+}
+
+func (s *service) GetWebhooks(ctx context.Context, req dto.BlankRequest) ([]dto.WebhookResponse, error) {
+	hooks, err := s.repo.WebhookRepository.GetWebhooks(ctx)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve webhooks from database").
+			WithCode("DB_QUERY_FAILED").
+			WithCaller()
+	}
+
+	responses := make([]dto.WebhookResponse, 0, len(hooks))
+	for _, hook := range hooks {
+		responses = append(responses, toWebhookResponse(hook))
+	}
+	return responses, nil
+}
+
+func (s *service) UpdateWebhook(ctx context.Context, req dto.WebhookUpdateRequest) (dto.WebhookResponse, error) {
+	hook, err := s.getWebhook(ctx, req.ID)
+	if err != nil {
+		return dto.WebhookResponse{}, err
+	}
+
+	params := onefeed_th_sqlc.UpdateWebhookParams{
+		Url:      hook.Url,
+		Sources:  hook.Sources,
+		Tags:     hook.Tags,
+		Disabled: hook.Disabled,
+		ID:       hook.ID,
+	}
+	if req.URL != nil {
+		params.Url = *req.URL
+	}
+	if req.Sources != nil {
+		params.Sources = nonNilStrings(*req.Sources)
+	}
+	if req.Tags != nil {
+		params.Tags = nonNilStrings(*req.Tags)
+	}
+	if req.Disabled != nil {
+		params.Disabled = *req.Disabled
+	}
+
+	hook, err = s.repo.WebhookRepository.UpdateWebhook(ctx, params)
+	if err != nil {
+		return dto.WebhookResponse{}, apperrors.Wrap(err, apperrors.DatabaseError, "failed to update webhook").
+			WithCode("DB_UPDATE_FAILED").
+			WithCaller()
+	}
+
+	slog.Info("Webhook updated",
+		"id", hook.ID,
+		"url", hook.Url,
+		"disabled", hook.Disabled,
+		"actor", actorFromContext(ctx),
+	)
+	return toWebhookResponse(hook), nil
+}
+
+func (s *service) DeleteWebhook(ctx context.Context, req dto.WebhookDeleteRequest) (any, error) {
+	affected, err := s.repo.WebhookRepository.DeleteWebhook(ctx, req.ID)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.DatabaseError, "failed to delete webhook").
+			WithCode("DB_DELETE_FAILED").
+			WithDetails(fmt.Sprintf("id: %d", req.ID)).
+			WithCaller()
+	}
+	if affected == 0 {
+		return nil, apperrors.Newf(apperrors.NotFoundError, "webhook %d not found", req.ID).
+			WithCode("WEBHOOK_NOT_FOUND")
+	}
+
+	slog.Info("Webhook deleted",
+		"id", req.ID,
+		"actor", actorFromContext(ctx),
+	)
+	return nil, nil
+}
+
+// GetWebhookFailures lists the newest deliveries that failed every attempt
+func (s *service) GetWebhookFailures(ctx context.Context, req dto.WebhookFailuresRequest) ([]dto.WebhookFailureResponse, error) {
+	if _, err := s.getWebhook(ctx, req.ID); err != nil {
+		return nil, err
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultWebhookFailureLimit
+	}
+	failures, err := s.repo.WebhookRepository.GetWebhookFailures(ctx, onefeed_th_sqlc.ListWebhookFailuresParams{
+		WebhookID: req.ID,
+		PageLimit: limit,
+	})
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve webhook failures from database").
+			WithCode("DB_QUERY_FAILED").
+			WithCaller()
+	}
+
+	responses := make([]dto.WebhookFailureResponse, 0, len(failures))
+	for _, failure := range failures {
+		response := dto.WebhookFailureResponse{
+			ID:        failure.ID,
+			Attempts:  failure.Attempts,
+			Error:     failure.Error,
+			Payload:   failure.Payload,
+			CreatedAt: converter.PGTypeTimestampToTime(failure.CreatedAt),
+		}
+		if failure.StatusCode.Valid {
+			statusCode := failure.StatusCode.Int32
+			response.StatusCode = &statusCode
+		}
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
+func (s *service) getWebhook(ctx context.Context, id int64) (onefeed_th_sqlc.Webhook, error) {
+	hook, err := s.repo.WebhookRepository.GetWebhookByID(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return hook, apperrors.Newf(apperrors.NotFoundError, "webhook %d not found", id).
+			WithCode("WEBHOOK_NOT_FOUND")
+	}
+	if err != nil {
+		return hook, apperrors.Wrap(err, apperrors.DatabaseError, "failed to retrieve webhook").
+			WithCode("DB_QUERY_FAILED").
+			WithCaller()
+	}
+	return hook, nil
+}
+
+// dispatchWebhooks queues a delivery of newly created news to every enabled webhook whose
+// filter matches, one job per webhook.batchSize items
+func (s *service) dispatchWebhooks(ctx context.Context, news []onefeed_th_sqlc.News) {
+	hooks, err := s.repo.WebhookRepository.GetEnabledWebhooks(ctx)
+	if err != nil {
+		slog.Error("Failed to load webhooks", "error", err)
+		return
+	}
+	if len(hooks) == 0 {
+		return
+	}
+
+	// tag filters match the tags of an item's source
+	sourceTags := make(map[string]string)
+	if slices.ContainsFunc(hooks, func(hook onefeed_th_sqlc.Webhook) bool { return len(hook.Tags) > 0 }) {
+		sources, err := s.cachedSources(ctx)
+		if err != nil {
+			slog.Error("Failed to load sources for webhook filters", "error", err)
+			return
+		}
+		for _, source := range sources {
+			sourceTags[source.Name] = converter.PGTypeTextToString(source.Tags)
+		}
+	}
+
+	for _, hook := range hooks {
+		items := make([]dto.NewsListGetResponse, 0, len(news))
+		for _, item := range news {
+			if webhookMatches(hook, item.Source, sourceTags[item.Source]) {
+				items = append(items, toNewsListGetResponse(item))
+			}
+		}
+		for batch := range slices.Chunk(items, max(config.GetConfig().Webhook.BatchSize, 1)) {
+			s.enqueueWebhookDelivery(ctx, hook, batch)
+		}
+	}
+}
+
+// webhookDelivery is the payload of a webhook delivery job
+type webhookDelivery struct {
+	WebhookID int64           `json:"webhookId"`
+	Body      json.RawMessage `json:"body"`
+}
+
+// webhookDeliveryError keeps the receiver's answer of a failed delivery for the dead-letter log
+type webhookDeliveryError struct {
+	webhook.Result
+}
+
+func (e *webhookDeliveryError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *webhookDeliveryError) Unwrap() error {
+	return e.Err
+}
+
+// enqueueWebhookDelivery queues one batch of items for hook
+func (s *service) enqueueWebhookDelivery(ctx context.Context, hook onefeed_th_sqlc.Webhook, items []dto.NewsListGetResponse) {
+	body, err := json.Marshal(dto.WebhookPayload{Event: webhookEventNewsCreated, News: items})
+	if err != nil {
+		slog.Error("Failed to encode webhook payload", "webhook_id", hook.ID, "error", err)
+		return
+	}
+	payload, err := json.Marshal(webhookDelivery{WebhookID: hook.ID, Body: body})
+	if err != nil {
+		slog.Error("Failed to encode webhook delivery", "webhook_id", hook.ID, "error", err)
+		return
+	}
+	if _, err := s.enqueueJob(ctx, jobTypeWebhookDelivery, payload, time.Now()); err != nil {
+		slog.Error("Failed to enqueue webhook delivery", "webhook_id", hook.ID, "error", err)
+	}
+}
+
+// deliverWebhookJob posts a queued batch. Deliveries to webhooks that were deleted or
+// disabled since are dropped
+func (s *service) deliverWebhookJob(ctx context.Context, payload []byte) error {
+	var delivery webhookDelivery
+	if err := json.Unmarshal(payload, &delivery); err != nil {
+		return permanent(fmt.Errorf("invalid webhook delivery payload: %w", err))
+	}
+	if delivery.WebhookID == 0 {
+		return permanent(errors.New("webhook delivery payload has no webhookId"))
+	}
+
+	hook, err := s.repo.WebhookRepository.GetWebhookByID(ctx, delivery.WebhookID)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && hook.Disabled) {
+		slog.Debug("Dropping delivery to a removed or disabled webhook", "webhook_id", delivery.WebhookID)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	result := s.webhooks.Deliver(ctx, hook.Url, hook.Secret, webhookEventNewsCreated, delivery.Body)
+	if result.Err == nil {
+		slog.Debug("Webhook delivered", "webhook_id", hook.ID, "status", result.StatusCode)
+		return nil
+	}
+	err = &webhookDeliveryError{result}
+	if !result.Retryable {
+		return permanent(err)
+	}
+	return err
+}
+
+// recordWebhookFailure keeps a delivery that failed every attempt in the dead-letter log
+func (s *service) recordWebhookFailure(ctx context.Context, job onefeed_th_sqlc.Job, err error) {
+	var delivery webhookDelivery
+	if json.Unmarshal(job.Payload, &delivery) != nil || delivery.WebhookID == 0 {
+		return
+	}
+
+	var statusCode int
+	var deliveryErr *webhookDeliveryError
+	if errors.As(err, &deliveryErr) {
+		statusCode = deliveryErr.StatusCode
+	}
+	err = s.repo.WebhookRepository.CreateWebhookFailure(ctx, onefeed_th_sqlc.CreateWebhookFailureParams{
+		WebhookID:  delivery.WebhookID,
+		Payload:    delivery.Body,
+		Attempts:   job.Attempts,
+		StatusCode: pgtype.Int4{Int32: int32(statusCode), Valid: statusCode != 0},
+		Error:      err.Error(),
+	})
+	if err != nil {
+		slog.Error("Failed to record webhook failure", "webhook_id", delivery.WebhookID, "error", err)
+	}
+}
+
+// webhookMatches reports whether an item from source passes the webhook's filter
+func webhookMatches(hook onefeed_th_sqlc.Webhook, source, sourceTags string) bool {
+	if len(hook.Sources) == 0 && len(hook.Tags) == 0 {
+		return true
+	}
+	return slices.Contains(hook.Sources, source) ||
+		(len(hook.Tags) > 0 && sourceHasAnyTag(sourceTags, hook.Tags))
+}
+
+func toWebhookResponse(hook onefeed_th_sqlc.Webhook) dto.WebhookResponse {
+	response := dto.WebhookResponse{
+		ID:        hook.ID,
+		URL:       hook.Url,
+		Sources:   nonNilStrings(hook.Sources),
+		Tags:      nonNilStrings(hook.Tags),
+		Disabled:  hook.Disabled,
+		CreatedAt: converter.PGTypeTimestampToTime(hook.CreatedAt),
+	}
+	if hook.UpdatedAt.Valid {
+		updatedAt := hook.UpdatedAt.Time
+		response.UpdatedAt = &updatedAt
+	}
+	return response
+}
+
+// nonNilStrings stores and returns empty lists rather than NULL
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
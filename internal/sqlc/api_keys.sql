@@ -0,0 +1,27 @@
+CREATE TABLE api_keys (
+  id BIGSERIAL PRIMARY KEY,
+  name TEXT NOT NULL,
+  key_prefix TEXT NOT NULL, -- ส่วนต้นของคีย์ ไว้แสดงผลให้แอดมินจำได้
+  key_hash TEXT NOT NULL UNIQUE, -- sha256 ของคีย์ (ไม่เก็บคีย์จริง)
+  created_at TIMESTAMP DEFAULT NOW(),
+  revoked_at TIMESTAMP,
+  role TEXT NOT NULL DEFAULT 'editor' -- admin, editor หรือ viewer
+);
+-- name: CreateApiKey :one
+INSERT INTO api_keys (name, key_prefix, key_hash, role)
+VALUES (@name, @key_prefix, @key_hash, @role)
+RETURNING *;
+-- name: GetActiveApiKeyByHash :one
+SELECT *
+FROM api_keys
+WHERE key_hash = @key_hash
+  AND revoked_at IS NULL;
+-- name: ListApiKeys :many
+SELECT *
+FROM api_keys
+ORDER BY created_at DESC;
+-- name: RevokeApiKey :execrows
+UPDATE api_keys
+SET revoked_at = NOW()
+WHERE id = @id
+  AND revoked_at IS NULL;
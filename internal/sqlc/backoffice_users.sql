@@ -0,0 +1,32 @@
+CREATE TABLE backoffice_users (
+  id BIGSERIAL PRIMARY KEY,
+  username TEXT NOT NULL UNIQUE,
+  password_hash TEXT NOT NULL, -- bcrypt
+  role TEXT NOT NULL, -- admin, editor หรือ viewer
+  disabled BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at TIMESTAMP DEFAULT NOW(),
+  updated_at TIMESTAMP
+);
+-- name: CreateBackofficeUser :one
+INSERT INTO backoffice_users (username, password_hash, role)
+VALUES (@username, @password_hash, @role)
+RETURNING *;
+-- name: GetBackofficeUserByID :one
+SELECT *
+FROM backoffice_users
+WHERE id = @id;
+-- name: GetBackofficeUserByUsername :one
+SELECT *
+FROM backoffice_users
+WHERE username = @username;
+-- name: ListBackofficeUsers :many
+SELECT *
+FROM backoffice_users
+ORDER BY username;
+-- name: UpdateBackofficeUser :one
+UPDATE backoffice_users
+SET role = @role,
+  disabled = @disabled,
+  updated_at = NOW()
+WHERE id = @id
+RETURNING *;
@@ -0,0 +1,34 @@
+CREATE TABLE bookmarks (
+  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  news_id BIGINT NOT NULL, -- ไม่มี FK เพราะ news แบ่ง partition และข่าวเก่าย้ายไป archive
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (user_id, news_id)
+);
+-- name: CreateBookmark :exec
+INSERT INTO bookmarks (user_id, news_id)
+VALUES (@user_id, @news_id)
+ON CONFLICT (user_id, news_id) DO NOTHING;
+-- name: DeleteBookmark :execrows
+DELETE FROM bookmarks
+WHERE user_id = @user_id
+  AND news_id = @news_id;
+-- name: ListBookmarks :many
+-- Keyset pagination on (created_at, news_id), the same as ListNewsReads
+SELECT *
+FROM bookmarks
+WHERE user_id = @user_id
+  AND (
+    @before_created_at::TIMESTAMP IS NULL
+    OR (created_at, news_id) < (@before_created_at::TIMESTAMP, @before_news_id::BIGINT)
+  )
+ORDER BY created_at DESC,
+  news_id DESC
+LIMIT @page_limit;
+-- name: MergeBookmarks :exec
+INSERT INTO bookmarks (user_id, news_id, created_at)
+SELECT @to_user_id,
+  news_id,
+  created_at
+FROM bookmarks
+WHERE user_id = @from_user_id
+ON CONFLICT (user_id, news_id) DO NOTHING;
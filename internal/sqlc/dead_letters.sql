@@ -0,0 +1,42 @@
+CREATE TABLE dead_letters (
+  id BIGSERIAL PRIMARY KEY,
+  source_id BIGINT NOT NULL REFERENCES sources (id) ON DELETE CASCADE,
+  item_key TEXT NOT NULL, -- guid ของ item, หรือ link, หรือ sha256 ของ payload
+  link TEXT,
+  payload JSONB NOT NULL, -- item ดิบจาก feed
+  error TEXT NOT NULL,
+  occurrences INT NOT NULL DEFAULT 1, -- จำนวนรอบ collect ที่เจอ item นี้
+  created_at TIMESTAMP DEFAULT NOW(),
+  last_seen_at TIMESTAMP DEFAULT NOW(),
+  UNIQUE (source_id, item_key)
+);
+-- name: DeleteDeadLetter :execrows
+DELETE FROM dead_letters
+WHERE id = @id;
+-- name: GetDeadLetterByID :one
+SELECT *
+FROM dead_letters
+WHERE id = @id;
+-- name: ListDeadLetters :many
+SELECT *
+FROM dead_letters
+WHERE (
+    @source_id::BIGINT = 0
+    OR source_id = @source_id::BIGINT
+  )
+ORDER BY id DESC
+LIMIT @page_limit;
+-- name: UpdateDeadLetterError :exec
+UPDATE dead_letters
+SET error = @error,
+  last_seen_at = NOW()
+WHERE id = @id;
+-- name: UpsertDeadLetter :exec
+-- An item failing again on the next collection updates its entry rather than adding one
+INSERT INTO dead_letters (source_id, item_key, link, payload, error)
+VALUES (@source_id, @item_key, @link, @payload, @error)
+ON CONFLICT (source_id, item_key) DO UPDATE
+SET payload = EXCLUDED.payload,
+  error = EXCLUDED.error,
+  occurrences = dead_letters.occurrences + 1,
+  last_seen_at = NOW();
@@ -0,0 +1,115 @@
+CREATE TABLE jobs (
+  id BIGSERIAL PRIMARY KEY,
+  type TEXT NOT NULL, -- collect, retention, cache-warm หรือ webhook-delivery
+  payload JSONB NOT NULL DEFAULT '{}',
+  status TEXT NOT NULL DEFAULT 'pending', -- pending, running, succeeded หรือ failed
+  attempts INT NOT NULL DEFAULT 0,
+  max_attempts INT NOT NULL,
+  last_error TEXT,
+  scheduled_at TIMESTAMP NOT NULL DEFAULT NOW(), -- ไม่รันก่อนเวลานี้ (retry backoff / lease ของ worker)
+  started_at TIMESTAMP,
+  finished_at TIMESTAMP,
+  created_at TIMESTAMP DEFAULT NOW(),
+  result JSONB, -- ผลของการรันครั้งล่าสุด เช่น จำนวนข่าวที่ collect ได้
+  is_unique BOOLEAN NOT NULL DEFAULT FALSE -- type ที่มี job pending ได้ทีละหนึ่งเท่านั้น
+);
+-- name: ClaimJobs :many
+-- Leases due jobs to one worker; a running job whose lease ran out is claimed again
+UPDATE jobs
+SET status = 'running',
+  attempts = jobs.attempts + 1,
+  started_at = @now::TIMESTAMP,
+  scheduled_at = @lease_until::TIMESTAMP
+WHERE jobs.id IN (
+    SELECT id
+    FROM jobs
+    WHERE jobs.status IN ('pending', 'running')
+      AND jobs.scheduled_at <= @now::TIMESTAMP
+    ORDER BY jobs.scheduled_at,
+      jobs.id
+    LIMIT @page_limit FOR UPDATE SKIP LOCKED
+  )
+RETURNING *;
+-- name: CompleteJob :execrows
+-- Settles the attempt a worker claimed; no rows when its lease was lost to another worker
+UPDATE jobs
+SET status = 'succeeded',
+  result = @result,
+  finished_at = NOW()
+WHERE id = @id
+  AND status = 'running'
+  AND attempts = @attempts;
+-- name: CreateJob :one
+-- Queues a job; a unique type that has a pending job returns that one instead, moved up to
+-- scheduled_at when it was due later
+INSERT INTO jobs (type, payload, max_attempts, scheduled_at, is_unique)
+VALUES (
+    @type,
+    @payload,
+    @max_attempts,
+    @scheduled_at::TIMESTAMP,
+    @is_unique
+  ) ON CONFLICT (type)
+WHERE status = 'pending'
+  AND is_unique DO
+UPDATE
+SET scheduled_at = LEAST(jobs.scheduled_at, EXCLUDED.scheduled_at)
+RETURNING *;
+-- name: DeleteJobsFinishedBefore :execrows
+DELETE FROM jobs
+WHERE status IN ('succeeded', 'failed')
+  AND finished_at < @finished_before::TIMESTAMP;
+-- name: ExtendJobLease :execrows
+-- Keeps a running job with its worker; no rows when the lease was lost to another worker
+UPDATE jobs
+SET scheduled_at = @lease_until::TIMESTAMP
+WHERE id = @id
+  AND status = 'running'
+  AND attempts = @attempts;
+-- name: FailJob :execrows
+UPDATE jobs
+SET status = 'failed',
+  last_error = @last_error,
+  result = @result,
+  finished_at = NOW()
+WHERE id = @id
+  AND status = 'running'
+  AND attempts = @attempts;
+-- name: GetJobByID :one
+SELECT *
+FROM jobs
+WHERE id = @id;
+-- name: ListJobs :many
+SELECT *
+FROM jobs
+WHERE (
+    @type::TEXT = ''
+    OR type = @type::TEXT
+  )
+  AND (
+    @status::TEXT = ''
+    OR status = @status::TEXT
+  )
+ORDER BY id DESC
+LIMIT @page_limit;
+-- name: RequeueJob :one
+UPDATE jobs
+SET status = 'pending',
+  attempts = 0,
+  last_error = NULL,
+  result = NULL,
+  scheduled_at = @scheduled_at::TIMESTAMP,
+  started_at = NULL,
+  finished_at = NULL
+WHERE id = @id
+  AND status = 'failed'
+RETURNING *;
+-- name: RetryJob :execrows
+UPDATE jobs
+SET status = 'pending',
+  last_error = @last_error,
+  result = @result,
+  scheduled_at = @scheduled_at::TIMESTAMP
+WHERE id = @id
+  AND status = 'running'
+  AND attempts = @attempts;
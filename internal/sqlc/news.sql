@@ -1,21 +1,33 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
 CREATE TABLE news (
-  id BIGSERIAL PRIMARY KEY,
+  id BIGSERIAL,
   title TEXT NOT NULL,
-  link TEXT NOT NULL UNIQUE,
+  link TEXT NOT NULL,
   source TEXT NOT NULL,
   image_url TEXT,
-  publish_date TIMESTAMP,
-  fetched_at TIMESTAMP DEFAULT NOW() -- เวลาเราดึงมาเก็บ
+  publish_date TIMESTAMP NOT NULL, -- partition key (รายเดือน)
+  fetched_at TIMESTAMP DEFAULT NOW(), -- เวลาเราดึงมาเก็บ
+  summary TEXT, -- สรุปข่าว 2-3 ประโยค
+  hidden BOOLEAN NOT NULL DEFAULT FALSE, -- ซ่อนโดยทีมงาน (soft delete)
+  updated_at TIMESTAMP, -- เวลาที่หัวข้อ/รูปถูกแก้ไขจากการดึงซ้ำ
+  PRIMARY KEY (id, publish_date)
+) PARTITION BY RANGE (publish_date);
+CREATE TABLE news_links (
+  link TEXT PRIMARY KEY,
+  news_id BIGINT NOT NULL,
+  publish_date TIMESTAMP NOT NULL -- ของข่าว news_id ใช้หา partition และลบตาม retention
 );
 -- name: ListNews :many
 SELECT *
 FROM news
 WHERE news.source = ANY(@sources::TEXT [])
-ORDER BY publish_date DESC
+  AND NOT news.hidden
+ORDER BY publish_date DESC,
+  id DESC
 LIMIT @page_limit OFFSET @page_offset;
--- name: RemoveNewsByPublishedDate :exec
+-- name: RemoveNewsByPublishedDate :execrows
 DELETE FROM news
-WHERE publish_date < NOW() - INTERVAL '30 days';
+WHERE publish_date < @before::TIMESTAMP;
 -- name: GetAllSource :many
 SELECT DISTINCT source
 FROM news;
@@ -25,5 +37,295 @@ WITH recv AS (
 )
 SELECT r.link::TEXT AS missing_link
 FROM recv r
-  LEFT JOIN news n ON r.link = n.link
-WHERE n.link IS NULL;
\ No newline at end of file
+  LEFT JOIN news_links l ON r.link = l.link
+WHERE l.link IS NULL;
+-- name: ListNewsOrderByPublishedAsc :many
+SELECT *
+FROM news
+WHERE news.source = ANY(@sources::TEXT [])
+  AND NOT news.hidden
+ORDER BY publish_date ASC
+LIMIT @page_limit OFFSET @page_offset;
+-- name: ListNewsOrderByFetchedAt :many
+SELECT *
+FROM news
+WHERE news.source = ANY(@sources::TEXT [])
+  AND NOT news.hidden
+ORDER BY fetched_at DESC
+LIMIT @page_limit OFFSET @page_offset;
+-- name: ListNewsOrderBySource :many
+SELECT *
+FROM news
+WHERE news.source = ANY(@sources::TEXT [])
+  AND NOT news.hidden
+ORDER BY source ASC,
+  publish_date DESC
+LIMIT @page_limit OFFSET @page_offset;
+-- name: GetNewsByID :one
+SELECT *
+FROM news
+WHERE id = @id;
+-- name: ListRelatedNewsBySource :many
+SELECT *
+FROM news
+WHERE source = @source
+  AND id <> @id
+  AND NOT hidden
+ORDER BY publish_date DESC
+LIMIT @page_limit;
+-- name: ListNewsByIDs :many
+SELECT *
+FROM news
+WHERE id = ANY(@ids::BIGINT [])
+  AND NOT hidden
+ORDER BY publish_date DESC;
+-- name: ListSimilarNews :many
+SELECT *
+FROM news
+WHERE id <> @id
+  AND NOT hidden
+  AND publish_date BETWEEN @window_start::TIMESTAMP AND @window_end::TIMESTAMP
+  AND similarity(title, @title::TEXT) >= @min_similarity::REAL
+ORDER BY similarity(title, @title::TEXT) DESC,
+  publish_date DESC
+LIMIT @page_limit;
+-- name: ListLatestNewsPerSource :many
+SELECT ranked.id,
+  ranked.title,
+  ranked.link,
+  ranked.source,
+  ranked.image_url,
+  ranked.publish_date,
+  ranked.fetched_at,
+  ranked.summary,
+  ranked.hidden,
+  ranked.updated_at
+FROM (
+    SELECT news.*,
+      ROW_NUMBER() OVER (
+        PARTITION BY news.source
+        ORDER BY news.publish_date DESC
+      ) AS rn
+    FROM news
+    WHERE news.source = ANY(@sources::TEXT [])
+      AND NOT news.hidden
+  ) ranked
+WHERE ranked.rn <= @per_source::INT
+ORDER BY ranked.source,
+  ranked.publish_date DESC;
+-- name: CountNews :one
+SELECT COUNT(*)
+FROM news
+WHERE news.source = ANY(@sources::TEXT [])
+  AND NOT news.hidden;
+-- name: ListNewsForExport :many
+SELECT *
+FROM news
+WHERE id > @after_id
+  AND publish_date >= @from_date::TIMESTAMP
+  AND publish_date < @to_date::TIMESTAMP
+  AND (
+    cardinality(@sources::TEXT []) = 0
+    OR source = ANY(@sources::TEXT [])
+  )
+ORDER BY id
+LIMIT @page_limit;
+-- name: ListRandomRecentNews :many
+SELECT *
+FROM news
+WHERE publish_date >= @since::TIMESTAMP
+  AND NOT hidden
+  AND NOT (source = ANY(@exclude_sources::TEXT []))
+ORDER BY random()
+LIMIT @page_limit;
+-- name: ListNewsMonthsBefore :many
+SELECT DISTINCT date_trunc('month', publish_date)::TIMESTAMP AS month
+FROM news
+WHERE publish_date < @before::TIMESTAMP;
+-- name: SetNewsHidden :execrows
+UPDATE news
+SET hidden = @hidden
+WHERE id = @id;
+-- name: SetNewsSummary :exec
+UPDATE news
+SET summary = @summary
+WHERE id = @id;
+-- name: InsertNewsBatch :exec
+WITH input AS (
+  SELECT DISTINCT ON (n.link) n.title,
+    n.link,
+    n.source,
+    n.image_url,
+    COALESCE(n.publish_date, NOW()) AS publish_date,
+    NULLIF(n.summary, '') AS summary
+  FROM unnest(
+      @titles::TEXT [],
+      @links::TEXT [],
+      @sources::TEXT [],
+      @image_urls::TEXT [],
+      @publish_dates::TIMESTAMP [],
+      @summaries::TEXT []
+    ) AS n(title, link, source, image_url, publish_date, summary)
+  ORDER BY n.link
+),
+claimed AS (
+  -- news can only enforce unique links per partition, news_links holds them across partitions
+  INSERT INTO news_links (link, news_id, publish_date)
+  SELECT i.link,
+    nextval('news_id_seq'),
+    i.publish_date
+  FROM input i
+  ON CONFLICT (link) DO NOTHING
+  RETURNING link,
+    news_id
+)
+INSERT INTO news (
+    id,
+    title,
+    link,
+    source,
+    image_url,
+    publish_date,
+    summary,
+    fetched_at
+  )
+SELECT c.news_id,
+  i.title,
+  i.link,
+  i.source,
+  i.image_url,
+  i.publish_date,
+  i.summary,
+  NOW()
+FROM claimed c
+  JOIN input i ON i.link = c.link;
+-- name: UpsertNewsBatch :exec
+WITH input AS (
+  SELECT DISTINCT ON (n.link) n.title,
+    n.link,
+    n.source,
+    n.image_url,
+    COALESCE(n.publish_date, NOW()) AS publish_date,
+    NULLIF(n.summary, '') AS summary
+  FROM unnest(
+      @titles::TEXT [],
+      @links::TEXT [],
+      @sources::TEXT [],
+      @image_urls::TEXT [],
+      @publish_dates::TIMESTAMP [],
+      @summaries::TEXT []
+    ) AS n(title, link, source, image_url, publish_date, summary)
+  ORDER BY n.link
+),
+refreshed AS (
+  UPDATE news
+  SET title = i.title,
+    image_url = COALESCE(NULLIF(i.image_url, ''), news.image_url),
+    updated_at = NOW()
+  FROM input i
+    JOIN news_links l ON l.link = i.link
+  WHERE news.id = l.news_id
+    AND news.publish_date = l.publish_date
+    AND (
+      news.title IS DISTINCT FROM i.title
+      OR (
+        NULLIF(i.image_url, '') IS NOT NULL
+        AND news.image_url IS DISTINCT FROM i.image_url
+      )
+    )
+),
+claimed AS (
+  -- news can only enforce unique links per partition, news_links holds them across partitions
+  INSERT INTO news_links (link, news_id, publish_date)
+  SELECT i.link,
+    nextval('news_id_seq'),
+    i.publish_date
+  FROM input i
+  ON CONFLICT (link) DO NOTHING
+  RETURNING link,
+    news_id
+)
+INSERT INTO news (
+    id,
+    title,
+    link,
+    source,
+    image_url,
+    publish_date,
+    summary,
+    fetched_at
+  )
+SELECT c.news_id,
+  i.title,
+  i.link,
+  i.source,
+  i.image_url,
+  i.publish_date,
+  i.summary,
+  NOW()
+FROM claimed c
+  JOIN input i ON i.link = c.link;
+-- name: RemoveNewsLinksByPublishedDate :execrows
+DELETE FROM news_links
+WHERE publish_date < @before::TIMESTAMP;
+-- name: ListNewsByLinks :many
+SELECT *
+FROM news
+WHERE link = ANY(@links::TEXT [])
+  AND NOT hidden
+ORDER BY publish_date DESC;
+-- name: NotifyNewsCreated :exec
+SELECT pg_notify('news_created', @payload::TEXT);
+-- name: SearchNews :many
+SELECT *
+FROM news
+WHERE title ILIKE @pattern::TEXT
+  AND (
+    cardinality(@sources::TEXT []) = 0
+    OR source = ANY(@sources::TEXT [])
+  )
+  AND NOT hidden
+ORDER BY publish_date DESC
+LIMIT @page_limit OFFSET @page_offset;
+-- name: CountSearchNews :one
+SELECT COUNT(*)
+FROM news
+WHERE title ILIKE @pattern::TEXT
+  AND (
+    cardinality(@sources::TEXT []) = 0
+    OR source = ANY(@sources::TEXT [])
+  )
+  AND NOT hidden;
+-- name: ListNewsAfterID :many
+SELECT *
+FROM news
+WHERE id > @after_id
+  AND NOT hidden
+ORDER BY id
+LIMIT @page_limit;
+-- name: ListNewsBeforeCursor :many
+-- The page of ListNews that follows the news at (publish_date, id), read by key rather than offset
+SELECT *
+FROM news
+WHERE news.source = ANY(@sources::TEXT [])
+  AND NOT news.hidden
+  AND (publish_date, id) < (@publish_date::TIMESTAMP, @id::BIGINT)
+ORDER BY publish_date DESC,
+  id DESC
+LIMIT @page_limit;
+-- name: RenameNewsSource :execrows
+UPDATE news
+SET source = @new_source
+WHERE source = @old_source;
+-- name: ListSourceNewsCounts :many
+-- Articles per source, in total and fetched since a time, busiest sources first
+SELECT source,
+  COUNT(*) AS total,
+  COUNT(*) FILTER (
+    WHERE fetched_at >= @fetched_since::TIMESTAMP
+  ) AS recent
+FROM news
+WHERE NOT hidden
+GROUP BY source
+ORDER BY total DESC,
+  source;
@@ -0,0 +1,73 @@
+CREATE TABLE news_archive (
+  id BIGINT NOT NULL,
+  title TEXT NOT NULL,
+  link TEXT NOT NULL,
+  source TEXT NOT NULL,
+  image_url TEXT,
+  publish_date TIMESTAMP,
+  fetched_at TIMESTAMP,
+  summary TEXT,
+  archived_at TIMESTAMP DEFAULT NOW(), -- เวลาที่ย้ายเข้า archive
+  hidden BOOLEAN NOT NULL DEFAULT FALSE
+) PARTITION BY RANGE (publish_date);
+-- name: ArchiveNewsPublishedBefore :execrows
+INSERT INTO news_archive (
+    id,
+    title,
+    link,
+    source,
+    image_url,
+    publish_date,
+    fetched_at,
+    summary,
+    hidden
+  )
+SELECT id,
+  title,
+  link,
+  source,
+  image_url,
+  publish_date,
+  fetched_at,
+  summary,
+  hidden
+FROM news
+WHERE publish_date < @before::TIMESTAMP;
+-- name: CountArchivedNewsBefore :one
+SELECT COUNT(*)
+FROM news_archive
+WHERE publish_date < @before::TIMESTAMP;
+-- name: ListArchivedNews :many
+SELECT *
+FROM news_archive
+WHERE source = ANY(@sources::TEXT [])
+  AND publish_date >= @from_date::TIMESTAMP
+  AND publish_date < @to_date::TIMESTAMP
+  AND NOT hidden
+ORDER BY publish_date DESC
+LIMIT @page_limit OFFSET @page_offset;
+-- name: RemoveArchivedNewsBookmarks :execrows
+-- Bookmarks, reads and tags go with the archived news removed past retention.archiveMonths
+DELETE FROM bookmarks
+WHERE news_id IN (
+    SELECT id
+    FROM news_archive
+    WHERE publish_date < @before::TIMESTAMP
+  );
+-- name: RemoveArchivedNewsByPublishedDate :execrows
+DELETE FROM news_archive
+WHERE publish_date < @before::TIMESTAMP;
+-- name: RemoveArchivedNewsReads :execrows
+DELETE FROM news_reads
+WHERE news_id IN (
+    SELECT id
+    FROM news_archive
+    WHERE publish_date < @before::TIMESTAMP
+  );
+-- name: RemoveArchivedNewsTags :execrows
+DELETE FROM news_tags
+WHERE news_id IN (
+    SELECT id
+    FROM news_archive
+    WHERE publish_date < @before::TIMESTAMP
+  );
@@ -0,0 +1,29 @@
+CREATE TABLE news_clicks (
+  news_id BIGINT NOT NULL,
+  bucket_start TIMESTAMP NOT NULL, -- ชั่วโมงที่นับคลิก (UTC)
+  clicks BIGINT NOT NULL DEFAULT 0,
+  PRIMARY KEY (news_id, bucket_start)
+);
+-- name: UpsertNewsClicks :exec
+INSERT INTO news_clicks (news_id, bucket_start, clicks)
+SELECT c.news_id,
+  @bucket_start::TIMESTAMP,
+  c.clicks
+FROM unnest(@news_ids::BIGINT [], @clicks::BIGINT []) AS c(news_id, clicks)
+  JOIN news ON news.id = c.news_id
+ON CONFLICT (news_id, bucket_start) DO UPDATE
+SET clicks = EXCLUDED.clicks;
+-- name: ListTrendingNews :many
+SELECT sqlc.embed(news),
+  SUM(news_clicks.clicks)::BIGINT AS clicks
+FROM news_clicks
+  JOIN news ON news.id = news_clicks.news_id
+WHERE news_clicks.bucket_start >= @since::TIMESTAMP
+  AND NOT news.hidden
+GROUP BY news.id
+ORDER BY clicks DESC,
+  news.publish_date DESC
+LIMIT @page_limit;
+-- name: RemoveNewsClicksBefore :exec
+DELETE FROM news_clicks
+WHERE bucket_start < @before::TIMESTAMP;
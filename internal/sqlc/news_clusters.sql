@@ -0,0 +1,70 @@
+CREATE TABLE news_clusters (
+  news_id BIGINT PRIMARY KEY,
+  cluster_id BIGINT NOT NULL, -- id ของข่าวแรกของเรื่องเดียวกัน
+  publish_date TIMESTAMP NOT NULL, -- ของข่าว news_id ใช้ลบตาม retention
+  created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+-- name: CountClusteredNews :one
+-- Counts the stories among the visible news of the sources
+SELECT COUNT(DISTINCT COALESCE(news_clusters.cluster_id, news.id))
+FROM news
+  LEFT JOIN news_clusters ON news_clusters.news_id = news.id
+WHERE news.source = ANY(@sources::TEXT [])
+  AND NOT news.hidden;
+-- name: CreateNewsClusters :exec
+INSERT INTO news_clusters (news_id, cluster_id, publish_date)
+SELECT c.news_id,
+  c.cluster_id,
+  c.publish_date
+FROM unnest(
+    @news_ids::BIGINT [],
+    @cluster_ids::BIGINT [],
+    @publish_dates::TIMESTAMP []
+  ) AS c(news_id, cluster_id, publish_date)
+ON CONFLICT (news_id) DO NOTHING;
+-- name: ListClusteredNews :many
+-- The first visible news of every story among the sources, newest first, with the number of
+-- other sources that covered it
+WITH members AS (
+  SELECT news.*,
+    COALESCE(news_clusters.cluster_id, news.id) AS cluster_id
+  FROM news
+    LEFT JOIN news_clusters ON news_clusters.news_id = news.id
+  WHERE news.source = ANY(@sources::TEXT [])
+    AND NOT news.hidden
+),
+coverage AS (
+  SELECT members.cluster_id,
+    COUNT(DISTINCT members.source) AS sources
+  FROM members
+  GROUP BY members.cluster_id
+)
+SELECT first.id,
+  first.title,
+  first.link,
+  first.source,
+  first.image_url,
+  first.publish_date,
+  first.fetched_at,
+  first.summary,
+  first.hidden,
+  first.updated_at,
+  (coverage.sources - 1)::BIGINT AS also_covered_by
+FROM (
+    SELECT DISTINCT ON (members.cluster_id) members.*
+    FROM members
+    ORDER BY members.cluster_id,
+      members.publish_date,
+      members.id
+  ) first
+  JOIN coverage ON coverage.cluster_id = first.cluster_id
+ORDER BY first.publish_date DESC,
+  first.id DESC
+LIMIT @page_limit OFFSET @page_offset;
+-- name: ListNewsClusters :many
+SELECT *
+FROM news_clusters
+WHERE news_id = ANY(@news_ids::BIGINT []);
+-- name: RemoveNewsClustersBefore :exec
+DELETE FROM news_clusters
+WHERE publish_date < @before::TIMESTAMP;
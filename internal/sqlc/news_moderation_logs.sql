@@ -0,0 +1,17 @@
+CREATE TABLE news_moderation_logs (
+  id BIGSERIAL PRIMARY KEY,
+  news_id BIGINT NOT NULL,
+  action TEXT NOT NULL, -- hide หรือ restore
+  reason TEXT,
+  actor TEXT,
+  created_at TIMESTAMP DEFAULT NOW()
+);
+-- name: CreateNewsModerationLog :one
+INSERT INTO news_moderation_logs (news_id, action, reason, actor)
+VALUES (@news_id, @action, @reason, @actor)
+RETURNING *;
+-- name: ListNewsModerationLogs :many
+SELECT *
+FROM news_moderation_logs
+WHERE news_id = @news_id
+ORDER BY created_at DESC;
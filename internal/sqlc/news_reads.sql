@@ -0,0 +1,58 @@
+CREATE TABLE news_reads (
+  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  news_id BIGINT NOT NULL, -- ไม่มี FK เพราะ news แบ่ง partition และข่าวเก่าย้ายไป archive
+  read_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (user_id, news_id)
+);
+-- name: CreateNewsReads :exec
+-- Reading an article again keeps the first read time
+INSERT INTO news_reads (user_id, news_id)
+SELECT @user_id,
+  unnest(@news_ids::BIGINT [])
+ON CONFLICT (user_id, news_id) DO NOTHING;
+-- name: DeleteNewsRead :execrows
+DELETE FROM news_reads
+WHERE user_id = @user_id
+  AND news_id = @news_id;
+-- name: ListNewsReads :many
+-- Keyset pagination on (read_at, news_id) since a batch of reads shares one read_at
+SELECT news_id,
+  read_at
+FROM news_reads
+WHERE user_id = @user_id
+  AND read_at > COALESCE(@since::TIMESTAMP, '-infinity')
+  AND (
+    @before_read_at::TIMESTAMP IS NULL
+    OR (read_at, news_id) < (@before_read_at::TIMESTAMP, @before_news_id::BIGINT)
+  )
+ORDER BY read_at DESC,
+  news_id DESC
+LIMIT @page_limit;
+-- name: ListReadNewsIDs :many
+SELECT news_id
+FROM news_reads
+WHERE user_id = @user_id
+  AND news_id = ANY(@news_ids::BIGINT []);
+-- name: ListReadSourceCounts :many
+-- How the reader's latest reads spread over sources, for personalized ranking
+SELECT news.source,
+  COUNT(*) AS reads
+FROM (
+    SELECT news_id
+    FROM news_reads
+    WHERE user_id = @user_id
+    ORDER BY read_at DESC
+    LIMIT @page_limit
+  ) AS recent
+  JOIN news ON news.id = recent.news_id
+GROUP BY news.source;
+-- name: MergeNewsReads :exec
+-- Articles read on both profiles keep the earlier read time
+INSERT INTO news_reads (user_id, news_id, read_at)
+SELECT @to_user_id,
+  news_id,
+  read_at
+FROM news_reads
+WHERE user_id = @from_user_id
+ON CONFLICT (user_id, news_id) DO UPDATE
+SET read_at = LEAST(news_reads.read_at, EXCLUDED.read_at);
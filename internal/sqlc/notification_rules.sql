@@ -0,0 +1,45 @@
+CREATE TABLE notification_rules (
+  id BIGSERIAL PRIMARY KEY,
+  name TEXT NOT NULL,
+  channel TEXT NOT NULL, -- line_notify, line_messaging, telegram หรือ discord
+  target TEXT NOT NULL DEFAULT '', -- token ของ LINE Notify, id ผู้รับ (ว่าง = broadcast), chat id หรือ webhook URL
+  keywords TEXT[] NOT NULL DEFAULT '{}', -- ว่าง = ไม่กรองคำ
+  sources TEXT[] NOT NULL DEFAULT '{}', -- ว่าง = ทุก source
+  disabled BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at TIMESTAMP DEFAULT NOW(),
+  updated_at TIMESTAMP,
+  tags TEXT[] NOT NULL DEFAULT '{}', -- ว่าง = ไม่กรอง tag
+  template TEXT NOT NULL DEFAULT '' -- ว่าง = รูปแบบข้อความเริ่มต้น
+);
+-- name: CreateNotificationRule :one
+INSERT INTO notification_rules (name, channel, target, keywords, sources, tags, template)
+VALUES (@name, @channel, @target, @keywords, @sources, @tags, @template)
+RETURNING *;
+-- name: DeleteNotificationRule :execrows
+DELETE FROM notification_rules
+WHERE id = @id;
+-- name: GetNotificationRuleByID :one
+SELECT *
+FROM notification_rules
+WHERE id = @id;
+-- name: ListEnabledNotificationRules :many
+SELECT *
+FROM notification_rules
+WHERE NOT disabled
+ORDER BY id;
+-- name: ListNotificationRules :many
+SELECT *
+FROM notification_rules
+ORDER BY id;
+-- name: UpdateNotificationRule :one
+UPDATE notification_rules
+SET name = @name,
+  target = @target,
+  keywords = @keywords,
+  sources = @sources,
+  tags = @tags,
+  template = @template,
+  disabled = @disabled,
+  updated_at = NOW()
+WHERE id = @id
+RETURNING *;
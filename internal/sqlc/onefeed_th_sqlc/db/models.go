@@ -8,6 +8,60 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type ApiKey struct {
+	ID        int64            `json:"id"`
+	Name      string           `json:"name"`
+	KeyPrefix string           `json:"key_prefix"`
+	KeyHash   string           `json:"key_hash"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+	RevokedAt pgtype.Timestamp `json:"revoked_at"`
+	Role      string           `json:"role"`
+}
+
+type BackofficeUser struct {
+	ID           int64            `json:"id"`
+	Username     string           `json:"username"`
+	PasswordHash string           `json:"password_hash"`
+	Role         string           `json:"role"`
+	Disabled     bool             `json:"disabled"`
+	CreatedAt    pgtype.Timestamp `json:"created_at"`
+	UpdatedAt    pgtype.Timestamp `json:"updated_at"`
+}
+
+type Bookmark struct {
+	UserID    int64            `json:"user_id"`
+	NewsID    int64            `json:"news_id"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+type DeadLetter struct {
+	ID          int64            `json:"id"`
+	SourceID    int64            `json:"source_id"`
+	ItemKey     string           `json:"item_key"`
+	Link        pgtype.Text      `json:"link"`
+	Payload     []byte           `json:"payload"`
+	Error       string           `json:"error"`
+	Occurrences int32            `json:"occurrences"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+	LastSeenAt  pgtype.Timestamp `json:"last_seen_at"`
+}
+
+type Job struct {
+	ID          int64            `json:"id"`
+	Type        string           `json:"type"`
+	Payload     []byte           `json:"payload"`
+	Status      string           `json:"status"`
+	Attempts    int32            `json:"attempts"`
+	MaxAttempts int32            `json:"max_attempts"`
+	LastError   pgtype.Text      `json:"last_error"`
+	ScheduledAt pgtype.Timestamp `json:"scheduled_at"`
+	StartedAt   pgtype.Timestamp `json:"started_at"`
+	FinishedAt  pgtype.Timestamp `json:"finished_at"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+	Result      []byte           `json:"result"`
+	IsUnique    bool             `json:"is_unique"`
+}
+
 type News struct {
 	ID          int64            `json:"id"`
 	Title       string           `json:"title"`
@@ -16,6 +70,65 @@ type News struct {
 	ImageUrl    pgtype.Text      `json:"image_url"`
 	PublishDate pgtype.Timestamp `json:"publish_date"`
 	FetchedAt   pgtype.Timestamp `json:"fetched_at"`
+	Summary     pgtype.Text      `json:"summary"`
+	Hidden      bool             `json:"hidden"`
+	UpdatedAt   pgtype.Timestamp `json:"updated_at"`
+}
+
+type NewsArchive struct {
+	ID          int64            `json:"id"`
+	Title       string           `json:"title"`
+	Link        string           `json:"link"`
+	Source      string           `json:"source"`
+	ImageUrl    pgtype.Text      `json:"image_url"`
+	PublishDate pgtype.Timestamp `json:"publish_date"`
+	FetchedAt   pgtype.Timestamp `json:"fetched_at"`
+	Summary     pgtype.Text      `json:"summary"`
+	ArchivedAt  pgtype.Timestamp `json:"archived_at"`
+	Hidden      bool             `json:"hidden"`
+}
+
+type NewsClick struct {
+	NewsID      int64            `json:"news_id"`
+	BucketStart pgtype.Timestamp `json:"bucket_start"`
+	Clicks      int64            `json:"clicks"`
+}
+
+type NewsClicksDaily struct {
+	NewsID   int64            `json:"news_id"`
+	Day      pgtype.Timestamp `json:"day"`
+	SourceID int64            `json:"source_id"`
+	Title    string           `json:"title"`
+	Link     string           `json:"link"`
+	Clicks   int64            `json:"clicks"`
+}
+
+type NewsCluster struct {
+	NewsID      int64            `json:"news_id"`
+	ClusterID   int64            `json:"cluster_id"`
+	PublishDate pgtype.Timestamp `json:"publish_date"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+}
+
+type NewsLink struct {
+	Link        string           `json:"link"`
+	NewsID      int64            `json:"news_id"`
+	PublishDate pgtype.Timestamp `json:"publish_date"`
+}
+
+type NewsModerationLog struct {
+	ID        int64            `json:"id"`
+	NewsID    int64            `json:"news_id"`
+	Action    string           `json:"action"`
+	Reason    pgtype.Text      `json:"reason"`
+	Actor     pgtype.Text      `json:"actor"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+type NewsRead struct {
+	UserID int64            `json:"user_id"`
+	NewsID int64            `json:"news_id"`
+	ReadAt pgtype.Timestamp `json:"read_at"`
 }
 
 type NewsTag struct {
@@ -23,15 +136,144 @@ type NewsTag struct {
 	TagID  int32 `json:"tag_id"`
 }
 
-type Source struct {
+type NotificationRule struct {
 	ID        int64            `json:"id"`
 	Name      string           `json:"name"`
-	Tags      pgtype.Text      `json:"tags"`
-	RssUrl    pgtype.Text      `json:"rss_url"`
+	Channel   string           `json:"channel"`
+	Target    string           `json:"target"`
+	Keywords  []string         `json:"keywords"`
+	Sources   []string         `json:"sources"`
+	Disabled  bool             `json:"disabled"`
 	CreatedAt pgtype.Timestamp `json:"created_at"`
+	UpdatedAt pgtype.Timestamp `json:"updated_at"`
+	Tags      []string         `json:"tags"`
+	Template  string           `json:"template"`
+}
+
+type PushDevice struct {
+	ID        int64            `json:"id"`
+	Token     string           `json:"token"`
+	Platform  string           `json:"platform"`
+	Sources   []string         `json:"sources"`
+	Keywords  []string         `json:"keywords"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+	UpdatedAt pgtype.Timestamp `json:"updated_at"`
+}
+
+type PushJob struct {
+	ID        int64            `json:"id"`
+	DeviceID  int64            `json:"device_id"`
+	NewsID    int64            `json:"news_id"`
+	Attempts  int32            `json:"attempts"`
+	LastError pgtype.Text      `json:"last_error"`
+	RunAt     pgtype.Timestamp `json:"run_at"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+type SavedSearch struct {
+	ID         int64            `json:"id"`
+	UserID     int64            `json:"user_id"`
+	Name       string           `json:"name"`
+	Query      string           `json:"query"`
+	Sources    []string         `json:"sources"`
+	PushToken  pgtype.Text      `json:"push_token"`
+	LineUserID pgtype.Text      `json:"line_user_id"`
+	NotifiedAt pgtype.Timestamp `json:"notified_at"`
+	CreatedAt  pgtype.Timestamp `json:"created_at"`
+	UpdatedAt  pgtype.Timestamp `json:"updated_at"`
+}
+
+type Source struct {
+	ID            int64            `json:"id"`
+	Name          string           `json:"name"`
+	Tags          pgtype.Text      `json:"tags"`
+	RssUrl        pgtype.Text      `json:"rss_url"`
+	CreatedAt     pgtype.Timestamp `json:"created_at"`
+	Enabled       bool             `json:"enabled"`
+	DeletedAt     pgtype.Timestamp `json:"deleted_at"`
+	ImageSelector pgtype.Text      `json:"image_selector"`
+	ImageFields   []string         `json:"image_fields"`
+}
+
+type SourceStatsDaily struct {
+	SourceID    int64            `json:"source_id"`
+	Day         pgtype.Timestamp `json:"day"`
+	Articles    int64            `json:"articles"`
+	Impressions int64            `json:"impressions"`
+	Clicks      int64            `json:"clicks"`
+}
+
+type SourceSuggestion struct {
+	ID           int64            `json:"id"`
+	UserID       int64            `json:"user_id"`
+	Name         string           `json:"name"`
+	Tags         pgtype.Text      `json:"tags"`
+	RssUrl       string           `json:"rss_url"`
+	Note         string           `json:"note"`
+	PushToken    pgtype.Text      `json:"push_token"`
+	Status       string           `json:"status"`
+	ReviewReason pgtype.Text      `json:"review_reason"`
+	ReviewedBy   pgtype.Text      `json:"reviewed_by"`
+	ReviewedAt   pgtype.Timestamp `json:"reviewed_at"`
+	SourceID     pgtype.Int8      `json:"source_id"`
+	CreatedAt    pgtype.Timestamp `json:"created_at"`
+}
+
+type SourceTag struct {
+	SourceID int64 `json:"source_id"`
+	TagID    int32 `json:"tag_id"`
 }
 
 type Tag struct {
-	ID   int32  `json:"id"`
-	Name string `json:"name"`
+	ID          int32            `json:"id"`
+	Name        string           `json:"name"`
+	Description pgtype.Text      `json:"description"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+}
+
+type User struct {
+	ID           int64            `json:"id"`
+	Email        pgtype.Text      `json:"email"`
+	PasswordHash pgtype.Text      `json:"password_hash"`
+	DisplayName  string           `json:"display_name"`
+	LastLoginAt  pgtype.Timestamp `json:"last_login_at"`
+	CreatedAt    pgtype.Timestamp `json:"created_at"`
+	UpdatedAt    pgtype.Timestamp `json:"updated_at"`
+	DeviceID     pgtype.Text      `json:"device_id"`
+}
+
+type UserIdentity struct {
+	Provider  string           `json:"provider"`
+	Subject   string           `json:"subject"`
+	UserID    int64            `json:"user_id"`
+	Email     pgtype.Text      `json:"email"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+type UserPreference struct {
+	UserID    int64            `json:"user_id"`
+	Sources   []string         `json:"sources"`
+	Tags      []string         `json:"tags"`
+	UpdatedAt pgtype.Timestamp `json:"updated_at"`
+}
+
+type Webhook struct {
+	ID        int64            `json:"id"`
+	Url       string           `json:"url"`
+	Secret    string           `json:"secret"`
+	Sources   []string         `json:"sources"`
+	Tags      []string         `json:"tags"`
+	Disabled  bool             `json:"disabled"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+	UpdatedAt pgtype.Timestamp `json:"updated_at"`
+}
+
+type WebhookFailure struct {
+	ID         int64            `json:"id"`
+	WebhookID  int64            `json:"webhook_id"`
+	Payload    []byte           `json:"payload"`
+	Attempts   int32            `json:"attempts"`
+	StatusCode pgtype.Int4      `json:"status_code"`
+	Error      string           `json:"error"`
+	CreatedAt  pgtype.Timestamp `json:"created_at"`
 }
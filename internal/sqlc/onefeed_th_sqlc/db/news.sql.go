@@ -7,16 +7,55 @@ package onefeed_th_sqlc
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const countNews = `-- name: CountNews :one
+SELECT COUNT(*)
+FROM news
+WHERE news.source = ANY($1::TEXT [])
+  AND NOT news.hidden
+`
+
+func (q *Queries) CountNews(ctx context.Context, sources []string) (int64, error) {
+	row := q.db.QueryRow(ctx, countNews, sources)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countSearchNews = `-- name: CountSearchNews :one
+SELECT COUNT(*)
+FROM news
+WHERE title ILIKE $1::TEXT
+  AND (
+    cardinality($2::TEXT []) = 0
+    OR source = ANY($2::TEXT [])
+  )
+  AND NOT hidden
+`
+
+type CountSearchNewsParams struct {
+	Pattern string   `json:"pattern"`
+	Sources []string `json:"sources"`
+}
+
+func (q *Queries) CountSearchNews(ctx context.Context, arg CountSearchNewsParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countSearchNews, arg.Pattern, arg.Sources)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const getAllMissingLinks = `-- name: GetAllMissingLinks :many
 WITH recv AS (
   SELECT unnest($1::TEXT []) AS link
 )
 SELECT r.link::TEXT AS missing_link
 FROM recv r
-  LEFT JOIN news n ON r.link = n.link
-WHERE n.link IS NULL
+  LEFT JOIN news_links l ON r.link = l.link
+WHERE l.link IS NULL
 `
 
 func (q *Queries) GetAllMissingLinks(ctx context.Context, links []string) ([]string, error) {
@@ -64,11 +103,184 @@ func (q *Queries) GetAllSource(ctx context.Context) ([]string, error) {
 	return items, nil
 }
 
+const getNewsByID = `-- name: GetNewsByID :one
+SELECT id, title, link, source, image_url, publish_date, fetched_at, summary, hidden, updated_at
+FROM news
+WHERE id = $1
+`
+
+func (q *Queries) GetNewsByID(ctx context.Context, id int64) (News, error) {
+	row := q.db.QueryRow(ctx, getNewsByID, id)
+	var i News
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Link,
+		&i.Source,
+		&i.ImageUrl,
+		&i.PublishDate,
+		&i.FetchedAt,
+		&i.Summary,
+		&i.Hidden,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const insertNewsBatch = `-- name: InsertNewsBatch :exec
+WITH input AS (
+  SELECT DISTINCT ON (n.link) n.title,
+    n.link,
+    n.source,
+    n.image_url,
+    COALESCE(n.publish_date, NOW()) AS publish_date,
+    NULLIF(n.summary, '') AS summary
+  FROM unnest(
+      $1::TEXT [],
+      $2::TEXT [],
+      $3::TEXT [],
+      $4::TEXT [],
+      $5::TIMESTAMP [],
+      $6::TEXT []
+    ) AS n(title, link, source, image_url, publish_date, summary)
+  ORDER BY n.link
+),
+claimed AS (
+  -- news can only enforce unique links per partition, news_links holds them across partitions
+  INSERT INTO news_links (link, news_id, publish_date)
+  SELECT i.link,
+    nextval('news_id_seq'),
+    i.publish_date
+  FROM input i
+  ON CONFLICT (link) DO NOTHING
+  RETURNING link,
+    news_id
+)
+INSERT INTO news (
+    id,
+    title,
+    link,
+    source,
+    image_url,
+    publish_date,
+    summary,
+    fetched_at
+  )
+SELECT c.news_id,
+  i.title,
+  i.link,
+  i.source,
+  i.image_url,
+  i.publish_date,
+  i.summary,
+  NOW()
+FROM claimed c
+  JOIN input i ON i.link = c.link
+`
+
+type InsertNewsBatchParams struct {
+	Titles       []string           `json:"titles"`
+	Links        []string           `json:"links"`
+	Sources      []string           `json:"sources"`
+	ImageUrls    []string           `json:"image_urls"`
+	PublishDates []pgtype.Timestamp `json:"publish_dates"`
+	Summaries    []string           `json:"summaries"`
+}
+
+func (q *Queries) InsertNewsBatch(ctx context.Context, arg InsertNewsBatchParams) error {
+	_, err := q.db.Exec(ctx, insertNewsBatch,
+		arg.Titles,
+		arg.Links,
+		arg.Sources,
+		arg.ImageUrls,
+		arg.PublishDates,
+		arg.Summaries,
+	)
+	return err
+}
+
+const listLatestNewsPerSource = `-- name: ListLatestNewsPerSource :many
+SELECT ranked.id,
+  ranked.title,
+  ranked.link,
+  ranked.source,
+  ranked.image_url,
+  ranked.publish_date,
+  ranked.fetched_at,
+  ranked.summary,
+  ranked.hidden,
+  ranked.updated_at
+FROM (
+    SELECT news.id, news.title, news.link, news.source, news.image_url, news.publish_date, news.fetched_at, news.summary, news.hidden, news.updated_at,
+      ROW_NUMBER() OVER (
+        PARTITION BY news.source
+        ORDER BY news.publish_date DESC
+      ) AS rn
+    FROM news
+    WHERE news.source = ANY($1::TEXT [])
+      AND NOT news.hidden
+  ) ranked
+WHERE ranked.rn <= $2::INT
+ORDER BY ranked.source,
+  ranked.publish_date DESC
+`
+
+type ListLatestNewsPerSourceParams struct {
+	Sources   []string `json:"sources"`
+	PerSource int32    `json:"per_source"`
+}
+
+type ListLatestNewsPerSourceRow struct {
+	ID          int64            `json:"id"`
+	Title       string           `json:"title"`
+	Link        string           `json:"link"`
+	Source      string           `json:"source"`
+	ImageUrl    pgtype.Text      `json:"image_url"`
+	PublishDate pgtype.Timestamp `json:"publish_date"`
+	FetchedAt   pgtype.Timestamp `json:"fetched_at"`
+	Summary     pgtype.Text      `json:"summary"`
+	Hidden      bool             `json:"hidden"`
+	UpdatedAt   pgtype.Timestamp `json:"updated_at"`
+}
+
+func (q *Queries) ListLatestNewsPerSource(ctx context.Context, arg ListLatestNewsPerSourceParams) ([]ListLatestNewsPerSourceRow, error) {
+	rows, err := q.db.Query(ctx, listLatestNewsPerSource, arg.Sources, arg.PerSource)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListLatestNewsPerSourceRow
+	for rows.Next() {
+		var i ListLatestNewsPerSourceRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Link,
+			&i.Source,
+			&i.ImageUrl,
+			&i.PublishDate,
+			&i.FetchedAt,
+			&i.Summary,
+			&i.Hidden,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listNews = `-- name: ListNews :many
-SELECT id, title, link, source, image_url, publish_date, fetched_at
+SELECT id, title, link, source, image_url, publish_date, fetched_at, summary, hidden, updated_at
 FROM news
 WHERE news.source = ANY($1::TEXT [])
-ORDER BY publish_date DESC
+  AND NOT news.hidden
+ORDER BY publish_date DESC,
+  id DESC
 LIMIT $3 OFFSET $2
 `
 
@@ -95,6 +307,9 @@ func (q *Queries) ListNews(ctx context.Context, arg ListNewsParams) ([]News, err
 			&i.ImageUrl,
 			&i.PublishDate,
 			&i.FetchedAt,
+			&i.Summary,
+			&i.Hidden,
+			&i.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -106,12 +321,831 @@ func (q *Queries) ListNews(ctx context.Context, arg ListNewsParams) ([]News, err
 	return items, nil
 }
 
-const removeNewsByPublishedDate = `-- name: RemoveNewsByPublishedDate :exec
-DELETE FROM news
-WHERE publish_date < NOW() - INTERVAL '30 days'
+const listNewsAfterID = `-- name: ListNewsAfterID :many
+SELECT id, title, link, source, image_url, publish_date, fetched_at, summary, hidden, updated_at
+FROM news
+WHERE id > $1
+  AND NOT hidden
+ORDER BY id
+LIMIT $2
+`
+
+type ListNewsAfterIDParams struct {
+	AfterID   int64 `json:"after_id"`
+	PageLimit int32 `json:"page_limit"`
+}
+
+func (q *Queries) ListNewsAfterID(ctx context.Context, arg ListNewsAfterIDParams) ([]News, error) {
+	rows, err := q.db.Query(ctx, listNewsAfterID, arg.AfterID, arg.PageLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []News
+	for rows.Next() {
+		var i News
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Link,
+			&i.Source,
+			&i.ImageUrl,
+			&i.PublishDate,
+			&i.FetchedAt,
+			&i.Summary,
+			&i.Hidden,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listNewsBeforeCursor = `-- name: ListNewsBeforeCursor :many
+SELECT id, title, link, source, image_url, publish_date, fetched_at, summary, hidden, updated_at
+FROM news
+WHERE news.source = ANY($1::TEXT [])
+  AND NOT news.hidden
+  AND (publish_date, id) < ($2::TIMESTAMP, $3::BIGINT)
+ORDER BY publish_date DESC,
+  id DESC
+LIMIT $4
+`
+
+type ListNewsBeforeCursorParams struct {
+	Sources     []string         `json:"sources"`
+	PublishDate pgtype.Timestamp `json:"publish_date"`
+	ID          int64            `json:"id"`
+	PageLimit   int32            `json:"page_limit"`
+}
+
+// The page of ListNews that follows the news at (publish_date, id), read by key rather than offset
+func (q *Queries) ListNewsBeforeCursor(ctx context.Context, arg ListNewsBeforeCursorParams) ([]News, error) {
+	rows, err := q.db.Query(ctx, listNewsBeforeCursor,
+		arg.Sources,
+		arg.PublishDate,
+		arg.ID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []News
+	for rows.Next() {
+		var i News
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Link,
+			&i.Source,
+			&i.ImageUrl,
+			&i.PublishDate,
+			&i.FetchedAt,
+			&i.Summary,
+			&i.Hidden,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listNewsByIDs = `-- name: ListNewsByIDs :many
+SELECT id, title, link, source, image_url, publish_date, fetched_at, summary, hidden, updated_at
+FROM news
+WHERE id = ANY($1::BIGINT [])
+  AND NOT hidden
+ORDER BY publish_date DESC
+`
+
+func (q *Queries) ListNewsByIDs(ctx context.Context, ids []int64) ([]News, error) {
+	rows, err := q.db.Query(ctx, listNewsByIDs, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []News
+	for rows.Next() {
+		var i News
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Link,
+			&i.Source,
+			&i.ImageUrl,
+			&i.PublishDate,
+			&i.FetchedAt,
+			&i.Summary,
+			&i.Hidden,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listNewsByLinks = `-- name: ListNewsByLinks :many
+SELECT id, title, link, source, image_url, publish_date, fetched_at, summary, hidden, updated_at
+FROM news
+WHERE link = ANY($1::TEXT [])
+  AND NOT hidden
+ORDER BY publish_date DESC
+`
+
+func (q *Queries) ListNewsByLinks(ctx context.Context, links []string) ([]News, error) {
+	rows, err := q.db.Query(ctx, listNewsByLinks, links)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []News
+	for rows.Next() {
+		var i News
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Link,
+			&i.Source,
+			&i.ImageUrl,
+			&i.PublishDate,
+			&i.FetchedAt,
+			&i.Summary,
+			&i.Hidden,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listNewsForExport = `-- name: ListNewsForExport :many
+SELECT id, title, link, source, image_url, publish_date, fetched_at, summary, hidden, updated_at
+FROM news
+WHERE id > $1
+  AND publish_date >= $2::TIMESTAMP
+  AND publish_date < $3::TIMESTAMP
+  AND (
+    cardinality($4::TEXT []) = 0
+    OR source = ANY($4::TEXT [])
+  )
+ORDER BY id
+LIMIT $5
+`
+
+type ListNewsForExportParams struct {
+	AfterID   int64            `json:"after_id"`
+	FromDate  pgtype.Timestamp `json:"from_date"`
+	ToDate    pgtype.Timestamp `json:"to_date"`
+	Sources   []string         `json:"sources"`
+	PageLimit int32            `json:"page_limit"`
+}
+
+func (q *Queries) ListNewsForExport(ctx context.Context, arg ListNewsForExportParams) ([]News, error) {
+	rows, err := q.db.Query(ctx, listNewsForExport,
+		arg.AfterID,
+		arg.FromDate,
+		arg.ToDate,
+		arg.Sources,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []News
+	for rows.Next() {
+		var i News
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Link,
+			&i.Source,
+			&i.ImageUrl,
+			&i.PublishDate,
+			&i.FetchedAt,
+			&i.Summary,
+			&i.Hidden,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listNewsMonthsBefore = `-- name: ListNewsMonthsBefore :many
+SELECT DISTINCT date_trunc('month', publish_date)::TIMESTAMP AS month
+FROM news
+WHERE publish_date < $1::TIMESTAMP
+`
+
+func (q *Queries) ListNewsMonthsBefore(ctx context.Context, before pgtype.Timestamp) ([]pgtype.Timestamp, error) {
+	rows, err := q.db.Query(ctx, listNewsMonthsBefore, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []pgtype.Timestamp
+	for rows.Next() {
+		var month pgtype.Timestamp
+		if err := rows.Scan(&month); err != nil {
+			return nil, err
+		}
+		items = append(items, month)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listNewsOrderByFetchedAt = `-- name: ListNewsOrderByFetchedAt :many
+SELECT id, title, link, source, image_url, publish_date, fetched_at, summary, hidden, updated_at
+FROM news
+WHERE news.source = ANY($1::TEXT [])
+  AND NOT news.hidden
+ORDER BY fetched_at DESC
+LIMIT $3 OFFSET $2
 `
 
-func (q *Queries) RemoveNewsByPublishedDate(ctx context.Context) error {
-	_, err := q.db.Exec(ctx, removeNewsByPublishedDate)
+type ListNewsOrderByFetchedAtParams struct {
+	Sources    []string `json:"sources"`
+	PageOffset int32    `json:"page_offset"`
+	PageLimit  int32    `json:"page_limit"`
+}
+
+func (q *Queries) ListNewsOrderByFetchedAt(ctx context.Context, arg ListNewsOrderByFetchedAtParams) ([]News, error) {
+	rows, err := q.db.Query(ctx, listNewsOrderByFetchedAt, arg.Sources, arg.PageOffset, arg.PageLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []News
+	for rows.Next() {
+		var i News
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Link,
+			&i.Source,
+			&i.ImageUrl,
+			&i.PublishDate,
+			&i.FetchedAt,
+			&i.Summary,
+			&i.Hidden,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listNewsOrderByPublishedAsc = `-- name: ListNewsOrderByPublishedAsc :many
+SELECT id, title, link, source, image_url, publish_date, fetched_at, summary, hidden, updated_at
+FROM news
+WHERE news.source = ANY($1::TEXT [])
+  AND NOT news.hidden
+ORDER BY publish_date ASC
+LIMIT $3 OFFSET $2
+`
+
+type ListNewsOrderByPublishedAscParams struct {
+	Sources    []string `json:"sources"`
+	PageOffset int32    `json:"page_offset"`
+	PageLimit  int32    `json:"page_limit"`
+}
+
+func (q *Queries) ListNewsOrderByPublishedAsc(ctx context.Context, arg ListNewsOrderByPublishedAscParams) ([]News, error) {
+	rows, err := q.db.Query(ctx, listNewsOrderByPublishedAsc, arg.Sources, arg.PageOffset, arg.PageLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []News
+	for rows.Next() {
+		var i News
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Link,
+			&i.Source,
+			&i.ImageUrl,
+			&i.PublishDate,
+			&i.FetchedAt,
+			&i.Summary,
+			&i.Hidden,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listNewsOrderBySource = `-- name: ListNewsOrderBySource :many
+SELECT id, title, link, source, image_url, publish_date, fetched_at, summary, hidden, updated_at
+FROM news
+WHERE news.source = ANY($1::TEXT [])
+  AND NOT news.hidden
+ORDER BY source ASC,
+  publish_date DESC
+LIMIT $3 OFFSET $2
+`
+
+type ListNewsOrderBySourceParams struct {
+	Sources    []string `json:"sources"`
+	PageOffset int32    `json:"page_offset"`
+	PageLimit  int32    `json:"page_limit"`
+}
+
+func (q *Queries) ListNewsOrderBySource(ctx context.Context, arg ListNewsOrderBySourceParams) ([]News, error) {
+	rows, err := q.db.Query(ctx, listNewsOrderBySource, arg.Sources, arg.PageOffset, arg.PageLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []News
+	for rows.Next() {
+		var i News
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Link,
+			&i.Source,
+			&i.ImageUrl,
+			&i.PublishDate,
+			&i.FetchedAt,
+			&i.Summary,
+			&i.Hidden,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRandomRecentNews = `-- name: ListRandomRecentNews :many
+SELECT id, title, link, source, image_url, publish_date, fetched_at, summary, hidden, updated_at
+FROM news
+WHERE publish_date >= $1::TIMESTAMP
+  AND NOT hidden
+  AND NOT (source = ANY($2::TEXT []))
+ORDER BY random()
+LIMIT $3
+`
+
+type ListRandomRecentNewsParams struct {
+	Since          pgtype.Timestamp `json:"since"`
+	ExcludeSources []string         `json:"exclude_sources"`
+	PageLimit      int32            `json:"page_limit"`
+}
+
+func (q *Queries) ListRandomRecentNews(ctx context.Context, arg ListRandomRecentNewsParams) ([]News, error) {
+	rows, err := q.db.Query(ctx, listRandomRecentNews, arg.Since, arg.ExcludeSources, arg.PageLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []News
+	for rows.Next() {
+		var i News
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Link,
+			&i.Source,
+			&i.ImageUrl,
+			&i.PublishDate,
+			&i.FetchedAt,
+			&i.Summary,
+			&i.Hidden,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRelatedNewsBySource = `-- name: ListRelatedNewsBySource :many
+SELECT id, title, link, source, image_url, publish_date, fetched_at, summary, hidden, updated_at
+FROM news
+WHERE source = $1
+  AND id <> $2
+  AND NOT hidden
+ORDER BY publish_date DESC
+LIMIT $3
+`
+
+type ListRelatedNewsBySourceParams struct {
+	Source    string `json:"source"`
+	ID        int64  `json:"id"`
+	PageLimit int32  `json:"page_limit"`
+}
+
+func (q *Queries) ListRelatedNewsBySource(ctx context.Context, arg ListRelatedNewsBySourceParams) ([]News, error) {
+	rows, err := q.db.Query(ctx, listRelatedNewsBySource, arg.Source, arg.ID, arg.PageLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []News
+	for rows.Next() {
+		var i News
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Link,
+			&i.Source,
+			&i.ImageUrl,
+			&i.PublishDate,
+			&i.FetchedAt,
+			&i.Summary,
+			&i.Hidden,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSimilarNews = `-- name: ListSimilarNews :many
+SELECT id, title, link, source, image_url, publish_date, fetched_at, summary, hidden, updated_at
+FROM news
+WHERE id <> $1
+  AND NOT hidden
+  AND publish_date BETWEEN $2::TIMESTAMP AND $3::TIMESTAMP
+  AND similarity(title, $4::TEXT) >= $5::REAL
+ORDER BY similarity(title, $4::TEXT) DESC,
+  publish_date DESC
+LIMIT $6
+`
+
+type ListSimilarNewsParams struct {
+	ID            int64            `json:"id"`
+	WindowStart   pgtype.Timestamp `json:"window_start"`
+	WindowEnd     pgtype.Timestamp `json:"window_end"`
+	Title         string           `json:"title"`
+	MinSimilarity float32          `json:"min_similarity"`
+	PageLimit     int32            `json:"page_limit"`
+}
+
+func (q *Queries) ListSimilarNews(ctx context.Context, arg ListSimilarNewsParams) ([]News, error) {
+	rows, err := q.db.Query(ctx, listSimilarNews,
+		arg.ID,
+		arg.WindowStart,
+		arg.WindowEnd,
+		arg.Title,
+		arg.MinSimilarity,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []News
+	for rows.Next() {
+		var i News
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Link,
+			&i.Source,
+			&i.ImageUrl,
+			&i.PublishDate,
+			&i.FetchedAt,
+			&i.Summary,
+			&i.Hidden,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSourceNewsCounts = `-- name: ListSourceNewsCounts :many
+SELECT source,
+  COUNT(*) AS total,
+  COUNT(*) FILTER (
+    WHERE fetched_at >= $1::TIMESTAMP
+  ) AS recent
+FROM news
+WHERE NOT hidden
+GROUP BY source
+ORDER BY total DESC,
+  source
+`
+
+type ListSourceNewsCountsRow struct {
+	Source string `json:"source"`
+	Total  int64  `json:"total"`
+	Recent int64  `json:"recent"`
+}
+
+// Articles per source, in total and fetched since a time, busiest sources first
+func (q *Queries) ListSourceNewsCounts(ctx context.Context, fetchedSince pgtype.Timestamp) ([]ListSourceNewsCountsRow, error) {
+	rows, err := q.db.Query(ctx, listSourceNewsCounts, fetchedSince)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListSourceNewsCountsRow
+	for rows.Next() {
+		var i ListSourceNewsCountsRow
+		if err := rows.Scan(&i.Source, &i.Total, &i.Recent); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const notifyNewsCreated = `-- name: NotifyNewsCreated :exec
+SELECT pg_notify('news_created', $1::TEXT)
+`
+
+func (q *Queries) NotifyNewsCreated(ctx context.Context, payload string) error {
+	_, err := q.db.Exec(ctx, notifyNewsCreated, payload)
+	return err
+}
+
+const removeNewsByPublishedDate = `-- name: RemoveNewsByPublishedDate :execrows
+DELETE FROM news
+WHERE publish_date < $1::TIMESTAMP
+`
+
+func (q *Queries) RemoveNewsByPublishedDate(ctx context.Context, before pgtype.Timestamp) (int64, error) {
+	result, err := q.db.Exec(ctx, removeNewsByPublishedDate, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const removeNewsLinksByPublishedDate = `-- name: RemoveNewsLinksByPublishedDate :execrows
+DELETE FROM news_links
+WHERE publish_date < $1::TIMESTAMP
+`
+
+func (q *Queries) RemoveNewsLinksByPublishedDate(ctx context.Context, before pgtype.Timestamp) (int64, error) {
+	result, err := q.db.Exec(ctx, removeNewsLinksByPublishedDate, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const renameNewsSource = `-- name: RenameNewsSource :execrows
+UPDATE news
+SET source = $1
+WHERE source = $2
+`
+
+type RenameNewsSourceParams struct {
+	NewSource string `json:"new_source"`
+	OldSource string `json:"old_source"`
+}
+
+func (q *Queries) RenameNewsSource(ctx context.Context, arg RenameNewsSourceParams) (int64, error) {
+	result, err := q.db.Exec(ctx, renameNewsSource, arg.NewSource, arg.OldSource)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const searchNews = `-- name: SearchNews :many
+SELECT id, title, link, source, image_url, publish_date, fetched_at, summary, hidden, updated_at
+FROM news
+WHERE title ILIKE $1::TEXT
+  AND (
+    cardinality($2::TEXT []) = 0
+    OR source = ANY($2::TEXT [])
+  )
+  AND NOT hidden
+ORDER BY publish_date DESC
+LIMIT $3 OFFSET $4
+`
+
+type SearchNewsParams struct {
+	Pattern    string   `json:"pattern"`
+	Sources    []string `json:"sources"`
+	PageLimit  int32    `json:"page_limit"`
+	PageOffset int32    `json:"page_offset"`
+}
+
+func (q *Queries) SearchNews(ctx context.Context, arg SearchNewsParams) ([]News, error) {
+	rows, err := q.db.Query(ctx, searchNews,
+		arg.Pattern,
+		arg.Sources,
+		arg.PageLimit,
+		arg.PageOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []News
+	for rows.Next() {
+		var i News
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Link,
+			&i.Source,
+			&i.ImageUrl,
+			&i.PublishDate,
+			&i.FetchedAt,
+			&i.Summary,
+			&i.Hidden,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setNewsHidden = `-- name: SetNewsHidden :execrows
+UPDATE news
+SET hidden = $1
+WHERE id = $2
+`
+
+type SetNewsHiddenParams struct {
+	Hidden bool  `json:"hidden"`
+	ID     int64 `json:"id"`
+}
+
+func (q *Queries) SetNewsHidden(ctx context.Context, arg SetNewsHiddenParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setNewsHidden, arg.Hidden, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const setNewsSummary = `-- name: SetNewsSummary :exec
+UPDATE news
+SET summary = $1
+WHERE id = $2
+`
+
+type SetNewsSummaryParams struct {
+	Summary pgtype.Text `json:"summary"`
+	ID      int64       `json:"id"`
+}
+
+func (q *Queries) SetNewsSummary(ctx context.Context, arg SetNewsSummaryParams) error {
+	_, err := q.db.Exec(ctx, setNewsSummary, arg.Summary, arg.ID)
+	return err
+}
+
+const upsertNewsBatch = `-- name: UpsertNewsBatch :exec
+WITH input AS (
+  SELECT DISTINCT ON (n.link) n.title,
+    n.link,
+    n.source,
+    n.image_url,
+    COALESCE(n.publish_date, NOW()) AS publish_date,
+    NULLIF(n.summary, '') AS summary
+  FROM unnest(
+      $1::TEXT [],
+      $2::TEXT [],
+      $3::TEXT [],
+      $4::TEXT [],
+      $5::TIMESTAMP [],
+      $6::TEXT []
+    ) AS n(title, link, source, image_url, publish_date, summary)
+  ORDER BY n.link
+),
+refreshed AS (
+  UPDATE news
+  SET title = i.title,
+    image_url = COALESCE(NULLIF(i.image_url, ''), news.image_url),
+    updated_at = NOW()
+  FROM input i
+    JOIN news_links l ON l.link = i.link
+  WHERE news.id = l.news_id
+    AND news.publish_date = l.publish_date
+    AND (
+      news.title IS DISTINCT FROM i.title
+      OR (
+        NULLIF(i.image_url, '') IS NOT NULL
+        AND news.image_url IS DISTINCT FROM i.image_url
+      )
+    )
+),
+claimed AS (
+  -- news can only enforce unique links per partition, news_links holds them across partitions
+  INSERT INTO news_links (link, news_id, publish_date)
+  SELECT i.link,
+    nextval('news_id_seq'),
+    i.publish_date
+  FROM input i
+  ON CONFLICT (link) DO NOTHING
+  RETURNING link,
+    news_id
+)
+INSERT INTO news (
+    id,
+    title,
+    link,
+    source,
+    image_url,
+    publish_date,
+    summary,
+    fetched_at
+  )
+SELECT c.news_id,
+  i.title,
+  i.link,
+  i.source,
+  i.image_url,
+  i.publish_date,
+  i.summary,
+  NOW()
+FROM claimed c
+  JOIN input i ON i.link = c.link
+`
+
+type UpsertNewsBatchParams struct {
+	Titles       []string           `json:"titles"`
+	Links        []string           `json:"links"`
+	Sources      []string           `json:"sources"`
+	ImageUrls    []string           `json:"image_urls"`
+	PublishDates []pgtype.Timestamp `json:"publish_dates"`
+	Summaries    []string           `json:"summaries"`
+}
+
+func (q *Queries) UpsertNewsBatch(ctx context.Context, arg UpsertNewsBatchParams) error {
+	_, err := q.db.Exec(ctx, upsertNewsBatch,
+		arg.Titles,
+		arg.Links,
+		arg.Sources,
+		arg.ImageUrls,
+		arg.PublishDates,
+		arg.Summaries,
+	)
 	return err
 }
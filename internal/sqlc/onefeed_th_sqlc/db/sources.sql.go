@@ -11,10 +11,44 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const countSources = `-- name: CountSources :one
+SELECT COUNT(*)
+FROM sources
+WHERE deleted_at IS NULL
+  AND name ILIKE $1::TEXT
+  AND (
+    $2::TEXT = ''
+    OR EXISTS (
+      SELECT 1
+      FROM source_tags
+        JOIN tags ON tags.id = source_tags.tag_id
+      WHERE source_tags.source_id = sources.id
+        AND LOWER(tags.name) = LOWER($2::TEXT)
+    )
+  )
+  AND (
+    $3::TEXT = ''
+    OR enabled = ($3::TEXT = 'enabled')
+  )
+`
+
+type CountSourcesParams struct {
+	Pattern string `json:"pattern"`
+	Tag     string `json:"tag"`
+	Status  string `json:"status"`
+}
+
+func (q *Queries) CountSources(ctx context.Context, arg CountSourcesParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countSources, arg.Pattern, arg.Tag, arg.Status)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createSource = `-- name: CreateSource :one
 INSERT INTO sources (name, tags, rss_url)
 VALUES ($1, $2, $3)
-RETURNING id, name, tags, rss_url, created_at
+RETURNING id, name, tags, rss_url, created_at, enabled, deleted_at, image_selector, image_fields
 `
 
 type CreateSourceParams struct {
@@ -32,17 +66,42 @@ func (q *Queries) CreateSource(ctx context.Context, arg CreateSourceParams) (Sou
 		&i.Tags,
 		&i.RssUrl,
 		&i.CreatedAt,
+		&i.Enabled,
+		&i.DeletedAt,
+		&i.ImageSelector,
+		&i.ImageFields,
 	)
 	return i, err
 }
 
+const deleteSource = `-- name: DeleteSource :execrows
+UPDATE sources
+SET deleted_at = NOW(),
+  enabled = FALSE
+WHERE id = $1
+  AND deleted_at IS NULL
+`
+
+func (q *Queries) DeleteSource(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteSource, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const getAllSources = `-- name: GetAllSources :many
-SELECT id, name, tags, rss_url, created_at
+SELECT id, name, tags, rss_url, created_at, enabled, deleted_at, image_selector, image_fields
 FROM sources
+WHERE deleted_at IS NULL
+  AND (
+    enabled
+    OR $1::BOOLEAN
+  )
 `
 
-func (q *Queries) GetAllSources(ctx context.Context) ([]Source, error) {
-	rows, err := q.db.Query(ctx, getAllSources)
+func (q *Queries) GetAllSources(ctx context.Context, includeDisabled bool) ([]Source, error) {
+	rows, err := q.db.Query(ctx, getAllSources, includeDisabled)
 	if err != nil {
 		return nil, err
 	}
@@ -56,6 +115,10 @@ func (q *Queries) GetAllSources(ctx context.Context) ([]Source, error) {
 			&i.Tags,
 			&i.RssUrl,
 			&i.CreatedAt,
+			&i.Enabled,
+			&i.DeletedAt,
+			&i.ImageSelector,
+			&i.ImageFields,
 		); err != nil {
 			return nil, err
 		}
@@ -68,19 +131,123 @@ func (q *Queries) GetAllSources(ctx context.Context) ([]Source, error) {
 }
 
 const getAllSourcesWithPagination = `-- name: GetAllSourcesWithPagination :many
-SELECT id, name, tags, rss_url, created_at
+SELECT id, name, tags, rss_url, created_at, enabled, deleted_at, image_selector, image_fields
 FROM sources
-ORDER BY created_at DESC
-LIMIT $2 OFFSET $1
+WHERE deleted_at IS NULL
+  AND name ILIKE $1::TEXT
+  AND (
+    $2::TEXT = ''
+    OR EXISTS (
+      SELECT 1
+      FROM source_tags
+        JOIN tags ON tags.id = source_tags.tag_id
+      WHERE source_tags.source_id = sources.id
+        AND LOWER(tags.name) = LOWER($2::TEXT)
+    )
+  )
+  AND (
+    $3::TEXT = ''
+    OR enabled = ($3::TEXT = 'enabled')
+  )
+ORDER BY CASE
+    WHEN $4::TEXT = 'name:asc' THEN name
+  END ASC,
+  CASE
+    WHEN $4::TEXT = 'name:desc' THEN name
+  END DESC,
+  CASE
+    WHEN $4::TEXT = 'createdAt:asc' THEN created_at
+  END ASC,
+  created_at DESC,
+  id DESC
+LIMIT $5 OFFSET $6
 `
 
 type GetAllSourcesWithPaginationParams struct {
-	PageOffset int32 `json:"page_offset"`
-	PageLimit  int32 `json:"page_limit"`
+	Pattern    string `json:"pattern"`
+	Tag        string `json:"tag"`
+	Status     string `json:"status"`
+	Sort       string `json:"sort"`
+	PageLimit  int32  `json:"page_limit"`
+	PageOffset int32  `json:"page_offset"`
 }
 
 func (q *Queries) GetAllSourcesWithPagination(ctx context.Context, arg GetAllSourcesWithPaginationParams) ([]Source, error) {
-	rows, err := q.db.Query(ctx, getAllSourcesWithPagination, arg.PageOffset, arg.PageLimit)
+	rows, err := q.db.Query(ctx, getAllSourcesWithPagination,
+		arg.Pattern,
+		arg.Tag,
+		arg.Status,
+		arg.Sort,
+		arg.PageLimit,
+		arg.PageOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Source
+	for rows.Next() {
+		var i Source
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Tags,
+			&i.RssUrl,
+			&i.CreatedAt,
+			&i.Enabled,
+			&i.DeletedAt,
+			&i.ImageSelector,
+			&i.ImageFields,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSourceByID = `-- name: GetSourceByID :one
+SELECT id, name, tags, rss_url, created_at, enabled, deleted_at, image_selector, image_fields
+FROM sources
+WHERE id = $1
+  AND deleted_at IS NULL
+`
+
+func (q *Queries) GetSourceByID(ctx context.Context, id int64) (Source, error) {
+	row := q.db.QueryRow(ctx, getSourceByID, id)
+	var i Source
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Tags,
+		&i.RssUrl,
+		&i.CreatedAt,
+		&i.Enabled,
+		&i.DeletedAt,
+		&i.ImageSelector,
+		&i.ImageFields,
+	)
+	return i, err
+}
+
+const refreshSourceTags = `-- name: RefreshSourceTags :many
+UPDATE sources
+SET tags = (
+    SELECT string_agg(tags.name, ',' ORDER BY tags.name)
+    FROM source_tags
+      JOIN tags ON tags.id = source_tags.tag_id
+    WHERE source_tags.source_id = sources.id
+  )
+WHERE id = ANY($1::BIGINT [])
+RETURNING id, name, tags, rss_url, created_at, enabled, deleted_at, image_selector, image_fields
+`
+
+// Rewrites the tags copy of the sources from their linked tags
+func (q *Queries) RefreshSourceTags(ctx context.Context, ids []int64) ([]Source, error) {
+	rows, err := q.db.Query(ctx, refreshSourceTags, ids)
 	if err != nil {
 		return nil, err
 	}
@@ -94,6 +261,10 @@ func (q *Queries) GetAllSourcesWithPagination(ctx context.Context, arg GetAllSou
 			&i.Tags,
 			&i.RssUrl,
 			&i.CreatedAt,
+			&i.Enabled,
+			&i.DeletedAt,
+			&i.ImageSelector,
+			&i.ImageFields,
 		); err != nil {
 			return nil, err
 		}
@@ -104,3 +275,67 @@ func (q *Queries) GetAllSourcesWithPagination(ctx context.Context, arg GetAllSou
 	}
 	return items, nil
 }
+
+const setSourceEnabled = `-- name: SetSourceEnabled :execrows
+UPDATE sources
+SET enabled = $1
+WHERE id = $2
+  AND deleted_at IS NULL
+`
+
+type SetSourceEnabledParams struct {
+	Enabled bool  `json:"enabled"`
+	ID      int64 `json:"id"`
+}
+
+func (q *Queries) SetSourceEnabled(ctx context.Context, arg SetSourceEnabledParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setSourceEnabled, arg.Enabled, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updateSource = `-- name: UpdateSource :one
+UPDATE sources
+SET name = $1,
+  tags = $2,
+  rss_url = $3,
+  image_selector = $4,
+  image_fields = $5
+WHERE id = $6
+RETURNING id, name, tags, rss_url, created_at, enabled, deleted_at, image_selector, image_fields
+`
+
+type UpdateSourceParams struct {
+	Name          string      `json:"name"`
+	Tags          pgtype.Text `json:"tags"`
+	RssUrl        pgtype.Text `json:"rss_url"`
+	ImageSelector pgtype.Text `json:"image_selector"`
+	ImageFields   []string    `json:"image_fields"`
+	ID            int64       `json:"id"`
+}
+
+func (q *Queries) UpdateSource(ctx context.Context, arg UpdateSourceParams) (Source, error) {
+	row := q.db.QueryRow(ctx, updateSource,
+		arg.Name,
+		arg.Tags,
+		arg.RssUrl,
+		arg.ImageSelector,
+		arg.ImageFields,
+		arg.ID,
+	)
+	var i Source
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Tags,
+		&i.RssUrl,
+		&i.CreatedAt,
+		&i.Enabled,
+		&i.DeletedAt,
+		&i.ImageSelector,
+		&i.ImageFields,
+	)
+	return i, err
+}
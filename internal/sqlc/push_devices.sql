@@ -0,0 +1,80 @@
+CREATE TABLE push_devices (
+  id BIGSERIAL PRIMARY KEY,
+  token TEXT NOT NULL UNIQUE, -- FCM registration token
+  platform TEXT NOT NULL, -- android, ios หรือ web
+  sources TEXT[] NOT NULL DEFAULT '{}', -- ว่าง = ทุก source
+  keywords TEXT[] NOT NULL DEFAULT '{}', -- ว่าง = ไม่กรองคำ
+  created_at TIMESTAMP DEFAULT NOW(),
+  updated_at TIMESTAMP
+);
+CREATE TABLE push_jobs (
+  id BIGSERIAL PRIMARY KEY,
+  device_id BIGINT NOT NULL REFERENCES push_devices(id) ON DELETE CASCADE,
+  news_id BIGINT NOT NULL,
+  attempts INT NOT NULL DEFAULT 0,
+  last_error TEXT,
+  run_at TIMESTAMP NOT NULL DEFAULT NOW(), -- ไม่ส่งก่อนเวลานี้ (retry backoff / lease ของ worker)
+  created_at TIMESTAMP DEFAULT NOW()
+);
+-- name: ClaimPushJobs :many
+-- Leases due jobs to one worker; a worker that dies lets the lease run out and the job is retried
+WITH claimed AS (
+  UPDATE push_jobs
+  SET attempts = push_jobs.attempts + 1,
+    run_at = @lease_until::TIMESTAMP
+  WHERE push_jobs.id IN (
+      SELECT id
+      FROM push_jobs
+      WHERE push_jobs.run_at <= @now::TIMESTAMP
+      ORDER BY id
+      LIMIT @page_limit FOR UPDATE SKIP LOCKED
+    )
+  RETURNING push_jobs.id,
+    push_jobs.device_id,
+    push_jobs.news_id,
+    push_jobs.attempts
+)
+SELECT claimed.id,
+  claimed.device_id,
+  claimed.news_id,
+  claimed.attempts,
+  push_devices.token,
+  news.title,
+  news.link,
+  news.source
+FROM claimed
+  JOIN push_devices ON push_devices.id = claimed.device_id
+  JOIN news ON news.id = claimed.news_id
+ORDER BY claimed.id;
+-- name: CreatePushJobs :exec
+INSERT INTO push_jobs (device_id, news_id)
+SELECT j.device_id,
+  j.news_id
+FROM unnest(@device_ids::BIGINT [], @news_ids::BIGINT []) AS j(device_id, news_id);
+-- name: DeletePushDeviceByID :exec
+DELETE FROM push_devices
+WHERE id = @id;
+-- name: DeletePushDeviceByToken :execrows
+DELETE FROM push_devices
+WHERE token = @token;
+-- name: DeletePushJob :exec
+DELETE FROM push_jobs
+WHERE id = @id;
+-- name: ListPushDevices :many
+SELECT *
+FROM push_devices
+ORDER BY id;
+-- name: RetryPushJob :exec
+UPDATE push_jobs
+SET last_error = @last_error,
+  run_at = @run_at::TIMESTAMP
+WHERE id = @id;
+-- name: UpsertPushDevice :one
+INSERT INTO push_devices (token, platform, sources, keywords)
+VALUES (@token, @platform, @sources, @keywords)
+ON CONFLICT (token) DO UPDATE
+SET platform = EXCLUDED.platform,
+  sources = EXCLUDED.sources,
+  keywords = EXCLUDED.keywords,
+  updated_at = NOW()
+RETURNING *;
@@ -0,0 +1,66 @@
+CREATE TABLE saved_searches (
+  id BIGSERIAL PRIMARY KEY,
+  user_id BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  query TEXT NOT NULL, -- ทุกคำต้องอยู่ใน title หรือ summary
+  sources TEXT[] NOT NULL DEFAULT '{}', -- ว่าง = ทุก source
+  push_token TEXT NULL, -- FCM token ของเครื่องที่รับแจ้งเตือน
+  line_user_id TEXT NULL, -- LINE user id ของผู้อ่านที่เป็นเพื่อนกับ official account
+  notified_at TIMESTAMP NULL, -- ครั้งล่าสุดที่มีข่าวตรงและแจ้งเตือน
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP NULL
+);
+-- name: ClearSavedSearchPushToken :exec
+-- Forgets a push token FCM reported as expired, on every search that uses it
+UPDATE saved_searches
+SET push_token = NULL
+WHERE push_token = @push_token;
+-- name: CountSavedSearches :one
+SELECT COUNT(*)
+FROM saved_searches
+WHERE user_id = @user_id;
+-- name: CreateSavedSearch :one
+INSERT INTO saved_searches (user_id, name, query, sources, push_token, line_user_id)
+VALUES (@user_id, @name, @query, @sources, @push_token, @line_user_id)
+RETURNING *;
+-- name: DeleteSavedSearch :execrows
+DELETE FROM saved_searches
+WHERE id = @id
+  AND user_id = @user_id;
+-- name: GetSavedSearch :one
+SELECT *
+FROM saved_searches
+WHERE id = @id
+  AND user_id = @user_id;
+-- name: ListNotifyingSavedSearches :many
+-- The searches that have somewhere to send their matches
+SELECT *
+FROM saved_searches
+WHERE push_token IS NOT NULL
+  OR line_user_id IS NOT NULL
+ORDER BY id;
+-- name: ListSavedSearches :many
+SELECT *
+FROM saved_searches
+WHERE user_id = @user_id
+ORDER BY id;
+-- name: MergeSavedSearches :exec
+-- Moves the searches of a device profile to the account it signs in to
+UPDATE saved_searches
+SET user_id = @to_user_id
+WHERE user_id = @from_user_id;
+-- name: TouchSavedSearchesNotified :exec
+UPDATE saved_searches
+SET notified_at = NOW()
+WHERE id = ANY(@ids::BIGINT []);
+-- name: UpdateSavedSearch :one
+UPDATE saved_searches
+SET name = @name,
+  query = @query,
+  sources = @sources,
+  push_token = @push_token,
+  line_user_id = @line_user_id,
+  updated_at = NOW()
+WHERE id = @id
+  AND user_id = @user_id
+RETURNING *;
@@ -0,0 +1,124 @@
+CREATE TABLE source_stats_daily (
+  source_id BIGINT NOT NULL REFERENCES sources (id) ON DELETE CASCADE,
+  day TIMESTAMP NOT NULL, -- วันที่นับ (UTC)
+  articles BIGINT NOT NULL DEFAULT 0, -- ข่าวที่เก็บได้ในวันนั้น
+  impressions BIGINT NOT NULL DEFAULT 0, -- จำนวนครั้งที่ข่าวแสดงใน /news
+  clicks BIGINT NOT NULL DEFAULT 0,
+  PRIMARY KEY (source_id, day)
+);
+CREATE TABLE news_clicks_daily (
+  news_id BIGINT NOT NULL,
+  day TIMESTAMP NOT NULL, -- วันที่นับคลิก (UTC)
+  source_id BIGINT NOT NULL REFERENCES sources (id) ON DELETE CASCADE,
+  title TEXT NOT NULL,
+  link TEXT NOT NULL,
+  clicks BIGINT NOT NULL DEFAULT 0,
+  PRIMARY KEY (news_id, day)
+);
+-- name: AggregateNewsClicksDaily :exec
+-- Rolls the hourly clicks of a day up per news
+INSERT INTO news_clicks_daily (news_id, day, source_id, title, link, clicks)
+SELECT news.id,
+  @day::TIMESTAMP,
+  sources.id,
+  news.title,
+  news.link,
+  SUM(news_clicks.clicks)::BIGINT
+FROM news_clicks
+  JOIN news ON news.id = news_clicks.news_id
+  JOIN sources ON sources.name = news.source
+  AND sources.deleted_at IS NULL
+WHERE news_clicks.bucket_start >= @day::TIMESTAMP
+  AND news_clicks.bucket_start < @day::TIMESTAMP + INTERVAL '1 day'
+GROUP BY news.id,
+  news.title,
+  news.link,
+  sources.id
+ON CONFLICT (news_id, day) DO UPDATE
+SET source_id = EXCLUDED.source_id,
+  title = EXCLUDED.title,
+  link = EXCLUDED.link,
+  clicks = EXCLUDED.clicks;
+-- name: ListSourceStats :many
+SELECT source_id,
+  date_trunc(@interval::TEXT, day)::TIMESTAMP AS bucket,
+  SUM(articles)::BIGINT AS articles,
+  SUM(impressions)::BIGINT AS impressions,
+  SUM(clicks)::BIGINT AS clicks
+FROM source_stats_daily
+WHERE day >= @from_date::TIMESTAMP
+  AND day < @to_date::TIMESTAMP
+  AND (
+    cardinality(@source_ids::BIGINT []) = 0
+    OR source_id = ANY(@source_ids::BIGINT [])
+  )
+GROUP BY source_id,
+  bucket
+ORDER BY source_id,
+  bucket;
+-- name: ListTopSourceNews :many
+-- The most clicked news of every source over a range, up to top_limit per source
+SELECT source_id,
+  news_id,
+  title,
+  link,
+  clicks
+FROM (
+    SELECT source_id,
+      news_id,
+      MAX(title)::TEXT AS title,
+      MAX(link)::TEXT AS link,
+      SUM(clicks)::BIGINT AS clicks,
+      ROW_NUMBER() OVER (
+        PARTITION BY source_id
+        ORDER BY SUM(clicks) DESC,
+          news_id DESC
+      ) AS position
+    FROM news_clicks_daily
+    WHERE day >= @from_date::TIMESTAMP
+      AND day < @to_date::TIMESTAMP
+      AND (
+        cardinality(@source_ids::BIGINT []) = 0
+        OR source_id = ANY(@source_ids::BIGINT [])
+      )
+    GROUP BY source_id,
+      news_id
+  ) ranked
+WHERE position <= @top_limit::INT
+ORDER BY source_id,
+  clicks DESC,
+  news_id DESC;
+-- name: RemoveNewsClicksDailyBefore :exec
+DELETE FROM news_clicks_daily
+WHERE day < @before::TIMESTAMP;
+-- name: UpsertSourceStatsDaily :exec
+-- Writes the totals of a day per source: the news fetched, the impressions counted by the API
+-- and the clicks rolled up by AggregateNewsClicksDaily
+INSERT INTO source_stats_daily (source_id, day, articles, impressions, clicks)
+SELECT sources.id,
+  @day::TIMESTAMP,
+  COALESCE(articles.count, 0),
+  COALESCE(impressions.count, 0),
+  COALESCE(clicks.count, 0)
+FROM sources
+  LEFT JOIN (
+    SELECT source,
+      COUNT(*) AS count
+    FROM news
+    WHERE fetched_at >= @day::TIMESTAMP
+      AND fetched_at < @day::TIMESTAMP + INTERVAL '1 day'
+    GROUP BY source
+  ) articles ON articles.source = sources.name
+  LEFT JOIN unnest(@impression_sources::TEXT [], @impressions::BIGINT []) AS impressions(source, count) ON impressions.source = sources.name
+  LEFT JOIN (
+    SELECT source_id,
+      SUM(news_clicks_daily.clicks) AS count
+    FROM news_clicks_daily
+    WHERE day = @day::TIMESTAMP
+    GROUP BY source_id
+  ) clicks ON clicks.source_id = sources.id
+WHERE sources.deleted_at IS NULL
+ON CONFLICT (source_id, day) DO UPDATE
+SET articles = EXCLUDED.articles,
+  impressions = EXCLUDED.impressions,
+  clicks = EXCLUDED.clicks;
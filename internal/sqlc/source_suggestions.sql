@@ -0,0 +1,55 @@
+CREATE TABLE source_suggestions (
+  id BIGSERIAL PRIMARY KEY,
+  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  tags TEXT NULL,
+  rss_url TEXT NOT NULL,
+  note TEXT NOT NULL DEFAULT '',
+  push_token TEXT NULL, -- แจ้งผลการพิจารณาไปยังเครื่องของผู้เสนอ
+  status TEXT NOT NULL DEFAULT 'pending', -- pending, approved หรือ rejected
+  review_reason TEXT NULL,
+  reviewed_by TEXT NULL,
+  reviewed_at TIMESTAMP NULL,
+  source_id BIGINT NULL REFERENCES sources(id) ON DELETE SET NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+-- name: CountPendingSourceSuggestions :one
+SELECT COUNT(*)
+FROM source_suggestions
+WHERE status = 'pending';
+-- name: CreateSourceSuggestion :one
+INSERT INTO source_suggestions (user_id, name, tags, rss_url, note, push_token)
+VALUES (@user_id, @name, @tags, @rss_url, @note, @push_token)
+RETURNING *;
+-- name: GetSourceSuggestionByID :one
+SELECT *
+FROM source_suggestions
+WHERE id = @id;
+-- name: ListPendingSourceSuggestions :many
+SELECT *
+FROM source_suggestions
+WHERE status = 'pending'
+ORDER BY id
+LIMIT @page_limit OFFSET @page_offset;
+-- name: ListUserSourceSuggestions :many
+SELECT *
+FROM source_suggestions
+WHERE user_id = @user_id
+ORDER BY id DESC
+LIMIT @page_limit;
+-- name: MergeSourceSuggestions :exec
+-- Moves the suggestions of a device profile to the account it signs in to
+UPDATE source_suggestions
+SET user_id = @to_user_id
+WHERE user_id = @from_user_id;
+-- name: ReviewSourceSuggestion :one
+-- Only a pending suggestion is changed, so two editors can't both decide on it
+UPDATE source_suggestions
+SET status = @status,
+  review_reason = @review_reason,
+  reviewed_by = @reviewed_by,
+  reviewed_at = NOW(),
+  source_id = @source_id
+WHERE id = @id
+  AND status = 'pending'
+RETURNING *;
@@ -0,0 +1,16 @@
+CREATE TABLE source_tags (
+  source_id BIGINT NOT NULL,
+  tag_id INT NOT NULL,
+  PRIMARY KEY (source_id, tag_id)
+);
+-- name: AddSourceTag :exec
+INSERT INTO source_tags (source_id, tag_id)
+VALUES (@source_id, @tag_id)
+ON CONFLICT DO NOTHING;
+-- name: DeleteSourceTags :exec
+DELETE FROM source_tags
+WHERE source_id = @source_id;
+-- name: ListTagSourceIDs :many
+SELECT source_id
+FROM source_tags
+WHERE tag_id = @tag_id;
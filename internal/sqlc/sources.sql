@@ -3,17 +3,106 @@ CREATE TABLE sources (
   name TEXT NOT NULL,
   tags TEXT NULL,
   rss_url TEXT,
-  created_at TIMESTAMP DEFAULT NOW()
+  created_at TIMESTAMP DEFAULT NOW(),
+  enabled BOOLEAN NOT NULL DEFAULT TRUE,
+  deleted_at TIMESTAMP NULL,
+  image_selector TEXT NULL,
+  image_fields TEXT[] NULL
 );
 -- name: GetAllSources :many
 SELECT *
-FROM sources;
+FROM sources
+WHERE deleted_at IS NULL
+  AND (
+    enabled
+    OR @include_disabled::BOOLEAN
+  );
 -- name: GetAllSourcesWithPagination :many
 SELECT *
 FROM sources
-ORDER BY created_at DESC
+WHERE deleted_at IS NULL
+  AND name ILIKE @pattern::TEXT
+  AND (
+    @tag::TEXT = ''
+    OR EXISTS (
+      SELECT 1
+      FROM source_tags
+        JOIN tags ON tags.id = source_tags.tag_id
+      WHERE source_tags.source_id = sources.id
+        AND LOWER(tags.name) = LOWER(@tag::TEXT)
+    )
+  )
+  AND (
+    @status::TEXT = ''
+    OR enabled = (@status::TEXT = 'enabled')
+  )
+ORDER BY CASE
+    WHEN @sort::TEXT = 'name:asc' THEN name
+  END ASC,
+  CASE
+    WHEN @sort::TEXT = 'name:desc' THEN name
+  END DESC,
+  CASE
+    WHEN @sort::TEXT = 'createdAt:asc' THEN created_at
+  END ASC,
+  created_at DESC,
+  id DESC
 LIMIT @page_limit OFFSET @page_offset;
+-- name: CountSources :one
+SELECT COUNT(*)
+FROM sources
+WHERE deleted_at IS NULL
+  AND name ILIKE @pattern::TEXT
+  AND (
+    @tag::TEXT = ''
+    OR EXISTS (
+      SELECT 1
+      FROM source_tags
+        JOIN tags ON tags.id = source_tags.tag_id
+      WHERE source_tags.source_id = sources.id
+        AND LOWER(tags.name) = LOWER(@tag::TEXT)
+    )
+  )
+  AND (
+    @status::TEXT = ''
+    OR enabled = (@status::TEXT = 'enabled')
+  );
 -- name: CreateSource :one
 INSERT INTO sources (name, tags, rss_url)
 VALUES (@name, @tags, @rss_url)
-RETURNING *;
\ No newline at end of file
+RETURNING *;
+-- name: GetSourceByID :one
+SELECT *
+FROM sources
+WHERE id = @id
+  AND deleted_at IS NULL;
+-- name: UpdateSource :one
+UPDATE sources
+SET name = @name,
+  tags = @tags,
+  rss_url = @rss_url,
+  image_selector = @image_selector,
+  image_fields = @image_fields
+WHERE id = @id
+RETURNING *;
+-- name: SetSourceEnabled :execrows
+UPDATE sources
+SET enabled = @enabled
+WHERE id = @id
+  AND deleted_at IS NULL;
+-- name: DeleteSource :execrows
+UPDATE sources
+SET deleted_at = NOW(),
+  enabled = FALSE
+WHERE id = @id
+  AND deleted_at IS NULL;-- name: RefreshSourceTags :many
+-- Rewrites the tags copy of the sources from their linked tags
+UPDATE sources
+SET tags = (
+    SELECT string_agg(tags.name, ',' ORDER BY tags.name)
+    FROM source_tags
+      JOIN tags ON tags.id = source_tags.tag_id
+    WHERE source_tags.source_id = sources.id
+  )
+WHERE id = ANY(@ids::BIGINT [])
+RETURNING *;
@@ -1,4 +1,42 @@
 CREATE TABLE tags (
   id SERIAL PRIMARY KEY,
-  name VARCHAR(50) NOT NULL UNIQUE -- เช่น "AI", "Startup", "ฟุตบอล"
-);
\ No newline at end of file
+  name VARCHAR(50) NOT NULL, -- เช่น "AI", "Startup", "ฟุตบอล", ไม่ซ้ำโดยไม่สนตัวพิมพ์
+  description TEXT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+-- name: CreateTag :one
+INSERT INTO tags (name, description)
+VALUES (@name, @description)
+RETURNING *;
+-- name: EnsureTag :one
+-- Returns the tag with the name regardless of case, creating it when missing
+INSERT INTO tags (name)
+VALUES (@name)
+ON CONFLICT ((LOWER(name))) DO UPDATE
+SET name = tags.name
+RETURNING *;
+-- name: GetTagByID :one
+SELECT *
+FROM tags
+WHERE id = @id;
+-- name: ListTagsWithSourceCounts :many
+SELECT tags.id,
+  tags.name,
+  tags.description,
+  tags.created_at,
+  COUNT(sources.id) AS source_count
+FROM tags
+  LEFT JOIN source_tags ON source_tags.tag_id = tags.id
+  LEFT JOIN sources ON sources.id = source_tags.source_id
+  AND sources.deleted_at IS NULL
+GROUP BY tags.id
+ORDER BY tags.name;
+-- name: UpdateTag :one
+UPDATE tags
+SET name = @name,
+  description = @description
+WHERE id = @id
+RETURNING *;
+-- name: DeleteTag :execrows
+DELETE FROM tags
+WHERE id = @id;
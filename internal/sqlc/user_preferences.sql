@@ -0,0 +1,28 @@
+CREATE TABLE user_preferences (
+  user_id BIGINT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+  sources TEXT[] NOT NULL DEFAULT '{}',
+  tags TEXT[] NOT NULL DEFAULT '{}',
+  updated_at TIMESTAMP DEFAULT NOW()
+);
+-- name: GetUserPreferences :one
+SELECT *
+FROM user_preferences
+WHERE user_id = @user_id;
+-- name: MergeUserPreferences :exec
+-- An account keeps its own preferences; a device's are only copied when it has none
+INSERT INTO user_preferences (user_id, sources, tags, updated_at)
+SELECT @to_user_id,
+  sources,
+  tags,
+  updated_at
+FROM user_preferences
+WHERE user_id = @from_user_id
+ON CONFLICT (user_id) DO NOTHING;
+-- name: UpsertUserPreferences :one
+INSERT INTO user_preferences (user_id, sources, tags, updated_at)
+VALUES (@user_id, @sources, @tags, NOW())
+ON CONFLICT (user_id) DO UPDATE
+SET sources = EXCLUDED.sources,
+  tags = EXCLUDED.tags,
+  updated_at = EXCLUDED.updated_at
+RETURNING *;
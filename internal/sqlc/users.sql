@@ -0,0 +1,70 @@
+CREATE TABLE users (
+  id BIGSERIAL PRIMARY KEY,
+  email TEXT UNIQUE, -- ตัวพิมพ์เล็กเสมอ, NULL เมื่อผู้ให้บริการ OAuth ไม่ยืนยันอีเมล
+  password_hash TEXT, -- bcrypt, NULL สำหรับบัญชีที่เข้าด้วย OAuth อย่างเดียว
+  display_name TEXT NOT NULL DEFAULT '',
+  last_login_at TIMESTAMP,
+  created_at TIMESTAMP DEFAULT NOW(),
+  updated_at TIMESTAMP,
+  device_id TEXT UNIQUE -- โปรไฟล์ของอุปกรณ์ที่ยังไม่สมัครสมาชิก (X-Device-ID)
+);
+CREATE TABLE user_identities (
+  provider TEXT NOT NULL, -- google หรือ apple
+  subject TEXT NOT NULL,
+  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  email TEXT,
+  created_at TIMESTAMP DEFAULT NOW(),
+  PRIMARY KEY (provider, subject)
+);
+-- name: ClearUserPassword :exec
+-- Signing in with a provider that verified the email proves who owns it, which registering
+-- with a password never did
+UPDATE users
+SET password_hash = NULL,
+  updated_at = NOW()
+WHERE id = @id;
+-- name: CreateDeviceUser :one
+-- Concurrent first requests from one device end up with the same profile
+INSERT INTO users (device_id, last_login_at)
+VALUES (@device_id, NOW())
+ON CONFLICT (device_id) DO UPDATE
+SET last_login_at = EXCLUDED.last_login_at
+RETURNING *;
+-- name: CreateUser :one
+INSERT INTO users (email, password_hash, display_name, last_login_at)
+VALUES (@email, @password_hash, @display_name, NOW())
+RETURNING *;
+-- name: CreateUserIdentity :exec
+INSERT INTO user_identities (provider, subject, user_id, email)
+VALUES (@provider, @subject, @user_id, @email);
+-- name: DeleteUser :execrows
+DELETE FROM users
+WHERE id = @id;
+-- name: GetUserByDeviceID :one
+SELECT *
+FROM users
+WHERE device_id = @device_id;
+-- name: GetUserByEmail :one
+SELECT *
+FROM users
+WHERE email = @email;
+-- name: GetUserByID :one
+SELECT *
+FROM users
+WHERE id = @id;
+-- name: GetUserByIdentity :one
+SELECT users.*
+FROM user_identities
+  JOIN users ON users.id = user_identities.user_id
+WHERE user_identities.provider = @provider
+  AND user_identities.subject = @subject;
+-- name: UpdateUserLastLogin :exec
+UPDATE users
+SET last_login_at = NOW()
+WHERE id = @id;
+-- name: UpdateUserProfile :one
+UPDATE users
+SET display_name = @display_name,
+  updated_at = NOW()
+WHERE id = @id
+RETURNING *;
@@ -0,0 +1,57 @@
+CREATE TABLE webhooks (
+  id BIGSERIAL PRIMARY KEY,
+  url TEXT NOT NULL,
+  secret TEXT NOT NULL, -- คีย์ HMAC สำหรับเซ็น payload (ต้องเก็บค่าจริงไว้เซ็น)
+  sources TEXT[] NOT NULL DEFAULT '{}', -- ว่าง = ทุก source
+  tags TEXT[] NOT NULL DEFAULT '{}',
+  disabled BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at TIMESTAMP DEFAULT NOW(),
+  updated_at TIMESTAMP
+);
+CREATE TABLE webhook_failures (
+  id BIGSERIAL PRIMARY KEY,
+  webhook_id BIGINT NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+  payload JSONB NOT NULL,
+  attempts INT NOT NULL,
+  status_code INT, -- NULL เมื่อไม่ได้รับ response
+  error TEXT NOT NULL,
+  created_at TIMESTAMP DEFAULT NOW()
+);
+-- name: CreateWebhook :one
+INSERT INTO webhooks (url, secret, sources, tags)
+VALUES (@url, @secret, @sources, @tags)
+RETURNING *;
+-- name: CreateWebhookFailure :exec
+INSERT INTO webhook_failures (webhook_id, payload, attempts, status_code, error)
+VALUES (@webhook_id, @payload, @attempts, @status_code, @error);
+-- name: DeleteWebhook :execrows
+DELETE FROM webhooks
+WHERE id = @id;
+-- name: GetWebhookByID :one
+SELECT *
+FROM webhooks
+WHERE id = @id;
+-- name: ListEnabledWebhooks :many
+SELECT *
+FROM webhooks
+WHERE NOT disabled
+ORDER BY id;
+-- name: ListWebhookFailures :many
+SELECT *
+FROM webhook_failures
+WHERE webhook_id = @webhook_id
+ORDER BY id DESC
+LIMIT @page_limit;
+-- name: ListWebhooks :many
+SELECT *
+FROM webhooks
+ORDER BY id;
+-- name: UpdateWebhook :one
+UPDATE webhooks
+SET url = @url,
+  sources = @sources,
+  tags = @tags,
+  disabled = @disabled,
+  updated_at = NOW()
+WHERE id = @id
+RETURNING *;
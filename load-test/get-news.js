@@ -10,23 +10,14 @@ export const options = {
 }
 
 export default function () {
-  const url = 'https://onefeed-th-api.artzakub.com/api/v1/news';
-  const payload = JSON.stringify({
-    source: [
-      "MacThai",
-      "DroidSans",
-      "เกมถูกบอกด้วย"
-    ],
-    page: 1,
-    limit: 20
-  });
+  const sources = [
+    "MacThai",
+    "DroidSans",
+    "เกมถูกบอกด้วย"
+  ];
+  const query = sources.map((source) => `source=${encodeURIComponent(source)}`).join('&');
+  const url = `https://onefeed-th-api.artzakub.com/api/v1/news?${query}&page=1&limit=20`;
 
-  const params = {
-    headers: {
-      'Content-Type': 'application/json',
-    },
-  };
+  http.get(url);
 
-  http.post(url, payload, params);
-
-}
\ No newline at end of file
+}
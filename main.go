@@ -5,18 +5,21 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
-	"net/http"
+	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
 	"github.com/onefeed-th/onefeed-th-backend-api/config"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/errorreport"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/logger"
 	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/rds"
 	"github.com/onefeed-th/onefeed-th-backend-api/internal/db"
-	"github.com/onefeed-th/onefeed-th-backend-api/internal/middleware"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/dto"
 	"github.com/onefeed-th/onefeed-th-backend-api/internal/repository"
-	"github.com/onefeed-th/onefeed-th-backend-api/internal/routes"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/repository/memory"
 	"github.com/onefeed-th/onefeed-th-backend-api/internal/service"
+	"github.com/spf13/cobra"
 )
 
 func main() {
@@ -24,67 +27,197 @@ func main() {
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
-	// initialize configuration
-	if err := config.Init(ctx, "config/config.yaml"); err != nil {
-		slog.Error("Failed to initialize configuration", "error", err)
-		return
+	err := newRootCommand().ExecuteContext(ctx)
+	errorreport.Flush(2 * time.Second)
+	if err != nil {
+		slog.Error("Command failed", "error", err)
 	}
-	cfg := config.GetConfig()
+	logger.Close()
+	if err != nil {
+		stop()
+		os.Exit(1)
+	}
+}
 
-	// initialize database
-	if err := db.InitDB(); err != nil {
-		slog.Error("Failed to initialize database", "error", err)
+// newRootCommand builds the CLI; without a subcommand the binary serves the API as it always has
+func newRootCommand() *cobra.Command {
+	var configPath string
+	mode := os.Getenv(appModeVar)
+	if mode == "" {
+		mode = modeAll
 	}
 
-	// initialize Redis
-	if err := rds.InitRedis(ctx); err != nil {
-		slog.Error("Failed to initialize Redis", "error", err)
+	root := &cobra.Command{
+		Use:               "onefeed-app",
+		Short:             "OneFeed news API",
+		Args:              cobra.NoArgs,
+		SilenceUsage:      true,
+		SilenceErrors:     true,
+		CompletionOptions: cobra.CompletionOptions{DisableDefaultCmd: true},
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			// help needs no configuration
+			if cmd.Name() == "help" {
+				return nil
+			}
+
+			// initialize configuration
+			if err := config.Init(cmd.Context(), configPath); err != nil {
+				return fmt.Errorf("failed to initialize configuration: %w", err)
+			}
+
+			// log at the configured levels, which PUT /internal/log-level can change
+			if err := logger.Init(); err != nil {
+				return fmt.Errorf("failed to initialize logging: %w", err)
+			}
+
+			// report server errors to Sentry when sentry.dsn is set
+			if err := errorreport.Init(); err != nil {
+				slog.Error("Failed to initialize error reporting", "error", err)
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return serve(cmd.Context(), mode)
+		},
 	}
+	root.PersistentFlags().StringVar(&configPath, "config", "config/config.yaml", "base config file; APP_ENV selects its overlay")
+	root.Flags().StringVar(&mode, "mode", mode, modeUsage)
 
-	// initialize repository
-	repo := repository.NewRepository()
+	serveCmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve the REST API (the default)",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return serve(cmd.Context(), mode)
+		},
+	}
+	serveCmd.Flags().StringVar(&mode, "mode", mode, modeUsage)
 
-	// initialize service
-	service := service.NewService(repo)
+	root.AddCommand(
+		serveCmd,
+		&cobra.Command{
+			Use:   "collect",
+			Short: "Collect news from every enabled source once and exit",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return runJob(cmd.Context(), "collect", func(ctx context.Context, s service.Service) error {
+					_, err := s.CollectNewsFromSource(ctx, dto.BlankRequest{})
+					return err
+				})
+			},
+		},
+		&cobra.Command{
+			Use:   "prune-news",
+			Short: "Archive news past the retention period once and exit",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return runJob(cmd.Context(), "prune-news", func(ctx context.Context, s service.Service) error {
+					_, err := s.RemoveOldNews(ctx, dto.BlankRequest{})
+					return err
+				})
+			},
+		},
+		&cobra.Command{
+			Use:       "migrate up|status|baseline",
+			Short:     "Run database migrations and exit",
+			Long:      "up applies pending migrations, status lists applied and pending ones and baseline marks all of them as applied without running them.",
+			Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+			ValidArgs: []string{"up", "status", "baseline"},
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return migrate(cmd.Context(), args[0])
+			},
+		},
+		newConfigCommand(),
+	)
+	return root
+}
 
-	// initialize mux
-	handler := routes.RegisterRoutes(service)
-	handler = middleware.LogRequest(handler)
-	handler = middleware.RecoverPanic(handler)
+func newConfigCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect the configuration",
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:   "validate",
+		Short: "Load and validate the configuration without connecting to anything",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// the root command already loaded and validated it, and failed on any problem
+			fmt.Fprintln(cmd.OutOrStdout(), "configuration is valid")
+			return nil
+		},
+	})
+	return cmd
+}
 
-	// global middlewares
-	var httpHandler http.Handler = handler
+// runJob runs one job the way its /internal endpoint does, against fully connected storage
+func runJob(ctx context.Context, name string, job func(context.Context, service.Service) error) error {
+	repo, err := initStorage(ctx, false)
+	if err != nil {
+		return err
+	}
+	defer closeStorage()
 
-	// create configure http server
-	server := http.Server{
-		Addr:    fmt.Sprintf(":%d", cfg.RestServer.Port),
-		Handler: httpHandler,
+	start := time.Now()
+	if err := job(ctx, service.NewService(repo)); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
 	}
+	slog.Info("Job finished", "job", name, "duration", time.Since(start))
+	return nil
+}
 
-	go func() {
-		slog.Info("Starting REST Server", "port", cfg.RestServer.Port)
-		slog.Info("Local server", "url", fmt.Sprintf("http://localhost:%d", cfg.RestServer.Port))
-		slog.Info("waiting for request...")
+// migrate runs a migration command against the database only
+func migrate(ctx context.Context, command string) error {
+	if config.GetConfig().Storage.Driver == config.StorageDriverMemory {
+		return errors.New("migrations are not available with the memory storage driver")
+	}
+	if err := db.InitDB(); err != nil {
+		return fmt.Errorf("failed to initialize database: %w", err)
+	}
+	defer db.CloseDB()
 
-		err := server.ListenAndServe()
-		if err != nil && !errors.Is(err, http.ErrServerClosed) {
-			slog.Error("Failed to serve", "error", err)
-			stop()
-		}
-	}()
+	if err := runMigrations(ctx, command); err != nil {
+		return fmt.Errorf("migration %s failed: %w", command, err)
+	}
+	return nil
+}
 
-	// wait for the context to be canceled (i.e., SIGINT or SIGTERM)
-	<-ctx.Done()
-	slog.Info("Shutting down server...")
+// initStorage connects Postgres and Redis, or sets up in-process storage with the memory
+// driver. When degraded is set a dependency that cannot be reached is logged instead of
+// failing, and the supervisor brings it up later
+func initStorage(ctx context.Context, degraded bool) (*repository.Repository, error) {
+	if config.GetConfig().Storage.Driver == config.StorageDriverMemory {
+		// in-process storage for local development; nothing is persisted
+		slog.Warn("Using in-memory storage, data is lost on restart")
+		rds.InitMemory()
+		return memory.NewRepository(), nil
+	}
 
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+	// initialize database
+	if err := db.InitDB(); err != nil {
+		if !degraded {
+			return nil, fmt.Errorf("failed to initialize database: %w", err)
+		}
+		slog.Error("Failed to initialize database", "error", err)
+		slog.Warn("Starting in degraded mode without a database")
+	}
 
-	// Shutdown HTTP server
-	if err := server.Shutdown(shutdownCtx); err != nil {
-		slog.Error("Server shutdown failed", "error", err)
+	// initialize Redis
+	if err := rds.InitRedis(ctx); err != nil {
+		if !degraded {
+			db.CloseDB()
+			return nil, fmt.Errorf("failed to initialize Redis: %w", err)
+		}
+		slog.Error("Failed to initialize Redis", "error", err)
+		slog.Warn("Starting in degraded mode without Redis")
 	}
 
+	// initialize repository
+	return repository.NewRepository(), nil
+}
+
+// closeStorage closes the connections initStorage opened
+func closeStorage() {
 	// Close database connections
 	db.CloseDB()
 	slog.Info("Database connections closed")
@@ -95,6 +228,36 @@ func main() {
 	} else {
 		slog.Info("Redis connections closed")
 	}
+}
 
-	slog.Info("Server gracefully stopped")
+func runMigrations(ctx context.Context, command string) error {
+	switch command {
+	case "up":
+		applied, err := db.MigrateUp(ctx)
+		if err != nil {
+			return err
+		}
+		slog.Info("Database migrated", "applied_count", len(applied), "applied", applied)
+	case "baseline":
+		recorded, err := db.MigrateBaseline(ctx)
+		if err != nil {
+			return err
+		}
+		slog.Info("Migrations baselined", "recorded_count", len(recorded), "recorded", recorded)
+	case "status":
+		states, err := db.MigrationStatus(ctx)
+		if err != nil {
+			return err
+		}
+		for _, state := range states {
+			if state.AppliedAt == nil {
+				fmt.Printf("pending  %s__%s\n", state.Version, state.Name)
+				continue
+			}
+			fmt.Printf("applied  %s__%s  %s\n", state.Version, state.Name, state.AppliedAt.Format(time.RFC3339))
+		}
+	default:
+		return fmt.Errorf("unknown migrate command %q: expected up, status or baseline", command)
+	}
+	return nil
 }
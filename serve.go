@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/onefeed-th/onefeed-th-backend-api/config"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/buildinfo"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/rds"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/core/supervisor"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/db"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/middleware"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/routes"
+	"github.com/onefeed-th/onefeed-th-backend-api/internal/service"
+)
+
+// appModeVar sets the run mode when --mode isn't given
+const appModeVar = "APP_MODE"
+
+// Run modes of serve. Worker instances consume the job and push queues without the API,
+// so ingestion scales apart from the read API; api instances leave the queues to them
+const (
+	modeAll    = "all"
+	modeAPI    = "api"
+	modeWorker = "worker"
+
+	modeUsage = "what to run: all (API and workers), api or worker; APP_MODE sets the default"
+)
+
+// serve runs the REST server, the background workers or both, depending on mode, until ctx
+// is done
+func serve(ctx context.Context, mode string) error {
+	if mode != modeAll && mode != modeAPI && mode != modeWorker {
+		return fmt.Errorf("invalid mode %q, use all, api or worker", mode)
+	}
+	cfg := config.GetConfig()
+
+	// pick up config file edits and SIGHUP; settings read at startup still need a restart
+	config.Watch(ctx)
+
+	build := buildinfo.Get()
+	slog.Info("Starting onefeed-api",
+		"commit", build.Commit,
+		"build_time", build.BuildTime,
+		"go_version", build.GoVersion,
+		"profile", build.Profile,
+		"modified", build.Modified,
+		"mode", mode,
+	)
+
+	repo, err := initStorage(ctx, cfg.Startup.Policy == config.StartupPolicyDegraded)
+	if err != nil {
+		return err
+	}
+	defer closeStorage()
+
+	if cfg.Storage.Driver != config.StorageDriverMemory && cfg.Postgres.MigrateOnStartup {
+		if err := runMigrations(ctx, "up"); err != nil {
+			return fmt.Errorf("failed to migrate database on startup: %w", err)
+		}
+	}
+
+	if mode != modeAll && cfg.Storage.Driver == config.StorageDriverMemory {
+		slog.Warn("Memory storage isn't shared between instances, so queued jobs only run with mode all")
+	}
+
+	if !cfg.Auth.Enabled {
+		slog.Warn("API key authentication is disabled, /internal and /backoffice are open")
+	}
+
+	// initialize service
+	service := service.NewService(repo)
+
+	// every instance pushes news collected by another one to its own streams
+	if mode != modeWorker && cfg.Storage.Driver != config.StorageDriverMemory {
+		go db.Listen(ctx, db.NewsCreatedChannel, service.HandleNewsCreated)
+	}
+
+	if mode != modeAPI {
+		// run queued background jobs; does nothing when jobs.workers is 0
+		go service.RunJobWorkers(ctx)
+
+		// send queued push notifications; does nothing unless FCM is configured
+		go service.RunPushWorker(ctx)
+	}
+
+	// ping Postgres and Redis in the background and rebuild connections that stay down
+	if cfg.Storage.Driver != config.StorageDriverMemory && cfg.HealthCheck.Interval > 0 {
+		supervisor.Register(
+			supervisor.Dependency{Name: "postgres", Critical: true, Ping: db.Ping, Reconnect: db.Reconnect},
+			supervisor.Dependency{Name: "redis", Ping: rds.Ping, Reconnect: rds.Reconnect},
+		)
+		go supervisor.Run(ctx, time.Duration(cfg.HealthCheck.Interval)*time.Second, cfg.HealthCheck.FailureThreshold)
+	}
+
+	// initialize mux; workers only answer the health checks of their orchestrator
+	var handler http.Handler
+	if mode == modeWorker {
+		handler = routes.RegisterProbeRoutes(service)
+	} else {
+		handler = routes.RegisterRoutes(service)
+	}
+	handler = middleware.AccessLog(handler)
+	handler = middleware.RecoverPanic(handler)
+	handler = middleware.RequestID(handler)
+
+	// global middlewares
+	var httpHandler http.Handler = handler
+
+	// create configure http server
+	server := http.Server{
+		Handler: httpHandler,
+	}
+
+	// open news streams never finish on their own, end them when shutting down
+	server.RegisterOnShutdown(service.CloseNewsStreams)
+
+	listener, err := listen(cfg)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := context.WithCancel(ctx)
+	defer stop()
+
+	go func() {
+		slog.Info("Starting REST Server", "address", listener.Addr().String())
+		if cfg.RestServer.Socket == "" {
+			host := cfg.RestServer.Host
+			if host == "" {
+				host = "localhost"
+			}
+			slog.Info("Local server", "url", fmt.Sprintf("http://%s", net.JoinHostPort(host, strconv.Itoa(cfg.RestServer.Port))))
+		}
+		slog.Info("waiting for request...")
+
+		err := server.Serve(listener)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("Failed to serve", "error", err)
+			stop()
+		}
+	}()
+
+	// wait for the context to be canceled (i.e., SIGINT or SIGTERM)
+	<-ctx.Done()
+	slog.Info("Shutting down server...")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Shutdown HTTP server
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		slog.Error("Server shutdown failed", "error", err)
+	}
+
+	slog.Info("Server gracefully stopped")
+	return nil
+}
+
+// listen opens restServer.socket when set, and restServer.host and port otherwise
+func listen(cfg *config.Config) (net.Listener, error) {
+	if cfg.RestServer.Socket == "" {
+		listener, err := net.Listen("tcp", net.JoinHostPort(cfg.RestServer.Host, strconv.Itoa(cfg.RestServer.Port)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to listen: %w", err)
+		}
+		return listener, nil
+	}
+
+	// a socket left behind by a process that didn't shut down cleanly would fail the bind
+	path := cfg.RestServer.Socket
+	if info, err := os.Stat(path); err == nil && info.Mode().Type() == fs.ModeSocket {
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+		}
+	}
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen: %w", err)
+	}
+	mode, _ := strconv.ParseUint(cfg.RestServer.SocketMode, 8, 32) // checked by config validation
+	if err := os.Chmod(path, fs.FileMode(mode)); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to set socket mode: %w", err)
+	}
+	return listener, nil
+}